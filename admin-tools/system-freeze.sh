@@ -131,6 +131,26 @@ if [ -z "${KNIT_GARBAGE_COLLECTION}" ]; then
 fi
 KNIT_GARBAGE_COLLECTION_REPLICAS=$(${KUBECTL} -n ${NAMESPACE} get deploy ${KNIT_GARBAGE_COLLECTION} -o json | ${JQ} -r '.spec.replicas')
 
+KNIT_RETENTION=${KNIT_RETENTION:-}
+if [ -z "${KNIT_RETENTION}" ]; then
+	KNIT_RETENTION=$(${HELM} -n ${NAMESPACE} get values -a "${CHART_NAME_APP}" -o json | ${JQ} -r '.loops.retention.component')-leader
+fi
+if [ -z "${KNIT_RETENTION}" ]; then
+	echo "KNIT_RETENTION not set" >&2
+	exit 1
+fi
+KNIT_RETENTION_REPLICAS=$(${KUBECTL} -n ${NAMESPACE} get deploy ${KNIT_RETENTION} -o json | ${JQ} -r '.spec.replicas')
+
+KNIT_MEASURE=${KNIT_MEASURE:-}
+if [ -z "${KNIT_MEASURE}" ]; then
+	KNIT_MEASURE=$(${HELM} -n ${NAMESPACE} get values -a "${CHART_NAME_APP}" -o json | ${JQ} -r '.loops.measure.component')-leader
+fi
+if [ -z "${KNIT_MEASURE}" ]; then
+	echo "KNIT_MEASURE not set" >&2
+	exit 1
+fi
+KNIT_MEASURE_REPLICAS=$(${KUBECTL} -n ${NAMESPACE} get deploy ${KNIT_MEASURE} -o json | ${JQ} -r '.spec.replicas')
+
 if ! [ "0" = $(${HELM} -n ${NAMESPACE} list --filter "${CHART_NAME_IMAGE_REGISTRY}" -o json | ${JQ} -r '. | length') ] ; then
 	echo " - ${CHART_NAME_IMAGE_REGISTRY} is detected." >&2
 	KNIT_IMAGE_REGISTRY=${KNIT_IMAGE_REGISTRY:-}
@@ -148,11 +168,15 @@ fi
 echo "* Freezing Knitfab in namespace ${NAMESPACE}" >&2
 # knitd-backend: to reject uploading new Data
 # garbage-collection-leader: to stop garbage collection, deleting PVC
+# retention-leader: to stop deleting expired Data
+# measure-leader: to stop spawning data agents for measuring Data
 # initialize-leader, projection-leader: to stop initializing new runs
 (
 	${KUBECTL} -n ${NAMESPACE} scale deployment --replicas=0 \
 		${KNIT_KNITD_BACKEND} \
 		${KNIT_GARBAGE_COLLECTION} \
+		${KNIT_RETENTION} \
+		${KNIT_MEASURE} \
 		${KNIT_INITIALIZE} \
 		${KNIT_PROJECTION} \
 		${KNIT_IMAGE_REGISTRY}  # if any.
@@ -199,6 +223,8 @@ ${KUBECTL} -n ${NAMESPACE} scale deployment --replicas=${KNIT_RUN_MANAGEMENT_REP
 ${KUBECTL} -n ${NAMESPACE} scale deployment --replicas=${KNIT_HOUSEKEEPING_REPLICAS} ${KNIT_HOUSEKEEPING}
 ${KUBECTL} -n ${NAMESPACE} scale deployment --replicas=${KNIT_FINISHING_REPLICAS} ${KNIT_FINISHING}
 ${KUBECTL} -n ${NAMESPACE} scale deployment --replicas=${KNIT_GARBAGE_COLLECTION_REPLICAS} ${KNIT_GARBAGE_COLLECTION}
+${KUBECTL} -n ${NAMESPACE} scale deployment --replicas=${KNIT_RETENTION_REPLICAS} ${KNIT_RETENTION}
+${KUBECTL} -n ${NAMESPACE} scale deployment --replicas=${KNIT_MEASURE_REPLICAS} ${KNIT_MEASURE}
 ${KUBECTL} -n ${NAMESPACE} scale deployment --replicas=${KNIT_IMAGE_REGISTRY_REPLICAS} ${KNIT_IMAGE_REGISTRY}
 
 EOF
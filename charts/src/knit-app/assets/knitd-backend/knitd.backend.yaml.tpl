@@ -11,11 +11,20 @@ cluster:
             initialCapacity: "{{ .Values.vex.margin }}"
     worker:
         priority: "{{ .Values.worker.priorityClassName }}"
+        maxConcurrentRuns: {{ .Values.worker.maxConcurrentRuns | default 0 }}
+        {{- with .Values.worker.quotas }}
+        quotas:
+{{ toYaml . | indent 12 }}
+        {{- end }}
         init:
             image: "{{ .Values.imageRepository }}{{ ternary "" "/" (empty .Values.imageRepository) }}{{ .Values.empty.image }}:{{ .Chart.AppVersion }}"
         nurse:
             serviceAccountSecret: "{{ .Values.nurse.serviceAccount }}-secret"
             image: "{{ .Values.imageRepository }}{{ ternary "" "/" (empty .Values.imageRepository) }}{{ .Values.nurse.image }}:{{ .Chart.AppVersion }}"
+    {{- with .Values.loops.retention.rules }}
+    retention:
+{{ toYaml . | indent 8 }}
+    {{- end }}
     keychains:
         signKeyForImportToken:
             name: "{{ .Values.knitd_backend.component }}-signer-for-import-token"
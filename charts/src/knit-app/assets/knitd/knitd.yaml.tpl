@@ -1,3 +1,12 @@
 dburi: "postgres://{{ .Values.database.service }}/knit"
 backendapiroot: http://{{ .Values.knitd_backend.service }}
 serverport: 8080
+auth:
+  enabled: {{ .Values.knitd.auth.enabled }}
+  tokenTTL: {{ .Values.knitd.auth.tokenTTL }}
+  {{- if .Values.knitd.auth.admin.email }}
+  admin:
+    email: {{ .Values.knitd.auth.admin.email | quote }}
+    name: {{ .Values.knitd.auth.admin.name | quote }}
+    passwordFile: /knit/admin/password
+  {{- end }}
@@ -4,20 +4,32 @@ go 1.24.0
 
 replace github.com/opst/knitfab => ../..
 
+// WebAPI types not released yet are developed in ../../knitfab-api-types,
+// until they are released as a new version of knitfab-api-types (see ../../knitfab-api-types/README.md).
+replace github.com/opst/knitfab-api-types => ../../knitfab-api-types
+
 require (
 	github.com/labstack/echo/v4 v4.13.3
 	github.com/opst/knitfab v1.6.1
+	github.com/opst/knitfab-api-types v1.6.1
 )
 
 require (
+	github.com/fxamacker/cbor/v2 v2.7.0 // indirect
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/google/go-containerregistry v0.20.3 // indirect
 	github.com/labstack/gommon v0.4.2 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/opst/knitfab-api-types v1.6.1 // indirect
+	github.com/opencontainers/go-digest v1.0.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.2 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/crypto v0.33.0 // indirect
 	golang.org/x/net v0.35.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
+	gopkg.in/inf.v0 v0.9.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+	k8s.io/apimachinery v0.32.2 // indirect
 )
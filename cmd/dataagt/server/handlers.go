@@ -2,6 +2,7 @@ package server
 
 import (
 	"compress/gzip"
+	"context"
 	"encoding/hex"
 	"io/fs"
 	"net/http"
@@ -9,11 +10,47 @@ import (
 	"path/filepath"
 
 	"github.com/labstack/echo/v4"
+	"github.com/opst/knitfab-api-types/data"
 	apierr "github.com/opst/knitfab/pkg/api-types-binding/errors"
 	"github.com/opst/knitfab/pkg/utils/archive"
 	kio "github.com/opst/knitfab/pkg/utils/io"
 )
 
+// Measure returns the size and the checksum of the content under root.
+//
+// Only regular files are counted in the size.
+func Measure(ctx context.Context, root string) (data.Measurement, error) {
+	size := data.Size{}
+	err := filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size.Bytes += info.Size()
+		size.Files += 1
+		return nil
+	})
+	if err != nil {
+		return data.Measurement{}, err
+	}
+
+	checksum, err := archive.ChecksumOf(ctx, root)
+	if err != nil {
+		return data.Measurement{}, err
+	}
+	return data.Measurement{Size: size, Checksum: checksum}, nil
+}
+
+// Reader returns a handler to send the content under root as tar.gz.
+//
+// When the request has the query parameter "size",
+// it responses the size and the checksum of the content (data.Measurement) as JSON, instead.
 func Reader(root string) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
@@ -27,6 +64,14 @@ func Reader(root string) echo.HandlerFunc {
 			return apierr.NotFound()
 		}
 
+		if c.QueryParams().Has("size") {
+			m, err := Measure(ctx, root)
+			if err != nil {
+				return apierr.InternalServerError(err)
+			}
+			return c.JSON(http.StatusOK, m)
+		}
+
 		resp.Header().Add("Trailer", "x-checksum-md5")
 		resp.Header().Add("Content-Type", "application/tar+gzip")
 
@@ -49,6 +94,12 @@ func (b breakWalk) Error() string {
 	return b.error
 }
 
+// Writer returns a handler to write the content in tar.gz under root.
+//
+// On success, it responses the size and the checksum of the content written (data.Measurement) as JSON.
+//
+// When root has content already, it is 409 Conflict with the header "x-checksum",
+// the checksum of the existing content, so that the client can verify it.
 func Writer(root string) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		err := filepath.Walk(root, func(p string, info fs.FileInfo, err error) error {
@@ -63,6 +114,11 @@ func Writer(root string) echo.HandlerFunc {
 		switch err.(type) {
 		case breakWalk:
 			c.Response().Header().Add("Content-Type", "application/json")
+			checksum, err := archive.ChecksumOf(c.Request().Context(), root)
+			if err != nil {
+				return apierr.InternalServerError(err)
+			}
+			c.Response().Header().Add("x-checksum", checksum)
 			return apierr.NewErrorMessage(http.StatusConflict, "data exists already")
 		case nil:
 			// nothing to do.
@@ -91,7 +147,10 @@ func Writer(root string) echo.HandlerFunc {
 			return apierr.NewErrorMessage(http.StatusBadRequest, "hash is not match.")
 		}
 
-		c.Response().WriteHeader(http.StatusNoContent)
-		return nil
+		m, err := Measure(c.Request().Context(), root)
+		if err != nil {
+			return apierr.InternalServerError(err)
+		}
+		return c.JSON(http.StatusOK, m)
 	}
 }
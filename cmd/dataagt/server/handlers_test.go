@@ -6,12 +6,14 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
 	"testing"
 
+	"github.com/opst/knitfab-api-types/data"
 	"github.com/opst/knitfab/cmd/dataagt/server"
 	httptestutil "github.com/opst/knitfab/internal/testutils/http"
 	"github.com/opst/knitfab/pkg/utils/archive"
@@ -192,6 +194,56 @@ func TestReader(t *testing.T) {
 	})
 }
 
+func TestMeasure(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sub", "dir"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"a.txt":             "hello",
+		"sub/b.txt":         "knitfab",
+		"sub/dir/empty.txt": "",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), b(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("a.txt", filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := try.To(server.Measure(context.Background(), root)).OrFatal(t)
+	want := data.Measurement{
+		Size:     data.Size{Bytes: 12, Files: 3},
+		Checksum: try.To(archive.ChecksumOf(context.Background(), root)).OrFatal(t),
+	}
+	if got != want {
+		t.Errorf("measurement: (actual, expected) = (%+v, %+v)", got, want)
+	}
+
+	t.Run("Reader responses the size when it is queried", func(t *testing.T) {
+		testee := server.Reader(root)
+		e := echo.New()
+		ctx, resprec := httptestutil.Get(e, "/?size")
+		if err := testee(ctx); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		resp := resprec.Result()
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Error("status code 200 !=", resp.StatusCode)
+		}
+		got := data.Measurement{}
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("measurement: (actual, expected) = (%+v, %+v)", got, want)
+		}
+	})
+}
+
 func TestWriter(t *testing.T) {
 	t.Run("it writes payload to given file", func(t *testing.T) {
 		root := t.TempDir()
@@ -212,12 +264,22 @@ func TestWriter(t *testing.T) {
 		resp := resprec.Result()
 		defer resp.Body.Close()
 
-		expectedStatusCode := http.StatusNoContent
+		expectedStatusCode := http.StatusOK
 		if resp.StatusCode != expectedStatusCode {
 			t.Error("expected", expectedStatusCode, ", but actual ", resp.StatusCode)
 		}
 
 		wantRoot := "./testdata/root"
+		{
+			want := try.To(server.Measure(context.Background(), wantRoot)).OrFatal(t)
+			got := data.Measurement{}
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("measurement: (actual, expected) = (%+v, %+v)", got, want)
+			}
+		}
 		err := filepath.Walk("./testdata/root", func(p string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
@@ -379,6 +441,11 @@ func TestWriter(t *testing.T) {
 			}
 		}
 
+		want := try.To(archive.ChecksumOf(context.Background(), root)).OrFatal(t)
+		if got := resp.Header.Get("x-checksum"); got != want {
+			t.Errorf("checksum of the existing content: got %q, want %q", got, want)
+		}
+
 		filesAfter := []string{}
 		filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
 			if err != nil {
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/hectane/go-acl"
 	"github.com/opst/knitfab/cmd/knit/config/open"
@@ -35,6 +36,29 @@ type KnitProfile struct {
 
 	// cert is a certificate for knit server.
 	Cert KnitCert `yaml:"cert"`
+
+	// credential is a bearer token to access knit server.
+	//
+	// It is set by `knit login`, and refreshed automatically.
+	Credential *KnitCredential `yaml:"credential,omitempty"`
+}
+
+// KnitCredential is a bearer token issued by knit server.
+type KnitCredential struct {
+	Token     string    `yaml:"token"`
+	IssuedAt  time.Time `yaml:"issuedAt"`
+	ExpiresAt time.Time `yaml:"expiresAt"`
+}
+
+// Expired returns true if the token is expired at the time.
+func (c *KnitCredential) Expired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
+
+// ShouldRefresh returns true if the token has passed the half of its lifetime at the time.
+func (c *KnitCredential) ShouldRefresh(now time.Time) bool {
+	halflife := c.ExpiresAt.Sub(c.IssuedAt) / 2
+	return !now.Before(c.IssuedAt.Add(halflife))
 }
 
 func verifyUrl(s string) bool {
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	prof "github.com/opst/knitfab/cmd/knit/config/profiles"
 )
@@ -35,6 +36,41 @@ profname:
 		if prof.Cert.CA != expectedCACert {
 			t.Errorf("prof.CACerts ummatch. (actual, expected) = (%v, %v)", prof.Cert.CA, expectedCACert)
 		}
+
+		if prof.Credential != nil {
+			t.Errorf("prof.Credential is not nil: %+v", prof.Credential)
+		}
+	})
+
+	t.Run("unmarshalling profile with credential works well", func(t *testing.T) {
+		conf, err := prof.Unmarshall([]byte(`
+profname:
+    apiRoot: "https://api.example.com"
+    credential:
+        token: TOKEN
+        issuedAt: 2024-04-01T12:00:00Z
+        expiresAt: 2024-04-02T12:00:00Z
+`))
+		if err != nil {
+			t.Fatalf("failed to unmarshal.: %+v", err)
+		}
+		p, ok := conf["profname"]
+		if !ok {
+			t.Fatal("config has not profile")
+		}
+		if p.Credential == nil {
+			t.Fatal("prof.Credential is nil")
+		}
+		expected := prof.KnitCredential{
+			Token:     "TOKEN",
+			IssuedAt:  time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC),
+			ExpiresAt: time.Date(2024, 4, 2, 12, 0, 0, 0, time.UTC),
+		}
+		if actual := *p.Credential; actual.Token != expected.Token ||
+			!actual.IssuedAt.Equal(expected.IssuedAt) ||
+			!actual.ExpiresAt.Equal(expected.ExpiresAt) {
+			t.Errorf("prof.Credential unmatch. (actual, expected) = (%+v, %+v)", actual, expected)
+		}
 	})
 
 }
@@ -101,5 +137,38 @@ func TestKnitProfile(t *testing.T) {
 
 }
 
+func TestKnitCredential(t *testing.T) {
+	cred := prof.KnitCredential{
+		Token:     "TOKEN",
+		IssuedAt:  time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC),
+		ExpiresAt: time.Date(2024, 4, 2, 12, 0, 0, 0, time.UTC),
+	}
+
+	for name, testcase := range map[string]struct {
+		now           time.Time
+		shouldRefresh bool
+		expired       bool
+	}{
+		"just after issued": {
+			now: cred.IssuedAt.Add(time.Minute), shouldRefresh: false, expired: false,
+		},
+		"after half of lifetime": {
+			now: cred.IssuedAt.Add(12 * time.Hour), shouldRefresh: true, expired: false,
+		},
+		"after expiration": {
+			now: cred.ExpiresAt, shouldRefresh: true, expired: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if actual := cred.ShouldRefresh(testcase.now); actual != testcase.shouldRefresh {
+				t.Errorf("ShouldRefresh: (actual, expected) = (%v, %v)", actual, testcase.shouldRefresh)
+			}
+			if actual := cred.Expired(testcase.now); actual != testcase.expired {
+				t.Errorf("Expired: (actual, expected) = (%v, %v)", actual, testcase.expired)
+			}
+		})
+	}
+}
+
 //go:embed testdata/ca.crt
 var cacertfile []byte
@@ -4,12 +4,17 @@ go 1.24.0
 
 replace github.com/opst/knitfab => ../..
 
+// WebAPI types not released yet are developed in ../../knitfab-api-types,
+// until they are released as a new version of knitfab-api-types (see ../../knitfab-api-types/README.md).
+replace github.com/opst/knitfab-api-types => ../../knitfab-api-types
+
 require (
 	github.com/cheggaaa/pb/v3 v3.1.5
 	github.com/hectane/go-acl v0.0.0-20230122075934-ca0b05cb1adb
 	github.com/opst/knitfab v1.6.1
 	github.com/opst/knitfab-api-types v1.6.1
 	github.com/youta-t/flarc v0.0.3
+	golang.org/x/term v0.29.0
 	gopkg.in/yaml.v3 v3.0.1
 	k8s.io/apimachinery v0.32.2
 )
@@ -26,6 +31,7 @@ require (
 	github.com/opencontainers/go-digest v1.0.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/crypto v0.33.0 // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	gopkg.in/inf.v0 v0.9.1 // indirect
 )
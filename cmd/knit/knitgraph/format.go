@@ -0,0 +1,149 @@
+package knitgraph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format is a format to output a graph.
+type Format string
+
+const (
+	// Graphviz dot format.
+	FormatDot Format = "dot"
+
+	// W3C PROV-JSON.
+	FormatProvJSON Format = "prov-json"
+
+	// OpenLineage events, one per line.
+	FormatOpenLineage Format = "openlineage"
+
+	// Nodes and edges in JSON.
+	FormatJSON Format = "json"
+
+	// Mermaid flowchart.
+	FormatMermaid Format = "mermaid"
+)
+
+func Formats() []Format {
+	return []Format{FormatDot, FormatProvJSON, FormatOpenLineage, FormatJSON, FormatMermaid}
+}
+
+func (f Format) String() string {
+	return string(f)
+}
+
+// Set sets the value of the format.
+//
+// Compliant with the flag.Value interface.
+func (f *Format) Set(s string) error {
+	for _, known := range Formats() {
+		if s == string(known) {
+			*f = known
+			return nil
+		}
+	}
+
+	names := []string{}
+	for _, known := range Formats() {
+		names = append(names, string(known))
+	}
+	return fmt.Errorf("the value should be one of %s: %v", strings.Join(names, ", "), s)
+}
+
+// Generate writes the graph in the format.
+func (g *DirectedGraph) Generate(w io.Writer, format Format) error {
+	switch format {
+	case FormatDot, "":
+		return g.GenerateDot(w)
+	case FormatProvJSON:
+		return g.GenerateProvJSON(w)
+	case FormatOpenLineage:
+		return g.GenerateOpenLineage(w, time.Now())
+	case FormatJSON:
+		return g.GenerateJSON(w)
+	case FormatMermaid:
+		return g.GenerateMermaid(w)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+type nodeKind int
+
+const (
+	kindData nodeKind = iota
+	kindRun
+	kindPlan
+	kindRoot
+)
+
+// nodeRef tells which Data, Run or Plan a node belongs to.
+type nodeRef struct {
+	kind nodeKind
+
+	// NodeId of the Data, Run or Plan node.
+	owner NodeId
+
+	// knitId, runId or planId.
+	id string
+
+	// path of the mountpoint, when the node is a mountpoint of a Plan.
+	path string
+}
+
+// index returns nodeRefs for all nodes in the graph.
+func (g *DirectedGraph) index() map[NodeId]nodeRef {
+	idx := map[NodeId]nodeRef{}
+	for _, d := range g.DataNodes.Iter() {
+		idx[d.NodeId] = nodeRef{kind: kindData, owner: d.NodeId, id: d.KnitId}
+	}
+	for _, r := range g.RunNodes.Iter() {
+		idx[r.NodeId] = nodeRef{kind: kindRun, owner: r.NodeId, id: r.RunId}
+	}
+	for _, p := range g.PlanNodes.Iter() {
+		idx[p.NodeId] = nodeRef{kind: kindPlan, owner: p.NodeId, id: p.PlanId}
+		for _, in := range p.InputNodes.Iter() {
+			idx[in.NodeId] = nodeRef{kind: kindPlan, owner: p.NodeId, id: p.PlanId, path: in.Path}
+		}
+		for _, out := range p.OutputNodes.Iter() {
+			idx[out.NodeId] = nodeRef{kind: kindPlan, owner: p.NodeId, id: p.PlanId, path: out.Path}
+		}
+		if l := p.LogNode; l != nil {
+			idx[l.NodeId] = nodeRef{kind: kindPlan, owner: p.NodeId, id: p.PlanId, path: "(log)"}
+		}
+	}
+	for _, r := range g.RootNodes {
+		idx[r.NodeId] = nodeRef{kind: kindRoot, owner: r.NodeId}
+	}
+	return idx
+}
+
+// resolvedEdge is an Edge between Data, Run or Plan nodes.
+type resolvedEdge struct {
+	From  nodeRef
+	To    nodeRef
+	Label string
+}
+
+// edges returns edges between Data, Run and Plan nodes.
+//
+// Edges from or to root nodes and edges to nodes not in the graph are dropped.
+func (g *DirectedGraph) edges() []resolvedEdge {
+	idx := g.index()
+	ret := []resolvedEdge{}
+	for _, e := range g.Edges {
+		from, ok := idx[e.FromId]
+		if !ok || from.kind == kindRoot {
+			continue
+		}
+		to, ok := idx[e.ToId]
+		if !ok || to.kind == kindRoot {
+			continue
+		}
+		ret = append(ret, resolvedEdge{From: from, To: to, Label: e.Label})
+	}
+	return ret
+}
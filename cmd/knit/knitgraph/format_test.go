@@ -0,0 +1,460 @@
+package knitgraph_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/opst/knitfab-api-types/data"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab-api-types/runs"
+	"github.com/opst/knitfab-api-types/tags"
+	"github.com/opst/knitfab/cmd/knit/knitgraph"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+// data1 --[/in/1]--> run1 --[/out/1]--> data2
+//
+//	`--[(log)]--> log1
+func lineageGraph(t *testing.T) *knitgraph.DirectedGraph {
+	run1Summary := runs.Summary{
+		RunId: "run1", Status: "done",
+		Plan: plans.Summary{
+			PlanId: "plan-3",
+			Image:  &plans.Image{Repository: "repo.invalid/trainer", Tag: "v1"},
+		},
+		UpdatedAt: try.To(rfctime.ParseRFC3339DateTime("2024-04-01T12:35:00+00:00")).OrFatal(t),
+	}
+	data1 := data.Detail{
+		KnitId: "data1",
+		Tags: []tags.Tag{
+			{Key: "foo", Value: "bar"},
+			{Key: domain.KeyKnitId, Value: "data1"},
+			{Key: domain.KeyKnitTimestamp, Value: "2024-04-01T12:34:55+00:00"},
+		},
+		Upstream: data.CreatedFrom{
+			Run: runs.Summary{
+				RunId: "run0", Status: "done",
+				Plan: plans.Summary{PlanId: "upload", Name: "knit#uploaded"},
+			},
+			Mountpoint: &plans.Mountpoint{Path: "/upload"},
+		},
+		Downstreams: []data.AssignedTo{
+			{Run: run1Summary, Mountpoint: plans.Mountpoint{Path: "/in/1"}},
+		},
+	}
+	data2 := data.Detail{
+		KnitId: "data2",
+		Tags: []tags.Tag{
+			{Key: domain.KeyKnitId, Value: "data2"},
+			{Key: domain.KeyKnitTimestamp, Value: "2024-04-01T12:34:56+00:00"},
+		},
+		Upstream: data.CreatedFrom{
+			Run:        run1Summary,
+			Mountpoint: &plans.Mountpoint{Path: "/out/1"},
+		},
+	}
+	log1 := data.Detail{
+		KnitId: "log1",
+		Tags: []tags.Tag{
+			{Key: domain.KeyKnitId, Value: "log1"},
+			{Key: domain.KeyKnitTimestamp, Value: "2024-04-01T12:34:57+00:00"},
+		},
+		Upstream: data.CreatedFrom{
+			Run: run1Summary,
+			Log: &plans.LogPoint{},
+		},
+	}
+	run1 := runs.Detail{
+		Summary: run1Summary,
+		Inputs: []runs.Assignment{
+			{KnitId: "data1", Mountpoint: plans.Mountpoint{Path: "/in/1"}},
+		},
+		Outputs: []runs.Assignment{
+			{KnitId: "data2", Mountpoint: plans.Mountpoint{Path: "/out/1"}},
+		},
+		Log: &runs.LogSummary{KnitId: "log1"},
+	}
+
+	return knitgraph.NewDirectedGraph(
+		knitgraph.WithData(data1, knitgraph.Emphasize()),
+		knitgraph.WithData(data2),
+		knitgraph.WithData(log1),
+		knitgraph.WithRun(run1),
+	)
+}
+
+// plan-a --[/out/1]-.-[/in/1]--> plan-b
+func planGraph() *knitgraph.DirectedGraph {
+	planA := plans.Detail{
+		Summary: plans.Summary{
+			PlanId: "plan-a",
+			Image:  &plans.Image{Repository: "repo.invalid/a", Tag: "v1"},
+		},
+		Active: true,
+		Outputs: []plans.Output{
+			{
+				Mountpoint: plans.Mountpoint{Path: "/out/1"},
+				Downstreams: []plans.Downstream{
+					{
+						Plan:       plans.Summary{PlanId: "plan-b"},
+						Mountpoint: plans.Mountpoint{Path: "/in/1"},
+					},
+				},
+			},
+		},
+	}
+	planB := plans.Detail{
+		Summary: plans.Summary{
+			PlanId: "plan-b",
+			Image:  &plans.Image{Repository: "repo.invalid/b", Tag: "v1"},
+		},
+		Active: false,
+		Inputs: []plans.Input{
+			{
+				Mountpoint: plans.Mountpoint{Path: "/in/1"},
+				Upstreams: []plans.Upstream{
+					{
+						Plan:       plans.Summary{PlanId: "plan-a"},
+						Mountpoint: &plans.Mountpoint{Path: "/out/1"},
+					},
+				},
+			},
+		},
+		Log: &plans.Log{},
+	}
+
+	return knitgraph.NewDirectedGraph(
+		knitgraph.WithPlan(planA, knitgraph.Emphasize()),
+		knitgraph.WithPlan(planB),
+	)
+}
+
+func TestFormat_Set(t *testing.T) {
+	for _, f := range knitgraph.Formats() {
+		var actual knitgraph.Format
+		if err := actual.Set(string(f)); err != nil {
+			t.Fatal(err)
+		}
+		if actual != f {
+			t.Errorf("format: (actual, expected) = (%s, %s)", actual, f)
+		}
+	}
+
+	var f knitgraph.Format
+	if err := f.Set("png"); err == nil {
+		t.Error("unknown format is accepted")
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	t.Run("for a data lineage graph", func(t *testing.T) {
+		actual := lineageGraph(t).ToJSON()
+
+		if !cmp.SliceEqWith(
+			actual.Data, []string{"data1", "data2", "log1"},
+			func(a knitgraph.JSONData, b string) bool { return a.Detail.KnitId == b },
+		) {
+			t.Errorf("data: %+v", actual.Data)
+		}
+		if !actual.Data[0].Emphasize {
+			t.Errorf("root data is not emphasized")
+		}
+		if len(actual.Runs) != 1 || actual.Runs[0].Detail.RunId != "run1" {
+			t.Errorf("runs: %+v", actual.Runs)
+		}
+		if len(actual.Plans) != 0 {
+			t.Errorf("plans: %+v", actual.Plans)
+		}
+
+		expectedEdges := []knitgraph.JSONEdge{
+			{From: "ddata1", To: "rrun1", Label: "/in/1"},
+			{From: "rrun1", To: "ddata2", Label: "/out/1"},
+			{From: "rrun1", To: "dlog1", Label: "(log)"},
+		}
+		if !cmp.SliceContentEq(actual.Edges, expectedEdges) {
+			t.Errorf("edges: (actual, expected) = (%+v, %+v)", actual.Edges, expectedEdges)
+		}
+	})
+
+	t.Run("for a plan graph", func(t *testing.T) {
+		actual := planGraph().ToJSON()
+
+		if len(actual.Plans) != 2 {
+			t.Errorf("plans: %+v", actual.Plans)
+		}
+		expectedEdges := []knitgraph.JSONEdge{
+			{From: "pplan-a", To: "pplan-b", FromPath: "/out/1", ToPath: "/in/1"},
+		}
+		if !cmp.SliceContentEq(actual.Edges, expectedEdges) {
+			t.Errorf("edges: (actual, expected) = (%+v, %+v)", actual.Edges, expectedEdges)
+		}
+	})
+
+	t.Run("GenerateJSON writes a valid json", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		if err := lineageGraph(t).Generate(buf, knitgraph.FormatJSON); err != nil {
+			t.Fatal(err)
+		}
+		got := knitgraph.JSONGraph{}
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Edges) != 3 {
+			t.Errorf("edges: %+v", got.Edges)
+		}
+	})
+}
+
+func TestGenerateMermaid(t *testing.T) {
+	t.Run("for a data lineage graph", func(t *testing.T) {
+		w := new(strings.Builder)
+		if err := lineageGraph(t).GenerateMermaid(w); err != nil {
+			t.Fatal(err)
+		}
+
+		local := func(s string) string {
+			return try.To(rfctime.ParseRFC3339DateTime(s)).OrFatal(t).
+				Time().Local().Format(rfctime.RFC3339DateTimeFormat)
+		}
+		expected := fmt.Sprintf(`flowchart TD
+	n0[("<b>Data</b><br/>knit#id: data1<br/>foo:bar<br/>%s")]
+	n1[("<b>Data</b><br/>knit#id: data2<br/>%s")]
+	n2[("<b>Data</b><br/>knit#id: log1<br/>%s")]
+	n3["<b>Run</b><br/>id: run1<br/>done<br/>last updated: %s<br/>image = repo.invalid/trainer:v1"]
+	n0 -->|"/in/1"| n3
+	n3 -->|"/out/1"| n1
+	n3 -->|"(log)"| n2
+	style n0 fill:#d4ecc6
+`,
+			local("2024-04-01T12:34:55+00:00"),
+			local("2024-04-01T12:34:56+00:00"),
+			local("2024-04-01T12:34:57+00:00"),
+			local("2024-04-01T12:35:00+00:00"),
+		)
+		if w.String() != expected {
+			t.Errorf("fail \nactual:\n%s \n=========\nexpect:\n%s", w.String(), expected)
+		}
+	})
+
+	t.Run("for data materialised from a parameter", func(t *testing.T) {
+		parameter := "0.01"
+		g := knitgraph.NewDirectedGraph(
+			knitgraph.WithData(data.Detail{
+				KnitId: "param1",
+				Tags: []tags.Tag{
+					{Key: domain.KeyKnitId, Value: "param1"},
+				},
+				Upstream: data.CreatedFrom{
+					Run: runs.Summary{
+						RunId: "run0", Status: "done",
+						Plan: plans.Summary{PlanId: "parameter", Name: "knit#parameter"},
+					},
+					Mountpoint: &plans.Mountpoint{Path: "/parameter"},
+				},
+				Parameter: &parameter,
+			}),
+		)
+
+		w := new(strings.Builder)
+		if err := g.GenerateMermaid(w); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `flowchart TD
+	n0[("<b>Data</b><br/>knit#id: param1<br/>parameter = 0.01")]
+`
+		if w.String() != expected {
+			t.Errorf("fail \nactual:\n%s \n=========\nexpect:\n%s", w.String(), expected)
+		}
+	})
+
+	t.Run("for a plan graph", func(t *testing.T) {
+		w := new(strings.Builder)
+		if err := planGraph().GenerateMermaid(w); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `flowchart TD
+	n0[["<b>Plan</b><br/>id: plan-a<br/>active<br/>image = repo.invalid/a:v1"]]
+	n1[["<b>Plan</b><br/>id: plan-b<br/>inactive<br/>image = repo.invalid/b:v1"]]
+	n0 -->|"/out/1 -> /in/1"| n1
+	style n0 fill:#EDD9B4
+`
+		if w.String() != expected {
+			t.Errorf("fail \nactual:\n%s \n=========\nexpect:\n%s", w.String(), expected)
+		}
+	})
+}
+
+func TestToProvJSON(t *testing.T) {
+	t.Run("for a data lineage graph", func(t *testing.T) {
+		doc := lineageGraph(t).ToProvJSON()
+
+		for _, id := range []string{"knit:data/data1", "knit:data/data2", "knit:data/log1", "knit:plan/plan-3"} {
+			if _, ok := doc.Entity[id]; !ok {
+				t.Errorf("entity %s is missing: %+v", id, doc.Entity)
+			}
+		}
+		if ts := doc.Entity["knit:data/data1"]["knit:timestamp"]; ts == nil {
+			t.Errorf("timestamp of data1 is missing: %+v", doc.Entity["knit:data/data1"])
+		}
+		if tags := doc.Entity["knit:data/data1"]["knit:tag"]; !cmp.SliceEq(tags.([]string), []string{"foo:bar"}) {
+			t.Errorf("tags of data1: %+v", tags)
+		}
+
+		run, ok := doc.Activity["knit:run/run1"]
+		if !ok {
+			t.Fatalf("activity run1 is missing: %+v", doc.Activity)
+		}
+		updatedAt := try.To(rfctime.ParseRFC3339DateTime("2024-04-01T12:35:00+00:00")).OrFatal(t).String()
+		if run["prov:endTime"] != updatedAt {
+			t.Errorf("endTime of run1: (actual, expected) = (%v, %s)", run["prov:endTime"], updatedAt)
+		}
+		if _, ok := doc.Agent["knit:plan/plan-3"]; !ok {
+			t.Errorf("agent plan-3 is missing: %+v", doc.Agent)
+		}
+
+		if len(doc.Used) != 1 {
+			t.Errorf("used: %+v", doc.Used)
+		}
+		for _, u := range doc.Used {
+			if u["prov:activity"] != "knit:run/run1" || u["prov:entity"] != "knit:data/data1" || u["prov:role"] != "/in/1" {
+				t.Errorf("used: %+v", u)
+			}
+		}
+
+		generated := map[any]any{}
+		for _, g := range doc.WasGeneratedBy {
+			if g["prov:activity"] != "knit:run/run1" {
+				t.Errorf("wasGeneratedBy: %+v", g)
+			}
+			generated[g["prov:entity"]] = g["prov:time"]
+		}
+		expectedGenerated := map[any]any{
+			"knit:data/data2": try.To(rfctime.ParseRFC3339DateTime("2024-04-01T12:34:56+00:00")).OrFatal(t).String(),
+			"knit:data/log1":  try.To(rfctime.ParseRFC3339DateTime("2024-04-01T12:34:57+00:00")).OrFatal(t).String(),
+		}
+		if !cmp.MapEq(generated, expectedGenerated) {
+			t.Errorf("wasGeneratedBy: (actual, expected) = (%+v, %+v)", generated, expectedGenerated)
+		}
+
+		if len(doc.WasAssociatedWith) != 1 {
+			t.Errorf("wasAssociatedWith: %+v", doc.WasAssociatedWith)
+		}
+		for _, a := range doc.WasAssociatedWith {
+			if a["prov:activity"] != "knit:run/run1" || a["prov:plan"] != "knit:plan/plan-3" {
+				t.Errorf("wasAssociatedWith: %+v", a)
+			}
+		}
+	})
+
+	t.Run("for a plan graph", func(t *testing.T) {
+		doc := planGraph().ToProvJSON()
+
+		for _, id := range []string{"knit:plan/plan-a", "knit:plan/plan-b"} {
+			if _, ok := doc.Entity[id]; !ok {
+				t.Errorf("entity %s is missing: %+v", id, doc.Entity)
+			}
+			if _, ok := doc.Agent[id]; !ok {
+				t.Errorf("agent %s is missing: %+v", id, doc.Agent)
+			}
+		}
+		if len(doc.WasInfluencedBy) != 1 {
+			t.Fatalf("wasInfluencedBy: %+v", doc.WasInfluencedBy)
+		}
+		for _, i := range doc.WasInfluencedBy {
+			if i["prov:influencee"] != "knit:plan/plan-b" || i["prov:influencer"] != "knit:plan/plan-a" {
+				t.Errorf("wasInfluencedBy: %+v", i)
+			}
+		}
+	})
+}
+
+func TestToOpenLineage(t *testing.T) {
+	now := try.To(rfctime.ParseRFC3339DateTime("2024-05-01T00:00:00+00:00")).OrFatal(t).Time()
+
+	t.Run("for a data lineage graph, it yields RunEvents", func(t *testing.T) {
+		actual := lineageGraph(t).ToOpenLineage(now)
+
+		if len(actual) != 1 {
+			t.Fatalf("events: %+v", actual)
+		}
+		ev := actual[0]
+		if ev.EventType != "COMPLETE" {
+			t.Errorf("eventType: %s", ev.EventType)
+		}
+		expectedTime := try.To(rfctime.ParseRFC3339DateTime("2024-04-01T12:35:00+00:00")).OrFatal(t).String()
+		if ev.EventTime != expectedTime {
+			t.Errorf("eventTime: (actual, expected) = (%s, %s)", ev.EventTime, expectedTime)
+		}
+		if ev.Run == nil || ev.Run.RunId != "run1" {
+			t.Errorf("run: %+v", ev.Run)
+		}
+		if ev.Job != (knitgraph.OpenLineageJob{Namespace: "knitfab", Name: "plan-3"}) {
+			t.Errorf("job: %+v", ev.Job)
+		}
+		if !cmp.SliceEq(ev.Inputs, []knitgraph.OpenLineageDataset{{Namespace: "knitfab", Name: "data1"}}) {
+			t.Errorf("inputs: %+v", ev.Inputs)
+		}
+		if !cmp.SliceEq(ev.Outputs, []knitgraph.OpenLineageDataset{
+			{Namespace: "knitfab", Name: "data2"},
+			{Namespace: "knitfab", Name: "log1"},
+		}) {
+			t.Errorf("outputs: %+v", ev.Outputs)
+		}
+		if !strings.HasSuffix(ev.SchemaURL, "#/$defs/RunEvent") {
+			t.Errorf("schemaURL: %s", ev.SchemaURL)
+		}
+	})
+
+	t.Run("for a plan graph, it yields JobEvents", func(t *testing.T) {
+		actual := planGraph().ToOpenLineage(now)
+
+		if len(actual) != 2 {
+			t.Fatalf("events: %+v", actual)
+		}
+		a, b := actual[0], actual[1]
+		if a.Job.Name != "plan-a" || b.Job.Name != "plan-b" {
+			t.Errorf("jobs: %+v, %+v", a.Job, b.Job)
+		}
+		if a.Run != nil || b.Run != nil {
+			t.Errorf("JobEvent has run")
+		}
+		if a.EventTime != rfctime.RFC3339(now).String() {
+			t.Errorf("eventTime: %s", a.EventTime)
+		}
+		if !cmp.SliceEq(a.Outputs, []knitgraph.OpenLineageDataset{{Namespace: "knitfab", Name: "plan-a:/out/1"}}) {
+			t.Errorf("outputs of plan-a: %+v", a.Outputs)
+		}
+		if !cmp.SliceEq(b.Inputs, []knitgraph.OpenLineageDataset{{Namespace: "knitfab", Name: "plan-a:/out/1"}}) {
+			t.Errorf("inputs of plan-b: %+v", b.Inputs)
+		}
+		if !cmp.SliceEq(b.Outputs, []knitgraph.OpenLineageDataset{{Namespace: "knitfab", Name: "plan-b:(log)"}}) {
+			t.Errorf("outputs of plan-b: %+v", b.Outputs)
+		}
+	})
+
+	t.Run("GenerateOpenLineage writes an event per line", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		if err := planGraph().GenerateOpenLineage(buf, now); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("lines: %d", len(lines))
+		}
+		for _, l := range lines {
+			ev := knitgraph.OpenLineageEvent{}
+			if err := json.Unmarshal([]byte(l), &ev); err != nil {
+				t.Errorf("invalid json: %s", l)
+			}
+		}
+	})
+
+}
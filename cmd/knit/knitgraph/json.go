@@ -0,0 +1,81 @@
+package knitgraph
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/opst/knitfab-api-types/data"
+	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab-api-types/runs"
+)
+
+// JSONGraph is the graph in json format.
+type JSONGraph struct {
+	Data  []JSONData `json:"data"`
+	Runs  []JSONRun  `json:"runs"`
+	Plans []JSONPlan `json:"plans"`
+	Edges []JSONEdge `json:"edges"`
+}
+
+type JSONData struct {
+	Id        NodeId      `json:"id"`
+	Emphasize bool        `json:"emphasize,omitempty"`
+	Detail    data.Detail `json:"detail"`
+}
+
+type JSONRun struct {
+	Id        NodeId      `json:"id"`
+	Emphasize bool        `json:"emphasize,omitempty"`
+	Detail    runs.Detail `json:"detail"`
+}
+
+type JSONPlan struct {
+	Id        NodeId       `json:"id"`
+	Emphasize bool         `json:"emphasize,omitempty"`
+	Detail    plans.Detail `json:"detail"`
+}
+
+// JSONEdge is an edge between nodes.
+//
+// For edges between Plans, FromPath and ToPath are the paths of mountpoints
+// (or "(log)") which the edge connects.
+type JSONEdge struct {
+	From     NodeId `json:"from"`
+	To       NodeId `json:"to"`
+	Label    string `json:"label,omitempty"`
+	FromPath string `json:"fromPath,omitempty"`
+	ToPath   string `json:"toPath,omitempty"`
+}
+
+// ToJSON converts the graph to JSONGraph.
+func (g *DirectedGraph) ToJSON() JSONGraph {
+	ret := JSONGraph{
+		Data:  []JSONData{},
+		Runs:  []JSONRun{},
+		Plans: []JSONPlan{},
+		Edges: []JSONEdge{},
+	}
+	for _, d := range g.DataNodes.Iter() {
+		ret.Data = append(ret.Data, JSONData{Id: d.NodeId, Emphasize: d.Emphasize, Detail: d.Detail})
+	}
+	for _, r := range g.RunNodes.Iter() {
+		ret.Runs = append(ret.Runs, JSONRun{Id: r.NodeId, Emphasize: r.Emphasize, Detail: r.Detail})
+	}
+	for _, p := range g.PlanNodes.Iter() {
+		ret.Plans = append(ret.Plans, JSONPlan{Id: p.NodeId, Emphasize: p.Emphasize, Detail: p.Detail})
+	}
+	for _, e := range g.edges() {
+		ret.Edges = append(ret.Edges, JSONEdge{
+			From: e.From.owner, To: e.To.owner, Label: e.Label,
+			FromPath: e.From.path, ToPath: e.To.path,
+		})
+	}
+	return ret
+}
+
+// GenerateJSON writes the graph in json format.
+func (g *DirectedGraph) GenerateJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(g.ToJSON())
+}
@@ -0,0 +1,130 @@
+package knitgraph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	"github.com/opst/knitfab-api-types/tags"
+	"github.com/opst/knitfab/pkg/domain"
+)
+
+// GenerateMermaid writes the graph as a Mermaid flowchart.
+//
+// Data are drawn as cylinders, Runs as rectangles and Plans as subroutines.
+func (g *DirectedGraph) GenerateMermaid(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "flowchart TD"); err != nil {
+		return err
+	}
+
+	// Mermaid node ids are sequential, because Knit Ids and so on may have characters
+	// not allowed in Mermaid ids.
+	ids := map[NodeId]string{}
+	newId := func(n NodeId) string {
+		id := fmt.Sprintf("n%d", len(ids))
+		ids[n] = id
+		return id
+	}
+	styles := []string{}
+
+	for _, d := range g.DataNodes.Iter() {
+		id := newId(d.NodeId)
+		lines := []string{"<b>Data</b>", "knit#id: " + d.KnitId}
+		for _, tag := range d.Tags {
+			switch tag.Key {
+			case tags.KeyKnitId:
+				continue
+			case domain.KeyKnitTimestamp:
+				if tsp, err := rfctime.ParseRFC3339DateTime(tag.Value); err == nil {
+					lines = append(lines, tsp.Time().Local().Format(rfctime.RFC3339DateTimeFormat))
+					continue
+				}
+			}
+			lines = append(lines, tag.String())
+		}
+		if d.Parameter != nil {
+			lines = append(lines, "parameter = "+*d.Parameter)
+		}
+		if _, err := fmt.Fprintf(w, "\t%s[(\"%s\")]\n", id, mermaidLabel(lines)); err != nil {
+			return err
+		}
+		if d.Emphasize {
+			styles = append(styles, fmt.Sprintf("\tstyle %s fill:#d4ecc6", id))
+		}
+	}
+
+	for _, r := range g.RunNodes.Iter() {
+		id := newId(r.NodeId)
+		lines := []string{
+			"<b>Run</b>",
+			"id: " + r.RunId,
+			r.Status,
+			"last updated: " + r.UpdatedAt.Time().Local().Format(rfctime.RFC3339DateTimeFormat),
+		}
+		if r.Plan.Image != nil {
+			lines = append(lines, "image = "+r.Plan.Image.String())
+		} else if r.Plan.Name != "" {
+			lines = append(lines, r.Plan.Name)
+		}
+		if _, err := fmt.Fprintf(w, "\t%s[\"%s\"]\n", id, mermaidLabel(lines)); err != nil {
+			return err
+		}
+		if r.Emphasize {
+			styles = append(styles, fmt.Sprintf("\tstyle %s fill:#FFD580", id))
+		}
+	}
+
+	for _, p := range g.PlanNodes.Iter() {
+		id := newId(p.NodeId)
+		activeness := "active"
+		if !p.Active {
+			activeness = "inactive"
+		}
+		lines := []string{"<b>Plan</b>", "id: " + p.PlanId, activeness}
+		if p.Image != nil {
+			lines = append(lines, "image = "+p.Image.String())
+		} else if p.Name != "" {
+			lines = append(lines, p.Name)
+		}
+		for _, a := range p.Annotations {
+			lines = append(lines, a.Key+"="+a.Value)
+		}
+		if _, err := fmt.Fprintf(w, "\t%s[[\"%s\"]]\n", id, mermaidLabel(lines)); err != nil {
+			return err
+		}
+		if p.Emphasize {
+			styles = append(styles, fmt.Sprintf("\tstyle %s fill:#EDD9B4", id))
+		}
+	}
+
+	for _, e := range g.edges() {
+		label := e.Label
+		if e.From.path != "" || e.To.path != "" {
+			label = e.From.path + " -> " + e.To.path
+		}
+		arrow := "-->"
+		if label != "" {
+			arrow = fmt.Sprintf("-->|\"%s\"|", mermaidLabel([]string{label}))
+		}
+		if _, err := fmt.Fprintf(w, "\t%s %s %s\n", ids[e.From.owner], arrow, ids[e.To.owner]); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range styles {
+		if _, err := fmt.Fprintln(w, s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mermaidLabel(lines []string) string {
+	escaped := make([]string, len(lines))
+	for i, l := range lines {
+		escaped[i] = strings.ReplaceAll(l, `"`, "#quot;")
+	}
+	return strings.Join(escaped, "<br/>")
+}
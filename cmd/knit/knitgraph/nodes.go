@@ -355,6 +355,12 @@ func (d *DataNode) ToDot(w io.Writer) error {
 			strings.Join(systemtag, " | "),
 		)
 	}
+	if d.Parameter != nil {
+		subheader += fmt.Sprintf(
+			`<TR><TD COLSPAN="2">parameter = <B>%s</B></TD></TR>`,
+			html.EscapeString(*d.Parameter),
+		)
+	}
 
 	//The background color of the data node that is the argument gets highlighted from the others.
 	idBgColor := "#FFFFFF"
@@ -0,0 +1,142 @@
+package knitgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	"github.com/opst/knitfab/pkg/domain"
+)
+
+const (
+	OpenLineageNamespace = "knitfab"
+	OpenLineageProducer  = "https://github.com/opst/knitfab"
+
+	openLineageRunEventSchema = "https://openlineage.io/spec/2-0-2/OpenLineage.json#/$defs/RunEvent"
+	openLineageJobEventSchema = "https://openlineage.io/spec/2-0-2/OpenLineage.json#/$defs/JobEvent"
+)
+
+// OpenLineageEvent is a RunEvent or JobEvent of OpenLineage.
+//
+// See: https://openlineage.io/docs/spec/object-model
+type OpenLineageEvent struct {
+	EventType string               `json:"eventType,omitempty"`
+	EventTime string               `json:"eventTime"`
+	Run       *OpenLineageRun      `json:"run,omitempty"`
+	Job       OpenLineageJob       `json:"job"`
+	Inputs    []OpenLineageDataset `json:"inputs"`
+	Outputs   []OpenLineageDataset `json:"outputs"`
+	Producer  string               `json:"producer"`
+	SchemaURL string               `json:"schemaURL"`
+}
+
+type OpenLineageRun struct {
+	RunId string `json:"runId"`
+}
+
+type OpenLineageJob struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type OpenLineageDataset struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// openLineageEventType returns the type of RunEvent for the status of a Run.
+func openLineageEventType(status string) string {
+	switch domain.KnitRunStatus(status) {
+	case domain.Running, domain.Completing:
+		return "RUNNING"
+	case domain.Done:
+		return "COMPLETE"
+	case domain.Failed:
+		return "FAIL"
+	case domain.Aborting, domain.Invalidated:
+		return "ABORT"
+	default:
+		return "START"
+	}
+}
+
+func openLineageDataset(name string) OpenLineageDataset {
+	return OpenLineageDataset{Namespace: OpenLineageNamespace, Name: name}
+}
+
+// ToOpenLineage converts the graph to OpenLineage events.
+//
+// For each Run, it yields a RunEvent whose time is the last updated time of the Run.
+// Data are datasets named with their Knit Id, and Plans are jobs named with their Plan Id.
+//
+// For each Plan, it yields a JobEvent at the time now.
+// Output mountpoints of Plans are datasets named with "PLAN_ID:PATH" (or "PLAN_ID:(log)" for logs).
+func (g *DirectedGraph) ToOpenLineage(now time.Time) []OpenLineageEvent {
+	events := []OpenLineageEvent{}
+
+	for _, r := range g.RunNodes.Iter() {
+		ev := OpenLineageEvent{
+			EventType: openLineageEventType(r.Status),
+			EventTime: r.UpdatedAt.String(),
+			Run:       &OpenLineageRun{RunId: r.RunId},
+			Job:       OpenLineageJob{Namespace: OpenLineageNamespace, Name: r.Plan.PlanId},
+			Inputs:    []OpenLineageDataset{},
+			Outputs:   []OpenLineageDataset{},
+			Producer:  OpenLineageProducer,
+			SchemaURL: openLineageRunEventSchema,
+		}
+		for _, in := range r.Inputs {
+			ev.Inputs = append(ev.Inputs, openLineageDataset(in.KnitId))
+		}
+		for _, out := range r.Outputs {
+			ev.Outputs = append(ev.Outputs, openLineageDataset(out.KnitId))
+		}
+		if l := r.Log; l != nil {
+			ev.Outputs = append(ev.Outputs, openLineageDataset(l.KnitId))
+		}
+		events = append(events, ev)
+	}
+
+	eventTime := rfctime.RFC3339(now).String()
+	for _, p := range g.PlanNodes.Iter() {
+		ev := OpenLineageEvent{
+			EventTime: eventTime,
+			Job:       OpenLineageJob{Namespace: OpenLineageNamespace, Name: p.PlanId},
+			Inputs:    []OpenLineageDataset{},
+			Outputs:   []OpenLineageDataset{},
+			Producer:  OpenLineageProducer,
+			SchemaURL: openLineageJobEventSchema,
+		}
+		for _, in := range p.Inputs {
+			for _, ups := range in.Upstreams {
+				if ups.Log != nil {
+					ev.Inputs = append(ev.Inputs, openLineageDataset(fmt.Sprintf("%s:(log)", ups.Plan.PlanId)))
+				} else if mp := ups.Mountpoint; mp != nil {
+					ev.Inputs = append(ev.Inputs, openLineageDataset(fmt.Sprintf("%s:%s", ups.Plan.PlanId, mp.Path)))
+				}
+			}
+		}
+		for _, out := range p.Outputs {
+			ev.Outputs = append(ev.Outputs, openLineageDataset(fmt.Sprintf("%s:%s", p.PlanId, out.Path)))
+		}
+		if p.Log != nil {
+			ev.Outputs = append(ev.Outputs, openLineageDataset(fmt.Sprintf("%s:(log)", p.PlanId)))
+		}
+		events = append(events, ev)
+	}
+
+	return events
+}
+
+// GenerateOpenLineage writes the graph as OpenLineage events, one event per line.
+func (g *DirectedGraph) GenerateOpenLineage(w io.Writer, now time.Time) error {
+	enc := json.NewEncoder(w)
+	for _, ev := range g.ToOpenLineage(now) {
+		if err := enc.Encode(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
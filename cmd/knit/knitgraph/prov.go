@@ -0,0 +1,172 @@
+package knitgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab/pkg/domain"
+)
+
+// ProvJSON is a provenance document in W3C PROV-JSON.
+//
+// - Data are entities.
+//
+// - Runs are activities.
+//
+// - Plans are plans (entities), and agents associated with Runs based on them.
+//
+// See: https://www.w3.org/submissions/prov-json/
+type ProvJSON struct {
+	Prefix            map[string]string         `json:"prefix"`
+	Entity            map[string]map[string]any `json:"entity,omitempty"`
+	Activity          map[string]map[string]any `json:"activity,omitempty"`
+	Agent             map[string]map[string]any `json:"agent,omitempty"`
+	Used              map[string]map[string]any `json:"used,omitempty"`
+	WasGeneratedBy    map[string]map[string]any `json:"wasGeneratedBy,omitempty"`
+	WasAssociatedWith map[string]map[string]any `json:"wasAssociatedWith,omitempty"`
+	WasInfluencedBy   map[string]map[string]any `json:"wasInfluencedBy,omitempty"`
+}
+
+const provPrefix = "knit"
+
+func provQName(name string) map[string]string {
+	return map[string]string{"$": name, "type": "prov:QUALIFIED_NAME"}
+}
+
+func provDateTime(t string) map[string]string {
+	return map[string]string{"$": t, "type": "xsd:dateTime"}
+}
+
+func provDataId(knitId string) string { return provPrefix + ":data/" + knitId }
+func provRunId(runId string) string   { return provPrefix + ":run/" + runId }
+func provPlanId(planId string) string { return provPrefix + ":plan/" + planId }
+
+// ToProvJSON converts the graph to a PROV-JSON document.
+func (g *DirectedGraph) ToProvJSON() ProvJSON {
+	doc := ProvJSON{
+		Prefix: map[string]string{
+			provPrefix: "urn:knitfab:",
+		},
+		Entity:            map[string]map[string]any{},
+		Activity:          map[string]map[string]any{},
+		Agent:             map[string]map[string]any{},
+		Used:              map[string]map[string]any{},
+		WasGeneratedBy:    map[string]map[string]any{},
+		WasAssociatedWith: map[string]map[string]any{},
+		WasInfluencedBy:   map[string]map[string]any{},
+	}
+
+	addPlan := func(p plans.Summary) {
+		id := provPlanId(p.PlanId)
+		if _, ok := doc.Agent[id]; ok {
+			return
+		}
+		label := p.Name
+		if p.Image != nil {
+			label = p.Image.String()
+		}
+		doc.Entity[id] = map[string]any{
+			"prov:type":  provQName("prov:Plan"),
+			"prov:label": label,
+		}
+		doc.Agent[id] = map[string]any{
+			"prov:type":  provQName("prov:SoftwareAgent"),
+			"prov:label": label,
+		}
+	}
+
+	// knitId -> timestamp
+	timestamps := map[string]string{}
+	for _, d := range g.DataNodes.Iter() {
+		attrs := map[string]any{
+			"prov:type":  provQName(provPrefix + ":Data"),
+			"prov:label": d.KnitId,
+		}
+		userTags := []string{}
+		for _, t := range d.Tags {
+			switch t.Key {
+			case domain.KeyKnitId:
+				continue
+			case domain.KeyKnitTimestamp:
+				if tsp, err := rfctime.ParseRFC3339DateTime(t.Value); err == nil {
+					timestamps[d.KnitId] = tsp.String()
+					attrs[provPrefix+":timestamp"] = provDateTime(tsp.String())
+					continue
+				}
+			}
+			userTags = append(userTags, t.String())
+		}
+		if 0 < len(userTags) {
+			attrs[provPrefix+":tag"] = userTags
+		}
+		if d.Parameter != nil {
+			attrs[provPrefix+":parameter"] = *d.Parameter
+		}
+		doc.Entity[provDataId(d.KnitId)] = attrs
+	}
+
+	for _, r := range g.RunNodes.Iter() {
+		updatedAt := r.UpdatedAt.String()
+		attrs := map[string]any{
+			"prov:type":               provQName(provPrefix + ":Run"),
+			provPrefix + ":status":    r.Status,
+			provPrefix + ":updatedAt": provDateTime(updatedAt),
+		}
+		switch domain.KnitRunStatus(r.Status) {
+		case domain.Done, domain.Failed, domain.Invalidated:
+			attrs["prov:endTime"] = updatedAt
+		}
+		runId := provRunId(r.RunId)
+		doc.Activity[runId] = attrs
+
+		addPlan(r.Plan)
+		doc.WasAssociatedWith[fmt.Sprintf("_:assoc%d", len(doc.WasAssociatedWith))] = map[string]any{
+			"prov:activity": runId,
+			"prov:agent":    provPlanId(r.Plan.PlanId),
+			"prov:plan":     provPlanId(r.Plan.PlanId),
+		}
+	}
+
+	for _, p := range g.PlanNodes.Iter() {
+		addPlan(p.Summary)
+	}
+
+	for _, e := range g.edges() {
+		switch {
+		case e.From.kind == kindData && e.To.kind == kindRun:
+			doc.Used[fmt.Sprintf("_:used%d", len(doc.Used))] = map[string]any{
+				"prov:activity": provRunId(e.To.id),
+				"prov:entity":   provDataId(e.From.id),
+				"prov:role":     e.Label,
+			}
+		case e.From.kind == kindRun && e.To.kind == kindData:
+			gen := map[string]any{
+				"prov:entity":   provDataId(e.To.id),
+				"prov:activity": provRunId(e.From.id),
+				"prov:role":     e.Label,
+			}
+			if ts, ok := timestamps[e.To.id]; ok {
+				gen["prov:time"] = ts
+			}
+			doc.WasGeneratedBy[fmt.Sprintf("_:gen%d", len(doc.WasGeneratedBy))] = gen
+		case e.From.kind == kindPlan && e.To.kind == kindPlan:
+			doc.WasInfluencedBy[fmt.Sprintf("_:infl%d", len(doc.WasInfluencedBy))] = map[string]any{
+				"prov:influencee": provPlanId(e.To.id),
+				"prov:influencer": provPlanId(e.From.id),
+				"prov:label":      e.From.path + " -> " + e.To.path,
+			}
+		}
+	}
+
+	return doc
+}
+
+// GenerateProvJSON writes the graph in W3C PROV-JSON.
+func (g *DirectedGraph) GenerateProvJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(g.ToProvJSON())
+}
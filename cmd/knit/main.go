@@ -9,10 +9,12 @@ import (
 	"os/signal"
 	"path"
 
+	subaudit "github.com/opst/knitfab/cmd/knit/subcommands/audit"
 	"github.com/opst/knitfab/cmd/knit/subcommands/common"
 	subdata "github.com/opst/knitfab/cmd/knit/subcommands/data"
 	"github.com/opst/knitfab/cmd/knit/subcommands/extensions"
 	subinit "github.com/opst/knitfab/cmd/knit/subcommands/init"
+	sublogin "github.com/opst/knitfab/cmd/knit/subcommands/login"
 	sublic "github.com/opst/knitfab/cmd/knit/subcommands/license"
 	"github.com/opst/knitfab/cmd/knit/subcommands/logger"
 	subplan "github.com/opst/knitfab/cmd/knit/subcommands/plan"
@@ -36,17 +38,21 @@ func main() {
 	defer cancel()
 
 	init := try.To(subinit.New()).OrFatal(logger)
+	login := try.To(sublogin.New()).OrFatal(logger)
 	data := try.To(subdata.New()).OrFatal(logger)
 	run := try.To(subrun.New()).OrFatal(logger)
 	plan := try.To(subplan.New()).OrFatal(logger)
+	audit := try.To(subaudit.New()).OrFatal(logger)
 	license := try.To(sublic.New(CREDITS)).OrFatal(logger)
 	version := try.To(subver.New()).OrFatal(logger)
 
 	subcommands := []flarc.CommandGroupOption{
 		flarc.WithSubcommand("init", init),
+		flarc.WithSubcommand("login", login),
 		flarc.WithSubcommand("data", data),
 		flarc.WithSubcommand("run", run),
 		flarc.WithSubcommand("plan", plan),
+		flarc.WithSubcommand("audit", audit),
 		flarc.WithSubcommand("license", license),
 		flarc.WithSubcommand("version", version),
 	}
@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	apiaudit "github.com/opst/knitfab-api-types/audit"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+)
+
+func (c *client) FindAudit(ctx context.Context, query FindAuditParameter) ([]apiaudit.Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apipath("audit"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	paramMap := map[string][]string{
+		"actor":  query.Actor,
+		"action": query.Action,
+		"type":   query.TargetType,
+		"target": query.TargetId,
+	}
+	if query.Since != nil {
+		paramMap["since"] = []string{query.Since.Format(rfctime.RFC3339DateTimeFormatZ)}
+	}
+	if query.Duration != nil {
+		paramMap["duration"] = []string{query.Duration.String()}
+	}
+	for key, value := range paramMap {
+		if len(value) > 0 {
+			q.Add(key, strings.Join(value, ","))
+		}
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.httpclient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	entries := []apiaudit.Entry{}
+	if err := unmarshalJsonResponse(
+		resp, &entries,
+		MessageFor{
+			Status4xx: fmt.Sprintf("[BUG] client is not compatible with the server (status code = %d)", resp.StatusCode),
+			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
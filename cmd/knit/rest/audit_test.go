@@ -0,0 +1,89 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	apiaudit "github.com/opst/knitfab-api-types/audit"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	kprof "github.com/opst/knitfab/cmd/knit/config/profiles"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestFindAudit(t *testing.T) {
+	timeStamp := "2024-04-22T12:34:56.987654321+07:00"
+	since := try.To(rfctime.ParseRFC3339DateTime(timeStamp)).OrFatal(t).Time()
+	duration := 2 * time.Hour
+
+	entries := []apiaudit.Entry{
+		{
+			Id:        1,
+			Timestamp: rfctime.RFC3339(since.Truncate(time.Second)),
+			Actor:     "user@example.com",
+			Action:    "plan.activate",
+			Target:    apiaudit.Target{Type: "plan", Id: "plan-1"},
+			Before:    json.RawMessage(`{"active":true}`),
+			After:     json.RawMessage(`{"active":false}`),
+		},
+	}
+
+	for name, testcase := range map[string]struct {
+		when krst.FindAuditParameter
+		then url.Values
+	}{
+		"when query with nothing, server receives empty query": {
+			when: krst.FindAuditParameter{},
+			then: url.Values{},
+		},
+		"when query with each item, server receives all": {
+			when: krst.FindAuditParameter{
+				Actor:      []string{"a@example.com", "b@example.com"},
+				Action:     []string{"plan.activate", "run.delete"},
+				TargetType: []string{"plan", "run"},
+				TargetId:   []string{"plan-1", "run-1"},
+				Since:      &since,
+				Duration:   &duration,
+			},
+			then: url.Values{
+				"actor":    []string{"a@example.com,b@example.com"},
+				"action":   []string{"plan.activate,run.delete"},
+				"type":     []string{"plan,run"},
+				"target":   []string{"plan-1,run-1"},
+				"since":    []string{timeStamp},
+				"duration": []string{"2h0m0s"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var query url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/api/audit" {
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+				query = r.URL.Query()
+				w.Header().Add("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(entries)
+			}))
+			defer server.Close()
+
+			profile := kprof.KnitProfile{ApiRoot: server.URL + "/api"}
+			testee := try.To(krst.NewClient(&profile)).OrFatal(t)
+
+			actual := try.To(testee.FindAudit(context.Background(), testcase.when)).OrFatal(t)
+
+			if !cmp.MapEqWith(query, testcase.then, cmp.SliceEq[string]) {
+				t.Errorf("query:\n===actual===\n%v\n===expected===\n%v", query, testcase.then)
+			}
+			if !cmp.SliceEqWith(actual, entries, apiaudit.Entry.Equal) {
+				t.Errorf("response:\n===actual===\n%+v\n===expected===\n%+v", actual, entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apiauth "github.com/opst/knitfab-api-types/auth"
+)
+
+// bearerTransport adds "Authorization: Bearer ..." header to each request.
+type bearerTransport struct {
+	base  http.RoundTripper
+	token string
+}
+
+func (bt *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+bt.token)
+	return bt.base.RoundTrip(req)
+}
+
+func withBearer(hc *http.Client, token string) *http.Client {
+	base := hc.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	hc.Transport = &bearerTransport{base: base, token: token}
+	return hc
+}
+
+func (c *client) IssueToken(ctx context.Context, email string, password string) (apiauth.Token, error) {
+	b, err := json.Marshal(apiauth.Credential{Email: email, Password: password})
+	if err != nil {
+		return apiauth.Token{}, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, c.apipath("auth", "token"), bytes.NewBuffer(b),
+	)
+	if err != nil {
+		return apiauth.Token{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpclient.Do(req)
+	if err != nil {
+		return apiauth.Token{}, err
+	}
+	defer resp.Body.Close()
+
+	var tok apiauth.Token
+	if err := unmarshalJsonResponse(
+		resp, &tok,
+		MessageFor{
+			Status4xx: "failed to login",
+			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
+		},
+	); err != nil {
+		return apiauth.Token{}, err
+	}
+	return tok, nil
+}
+
+func (c *client) RefreshToken(ctx context.Context) (apiauth.Token, error) {
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, c.apipath("auth", "token", "refresh"), nil,
+	)
+	if err != nil {
+		return apiauth.Token{}, err
+	}
+
+	resp, err := c.httpclient.Do(req)
+	if err != nil {
+		return apiauth.Token{}, err
+	}
+	defer resp.Body.Close()
+
+	var tok apiauth.Token
+	if err := unmarshalJsonResponse(
+		resp, &tok,
+		MessageFor{
+			Status4xx: "failed to refresh credential",
+			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
+		},
+	); err != nil {
+		return apiauth.Token{}, err
+	}
+	return tok, nil
+}
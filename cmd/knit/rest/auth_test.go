@@ -0,0 +1,111 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apiauth "github.com/opst/knitfab-api-types/auth"
+	apierr "github.com/opst/knitfab-api-types/errors"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	kprof "github.com/opst/knitfab/cmd/knit/config/profiles"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestIssueToken(t *testing.T) {
+	issuedAt := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	token := apiauth.Token{
+		Token:     "new-token",
+		IssuedAt:  rfctime.RFC3339(issuedAt),
+		ExpiresAt: rfctime.RFC3339(issuedAt.Add(24 * time.Hour)),
+	}
+
+	t.Run("when server responses token, it returns the token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/auth/token") {
+				t.Errorf("request is not POST /api/auth/token (actual = %s %s)", r.Method, r.URL.Path)
+			}
+			if h := r.Header.Get("Authorization"); h != "" {
+				t.Errorf("unexpected Authorization header: %s", h)
+			}
+
+			cred := apiauth.Credential{}
+			if err := json.NewDecoder(r.Body).Decode(&cred); err != nil {
+				t.Fatal(err)
+			}
+			if cred.Email != "user@example.com" || cred.Password != "p@ssw0rd" {
+				t.Errorf("unexpected credential: %+v", cred)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write(try.To(json.Marshal(token)).OrFatal(t))
+		}))
+		defer server.Close()
+
+		prof := kprof.KnitProfile{ApiRoot: server.URL}
+		testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+		actual, err := testee.IssueToken(context.Background(), "user@example.com", "p@ssw0rd")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual.Token != token.Token ||
+			!actual.IssuedAt.Equal(token.IssuedAt) ||
+			!actual.ExpiresAt.Equal(token.ExpiresAt) {
+			t.Errorf("unexpected token: %+v", actual)
+		}
+	})
+
+	t.Run("when server responses Unauthorized, it returns error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write(try.To(json.Marshal(apierr.ErrorMessage{Reason: "email or password is wrong"})).OrFatal(t))
+		}))
+		defer server.Close()
+
+		prof := kprof.KnitProfile{ApiRoot: server.URL}
+		testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+		if _, err := testee.IssueToken(context.Background(), "user@example.com", "wrong"); err == nil {
+			t.Errorf("no error occured")
+		}
+	})
+}
+
+func TestBearerToken(t *testing.T) {
+	t.Run("when profile has credential, requests are sent with bearer token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if h := r.Header.Get("Authorization"); h != "Bearer current-token" {
+				t.Errorf("unexpected Authorization header: %s", h)
+			}
+			if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/auth/token/refresh") {
+				t.Errorf("request is not POST /api/auth/token/refresh (actual = %s %s)", r.Method, r.URL.Path)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write(try.To(json.Marshal(apiauth.Token{Token: "refreshed-token"})).OrFatal(t))
+		}))
+		defer server.Close()
+
+		prof := kprof.KnitProfile{
+			ApiRoot:    server.URL,
+			Credential: &kprof.KnitCredential{Token: "current-token"},
+		}
+		testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+		actual, err := testee.RefreshToken(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual.Token != "refreshed-token" {
+			t.Errorf("unexpected token: %+v", actual)
+		}
+	})
+}
@@ -11,7 +11,10 @@ import (
 	"strings"
 	"time"
 
+	apiaudit "github.com/opst/knitfab-api-types/audit"
+	apiauth "github.com/opst/knitfab-api-types/auth"
 	"github.com/opst/knitfab-api-types/data"
+	"github.com/opst/knitfab-api-types/lineage"
 	"github.com/opst/knitfab-api-types/plans"
 	"github.com/opst/knitfab-api-types/runs"
 	"github.com/opst/knitfab-api-types/tags"
@@ -38,6 +41,34 @@ type FindRunParameter struct {
 	Since *time.Time
 	// duration which updated time of run to be found is within
 	Duration *time.Duration
+	// cause which run to be found has exited with
+	ExitCause []string
+}
+
+// struct that contains the arguments for FindAudit
+type FindAuditParameter struct {
+	// actor who made changes
+	Actor []string
+	// action of changes
+	Action []string
+	// type of changed items: "data", "plan" or "run"
+	TargetType []string
+	// id of changed items
+	TargetId []string
+	// time which timestamp of entries to be found is equal or later than
+	Since *time.Time
+	// duration which timestamp of entries to be found is within
+	Duration *time.Duration
+}
+
+// struct that contains the arguments for GetLineage
+type LineageParameter struct {
+	// trace Runs using the Data, and Data generated by them
+	Downstream bool
+	// trace the Run generating the Data, and Data used by it
+	Upstream bool
+	// max number of Runs to be traced along a path. nil means unlimited.
+	Depth *uint
 }
 
 var ValUnit Unit = struct{}{}
@@ -73,6 +104,25 @@ type KnitClient interface {
 	// - error
 	PutTagsForData(knitId string, tags tags.Change) (*data.Detail, error)
 
+	// Preview Runs which would be created when tags of Data are changed.
+	//
+	// Tags are not changed actually.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: knitId to be (un)tagged.
+	//
+	// - apitag.Change: adding/removing tags.
+	//
+	// Returns
+	//
+	// - []apiruns.Preview: Runs which would be created
+	//
+	// - error
+	PreviewTagsForData(ctx context.Context, knitId string, tags tags.Change) ([]runs.Preview, error)
+
 	// Download Data from knitfab and verify checksum.
 	//
 	// Args
@@ -115,12 +165,45 @@ type KnitClient interface {
 	//
 	// - time.duration: duration which updated time of run to be found is within
 	//
+	// - func([]apidata.Detail) error: handler called with metadata of found data, page by page.
+	// When it returns an error, finding is stopped and the error is returned.
+	//
 	// Returns
 	//
-	// - []apidata.Detail: metadata of found data
+	// - error
+	FindData(ctx context.Context, tag []tags.Tag, since *time.Time, duration *time.Duration, handler func([]data.Detail) error) error
+
+	// DeleteData delete data with given knitId.
+	//
+	// The Run generating the Data is also deleted, with its other outputs.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: knitId to be deleted
+	//
+	// Returns
 	//
 	// - error
-	FindData(ctx context.Context, tag []tags.Tag, since *time.Time, duration *time.Duration) ([]data.Detail, error)
+	DeleteData(ctx context.Context, knitId string) error
+
+	// GetLineage get the Data Lineage traced from data with given knitId.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: knitId of the data where tracing starts
+	//
+	// - LineageParameter: directions and depth to be traced
+	//
+	// Returns
+	//
+	// - lineage.Graph: data and runs in the traced subgraph
+	//
+	// - error
+	GetLineage(ctx context.Context, knitId string, param LineageParameter) (lineage.Graph, error)
 
 	// GetPlan get plan detail with given planId.
 	//
@@ -154,15 +237,17 @@ type KnitClient interface {
 	//
 	// - []apitag.Tag: tags which plan to be found has as output
 	//
-	// Returns
+	// - func([]apiplans.Detail) error: handler called with metadata of found plans, page by page.
+	// When it returns an error, finding is stopped and the error is returned.
 	//
-	// - []apiplans.Detail: metadata of found plan
+	// Returns
 	//
 	// - error
 	FindPlan(
 		ctx context.Context, active logic.Ternary, imageVer *domain.ImageIdentifier,
 		inTags []tags.Tag, outTags []tags.Tag,
-	) ([]plans.Detail, error)
+		handler func([]plans.Detail) error,
+	) error
 
 	// Activate or deactivate a plan.
 	//
@@ -196,6 +281,59 @@ type KnitClient interface {
 	// - error
 	RegisterPlan(ctx context.Context, spec plans.PlanSpec) (plans.Detail, error)
 
+	// SupersedePlan register a new plan as the successor of the plan with given planId.
+	//
+	// The predecessor is deactivated.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: planId of the predecessor
+	//
+	// - apiplans.PlanSpec: spec of plan to be registered
+	//
+	// - bool: true if pending data for the predecessor should be routed to the successor.
+	//
+	// Returns
+	//
+	// - apiplans.Detail: metadata of created plan
+	//
+	// - error
+	SupersedePlan(ctx context.Context, predecessor string, spec plans.PlanSpec, reroute bool) (plans.Detail, error)
+
+	// PreviewPlan preview Runs which would be created when the plan is registered.
+	//
+	// The plan is not registered actually.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - apiplans.PlanSpec: spec of plan to be previewed
+	//
+	// Returns
+	//
+	// - []apiruns.Preview: Runs which would be created
+	//
+	// - error
+	PreviewPlan(ctx context.Context, spec plans.PlanSpec) ([]runs.Preview, error)
+
+	// DeletePlan delete the plan with given planId.
+	//
+	// Plans which have runs cannot be deleted.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: planId to be deleted
+	//
+	// Returns
+	//
+	// - error
+	DeletePlan(ctx context.Context, planId string) error
+
 	// SetResources set (or unset) resource limits of plan with given planId.
 	//
 	// Args
@@ -266,6 +404,24 @@ type KnitClient interface {
 	//
 	UnsetServiceAccount(ctx context.Context, planId string) (plans.Detail, error)
 
+	// UpdatePriority changes priority and concurrency limit of plan with given planId.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: planId to be updated
+	//
+	// - apiplans.PriorityChange: change of priority and concurrency limit
+	//
+	// Returns
+	//
+	// - apiplans.Detail: metadata of updated plan
+	//
+	// - error
+	//
+	UpdatePriority(ctx context.Context, planId string, change plans.PriorityChange) (plans.Detail, error)
+
 	// GetRun get run detail with given runId.
 	//
 	// Args
@@ -304,12 +460,13 @@ type KnitClient interface {
 	//
 	// - FindRunParameter
 	//
-	// Returns
+	// - func([]apirun.Detail) error: handler called with metadata of found runs, page by page.
+	// When it returns an error, finding is stopped and the error is returned.
 	//
-	// - []apirun.Detail: metadata of found run
+	// Returns
 	//
 	// - error
-	FindRun(context.Context, FindRunParameter) ([]runs.Detail, error)
+	FindRun(context.Context, FindRunParameter, func([]runs.Detail) error) error
 
 	// Abort abort run with given runId.
 	//
@@ -368,6 +525,51 @@ type KnitClient interface {
 	//
 	// - error
 	Retry(ctx context.Context, runId string) error
+
+	// IssueToken issue a new bearer token with email and password.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: email of the user
+	//
+	// - string: password of the user
+	//
+	// Returns
+	//
+	// - apiauth.Token: issued token
+	//
+	// - error
+	IssueToken(ctx context.Context, email string, password string) (apiauth.Token, error)
+
+	// RefreshToken issue a new bearer token in exchange for the current one.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// Returns
+	//
+	// - apiauth.Token: issued token
+	//
+	// - error
+	RefreshToken(ctx context.Context) (apiauth.Token, error)
+
+	// FindAudit find audit log entries with FindAuditParameter.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - FindAuditParameter
+	//
+	// Returns
+	//
+	// - []apiaudit.Entry: found entries
+	//
+	// - error
+	FindAudit(ctx context.Context, query FindAuditParameter) ([]apiaudit.Entry, error)
 }
 
 type client struct {
@@ -400,6 +602,10 @@ func NewClient(prof *kprof.KnitProfile) (KnitClient, error) {
 		httpclient = hc
 	}
 
+	if cred := prof.Credential; cred != nil && cred.Token != "" {
+		httpclient = withBearer(httpclient, cred.Token)
+	}
+
 	c := &client{
 		httpclient: httpclient,
 		api:        strings.TrimSuffix(prof.ApiRoot, "/"),
@@ -11,10 +11,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/opst/knitfab-api-types/data"
 	"github.com/opst/knitfab-api-types/misc/rfctime"
+	"github.com/opst/knitfab-api-types/runs"
 	"github.com/opst/knitfab-api-types/tags"
 	"github.com/opst/knitfab/pkg/utils/archive"
 	kio "github.com/opst/knitfab/pkg/utils/io"
@@ -215,6 +217,41 @@ func (c *client) PutTagsForData(knitId string, tags tags.Change) (*data.Detail,
 	return &res, nil
 }
 
+func (c *client) PreviewTagsForData(ctx context.Context, knitId string, tags tags.Change) ([]runs.Preview, error) {
+
+	reqBody, err := json.Marshal(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, c.apipath("data", knitId, "preview"), bytes.NewReader(reqBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := c.httpclient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	previews := []runs.Preview{}
+	if err := unmarshalJsonResponse(
+		resp, &previews,
+		MessageFor{
+			Status4xx: fmt.Sprintf("tagging data is rejected by server (status code = %d)", resp.StatusCode),
+			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return previews, nil
+}
+
 func (ci *client) GetDataRaw(ctx context.Context, knitId string, handler func(io.Reader) error) error {
 	req, err := http.NewRequestWithContext(
 		ctx, http.MethodGet, ci.apipath("data", knitId), nil,
@@ -311,15 +348,10 @@ func (ci *client) GetData(ctx context.Context, knitid string, handler func(FileE
 	})
 }
 
-func (c *client) FindData(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration) ([]data.Detail, error) {
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apipath("data"), nil)
-	if err != nil {
-		return nil, err
-	}
+func (c *client) FindData(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration, handler func([]data.Detail) error) error {
 
 	// set query values
-	q := req.URL.Query()
+	q := url.Values{}
 
 	if since != nil {
 		q.Add("since", since.Format(rfctime.RFC3339DateTimeFormatZ))
@@ -332,24 +364,29 @@ func (c *client) FindData(ctx context.Context, tags []tags.Tag, since *time.Time
 	for _, t := range tags {
 		q.Add("tag", t.String())
 	}
-	req.URL.RawQuery = q.Encode()
+
+	return findPages(ctx, c, c.apipath("data"), q, handler)
+}
+
+func (c *client) DeleteData(ctx context.Context, knitId string) error {
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodDelete, c.apipath("data", knitId), nil,
+	)
+	if err != nil {
+		return err
+	}
 
 	resp, err := c.httpclient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
-	dataMetas := make([]data.Detail, 0, 5)
-	if err := unmarshalJsonResponse(
-		resp, &dataMetas,
+	return unmarshalResponseDiscardingPayload(
+		resp,
 		MessageFor{
-			Status4xx: fmt.Sprintf("[BUG] client is not compatible with the server (status code = %d)", resp.StatusCode),
+			Status4xx: fmt.Sprintf("knitId:%v cannot be deleted", knitId),
 			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
 		},
-	); err != nil {
-		return nil, err
-	}
-
-	return dataMetas, nil
+	)
 }
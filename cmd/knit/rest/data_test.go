@@ -609,7 +609,10 @@ func TestFindData(t *testing.T) {
 				then := testcase.then
 
 				testee := try.To(krst.NewClient(&profile)).OrFatal(t)
-				result := try.To(testee.FindData(ctx, when.tags, when.since, when.duration)).OrFatal(t)
+				result := []data.Detail{}
+				if err := testee.FindData(ctx, when.tags, when.since, when.duration, collect(&result)); err != nil {
+					t.Fatal(err)
+				}
 
 				if !cmp.SliceContentEqWith(result, response, data.Detail.Equal) {
 					t.Errorf(
@@ -817,7 +820,10 @@ func TestFindData(t *testing.T) {
 			duration := time.Duration(2 * time.Hour)
 
 			testee := try.To(krst.NewClient(&profile)).OrFatal(t)
-			actualResponse := try.To(testee.FindData(ctx, queryTags, &since, &duration)).OrFatal(t)
+			actualResponse := []data.Detail{}
+			if err := testee.FindData(ctx, queryTags, &since, &duration, collect(&actualResponse)); err != nil {
+				t.Fatal(err)
+			}
 
 			if !cmp.SliceContentEqWith(actualResponse, expectedResponse, data.Detail.Equal) {
 				t.Errorf(
@@ -862,7 +868,7 @@ func TestFindData(t *testing.T) {
 				since := try.To(rfctime.ParseRFC3339DateTime("2024-04-22T12:34:56.987654321+07:00")).OrFatal(t).Time()
 				duration := time.Duration(2 * time.Hour)
 
-				if _, err := testee.FindData(ctx, queryTags, &since, &duration); err == nil {
+				if err := testee.FindData(ctx, queryTags, &since, &duration, collect(&[]data.Detail{})); err == nil {
 					t.Errorf("no error occured")
 				}
 
@@ -1261,3 +1267,126 @@ func TestGetDataRaw(t *testing.T) {
 		}
 	})
 }
+
+func TestPreviewTagsForData(t *testing.T) {
+	change := tags.Change{
+		AddTags:    []tags.UserTag{{Key: "type", Value: "raw"}},
+		RemoveTags: []tags.UserTag{{Key: "stage", Value: "draft"}},
+	}
+
+	t.Run("when server responses successfully, it returns previews", func(t *testing.T) {
+		expected := []runs.Preview{
+			{
+				Plan: plans.Summary{
+					PlanId: "plan-1",
+					Image:  &plans.Image{Repository: "repo.invalid/image", Tag: "0.1.0"},
+				},
+				Inputs: []runs.Assignment{
+					{
+						Mountpoint: plans.Mountpoint{
+							Path: "/in", Tags: []tags.Tag{{Key: "type", Value: "raw"}},
+						},
+						KnitId: "knit-1",
+					},
+				},
+			},
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				t.Errorf("unexpected method: %s", r.Method)
+			}
+			if !strings.HasSuffix(r.URL.Path, "/data/knit-1/preview") {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+
+			actual := tags.Change{}
+			if err := json.NewDecoder(r.Body).Decode(&actual); err != nil {
+				t.Fatal(err)
+			}
+			if !actual.Equal(&change) {
+				t.Errorf("unexpected change: %+v", actual)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(expected)
+		}))
+		defer server.Close()
+
+		prof := kprof.KnitProfile{ApiRoot: server.URL}
+		testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+		actual, err := testee.PreviewTagsForData(context.Background(), "knit-1", change)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.SliceContentEqWith(actual, expected, runs.Preview.Equal) {
+			t.Errorf("unexpected response: %+v", actual)
+		}
+	})
+
+	for _, status := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError} {
+		t.Run(fmt.Sprintf("when server responses with %d, it returns error", status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(apierr.ErrorMessage{Reason: "something wrong"})
+			}))
+			defer server.Close()
+
+			prof := kprof.KnitProfile{ApiRoot: server.URL}
+			testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+			if _, err := testee.PreviewTagsForData(context.Background(), "knit-1", change); err == nil {
+				t.Errorf("no error occured")
+			}
+		})
+	}
+}
+
+func TestDeleteData(t *testing.T) {
+	t.Run("when server responses without err, it returns nil", func(t *testing.T) {
+		knitId := "some-knit-id"
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodDelete {
+				t.Errorf("request is not DELETE /api/data/:knitid (actual method = %s)", r.Method)
+			}
+			if !strings.HasSuffix(r.URL.Path, "/data/"+knitId) {
+				t.Errorf("request is not DELETE /api/data/:knitid (actual path = %s)", r.URL.Path)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		prof := kprof.KnitProfile{ApiRoot: server.URL}
+		testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+		ctx := context.Background()
+		if err := testee.DeleteData(ctx, knitId); err != nil {
+			t.Fatalf("DeleteData returns error: %s", err)
+		}
+	})
+
+	for _, status := range []int{http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError} {
+		t.Run(fmt.Sprintf("when server responding with %d, it returns error", status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				buf, err := json.Marshal(apierr.ErrorMessage{Reason: "something wrong"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				w.Write(buf)
+			}))
+			defer server.Close()
+
+			prof := kprof.KnitProfile{ApiRoot: server.URL}
+			testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+			ctx := context.Background()
+			if err := testee.DeleteData(ctx, "some-knit-id"); err == nil {
+				t.Errorf("no error occured")
+			}
+		})
+	}
+}
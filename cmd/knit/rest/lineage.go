@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/opst/knitfab-api-types/lineage"
+)
+
+func (c *client) GetLineage(ctx context.Context, knitId string, param LineageParameter) (lineage.Graph, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apipath("lineage", knitId), nil)
+	if err != nil {
+		return lineage.Graph{}, err
+	}
+
+	q := req.URL.Query()
+	if param.Upstream {
+		q.Add("upstream", "true")
+	}
+	if param.Downstream {
+		q.Add("downstream", "true")
+	}
+	if param.Depth == nil {
+		q.Add("depth", "all")
+	} else {
+		q.Add("depth", strconv.FormatUint(uint64(*param.Depth), 10))
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.httpclient.Do(req)
+	if err != nil {
+		return lineage.Graph{}, err
+	}
+	defer resp.Body.Close()
+
+	var graph lineage.Graph
+	if err := unmarshalJsonResponse(
+		resp, &graph,
+		MessageFor{
+			Status4xx: fmt.Sprintf("knitId:%v is not found", knitId),
+			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
+		},
+	); err != nil {
+		return lineage.Graph{}, err
+	}
+	return graph, nil
+}
@@ -0,0 +1,115 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/opst/knitfab-api-types/data"
+	apierr "github.com/opst/knitfab-api-types/errors"
+	"github.com/opst/knitfab-api-types/lineage"
+	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab-api-types/runs"
+	kprof "github.com/opst/knitfab/cmd/knit/config/profiles"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/pkg/utils/pointer"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestGetLineage(t *testing.T) {
+	t.Run("when server returns a graph, it returns that as is", func(t *testing.T) {
+		expectedResponse := lineage.Graph{
+			Root: "knit-1",
+			Data: []data.Detail{
+				{
+					KnitId: "knit-1",
+					Upstream: data.CreatedFrom{
+						Run: runs.Summary{RunId: "run-upload", Status: "done"},
+					},
+					Downstreams: []data.AssignedTo{
+						{Run: runs.Summary{RunId: "run-train", Status: "done"}},
+					},
+				},
+			},
+			Runs: []runs.Detail{
+				{
+					Summary: runs.Summary{
+						RunId: "run-train", Status: "done",
+						Plan: plans.Summary{PlanId: "plan-train"},
+					},
+					Inputs: []runs.Assignment{{KnitId: "knit-1"}},
+				},
+			},
+		}
+
+		for name, testcase := range map[string]struct {
+			param    krst.LineageParameter
+			expected url.Values
+		}{
+			"upstream with depth": {
+				param:    krst.LineageParameter{Upstream: true, Depth: pointer.Ref[uint](2)},
+				expected: url.Values{"upstream": {"true"}, "depth": {"2"}},
+			},
+			"downstream without limit": {
+				param:    krst.LineageParameter{Downstream: true},
+				expected: url.Values{"downstream": {"true"}, "depth": {"all"}},
+			},
+			"both directions": {
+				param: krst.LineageParameter{Upstream: true, Downstream: true, Depth: pointer.Ref[uint](1)},
+				expected: url.Values{
+					"upstream": {"true"}, "downstream": {"true"}, "depth": {"1"},
+				},
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				var request *http.Request
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					request = r
+					w.Header().Add("Content-Type", "application/json")
+					w.WriteHeader(http.StatusOK)
+					w.Write(try.To(json.Marshal(expectedResponse)).OrFatal(t))
+				}))
+				defer server.Close()
+
+				profile := kprof.KnitProfile{ApiRoot: server.URL}
+				testee := try.To(krst.NewClient(&profile)).OrFatal(t)
+
+				actual := try.To(testee.GetLineage(context.Background(), "knit-1", testcase.param)).OrFatal(t)
+				if !actual.Equal(expectedResponse) {
+					t.Errorf("response is not equal (actual,expected): %v,%v", actual, expectedResponse)
+				}
+
+				if request.Method != http.MethodGet {
+					t.Errorf("request is not GET (actual method = %s)", request.Method)
+				}
+				if request.URL.Path != "/lineage/knit-1" {
+					t.Errorf("request is not /lineage/knit-1 (actual path = %s)", request.URL.Path)
+				}
+				if q := request.URL.Query(); q.Encode() != testcase.expected.Encode() {
+					t.Errorf("query: (actual, expected) = (%s, %s)", q.Encode(), testcase.expected.Encode())
+				}
+			})
+		}
+	})
+
+	for _, status := range []int{http.StatusNotFound, http.StatusInternalServerError} {
+		t.Run(fmt.Sprintf("when server responding with %d, it returns error", status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				w.Write(try.To(json.Marshal(apierr.ErrorMessage{Reason: "something wrong"})).OrFatal(t))
+			}))
+			defer server.Close()
+
+			profile := kprof.KnitProfile{ApiRoot: server.URL}
+			testee := try.To(krst.NewClient(&profile)).OrFatal(t)
+			if _, err := testee.GetLineage(context.Background(), "knit-1", krst.LineageParameter{}); err == nil {
+				t.Errorf("no error occured")
+			}
+		})
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"testing"
 	"time"
 
+	apiaudit "github.com/opst/knitfab-api-types/audit"
+	apiauth "github.com/opst/knitfab-api-types/auth"
 	"github.com/opst/knitfab-api-types/data"
+	"github.com/opst/knitfab-api-types/lineage"
 	"github.com/opst/knitfab-api-types/plans"
 	"github.com/opst/knitfab-api-types/runs"
 	apitags "github.com/opst/knitfab-api-types/tags"
@@ -37,6 +40,11 @@ type FindDataArgs struct {
 	duration *time.Duration
 }
 
+type GetLineageArgs struct {
+	KnitId string
+	Param  rest.LineageParameter
+}
+
 type FindRunArgs struct {
 	planId    []string
 	KnitIdIn  []string
@@ -46,11 +54,27 @@ type FindRunArgs struct {
 	duration  time.Duration
 }
 
+type UpdatePriorityArgs struct {
+	PlanId string
+	Change plans.PriorityChange
+}
+
 type UpdateAnnotationsArgs struct {
 	PlanId      string
 	Annotations plans.AnnotationChange
 }
 
+type PreviewTagsForDataArgs struct {
+	KnitId string
+	Tags   apitags.Change
+}
+
+type SupersedePlanArgs struct {
+	Predecessor string
+	Spec        plans.PlanSpec
+	Reroute     bool
+}
+
 func New(t *testing.T) *mockKnitClient {
 	return &mockKnitClient{t: t}
 }
@@ -104,38 +128,54 @@ func (m *MockedPostDataProgress) Sent() <-chan struct{} {
 type mockKnitClient struct {
 	t    *testing.T
 	Impl struct {
-		PostData       func(ctx context.Context, source string, dereference bool) rest.Progress[*data.Detail]
-		PutTagsForData func(knitId string, tags apitags.Change) (*data.Detail, error)
-		GetDataRaw     func(context.Context, string, func(io.Reader) error) error
-		GetData        func(context.Context, string, func(rest.FileEntry) error) error
-		FindData       func(ctx context.Context, tags []apitags.Tag, since *time.Time, duration *time.Duration) ([]data.Detail, error)
+		PostData           func(ctx context.Context, source string, dereference bool) rest.Progress[*data.Detail]
+		PutTagsForData     func(knitId string, tags apitags.Change) (*data.Detail, error)
+		PreviewTagsForData func(ctx context.Context, knitId string, tags apitags.Change) ([]runs.Preview, error)
+		GetDataRaw         func(context.Context, string, func(io.Reader) error) error
+		GetData            func(context.Context, string, func(rest.FileEntry) error) error
+		FindData           func(ctx context.Context, tags []apitags.Tag, since *time.Time, duration *time.Duration, handler func([]data.Detail) error) error
+		DeleteData         func(ctx context.Context, knitId string) error
+		GetLineage         func(ctx context.Context, knitId string, param rest.LineageParameter) (lineage.Graph, error)
 
 		GetPlans func(ctx context.Context, planId string) (plans.Detail, error)
 		FindPlan func(
 			ctx context.Context, active logic.Ternary, imageVer *domain.ImageIdentifier,
 			inTags []apitags.Tag, outTags []apitags.Tag,
-		) ([]plans.Detail, error)
+			handler func([]plans.Detail) error,
+		) error
 		PutPlanForActivate  func(ctx context.Context, planId string, isActive bool) (plans.Detail, error)
 		UpdateResources     func(ctx context.Context, runId string, resources plans.ResourceLimitChange) (plans.Detail, error)
 		RegisterPlan        func(ctx context.Context, spec plans.PlanSpec) (plans.Detail, error)
+		SupersedePlan       func(ctx context.Context, predecessor string, spec plans.PlanSpec, reroute bool) (plans.Detail, error)
+		PreviewPlan         func(ctx context.Context, spec plans.PlanSpec) ([]runs.Preview, error)
+		DeletePlan          func(ctx context.Context, planId string) error
 		UpdateAnnotations   func(ctx context.Context, planId string, annotations plans.AnnotationChange) (plans.Detail, error)
 		SetServiceAccount   func(ctx context.Context, planId string, serviceAccount plans.SetServiceAccount) (plans.Detail, error)
 		UnsetServiceAccount func(ctx context.Context, planId string) (plans.Detail, error)
+		UpdatePriority      func(ctx context.Context, planId string, change plans.PriorityChange) (plans.Detail, error)
 
 		GetRun    func(ctx context.Context, runId string) (runs.Detail, error)
 		GetRunLog func(ctx context.Context, runId string, follow bool) (io.ReadCloser, error)
-		FindRun   func(ctx context.Context, query rest.FindRunParameter) ([]runs.Detail, error)
+		FindRun   func(ctx context.Context, query rest.FindRunParameter, handler func([]runs.Detail) error) error
 		Abort     func(ctx context.Context, runId string) (runs.Detail, error)
 		Tearoff   func(ctx context.Context, runId string) (runs.Detail, error)
 		DeleteRun func(ctx context.Context, runId string) error
 		Retry     func(ctx context.Context, runId string) error
+
+		IssueToken   func(ctx context.Context, email string, password string) (apiauth.Token, error)
+		RefreshToken func(ctx context.Context) (apiauth.Token, error)
+
+		FindAudit func(ctx context.Context, query rest.FindAuditParameter) ([]apiaudit.Entry, error)
 	}
 	Calls struct {
-		PostData       []PostDataArgs
-		PutTagsForData []PutTagsForDataArgs
-		GetDataRaw     []string
-		GetData        []string
-		FindData       []FindDataArgs
+		PostData           []PostDataArgs
+		PutTagsForData     []PutTagsForDataArgs
+		PreviewTagsForData []PreviewTagsForDataArgs
+		GetDataRaw         []string
+		GetData            []string
+		FindData           []FindDataArgs
+		DeleteData         []string
+		GetLineage         []GetLineageArgs
 
 		GetPlans           []string
 		Findplan           []FindPlanArgs
@@ -150,7 +190,11 @@ type mockKnitClient struct {
 			ServiceAccount plans.SetServiceAccount
 		}
 		UnsetServiceAccount []string
+		UpdatePriority      []UpdatePriorityArgs
 		RegisterPlan        []plans.PlanSpec
+		SupersedePlan       []SupersedePlanArgs
+		PreviewPlan         []plans.PlanSpec
+		DeletePlan          []string
 
 		GetRun    []string
 		GetRunLog []struct {
@@ -162,6 +206,11 @@ type mockKnitClient struct {
 		Abort     []string
 		DeleteRun []string
 		Retry     []string
+
+		IssueToken   []string
+		RefreshToken int
+
+		FindAudit []rest.FindAuditParameter
 	}
 }
 
@@ -188,6 +237,17 @@ func (m *mockKnitClient) PutTagsForData(knitId string, argtags apitags.Change) (
 	return m.Impl.PutTagsForData(knitId, argtags)
 }
 
+func (m *mockKnitClient) PreviewTagsForData(ctx context.Context, knitId string, argtags apitags.Change) ([]runs.Preview, error) {
+	m.t.Helper()
+
+	m.Calls.PreviewTagsForData = append(m.Calls.PreviewTagsForData, PreviewTagsForDataArgs{KnitId: knitId, Tags: argtags})
+
+	if m.Impl.PreviewTagsForData == nil {
+		m.t.Fatal("PreviewTagsForData is not ready to be called")
+	}
+	return m.Impl.PreviewTagsForData(ctx, knitId, argtags)
+}
+
 func (m *mockKnitClient) GetDataRaw(ctx context.Context, knitId string, handler func(io.Reader) error) error {
 	m.t.Helper()
 
@@ -208,7 +268,7 @@ func (m *mockKnitClient) GetData(ctx context.Context, knitId string, handler fun
 	return m.Impl.GetData(ctx, knitId, handler)
 }
 
-func (m *mockKnitClient) FindData(ctx context.Context, tags []apitags.Tag, since *time.Time, duration *time.Duration) ([]data.Detail, error) {
+func (m *mockKnitClient) FindData(ctx context.Context, tags []apitags.Tag, since *time.Time, duration *time.Duration, handler func([]data.Detail) error) error {
 	m.t.Helper()
 
 	m.Calls.FindData = append(
@@ -219,7 +279,27 @@ func (m *mockKnitClient) FindData(ctx context.Context, tags []apitags.Tag, since
 	if m.Impl.FindData == nil {
 		m.t.Fatal("FindData is not ready to be called")
 	}
-	return m.Impl.FindData(ctx, tags, since, duration)
+	return m.Impl.FindData(ctx, tags, since, duration, handler)
+}
+
+func (m *mockKnitClient) DeleteData(ctx context.Context, knitId string) error {
+	m.t.Helper()
+
+	m.Calls.DeleteData = append(m.Calls.DeleteData, knitId)
+	if m.Impl.DeleteData == nil {
+		m.t.Fatal("DeleteData is not ready to be called")
+	}
+	return m.Impl.DeleteData(ctx, knitId)
+}
+
+func (m *mockKnitClient) GetLineage(ctx context.Context, knitId string, param rest.LineageParameter) (lineage.Graph, error) {
+	m.t.Helper()
+
+	m.Calls.GetLineage = append(m.Calls.GetLineage, GetLineageArgs{KnitId: knitId, Param: param})
+	if m.Impl.GetLineage == nil {
+		m.t.Fatal("GetLineage is not ready to be called")
+	}
+	return m.Impl.GetLineage(ctx, knitId, param)
 }
 
 func (m *mockKnitClient) GetPlans(ctx context.Context, planId string) (plans.Detail, error) {
@@ -238,14 +318,15 @@ func (m *mockKnitClient) FindPlan(
 	imageVer *domain.ImageIdentifier,
 	inTags []apitags.Tag,
 	outTags []apitags.Tag,
-) ([]plans.Detail, error) {
+	handler func([]plans.Detail) error,
+) error {
 	m.t.Helper()
 
 	m.Calls.Findplan = append(m.Calls.Findplan, FindPlanArgs{active, imageVer, inTags, outTags})
 	if m.Impl.FindPlan == nil {
 		m.t.Fatal("FindPlan is not ready to be called")
 	}
-	return m.Impl.FindPlan(ctx, active, imageVer, inTags, outTags)
+	return m.Impl.FindPlan(ctx, active, imageVer, inTags, outTags, handler)
 }
 
 func (m *mockKnitClient) PutPlanForActivate(ctx context.Context, planId string, isActive bool) (plans.Detail, error) {
@@ -268,6 +349,38 @@ func (m *mockKnitClient) RegisterPlan(ctx context.Context, spec plans.PlanSpec)
 	return m.Impl.RegisterPlan(ctx, spec)
 }
 
+func (m *mockKnitClient) SupersedePlan(ctx context.Context, predecessor string, spec plans.PlanSpec, reroute bool) (plans.Detail, error) {
+	m.t.Helper()
+
+	m.Calls.SupersedePlan = append(m.Calls.SupersedePlan, SupersedePlanArgs{
+		Predecessor: predecessor, Spec: spec, Reroute: reroute,
+	})
+	if m.Impl.SupersedePlan == nil {
+		m.t.Fatal("SupersedePlan is not ready to be called")
+	}
+	return m.Impl.SupersedePlan(ctx, predecessor, spec, reroute)
+}
+
+func (m *mockKnitClient) PreviewPlan(ctx context.Context, spec plans.PlanSpec) ([]runs.Preview, error) {
+	m.t.Helper()
+
+	m.Calls.PreviewPlan = append(m.Calls.PreviewPlan, spec)
+	if m.Impl.PreviewPlan == nil {
+		m.t.Fatal("PreviewPlan is not ready to be called")
+	}
+	return m.Impl.PreviewPlan(ctx, spec)
+}
+
+func (m *mockKnitClient) DeletePlan(ctx context.Context, planId string) error {
+	m.t.Helper()
+
+	m.Calls.DeletePlan = append(m.Calls.DeletePlan, planId)
+	if m.Impl.DeletePlan == nil {
+		m.t.Fatal("DeletePlan is not ready to be called")
+	}
+	return m.Impl.DeletePlan(ctx, planId)
+}
+
 func (m *mockKnitClient) UpdateResources(ctx context.Context, runId string, resources plans.ResourceLimitChange) (plans.Detail, error) {
 	m.t.Helper()
 
@@ -314,6 +427,16 @@ func (m *mockKnitClient) UnsetServiceAccount(ctx context.Context, planId string)
 	return m.Impl.UnsetServiceAccount(ctx, planId)
 }
 
+func (m *mockKnitClient) UpdatePriority(ctx context.Context, planId string, change plans.PriorityChange) (plans.Detail, error) {
+	m.t.Helper()
+
+	m.Calls.UpdatePriority = append(m.Calls.UpdatePriority, UpdatePriorityArgs{PlanId: planId, Change: change})
+	if m.Impl.UpdatePriority == nil {
+		m.t.Fatal("UpdatePriority is not ready to be called")
+	}
+	return m.Impl.UpdatePriority(ctx, planId, change)
+}
+
 func (m *mockKnitClient) GetRun(ctx context.Context, runId string) (runs.Detail, error) {
 	m.t.Helper()
 
@@ -343,7 +466,8 @@ func (m *mockKnitClient) GetRunLog(ctx context.Context, runId string, follow boo
 func (m *mockKnitClient) FindRun(
 	ctx context.Context,
 	query rest.FindRunParameter,
-) ([]runs.Detail, error) {
+	handler func([]runs.Detail) error,
+) error {
 	m.t.Helper()
 
 	m.Calls.FindRun = append(
@@ -353,7 +477,7 @@ func (m *mockKnitClient) FindRun(
 	if m.Impl.FindRun == nil {
 		m.t.Fatal("FindRun is not ready to be called")
 	}
-	return m.Impl.FindRun(ctx, query)
+	return m.Impl.FindRun(ctx, query, handler)
 }
 
 func (m *mockKnitClient) Abort(ctx context.Context, runId string) (runs.Detail, error) {
@@ -395,3 +519,33 @@ func (m *mockKnitClient) Retry(ctx context.Context, runId string) error {
 	}
 	return m.Impl.Retry(ctx, runId)
 }
+
+func (m *mockKnitClient) IssueToken(ctx context.Context, email string, password string) (apiauth.Token, error) {
+	m.t.Helper()
+
+	m.Calls.IssueToken = append(m.Calls.IssueToken, email)
+	if m.Impl.IssueToken == nil {
+		m.t.Fatal("IssueToken is not ready to be called")
+	}
+	return m.Impl.IssueToken(ctx, email, password)
+}
+
+func (m *mockKnitClient) RefreshToken(ctx context.Context) (apiauth.Token, error) {
+	m.t.Helper()
+
+	m.Calls.RefreshToken += 1
+	if m.Impl.RefreshToken == nil {
+		m.t.Fatal("RefreshToken is not ready to be called")
+	}
+	return m.Impl.RefreshToken(ctx)
+}
+
+func (m *mockKnitClient) FindAudit(ctx context.Context, query rest.FindAuditParameter) ([]apiaudit.Entry, error) {
+	m.t.Helper()
+
+	m.Calls.FindAudit = append(m.Calls.FindAudit, query)
+	if m.Impl.FindAudit == nil {
+		m.t.Fatal("FindAudit is not ready to be called")
+	}
+	return m.Impl.FindAudit(ctx, query)
+}
@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// findPageSize is the number of items requested at once by FindData, FindPlan and FindRun.
+const findPageSize = 500
+
+// findPages requests items page by page following "next" links,
+// and passes each page to handler as soon as it is received.
+//
+// # Args
+//
+// - ctx
+//
+// - c: client to send requests
+//
+// - path: path of the API
+//
+// - q: query of the request. "limit" and "cursor" are overwritten.
+//
+// - handler: called with items in each page, in order.
+// When it returns an error, findPages stops requesting pages and returns the error.
+func findPages[T any](ctx context.Context, c *client, path string, q url.Values, handler func([]T) error) error {
+	q.Set("limit", strconv.Itoa(findPageSize))
+	q.Del("cursor")
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return err
+		}
+		req.URL.RawQuery = q.Encode()
+
+		resp, err := c.httpclient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		page := make([]T, 0, findPageSize)
+		err = unmarshalJsonResponse(
+			resp, &page,
+			MessageFor{
+				Status4xx: fmt.Sprintf("[BUG] client is not compatible with the server (status code = %d)", resp.StatusCode),
+				Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
+			},
+		)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if err := handler(page); err != nil {
+			return err
+		}
+
+		next := nextCursor(resp)
+		if next == "" {
+			return nil
+		}
+		q.Set("cursor", next)
+	}
+}
+
+// nextCursor returns the cursor in the "next" link of the "Link" header.
+//
+// It returns empty string when there are no next pages.
+func nextCursor(resp *http.Response) string {
+	for _, header := range resp.Header.Values("Link") {
+		for _, link := range strings.Split(header, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok {
+				continue
+			}
+
+			next := false
+			for _, p := range strings.Split(params, ";") {
+				if k, v, _ := strings.Cut(strings.TrimSpace(p), "="); k == "rel" && strings.Trim(v, `"`) == "next" {
+					next = true
+				}
+			}
+			if !next {
+				continue
+			}
+
+			u, err := url.Parse(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(target), "<"), ">"))
+			if err != nil {
+				continue
+			}
+			if cursor := u.Query().Get("cursor"); cursor != "" {
+				return cursor
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opst/knitfab-api-types/runs"
+	kprof "github.com/opst/knitfab/cmd/knit/config/profiles"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestFind_FollowsPages(t *testing.T) {
+	// pages of Runs, keyed by the cursor to request them.
+	pages := map[string][]runs.Detail{
+		"":         {{Summary: runs.Summary{RunId: "run-1"}}, {Summary: runs.Summary{RunId: "run-2"}}},
+		"cursor-2": {{Summary: runs.Summary{RunId: "run-3"}}, {Summary: runs.Summary{RunId: "run-4"}}},
+		"cursor-3": {{Summary: runs.Summary{RunId: "run-5"}}},
+	}
+	links := map[string]string{
+		"":         "cursor-2",
+		"cursor-2": "cursor-3",
+	}
+
+	requested := []string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		cursor := q.Get("cursor")
+		requested = append(requested, cursor)
+
+		if q.Get("limit") == "" {
+			t.Errorf("limit is not requested: %s", r.URL)
+		}
+		if status := q.Get("status"); status != "done" {
+			t.Errorf("query is not kept: %s", r.URL)
+		}
+
+		if next, ok := links[cursor]; ok {
+			q.Set("cursor", next)
+			w.Header().Add("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, q.Encode()))
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.Write(try.To(json.Marshal(pages[cursor])).OrFatal(t))
+	}))
+	defer ts.Close()
+
+	profile := kprof.KnitProfile{ApiRoot: ts.URL}
+	testee := try.To(krst.NewClient(&profile)).OrFatal(t)
+
+	t.Run("it passes each page to the handler in order", func(t *testing.T) {
+		requested = []string{}
+
+		actual := [][]runs.Detail{}
+		if err := testee.FindRun(
+			context.Background(), krst.FindRunParameter{Status: []string{"done"}},
+			func(page []runs.Detail) error {
+				actual = append(actual, page)
+				return nil
+			},
+		); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := [][]runs.Detail{pages[""], pages["cursor-2"], pages["cursor-3"]}
+		if !cmp.SliceEqWith(actual, expected, func(a, b []runs.Detail) bool {
+			return cmp.SliceEqWith(a, b, runs.Detail.Equal)
+		}) {
+			t.Errorf("pages: actual = %+v, expected = %+v", actual, expected)
+		}
+		if want := []string{"", "cursor-2", "cursor-3"}; !cmp.SliceEq(requested, want) {
+			t.Errorf("requested cursors: actual = %v, expected = %v", requested, want)
+		}
+	})
+
+	t.Run("when the handler returns an error, it stops requesting pages", func(t *testing.T) {
+		requested = []string{}
+		expectedErr := errors.New("fake error")
+
+		err := testee.FindRun(
+			context.Background(), krst.FindRunParameter{Status: []string{"done"}},
+			func(page []runs.Detail) error { return expectedErr },
+		)
+		if !errors.Is(err, expectedErr) {
+			t.Errorf("error: actual = %v, expected = %v", err, expectedErr)
+		}
+		if want := []string{""}; !cmp.SliceEq(requested, want) {
+			t.Errorf("requested cursors: actual = %v, expected = %v", requested, want)
+		}
+	})
+}
+
+// collect returns a handler of pages appending items in each page to found.
+func collect[T any](found *[]T) func([]T) error {
+	return func(page []T) error {
+		*found = append(*found, page...)
+		return nil
+	}
+}
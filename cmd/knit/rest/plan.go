@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab-api-types/runs"
 	"github.com/opst/knitfab-api-types/tags"
 	"github.com/opst/knitfab/pkg/domain"
 	"github.com/opst/knitfab/pkg/utils/logic"
@@ -87,19 +89,109 @@ func (c *client) RegisterPlan(ctx context.Context, spec plans.PlanSpec) (plans.D
 	return dataMetas, nil
 }
 
+func (c *client) SupersedePlan(ctx context.Context, predecessor string, spec plans.PlanSpec, reroute bool) (plans.Detail, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return plans.Detail{}, err
+	}
+
+	path := c.apipath("plans", predecessor, "successor")
+	if reroute {
+		path += "?reroute=true"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, path, bytes.NewBuffer(b))
+	if err != nil {
+		return plans.Detail{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpclient.Do(req)
+	if err != nil {
+		return plans.Detail{}, err
+	}
+	defer resp.Body.Close()
+
+	var dataMetas plans.Detail
+	if err := unmarshalJsonResponse(
+		resp, &dataMetas,
+		MessageFor{
+			Status4xx: fmt.Sprintf("planId:%v cannot be superseded", predecessor),
+			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
+		},
+	); err != nil {
+		return plans.Detail{}, err
+	}
+	return dataMetas, nil
+}
+
+func (c *client) PreviewPlan(ctx context.Context, spec plans.PlanSpec) ([]runs.Preview, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apipath("plans", "preview"), bytes.NewBuffer(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpclient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	previews := []runs.Preview{}
+	if err := unmarshalJsonResponse(
+		resp, &previews,
+		MessageFor{
+			Status4xx: "invalid request",
+			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
+		},
+	); err != nil {
+		return nil, err
+	}
+	return previews, nil
+}
+
+func (c *client) DeletePlan(ctx context.Context, planId string) error {
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodDelete, c.apipath("plans", planId), nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpclient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := unmarshalResponseDiscardingPayload(
+		resp,
+		MessageFor{
+			Status4xx: fmt.Sprintf("planId:%v cannot be deleted", planId),
+			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
+		},
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (c *client) FindPlan(
 	ctx context.Context,
 	active logic.Ternary,
 	imageVer *domain.ImageIdentifier,
 	inTags []tags.Tag,
 	outTags []tags.Tag,
-) ([]plans.Detail, error) {
+	handler func([]plans.Detail) error,
+) error {
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apipath("plans"), nil)
-	if err != nil {
-		return nil, err
-	}
-	q := req.URL.Query()
+	q := url.Values{}
 
 	switch active {
 	case logic.True:
@@ -128,34 +220,47 @@ func (c *client) FindPlan(
 		}
 	}
 
-	req.URL.RawQuery = q.Encode()
+	return findPages(ctx, c, c.apipath("plans"), q, handler)
+}
+
+func (c *client) UpdateResources(ctx context.Context, planId string, res plans.ResourceLimitChange) (plans.Detail, error) {
+	b, err := json.Marshal(res)
+	if err != nil {
+		return plans.Detail{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, c.apipath("plans", planId, "resources"), bytes.NewBuffer(b))
+	if err != nil {
+		return plans.Detail{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
 	resp, err := c.httpclient.Do(req)
 	if err != nil {
-		return nil, err
+		return plans.Detail{}, err
 	}
 	defer resp.Body.Close()
 
-	dataMetas := make([]plans.Detail, 0, 5)
+	var dataMetas plans.Detail
 	if err := unmarshalJsonResponse(
 		resp, &dataMetas,
 		MessageFor{
-			Status4xx: fmt.Sprintf("[BUG] client is not compatible with the server (status code = %d)", resp.StatusCode),
+			Status4xx: fmt.Sprintf("planId:%v cannot be updated", planId),
 			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
 		},
 	); err != nil {
-		return nil, err
+		return plans.Detail{}, err
 	}
-
 	return dataMetas, nil
 }
 
-func (c *client) UpdateResources(ctx context.Context, planId string, res plans.ResourceLimitChange) (plans.Detail, error) {
-	b, err := json.Marshal(res)
+func (c *client) UpdateAnnotations(ctx context.Context, planId string, change plans.AnnotationChange) (plans.Detail, error) {
+	b, err := json.Marshal(change)
 	if err != nil {
 		return plans.Detail{}, err
 	}
 
-	req, err := http.NewRequest(http.MethodPut, c.apipath("plans", planId, "resources"), bytes.NewBuffer(b))
+	req, err := http.NewRequest(http.MethodPut, c.apipath("plans", planId, "annotations"), bytes.NewBuffer(b))
 	if err != nil {
 		return plans.Detail{}, err
 	}
@@ -180,13 +285,13 @@ func (c *client) UpdateResources(ctx context.Context, planId string, res plans.R
 	return dataMetas, nil
 }
 
-func (c *client) UpdateAnnotations(ctx context.Context, planId string, change plans.AnnotationChange) (plans.Detail, error) {
-	b, err := json.Marshal(change)
+func (c *client) SetServiceAccount(ctx context.Context, planId string, setServiceAccount plans.SetServiceAccount) (plans.Detail, error) {
+	b, err := json.Marshal(setServiceAccount)
 	if err != nil {
 		return plans.Detail{}, err
 	}
 
-	req, err := http.NewRequest(http.MethodPut, c.apipath("plans", planId, "annotations"), bytes.NewBuffer(b))
+	req, err := http.NewRequest(http.MethodPut, c.apipath("plans", planId, "serviceaccount"), bytes.NewBuffer(b))
 	if err != nil {
 		return plans.Detail{}, err
 	}
@@ -211,13 +316,13 @@ func (c *client) UpdateAnnotations(ctx context.Context, planId string, change pl
 	return dataMetas, nil
 }
 
-func (c *client) SetServiceAccount(ctx context.Context, planId string, setServiceAccount plans.SetServiceAccount) (plans.Detail, error) {
-	b, err := json.Marshal(setServiceAccount)
+func (c *client) UpdatePriority(ctx context.Context, planId string, change plans.PriorityChange) (plans.Detail, error) {
+	b, err := json.Marshal(change)
 	if err != nil {
 		return plans.Detail{}, err
 	}
 
-	req, err := http.NewRequest(http.MethodPut, c.apipath("plans", planId, "serviceaccount"), bytes.NewBuffer(b))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.apipath("plans", planId, "priority"), bytes.NewBuffer(b))
 	if err != nil {
 		return plans.Detail{}, err
 	}
@@ -229,9 +334,9 @@ func (c *client) SetServiceAccount(ctx context.Context, planId string, setServic
 	}
 	defer resp.Body.Close()
 
-	var dataMetas plans.Detail
+	var detail plans.Detail
 	if err := unmarshalJsonResponse(
-		resp, &dataMetas,
+		resp, &detail,
 		MessageFor{
 			Status4xx: fmt.Sprintf("planId:%v cannot be updated", planId),
 			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
@@ -239,7 +344,7 @@ func (c *client) SetServiceAccount(ctx context.Context, planId string, setServic
 	); err != nil {
 		return plans.Detail{}, err
 	}
-	return dataMetas, nil
+	return detail, nil
 }
 
 func (c *client) UnsetServiceAccount(ctx context.Context, planId string) (plans.Detail, error) {
@@ -16,6 +16,7 @@ import (
 
 	apierr "github.com/opst/knitfab-api-types/errors"
 	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab-api-types/runs"
 	"github.com/opst/knitfab-api-types/tags"
 	"github.com/opst/knitfab/pkg/domain"
 	"github.com/opst/knitfab/pkg/utils/cmp"
@@ -760,9 +761,12 @@ func TestFindPlan(t *testing.T) {
 
 				//test start
 				testee := try.To(krst.NewClient(&profile)).OrFatal(t)
-				result := try.To(testee.FindPlan(
-					ctx, when.active, when.imageVer, when.inTags, when.outTags,
-				)).OrFatal(t)
+				result := []plans.Detail{}
+				if err := testee.FindPlan(
+					ctx, when.active, when.imageVer, when.inTags, when.outTags, collect(&result),
+				); err != nil {
+					t.Fatal(err)
+				}
 
 				// check response
 				if !cmp.SliceContentEqWith(result, response, plans.Detail.Equal) {
@@ -839,7 +843,12 @@ func TestFindPlan(t *testing.T) {
 			}
 			//test start
 			testee := try.To(krst.NewClient(&profile)).OrFatal(t)
-			actualResponse := try.To(testee.FindPlan(ctx, queryActive, queryImagever, queryInTags, queryOutTags)).OrFatal(t)
+			actualResponse := []plans.Detail{}
+			if err := testee.FindPlan(
+				ctx, queryActive, queryImagever, queryInTags, queryOutTags, collect(&actualResponse),
+			); err != nil {
+				t.Fatal(err)
+			}
 
 			if !cmp.SliceContentEqWith(actualResponse, expectedResponse, plans.Detail.Equal) {
 				t.Errorf(
@@ -892,7 +901,9 @@ func TestFindPlan(t *testing.T) {
 					{Key: "tag-b", Value: "value-b"},
 				}
 
-				if _, err := testee.FindPlan(ctx, queryActive, queryImagever, queryInTags, queryOutTags); err == nil {
+				if err := testee.FindPlan(
+					ctx, queryActive, queryImagever, queryInTags, queryOutTags, collect(&[]plans.Detail{}),
+				); err == nil {
 					t.Errorf("no error occured")
 				}
 			})
@@ -1509,3 +1520,254 @@ func TestUnsetServiceAccount_error_case(t *testing.T) {
 		statusCode: http.StatusInternalServerError,
 	}))
 }
+
+func TestSupersedePlan(t *testing.T) {
+	spec := plans.PlanSpec{
+		Image: plans.Image{Repository: "repo.invalid/image", Tag: "0.2.0"},
+		Inputs: []plans.Mountpoint{
+			{Path: "/in", Tags: []tags.Tag{{Key: "type", Value: "raw"}}},
+		},
+	}
+
+	for name, reroute := range map[string]bool{
+		"with reroute":    true,
+		"without reroute": false,
+	} {
+		t.Run("when server responses successfully "+name+", it returns the successor", func(t *testing.T) {
+			expected := plans.Detail{
+				Summary:    plans.Summary{PlanId: "plan-2", Image: &spec.Image},
+				Active:     true,
+				Supersedes: "plan-1",
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("unexpected method: %s", r.Method)
+				}
+				if !strings.HasSuffix(r.URL.Path, "/plans/plan-1/successor") {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+				if got := r.URL.Query().Get("reroute") == "true"; got != reroute {
+					t.Errorf("unexpected reroute: %s", r.URL.RawQuery)
+				}
+
+				actual := plans.PlanSpec{}
+				if err := json.NewDecoder(r.Body).Decode(&actual); err != nil {
+					t.Fatal(err)
+				}
+				if !actual.Equal(spec) {
+					t.Errorf("unexpected spec: %+v", actual)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				json.NewEncoder(w).Encode(expected)
+			}))
+			defer server.Close()
+
+			prof := kprof.KnitProfile{ApiRoot: server.URL}
+			testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+			actual, err := testee.SupersedePlan(context.Background(), "plan-1", spec, reroute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !actual.Equal(expected) {
+				t.Errorf("unexpected response: %+v", actual)
+			}
+		})
+	}
+
+	for _, status := range []int{http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError} {
+		t.Run(fmt.Sprintf("when server responses with %d, it returns error", status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(apierr.ErrorMessage{Reason: "something wrong"})
+			}))
+			defer server.Close()
+
+			prof := kprof.KnitProfile{ApiRoot: server.URL}
+			testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+			if _, err := testee.SupersedePlan(context.Background(), "plan-1", spec, false); err == nil {
+				t.Errorf("no error occured")
+			}
+		})
+	}
+}
+
+func TestDeletePlan(t *testing.T) {
+	t.Run("when server responses without error, it returns nil", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodDelete {
+				t.Errorf("unexpected method: %s", r.Method)
+			}
+			if !strings.HasSuffix(r.URL.Path, "/plans/plan-1") {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		prof := kprof.KnitProfile{ApiRoot: server.URL}
+		testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+		if err := testee.DeletePlan(context.Background(), "plan-1"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	for _, status := range []int{http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError} {
+		t.Run(fmt.Sprintf("when server responses with %d, it returns error", status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(apierr.ErrorMessage{Reason: "something wrong"})
+			}))
+			defer server.Close()
+
+			prof := kprof.KnitProfile{ApiRoot: server.URL}
+			testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+			if err := testee.DeletePlan(context.Background(), "plan-1"); err == nil {
+				t.Errorf("no error occured")
+			}
+		})
+	}
+}
+
+func TestPreviewPlan(t *testing.T) {
+	spec := plans.PlanSpec{
+		Image: plans.Image{Repository: "repo.invalid/image", Tag: "0.2.0"},
+		Inputs: []plans.Mountpoint{
+			{Path: "/in", Tags: []tags.Tag{{Key: "type", Value: "raw"}}},
+		},
+	}
+
+	t.Run("when server responses successfully, it returns previews", func(t *testing.T) {
+		expected := []runs.Preview{
+			{
+				Plan: plans.Summary{PlanId: "plan-tmp", Image: &spec.Image},
+				Inputs: []runs.Assignment{
+					{Mountpoint: spec.Inputs[0], KnitId: "knit-1"},
+				},
+			},
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				t.Errorf("unexpected method: %s", r.Method)
+			}
+			if !strings.HasSuffix(r.URL.Path, "/plans/preview") {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+
+			actual := plans.PlanSpec{}
+			if err := json.NewDecoder(r.Body).Decode(&actual); err != nil {
+				t.Fatal(err)
+			}
+			if !actual.Equal(spec) {
+				t.Errorf("unexpected spec: %+v", actual)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(expected)
+		}))
+		defer server.Close()
+
+		prof := kprof.KnitProfile{ApiRoot: server.URL}
+		testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+		actual, err := testee.PreviewPlan(context.Background(), spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.SliceContentEqWith(actual, expected, runs.Preview.Equal) {
+			t.Errorf("unexpected response: %+v", actual)
+		}
+	})
+
+	for _, status := range []int{http.StatusBadRequest, http.StatusConflict, http.StatusInternalServerError} {
+		t.Run(fmt.Sprintf("when server responses with %d, it returns error", status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(apierr.ErrorMessage{Reason: "something wrong"})
+			}))
+			defer server.Close()
+
+			prof := kprof.KnitProfile{ApiRoot: server.URL}
+			testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+			if _, err := testee.PreviewPlan(context.Background(), spec); err == nil {
+				t.Errorf("no error occured")
+			}
+		})
+	}
+}
+
+func TestUpdatePriority(t *testing.T) {
+	priority := 10
+	maxConcurrency := 2
+	change := plans.PriorityChange{Priority: &priority, MaxConcurrency: &maxConcurrency}
+
+	t.Run("when server responses successfully, it returns the updated plan", func(t *testing.T) {
+		expected := plans.Detail{
+			Summary:        plans.Summary{PlanId: "plan-1"},
+			Active:         true,
+			Priority:       priority,
+			MaxConcurrency: maxConcurrency,
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPut {
+				t.Errorf("unexpected method: %s", r.Method)
+			}
+			if !strings.HasSuffix(r.URL.Path, "/plans/plan-1/priority") {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+
+			actual := plans.PriorityChange{}
+			if err := json.NewDecoder(r.Body).Decode(&actual); err != nil {
+				t.Fatal(err)
+			}
+			if actual.Priority == nil || *actual.Priority != priority ||
+				actual.MaxConcurrency == nil || *actual.MaxConcurrency != maxConcurrency {
+				t.Errorf("unexpected change: %+v", actual)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(expected)
+		}))
+		defer server.Close()
+
+		prof := kprof.KnitProfile{ApiRoot: server.URL}
+		testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+		actual, err := testee.UpdatePriority(context.Background(), "plan-1", change)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !actual.Equal(expected) {
+			t.Errorf("unexpected response: %+v", actual)
+		}
+	})
+
+	for _, status := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError} {
+		t.Run(fmt.Sprintf("when server responses with %d, it returns error", status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				json.NewEncoder(w).Encode(apierr.ErrorMessage{Reason: "something wrong"})
+			}))
+			defer server.Close()
+
+			prof := kprof.KnitProfile{ApiRoot: server.URL}
+			testee := try.To(krst.NewClient(&prof)).OrFatal(t)
+
+			if _, err := testee.UpdatePriority(context.Background(), "plan-1", change); err == nil {
+				t.Errorf("no error occured")
+			}
+		})
+	}
+}
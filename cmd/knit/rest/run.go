@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/opst/knitfab-api-types/misc/rfctime"
@@ -67,20 +68,17 @@ func (c *client) GetRunLog(ctx context.Context, runId string, follow bool) (io.R
 func (c *client) FindRun(
 	ctx context.Context,
 	query FindRunParameter,
-) ([]runs.Detail, error) {
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apipath("runs"), nil)
-	if err != nil {
-		return nil, err
-	}
+	handler func([]runs.Detail) error,
+) error {
 
 	// set query values
-	q := req.URL.Query()
+	q := url.Values{}
 	paramMap := map[string][]string{
 		"plan":         query.PlanId,
 		"knitIdInput":  query.KnitIdIn,
 		"knitIdOutput": query.KnitIdOut,
 		"status":       query.Status,
+		"cause":        query.ExitCause,
 	}
 
 	if query.Since != nil {
@@ -96,26 +94,8 @@ func (c *client) FindRun(
 			q.Add(key, strings.Join(value, ","))
 		}
 	}
-	req.URL.RawQuery = q.Encode()
-
-	resp, err := c.httpclient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	dataMetas := make([]runs.Detail, 0, 5)
-	if err := unmarshalJsonResponse(
-		resp, &dataMetas,
-		MessageFor{
-			Status4xx: fmt.Sprintf("[BUG] client is not compatible with the server (status code = %d)", resp.StatusCode),
-			Status5xx: fmt.Sprintf("server error (status code = %d)", resp.StatusCode),
-		},
-	); err != nil {
-		return nil, err
-	}
 
-	return dataMetas, nil
+	return findPages(ctx, c, c.apipath("runs"), q, handler)
 }
 
 func (c *client) Tearoff(ctx context.Context, runId string) (runs.Detail, error) {
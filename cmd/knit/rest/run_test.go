@@ -322,6 +322,7 @@ func TestFindRun(t *testing.T) {
 			statusInQuery    []string
 			sinceQuery       string
 			durationQuery    string
+			causeInQuery     []string
 		}
 
 		type testcase struct {
@@ -343,6 +344,7 @@ func TestFindRun(t *testing.T) {
 					statusInQuery:    []string{},
 					sinceQuery:       "",
 					durationQuery:    "",
+					causeInQuery:     []string{},
 				},
 			},
 			"when query with each item, server receives all": {
@@ -353,6 +355,7 @@ func TestFindRun(t *testing.T) {
 					Status:    []string{"wating", "running"},
 					Since:     &since,
 					Duration:  &duration,
+					ExitCause: []string{"timeout", "failed"},
 				},
 				then: then{
 					planIdInQuery:    []string{"test-a,test-b"},
@@ -361,6 +364,7 @@ func TestFindRun(t *testing.T) {
 					statusInQuery:    []string{"wating,running"},
 					sinceQuery:       timeStamp,
 					durationQuery:    "2h0m0s",
+					causeInQuery:     []string{"timeout,failed"},
 				},
 			},
 		} {
@@ -380,9 +384,10 @@ func TestFindRun(t *testing.T) {
 
 				//test start
 				testee := try.To(krst.NewClient(&profile)).OrFatal(t)
-				result := try.To(testee.FindRun(
-					ctx, when,
-				)).OrFatal(t)
+				result := []runs.Detail{}
+				if err := testee.FindRun(ctx, when, collect(&result)); err != nil {
+					t.Fatal(err)
+				}
 
 				// check response
 				if !cmp.SliceContentEqWith(result, response, runs.Detail.Equal) {
@@ -405,11 +410,13 @@ func TestFindRun(t *testing.T) {
 				actualStatus := getLastRequest().URL.Query()["status"]
 				actualSince := getLastRequest().URL.Query().Get("since")
 				actualDuration := getLastRequest().URL.Query().Get("duration")
+				actualCause := getLastRequest().URL.Query()["cause"]
 
 				checkSliceContentEquality(t, "active", actualPlan, then.planIdInQuery)
 				checkSliceContentEquality(t, "image", actualKnitIdIn, then.knitIdInInQuery)
 				checkSliceContentEquality(t, "input tag", actualKnitIdOut, then.knitIdOutInQuery)
 				checkSliceContentEquality(t, "output tag", actualStatus, then.statusInQuery)
+				checkSliceContentEquality(t, "cause", actualCause, then.causeInQuery)
 				if actualSince != then.sinceQuery {
 					t.Errorf("query since is wrong: actual=%s, then=%s)", actualSince, then.sinceQuery)
 				}
@@ -450,11 +457,12 @@ func TestFindRun(t *testing.T) {
 			}
 
 			//test start
-			actualResponse := try.To(
-				testee.FindRun(
-					ctx, findRunParameter,
-				),
-			).OrFatal(t)
+			actualResponse := []runs.Detail{}
+			if err := testee.FindRun(
+				ctx, findRunParameter, collect(&actualResponse),
+			); err != nil {
+				t.Fatal(err)
+			}
 
 			if !cmp.SliceContentEqWith(actualResponse, expectedResponse, runs.Detail.Equal) {
 				t.Errorf(
@@ -509,8 +517,8 @@ func TestFindRun(t *testing.T) {
 					Since:     &since,
 					Duration:  &duration,
 				}
-				if _, err := testee.FindRun(
-					ctx, findRunParameter,
+				if err := testee.FindRun(
+					ctx, findRunParameter, collect(&[]runs.Detail{}),
 				); err == nil {
 					t.Errorf("no error occured")
 				}
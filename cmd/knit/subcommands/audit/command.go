@@ -0,0 +1,19 @@
+package audit
+
+import (
+	audit_find "github.com/opst/knitfab/cmd/knit/subcommands/audit/find"
+	"github.com/youta-t/flarc"
+)
+
+func New() (flarc.Command, error) {
+	find, err := audit_find.New()
+	if err != nil {
+		return nil, err
+	}
+
+	return flarc.NewCommandGroup(
+		"Look into Knitfab audit log.",
+		struct{}{},
+		flarc.WithSubcommand("find", find),
+	)
+}
@@ -0,0 +1,146 @@
+package find
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	apiaudit "github.com/opst/knitfab-api-types/audit"
+	"github.com/opst/knitfab/cmd/knit/env"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/cmd/knit/subcommands/common"
+	kargs "github.com/opst/knitfab/pkg/utils/args"
+	ptr "github.com/opst/knitfab/pkg/utils/pointer"
+	"github.com/youta-t/flarc"
+)
+
+type Flag struct {
+	Actor    *kargs.Argslice             `flag:"actor" alias:"a" metavar:"ACTOR" help:"Find entries made by this actor (email address of user, or \"loop/...\"). Repeatable."`
+	Action   *kargs.Argslice             `flag:"action" metavar:"ACTION" help:"Find entries of this action (for example, \"plan.activate\"). Repeatable."`
+	Type     *kargs.Argslice             `flag:"type" alias:"t" metavar:"data|plan|run" help:"Find entries changing this type of item. Repeatable."`
+	Target   *kargs.Argslice             `flag:"target" alias:"i" metavar:"ID" help:"Find entries changing the item with this Knit Id, Plan Id or Run Id. Repeatable."`
+	Since    *kargs.OptionalLooseRFC3339 `flag:"since" metavar:"YYYY-mm-dd[THH[:MM[:SS]]][TZ]" help:"Find entries recorded at this time or later."`
+	Duration *kargs.OptionalDuration     `flag:"duration" metavar:"DURATION" help:"Find entries recorded in '--duration' from '--since'."`
+}
+
+type Option struct {
+	find func(
+		ctx context.Context,
+		log *log.Logger,
+		client krst.KnitClient,
+		parameter krst.FindAuditParameter,
+	) ([]apiaudit.Entry, error)
+}
+
+func WithFind(
+	find func(
+		ctx context.Context,
+		log *log.Logger,
+		client krst.KnitClient,
+		parameter krst.FindAuditParameter,
+	) ([]apiaudit.Entry, error),
+) func(*Option) *Option {
+	return func(o *Option) *Option {
+		o.find = find
+		return o
+	}
+}
+
+func New(
+	options ...func(*Option) *Option,
+) (flarc.Command, error) {
+	option := &Option{
+		find: RunFindAudit,
+	}
+	for _, opt := range options {
+		option = opt(option)
+	}
+
+	return flarc.NewCommand(
+		"Find audit log entries that satisfy all specified conditions.",
+		Flag{
+			Actor:    &kargs.Argslice{},
+			Action:   &kargs.Argslice{},
+			Type:     &kargs.Argslice{},
+			Target:   &kargs.Argslice{},
+			Since:    &kargs.OptionalLooseRFC3339{},
+			Duration: &kargs.OptionalDuration{},
+		},
+		flarc.Args{},
+		common.NewTask(Task(option.find)),
+		flarc.WithDescription(`
+Find audit log entries that satisfy all specified conditions.
+
+Audit log records who changed what and when: tags of Data, activeness, resources,
+annotations and service account of Plans, and status of Runs.
+Each entry has snapshots of the changed item before and after the change.
+
+If the same flags multiple times, it will display entries that satisfy any of the values.
+
+'--since' and '--duration' limit a result to entries recorded in the timespan,
+in the same manner as "knit run find".
+
+Example
+-------
+
+Finding who (de)activated the Plan "plan1":
+
+	{{ .Command }} --type plan --target plan1 --action plan.activate
+
+Finding changes made by a user in a day:
+
+	{{ .Command }} --actor user@example.com --since 2024-01-01 --duration 24h
+`,
+		),
+	)
+}
+
+func Task(
+	find func(
+		ctx context.Context,
+		log *log.Logger,
+		client krst.KnitClient,
+		parameter krst.FindAuditParameter,
+	) ([]apiaudit.Entry, error),
+) common.Task[Flag] {
+	return func(ctx context.Context, logger *log.Logger, knitEnv env.KnitEnv, client krst.KnitClient, cl flarc.Commandline[Flag], params []any) error {
+		flags := cl.Flags()
+		since := flags.Since.Time()
+		duration := flags.Duration.Duration()
+
+		if since == nil && duration != nil {
+			return fmt.Errorf("%w: --duration must be together with --since", flarc.ErrUsage)
+		}
+
+		parameter := krst.FindAuditParameter{
+			Actor:      ptr.SafeDeref(flags.Actor),
+			Action:     ptr.SafeDeref(flags.Action),
+			TargetType: ptr.SafeDeref(flags.Type),
+			TargetId:   ptr.SafeDeref(flags.Target),
+			Since:      since,
+			Duration:   duration,
+		}
+
+		entries, err := find(ctx, logger, client, parameter)
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(cl.Stdout())
+		enc.SetIndent("", "    ")
+		if err := enc.Encode(entries); err != nil {
+			logger.Panicf("fail to dump found audit log")
+		}
+		return nil
+	}
+}
+
+func RunFindAudit(
+	ctx context.Context,
+	logger *log.Logger,
+	client krst.KnitClient,
+	parameter krst.FindAuditParameter,
+) ([]apiaudit.Entry, error) {
+	return client.FindAudit(ctx, parameter)
+}
@@ -0,0 +1,129 @@
+package find_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	apiaudit "github.com/opst/knitfab-api-types/audit"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	kenv "github.com/opst/knitfab/cmd/knit/env"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/cmd/knit/rest/mock"
+	audit_find "github.com/opst/knitfab/cmd/knit/subcommands/audit/find"
+	"github.com/opst/knitfab/cmd/knit/subcommands/internal/commandline"
+	"github.com/opst/knitfab/cmd/knit/subcommands/logger"
+	kargs "github.com/opst/knitfab/pkg/utils/args"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+	"github.com/youta-t/flarc"
+)
+
+func TestFindCommand(t *testing.T) {
+	entries := []apiaudit.Entry{
+		{
+			Id: 1,
+			Timestamp: try.To(rfctime.ParseRFC3339DateTime(
+				"2024-04-22T12:00:00+00:00",
+			)).OrFatal(t),
+			Actor:  "user@example.com",
+			Action: "plan.activate",
+			Target: apiaudit.Target{Type: "plan", Id: "plan-1"},
+			Before: json.RawMessage(`{"active":true}`),
+			After:  json.RawMessage(`{"active":false}`),
+		},
+	}
+
+	run := func(t *testing.T, flag audit_find.Flag, find func(context.Context, *log.Logger, krst.KnitClient, krst.FindAuditParameter) ([]apiaudit.Entry, error)) (string, error) {
+		stdout := new(strings.Builder)
+		err := audit_find.Task(find)(
+			context.Background(), logger.Null(), *kenv.New(), mock.New(t),
+			commandline.MockCommandline[audit_find.Flag]{
+				Fullname_: "knit audit find",
+				Stdout_:   stdout,
+				Stderr_:   io.Discard,
+				Flags_:    flag,
+			},
+			[]any{},
+		)
+		return stdout.String(), err
+	}
+
+	t.Run("when flags are passed, it finds entries with them and prints the result", func(t *testing.T) {
+		timestamp := try.To(rfctime.ParseRFC3339DateTime("2024-04-22T00:00:00.000+09:00")).OrFatal(t).Time()
+		since := new(kargs.OptionalLooseRFC3339)
+		if err := since.Set("2024-04-22T00:00:00.000+09:00"); err != nil {
+			t.Fatal(err)
+		}
+		duration := new(kargs.OptionalDuration)
+		if err := duration.Set("2h"); err != nil {
+			t.Fatal(err)
+		}
+
+		var got krst.FindAuditParameter
+		stdout, err := run(t, audit_find.Flag{
+			Actor:    &kargs.Argslice{"user@example.com"},
+			Action:   &kargs.Argslice{"plan.activate", "run.delete"},
+			Type:     &kargs.Argslice{"plan"},
+			Target:   &kargs.Argslice{"plan-1"},
+			Since:    since,
+			Duration: duration,
+		}, func(_ context.Context, _ *log.Logger, _ krst.KnitClient, p krst.FindAuditParameter) ([]apiaudit.Entry, error) {
+			got = p
+			return entries, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !cmp.SliceEq(got.Actor, []string{"user@example.com"}) ||
+			!cmp.SliceEq(got.Action, []string{"plan.activate", "run.delete"}) ||
+			!cmp.SliceEq(got.TargetType, []string{"plan"}) ||
+			!cmp.SliceEq(got.TargetId, []string{"plan-1"}) {
+			t.Errorf("unexpected parameter: %+v", got)
+		}
+		if got.Since == nil || !got.Since.Equal(timestamp) {
+			t.Errorf("since: %v", got.Since)
+		}
+		if got.Duration == nil || *got.Duration != 2*time.Hour {
+			t.Errorf("duration: %v", got.Duration)
+		}
+
+		actual := []apiaudit.Entry{}
+		if err := json.Unmarshal([]byte(stdout), &actual); err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.SliceEqWith(actual, entries, apiaudit.Entry.Equal) {
+			t.Errorf("stdout:\n===actual===\n%+v\n===expected===\n%+v", actual, entries)
+		}
+	})
+
+	t.Run("when --duration is passed without --since, it returns ErrUsage", func(t *testing.T) {
+		duration := new(kargs.OptionalDuration)
+		if err := duration.Set("2h"); err != nil {
+			t.Fatal(err)
+		}
+		_, err := run(t, audit_find.Flag{Duration: duration, Since: &kargs.OptionalLooseRFC3339{}}, func(context.Context, *log.Logger, krst.KnitClient, krst.FindAuditParameter) ([]apiaudit.Entry, error) {
+			t.Fatal("find should not be called")
+			return nil, nil
+		})
+		if !errors.Is(err, flarc.ErrUsage) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("when find fails, it returns the error", func(t *testing.T) {
+		expectedErr := errors.New("fake error")
+		_, err := run(t, audit_find.Flag{}, func(context.Context, *log.Logger, krst.KnitClient, krst.FindAuditParameter) ([]apiaudit.Entry, error) {
+			return nil, expectedErr
+		})
+		if !errors.Is(err, expectedErr) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/opst/knitfab/cmd/knit/config/profiles"
 	"github.com/opst/knitfab/cmd/knit/env"
@@ -105,6 +106,55 @@ func NewTask[T any](task Task[T]) flarc.Task[T] {
 				err, commonFlag.Profile, commonFlag.ProfileStore,
 			)
 		}
+
+		if cred := prof.Credential; cred != nil {
+			now := time.Now()
+			if cred.Expired(now) {
+				logger.Printf(
+					"credential of knitprofile (%s) has been expired. Please try `knit login`",
+					commonFlag.Profile,
+				)
+			} else if cred.ShouldRefresh(now) {
+				if c, err := refreshCredential(ctx, client, profile, commonFlag); err != nil {
+					logger.Printf("failed to refresh credential (continue with current one): %s", err)
+				} else {
+					client = c
+				}
+			}
+		}
+
 		return task(ctx, logger, *e, client, cl, params)
 	})
 }
+
+// refreshCredential exchanges the credential in the profile for a new one,
+// and saves the profile store.
+//
+// # Returns
+//
+// - krest.KnitClient: client with the new credential.
+//
+// - error
+func refreshCredential(
+	ctx context.Context,
+	client krest.KnitClient,
+	store profiles.ProfileStore,
+	commonFlag CommonFlags,
+) (krest.KnitClient, error) {
+	tok, err := client.RefreshToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prof := store[commonFlag.Profile]
+	prof.Credential = &profiles.KnitCredential{
+		Token:     tok.Token,
+		IssuedAt:  tok.IssuedAt.Time(),
+		ExpiresAt: tok.ExpiresAt.Time(),
+	}
+	if err := store.Save(commonFlag.ProfileStore); err != nil {
+		return nil, err
+	}
+
+	return krest.NewClient(prof)
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JsonArrayEncoder writes items into w as a JSON array, item by item.
+//
+// The output is same as json.Encoder with SetIndent("", "    ") encoding all items in a slice,
+// but each item is written as soon as it is encoded.
+// So, items can be printed while they are being found.
+type JsonArrayEncoder[T any] struct {
+	w     io.Writer
+	count int
+}
+
+// NewJsonArrayEncoder returns JsonArrayEncoder writing into w.
+//
+// Call Close after all items are encoded to end the array.
+func NewJsonArrayEncoder[T any](w io.Writer) *JsonArrayEncoder[T] {
+	return &JsonArrayEncoder[T]{w: w}
+}
+
+// Encode writes items as elements of the array.
+func (e *JsonArrayEncoder[T]) Encode(items []T) error {
+	for _, item := range items {
+		b, err := json.MarshalIndent(item, "    ", "    ")
+		if err != nil {
+			return err
+		}
+
+		sep := ",\n    "
+		if e.count == 0 {
+			sep = "[\n    "
+		}
+		if _, err := io.WriteString(e.w, sep); err != nil {
+			return err
+		}
+		if _, err := e.w.Write(b); err != nil {
+			return err
+		}
+		e.count += 1
+	}
+	return nil
+}
+
+// Close ends the array.
+//
+// When no items are encoded, it writes an empty array.
+func (e *JsonArrayEncoder[T]) Close() error {
+	end := "\n]\n"
+	if e.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
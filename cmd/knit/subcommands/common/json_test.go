@@ -0,0 +1,63 @@
+package common_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	common "github.com/opst/knitfab/cmd/knit/subcommands/common"
+)
+
+func TestJsonArrayEncoder(t *testing.T) {
+	type Item struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	theory := func(pages [][]Item) func(*testing.T) {
+		return func(t *testing.T) {
+			expected := new(strings.Builder)
+			{
+				all := []Item{}
+				for _, p := range pages {
+					all = append(all, p...)
+				}
+				enc := json.NewEncoder(expected)
+				enc.SetIndent("", "    ")
+				if err := enc.Encode(all); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			actual := new(strings.Builder)
+			testee := common.NewJsonArrayEncoder[Item](actual)
+			for _, p := range pages {
+				if err := testee.Encode(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := testee.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if actual.String() != expected.String() {
+				t.Errorf(
+					"output:\n===actual===\n%s\n===expected===\n%s",
+					actual.String(), expected.String(),
+				)
+			}
+		}
+	}
+
+	t.Run("when no items are encoded, it writes an empty array", theory(nil))
+	t.Run("when only empty pages are encoded, it writes an empty array", theory(
+		[][]Item{{}, {}},
+	))
+	t.Run("when items are encoded in pages, it writes them as one array", theory(
+		[][]Item{
+			{{Name: "a", Tags: []string{"x", "y"}}, {Name: "b"}},
+			{},
+			{{Name: "c", Tags: []string{}}},
+		},
+	))
+}
@@ -5,7 +5,9 @@ import (
 	data_lineage "github.com/opst/knitfab/cmd/knit/subcommands/data/lineage"
 	data_pull "github.com/opst/knitfab/cmd/knit/subcommands/data/pull"
 	data_push "github.com/opst/knitfab/cmd/knit/subcommands/data/push"
+	data_rm "github.com/opst/knitfab/cmd/knit/subcommands/data/rm"
 	data_tag "github.com/opst/knitfab/cmd/knit/subcommands/data/tag"
+	data_verify "github.com/opst/knitfab/cmd/knit/subcommands/data/verify"
 	"github.com/youta-t/flarc"
 )
 
@@ -32,6 +34,16 @@ func New() (flarc.Command, error) {
 		return nil, err
 	}
 
+	rm, err := data_rm.New()
+	if err != nil {
+		return nil, err
+	}
+
+	verify, err := data_verify.New()
+	if err != nil {
+		return nil, err
+	}
+
 	return flarc.NewCommandGroup(
 		"Manupirate Knifab Data and Tags.",
 		struct{}{},
@@ -40,5 +52,7 @@ func New() (flarc.Command, error) {
 		flarc.WithSubcommand("push", push),
 		flarc.WithSubcommand("tag", tag),
 		flarc.WithSubcommand("lineage", lineage),
+		flarc.WithSubcommand("rm", rm),
+		flarc.WithSubcommand("verify", verify),
 	)
 }
@@ -2,7 +2,6 @@ package find
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"time"
@@ -44,7 +43,8 @@ type Option struct {
 		*log.Logger,
 		krst.KnitClient,
 		Query,
-	) ([]data.Detail, error)
+		func([]data.Detail) error,
+	) error
 }
 
 func WithFindData(
@@ -53,7 +53,8 @@ func WithFindData(
 		*log.Logger,
 		krst.KnitClient,
 		Query,
-	) ([]data.Detail, error),
+		func([]data.Detail) error,
+	) error,
 ) func(*Option) *Option {
 	return func(dfc *Option) *Option {
 		dfc.findData = findData
@@ -164,7 +165,8 @@ func Task(
 		logger *log.Logger,
 		client krst.KnitClient,
 		q Query,
-	) ([]data.Detail, error),
+		handler func([]data.Detail) error,
+	) error,
 ) common.Task[Flag] {
 	return func(
 		ctx context.Context,
@@ -201,7 +203,8 @@ func Task(
 			)
 		}
 
-		data, err := findData(
+		enc := common.NewJsonArrayEncoder[data.Detail](cl.Stdout())
+		if err := findData(
 			ctx, l, c,
 			Query{
 				Tags:      tags,
@@ -209,18 +212,12 @@ func Task(
 				Since:     since,
 				Duration:  duration,
 			},
-		)
-		if err != nil {
+			enc.Encode,
+		); err != nil {
 			return err
 		}
 
-		enc := json.NewEncoder(cl.Stdout())
-		enc.SetIndent("", "    ")
-		if err := enc.Encode(data); err != nil {
-			l.Panicf("fail to dump found Data")
-		}
-
-		return nil
+		return enc.Close()
 	}
 }
 
@@ -242,21 +239,17 @@ type Query struct {
 //     when... TransientAny, each data is returned wheather it has "knit#transient" tag or not.
 //     TransientOnly, returned data will be restricted to ones with `knit#transint` tag.
 //     TransientExclude, returned data will be restricted to ones without `knit#transint` tag.
+//   - handler: called with found data, page by page.
 //
 // returns:
-//   - []presentation.Data: found data. they are re-formatted for printing to console.
 //   - error
 func FindData(
 	ctx context.Context,
 	logger *log.Logger,
 	client krst.KnitClient,
 	q Query,
-) ([]data.Detail, error) {
-
-	result, err := client.FindData(ctx, q.Tags, q.Since, q.Duration)
-	if err != nil {
-		return nil, err
-	}
+	handler func([]data.Detail) error,
+) error {
 
 	isTransient := func(d data.Detail) bool {
 		_, ok := slices.First(d.Tags, func(t apitag.Tag) bool {
@@ -275,11 +268,14 @@ func FindData(
 		filter = func(d data.Detail) bool { return !isTransient(d) }
 	}
 
-	satisfied, _ := slices.Group(result, filter)
-
-	if satisfied == nil {
-		return []data.Detail{}, nil
-	}
-
-	return satisfied, nil
+	return client.FindData(
+		ctx, q.Tags, q.Since, q.Duration,
+		func(page []data.Detail) error {
+			satisfied, _ := slices.Group(page, filter)
+			if len(satisfied) == 0 {
+				return nil
+			}
+			return handler(satisfied)
+		},
+	)
 }
@@ -80,7 +80,8 @@ func TestFindDataCommand(t *testing.T) {
 			mockFindData := func(
 				_ context.Context, _ *log.Logger, _ krst.KnitClient,
 				q data_find.Query,
-			) ([]data.Detail, error) {
+				handler func([]data.Detail) error,
+			) error {
 				if !cmp.SliceContentEqWith(q.Tags, then.tags, tags.Tag.Equal) {
 					t.Errorf(
 						"wrong tags are passed into client:\nactual = %+v\nexpected = %+v",
@@ -127,7 +128,10 @@ func TestFindDataCommand(t *testing.T) {
 					}
 				}
 
-				return when.presentation, when.err
+				if when.err != nil {
+					return when.err
+				}
+				return handler(when.presentation)
 			}
 
 			stdout := new(strings.Builder)
@@ -706,7 +710,7 @@ func TestFindData(t *testing.T) {
 			ctx := context.Background()
 			logger := logger.Null()
 			mock := mock.New(t)
-			mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, s *time.Time, d *time.Duration) ([]data.Detail, error) {
+			mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, s *time.Time, d *time.Duration, handler func([]data.Detail) error) error {
 
 				if !cmp.SliceContentEq(tags, testcase.when.tags) {
 					t.Errorf(
@@ -747,10 +751,17 @@ func TestFindData(t *testing.T) {
 					}
 				}
 
-				return testcase.given, nil
+				// respond each Data as a page.
+				for _, d := range testcase.given {
+					if err := handler([]data.Detail{d}); err != nil {
+						return err
+					}
+				}
+				return nil
 			}
 
-			actual := try.To(data_find.FindData(
+			actual := []data.Detail{}
+			if err := data_find.FindData(
 				ctx, logger, mock,
 				data_find.Query{
 					Tags:      testcase.when.tags,
@@ -758,7 +769,13 @@ func TestFindData(t *testing.T) {
 					Since:     testcase.when.since,
 					Duration:  testcase.when.duration,
 				},
-			)).OrFatal(t)
+				func(page []data.Detail) error {
+					actual = append(actual, page...)
+					return nil
+				},
+			); err != nil {
+				t.Fatal(err)
+			}
 
 			{
 				given := slices.ToMap(testcase.given, func(d data.Detail) string { return d.KnitId })
@@ -805,14 +822,15 @@ func TestFindData(t *testing.T) {
 		expectedError := errors.New("fake error")
 
 		mock := mock.New(t)
-		mock.Impl.FindData = func(ctx context.Context, t []tags.Tag, s *time.Time, d *time.Duration) ([]data.Detail, error) {
-			return nil, expectedError
+		mock.Impl.FindData = func(ctx context.Context, t []tags.Tag, s *time.Time, d *time.Duration, handler func([]data.Detail) error) error {
+			return expectedError
 		}
 
 		since := try.To(rfctime.ParseRFC3339DateTime("2024-04-22T00:00:00.000+09:00")).OrFatal(t).Time()
 		duration := time.Duration(2 * time.Hour)
 
-		actual, err := data_find.FindData(
+		actual := []data.Detail{}
+		err := data_find.FindData(
 			ctx, logger, mock,
 			data_find.Query{
 				Tags:      []tags.Tag{},
@@ -820,6 +838,10 @@ func TestFindData(t *testing.T) {
 				Since:     &since,
 				Duration:  &duration,
 			},
+			func(page []data.Detail) error {
+				actual = append(actual, page...)
+				return nil
+			},
 		)
 
 		if len(actual) != 0 {
@@ -13,6 +13,7 @@ import (
 	"github.com/opst/knitfab/cmd/knit/subcommands/common"
 	"github.com/opst/knitfab/pkg/domain"
 	"github.com/opst/knitfab/pkg/utils/args"
+	"github.com/opst/knitfab/pkg/utils/nils"
 	"github.com/opst/knitfab/pkg/utils/pointer"
 	"github.com/opst/knitfab/pkg/utils/slices"
 	"github.com/youta-t/flarc"
@@ -21,9 +22,10 @@ import (
 )
 
 type Flag struct {
-	Upstream   bool        `flag:"upstream" alias:"u" help:"Trace the upstream of the specified Data."`
-	Downstream bool        `flag:"downstream" alias:"d" help:"Trace the downstream of the specified Data."`
-	Numbers    *args.Depth `flag:"numbers" alias:"n" help:"Trace up to the specified depth. Trace to the upstream-most/downstream-most if 'all' is specified.,metavar=number of depth"`
+	Upstream   bool              `flag:"upstream" alias:"u" help:"Trace the upstream of the specified Data."`
+	Downstream bool              `flag:"downstream" alias:"d" help:"Trace the downstream of the specified Data."`
+	Numbers    *args.Depth       `flag:"numbers" alias:"n" help:"Trace up to the specified depth. Trace to the upstream-most/downstream-most if 'all' is specified.,metavar=number of depth"`
+	Format     *knitgraph.Format `flag:"format" alias:"f" help:"Output format. One of dot, prov-json, openlineage, json or mermaid.,metavar=format"`
 }
 
 type Option struct {
@@ -43,6 +45,8 @@ type Runner func(
 	depth args.Depth,
 ) (*knitgraph.DirectedGraph, error)
 
+var _ Runner = FetchUpStream
+var _ Runner = FetchDownStream
 var _ Runner = TraceUpStream
 var _ Runner = TraceDownStream
 
@@ -60,8 +64,8 @@ func New(
 ) (flarc.Command, error) {
 	opt := &Option{
 		Traverser: Traverser{
-			ForUpstream:   TraceUpStream,
-			ForDownstream: TraceDownStream,
+			ForUpstream:   FetchUpStream,
+			ForDownstream: FetchDownStream,
 		},
 	}
 	for _, o := range options {
@@ -69,11 +73,12 @@ func New(
 	}
 
 	return flarc.NewCommand(
-		"Output the result of tracing the Data Lineage as dot format.",
+		"Output the result of tracing the Data Lineage as dot format, or other formats.",
 		Flag{
 			Upstream:   false,
 			Downstream: false,
 			Numbers:    pointer.Ref(args.NewDepth(3)),
+			Format:     pointer.Ref(knitgraph.FormatDot),
 		},
 		flarc.Args{
 			{
@@ -89,6 +94,14 @@ and outputs the result in dot format (graphviz).
 You can specify the depth of the trace as a natural number,
 and choose whether to trace upstream, downstream, or both.
 
+The output format can be changed with the --format flag:
+
+- dot: Graphviz dot format (default).
+- prov-json: W3C PROV-JSON. Data are entities, Runs are activities and Plans are plans/agents.
+- openlineage: OpenLineage RunEvents, one event per line.
+- json: Data, Runs and edges between them in JSON.
+- mermaid: Mermaid flowchart.
+
 Example
 -------
 
@@ -123,6 +136,9 @@ Example
 
 - Generate the traced result as a dot file:
 	{{ .Command }} KNIT_ID > graph.dot
+
+- Generate the traced result as a W3C PROV-JSON document:
+	{{ .Command }} --format prov-json KNIT_ID > provenance.json
 `,
 		),
 	)
@@ -168,10 +184,11 @@ func Task(option Traverser) common.Task[Flag] {
 			graph = _graph
 		}
 
-		if err := graph.GenerateDot(cl.Stdout()); err != nil {
-			return fmt.Errorf("fail to output dot format: %w", err)
+		format := nils.Default(flags.Format, knitgraph.FormatDot)
+		if err := graph.Generate(cl.Stdout(), format); err != nil {
+			return fmt.Errorf("fail to output %s format: %w", format, err)
 		}
-		logger.Println("success to output dot format")
+		logger.Printf("success to output %s format", format)
 		return nil
 	}
 }
@@ -208,7 +225,73 @@ func AddEdgeFromRunToLog(
 	}
 }
 
+// Get the upstream data lineage from knitd at once.
+func FetchUpStream(
+	ctx context.Context,
+	client krst.KnitClient,
+	graph *knitgraph.DirectedGraph,
+	rootKnitId string,
+	maxDepth args.Depth,
+) (*knitgraph.DirectedGraph, error) {
+	return fetchLineage(ctx, client, graph, rootKnitId, krst.LineageParameter{
+		Upstream: true, Depth: depthParameter(maxDepth),
+	})
+}
+
+// Get the downstream data lineage from knitd at once.
+func FetchDownStream(
+	ctx context.Context,
+	client krst.KnitClient,
+	graph *knitgraph.DirectedGraph,
+	rootKnitId string,
+	maxDepth args.Depth,
+) (*knitgraph.DirectedGraph, error) {
+	return fetchLineage(ctx, client, graph, rootKnitId, krst.LineageParameter{
+		Downstream: true, Depth: depthParameter(maxDepth),
+	})
+}
+
+func depthParameter(depth args.Depth) *uint {
+	if depth.IsInfinity() {
+		return nil
+	}
+	return pointer.Ref(depth.Value())
+}
+
+func fetchLineage(
+	ctx context.Context,
+	client krst.KnitClient,
+	graph *knitgraph.DirectedGraph,
+	rootKnitId string,
+	param krst.LineageParameter,
+) (*knitgraph.DirectedGraph, error) {
+	lin, err := client.GetLineage(ctx, rootKnitId, param)
+	if err != nil {
+		return graph, fmt.Errorf("failed to get lineage of %s: %w", rootKnitId, err)
+	}
+
+	for _, d := range lin.Data {
+		if _, ok := graph.DataNodes.Get(d.KnitId); ok {
+			continue
+		}
+		styles := []knitgraph.StyleOption{}
+		if d.KnitId == lin.Root {
+			styles = append(styles, knitgraph.Emphasize())
+		}
+		graph.AddDataNode(d, styles...)
+	}
+	for _, r := range lin.Runs {
+		if _, ok := graph.RunNodes.Get(r.RunId); ok {
+			continue
+		}
+		graph.AddRunNode(r)
+	}
+	return graph, nil
+}
+
 // Trace the downstream data lineage.
+//
+// This traces the lineage node by node, making REST calls for each node.
 func TraceDownStream(
 	ctx context.Context,
 	client krst.KnitClient,
@@ -338,6 +421,8 @@ func TraceDownstreamForSingleNode(
 }
 
 // Trace the upstream data lineage.
+//
+// This traces the lineage node by node, making REST calls for each node.
 func TraceUpStream(
 	ctx context.Context,
 	client krst.KnitClient,
@@ -458,7 +543,14 @@ func TraceUpstreamForSingleNode(
 }
 
 func getData(ctx context.Context, client krst.KnitClient, knitId string) (data.Detail, error) {
-	datas, err := client.FindData(ctx, []tags.Tag{knitIdTag(knitId)}, nil, nil)
+	datas := []data.Detail{}
+	err := client.FindData(
+		ctx, []tags.Tag{knitIdTag(knitId)}, nil, nil,
+		func(page []data.Detail) error {
+			datas = append(datas, page...)
+			return nil
+		},
+	)
 	if err != nil {
 		return data.Detail{}, knitgraph.ErrFindDataWithKnitId(knitId, err)
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/youta-t/flarc"
 
 	"github.com/opst/knitfab-api-types/data"
+	apilineage "github.com/opst/knitfab-api-types/lineage"
 	"github.com/opst/knitfab-api-types/plans"
 	"github.com/opst/knitfab-api-types/runs"
 	"github.com/opst/knitfab-api-types/tags"
@@ -255,10 +256,11 @@ func TestTraceDownStream(t *testing.T) {
 			nthData := 0
 			mock.Impl.FindData = func(
 				ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration,
-			) ([]data.Detail, error) {
+				handler func([]data.Detail) error,
+			) error {
 				ret := when.FindDataReturns[nthData]
 				nthData += 1
-				return ret, nil
+				return handler(ret)
 			}
 
 			nthRun := 0
@@ -1464,8 +1466,8 @@ func TestTraceDownStream(t *testing.T) {
 
 	t.Run("When FindData returns Empty array it returns ErrNotFoundData", func(t *testing.T) {
 		mock := mock.New(t)
-		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration) ([]data.Detail, error) {
-			return []data.Detail{}, nil
+		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration, handler func([]data.Detail) error) error {
+			return handler([]data.Detail{})
 		}
 		mock.Impl.GetRun = func(ctx context.Context, runId string) (runs.Detail, error) {
 			return runs.Detail{}, nil
@@ -1483,8 +1485,8 @@ func TestTraceDownStream(t *testing.T) {
 	expectedError := errors.New("fake error")
 	t.Run("When FindData fails, it returns the error that contains that error ", func(t *testing.T) {
 		mock := mock.New(t)
-		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration) ([]data.Detail, error) {
-			return []data.Detail{}, expectedError
+		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration, handler func([]data.Detail) error) error {
+			return expectedError
 		}
 		mock.Impl.GetRun = func(ctx context.Context, runId string) (runs.Detail, error) {
 			return runs.Detail{}, nil
@@ -1502,8 +1504,8 @@ func TestTraceDownStream(t *testing.T) {
 	t.Run("When GetRun fails, it returns the error that contains that error", func(t *testing.T) {
 		mock := mock.New(t)
 		knitId := "knitId-test"
-		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration) ([]data.Detail, error) {
-			return []data.Detail{
+		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration, handler func([]data.Detail) error) error {
+			return handler([]data.Detail{
 				{
 					KnitId: knitId,
 					Upstream: data.CreatedFrom{
@@ -1517,7 +1519,7 @@ func TestTraceDownStream(t *testing.T) {
 						},
 					},
 				},
-			}, nil
+			})
 		}
 		mock.Impl.GetRun = func(ctx context.Context, runId string) (runs.Detail, error) {
 			return runs.Detail{}, expectedError
@@ -1552,11 +1554,11 @@ func TestTraceUpStream(t *testing.T) {
 			// Store arguments and return values for each call
 			nthData := 0
 			mock.Impl.FindData = func(
-				ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration) (
-				[]data.Detail, error) {
+				ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration,
+				handler func([]data.Detail) error) error {
 				ret := when.FindDataReturns[nthData]
 				nthData += 1
-				return ret, nil
+				return handler(ret)
 			}
 
 			nthRun := 0
@@ -2351,8 +2353,8 @@ func TestTraceUpStream(t *testing.T) {
 
 	t.Run("When FindData returns Empty array it returns ErrNotFoundData", func(t *testing.T) {
 		mock := mock.New(t)
-		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration) ([]data.Detail, error) {
-			return []data.Detail{}, nil
+		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration, handler func([]data.Detail) error) error {
+			return handler([]data.Detail{})
 		}
 		mock.Impl.GetRun = func(ctx context.Context, runId string) (runs.Detail, error) {
 			return runs.Detail{}, nil
@@ -2370,8 +2372,8 @@ func TestTraceUpStream(t *testing.T) {
 	expectedError := errors.New("fake error")
 	t.Run("When FindData fails, it returns the error that contains that error ", func(t *testing.T) {
 		mock := mock.New(t)
-		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration) ([]data.Detail, error) {
-			return []data.Detail{}, expectedError
+		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration, handler func([]data.Detail) error) error {
+			return expectedError
 		}
 		mock.Impl.GetRun = func(ctx context.Context, runId string) (runs.Detail, error) {
 			return runs.Detail{}, nil
@@ -2392,8 +2394,8 @@ func TestTraceUpStream(t *testing.T) {
 			return runs.Detail{}, expectedError
 		}
 		knitId := "knitId-test"
-		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration) ([]data.Detail, error) {
-			return []data.Detail{
+		mock.Impl.FindData = func(ctx context.Context, tags []tags.Tag, since *time.Time, duration *time.Duration, handler func([]data.Detail) error) error {
+			return handler([]data.Detail{
 				{
 					KnitId: knitId,
 					Upstream: data.CreatedFrom{
@@ -2407,7 +2409,7 @@ func TestTraceUpStream(t *testing.T) {
 						},
 					},
 				},
-			}, nil
+			})
 		}
 		ctx := context.Background()
 		graph := knitgraph.NewDirectedGraph()
@@ -2417,3 +2419,170 @@ func TestTraceUpStream(t *testing.T) {
 		}
 	})
 }
+
+func TestFetchLineage(t *testing.T) {
+	// [test case of data lineage]
+	// data1 --[/in/1]--> run1 --[/out/1]--> data2
+	data1 := data.Detail{
+		KnitId: "data1",
+		Upstream: data.CreatedFrom{
+			Run:        runs.Summary{RunId: "run0"},
+			Mountpoint: &plans.Mountpoint{Path: "/out/1"},
+		},
+		Downstreams: []data.AssignedTo{
+			{
+				Run:        runs.Summary{RunId: "run1"},
+				Mountpoint: plans.Mountpoint{Path: "/in/1"},
+			},
+		},
+	}
+	run1 := runs.Detail{
+		Summary: runs.Summary{
+			RunId: "run1",
+			Plan: plans.Summary{
+				PlanId: "plan1",
+				Image:  &plans.Image{Repository: "repo1", Tag: "tag1"},
+			},
+		},
+		Inputs: []runs.Assignment{
+			{KnitId: "data1", Mountpoint: plans.Mountpoint{Path: "/in/1"}},
+		},
+		Outputs: []runs.Assignment{
+			{KnitId: "data2", Mountpoint: plans.Mountpoint{Path: "/out/1"}},
+		},
+	}
+	data2 := data.Detail{
+		KnitId: "data2",
+		Upstream: data.CreatedFrom{
+			Run:        runs.Summary{RunId: "run1"},
+			Mountpoint: &plans.Mountpoint{Path: "/out/1"},
+		},
+	}
+
+	type When struct {
+		RootKnitId string
+		Depth      args.Depth
+		Runner     lineage.Runner
+		Graph      apilineage.Graph
+	}
+	type Then struct {
+		Param krst.LineageParameter
+		Graph *knitgraph.DirectedGraph
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			client := mock.New(t)
+			client.Impl.GetLineage = func(
+				ctx context.Context, knitId string, param krst.LineageParameter,
+			) (apilineage.Graph, error) {
+				return when.Graph, nil
+			}
+
+			graph, err := when.Runner(
+				context.Background(), client, knitgraph.NewDirectedGraph(), when.RootKnitId, when.Depth,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.SliceEqWith(
+				client.Calls.GetLineage,
+				[]mock.GetLineageArgs{{KnitId: when.RootKnitId, Param: then.Param}},
+				func(a, b mock.GetLineageArgs) bool {
+					return a.KnitId == b.KnitId &&
+						a.Param.Upstream == b.Param.Upstream &&
+						a.Param.Downstream == b.Param.Downstream &&
+						cmp.PEqEq(a.Param.Depth, b.Param.Depth)
+				},
+			) {
+				t.Errorf("GetLineage is called with unexpected args: %+v", client.Calls.GetLineage)
+			}
+
+			if !cmp.MapEqWith(
+				graph.DataNodes.ToMap(),
+				then.Graph.DataNodes.ToMap(),
+				func(a, b knitgraph.DataNode) bool { return a.Equal(&b) },
+			) {
+				t.Errorf(
+					"DataNodes is not equal (actual,expected): %v,%v",
+					graph.DataNodes, then.Graph.DataNodes,
+				)
+			}
+			if !cmp.MapEqWith(
+				graph.RunNodes.ToMap(),
+				then.Graph.RunNodes.ToMap(),
+				func(a, b knitgraph.RunNode) bool { return a.Summary.Equal(b.Summary) },
+			) {
+				t.Errorf(
+					"RunNodes is not equal (actual,expected): %v,%v",
+					graph.RunNodes, then.Graph.RunNodes,
+				)
+			}
+			if !cmp.SliceContentEq(graph.Edges, then.Graph.Edges) {
+				t.Errorf(
+					"Edges is not equal (actual,expected): %v,%v",
+					graph.Edges, then.Graph.Edges,
+				)
+			}
+		}
+	}
+
+	t.Run("FetchDownStream builds the graph from the lineage", theory(
+		When{
+			RootKnitId: "data1",
+			Depth:      args.NewDepth(1),
+			Runner:     lineage.FetchDownStream,
+			Graph: apilineage.Graph{
+				Root: "data1",
+				Data: []data.Detail{data1, data2},
+				Runs: []runs.Detail{run1},
+			},
+		},
+		Then{
+			Param: krst.LineageParameter{Downstream: true, Depth: pointer.Ref[uint](1)},
+			Graph: knitgraph.NewDirectedGraph(
+				knitgraph.WithData(data1, knitgraph.Emphasize()),
+				knitgraph.WithData(data2),
+				knitgraph.WithRun(run1),
+			),
+		},
+	))
+
+	t.Run("FetchUpStream builds the graph from the lineage", theory(
+		When{
+			RootKnitId: "data2",
+			Depth:      args.NewInfinityDepth(),
+			Runner:     lineage.FetchUpStream,
+			Graph: apilineage.Graph{
+				Root: "data2",
+				Data: []data.Detail{data1, data2},
+				Runs: []runs.Detail{run1},
+			},
+		},
+		Then{
+			Param: krst.LineageParameter{Upstream: true},
+			Graph: knitgraph.NewDirectedGraph(
+				knitgraph.WithData(data1),
+				knitgraph.WithData(data2, knitgraph.Emphasize()),
+				knitgraph.WithRun(run1),
+			),
+		},
+	))
+
+	t.Run("When GetLineage fails, it returns the error that contains that error", func(t *testing.T) {
+		expectedError := errors.New("fake error")
+		client := mock.New(t)
+		client.Impl.GetLineage = func(
+			ctx context.Context, knitId string, param krst.LineageParameter,
+		) (apilineage.Graph, error) {
+			return apilineage.Graph{}, expectedError
+		}
+		_, err := lineage.FetchUpStream(
+			context.Background(), client, knitgraph.NewDirectedGraph(), "data1", args.NewDepth(1),
+		)
+		if !errors.Is(err, expectedError) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package rm
+
+import (
+	"context"
+	"log"
+
+	"github.com/opst/knitfab/cmd/knit/env"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/cmd/knit/subcommands/common"
+	"github.com/youta-t/flarc"
+)
+
+type Option struct {
+	remove func(
+		ctx context.Context,
+		client krst.KnitClient,
+		knitId string,
+	) error
+}
+
+func WithRemover(
+	remove func(
+		ctx context.Context,
+		client krst.KnitClient,
+		knitId string,
+	) error,
+) func(*Option) *Option {
+	return func(opt *Option) *Option {
+		opt.remove = remove
+		return opt
+	}
+}
+
+const ARG_KNITID = "KNIT_ID"
+
+func New(
+	options ...func(*Option) *Option,
+) (flarc.Command, error) {
+	option := &Option{
+		remove: RunDeleteData,
+	}
+	for _, opt := range options {
+		option = opt(option)
+	}
+
+	return flarc.NewCommand(
+		"Delete Data for the specified Knit Id.",
+		struct{}{},
+		flarc.Args{
+			{
+				Name:       ARG_KNITID,
+				Required:   true,
+				Repeatable: false,
+				Help:       "Knit Id of the Data to be deleted.",
+			},
+		},
+		common.NewTask(Task(option.remove)),
+		flarc.WithDescription(`
+Delete Data for the specified Knit Id, with the Run which has generated the Data.
+
+Data can be deleted only when the Run has no other outputs (including its log),
+so uploaded Data can be deleted, but outputs of Runs of Plans with images can not.
+To delete them, delete the Run with "knit run rm".
+Data used by other Runs can not be deleted.
+`),
+	)
+}
+
+func Task(
+	remove func(context.Context, krst.KnitClient, string) error,
+) common.Task[struct{}] {
+	return func(
+		ctx context.Context,
+		logger *log.Logger,
+		knitEnv env.KnitEnv,
+		client krst.KnitClient,
+		cl flarc.Commandline[struct{}],
+		params []any,
+	) error {
+
+		knitId := cl.Args()[ARG_KNITID][0]
+		if err := remove(ctx, client, knitId); err == nil {
+			logger.Printf("deleted Knit Id:%v", knitId)
+		} else {
+			return err
+		}
+		return nil
+	}
+}
+
+func RunDeleteData(ctx context.Context, client krst.KnitClient, knitId string,
+) error {
+	err := client.DeleteData(ctx, knitId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
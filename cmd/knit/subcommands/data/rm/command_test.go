@@ -0,0 +1,129 @@
+package rm_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	kprof "github.com/opst/knitfab/cmd/knit/config/profiles"
+	kenv "github.com/opst/knitfab/cmd/knit/env"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/cmd/knit/rest/mock"
+	data_rm "github.com/opst/knitfab/cmd/knit/subcommands/data/rm"
+	"github.com/opst/knitfab/cmd/knit/subcommands/internal/commandline"
+	"github.com/opst/knitfab/cmd/knit/subcommands/logger"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestDeleteCommand(t *testing.T) {
+	type when struct {
+		knitId []string
+		err    error
+	}
+
+	type then struct {
+		knitId string
+		err    error
+	}
+
+	theory := func(when when, then then) func(*testing.T) {
+		return func(t *testing.T) {
+			profile := &kprof.KnitProfile{ApiRoot: "http://api.knit.invalid"}
+			client := try.To(krst.NewClient(profile)).OrFatal(t)
+
+			removeMock := func(
+				ctx context.Context,
+				client krst.KnitClient,
+				knitId string,
+			) error {
+				if knitId != then.knitId {
+					t.Errorf("knitId: got %s, but want %s", knitId, then.knitId)
+				}
+				return when.err
+			}
+
+			testee := data_rm.Task(removeMock)
+
+			stdout := new(strings.Builder)
+			stderr := new(strings.Builder)
+
+			ctx := context.Background()
+			err := testee(
+				ctx,
+				logger.Null(),
+				*kenv.New(),
+				client,
+				commandline.MockCommandline[struct{}]{
+					Fullname_: "knit data rm",
+					Stdout_:   stdout,
+					Stderr_:   stderr,
+					Flags_:    struct{}{},
+					Args_: map[string][]string{
+						data_rm.ARG_KNITID: when.knitId,
+					},
+				},
+				[]any{},
+			)
+
+			if !errors.Is(err, then.err) {
+				t.Errorf(
+					"wrong status: (actual, expected) != (%d, %d)",
+					err, then.err,
+				)
+			}
+		}
+	}
+	t.Run("when it is passed existed knitId, it should return exitsuccess", theory(
+		when{
+			knitId: []string{"test-Id"},
+			err:    nil,
+		},
+		then{
+			err:    nil,
+			knitId: "test-Id",
+		},
+	))
+	{
+		expectedError := errors.New("fake error")
+		t.Run("when error is caused in client, it returns the error", theory(
+			when{
+				knitId: []string{"test-Id"},
+				err:    expectedError,
+			},
+			then{
+				err:    expectedError,
+				knitId: "test-Id",
+			},
+		))
+	}
+}
+
+func TestRunDeleteData(t *testing.T) {
+	t.Run("When client does not cause any error, it should return the content returned by client as is", func(t *testing.T) {
+		ctx := context.Background()
+		mock := mock.New(t)
+		mock.Impl.DeleteData = func(ctx context.Context, knitId string) error {
+			return nil
+		}
+
+		err := data_rm.RunDeleteData(ctx, mock, "test-knitId")
+		if err != nil {
+			t.Fatalf("RunDeleteData returns error unexpectedly: %s (%+v)", err.Error(), err)
+		}
+	})
+
+	t.Run("when client returns error, it should return the error as is", func(t *testing.T) {
+		ctx := context.Background()
+		mock := mock.New(t)
+		expectedError := errors.New("fake error")
+		mock.Impl.DeleteData = func(ctx context.Context, knitId string) error {
+			return expectedError
+		}
+
+		err := data_rm.RunDeleteData(ctx, mock, "test-knitId")
+		if !errors.Is(err, expectedError) {
+			t.Errorf("returned error is not expected one: %+v", err)
+		}
+	})
+}
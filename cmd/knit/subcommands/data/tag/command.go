@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 
 	apitag "github.com/opst/knitfab-api-types/tags"
@@ -18,6 +19,7 @@ type Flag struct {
 	AddTag    *kargs.Tags `flag:"add" metavar:"KEY:VALUE..." help:"add Tags to Data. Repeatable."`
 	RemoveTag *kargs.Tags `flag:"remove" metavar:"KEY:VALUE..." help:"remove Tags from Data. Repeatable."`
 	RemoveKey []string    `flag:"remove-key" metavar:"KEY..." help:"remove Tags by key. Repeatable"`
+	DryRun    bool        `flag:"dry-run" help:"do not change Tags. Print Runs which would be created by the change instead."`
 }
 
 var ARG_KNITID = "KNIT_ID"
@@ -40,6 +42,9 @@ func New() (flarc.Command, error) {
 Add and/or remove Tags on Data in knitfab.
 
 If the same Tag is specified in both add and remove, the Tag will be added. (remove first, then add)
+
+With --dry-run, Tags are not changed.
+Instead, Runs which would be created by the change are printed.
 `),
 	)
 }
@@ -85,6 +90,10 @@ func Task(
 		}
 	}
 
+	if flags.DryRun {
+		return PreviewTag(ctx, l, c, cl.Stdout(), knitId, change)
+	}
+
 	if err := UpdateTag(ctx, l, c, knitId, change); err != nil {
 		return err
 	}
@@ -119,3 +128,23 @@ func UpdateTag(
 
 	return nil
 }
+
+// PreviewTag writes Runs which would be created when tags of the data are changed.
+func PreviewTag(
+	ctx context.Context,
+	logger *log.Logger,
+	ci krst.KnitClient,
+	stdout io.Writer,
+	knitid string,
+	change apitag.Change,
+) error {
+	logger.Printf("previewing tagging to knit#id:%s", knitid)
+	previews, err := ci.PreviewTagsForData(ctx, knitid, change)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "    ")
+	return enc.Encode(previews)
+}
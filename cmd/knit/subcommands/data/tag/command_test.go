@@ -1,8 +1,11 @@
 package tag_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"testing"
 
 	dara "github.com/opst/knitfab-api-types/data"
@@ -472,3 +475,71 @@ func comparePutHist(actualHist, expectedHist []rmock.PutTagsForDataArgs) bool {
 	}
 	return true
 }
+
+func TestPreviewTag(t *testing.T) {
+	change := tags.Change{
+		AddTags:    []apitag.UserTag{{Key: "key1", Value: "val1"}},
+		RemoveTags: []apitag.UserTag{{Key: "remkey1", Value: "remval1"}},
+	}
+
+	t.Run("when client does not cause any error, it writes previews.", func(t *testing.T) {
+		mock := rmock.New(t)
+		expected := []runs.Preview{
+			{
+				Plan: plans.Summary{
+					PlanId: "plan-1",
+					Image:  &plans.Image{Repository: "repo.invalid/image", Tag: "v1"},
+				},
+				Inputs: []runs.Assignment{
+					{
+						Mountpoint: plans.Mountpoint{
+							Path: "/in/1", Tags: []tags.Tag{{Key: "key1", Value: "val1"}},
+						},
+						KnitId: "1234",
+					},
+				},
+			},
+		}
+		mock.Impl.PreviewTagsForData = func(ctx context.Context, knitId string, argtags apitag.Change) ([]runs.Preview, error) {
+			return expected, nil
+		}
+
+		stdout := new(bytes.Buffer)
+		if err := data_tag.PreviewTag(
+			context.Background(), logger.Null(), mock, stdout, "1234", change,
+		); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := mock.Calls.PreviewTagsForData; len(got) != 1 {
+			t.Fatalf("PreviewTagsForData is called %d times", len(got))
+		} else if got[0].KnitId != "1234" || !got[0].Tags.Equal(&change) {
+			t.Errorf("unexpected args: %+v", got[0])
+		}
+		if len(mock.Calls.PutTagsForData) != 0 {
+			t.Errorf("tags are updated")
+		}
+
+		actual := []runs.Preview{}
+		if err := json.Unmarshal(stdout.Bytes(), &actual); err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.SliceContentEqWith(actual, expected, runs.Preview.Equal) {
+			t.Errorf("unexpected output:\n===actual===\n%+v\n===expected===\n%+v", actual, expected)
+		}
+	})
+
+	t.Run("it returns error when client.PreviewTagsForData returns error.", func(t *testing.T) {
+		mock := rmock.New(t)
+		mock.Impl.PreviewTagsForData = func(ctx context.Context, knitId string, argtags apitag.Change) ([]runs.Preview, error) {
+			return nil, errors.New("Internel server error 500")
+		}
+
+		err := data_tag.PreviewTag(
+			context.Background(), logger.Null(), mock, io.Discard, "1234", change,
+		)
+		if err == nil {
+			t.Errorf("unmatch error")
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package verify
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/opst/knitfab-api-types/data"
+	"github.com/opst/knitfab-api-types/tags"
+	"github.com/opst/knitfab/cmd/knit/env"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/cmd/knit/subcommands/common"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/utils/archive"
+	"github.com/youta-t/flarc"
+)
+
+type Option struct {
+	verify func(
+		ctx context.Context,
+		client krst.KnitClient,
+		knitId string,
+	) (Result, error)
+}
+
+func WithVerifier(
+	verify func(
+		ctx context.Context,
+		client krst.KnitClient,
+		knitId string,
+	) (Result, error),
+) func(*Option) *Option {
+	return func(opt *Option) *Option {
+		opt.verify = verify
+		return opt
+	}
+}
+
+const ARG_KNITID = "KNIT_ID"
+
+// Result of verification.
+type Result struct {
+	KnitId string `json:"knitId"`
+
+	// Expected is the checksum recorded in knitfab.
+	Expected string `json:"expected"`
+
+	// Actual is the checksum of the content downloaded.
+	Actual string `json:"actual"`
+
+	// Ok is true if Expected and Actual are same.
+	Ok bool `json:"ok"`
+}
+
+var (
+	ErrNotFoundData       = errors.New("data not found")
+	ErrChecksumNotFound   = errors.New("checksum is not recorded yet")
+	ErrChecksumMismatched = errors.New("checksum is mismatched")
+)
+
+func New(
+	options ...func(*Option) *Option,
+) (flarc.Command, error) {
+	option := &Option{
+		verify: RunVerifyData,
+	}
+	for _, opt := range options {
+		option = opt(option)
+	}
+
+	return flarc.NewCommand(
+		"Verify the content of Data with its checksum.",
+		struct{}{},
+		flarc.Args{
+			{
+				Name:       ARG_KNITID,
+				Required:   true,
+				Repeatable: false,
+				Help:       "Knit Id of the Data to be verified.",
+			},
+		},
+		common.NewTask(Task(option.verify)),
+		flarc.WithDescription(`
+Verify the content of Data for the specified Knit Id.
+
+The content of the Data is downloaded, and its checksum is compared with
+the one recorded in knitfab when the Data was uploaded or generated.
+The result is written to stdout as JSON.
+
+If the checksums are mismatched, this command fails.
+If the checksum is not recorded yet, retry later.
+`),
+	)
+}
+
+func Task(
+	verify func(context.Context, krst.KnitClient, string) (Result, error),
+) common.Task[struct{}] {
+	return func(
+		ctx context.Context,
+		logger *log.Logger,
+		knitEnv env.KnitEnv,
+		client krst.KnitClient,
+		cl flarc.Commandline[struct{}],
+		params []any,
+	) error {
+		knitId := cl.Args()[ARG_KNITID][0]
+		result, err := verify(ctx, client, knitId)
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(cl.Stdout())
+		enc.SetIndent("", "    ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+
+		if !result.Ok {
+			return fmt.Errorf("%w: Knit Id:%s", ErrChecksumMismatched, knitId)
+		}
+		logger.Printf("verified Knit Id:%s", knitId)
+		return nil
+	}
+}
+
+// RunVerifyData downloads the Data and compares its checksum with the recorded one.
+func RunVerifyData(ctx context.Context, client krst.KnitClient, knitId string) (Result, error) {
+	found := []data.Detail{}
+	if err := client.FindData(
+		ctx, []tags.Tag{{Key: domain.KeyKnitId, Value: knitId}}, nil, nil,
+		func(page []data.Detail) error {
+			found = append(found, page...)
+			return nil
+		},
+	); err != nil {
+		return Result{}, err
+	}
+	if len(found) == 0 {
+		return Result{}, fmt.Errorf("%w: Knit Id:%s", ErrNotFoundData, knitId)
+	}
+	expected := found[0].Checksum
+	if expected == "" {
+		return Result{}, fmt.Errorf("%w: Knit Id:%s", ErrChecksumNotFound, knitId)
+	}
+
+	checksum := archive.NewChecksum()
+	if err := client.GetData(ctx, knitId, func(fe krst.FileEntry) error {
+		switch fe.Header.Typeflag {
+		case tar.TypeReg:
+			return checksum.AddFile(fe.Header.Name, fe.Body)
+		case tar.TypeSymlink:
+			checksum.AddSymlink(fe.Header.Name, fe.Header.Linkname)
+		}
+		return nil
+	}); err != nil {
+		return Result{}, err
+	}
+
+	actual := checksum.Sum()
+	return Result{
+		KnitId:   knitId,
+		Expected: expected,
+		Actual:   actual,
+		Ok:       expected == actual,
+	}, nil
+}
@@ -0,0 +1,201 @@
+package verify_test
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab-api-types/data"
+	"github.com/opst/knitfab-api-types/tags"
+	kprof "github.com/opst/knitfab/cmd/knit/config/profiles"
+	kenv "github.com/opst/knitfab/cmd/knit/env"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/cmd/knit/rest/mock"
+	data_verify "github.com/opst/knitfab/cmd/knit/subcommands/data/verify"
+	"github.com/opst/knitfab/cmd/knit/subcommands/internal/commandline"
+	"github.com/opst/knitfab/cmd/knit/subcommands/logger"
+	"github.com/opst/knitfab/pkg/utils/archive"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestVerifyCommand(t *testing.T) {
+	type when struct {
+		result data_verify.Result
+		err    error
+	}
+
+	type then struct {
+		err error
+	}
+
+	theory := func(when when, then then) func(*testing.T) {
+		return func(t *testing.T) {
+			profile := &kprof.KnitProfile{ApiRoot: "http://api.knit.invalid"}
+			client := try.To(krst.NewClient(profile)).OrFatal(t)
+
+			verifyMock := func(
+				ctx context.Context,
+				client krst.KnitClient,
+				knitId string,
+			) (data_verify.Result, error) {
+				if knitId != "test-Id" {
+					t.Errorf("knitId: got %s, but want %s", knitId, "test-Id")
+				}
+				return when.result, when.err
+			}
+
+			testee := data_verify.Task(verifyMock)
+
+			stdout := new(strings.Builder)
+			stderr := new(strings.Builder)
+
+			ctx := context.Background()
+			err := testee(
+				ctx,
+				logger.Null(),
+				*kenv.New(),
+				client,
+				commandline.MockCommandline[struct{}]{
+					Fullname_: "knit data verify",
+					Stdout_:   stdout,
+					Stderr_:   stderr,
+					Flags_:    struct{}{},
+					Args_: map[string][]string{
+						data_verify.ARG_KNITID: {"test-Id"},
+					},
+				},
+				[]any{},
+			)
+
+			if !errors.Is(err, then.err) {
+				t.Errorf("wrong error: (actual, expected) != (%v, %v)", err, then.err)
+			}
+			if when.err != nil {
+				return
+			}
+
+			var got data_verify.Result
+			if err := json.Unmarshal([]byte(stdout.String()), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != when.result {
+				t.Errorf("output: (actual, expected) = (%+v, %+v)", got, when.result)
+			}
+		}
+	}
+
+	t.Run("when checksums are matched, it succeeds", theory(
+		when{
+			result: data_verify.Result{
+				KnitId: "test-Id", Expected: "sha256:aaaa", Actual: "sha256:aaaa", Ok: true,
+			},
+		},
+		then{err: nil},
+	))
+
+	t.Run("when checksums are mismatched, it fails", theory(
+		when{
+			result: data_verify.Result{
+				KnitId: "test-Id", Expected: "sha256:aaaa", Actual: "sha256:bbbb", Ok: false,
+			},
+		},
+		then{err: data_verify.ErrChecksumMismatched},
+	))
+
+	{
+		expectedError := errors.New("fake error")
+		t.Run("when error is caused in verifier, it returns the error", theory(
+			when{err: expectedError},
+			then{err: expectedError},
+		))
+	}
+}
+
+func TestRunVerifyData(t *testing.T) {
+	files := map[string]string{
+		"a.txt":     "alpha",
+		"dir/b.txt": "bravo",
+	}
+	checksumOf := func(files map[string]string) string {
+		c := archive.NewChecksum()
+		for name, content := range files {
+			if err := c.AddFile(name, strings.NewReader(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return c.Sum()
+	}
+	setup := func(t *testing.T, checksum string) krst.KnitClient {
+		client := mock.New(t)
+		client.Impl.FindData = func(
+			ctx context.Context, tag []tags.Tag, since *time.Time, duration *time.Duration,
+			handler func([]data.Detail) error,
+		) error {
+			want := []tags.Tag{{Key: "knit#id", Value: "test-Id"}}
+			if len(tag) != 1 || tag[0] != want[0] {
+				t.Errorf("unexpected tags: %+v", tag)
+			}
+			return handler([]data.Detail{{KnitId: "test-Id", Checksum: checksum}})
+		}
+		client.Impl.GetData = func(ctx context.Context, knitId string, handler func(krst.FileEntry) error) error {
+			for name, content := range files {
+				if err := handler(krst.FileEntry{
+					Header: tar.Header{Name: name, Typeflag: tar.TypeReg, Size: int64(len(content))},
+					Body:   strings.NewReader(content),
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return client
+	}
+
+	t.Run("when the content is not changed, it is ok", func(t *testing.T) {
+		want := checksumOf(files)
+		client := setup(t, want)
+
+		got := try.To(data_verify.RunVerifyData(context.Background(), client, "test-Id")).OrFatal(t)
+		expected := data_verify.Result{KnitId: "test-Id", Expected: want, Actual: want, Ok: true}
+		if got != expected {
+			t.Errorf("result: (actual, expected) = (%+v, %+v)", got, expected)
+		}
+	})
+
+	t.Run("when the content is changed, it is not ok", func(t *testing.T) {
+		recorded := checksumOf(map[string]string{"a.txt": "alpha", "dir/b.txt": "BRAVO"})
+		client := setup(t, recorded)
+
+		got := try.To(data_verify.RunVerifyData(context.Background(), client, "test-Id")).OrFatal(t)
+		expected := data_verify.Result{KnitId: "test-Id", Expected: recorded, Actual: checksumOf(files), Ok: false}
+		if got != expected {
+			t.Errorf("result: (actual, expected) = (%+v, %+v)", got, expected)
+		}
+	})
+
+	t.Run("when the checksum is not recorded, it returns ErrChecksumNotFound", func(t *testing.T) {
+		client := setup(t, "")
+		_, err := data_verify.RunVerifyData(context.Background(), client, "test-Id")
+		if !errors.Is(err, data_verify.ErrChecksumNotFound) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("when the data is not found, it returns ErrNotFoundData", func(t *testing.T) {
+		client := mock.New(t)
+		client.Impl.FindData = func(
+			ctx context.Context, tag []tags.Tag, since *time.Time, duration *time.Duration,
+			handler func([]data.Detail) error,
+		) error {
+			return nil
+		}
+		_, err := data_verify.RunVerifyData(context.Background(), client, "test-Id")
+		if !errors.Is(err, data_verify.ErrNotFoundData) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package login
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/opst/knitfab/cmd/knit/config/profiles"
+	"github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/cmd/knit/subcommands/common"
+	"github.com/youta-t/flarc"
+	"golang.org/x/term"
+)
+
+type Flag struct {
+	Email         string `flag:"email" alias:"e" help:"email address of your account."`
+	PasswordStdin bool   `flag:"password-stdin" help:"read password from stdin, instead of prompting."`
+}
+
+func New() (flarc.Command, error) {
+	return flarc.NewCommand(
+		"Login to Knitfab, and save the credential into your knitprofile.",
+		Flag{},
+		flarc.Args{},
+		common.NewTaskWithCommonFlag(func(
+			ctx context.Context,
+			logger *log.Logger,
+			cf common.CommonFlags,
+			cl flarc.Commandline[Flag],
+			params []any,
+		) error {
+			flags := cl.Flags()
+			if flags.Email == "" {
+				logger.Println("--email is required")
+				return flarc.ErrUsage
+			}
+
+			store, err := profiles.LoadProfileStore(cf.ProfileStore)
+			if err != nil {
+				return fmt.Errorf(
+					"%w: failed to load knitprofile store (%s). Please try `knit init` first",
+					err, cf.ProfileStore,
+				)
+			}
+			prof, ok := store[cf.Profile]
+			if !ok {
+				return fmt.Errorf(
+					"profile '%s' not found in the profile store (%s)",
+					cf.Profile, cf.ProfileStore,
+				)
+			}
+
+			password, err := readPassword(cl.Stdin(), cl.Stderr(), flags.PasswordStdin)
+			if err != nil {
+				return fmt.Errorf("failed to read password: %w", err)
+			}
+
+			// login with fresh client, not to send current (maybe expired) credential.
+			anonymous := *prof
+			anonymous.Credential = nil
+			client, err := rest.NewClient(&anonymous)
+			if err != nil {
+				return fmt.Errorf(
+					"%w: failed to create knit client. Your knitprofile (%s in %s) can be broken",
+					err, cf.Profile, cf.ProfileStore,
+				)
+			}
+
+			if err := Task(ctx, logger, client, prof, flags.Email, password); err != nil {
+				return err
+			}
+			if err := store.Save(cf.ProfileStore); err != nil {
+				return fmt.Errorf(
+					"failed to save profile store (%s) : %w", cf.ProfileStore, err,
+				)
+			}
+			logger.Printf("credential is saved into profile %s", cf.Profile)
+			return nil
+		}),
+		flarc.WithDescription(`
+Login to Knitfab with your email and password.
+
+"{{ .Command }}" issues a new credential, and save it into the knitprofile
+( given by "--profile" ). The credential is refreshed automatically
+while you use other commands.
+
+Password is prompted, or read from stdin with "--password-stdin".
+`),
+	)
+}
+
+// Task issues a new credential and set it into the profile.
+func Task(
+	ctx context.Context,
+	logger *log.Logger,
+	client rest.KnitClient,
+	prof *profiles.KnitProfile,
+	email string,
+	password string,
+) error {
+	tok, err := client.IssueToken(ctx, email, password)
+	if err != nil {
+		return err
+	}
+
+	prof.Credential = &profiles.KnitCredential{
+		Token:     tok.Token,
+		IssuedAt:  tok.IssuedAt.Time(),
+		ExpiresAt: tok.ExpiresAt.Time(),
+	}
+	logger.Printf("logged in as %s (expires at %s)", email, tok.ExpiresAt)
+	return nil
+}
+
+func readPassword(stdin io.Reader, prompt io.Writer, fromStdin bool) (string, error) {
+	if f, ok := stdin.(*os.File); ok && !fromStdin && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(prompt, "Password: ")
+		b, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(prompt)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
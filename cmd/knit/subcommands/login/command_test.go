@@ -0,0 +1,67 @@
+package login_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apiauth "github.com/opst/knitfab-api-types/auth"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	"github.com/opst/knitfab/cmd/knit/config/profiles"
+	"github.com/opst/knitfab/cmd/knit/rest/mock"
+	"github.com/opst/knitfab/cmd/knit/subcommands/logger"
+	"github.com/opst/knitfab/cmd/knit/subcommands/login"
+)
+
+func TestLogin(t *testing.T) {
+	t.Run("when client issues token, it is set into the profile", func(t *testing.T) {
+		ctx := context.Background()
+		issuedAt := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+
+		kc := mock.New(t)
+		kc.Impl.IssueToken = func(ctx context.Context, email string, password string) (apiauth.Token, error) {
+			if email != "user@example.com" || password != "p@ssw0rd" {
+				t.Errorf("unexpected credential: %s, %s", email, password)
+			}
+			return apiauth.Token{
+				Token:     "issued-token",
+				IssuedAt:  rfctime.RFC3339(issuedAt),
+				ExpiresAt: rfctime.RFC3339(issuedAt.Add(time.Hour)),
+			}, nil
+		}
+
+		prof := &profiles.KnitProfile{ApiRoot: "https://api.example.com"}
+		if err := login.Task(ctx, logger.Null(), kc, prof, "user@example.com", "p@ssw0rd"); err != nil {
+			t.Fatal(err)
+		}
+
+		if prof.Credential == nil {
+			t.Fatal("credential is not set")
+		}
+		if prof.Credential.Token != "issued-token" ||
+			!prof.Credential.IssuedAt.Equal(issuedAt) ||
+			!prof.Credential.ExpiresAt.Equal(issuedAt.Add(time.Hour)) {
+			t.Errorf("unexpected credential: %+v", prof.Credential)
+		}
+	})
+
+	t.Run("when client returns error, it returns the error and keeps the profile", func(t *testing.T) {
+		ctx := context.Background()
+		expectedErr := errors.New("fake error")
+
+		kc := mock.New(t)
+		kc.Impl.IssueToken = func(ctx context.Context, email string, password string) (apiauth.Token, error) {
+			return apiauth.Token{}, expectedErr
+		}
+
+		current := &profiles.KnitCredential{Token: "current-token"}
+		prof := &profiles.KnitProfile{ApiRoot: "https://api.example.com", Credential: current}
+		if err := login.Task(ctx, logger.Null(), kc, prof, "user@example.com", "wrong"); !errors.Is(err, expectedErr) {
+			t.Errorf("unexpected error: %+v", err)
+		}
+		if prof.Credential != current {
+			t.Errorf("credential is changed: %+v", prof.Credential)
+		}
+	})
+}
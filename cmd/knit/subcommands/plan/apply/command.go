@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab-api-types/runs"
 	"github.com/opst/knitfab/cmd/knit/env"
 	krest "github.com/opst/knitfab/cmd/knit/rest"
 	"github.com/opst/knitfab/cmd/knit/subcommands/common"
@@ -15,8 +16,16 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+type Flags struct {
+	Supersede string `flag:"supersede" metavar:"PLAN_ID" help:"Register the Plan as the successor of the specified Plan. The predecessor is deactivated."`
+	Reroute   bool   `flag:"reroute" help:"With --supersede, drop Data waiting for the predecessor, so the predecessor makes no more Runs. The successor takes Data matching its inputs regardless of this flag."`
+	DryRun    bool   `flag:"dry-run" help:"Do not register the Plan. Print Runs which would be created by the Plan instead. It cannot be used with --supersede."`
+}
+
 type Option struct {
-	applyfunc func(context.Context, krest.KnitClient, plans.PlanSpec) (plans.Detail, error)
+	applyfunc     func(context.Context, krest.KnitClient, plans.PlanSpec) (plans.Detail, error)
+	supersedefunc func(context.Context, krest.KnitClient, string, plans.PlanSpec, bool) (plans.Detail, error)
+	previewfunc   func(context.Context, krest.KnitClient, plans.PlanSpec) ([]runs.Preview, error)
 }
 
 func WithApply(
@@ -28,13 +37,33 @@ func WithApply(
 	}
 }
 
+func WithSupersede(
+	supersede func(context.Context, krest.KnitClient, string, plans.PlanSpec, bool) (plans.Detail, error),
+) func(*Option) *Option {
+	return func(dfc *Option) *Option {
+		dfc.supersedefunc = supersede
+		return dfc
+	}
+}
+
+func WithPreview(
+	preview func(context.Context, krest.KnitClient, plans.PlanSpec) ([]runs.Preview, error),
+) func(*Option) *Option {
+	return func(dfc *Option) *Option {
+		dfc.previewfunc = preview
+		return dfc
+	}
+}
+
 const (
 	ARG_PLAN_FILE = "PLAN_FILE"
 )
 
 func New(options ...func(*Option) *Option) (flarc.Command, error) {
 	option := &Option{
-		applyfunc: ApplyPlan,
+		applyfunc:     ApplyPlan,
+		supersedefunc: SupersedePlan,
+		previewfunc:   PreviewPlan,
 	}
 	for _, opt := range options {
 		option = opt(option)
@@ -42,28 +71,38 @@ func New(options ...func(*Option) *Option) (flarc.Command, error) {
 
 	return flarc.NewCommand(
 		"Apply Plan file as a Plan in knitfab.",
-		struct{}{},
+		Flags{},
 		flarc.Args{
 			{
 				Name: ARG_PLAN_FILE, Required: true,
 				Help: "Path to the Plan file. If you need it, try `knit plan template`",
 			},
 		},
-		common.NewTask(Task(option.applyfunc)),
+		common.NewTask(Task(option.applyfunc, option.supersedefunc, option.previewfunc)),
 	)
 }
 
 func Task(
 	applyFunc func(context.Context, krest.KnitClient, plans.PlanSpec) (plans.Detail, error),
-) common.Task[struct{}] {
+	supersedeFunc func(context.Context, krest.KnitClient, string, plans.PlanSpec, bool) (plans.Detail, error),
+	previewFunc func(context.Context, krest.KnitClient, plans.PlanSpec) ([]runs.Preview, error),
+) common.Task[Flags] {
 	return func(
 		ctx context.Context,
 		logger *log.Logger,
 		knitEnv env.KnitEnv,
 		client krest.KnitClient,
-		cl flarc.Commandline[struct{}],
+		cl flarc.Commandline[Flags],
 		params []any,
 	) error {
+		flags := cl.Flags()
+		if flags.Reroute && flags.Supersede == "" {
+			return fmt.Errorf("%w: --reroute requires --supersede", flarc.ErrUsage)
+		}
+		if flags.DryRun && flags.Supersede != "" {
+			return fmt.Errorf("%w: --dry-run cannot be used with --supersede", flarc.ErrUsage)
+		}
+
 		args := cl.Args()
 		buf, err := os.ReadFile(args[ARG_PLAN_FILE][0])
 		if err != nil {
@@ -75,13 +114,27 @@ func Task(
 			return fmt.Errorf("fail to parse Plan file: %w", err)
 		}
 
-		data, err := applyFunc(ctx, client, *spec)
+		enc := json.NewEncoder(cl.Stdout())
+		enc.SetIndent("", "    ")
+
+		if flags.DryRun {
+			previews, err := previewFunc(ctx, client, *spec)
+			if err != nil {
+				return fmt.Errorf("failed to preview Plan: %w", err)
+			}
+			return enc.Encode(previews)
+		}
+
+		var data plans.Detail
+		if flags.Supersede != "" {
+			data, err = supersedeFunc(ctx, client, flags.Supersede, *spec, flags.Reroute)
+		} else {
+			data, err = applyFunc(ctx, client, *spec)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to apply Plan: %w", err)
 		}
 
-		enc := json.NewEncoder(cl.Stdout())
-		enc.SetIndent("", "    ")
 		if err := enc.Encode(data); err != nil {
 			return err
 		}
@@ -102,3 +155,22 @@ func ApplyPlan(
 	}
 	return result, nil
 }
+
+func SupersedePlan(
+	ctx context.Context,
+	client krest.KnitClient,
+	predecessor string,
+	spec plans.PlanSpec,
+	reroute bool,
+) (plans.Detail, error) {
+	return client.SupersedePlan(ctx, predecessor, spec, reroute)
+}
+
+// PreviewPlan returns Runs which would be created when the Plan is applied.
+func PreviewPlan(
+	ctx context.Context,
+	client krest.KnitClient,
+	spec plans.PlanSpec,
+) ([]runs.Preview, error) {
+	return client.PreviewPlan(ctx, spec)
+}
@@ -3,12 +3,21 @@ package apply_test
 import (
 	"context"
 	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab-api-types/runs"
 	"github.com/opst/knitfab-api-types/tags"
+	"github.com/opst/knitfab/cmd/knit/env"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
 	restmock "github.com/opst/knitfab/cmd/knit/rest/mock"
+	"github.com/opst/knitfab/cmd/knit/subcommands/internal/commandline"
+	"github.com/opst/knitfab/cmd/knit/subcommands/logger"
 	plan_apply "github.com/opst/knitfab/cmd/knit/subcommands/plan/apply"
+	"github.com/youta-t/flarc"
 )
 
 func TestApplyPlan(t *testing.T) {
@@ -157,6 +166,111 @@ func TestApplyPlan(t *testing.T) {
 	))
 }
 
+func TestTask(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.yaml")
+	if err := os.WriteFile(planFile, []byte(`
+image: "test-image:test-version"
+inputs:
+  - path: "/in/1"
+    tags: ["type:raw data"]
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	type then struct {
+		applied    bool
+		superseded *restmock.SupersedePlanArgs
+		previewed  bool
+		err        error
+	}
+
+	theory := func(flags plan_apply.Flags, then then) func(*testing.T) {
+		return func(t *testing.T) {
+			applied := false
+			previewed := false
+			var superseded *restmock.SupersedePlanArgs
+
+			testee := plan_apply.Task(
+				func(ctx context.Context, kc krst.KnitClient, ps plans.PlanSpec) (plans.Detail, error) {
+					applied = true
+					return plans.Detail{}, nil
+				},
+				func(ctx context.Context, kc krst.KnitClient, predecessor string, ps plans.PlanSpec, reroute bool) (plans.Detail, error) {
+					superseded = &restmock.SupersedePlanArgs{Predecessor: predecessor, Spec: ps, Reroute: reroute}
+					return plans.Detail{}, nil
+				},
+				func(ctx context.Context, kc krst.KnitClient, ps plans.PlanSpec) ([]runs.Preview, error) {
+					previewed = true
+					return []runs.Preview{}, nil
+				},
+			)
+
+			err := testee(
+				context.Background(), logger.Null(), *env.New(), restmock.New(t),
+				commandline.MockCommandline[plan_apply.Flags]{
+					Fullname_: "knit plan apply",
+					Stdout_:   io.Discard,
+					Stderr_:   io.Discard,
+					Flags_:    flags,
+					Args_: map[string][]string{
+						plan_apply.ARG_PLAN_FILE: {planFile},
+					},
+				},
+				[]any{},
+			)
+
+			if !errors.Is(err, then.err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if applied != then.applied {
+				t.Errorf("applied: got %v, want %v", applied, then.applied)
+			}
+			if previewed != then.previewed {
+				t.Errorf("previewed: got %v, want %v", previewed, then.previewed)
+			}
+			if then.superseded == nil {
+				if superseded != nil {
+					t.Errorf("unexpectedly superseded: %+v", superseded)
+				}
+			} else if superseded == nil {
+				t.Errorf("not superseded")
+			} else if superseded.Predecessor != then.superseded.Predecessor || superseded.Reroute != then.superseded.Reroute {
+				t.Errorf("superseded: got %+v, want %+v", superseded, then.superseded)
+			}
+		}
+	}
+
+	t.Run("without --supersede, it registers a new plan", theory(
+		plan_apply.Flags{},
+		then{applied: true},
+	))
+
+	t.Run("with --supersede, it registers a successor", theory(
+		plan_apply.Flags{Supersede: "plan-1"},
+		then{superseded: &restmock.SupersedePlanArgs{Predecessor: "plan-1"}},
+	))
+
+	t.Run("with --supersede and --reroute, it registers a successor with rerouting", theory(
+		plan_apply.Flags{Supersede: "plan-1", Reroute: true},
+		then{superseded: &restmock.SupersedePlanArgs{Predecessor: "plan-1", Reroute: true}},
+	))
+
+	t.Run("with --reroute only, it causes usage error", theory(
+		plan_apply.Flags{Reroute: true},
+		then{err: flarc.ErrUsage},
+	))
+
+	t.Run("with --dry-run, it previews runs without registering", theory(
+		plan_apply.Flags{DryRun: true},
+		then{previewed: true},
+	))
+
+	t.Run("with --dry-run and --supersede, it causes usage error", theory(
+		plan_apply.Flags{DryRun: true, Supersede: "plan-1"},
+		then{err: flarc.ErrUsage},
+	))
+}
+
 func ref[T any](v T) *T {
 	return &v
 }
@@ -6,7 +6,9 @@ import (
 	plan_apply "github.com/opst/knitfab/cmd/knit/subcommands/plan/apply"
 	plan_find "github.com/opst/knitfab/cmd/knit/subcommands/plan/find"
 	plan_graph "github.com/opst/knitfab/cmd/knit/subcommands/plan/graph"
+	plan_priority "github.com/opst/knitfab/cmd/knit/subcommands/plan/priority"
 	plan_resource "github.com/opst/knitfab/cmd/knit/subcommands/plan/resource"
+	plan_rm "github.com/opst/knitfab/cmd/knit/subcommands/plan/rm"
 	plan_serviceaccount "github.com/opst/knitfab/cmd/knit/subcommands/plan/serviceaccount"
 	plan_show "github.com/opst/knitfab/cmd/knit/subcommands/plan/show"
 	plan_template "github.com/opst/knitfab/cmd/knit/subcommands/plan/template"
@@ -45,6 +47,11 @@ func New() (flarc.Command, error) {
 		return nil, err
 	}
 
+	priority, err := plan_priority.New()
+	if err != nil {
+		return nil, err
+	}
+
 	annotate, err := plan_annotate.New()
 	if err != nil {
 		return nil, err
@@ -60,6 +67,11 @@ func New() (flarc.Command, error) {
 		return nil, err
 	}
 
+	rm, err := plan_rm.New()
+	if err != nil {
+		return nil, err
+	}
+
 	return flarc.NewCommandGroup(
 		"Manipulate Knitfab Plan.",
 		struct{}{},
@@ -70,8 +82,10 @@ func New() (flarc.Command, error) {
 		flarc.WithSubcommand("apply", apply),
 		flarc.WithSubcommand("active", active),
 		flarc.WithSubcommand("resource", resource),
+		flarc.WithSubcommand("priority", priority),
 		flarc.WithSubcommand("annotate", annotate),
 		flarc.WithSubcommand("serviceaccount", serviceaccount),
+		flarc.WithSubcommand("rm", rm),
 	)
 
 }
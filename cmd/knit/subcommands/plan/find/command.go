@@ -2,7 +2,6 @@ package find
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 
@@ -33,7 +32,8 @@ type Option struct {
 		imageVer *domain.ImageIdentifier,
 		inTags []tags.Tag,
 		outTags []tags.Tag,
-	) ([]plans.Detail, error)
+		handler func([]plans.Detail) error,
+	) error
 }
 
 func WithFind(
@@ -45,7 +45,8 @@ func WithFind(
 		imageVer *domain.ImageIdentifier,
 		inTags []tags.Tag,
 		outTags []tags.Tag,
-	) ([]plans.Detail, error),
+		handler func([]plans.Detail) error,
+	) error,
 ) func(*Option) *Option {
 	return func(dfc *Option) *Option {
 		dfc.find = find
@@ -115,7 +116,8 @@ func Task(
 		imageVer *domain.ImageIdentifier,
 		inTags []tags.Tag,
 		outTags []tags.Tag,
-	) ([]plans.Detail, error),
+		handler func([]plans.Detail) error,
+	) error,
 ) common.Task[Flag] {
 	return func(
 		ctx context.Context,
@@ -160,18 +162,14 @@ func Task(
 			outTags = *flags.OutTags
 		}
 
-		plan, err := find(ctx, logger, client, activateFlag, imageVer, inTags, outTags)
-		if err != nil {
+		enc := common.NewJsonArrayEncoder[plans.Detail](cl.Stdout())
+		if err := find(
+			ctx, logger, client, activateFlag, imageVer, inTags, outTags, enc.Encode,
+		); err != nil {
 			return err
 		}
 
-		enc := json.NewEncoder(cl.Stdout())
-		enc.SetIndent("", "    ")
-		if err := enc.Encode(plan); err != nil {
-			return err
-		}
-
-		return nil
+		return enc.Close()
 	}
 }
 
@@ -183,12 +181,7 @@ func RunFindPlan(
 	imageVer *domain.ImageIdentifier,
 	inTags []tags.Tag,
 	outTags []tags.Tag,
-) ([]plans.Detail, error) {
-
-	result, err := client.FindPlan(ctx, active, imageVer, inTags, outTags)
-	if err != nil {
-		return nil, err
-	}
-
-	return result, nil
+	handler func([]plans.Detail) error,
+) error {
+	return client.FindPlan(ctx, active, imageVer, inTags, outTags, handler)
 }
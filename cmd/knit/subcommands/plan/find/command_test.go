@@ -124,7 +124,8 @@ func TestFindCommand(t *testing.T) {
 				image *domain.ImageIdentifier,
 				inTags []tags.Tag,
 				outTags []tags.Tag,
-			) ([]plans.Detail, error) {
+				handler func([]plans.Detail) error,
+			) error {
 				if active != then.active {
 					t.Errorf(
 						"wrong active: (actual, expected) != (%d, %d)",
@@ -161,7 +162,10 @@ func TestFindCommand(t *testing.T) {
 					)
 				}
 
-				return when.presentation, when.err
+				if when.err != nil {
+					return when.err
+				}
+				return handler(when.presentation)
 			}
 
 			testee := plan_find.Task(task)
@@ -447,8 +451,9 @@ func TestRunFindPlan(t *testing.T) {
 		mock.Impl.FindPlan = func(
 			ctx context.Context, active logic.Ternary, imageVer *domain.ImageIdentifier,
 			inTags []tags.Tag, outTags []tags.Tag,
-		) ([]plans.Detail, error) {
-			return expectedValue, nil
+			handler func([]plans.Detail) error,
+		) error {
+			return handler(expectedValue)
 		}
 
 		// arguments set up
@@ -459,8 +464,16 @@ func TestRunFindPlan(t *testing.T) {
 		output := []tags.Tag{{Key: "foo", Value: "bar"}}
 
 		// test start
-		actual := try.To(plan_find.RunFindPlan(
-			ctx, log, mock, logic.Indeterminate, imageVer, input, output)).OrFatal(t)
+		actual := []plans.Detail{}
+		if err := plan_find.RunFindPlan(
+			ctx, log, mock, logic.Indeterminate, imageVer, input, output,
+			func(page []plans.Detail) error {
+				actual = append(actual, page...)
+				return nil
+			},
+		); err != nil {
+			t.Fatal(err)
+		}
 
 		if !cmp.SliceContentEqWith(actual, expectedValue, plans.Detail.Equal) {
 			t.Errorf(
@@ -480,8 +493,9 @@ func TestRunFindPlan(t *testing.T) {
 		mock.Impl.FindPlan = func(
 			ctx context.Context, active logic.Ternary, imageVer *domain.ImageIdentifier,
 			inTags []tags.Tag, outTags []tags.Tag,
-		) ([]plans.Detail, error) {
-			return nil, expectedError
+			handler func([]plans.Detail) error,
+		) error {
+			return expectedError
 		}
 
 		// argements set up
@@ -492,10 +506,16 @@ func TestRunFindPlan(t *testing.T) {
 		output := []tags.Tag{{Key: "foo", Value: "bar"}}
 
 		// test start
-		actual, err := plan_find.RunFindPlan(
-			ctx, log, mock, logic.Indeterminate, imageVer, input, output)
+		actual := []plans.Detail{}
+		err := plan_find.RunFindPlan(
+			ctx, log, mock, logic.Indeterminate, imageVer, input, output,
+			func(page []plans.Detail) error {
+				actual = append(actual, page...)
+				return nil
+			},
+		)
 
-		if actual != nil {
+		if len(actual) != 0 {
 			t.Errorf("unexpected value is returned: %+v", actual)
 		}
 
@@ -17,9 +17,10 @@ import (
 )
 
 type Flag struct {
-	Upstream   *bool       `flag:"upstream" alias:"u" help:"Trace the upstream of the specified Plan."`
-	Downstream *bool       `flag:"downstream" alias:"d" help:"Trace the downstream of the specified Plan."`
-	Numbers    *args.Depth `flag:"numbers" alias:"n" help:"Trace up to the specified depth. Trace to the upstream-most/downstream-most if 'all' is specified.,metavar=number of depth"`
+	Upstream   *bool             `flag:"upstream" alias:"u" help:"Trace the upstream of the specified Plan."`
+	Downstream *bool             `flag:"downstream" alias:"d" help:"Trace the downstream of the specified Plan."`
+	Numbers    *args.Depth       `flag:"numbers" alias:"n" help:"Trace up to the specified depth. Trace to the upstream-most/downstream-most if 'all' is specified.,metavar=number of depth"`
+	Format     *knitgraph.Format `flag:"format" alias:"f" help:"Output format. One of dot, prov-json, openlineage, json or mermaid.,metavar=format"`
 }
 
 const ARG_PLANID = "PLAN_ID"
@@ -31,6 +32,7 @@ func New() (flarc.Command, error) {
 			Upstream:   nil,
 			Downstream: nil,
 			Numbers:    pointer.Ref(args.NewDepth(3)),
+			Format:     pointer.Ref(knitgraph.FormatDot),
 		},
 		flarc.Args{
 			{
@@ -64,6 +66,17 @@ To traverse only the upstream or downstream Plans, use the --upstream or --downs
 
 	{{ .Command }} PLAN_ID --upstream | dot -Tpng -o graph.png
 	{{ .Command }} PLAN_ID --downstream | dot -Tpng -o graph.png
+
+To output in other formats, use the --format flag.
+
+- dot: Graphviz dot format (default).
+- prov-json: W3C PROV-JSON. Plans are plans/agents, influenced by their upstream Plans.
+- openlineage: OpenLineage JobEvents, one event per line.
+  Outputs of Plans are datasets named as "PLAN_ID:PATH".
+- json: Plans and edges between them in JSON.
+- mermaid: Mermaid flowchart.
+
+	{{ .Command }} PLAN_ID --format mermaid
 `),
 	)
 }
@@ -105,7 +118,7 @@ func Task(makeGraph MakeGraphFunc) common.Task[Flag] {
 			return err
 		}
 
-		return graph.GenerateDot(cl.Stdout())
+		return graph.Generate(cl.Stdout(), nils.Default(cl.Flags().Format, knitgraph.FormatDot))
 	}
 }
 
@@ -0,0 +1,84 @@
+package priority
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab/cmd/knit/env"
+	"github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/cmd/knit/subcommands/common"
+	"github.com/youta-t/flarc"
+)
+
+type Flag struct {
+	Priority       *int `flag:"priority" metavar:"N" help:"Set the priority of Runs of the Plan. Higher is started earlier."`
+	MaxConcurrency *int `flag:"max-concurrency" metavar:"N" help:"Set the maximum number of Runs of the Plan running at once. 0 means unlimited."`
+}
+
+const ARGS_PLAN_ID = "PLAN_ID"
+
+func New() (flarc.Command, error) {
+	return flarc.NewCommand(
+		"set the priority and the concurrency limit of a plan",
+		Flag{},
+		flarc.Args{
+			{
+				Name: ARGS_PLAN_ID, Required: true,
+				Help: "Specify the id of the Plan to be updated its priority.",
+			},
+		},
+		common.NewTask(Task()),
+		flarc.WithDescription(`
+Set the priority and the concurrency limit of a Plan.
+
+When there are Runs ready to start, Runs of Plans with higher priority are started earlier.
+The default priority is 0, and it can be negative.
+
+A Plan with --max-concurrency N has at most N Runs starting or running at once.
+The others wait in "ready" status. 0 means unlimited.
+
+Flags not specified are left unchanged.
+`),
+	)
+}
+
+func Task() common.Task[Flag] {
+	return func(
+		ctx context.Context,
+		logger *log.Logger,
+		knitEnv env.KnitEnv,
+		client rest.KnitClient,
+		cl flarc.Commandline[Flag],
+		params []any,
+	) error {
+		planId := cl.Args()[ARGS_PLAN_ID][0]
+		flags := cl.Flags()
+
+		if flags.Priority == nil && flags.MaxConcurrency == nil {
+			return fmt.Errorf("%w: either --priority or --max-concurrency is required", flarc.ErrUsage)
+		}
+		if flags.MaxConcurrency != nil && *flags.MaxConcurrency < 0 {
+			return fmt.Errorf("%w: --max-concurrency should not be negative", flarc.ErrUsage)
+		}
+
+		resp, err := client.UpdatePriority(ctx, planId, plans.PriorityChange{
+			Priority:       flags.Priority,
+			MaxConcurrency: flags.MaxConcurrency,
+		})
+		if err != nil {
+			return err
+		}
+		logger.Printf("Plan %s: priority is updated", planId)
+
+		j := json.NewEncoder(cl.Stdout())
+		j.SetIndent("", "    ")
+		if err := j.Encode(resp); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
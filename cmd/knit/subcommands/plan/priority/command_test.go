@@ -0,0 +1,120 @@
+package priority_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab/cmd/knit/env"
+	"github.com/opst/knitfab/cmd/knit/rest/mock"
+	"github.com/opst/knitfab/cmd/knit/subcommands/internal/commandline"
+	"github.com/opst/knitfab/cmd/knit/subcommands/logger"
+	"github.com/opst/knitfab/cmd/knit/subcommands/plan/priority"
+	"github.com/youta-t/flarc"
+)
+
+func TestTask(t *testing.T) {
+	ref := func(i int) *int { return &i }
+
+	type When struct {
+		Flags priority.Flag
+
+		Response plans.Detail
+		RespErr  error
+	}
+
+	type Then struct {
+		IsCalled bool
+		Err      error
+	}
+
+	theory := func(when When, then Then) func(t *testing.T) {
+		return func(t *testing.T) {
+			stdout := new(strings.Builder)
+			cl := commandline.MockCommandline[priority.Flag]{
+				Fullname_: "priority",
+				Flags_:    when.Flags,
+				Args_:     map[string][]string{priority.ARGS_PLAN_ID: {"plan-1"}},
+				Stdout_:   stdout,
+				Stderr_:   new(strings.Builder),
+			}
+
+			client := mock.New(t)
+			client.Impl.UpdatePriority = func(ctx context.Context, planId string, change plans.PriorityChange) (plans.Detail, error) {
+				if planId != "plan-1" {
+					t.Errorf("planId: got %v, want %v", planId, "plan-1")
+				}
+				if change.Priority != when.Flags.Priority {
+					t.Errorf("priority: got %v, want %v", change.Priority, when.Flags.Priority)
+				}
+				if change.MaxConcurrency != when.Flags.MaxConcurrency {
+					t.Errorf("max concurrency: got %v, want %v", change.MaxConcurrency, when.Flags.MaxConcurrency)
+				}
+				return when.Response, when.RespErr
+			}
+
+			err := priority.Task()(
+				context.Background(), logger.Null(), *env.New(), client, cl, []any{},
+			)
+			if isCalled := 0 < len(client.Calls.UpdatePriority); isCalled != then.IsCalled {
+				t.Errorf("UpdatePriority is called: got %v, want %v", isCalled, then.IsCalled)
+			}
+			if then.Err != nil {
+				if !errors.Is(err, then.Err) {
+					t.Errorf("expected error: %v, got: %v", then.Err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := plans.Detail{}
+			if err := json.Unmarshal([]byte(stdout.String()), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(when.Response) {
+				t.Errorf("response: got %v, want %v", got, when.Response)
+			}
+		}
+	}
+
+	t.Run("set priority", theory(
+		When{
+			Flags:    priority.Flag{Priority: ref(10)},
+			Response: plans.Detail{Summary: plans.Summary{PlanId: "plan-1"}, Priority: 10},
+		},
+		Then{IsCalled: true},
+	))
+
+	t.Run("set priority and max concurrency", theory(
+		When{
+			Flags: priority.Flag{Priority: ref(-1), MaxConcurrency: ref(2)},
+			Response: plans.Detail{
+				Summary: plans.Summary{PlanId: "plan-1"}, Priority: -1, MaxConcurrency: 2,
+			},
+		},
+		Then{IsCalled: true},
+	))
+
+	t.Run("no flags (should be error)", theory(
+		When{Flags: priority.Flag{}},
+		Then{IsCalled: false, Err: flarc.ErrUsage},
+	))
+
+	t.Run("negative max concurrency (should be error)", theory(
+		When{Flags: priority.Flag{MaxConcurrency: ref(-1)}},
+		Then{IsCalled: false, Err: flarc.ErrUsage},
+	))
+
+	{
+		wantErr := errors.New("test-error")
+		t.Run("client error", theory(
+			When{Flags: priority.Flag{Priority: ref(1)}, RespErr: wantErr},
+			Then{IsCalled: true, Err: wantErr},
+		))
+	}
+}
@@ -18,6 +18,9 @@ import (
 type Flag struct {
 	Set   *ResourceQuantityList `flag:"set" alias:"s" help:"Set resource limits for a Plan. Repeatable"`
 	Unset *Types                `flag:"unset" alias:"u" help:"Unset resource limits for a Plan. Repeatable"`
+
+	SetRequest   *ResourceQuantityList `flag:"set-request" help:"Set resource requests for a Plan. Repeatable"`
+	UnsetRequest *Types                `flag:"unset-request" help:"Unset resource requests for a Plan. Repeatable"`
 }
 
 const ARGS_PLAN_ID = "PLAN_ID"
@@ -26,8 +29,10 @@ func New() (flarc.Command, error) {
 	return flarc.NewCommand(
 		"Set or unset resource limits for a Plan.",
 		Flag{
-			Set:   &ResourceQuantityList{},
-			Unset: &Types{},
+			Set:          &ResourceQuantityList{},
+			Unset:        &Types{},
+			SetRequest:   &ResourceQuantityList{},
+			UnsetRequest: &Types{},
 		},
 		flarc.Args{
 			{
@@ -58,7 +63,16 @@ Even if you unset cpu or memory, the default value will be used: "1" for cpu and
 
 Flags --set and --unset are repeatable and can be passed at once.
 If you pass --set for same key multiple times, the last value will take precedence.
-If you pass --set and --unset for same key, --set will take precedence.`,
+If you pass --set and --unset for same key, --set will take precedence.
+
+Resource requests, which are used for scheduling, can be changed as well
+by --set-request and --unset-request, in the same way as --set and --unset.
+Requests lower than limits allow Runs to burst over them when nodes have room.
+Requests should not exceed limits.
+
+    {{ .Command }} --set cpu=4 --set-request cpu=500m
+
+Resources without requests are requested as much as limits.`,
 		),
 	)
 }
@@ -73,7 +87,8 @@ func Task() common.Task[Flag] {
 		params []any,
 	) error {
 		flag := cl.Flags()
-		if flag.Set.Empty() && flag.Unset.Empty() {
+		if flag.Set.Empty() && flag.Unset.Empty() &&
+			flag.SetRequest.Empty() && flag.UnsetRequest.Empty() {
 			logger.Println("Nothing to do.")
 			return nil
 		}
@@ -81,8 +96,10 @@ func Task() common.Task[Flag] {
 		planId := cl.Args()[ARGS_PLAN_ID][0]
 
 		change := plans.ResourceLimitChange{
-			Set:   plans.Resources(flag.Set.Map()),
-			Unset: flag.Unset.Slice(),
+			Set:           plans.Resources(flag.Set.Map()),
+			Unset:         flag.Unset.Slice(),
+			SetRequests:   plans.Resources(flag.SetRequest.Map()),
+			UnsetRequests: flag.UnsetRequest.Slice(),
 		}
 
 		pln, err := client.UpdateResources(ctx, planId, change)
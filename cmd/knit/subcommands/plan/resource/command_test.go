@@ -121,6 +121,14 @@ func TestCommand(t *testing.T) {
 					t.Errorf("UpdateResources() res.Unset = %v, want %v", res.Unset, then.Change.Unset)
 				}
 
+				if !cmp.MapEqWith(then.Change.SetRequests, res.SetRequests, resource.Quantity.Equal) {
+					t.Errorf("UpdateResources() res.SetRequests = %v, want %v", res.SetRequests, then.Change.SetRequests)
+				}
+
+				if !cmp.SliceContentEq(then.Change.UnsetRequests, res.UnsetRequests) {
+					t.Errorf("UpdateResources() res.UnsetRequests = %v, want %v", res.UnsetRequests, then.Change.UnsetRequests)
+				}
+
 				return when.PlanReturned, then.Err
 			}
 
@@ -283,6 +291,27 @@ func TestCommand(t *testing.T) {
 		},
 	))
 
+	t.Run("with --set-request and --unset-request", theory(
+		When{
+			Flags: plan_resource.Flag{
+				SetRequest:   &plan_resource.ResourceQuantityList{"cpu": resource.MustParse("500m")},
+				UnsetRequest: &plan_resource.Types{"memory"},
+			},
+			Args: map[string][]string{
+				plan_resource.ARGS_PLAN_ID: {"test"},
+			},
+			PlanReturned: planRetuened,
+		},
+		Then{
+			WantApiCalled: true,
+			PlanId:        "test",
+			Change: plans.ResourceLimitChange{
+				SetRequests:   map[string]resource.Quantity{"cpu": resource.MustParse("500m")},
+				UnsetRequests: []string{"memory"},
+			},
+		},
+	))
+
 	fakeError := errors.New("fake error")
 	t.Run("client returns error", theory(
 		When{
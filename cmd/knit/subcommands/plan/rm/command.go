@@ -0,0 +1,90 @@
+package rm
+
+import (
+	"context"
+	"log"
+
+	"github.com/opst/knitfab/cmd/knit/env"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/cmd/knit/subcommands/common"
+	"github.com/youta-t/flarc"
+)
+
+type Option struct {
+	remove func(
+		ctx context.Context,
+		client krst.KnitClient,
+		planId string,
+	) error
+}
+
+func WithRemover(
+	remove func(
+		ctx context.Context,
+		client krst.KnitClient,
+		planId string,
+	) error,
+) func(*Option) *Option {
+	return func(opt *Option) *Option {
+		opt.remove = remove
+		return opt
+	}
+}
+
+const ARG_PLAN_ID = "PLAN_ID"
+
+func New(
+	options ...func(*Option) *Option,
+) (flarc.Command, error) {
+	option := &Option{
+		remove: RunDeletePlan,
+	}
+	for _, opt := range options {
+		option = opt(option)
+	}
+
+	return flarc.NewCommand(
+		"Delete Plan for the specified Plan Id.",
+		struct{}{},
+		flarc.Args{
+			{
+				Name:       ARG_PLAN_ID,
+				Required:   true,
+				Repeatable: false,
+				Help:       "Id of the Plan to be deleted.",
+			},
+		},
+		common.NewTask(Task(option.remove)),
+		flarc.WithDescription(`
+Delete Plan for the specified Plan Id.
+
+Only Plans which have no Runs can be deleted.
+To retire a Plan which has Runs, deactivate it with "knit plan active no PLAN_ID",
+or register its successor with "knit plan apply --supersede".
+`),
+	)
+}
+
+func Task(
+	remove func(context.Context, krst.KnitClient, string) error,
+) common.Task[struct{}] {
+	return func(
+		ctx context.Context,
+		logger *log.Logger,
+		knitEnv env.KnitEnv,
+		client krst.KnitClient,
+		cl flarc.Commandline[struct{}],
+		params []any,
+	) error {
+		planId := cl.Args()[ARG_PLAN_ID][0]
+		if err := remove(ctx, client, planId); err != nil {
+			return err
+		}
+		logger.Printf("deleted Plan Id:%v", planId)
+		return nil
+	}
+}
+
+func RunDeletePlan(ctx context.Context, client krst.KnitClient, planId string) error {
+	return client.DeletePlan(ctx, planId)
+}
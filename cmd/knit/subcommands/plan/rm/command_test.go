@@ -0,0 +1,129 @@
+package rm_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	kprof "github.com/opst/knitfab/cmd/knit/config/profiles"
+	kenv "github.com/opst/knitfab/cmd/knit/env"
+	krst "github.com/opst/knitfab/cmd/knit/rest"
+	"github.com/opst/knitfab/cmd/knit/rest/mock"
+	"github.com/opst/knitfab/cmd/knit/subcommands/internal/commandline"
+	"github.com/opst/knitfab/cmd/knit/subcommands/logger"
+	plan_rm "github.com/opst/knitfab/cmd/knit/subcommands/plan/rm"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestDeleteCommand(t *testing.T) {
+	type when struct {
+		planId []string
+		err    error
+	}
+
+	type then struct {
+		planId string
+		err    error
+	}
+
+	theory := func(when when, then then) func(*testing.T) {
+		return func(t *testing.T) {
+			profile := &kprof.KnitProfile{ApiRoot: "http://api.knit.invalid"}
+			client := try.To(krst.NewClient(profile)).OrFatal(t)
+
+			removeMock := func(
+				ctx context.Context,
+				client krst.KnitClient,
+				planId string,
+			) error {
+				if planId != then.planId {
+					t.Errorf("planId: got %s, but want %s", planId, then.planId)
+				}
+				return when.err
+			}
+
+			testee := plan_rm.Task(removeMock)
+
+			stdout := new(strings.Builder)
+			stderr := new(strings.Builder)
+
+			ctx := context.Background()
+			err := testee(
+				ctx,
+				logger.Null(),
+				*kenv.New(),
+				client,
+				commandline.MockCommandline[struct{}]{
+					Fullname_: "knit plan rm",
+					Stdout_:   stdout,
+					Stderr_:   stderr,
+					Flags_:    struct{}{},
+					Args_: map[string][]string{
+						plan_rm.ARG_PLAN_ID: when.planId,
+					},
+				},
+				[]any{},
+			)
+
+			if !errors.Is(err, then.err) {
+				t.Errorf(
+					"wrong status: (actual, expected) != (%d, %d)",
+					err, then.err,
+				)
+			}
+		}
+	}
+	t.Run("when it is passed existed planId, it should return exitsuccess", theory(
+		when{
+			planId: []string{"test-Id"},
+			err:    nil,
+		},
+		then{
+			err:    nil,
+			planId: "test-Id",
+		},
+	))
+	{
+		expectedError := errors.New("fake error")
+		t.Run("when error is caused in client, it returns the error", theory(
+			when{
+				planId: []string{"test-Id"},
+				err:    expectedError,
+			},
+			then{
+				err:    expectedError,
+				planId: "test-Id",
+			},
+		))
+	}
+}
+
+func TestRunDeletePlan(t *testing.T) {
+	t.Run("When client does not cause any error, it should return the content returned by client as is", func(t *testing.T) {
+		ctx := context.Background()
+		mock := mock.New(t)
+		mock.Impl.DeletePlan = func(ctx context.Context, planId string) error {
+			return nil
+		}
+
+		err := plan_rm.RunDeletePlan(ctx, mock, "test-planId")
+		if err != nil {
+			t.Fatalf("RunDeletePlan returns error unexpectedly: %s (%+v)", err.Error(), err)
+		}
+	})
+
+	t.Run("when client returns error, it should return the error as is", func(t *testing.T) {
+		ctx := context.Background()
+		mock := mock.New(t)
+		expectedError := errors.New("fake error")
+		mock.Impl.DeletePlan = func(ctx context.Context, planId string) error {
+			return expectedError
+		}
+
+		err := plan_rm.RunDeletePlan(ctx, mock, "test-planId")
+		if !errors.Is(err, expectedError) {
+			t.Errorf("returned error is not expected one: %+v", err)
+		}
+	})
+}
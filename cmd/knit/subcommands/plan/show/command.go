@@ -13,12 +13,21 @@ import (
 	"github.com/youta-t/flarc"
 )
 
+type Flags struct {
+	Chain bool `flag:"chain" help:"Show the version chain of the Plan, from the oldest to the newest."`
+}
+
 type Option struct {
 	show func(
 		ctx context.Context,
 		client krst.KnitClient,
 		planId string,
 	) (plans.Detail, error)
+	chain func(
+		ctx context.Context,
+		client krst.KnitClient,
+		planId string,
+	) ([]plans.Detail, error)
 }
 
 func WithShow(
@@ -34,13 +43,27 @@ func WithShow(
 	}
 }
 
+func WithChain(
+	chain func(
+		ctx context.Context,
+		client krst.KnitClient,
+		planId string,
+	) ([]plans.Detail, error),
+) func(*Option) *Option {
+	return func(cmd *Option) *Option {
+		cmd.chain = chain
+		return cmd
+	}
+}
+
 const (
 	ARG_PLAN_ID = "PLAN_ID"
 )
 
 func New(options ...func(*Option) *Option) (flarc.Command, error) {
 	option := &Option{
-		show: RunShowPlan,
+		show:  RunShowPlan,
+		chain: RunShowPlanChain,
 	}
 
 	for _, opt := range options {
@@ -49,14 +72,20 @@ func New(options ...func(*Option) *Option) (flarc.Command, error) {
 
 	return flarc.NewCommand(
 		"Return the Plan information for the specified Plan Id.",
-		struct{}{},
+		Flags{},
 		flarc.Args{
 			{
 				Name: ARG_PLAN_ID, Required: true,
 				Help: "Specify the Plan Id you finding",
 			},
 		},
-		common.NewTask(Task(option.show)),
+		common.NewTask(Task(option.show, option.chain)),
+		flarc.WithDescription(`
+Return the Plan information for the specified Plan Id.
+
+With --chain, return the versions of the Plan as an array,
+from the oldest predecessor to the newest successor.
+`),
 	)
 }
 
@@ -66,19 +95,35 @@ func Task(
 		client krst.KnitClient,
 		planId string,
 	) (plans.Detail, error),
-) common.Task[struct{}] {
+	chain func(
+		ctx context.Context,
+		client krst.KnitClient,
+		planId string,
+	) ([]plans.Detail, error),
+) common.Task[Flags] {
 	return func(
 		ctx context.Context,
 		logger *log.Logger,
 		knitEnv env.KnitEnv,
 		client krst.KnitClient,
-		cl flarc.Commandline[struct{}],
+		cl flarc.Commandline[Flags],
 		params []any,
 	) error {
 		planId := cl.Args()[ARG_PLAN_ID][0]
-		data, err := show(ctx, client, planId)
-		if err != nil {
-			return fmt.Errorf("%w: Plan Id:%v", err, planId)
+
+		var data any
+		if cl.Flags().Chain {
+			c, err := chain(ctx, client, planId)
+			if err != nil {
+				return fmt.Errorf("%w: Plan Id:%v", err, planId)
+			}
+			data = c
+		} else {
+			d, err := show(ctx, client, planId)
+			if err != nil {
+				return fmt.Errorf("%w: Plan Id:%v", err, planId)
+			}
+			data = d
 		}
 
 		enc := json.NewEncoder(cl.Stdout())
@@ -104,3 +149,51 @@ func RunShowPlan(
 
 	return result, nil
 }
+
+// RunShowPlanChain returns versions of the Plan, from the oldest to the newest.
+func RunShowPlanChain(
+	ctx context.Context,
+	client krst.KnitClient,
+	planId string,
+) ([]plans.Detail, error) {
+	plan, err := client.GetPlans(ctx, planId)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{plan.PlanId: {}}
+	predecessors := []plans.Detail{}
+	for p := plan.Supersedes; p != ""; {
+		if _, ok := seen[p]; ok {
+			break
+		}
+		seen[p] = struct{}{}
+		pred, err := client.GetPlans(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		predecessors = append(predecessors, pred)
+		p = pred.Supersedes
+	}
+
+	chain := make([]plans.Detail, 0, len(predecessors)+1)
+	for i := len(predecessors) - 1; 0 <= i; i-- {
+		chain = append(chain, predecessors[i])
+	}
+	chain = append(chain, plan)
+
+	for s := plan.SupersededBy; s != ""; {
+		if _, ok := seen[s]; ok {
+			break
+		}
+		seen[s] = struct{}{}
+		succ, err := client.GetPlans(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, succ)
+		s = succ.SupersededBy
+	}
+
+	return chain, nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/opst/knitfab/cmd/knit/subcommands/internal/commandline"
 	"github.com/opst/knitfab/cmd/knit/subcommands/logger"
 	plan_show "github.com/opst/knitfab/cmd/knit/subcommands/plan/show"
+	"github.com/opst/knitfab/pkg/utils/cmp"
 	"github.com/opst/knitfab/pkg/utils/try"
 )
 
@@ -125,16 +126,25 @@ func TestShowCommand(t *testing.T) {
 				return when.plan, when.err
 			}
 
-			testee := plan_show.Task(show)
+			chain := func(
+				ctx context.Context,
+				client krst.KnitClient,
+				planId string,
+			) ([]plans.Detail, error) {
+				t.Errorf("chain should not be called")
+				return nil, nil
+			}
+
+			testee := plan_show.Task(show, chain)
 
 			ctx := context.Background()
 			actual := testee(
 				ctx, logger.Null(), *env.New(), client,
-				commandline.MockCommandline[struct{}]{
+				commandline.MockCommandline[plan_show.Flags]{
 					Fullname_: "knit plan show",
 					Stdout_:   io.Discard,
 					Stderr_:   io.Discard,
-					Flags_:    struct{}{},
+					Flags_:    plan_show.Flags{},
 					Args_: map[string][]string{
 						plan_show.ARG_PLAN_ID: when.planId,
 					},
@@ -305,3 +315,58 @@ func TestRunShowplan(t *testing.T) {
 		}
 	})
 }
+
+func TestRunShowPlanChain(t *testing.T) {
+	plan := func(planId, supersedes, supersededBy string) plans.Detail {
+		return plans.Detail{
+			Summary: plans.Summary{
+				PlanId: planId,
+				Image:  &plans.Image{Repository: "image", Tag: planId},
+			},
+			Supersedes:   supersedes,
+			SupersededBy: supersededBy,
+		}
+	}
+
+	chain := map[string]plans.Detail{
+		"plan-1": plan("plan-1", "", "plan-2"),
+		"plan-2": plan("plan-2", "plan-1", "plan-3"),
+		"plan-3": plan("plan-3", "plan-2", ""),
+	}
+
+	for _, planId := range []string{"plan-1", "plan-2", "plan-3"} {
+		t.Run("when started from "+planId+", it returns whole the chain", func(t *testing.T) {
+			ctx := context.Background()
+			client := mock.New(t)
+			client.Impl.GetPlans = func(ctx context.Context, planId string) (plans.Detail, error) {
+				p, ok := chain[planId]
+				if !ok {
+					t.Fatalf("unexpected planId: %s", planId)
+				}
+				return p, nil
+			}
+
+			actual := try.To(plan_show.RunShowPlanChain(ctx, client, planId)).OrFatal(t)
+			expected := []plans.Detail{chain["plan-1"], chain["plan-2"], chain["plan-3"]}
+			if !cmp.SliceEqWith(actual, expected, plans.Detail.Equal) {
+				t.Errorf("unexpected chain:\n===actual===\n%+v\n===expected===\n%+v", actual, expected)
+			}
+		})
+	}
+
+	t.Run("when client causes error, it returns the error", func(t *testing.T) {
+		ctx := context.Background()
+		client := mock.New(t)
+		expectedError := errors.New("fake error")
+		client.Impl.GetPlans = func(ctx context.Context, planId string) (plans.Detail, error) {
+			if planId == "plan-2" {
+				return chain[planId], nil
+			}
+			return plans.Detail{}, expectedError
+		}
+
+		if _, err := plan_show.RunShowPlanChain(ctx, client, "plan-2"); !errors.Is(err, expectedError) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
@@ -552,6 +552,14 @@ inputs:
   List of filepath and Tags as Input of this Plans.
   1 or more Inputs are needed.
   Each filepath should be absolute. Tags should be formatted in "key:value"-style.
+  Optionally, each Input can have "select" to choose Data to be used:
+    - "all" (default): all Data with the Tags are used.
+    - "latest": only the latest Data is used. Older ones are replaced when new one comes.
+    - "latest:N": only the latest N Data are used.
+  An Input can be a parameter by "values", a list of literal values.
+  Each value is stored as a Data containing a file "value",
+  and drives a Run of this Plan. Tags of parameter Inputs are not used to find Data.
+  With 2 or more parameter Inputs, Runs are created for all combinations of them.
 `)),
 			y.Seq(
 				slices.Map(p.Inputs, mountpoint.yamlNode)...,
@@ -648,10 +656,113 @@ memory (optional; default = 1Gi):
 #   must:
 #     - "accelarator=gpu"
 #
+# # resource_requests (optional, mutable):
+# #   Specify resources which workers of this Plan request for scheduling, in the same format as "resources".
+# #   Each of them should not exceed the one in "resources" (limits).
+# #   Resources not listed here are requested as much as "resources".
+# #   Requesting less than limits lets workers burst when nodes have room (advanced note: container.resources.requests).
+# resource_requests:
+#   cpu: "0.5"
+#
+# # tolerations (optional):
+# #   Specify kubernetes tolerations of workers of this Plan, in addition to ones from on_node.
+# #   - key: key of the taint. If missing, operator should be "Exists" and it matches any key.
+# #   - operator: "Equal" (default) or "Exists".
+# #   - value: value of the taint. It should be missing for "Exists".
+# #   - effect: "NoSchedule", "PreferNoSchedule" or "NoExecute". If missing, it matches any effect.
+# tolerations:
+#   - key: "nvidia.com/gpu"
+#     operator: "Exists"
+#     effect: "NoSchedule"
+#
+# # priority_class_name (optional):
+# #   Specify the kubernetes PriorityClass of workers of this Plan.
+# #   If missing, the default of Knitfab is used. Ask your administrator for available ones.
+# priority_class_name: "high-priority"
+#
+# # runtime_class_name (optional):
+# #   Specify the kubernetes RuntimeClass of workers of this Plan, like "nvidia".
+# #   If missing, the default runtime is used. Ask your administrator for available ones.
+# runtime_class_name: "nvidia"
+#
+# # scratch (optional):
+# #   Specify ephemeral directories for workers of this Plan, like /tmp or cache directories.
+# #   They are empty when a worker starts, and discarded when it finishes. Files in them are not recorded as Data.
+# #   - path: absolute path of the directory. It should not overlap with inputs, outputs, /log and each other.
+# #   - size_limit: max size of the directory, like "10Gi". If missing, it is unlimited.
+# #   - medium: "Memory" to back the directory with memory (tmpfs). It counts toward the memory limit.
+# #     If missing, the disk of the node is used.
+# scratch:
+#   - path: "/tmp"
+#     size_limit: "10Gi"
+#
+# # shm_size (optional):
+# #   Specify the size of shared memory (/dev/shm) of workers of this Plan, like "2Gi".
+# #   It is needed by, for example, DataLoader of PyTorch with multiple workers. It counts toward the memory limit.
+# #   If missing, the default of the container runtime is used (which is usually small, 64Mi).
+# shm_size: "2Gi"
+#
 # # service_account (optional, mutable):
 # #   Specify the service account to run this Plan.
 # #   If missing or null, the service account is not used.
 # service_account: "default"
+#
+# # schedule (optional):
+# #   Specify a cron schedule (minute hour day-of-month month day-of-week, in UTC) to run this Plan periodically.
+# #   When the schedule comes, a new Run is created with the most recent Data for each input.
+# #   Descriptors like "@daily" and "@hourly" are also accepted.
+# #   If missing or empty, Runs are created only when new Data come.
+# schedule: "0 3 * * *"
+#
+# # priority (optional, mutable):
+# #   Specify the priority of Runs of this Plan. Default is 0, and it can be negative.
+# #   When there are Runs ready to start, Runs with higher priority are started earlier.
+# priority: 0
+#
+# # max_concurrency (optional, mutable):
+# #   Specify the maximum number of Runs of this Plan starting or running at once.
+# #   If missing or 0, it is unlimited.
+# max_concurrency: 2
+#
+# # max_duration (optional):
+# #   Specify the time limit of a Run of this Plan, like "30m" or "1h30m" (in whole seconds).
+# #   When the Run has been active for longer than this, it is terminated and failed by timeout.
+# #   If missing or empty, it is unlimited.
+# max_duration: "6h"
+#
+# # retry (optional):
+# #   Specify how failed Runs of this Plan are retried automatically.
+# #   - max_attempts: max number of attempts, including the first one.
+# #   - backoff: duration to wait before the first retry. It is doubled for each retry, up to 1 hour.
+# #   - on: causes to be retried; "failed" (the worker has failed) and/or "stucking" (the worker could not start).
+# #     If missing, both are retried.
+# #   - exit_codes: when the worker has failed, retry only if it exits with one of these codes.
+# #     If missing, any exit code is retried.
+# retry:
+#   max_attempts: 3
+#   backoff: "30s"
+#   on: ["failed", "stucking"]
+#   exit_codes: [137]
+#
+# # env (optional):
+# #   Specify environment variables set to workers of this Plan.
+# #   Each of them has a static "value", or "value_from" a key of a Secret or a ConfigMap
+# #   in the namespace where workers run. Use Secrets for credentials, like API tokens.
+# #   Static values are redacted in "knit plan show".
+# #   Plans differing only in env are regarded as the same.
+# env:
+#   - name: "MODE"
+#     value: "train"
+#   - name: "WANDB_API_KEY"
+#     value_from:
+#       secret_key_ref:
+#         name: "wandb"
+#         key: "api-key"
+#   - name: "ENDPOINT"
+#     value_from:
+#       config_map_key_ref:
+#         name: "api-config"
+#         key: "endpoint"
 `
 
 	return doc, nil
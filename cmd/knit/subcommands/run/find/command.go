@@ -2,7 +2,6 @@ package find
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 
@@ -22,6 +21,7 @@ type Flag struct {
 	Status    *kargs.Argslice         `flag:"status" alias:"s" metavar:"waiting|deactivated|starting|running|done|failed..." help:"Find Run in this status. Repeatable."`
 	Since     *kargs.LooseRFC3339     `flag:"since" metavar:"YYYY-mm-dd[THH[:MM[:SS]]][TZ]" help:"Find Run only updated at this time or later."`
 	Duration  *kargs.OptionalDuration `flag:"duration" metavar:"DURATION" help:"Find Run only updated in '--duration' from '--since'."`
+	Cause     *kargs.Argslice         `flag:"cause" alias:"c" metavar:"failed|stucking|timeout" help:"Find Run which has exited with this cause. Repeatable."`
 }
 
 type Option struct {
@@ -30,7 +30,8 @@ type Option struct {
 		log *log.Logger,
 		client krst.KnitClient,
 		parameter krst.FindRunParameter,
-	) ([]runs.Detail, error)
+		handler func([]runs.Detail) error,
+	) error
 }
 
 func WithFind(
@@ -39,7 +40,8 @@ func WithFind(
 		log *log.Logger,
 		client krst.KnitClient,
 		parameter krst.FindRunParameter,
-	) ([]runs.Detail, error),
+		handler func([]runs.Detail) error,
+	) error,
 ) func(*Option) *Option {
 	return func(dfc *Option) *Option {
 		dfc.find = find
@@ -66,6 +68,7 @@ func New(
 			Status:    &kargs.Argslice{},
 			Since:     &kargs.LooseRFC3339{},
 			Duration:  &kargs.OptionalDuration{},
+			Cause:     &kargs.Argslice{},
 		},
 		flarc.Args{},
 		common.NewTask(Task(option.find)),
@@ -87,6 +90,10 @@ For example, "2024-10-31T01:23:45.987Z", "2024-10-31 01:23" or "2024-10-31+09:00
 Supported units are "ms" (milliseconds), "s" (seconds), "m" (minutes) and "h" (hours).
 For example, "300ms", "1.5h" or "2h45m". Units are required. Negative duration is not supported.
 
+'--cause' limits a result to Runs which have exited with the cause:
+"failed" (the worker has failed), "stucking" (the worker could not start)
+or "timeout" (the worker has exceeded the max duration of the Plan).
+
 Example
 -------
 
@@ -105,6 +112,10 @@ Finding runs with Plan Id "plan1" AND Knit Id "knit1" in Input :
 
 	{{ .Command }} --planid plan1 --in-knitid knit1
 
+Finding runs failed by timeout:
+
+	{{ .Command }} --status failed --cause timeout
+
 Scan over Runs for day by day:
 
 	{{ .Command }} --duration 24h --since 2024-01-01
@@ -122,7 +133,8 @@ func Task(
 		log *log.Logger,
 		client krst.KnitClient,
 		parameter krst.FindRunParameter,
-	) ([]runs.Detail, error),
+		handler func([]runs.Detail) error,
+	) error,
 ) common.Task[Flag] {
 	return func(ctx context.Context, logger *log.Logger, knitEnv env.KnitEnv, client krst.KnitClient, cl flarc.Commandline[Flag], params []any) error {
 		flags := cl.Flags()
@@ -130,6 +142,7 @@ func Task(
 		knitIdIn := ptr.SafeDeref(flags.KnitIdIn)
 		knitIdOut := ptr.SafeDeref(flags.KnitIdOut)
 		status := ptr.SafeDeref(flags.Status)
+		cause := ptr.SafeDeref(flags.Cause)
 		since := flags.Since.Time()
 		duration := flags.Duration.Duration()
 
@@ -144,19 +157,14 @@ func Task(
 			Status:    status,
 			Since:     since,
 			Duration:  duration,
+			ExitCause: cause,
 		}
 
-		run, err := find(ctx, logger, client, parameter)
-		if err != nil {
+		enc := common.NewJsonArrayEncoder[runs.Detail](cl.Stdout())
+		if err := find(ctx, logger, client, parameter, enc.Encode); err != nil {
 			return err
 		}
-
-		enc := json.NewEncoder(cl.Stdout())
-		enc.SetIndent("", "    ")
-		if err := enc.Encode(run); err != nil {
-			logger.Panicf("fail to dump found Run")
-		}
-		return nil
+		return enc.Close()
 	}
 }
 
@@ -165,11 +173,7 @@ func RunFindRun(
 	logger *log.Logger,
 	client krst.KnitClient,
 	parameter krst.FindRunParameter,
-) ([]runs.Detail, error) {
-	result, err := client.FindRun(ctx, parameter)
-	if err != nil {
-		return nil, err
-	}
-
-	return result, nil
+	handler func([]runs.Detail) error,
+) error {
+	return client.FindRun(ctx, parameter, handler)
 }
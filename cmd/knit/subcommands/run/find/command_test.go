@@ -102,12 +102,14 @@ func TestFindCommand(t *testing.T) {
 				_ *log.Logger,
 				_ krst.KnitClient,
 				parameter krst.FindRunParameter,
-			) ([]runs.Detail, error) {
+				handler func([]runs.Detail) error,
+			) error {
 
 				checkSliceEq(t, "planId", parameter.PlanId, ptr.SafeDeref(when.flag.PlanId))
 				checkSliceEq(t, "knitIdIn", parameter.KnitIdIn, ptr.SafeDeref(when.flag.KnitIdIn))
 				checkSliceEq(t, "knitIdOut", parameter.KnitIdOut, ptr.SafeDeref(when.flag.KnitIdOut))
 				checkSliceEq(t, "status", parameter.Status, ptr.SafeDeref(when.flag.Status))
+				checkSliceEq(t, "cause", parameter.ExitCause, ptr.SafeDeref(when.flag.Cause))
 				if want := when.flag.Since.Time(); want == nil {
 					if parameter.Since != nil {
 						t.Errorf("wrong since: (actual, expected) != (%s, %s)", parameter.Since, when.flag.Since)
@@ -124,7 +126,10 @@ func TestFindCommand(t *testing.T) {
 					t.Errorf("wrong duration: (actual, expected) != (%s, %s)", *parameter.Duration, want)
 				}
 
-				return when.presentation, when.err
+				if when.err != nil {
+					return when.err
+				}
+				return handler(when.presentation)
 			}
 
 			testee := run_find.Task(task)
@@ -203,6 +208,9 @@ func TestFindCommand(t *testing.T) {
 					Status: &kargs.Argslice{
 						"waiting", "running",
 					},
+					Cause: &kargs.Argslice{
+						"timeout",
+					},
 					Since:    &since,
 					Duration: duration,
 				},
@@ -304,9 +312,15 @@ func TestRunFindRun(t *testing.T) {
 		log := logger.Null()
 		mock := mock.New(t)
 		mock.Impl.FindRun = func(
-			ctx context.Context, query krst.FindRunParameter,
-		) ([]runs.Detail, error) {
-			return expectedValue, nil
+			ctx context.Context, query krst.FindRunParameter, handler func([]runs.Detail) error,
+		) error {
+			// respond each Run as a page.
+			for _, r := range expectedValue {
+				if err := handler([]runs.Detail{r}); err != nil {
+					return err
+				}
+			}
+			return nil
 		}
 
 		// arguments set up
@@ -323,8 +337,16 @@ func TestRunFindRun(t *testing.T) {
 		}
 
 		// test start
-		actual := try.To(run_find.RunFindRun(
-			ctx, log, mock, parameter)).OrFatal(t)
+		actual := []runs.Detail{}
+		if err := run_find.RunFindRun(
+			ctx, log, mock, parameter,
+			func(page []runs.Detail) error {
+				actual = append(actual, page...)
+				return nil
+			},
+		); err != nil {
+			t.Fatal(err)
+		}
 
 		//check actual
 		if !cmp.SliceContentEqWith(actual, expectedValue, runs.Detail.Equal) {
@@ -338,14 +360,13 @@ func TestRunFindRun(t *testing.T) {
 	t.Run("when client returns error, it should return the error as is", func(t *testing.T) {
 		ctx := context.Background()
 		log := logger.Null()
-		var expectedValue []runs.Detail = nil
 		expectedError := errors.New("fake error")
 
 		mock := mock.New(t)
 		mock.Impl.FindRun = func(
-			ctx context.Context, query krst.FindRunParameter,
-		) ([]runs.Detail, error) {
-			return expectedValue, expectedError
+			ctx context.Context, query krst.FindRunParameter, handler func([]runs.Detail) error,
+		) error {
+			return expectedError
 		}
 
 		// argements set up
@@ -362,11 +383,17 @@ func TestRunFindRun(t *testing.T) {
 		}
 
 		// test start
-		actual, err := run_find.RunFindRun(
-			ctx, log, mock, parameter)
+		actual := []runs.Detail{}
+		err := run_find.RunFindRun(
+			ctx, log, mock, parameter,
+			func(page []runs.Detail) error {
+				actual = append(actual, page...)
+				return nil
+			},
+		)
 
 		//check actual and err
-		if actual != nil {
+		if len(actual) != 0 {
 			t.Errorf("unexpected value is returned: %+v", actual)
 		}
 		if !errors.Is(err, expectedError) {
@@ -78,6 +78,10 @@ func New(
 		flarc.WithDescription(`
 Return the Run information for the specified Run Id.
 
+When the Run has exited, "exit" shows its exit code, message and cause.
+The cause is one of "failed", "stucking" or "timeout" for Runs exited by their workers.
+The exit code of Runs timed out (exceeded "max_duration" of their Plans) is 252.
+
 when --log is passed, it display the log of that Run on the console.
 `),
 	)
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	apiaudit "github.com/opst/knitfab-api-types/audit"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	bindaudit "github.com/opst/knitfab/pkg/api-types-binding/audit"
+	binderr "github.com/opst/knitfab/pkg/api-types-binding/errors"
+	"github.com/opst/knitfab/pkg/domain"
+	kdbaudit "github.com/opst/knitfab/pkg/domain/audit/db"
+	"github.com/opst/knitfab/pkg/utils/slices"
+	kstrings "github.com/opst/knitfab/pkg/utils/strings"
+)
+
+// FindAuditHandler returns a handler to find audit log entries.
+//
+// Query parameters (all are optional):
+//
+// - actor: comma separated actors
+//
+// - action: comma separated actions
+//
+// - type: comma separated target types. "data", "plan" or "run".
+//
+// - target: comma separated target ids
+//
+// - since: RFC3339 date-time. entries at or after this are found.
+//
+// - duration: Go duration. entries before since + duration are found. requires since.
+func FindAuditHandler(dbaudit kdbaudit.AuditInterface) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		query := domain.AuditFindQuery{
+			Actor: kstrings.SplitIfNotEmpty(c.QueryParam("actor"), ","),
+			Action: slices.Map(
+				kstrings.SplitIfNotEmpty(c.QueryParam("action"), ","),
+				func(s string) domain.AuditAction { return domain.AuditAction(s) },
+			),
+			TargetId: kstrings.SplitIfNotEmpty(c.QueryParam("target"), ","),
+		}
+
+		for _, t := range kstrings.SplitIfNotEmpty(c.QueryParam("type"), ",") {
+			switch typ := domain.AuditTargetType(t); typ {
+			case domain.AuditTargetData, domain.AuditTargetPlan, domain.AuditTargetRun:
+				query.TargetType = append(query.TargetType, typ)
+			default:
+				return binderr.BadRequest(`"type" should be one of "data", "plan" or "run"`, nil)
+			}
+		}
+
+		if since := c.QueryParam("since"); since != "" {
+			t, err := rfctime.ParseRFC3339DateTime(since)
+			if err != nil {
+				return binderr.BadRequest(`"since" should be a RFC3339 date-time format`, err)
+			}
+			_t := t.Time()
+			query.Since = &_t
+		}
+
+		if duration := c.QueryParam("duration"); duration != "" {
+			if query.Since == nil {
+				return binderr.BadRequest(`"duration" requires "since"`, nil)
+			}
+			d, err := time.ParseDuration(duration)
+			if err != nil {
+				return binderr.BadRequest(`"duration" should be a Go duration format`, err)
+			}
+			_t := query.Since.Add(d)
+			query.Until = &_t
+		}
+
+		entries, err := dbaudit.Find(c.Request().Context(), query)
+		if err != nil {
+			return binderr.InternalServerError(err)
+		}
+
+		resp := make([]apiaudit.Entry, 0, len(entries))
+		for _, e := range entries {
+			resp = append(resp, bindaudit.Compose(e))
+		}
+		return c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,135 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	apiaudit "github.com/opst/knitfab-api-types/audit"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	handlers "github.com/opst/knitfab/cmd/knitd/handlers"
+	httptestutil "github.com/opst/knitfab/internal/testutils/http"
+	"github.com/opst/knitfab/pkg/domain"
+	mockdb "github.com/opst/knitfab/pkg/domain/audit/db/mock"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestFindAuditHandler(t *testing.T) {
+	since := try.To(rfctime.ParseRFC3339DateTime("2024-01-02T03:04:05+00:00")).OrFatal(t).Time()
+	until := since.Add(2 * time.Hour)
+
+	t.Run("it passes query to database, and responses entries", func(t *testing.T) {
+		entries := []domain.AuditEntry{
+			{
+				Id:         1,
+				Timestamp:  since.Add(time.Minute),
+				Actor:      "user@example.com",
+				Action:     domain.AuditPlanActivate,
+				TargetType: domain.AuditTargetPlan,
+				TargetId:   "plan-1",
+				Before:     json.RawMessage(`{"active": true}`),
+				After:      json.RawMessage(`{"active": false}`),
+			},
+			{
+				Id:         2,
+				Timestamp:  since.Add(2 * time.Minute),
+				Actor:      "user@example.com",
+				Action:     domain.AuditRunDelete,
+				TargetType: domain.AuditTargetRun,
+				TargetId:   "run-1",
+				Before:     json.RawMessage(`{"status": "done"}`),
+			},
+		}
+		dbaudit := mockdb.NewAuditInterface()
+		dbaudit.Impl.Find = func(ctx context.Context, q domain.AuditFindQuery) ([]domain.AuditEntry, error) {
+			return entries, nil
+		}
+
+		e := echo.New()
+		c, resp := httptestutil.Get(
+			e,
+			"/api/audit?actor=user@example.com&action=plan.activate,run.delete&type=plan,run&target=plan-1,run-1"+
+				"&since=2024-01-02T03:04:05%2B00:00&duration=2h",
+		)
+		if err := handlers.FindAuditHandler(dbaudit)(c); err != nil {
+			t.Fatal(err)
+		}
+
+		if dbaudit.Calls.Find.Times() != 1 {
+			t.Fatalf("Find is called %d times", dbaudit.Calls.Find.Times())
+		}
+		q := dbaudit.Calls.Find[0]
+		if !cmp.SliceEq(q.Actor, []string{"user@example.com"}) {
+			t.Errorf("actor: %v", q.Actor)
+		}
+		if !cmp.SliceEq(q.Action, []domain.AuditAction{domain.AuditPlanActivate, domain.AuditRunDelete}) {
+			t.Errorf("action: %v", q.Action)
+		}
+		if !cmp.SliceEq(q.TargetType, []domain.AuditTargetType{domain.AuditTargetPlan, domain.AuditTargetRun}) {
+			t.Errorf("target type: %v", q.TargetType)
+		}
+		if !cmp.SliceEq(q.TargetId, []string{"plan-1", "run-1"}) {
+			t.Errorf("target id: %v", q.TargetId)
+		}
+		if q.Since == nil || !q.Since.Equal(since) {
+			t.Errorf("since: %v", q.Since)
+		}
+		if q.Until == nil || !q.Until.Equal(until) {
+			t.Errorf("until: %v", q.Until)
+		}
+
+		if resp.Code != http.StatusOK {
+			t.Errorf("status code: %d", resp.Code)
+		}
+		got := []apiaudit.Entry{}
+		if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		expected := []apiaudit.Entry{
+			{
+				Id:        1,
+				Timestamp: rfctime.RFC3339(since.Add(time.Minute)),
+				Actor:     "user@example.com",
+				Action:    "plan.activate",
+				Target:    apiaudit.Target{Type: "plan", Id: "plan-1"},
+				Before:    json.RawMessage(`{"active":true}`),
+				After:     json.RawMessage(`{"active":false}`),
+			},
+			{
+				Id:        2,
+				Timestamp: rfctime.RFC3339(since.Add(2 * time.Minute)),
+				Actor:     "user@example.com",
+				Action:    "run.delete",
+				Target:    apiaudit.Target{Type: "run", Id: "run-1"},
+				Before:    json.RawMessage(`{"status":"done"}`),
+			},
+		}
+		if !cmp.SliceEqWith(got, expected, apiaudit.Entry.Equal) {
+			t.Errorf("response:\n===actual===\n%+v\n===expected===\n%+v", got, expected)
+		}
+	})
+
+	for name, request := range map[string]string{
+		"unknown target type":    "/api/audit?type=user",
+		"broken since":           "/api/audit?since=yesterday",
+		"duration without since": "/api/audit?duration=1h",
+		"broken duration":        "/api/audit?since=2024-01-02T03:04:05%2B00:00&duration=long",
+	} {
+		t.Run("it responses BadRequest for "+name, func(t *testing.T) {
+			dbaudit := mockdb.NewAuditInterface()
+			e := echo.New()
+			c, _ := httptestutil.Get(e, request)
+			err := handlers.FindAuditHandler(dbaudit)(c)
+			if code := statusOf(t, err); code != http.StatusBadRequest {
+				t.Errorf("unexpected status code: %d", code)
+			}
+			if dbaudit.Calls.Find.Times() != 0 {
+				t.Errorf("Find is called")
+			}
+		})
+	}
+}
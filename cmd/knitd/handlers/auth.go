@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	apiauth "github.com/opst/knitfab-api-types/auth"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	binderr "github.com/opst/knitfab/pkg/api-types-binding/errors"
+	"github.com/opst/knitfab/pkg/domain"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	kdbuser "github.com/opst/knitfab/pkg/domain/user/db"
+)
+
+const (
+	contextKeyUser = "knitfab/user"
+	tokenIssuer    = "knitfab"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// UserOf returns the user who sends the request.
+//
+// It is available after Authenticate or Unauthenticated middleware.
+func UserOf(c echo.Context) (domain.User, bool) {
+	u, ok := c.Get(contextKeyUser).(domain.User)
+	return u, ok
+}
+
+// Authenticate returns a middleware which verifies "Authorization: Bearer ..." header.
+//
+// Requests without valid token are rejected with 401 Unauthorized.
+func Authenticate(dbuser kdbuser.UserInterface) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get("Authorization")
+			scheme, token, ok := strings.Cut(header, " ")
+			if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+				return binderr.NewErrorMessage(
+					http.StatusUnauthorized, "authorization required",
+					binderr.WithAdvice("login with `knit login`, or pass bearer token."),
+				)
+			}
+
+			user, err := verifyToken(c, dbuser, strings.TrimSpace(token))
+			if errors.Is(err, ErrInvalidToken) {
+				return binderr.NewErrorMessage(
+					http.StatusUnauthorized, "token is invalid or expired",
+					binderr.WithAdvice("login again with `knit login`."),
+					binderr.WithError(err),
+				)
+			} else if err != nil {
+				return binderr.InternalServerError(err)
+			}
+
+			setUser(c, user, user.Email)
+			return next(c)
+		}
+	}
+}
+
+// Unauthenticated returns a middleware which treats every request as admin's.
+//
+// Use this when authentication is disabled.
+func Unauthenticated() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			setUser(c, domain.User{Name: "anonymous", Role: domain.RoleAdmin}, "anonymous")
+			return next(c)
+		}
+	}
+}
+
+// setUser binds the user to the request.
+//
+// The actor is also put into the request context, to be recorded in audit log.
+func setUser(c echo.Context, user domain.User, actor string) {
+	c.Set(contextKeyUser, user)
+	req := c.Request()
+	c.SetRequest(req.WithContext(domain.WithActor(req.Context(), actor)))
+}
+
+// RequireRole returns a middleware which rejects requests from users without the role.
+//
+// This should be placed after Authenticate or Unauthenticated middleware.
+func RequireRole(role domain.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := UserOf(c)
+			if !ok {
+				return binderr.NewErrorMessage(http.StatusUnauthorized, "authorization required")
+			}
+			if !user.Role.Includes(role) {
+				return binderr.NewErrorMessage(
+					http.StatusForbidden,
+					fmt.Sprintf("permission denied: %s role is required", role),
+					binderr.WithAdvice("ask your admin to grant the role."),
+				)
+			}
+			return next(c)
+		}
+	}
+}
+
+func verifyToken(c echo.Context, dbuser kdbuser.UserInterface, token string) (domain.User, error) {
+	ctx := c.Request().Context()
+
+	var user domain.User
+	var cred domain.PasswordCredential
+	claims := jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(
+		token, &claims,
+		func(t *jwt.Token) (any, error) {
+			sub, err := t.Claims.GetSubject()
+			if err != nil {
+				return nil, err
+			}
+			u, c, err := dbuser.Get(ctx, sub)
+			if err != nil {
+				return nil, err
+			}
+			if c.Revoked {
+				return nil, fmt.Errorf("%w: credential is revoked", ErrInvalidToken)
+			}
+			user, cred = u, c
+			return c.SigningKey()
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, kerr.ErrMissing) || errors.Is(err, ErrInvalidToken) {
+			return domain.User{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+		}
+		if errors.Is(err, jwt.ErrTokenUnverifiable) {
+			// failed on looking up the key
+			return domain.User{}, err
+		}
+		return domain.User{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	// tokens issued before password update are not valid.
+	if claims.IssuedAt.Time.Before(cred.UpdatedAt.Truncate(time.Second)) {
+		return domain.User{}, fmt.Errorf("%w: credential is updated after issue", ErrInvalidToken)
+	}
+
+	return user, nil
+}
+
+func issueToken(user domain.User, cred domain.PasswordCredential, ttl time.Duration) (apiauth.Token, error) {
+	key, err := cred.SigningKey()
+	if err != nil {
+		return apiauth.Token{}, err
+	}
+
+	now := time.Now().Truncate(time.Second)
+	exp := now.Add(ttl)
+	tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    tokenIssuer,
+		Subject:   user.Id,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(exp),
+	}).SignedString(key)
+	if err != nil {
+		return apiauth.Token{}, err
+	}
+
+	return apiauth.Token{
+		Token:     tok,
+		IssuedAt:  rfctime.RFC3339(now),
+		ExpiresAt: rfctime.RFC3339(exp),
+	}, nil
+}
+
+// IssueTokenHandler returns a handler to issue a new token for the user with email & password.
+func IssueTokenHandler(dbuser kdbuser.UserInterface, ttl time.Duration) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		ctx := req.Context()
+		if strings.ToLower(req.Header.Get("content-type")) != "application/json" {
+			return binderr.BadRequest(
+				"unexpected content type. it shoule be application/json", nil,
+			)
+		}
+
+		cr := new(apiauth.Credential)
+		if err := json.NewDecoder(req.Body).Decode(cr); err != nil {
+			return binderr.BadRequest("can not understand the requested json", err)
+		}
+
+		user, cred, err := dbuser.Lookup(ctx, cr.Email)
+		if errors.Is(err, kerr.ErrMissing) {
+			return binderr.Unauthorized("email or password is wrong", nil)
+		} else if err != nil {
+			return binderr.InternalServerError(err)
+		}
+		if !cred.Verify(cr.Password) {
+			return binderr.Unauthorized("email or password is wrong", nil)
+		}
+
+		tok, err := issueToken(user, cred, ttl)
+		if err != nil {
+			return binderr.InternalServerError(err)
+		}
+		return c.JSON(http.StatusOK, tok)
+	}
+}
+
+// RefreshTokenHandler returns a handler to issue a new token for the authenticated user.
+//
+// This should be placed after Authenticate middleware.
+func RefreshTokenHandler(dbuser kdbuser.UserInterface, ttl time.Duration) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		authn, ok := UserOf(c)
+		if !ok {
+			return binderr.NewErrorMessage(http.StatusUnauthorized, "authorization required")
+		}
+
+		user, cred, err := dbuser.Get(ctx, authn.Id)
+		if errors.Is(err, kerr.ErrMissing) {
+			return binderr.Unauthorized("user is not found", err)
+		} else if err != nil {
+			return binderr.InternalServerError(err)
+		}
+
+		tok, err := issueToken(user, cred, ttl)
+		if err != nil {
+			return binderr.InternalServerError(err)
+		}
+		return c.JSON(http.StatusOK, tok)
+	}
+}
+
+// RegisterUserHandler returns a handler to register a new user.
+func RegisterUserHandler(dbuser kdbuser.UserInterface) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		ctx := req.Context()
+		if strings.ToLower(req.Header.Get("content-type")) != "application/json" {
+			return binderr.BadRequest(
+				"unexpected content type. it shoule be application/json", nil,
+			)
+		}
+
+		spec := new(apiauth.UserSpec)
+		if err := json.NewDecoder(req.Body).Decode(spec); err != nil {
+			return binderr.BadRequest("can not understand the requested json", err)
+		}
+		if spec.Email == "" || spec.Password == "" {
+			return binderr.BadRequest("email and password are required", nil)
+		}
+		role, err := domain.AsRole(string(spec.Role))
+		if err != nil {
+			return binderr.BadRequest(
+				"unknown role. it should be one of viewer, plan-author or admin", err,
+			)
+		}
+
+		cred, err := domain.NewPasswordCredential(spec.Password)
+		if err != nil {
+			return binderr.InternalServerError(err)
+		}
+
+		user, err := dbuser.Register(
+			ctx, domain.User{Email: spec.Email, Name: spec.Name, Role: role}, cred,
+		)
+		if errors.Is(err, domain.ErrUserExists) {
+			return binderr.Conflict("user with the email exists already", binderr.WithError(err))
+		} else if err != nil {
+			return binderr.InternalServerError(err)
+		}
+
+		return c.JSON(http.StatusOK, apiauth.User{
+			UserId: user.Id,
+			Email:  user.Email,
+			Name:   user.Name,
+			Role:   apiauth.Role(user.Role),
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	apiauth "github.com/opst/knitfab-api-types/auth"
+	handlers "github.com/opst/knitfab/cmd/knitd/handlers"
+	httptestutil "github.com/opst/knitfab/internal/testutils/http"
+	"github.com/opst/knitfab/pkg/domain"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	mockdb "github.com/opst/knitfab/pkg/domain/user/db/mock"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func statusOf(t *testing.T, err error) int {
+	t.Helper()
+	httperr := new(echo.HTTPError)
+	if !errors.As(err, &httperr) {
+		t.Fatalf("error type: %+v is not echo.HTTPError", err)
+	}
+	return httperr.Code
+}
+
+func TestAuthentication(t *testing.T) {
+	cred := try.To(domain.NewPasswordCredential("p@ssw0rd")).OrFatal(t)
+	cred.UpdatedAt = time.Now().Add(-time.Hour)
+	user := domain.User{
+		Id: "user-1", Email: "user@example.com", Name: "user", Role: domain.RolePlanAuthor,
+	}
+
+	newMock := func(cred domain.PasswordCredential) *mockdb.UserInterface {
+		dbuser := mockdb.NewUserInterface()
+		dbuser.Impl.Lookup = func(ctx context.Context, email string) (domain.User, domain.PasswordCredential, error) {
+			if email != user.Email {
+				return domain.User{}, domain.PasswordCredential{}, kerr.ErrMissing
+			}
+			return user, cred, nil
+		}
+		dbuser.Impl.Get = func(ctx context.Context, userId string) (domain.User, domain.PasswordCredential, error) {
+			if userId != user.Id {
+				return domain.User{}, domain.PasswordCredential{}, kerr.ErrMissing
+			}
+			return user, cred, nil
+		}
+		return dbuser
+	}
+
+	issue := func(t *testing.T, dbuser *mockdb.UserInterface, email, password string) (apiauth.Token, error) {
+		t.Helper()
+		body, _ := json.Marshal(apiauth.Credential{Email: email, Password: password})
+		e := echo.New()
+		c, resp := httptestutil.Post(
+			e, "/api/auth/token", strings.NewReader(string(body)),
+			httptestutil.ContentType("application/json"),
+		)
+		if err := handlers.IssueTokenHandler(dbuser, time.Hour)(c); err != nil {
+			return apiauth.Token{}, err
+		}
+		tok := apiauth.Token{}
+		if err := json.Unmarshal(resp.Body.Bytes(), &tok); err != nil {
+			t.Fatal(err)
+		}
+		return tok, nil
+	}
+
+	authenticated := func(dbuser *mockdb.UserInterface, role domain.Role, header string) (domain.User, error) {
+		e := echo.New()
+		opts := []httptestutil.RequestOption{}
+		if header != "" {
+			opts = append(opts, httptestutil.WithHeader("Authorization", header))
+		}
+		c, _ := httptestutil.Get(e, "/api/plans", opts...)
+
+		var got domain.User
+		h := handlers.Authenticate(dbuser)(handlers.RequireRole(role)(func(c echo.Context) error {
+			got, _ = handlers.UserOf(c)
+			return c.NoContent(http.StatusOK)
+		}))
+		return got, h(c)
+	}
+
+	t.Run("it issues token for valid password, and the token is accepted", func(t *testing.T) {
+		dbuser := newMock(cred)
+		tok, err := issue(t, dbuser, user.Email, "p@ssw0rd")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tok.ExpiresAt.Time().Equal(tok.IssuedAt.Time().Add(time.Hour)) {
+			t.Errorf("unexpected expiration: %s (issued at %s)", tok.ExpiresAt, tok.IssuedAt)
+		}
+
+		got, err := authenticated(dbuser, domain.RoleViewer, "Bearer "+tok.Token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(user) {
+			t.Errorf("unexpected user: %+v", got)
+		}
+	})
+
+	t.Run("it puts the user's email into request context as actor", func(t *testing.T) {
+		dbuser := newMock(cred)
+		tok, err := issue(t, dbuser, user.Email, "p@ssw0rd")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e := echo.New()
+		c, _ := httptestutil.Get(
+			e, "/api/plans", httptestutil.WithHeader("Authorization", "Bearer "+tok.Token),
+		)
+		var actor string
+		if err := handlers.Authenticate(dbuser)(func(c echo.Context) error {
+			actor = domain.ActorOf(c.Request().Context())
+			return nil
+		})(c); err != nil {
+			t.Fatal(err)
+		}
+		if actor != user.Email {
+			t.Errorf("unexpected actor: %s", actor)
+		}
+	})
+
+	t.Run("it rejects wrong password", func(t *testing.T) {
+		dbuser := newMock(cred)
+		_, err := issue(t, dbuser, user.Email, "wrong")
+		if code := statusOf(t, err); code != http.StatusUnauthorized {
+			t.Errorf("unexpected status code: %d", code)
+		}
+	})
+
+	t.Run("it rejects unknown user", func(t *testing.T) {
+		dbuser := newMock(cred)
+		_, err := issue(t, dbuser, "unknown@example.com", "p@ssw0rd")
+		if code := statusOf(t, err); code != http.StatusUnauthorized {
+			t.Errorf("unexpected status code: %d", code)
+		}
+	})
+
+	t.Run("it rejects requests without token", func(t *testing.T) {
+		dbuser := newMock(cred)
+		_, err := authenticated(dbuser, domain.RoleViewer, "")
+		if code := statusOf(t, err); code != http.StatusUnauthorized {
+			t.Errorf("unexpected status code: %d", code)
+		}
+	})
+
+	t.Run("it rejects broken token", func(t *testing.T) {
+		dbuser := newMock(cred)
+		_, err := authenticated(dbuser, domain.RoleViewer, "Bearer not-a-token")
+		if code := statusOf(t, err); code != http.StatusUnauthorized {
+			t.Errorf("unexpected status code: %d", code)
+		}
+	})
+
+	t.Run("it rejects token after credential is updated", func(t *testing.T) {
+		dbuser := newMock(cred)
+		tok, err := issue(t, dbuser, user.Email, "p@ssw0rd")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		updated := cred
+		updated.UpdatedAt = time.Now().Add(time.Minute)
+		_, err = authenticated(newMock(updated), domain.RoleViewer, "Bearer "+tok.Token)
+		if code := statusOf(t, err); code != http.StatusUnauthorized {
+			t.Errorf("unexpected status code: %d", code)
+		}
+	})
+
+	t.Run("it rejects token after credential is revoked", func(t *testing.T) {
+		dbuser := newMock(cred)
+		tok, err := issue(t, dbuser, user.Email, "p@ssw0rd")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		revoked := cred
+		revoked.Revoked = true
+		_, err = authenticated(newMock(revoked), domain.RoleViewer, "Bearer "+tok.Token)
+		if code := statusOf(t, err); code != http.StatusUnauthorized {
+			t.Errorf("unexpected status code: %d", code)
+		}
+	})
+
+	t.Run("it forbids users without required role", func(t *testing.T) {
+		dbuser := newMock(cred)
+		tok, err := issue(t, dbuser, user.Email, "p@ssw0rd")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = authenticated(dbuser, domain.RoleAdmin, "Bearer "+tok.Token)
+		if code := statusOf(t, err); code != http.StatusForbidden {
+			t.Errorf("unexpected status code: %d", code)
+		}
+	})
+}
+
+func TestRequireRole(t *testing.T) {
+	for name, testcase := range map[string]struct {
+		role     domain.Role
+		required domain.Role
+		ok       bool
+	}{
+		"viewer can view":              {role: domain.RoleViewer, required: domain.RoleViewer, ok: true},
+		"viewer cannot author plans":   {role: domain.RoleViewer, required: domain.RolePlanAuthor, ok: false},
+		"plan-author can view":         {role: domain.RolePlanAuthor, required: domain.RoleViewer, ok: true},
+		"plan-author can author plans": {role: domain.RolePlanAuthor, required: domain.RolePlanAuthor, ok: true},
+		"plan-author cannot admin":     {role: domain.RolePlanAuthor, required: domain.RoleAdmin, ok: false},
+		"admin can admin":              {role: domain.RoleAdmin, required: domain.RoleAdmin, ok: true},
+		"unknown role cannot view":     {role: domain.Role("guest"), required: domain.RoleViewer, ok: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			c, _ := httptestutil.Get(e, "/api/runs")
+			c.Set("knitfab/user", domain.User{Id: "user-1", Role: testcase.role})
+
+			err := handlers.RequireRole(testcase.required)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})(c)
+
+			if testcase.ok {
+				if err != nil {
+					t.Errorf("unexpected error: %+v", err)
+				}
+				return
+			}
+			if code := statusOf(t, err); code != http.StatusForbidden {
+				t.Errorf("unexpected status code: %d", code)
+			}
+		})
+	}
+
+	t.Run("Unauthenticated treats requests as admin's", func(t *testing.T) {
+		e := echo.New()
+		c, _ := httptestutil.Get(e, "/api/runs")
+
+		err := handlers.Unauthenticated()(
+			handlers.RequireRole(domain.RoleAdmin)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}),
+		)(c)
+		if err != nil {
+			t.Errorf("unexpected error: %+v", err)
+		}
+	})
+}
+
+func TestRegisterUserHandler(t *testing.T) {
+	t.Run("it registers a new user with role", func(t *testing.T) {
+		dbuser := mockdb.NewUserInterface()
+		dbuser.Impl.Register = func(ctx context.Context, u domain.User, c domain.PasswordCredential) (domain.User, error) {
+			u.Id = "user-2"
+			return u, nil
+		}
+
+		body := `{"email": "new@example.com", "name": "new user", "password": "secret", "role": "plan-author"}`
+		e := echo.New()
+		c, resp := httptestutil.Post(
+			e, "/api/users", strings.NewReader(body),
+			httptestutil.ContentType("application/json"),
+		)
+		if err := handlers.RegisterUserHandler(dbuser)(c); err != nil {
+			t.Fatal(err)
+		}
+
+		if dbuser.Calls.Register.Times() != 1 {
+			t.Fatalf("Register is called %d times", dbuser.Calls.Register.Times())
+		}
+		args := dbuser.Calls.Register[0]
+		if !args.Credential.Verify("secret") {
+			t.Errorf("credential does not match with the password")
+		}
+
+		got := apiauth.User{}
+		if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		expected := apiauth.User{
+			UserId: "user-2", Email: "new@example.com", Name: "new user", Role: apiauth.RolePlanAuthor,
+		}
+		if !got.Equal(expected) {
+			t.Errorf("response:\n===actual===\n%+v\n===expected===\n%+v", got, expected)
+		}
+	})
+
+	t.Run("it rejects unknown role", func(t *testing.T) {
+		dbuser := mockdb.NewUserInterface()
+		body := `{"email": "new@example.com", "name": "new user", "password": "secret", "role": "superuser"}`
+		e := echo.New()
+		c, _ := httptestutil.Post(
+			e, "/api/users", strings.NewReader(body),
+			httptestutil.ContentType("application/json"),
+		)
+		err := handlers.RegisterUserHandler(dbuser)(c)
+		if code := statusOf(t, err); code != http.StatusBadRequest {
+			t.Errorf("unexpected status code: %d", code)
+		}
+	})
+
+	t.Run("it responses Conflict when the email is used", func(t *testing.T) {
+		dbuser := mockdb.NewUserInterface()
+		dbuser.Impl.Register = func(ctx context.Context, u domain.User, c domain.PasswordCredential) (domain.User, error) {
+			return domain.User{}, domain.ErrUserExists
+		}
+		body := `{"email": "new@example.com", "name": "new user", "password": "secret", "role": "viewer"}`
+		e := echo.New()
+		c, _ := httptestutil.Post(
+			e, "/api/users", strings.NewReader(body),
+			httptestutil.ContentType("application/json"),
+		)
+		err := handlers.RegisterUserHandler(dbuser)(c)
+		if code := statusOf(t, err); code != http.StatusConflict {
+			t.Errorf("unexpected status code: %d", code)
+		}
+	})
+}
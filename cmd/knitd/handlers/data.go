@@ -12,13 +12,23 @@ import (
 	"github.com/opst/knitfab-api-types/data"
 	"github.com/opst/knitfab-api-types/misc/rfctime"
 	apitags "github.com/opst/knitfab-api-types/tags"
+	apiwebhooks "github.com/opst/knitfab-api-types/webhooks"
 	binddata "github.com/opst/knitfab/pkg/api-types-binding/data"
 	binderr "github.com/opst/knitfab/pkg/api-types-binding/errors"
+	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
+	bindtags "github.com/opst/knitfab/pkg/api-types-binding/tags"
 	"github.com/opst/knitfab/pkg/domain"
 	kdbdata "github.com/opst/knitfab/pkg/domain/data/db"
 	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	kdbrun "github.com/opst/knitfab/pkg/domain/run/db"
+	"github.com/opst/knitfab/pkg/hook"
+	"github.com/opst/knitfab/pkg/utils/slices"
 )
 
+// GetDataForDataHandler returns a handler to find Data.
+//
+// Found Data are paginated with query parameters "limit", "cursor" and "order" (see queryParamToPage).
+// When there are more Data, the "Link" header points the next page.
 func GetDataForDataHandler(dbData kdbdata.DataInterface) echo.HandlerFunc {
 	return func(c echo.Context) error {
 
@@ -61,10 +71,16 @@ func GetDataForDataHandler(dbData kdbdata.DataInterface) echo.HandlerFunc {
 			until = &_t
 		}
 
-		knitIds, err := dbData.Find(ctx, tags, since, until)
+		page, err := queryParamToPage(c)
 		if err != nil {
-			return binderr.InternalServerError(err)
+			return err
+		}
+
+		knitIds, next, err := dbData.Find(ctx, tags, since, until, page)
+		if err != nil {
+			return pageError(err)
 		}
+		setNextLink(c, next)
 		if len(knitIds) == 0 {
 			return c.JSON(http.StatusOK, []data.Detail{})
 		}
@@ -114,46 +130,46 @@ func queryParamToTags(queryParam []string) ([]domain.Tag, error) {
 
 var errIncorrectQueryTag = errors.New("incorrect query tag")
 
-func PutTagForDataHandler(dbData kdbdata.DataInterface, paramKey string) echo.HandlerFunc {
+// PutTagForDataHandler returns a handler to change Tags of the Data.
+//
+// dataHook is called before and after the change as a "tagged" event.
+func PutTagForDataHandler(
+	dbData kdbdata.DataInterface,
+	paramKey string,
+	dataHook hook.Hook[apiwebhooks.DataEvent, struct{}],
+) echo.HandlerFunc {
 
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 		knitId := c.Param(paramKey)
 
-		// read request body
-		change := apitags.Change{}
-		decoder := json.NewDecoder(c.Request().Body)
-		decoder.DisallowUnknownFields()
-
-		if err := decoder.Decode(&change); err != nil {
-			return binderr.NewErrorMessage(
-				http.StatusBadRequest,
-				"format error",
-				binderr.WithAdvice(err.Error()),
-				binderr.WithError(err),
-			)
+		// malformed tags are ignored, for compatibility.
+		delta, err := readTagChange(c, false)
+		if err != nil {
+			return err
 		}
+		change := bindtags.ComposeChange(delta)
 
-		delta := domain.TagDelta{
-			RemoveKey: change.RemoveKey,
-		}
-		for _, tag := range change.AddTags {
-			if t, err := domain.NewTag(tag.Key, tag.Value); err != nil {
-				binderr.BadRequest(fmt.Sprintf("bad tag: %s", tag), err)
-			} else {
-				delta.Add = append(delta.Add, t)
+		{
+			resultSet, err := dbData.Get(ctx, []string{knitId})
+			if err != nil {
+				return binderr.InternalServerError(err)
 			}
-		}
-		for _, tag := range change.RemoveTags {
-			if t, err := domain.NewTag(tag.Key, tag.Value); err != nil {
-				binderr.BadRequest(fmt.Sprintf("bad tag: %s", tag), err)
-			} else {
-				delta.Remove = append(delta.Remove, t)
+			d, ok := resultSet[knitId]
+			if !ok {
+				return binderr.NewErrorMessage(http.StatusNotFound, "corresponding data is missing")
+			}
+			if err := hookBefore(ctx, dataHook, apiwebhooks.DataEvent{
+				Event: apiwebhooks.DataTagged,
+				Data:  binddata.ComposeDetail(d),
+				Tags:  &change,
+			}); err != nil {
+				return err
 			}
 		}
 
 		if err := dbData.UpdateTag(ctx, knitId, delta); errors.Is(err, kerr.ErrMissing) {
-			return binderr.NewErrorMessage(http.StatusNotFound, "correspontind data is missing")
+			return binderr.NewErrorMessage(http.StatusNotFound, "corresponding data is missing")
 		} else if err != nil {
 			return binderr.InternalServerError(err)
 		}
@@ -168,6 +184,152 @@ func PutTagForDataHandler(dbData kdbdata.DataInterface, paramKey string) echo.Ha
 			return binderr.InternalServerError(errors.New("data not found; the data was updated tag just now"))
 		}
 
-		return c.JSON(http.StatusOK, binddata.ComposeDetail(d))
+		resp := binddata.ComposeDetail(d)
+		hookAfter(c, dataHook, apiwebhooks.DataEvent{
+			Event: apiwebhooks.DataTagged,
+			Data:  resp,
+			Tags:  &change,
+		})
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+// PreviewTagForDataHandler returns a handler to preview Runs
+// which would be created when tags of the Data are changed.
+//
+// Tags are not changed actually.
+func PreviewTagForDataHandler(dbData kdbdata.DataInterface, paramKey string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		knitId := c.Param(paramKey)
+
+		delta, err := readTagChange(c, true)
+		if err != nil {
+			return err
+		}
+
+		previews, err := dbData.PreviewUpdateTag(ctx, knitId, delta)
+		if errors.Is(err, kerr.ErrMissing) {
+			return binderr.NewErrorMessage(http.StatusNotFound, "corresponding data is missing")
+		} else if err != nil {
+			return binderr.InternalServerError(err)
+		}
+
+		return c.JSON(http.StatusOK, slices.Map(previews, bindruns.ComposePreview))
+	}
+}
+
+// DeleteDataHandler returns a handler to delete the Data.
+//
+// The Data is deleted with the Run which has generated it.
+// When the Run has other outputs (including its log), or the Data is used by other Runs, it is 409 Conflict.
+// To delete such Data, delete the Run instead.
+func DeleteDataHandler(dbData kdbdata.DataInterface, dbRun kdbrun.Interface, paramKey string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		knitId := c.Param(paramKey)
+
+		resultSet, err := dbData.Get(ctx, []string{knitId})
+		if err != nil {
+			return binderr.InternalServerError(err)
+		}
+		d, ok := resultSet[knitId]
+		if !ok {
+			return binderr.NewErrorMessage(http.StatusNotFound, "corresponding data is missing")
+		}
+
+		// deleting the data deletes the whole Run generating it.
+		// refuse when it would delete other outputs silently.
+		runs, err := dbRun.Get(ctx, []string{d.Upsteram.RunBody.Id})
+		if err != nil {
+			return binderr.InternalServerError(err)
+		}
+		run, ok := runs[d.Upsteram.RunBody.Id]
+		if !ok {
+			return binderr.NewErrorMessage(http.StatusNotFound, "corresponding data is missing")
+		}
+		if siblings := siblingsOf(run, knitId); 0 < len(siblings) {
+			return binderr.Conflict(
+				fmt.Sprintf(
+					"the run %s generating the data has other outputs: %s",
+					run.Id, strings.Join(siblings, ", "),
+				),
+				binderr.WithAdvice(fmt.Sprintf(
+					"to delete them together, delete the run %s (knit run rm)", run.Id,
+				)),
+			)
+		}
+
+		if err := dbRun.Delete(ctx, d.Upsteram.RunBody.Id); errors.Is(err, kerr.ErrMissing) {
+			return binderr.NewErrorMessage(http.StatusNotFound, "corresponding data is missing")
+		} else if errors.Is(err, domain.ErrRunIsProtected) {
+			return binderr.Conflict(
+				"the data, or other outputs of the run generating the data, are in use",
+				binderr.WithError(err),
+				binderr.WithAdvice(fmt.Sprintf("delete runs using outputs of the run %s first", d.Upsteram.RunBody.Id)),
+			)
+		} else if err != nil {
+			return binderr.InternalServerError(err)
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// siblingsOf returns knit ids of outputs and the log of the Run, except knitId.
+func siblingsOf(run domain.Run, knitId string) []string {
+	siblings := []string{}
+	for _, o := range run.Outputs {
+		if o.KnitDataBody.KnitId != knitId {
+			siblings = append(siblings, o.KnitDataBody.KnitId)
+		}
+	}
+	if run.Log != nil && run.Log.KnitDataBody.KnitId != knitId {
+		siblings = append(siblings, run.Log.KnitDataBody.KnitId)
+	}
+	return siblings
+}
+
+// readTagChange reads tags.Change from the request body as domain.TagDelta.
+//
+// When strict is true, malformed tags are 400 Bad Request.
+// Otherwise, they are left out from the delta.
+func readTagChange(c echo.Context, strict bool) (domain.TagDelta, error) {
+	change := apitags.Change{}
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&change); err != nil {
+		return domain.TagDelta{}, binderr.NewErrorMessage(
+			http.StatusBadRequest,
+			"format error",
+			binderr.WithAdvice(err.Error()),
+			binderr.WithError(err),
+		)
+	}
+
+	delta := domain.TagDelta{
+		RemoveKey: change.RemoveKey,
+	}
+	for _, tag := range change.AddTags {
+		t, err := domain.NewTag(tag.Key, tag.Value)
+		if err != nil {
+			if !strict {
+				continue
+			}
+			return domain.TagDelta{}, binderr.BadRequest(fmt.Sprintf("bad tag: %s", tag), err)
+		}
+		delta.Add = append(delta.Add, t)
+	}
+	for _, tag := range change.RemoveTags {
+		t, err := domain.NewTag(tag.Key, tag.Value)
+		if err != nil {
+			if !strict {
+				continue
+			}
+			return domain.TagDelta{}, binderr.BadRequest(fmt.Sprintf("bad tag: %s", tag), err)
+		}
+		delta.Remove = append(delta.Remove, t)
 	}
+	return delta, nil
 }
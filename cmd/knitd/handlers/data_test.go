@@ -16,10 +16,13 @@ import (
 	"github.com/opst/knitfab-api-types/plans"
 	"github.com/opst/knitfab-api-types/runs"
 	"github.com/opst/knitfab-api-types/tags"
+	apiwebhooks "github.com/opst/knitfab-api-types/webhooks"
 	httptestutil "github.com/opst/knitfab/internal/testutils/http"
 	"github.com/opst/knitfab/pkg/domain"
 	dbmock "github.com/opst/knitfab/pkg/domain/data/db/mock"
 	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	dbrunmock "github.com/opst/knitfab/pkg/domain/run/db/mock"
+	"github.com/opst/knitfab/pkg/hook"
 	"github.com/opst/knitfab/pkg/utils/cmp"
 	"github.com/opst/knitfab/pkg/utils/slices"
 	"github.com/opst/knitfab/pkg/utils/try"
@@ -31,8 +34,8 @@ func TestGetDataForDataHandler(t *testing.T) {
 
 	t.Run("When data is received from the database, it should be converted to JSON format", func(t *testing.T) {
 		mckdbdata := dbmock.NewDataInterface()
-		mckdbdata.Impl.Find = func(ctx context.Context, tags []domain.Tag, since *time.Time, until *time.Time) ([]string, error) {
-			return []string{"knit-1", "knit-2"}, nil
+		mckdbdata.Impl.Find = func(ctx context.Context, tags []domain.Tag, since *time.Time, until *time.Time, page domain.Page) ([]string, string, error) {
+			return []string{"knit-1", "knit-2"}, "", nil
 		}
 		mckdbdata.Impl.Get = func(ctx context.Context, knitId []string) (map[string]domain.KnitData, error) {
 			d := map[string]domain.KnitData{
@@ -175,6 +178,7 @@ func TestGetDataForDataHandler(t *testing.T) {
 				Tags  []domain.Tag
 				Since *time.Time
 				Until *time.Time
+				Page  domain.Page
 			}{
 				{Tags: expectTag, Since: &expectedSince, Until: &expectedUntil},
 			},
@@ -183,14 +187,16 @@ func TestGetDataForDataHandler(t *testing.T) {
 					Tags  []domain.Tag
 					Since *time.Time
 					Until *time.Time
+					Page  domain.Page
 				},
 				b struct {
 					Tags  []domain.Tag
 					Since *time.Time
 					Until *time.Time
+					Page  domain.Page
 				}) bool {
 				return cmp.SliceContentEqWith(slices.RefOf(a.Tags), slices.RefOf(b.Tags), (*domain.Tag).Equal) &&
-					a.Since.Equal(*b.Since) && a.Until.Equal(*b.Until)
+					a.Since.Equal(*b.Since) && a.Until.Equal(*b.Until) && a.Page == b.Page
 			},
 		) {
 			t.Error("DataInterface.Find did not call with correct userTag args.")
@@ -307,9 +313,9 @@ func TestGetDataForDataHandler(t *testing.T) {
 		knitId := []string{}
 
 		mckdbdata := dbmock.NewDataInterface()
-		mckdbdata.Impl.Find = func(ctx context.Context, tags []domain.Tag, since *time.Time, until *time.Time) ([]string, error) {
+		mckdbdata.Impl.Find = func(ctx context.Context, tags []domain.Tag, since *time.Time, until *time.Time, page domain.Page) ([]string, string, error) {
 			d := knitId
-			return d, nil
+			return d, "", nil
 		}
 
 		e := echo.New()
@@ -367,8 +373,8 @@ func TestGetDataForDataHandler(t *testing.T) {
 
 	t.Run("When Process of obtaining knitId from specified tag encounters an internal error, status code should be 500", func(t *testing.T) {
 		mckdbdata := dbmock.NewDataInterface()
-		mckdbdata.Impl.Find = func(ctx context.Context, tags []domain.Tag, since *time.Time, until *time.Time) ([]string, error) {
-			return nil, errors.New("Test Internal Error")
+		mckdbdata.Impl.Find = func(ctx context.Context, tags []domain.Tag, since *time.Time, until *time.Time, page domain.Page) ([]string, string, error) {
+			return nil, "", errors.New("Test Internal Error")
 		}
 
 		e := echo.New()
@@ -390,9 +396,9 @@ func TestGetDataForDataHandler(t *testing.T) {
 		knitId := []string{"knit-1"}
 
 		mckdbdata := dbmock.NewDataInterface()
-		mckdbdata.Impl.Find = func(ctx context.Context, tags []domain.Tag, since *time.Time, until *time.Time) ([]string, error) {
+		mckdbdata.Impl.Find = func(ctx context.Context, tags []domain.Tag, since *time.Time, until *time.Time, page domain.Page) ([]string, string, error) {
 			d := knitId
-			return d, nil
+			return d, "", nil
 		}
 		mckdbdata.Impl.Get = func(ctx context.Context, knitId []string) (map[string]domain.KnitData, error) {
 			return nil, errors.New("Test Internal Error")
@@ -624,13 +630,38 @@ func TestPutTagsForDataHandler(t *testing.T) {
 		c.SetParamNames("knitid")
 		c.SetParamValues(knitId)
 
-		testee := handlers.PutTagForDataHandler(dbdata, "knitid")
+		hookCalls := []string{}
+		dataHook := hook.Func[apiwebhooks.DataEvent, struct{}]{
+			BeforeFn: func(ev apiwebhooks.DataEvent) (struct{}, error) {
+				hookCalls = append(hookCalls, "before")
+				if ev.Event != apiwebhooks.DataTagged || ev.Data.KnitId != knitId || ev.Tags == nil || len(ev.Tags.AddTags) != 2 || len(ev.Tags.RemoveTags) != 2 {
+					t.Errorf("unexpected event for before: %+v", ev)
+				}
+				if len(dbdata.Calls.Updatetag) != 0 {
+					t.Error("before hook is called after update")
+				}
+				return struct{}{}, nil
+			},
+			AfterFn: func(ev apiwebhooks.DataEvent) error {
+				hookCalls = append(hookCalls, "after")
+				if ev.Event != apiwebhooks.DataTagged || !expectedResponse.Equal(ev.Data) || ev.Tags == nil {
+					t.Errorf("unexpected event for after: %+v", ev)
+				}
+				return nil
+			},
+		}
+
+		testee := handlers.PutTagForDataHandler(dbdata, "knitid", dataHook)
 		err := testee(c)
 
 		if err != nil {
 			t.Error("unexpected error occures")
 		}
 
+		if !cmp.SliceEq(hookCalls, []string{"before", "after"}) {
+			t.Errorf("hook calls: %v", hookCalls)
+		}
+
 		if !cmp.SliceContentEqWith(
 			dbdata.Calls.Updatetag,
 			[]struct {
@@ -673,7 +704,7 @@ func TestPutTagsForDataHandler(t *testing.T) {
 		c.SetParamValues("test-knit-id")
 
 		dbtag := dbmock.NewDataInterface()
-		testee := handlers.PutTagForDataHandler(dbtag, "knitid")
+		testee := handlers.PutTagForDataHandler(dbtag, "knitid", hook.None[apiwebhooks.DataEvent]{})
 		err := testee(c)
 
 		var echoErr *echo.HTTPError
@@ -721,7 +752,7 @@ func TestPutTagsForDataHandler(t *testing.T) {
 			c.SetPath("/data/:knitid")
 			c.SetParamNames("knitid")
 			c.SetParamValues("test-knit-id")
-			testee := handlers.PutTagForDataHandler(dbData, "knitid")
+			testee := handlers.PutTagForDataHandler(dbData, "knitid", hook.None[apiwebhooks.DataEvent]{})
 
 			err := testee(c)
 
@@ -738,6 +769,7 @@ func TestPutTagsForDataHandler(t *testing.T) {
 
 	t.Run("If target data is not found, it should response Not Found", func(t *testing.T) {
 		dbtag := dbmock.NewDataInterface()
+		dbtag.Impl.Get = getData("example-knit-id")
 		dbtag.Impl.UpdateTag = func(ctx context.Context, knitId string, delta domain.TagDelta) error {
 			return kerr.ErrMissing
 		}
@@ -758,7 +790,7 @@ func TestPutTagsForDataHandler(t *testing.T) {
 		c.SetPath("/data/:knitid")
 		c.SetParamNames("knitid")
 		c.SetParamValues("example-knit-id")
-		testee := handlers.PutTagForDataHandler(dbtag, "knitid")
+		testee := handlers.PutTagForDataHandler(dbtag, "knitid", hook.None[apiwebhooks.DataEvent]{})
 		err := testee(c)
 
 		var echoErr *echo.HTTPError
@@ -772,6 +804,7 @@ func TestPutTagsForDataHandler(t *testing.T) {
 
 	t.Run("update operation did not completed with unexpected error. it should be retur internal server error", func(t *testing.T) {
 		dbtag := dbmock.NewDataInterface()
+		dbtag.Impl.Get = getData("example-knit-id")
 		dbtag.Impl.UpdateTag = func(context.Context, string, domain.TagDelta) error {
 			return errors.New("some kind of error happen")
 		}
@@ -791,7 +824,7 @@ func TestPutTagsForDataHandler(t *testing.T) {
 		c.SetPath("/data/:knitid")
 		c.SetParamNames("knitid")
 		c.SetParamValues("example-knit-id")
-		testee := handlers.PutTagForDataHandler(dbtag, "knitid")
+		testee := handlers.PutTagForDataHandler(dbtag, "knitid", hook.None[apiwebhooks.DataEvent]{})
 		err := testee(c)
 
 		var echoErr *echo.HTTPError
@@ -803,3 +836,372 @@ func TestPutTagsForDataHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestPutTagForDataHandler_Hook(t *testing.T) {
+	body := []byte(`{"add": [{"key": "model", "value": "candidate"}]}`)
+
+	for name, testcase := range map[string]struct {
+		err  error
+		code int
+	}{
+		"when the hook rejects, it responses Forbidden": {
+			err:  hook.ErrHookRejected,
+			code: http.StatusForbidden,
+		},
+		"when the hook fails, it responses Service Unavailable": {
+			err:  errors.New("fake error"),
+			code: http.StatusServiceUnavailable,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dbdata := dbmock.NewDataInterface()
+			dbdata.Impl.Get = getData("example-knit-id")
+			dbdata.Impl.UpdateTag = func(context.Context, string, domain.TagDelta) error {
+				t.Error("UpdateTag should not be called")
+				return nil
+			}
+
+			e := echo.New()
+			c, _ := httptestutil.Put(e, "/data/example-knit-id", bytes.NewReader(body))
+			c.SetPath("/data/:knitid")
+			c.SetParamNames("knitid")
+			c.SetParamValues("example-knit-id")
+			testee := handlers.PutTagForDataHandler(
+				dbdata, "knitid",
+				hook.Func[apiwebhooks.DataEvent, struct{}]{
+					BeforeFn: func(apiwebhooks.DataEvent) (struct{}, error) { return struct{}{}, testcase.err },
+					AfterFn: func(apiwebhooks.DataEvent) error {
+						t.Error("after hook should not be called")
+						return nil
+					},
+				},
+			)
+			err := testee(c)
+
+			var echoErr *echo.HTTPError
+			if !errors.As(err, &echoErr) {
+				t.Fatalf("error is not echo.HTTPError. actual = %+v", err)
+			}
+			if echoErr.Code != testcase.code {
+				t.Errorf("unmatch error code:%d, expeced:%d", echoErr.Code, testcase.code)
+			}
+		})
+	}
+}
+
+func TestPutTagForDataHandler_SystemTags(t *testing.T) {
+	// tags in requests are user tags. system tags ("knit#...") are not user tags.
+	for name, body := range map[string]string{
+		"when system tags are added":   `{"add": [{"key": "knit#timestamp", "value": "2024-03-01T12:00:00+00:00"}]}`,
+		"when system tags are removed": `{"remove": [{"key": "knit#transient", "value": "processing"}]}`,
+	} {
+		t.Run(name+", it responses Bad Request and changes nothing", func(t *testing.T) {
+			dbdata := dbmock.NewDataInterface()
+			dbdata.Impl.Get = getData("example-knit-id")
+
+			e := echo.New()
+			c, _ := httptestutil.Put(e, "/data/example-knit-id", bytes.NewBufferString(body))
+			c.SetPath("/data/:knitid")
+			c.SetParamNames("knitid")
+			c.SetParamValues("example-knit-id")
+			testee := handlers.PutTagForDataHandler(dbdata, "knitid", hook.None[apiwebhooks.DataEvent]{})
+
+			err := testee(c)
+			if got := statusOf(t, err); got != http.StatusBadRequest {
+				t.Errorf("status code: got %d, want %d", got, http.StatusBadRequest)
+			}
+			if len(dbdata.Calls.Updatetag) != 0 {
+				t.Error("UpdateTag should not be called")
+			}
+		})
+
+		t.Run(name+", its preview responses Bad Request", func(t *testing.T) {
+			dbdata := dbmock.NewDataInterface()
+
+			e := echo.New()
+			c, _ := httptestutil.Post(e, "/api/data/:knitId/preview", bytes.NewBufferString(body))
+			c.SetParamNames("knitId")
+			c.SetParamValues("example-knit-id")
+
+			err := handlers.PreviewTagForDataHandler(dbdata, "knitId")(c)
+			if got := statusOf(t, err); got != http.StatusBadRequest {
+				t.Errorf("status code: got %d, want %d", got, http.StatusBadRequest)
+			}
+			if len(dbdata.Calls.PreviewUpdateTag) != 0 {
+				t.Error("PreviewUpdateTag should not be called")
+			}
+		})
+	}
+}
+
+// getData returns a fake of DataInterface.Get which knows a Data with the knitId.
+func getData(knitId string) func(context.Context, []string) (map[string]domain.KnitData, error) {
+	return func(context.Context, []string) (map[string]domain.KnitData, error) {
+		return map[string]domain.KnitData{
+			knitId: {
+				KnitDataBody: domain.KnitDataBody{
+					KnitId: knitId, VolumeRef: "#volume-ref",
+					Tags: domain.NewTagSet([]domain.Tag{{Key: tags.KeyKnitId, Value: knitId}}),
+				},
+				Upsteram: domain.DataSource{
+					RunBody: domain.RunBody{
+						Id: "run#1", Status: domain.Done,
+						PlanBody: domain.PlanBody{PlanId: "plan#1", Hash: "#plan1", Active: true},
+					},
+					MountPoint: &domain.MountPoint{Id: 1010, Path: "/out/1"},
+				},
+			},
+		}, nil
+	}
+}
+
+func TestPreviewTagForDataHandler(t *testing.T) {
+	body := `{"add": [{"key": "type", "value": "raw data"}], "remove": [{"key": "stage", "value": "draft"}]}`
+
+	t.Run("it responses runs which would be created", func(t *testing.T) {
+		dbdata := dbmock.NewDataInterface()
+		dbdata.Impl.PreviewUpdateTag = func(ctx context.Context, knitId string, delta domain.TagDelta) ([]domain.RunPreview, error) {
+			return []domain.RunPreview{
+				{
+					Plan: domain.PlanBody{
+						PlanId: "plan-1", Active: true, Hash: "hash-1",
+						Image: &domain.ImageIdentifier{Image: "repo.invalid/image", Version: "v1"},
+					},
+					Inputs: []domain.Assignment{
+						{
+							MountPoint: domain.MountPoint{
+								Id: 1, Path: "/in/1",
+								Tags: domain.NewTagSet([]domain.Tag{{Key: "type", Value: "raw data"}}),
+							},
+							KnitDataBody: domain.KnitDataBody{KnitId: knitId},
+						},
+					},
+				},
+			}, nil
+		}
+
+		e := echo.New()
+		c, resprec := httptestutil.Post(e, "/api/data/:knitId/preview", bytes.NewBufferString(body))
+		c.SetParamNames("knitId")
+		c.SetParamValues("knit-1")
+
+		if err := handlers.PreviewTagForDataHandler(dbdata, "knitId")(c); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := dbdata.Calls.PreviewUpdateTag; len(got) != 1 {
+			t.Fatalf("PreviewUpdateTag is called %d times", len(got))
+		} else {
+			want := domain.TagDelta{
+				Add:    []domain.Tag{{Key: "type", Value: "raw data"}},
+				Remove: []domain.Tag{{Key: "stage", Value: "draft"}},
+			}
+			if got[0].KnitId != "knit-1" || !got[0].Delta.Equal(&want) {
+				t.Errorf("unexpected args: %+v", got[0])
+			}
+		}
+
+		actual := []runs.Preview{}
+		if err := json.Unmarshal(resprec.Body.Bytes(), &actual); err != nil {
+			t.Fatal(err)
+		}
+		expected := []runs.Preview{
+			{
+				Plan: plans.Summary{
+					PlanId: "plan-1",
+					Image:  &plans.Image{Repository: "repo.invalid/image", Tag: "v1"},
+				},
+				Inputs: []runs.Assignment{
+					{
+						Mountpoint: plans.Mountpoint{
+							Path: "/in/1", Tags: []tags.Tag{{Key: "type", Value: "raw data"}},
+						},
+						KnitId: "knit-1",
+					},
+				},
+			},
+		}
+		if !cmp.SliceContentEqWith(actual, expected, runs.Preview.Equal) {
+			t.Errorf("unexpected response:\n===actual===\n%+v\n===expected===\n%+v", actual, expected)
+		}
+	})
+
+	for name, testcase := range map[string]struct {
+		body string
+		err  error
+		then int
+	}{
+		"request is malformed": {
+			body: `{"add": [`, then: http.StatusBadRequest,
+		},
+		"data is missing": {
+			body: body, err: kerr.ErrMissing, then: http.StatusNotFound,
+		},
+		"unexpected error": {
+			body: body, err: errors.New("fake error"), then: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dbdata := dbmock.NewDataInterface()
+			dbdata.Impl.PreviewUpdateTag = func(ctx context.Context, knitId string, delta domain.TagDelta) ([]domain.RunPreview, error) {
+				return nil, testcase.err
+			}
+
+			e := echo.New()
+			c, _ := httptestutil.Post(e, "/api/data/:knitId/preview", bytes.NewBufferString(testcase.body))
+			c.SetParamNames("knitId")
+			c.SetParamValues("knit-1")
+
+			err := handlers.PreviewTagForDataHandler(dbdata, "knitId")(c)
+			if got := statusOf(t, err); got != testcase.then {
+				t.Errorf("status code: got %d, want %d", got, testcase.then)
+			}
+		})
+	}
+}
+
+func TestDeleteDataHandler(t *testing.T) {
+	knitData := domain.KnitData{
+		KnitDataBody: domain.KnitDataBody{KnitId: "knit-1"},
+		Upsteram:     domain.DataSource{RunBody: domain.RunBody{Id: "run-1"}},
+	}
+	// run-1 has knit-1 as its only output.
+	run := domain.Run{
+		RunBody: domain.RunBody{Id: "run-1"},
+		Outputs: []domain.Assignment{
+			{KnitDataBody: domain.KnitDataBody{KnitId: "knit-1"}},
+		},
+	}
+	getRun := func(r domain.Run) func(context.Context, []string) (map[string]domain.Run, error) {
+		return func(ctx context.Context, runIds []string) (map[string]domain.Run, error) {
+			return map[string]domain.Run{r.Id: r}, nil
+		}
+	}
+
+	t.Run("it deletes the run generating the data", func(t *testing.T) {
+		dbdata := dbmock.NewDataInterface()
+		dbdata.Impl.Get = func(ctx context.Context, knitIds []string) (map[string]domain.KnitData, error) {
+			return map[string]domain.KnitData{"knit-1": knitData}, nil
+		}
+		dbrun := dbrunmock.NewRunInterface()
+		dbrun.Impl.Get = getRun(run)
+		dbrun.Impl.Delete = func(ctx context.Context, runId string) error {
+			return nil
+		}
+
+		e := echo.New()
+		c, resprec := httptestutil.Delete(e, "/api/data/:knitId")
+		c.SetParamNames("knitId")
+		c.SetParamValues("knit-1")
+
+		if err := handlers.DeleteDataHandler(dbdata, dbrun, "knitId")(c); err != nil {
+			t.Fatal(err)
+		}
+		if resprec.Code != http.StatusNoContent {
+			t.Errorf("status code: got %d, want %d", resprec.Code, http.StatusNoContent)
+		}
+		if got := dbdata.Calls.Get; len(got) != 1 || !cmp.SliceEq(got[0].KnitId, []string{"knit-1"}) {
+			t.Errorf("unexpected Get calls: %+v", got)
+		}
+		if got := dbrun.Calls.Delete; !cmp.SliceEq(got, []string{"run-1"}) {
+			t.Errorf("unexpected Delete calls: %+v", got)
+		}
+	})
+
+	t.Run("it responses 404 when the data is missing", func(t *testing.T) {
+		dbdata := dbmock.NewDataInterface()
+		dbdata.Impl.Get = func(ctx context.Context, knitIds []string) (map[string]domain.KnitData, error) {
+			return map[string]domain.KnitData{}, nil
+		}
+		dbrun := dbrunmock.NewRunInterface()
+
+		e := echo.New()
+		c, _ := httptestutil.Delete(e, "/api/data/:knitId")
+		c.SetParamNames("knitId")
+		c.SetParamValues("knit-1")
+
+		err := handlers.DeleteDataHandler(dbdata, dbrun, "knitId")(c)
+		if got := statusOf(t, err); got != http.StatusNotFound {
+			t.Errorf("status code: got %d, want %d", got, http.StatusNotFound)
+		}
+		if len(dbrun.Calls.Delete) != 0 {
+			t.Error("Delete should not be called")
+		}
+	})
+
+	for name, siblings := range map[string]domain.Run{
+		"other outputs": {
+			RunBody: domain.RunBody{Id: "run-1"},
+			Outputs: []domain.Assignment{
+				{KnitDataBody: domain.KnitDataBody{KnitId: "knit-1"}},
+				{KnitDataBody: domain.KnitDataBody{KnitId: "knit-2"}},
+			},
+		},
+		"a log": {
+			RunBody: domain.RunBody{Id: "run-1"},
+			Outputs: []domain.Assignment{
+				{KnitDataBody: domain.KnitDataBody{KnitId: "knit-1"}},
+			},
+			Log: &domain.Log{KnitDataBody: domain.KnitDataBody{KnitId: "knit-log"}},
+		},
+	} {
+		t.Run("it responses 409 and deletes nothing when the run has "+name, func(t *testing.T) {
+			dbdata := dbmock.NewDataInterface()
+			dbdata.Impl.Get = func(ctx context.Context, knitIds []string) (map[string]domain.KnitData, error) {
+				return map[string]domain.KnitData{"knit-1": knitData}, nil
+			}
+			dbrun := dbrunmock.NewRunInterface()
+			dbrun.Impl.Get = getRun(siblings)
+
+			e := echo.New()
+			c, _ := httptestutil.Delete(e, "/api/data/:knitId")
+			c.SetParamNames("knitId")
+			c.SetParamValues("knit-1")
+
+			err := handlers.DeleteDataHandler(dbdata, dbrun, "knitId")(c)
+			if got := statusOf(t, err); got != http.StatusConflict {
+				t.Errorf("status code: got %d, want %d", got, http.StatusConflict)
+			}
+			if len(dbrun.Calls.Delete) != 0 {
+				t.Error("Delete should not be called")
+			}
+		})
+	}
+
+	for name, testcase := range map[string]struct {
+		err  error
+		then int
+	}{
+		"run is protected": {
+			err: domain.ErrRunHasDownstreams, then: http.StatusConflict,
+		},
+		"run is missing": {
+			err: kerr.ErrMissing, then: http.StatusNotFound,
+		},
+		"unexpected error": {
+			err: errors.New("fake error"), then: http.StatusInternalServerError,
+		},
+	} {
+		t.Run("when deleting run fails because "+name, func(t *testing.T) {
+			dbdata := dbmock.NewDataInterface()
+			dbdata.Impl.Get = func(ctx context.Context, knitIds []string) (map[string]domain.KnitData, error) {
+				return map[string]domain.KnitData{"knit-1": knitData}, nil
+			}
+			dbrun := dbrunmock.NewRunInterface()
+			dbrun.Impl.Get = getRun(run)
+			dbrun.Impl.Delete = func(ctx context.Context, runId string) error {
+				return testcase.err
+			}
+
+			e := echo.New()
+			c, _ := httptestutil.Delete(e, "/api/data/:knitId")
+			c.SetParamNames("knitId")
+			c.SetParamValues("knit-1")
+
+			err := handlers.DeleteDataHandler(dbdata, dbrun, "knitId")(c)
+			if got := statusOf(t, err); got != testcase.then {
+				t.Errorf("status code: got %d, want %d", got, testcase.then)
+			}
+		})
+	}
+}
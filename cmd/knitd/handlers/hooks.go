@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	binderr "github.com/opst/knitfab/pkg/api-types-binding/errors"
+	"github.com/opst/knitfab/pkg/hook"
+)
+
+// hookBefore calls the "before" hook, and converts its failure into an HTTP error.
+func hookBefore[T any](ctx context.Context, h hook.Hook[T, struct{}], value T) error {
+	if _, err := h.Before(ctx, value); err != nil {
+		return hookError(err)
+	}
+	return nil
+}
+
+// hookError converts a failure of "before" hooks into an HTTP error.
+//
+// When the hook rejects the value, it is 403 Forbidden.
+// Otherwise, it is 503 Service Unavailable.
+func hookError(err error) error {
+	if errors.Is(err, hook.ErrHookRejected) {
+		return binderr.NewErrorMessage(
+			http.StatusForbidden, "rejected by hook",
+			binderr.WithError(err),
+		)
+	}
+	return binderr.ServiceUnavailable("hook is not available. retry later.", err)
+}
+
+// hookAfter queues the value into the outbox of the "after" hook, if any, and calls it.
+//
+// The change has been committed already, so failures are just logged.
+func hookAfter[T any](c echo.Context, h hook.Hook[T, struct{}], value T) {
+	ctx := c.Request().Context()
+	if err := hook.Enqueue(ctx, h, value); err != nil {
+		c.Logger().Errorf("after hook failed: %s", err)
+	}
+	if err := h.After(ctx, value); err != nil {
+		c.Logger().Errorf("after hook failed: %s", err)
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opst/knitfab-api-types/data"
+	"github.com/opst/knitfab-api-types/lineage"
+	apiruns "github.com/opst/knitfab-api-types/runs"
+	binddata "github.com/opst/knitfab/pkg/api-types-binding/data"
+	binderr "github.com/opst/knitfab/pkg/api-types-binding/errors"
+	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
+	"github.com/opst/knitfab/pkg/domain"
+	kdbdata "github.com/opst/knitfab/pkg/domain/data/db"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	kdbrun "github.com/opst/knitfab/pkg/domain/run/db"
+)
+
+// GetLineageHandler returns a handler to trace the Data Lineage from a Data.
+//
+// Query parameters:
+//
+// - upstream, downstream: "true" to trace the direction.
+// If neither is specified, both directions are traced.
+//
+// - depth: max number of Runs to be traced along a path, positive integer or "all".
+// Default is "all".
+func GetLineageHandler(dbData kdbdata.DataInterface, dbRun kdbrun.Interface, paramKey string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Add("Content-Type", "application/json")
+		ctx := c.Request().Context()
+		knitId := c.Param(paramKey)
+
+		query := domain.LineageQuery{Depth: -1}
+		for name, dest := range map[string]*bool{
+			"upstream":   &query.Upstream,
+			"downstream": &query.Downstream,
+		} {
+			p := c.QueryParam(name)
+			if p == "" {
+				continue
+			}
+			b, err := strconv.ParseBool(p)
+			if err != nil {
+				return binderr.BadRequest(`"`+name+`" should be "true" or "false"`, err)
+			}
+			*dest = b
+		}
+		if c.QueryParam("upstream") == "" && c.QueryParam("downstream") == "" {
+			query.Upstream = true
+			query.Downstream = true
+		}
+
+		if p := c.QueryParam("depth"); p != "" && p != "all" {
+			d, err := strconv.Atoi(p)
+			if err != nil || d <= 0 {
+				return binderr.BadRequest(`"depth" should be a positive integer or "all"`, err)
+			}
+			query.Depth = d
+		}
+
+		lin, err := dbData.Lineage(ctx, knitId, query)
+		if err != nil {
+			if errors.Is(err, kerr.ErrMissing) {
+				return binderr.NotFound()
+			}
+			return binderr.InternalServerError(err)
+		}
+
+		graph := lineage.Graph{
+			Root: knitId,
+			Data: []data.Detail{},
+			Runs: []apiruns.Detail{},
+		}
+
+		if 0 < len(lin.KnitIds) {
+			ds, err := dbData.Get(ctx, lin.KnitIds)
+			if err != nil {
+				return binderr.InternalServerError(err)
+			}
+			for _, knitId := range lin.KnitIds {
+				if d, ok := ds[knitId]; ok {
+					graph.Data = append(graph.Data, binddata.ComposeDetail(d))
+				}
+			}
+		}
+
+		if 0 < len(lin.RunIds) {
+			rs, err := dbRun.Get(ctx, lin.RunIds)
+			if err != nil {
+				return binderr.InternalServerError(err)
+			}
+			for _, runId := range lin.RunIds {
+				if r, ok := rs[runId]; ok {
+					graph.Runs = append(graph.Runs, bindruns.ComposeDetail(r))
+				}
+			}
+		}
+
+		return c.JSON(http.StatusOK, graph)
+	}
+}
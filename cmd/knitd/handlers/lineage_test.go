@@ -0,0 +1,260 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opst/knitfab-api-types/data"
+	"github.com/opst/knitfab-api-types/lineage"
+	apiruns "github.com/opst/knitfab-api-types/runs"
+	handlers "github.com/opst/knitfab/cmd/knitd/handlers"
+	httptestutil "github.com/opst/knitfab/internal/testutils/http"
+	binddata "github.com/opst/knitfab/pkg/api-types-binding/data"
+	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
+	"github.com/opst/knitfab/pkg/domain"
+	dbdatamock "github.com/opst/knitfab/pkg/domain/data/db/mock"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	dbrunmock "github.com/opst/knitfab/pkg/domain/run/db/mock"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+)
+
+func TestGetLineageHandler(t *testing.T) {
+	knitData := map[string]domain.KnitData{
+		"knit-1": {
+			KnitDataBody: domain.KnitDataBody{
+				KnitId: "knit-1",
+				Tags:   domain.NewTagSet([]domain.Tag{{Key: "type", Value: "dataset"}}),
+			},
+			Upsteram: domain.DataSource{
+				RunBody:    domain.RunBody{Id: "run-upload", Status: domain.Done},
+				MountPoint: &domain.MountPoint{Id: 1, Path: "/out"},
+			},
+			Downstreams: []domain.DataSink{
+				{
+					RunBody:    domain.RunBody{Id: "run-train", Status: domain.Done},
+					MountPoint: domain.MountPoint{Id: 2, Path: "/in"},
+				},
+			},
+		},
+		"knit-2": {
+			KnitDataBody: domain.KnitDataBody{
+				KnitId: "knit-2",
+				Tags:   domain.NewTagSet([]domain.Tag{{Key: "type", Value: "model"}}),
+			},
+			Upsteram: domain.DataSource{
+				RunBody:    domain.RunBody{Id: "run-train", Status: domain.Done},
+				MountPoint: &domain.MountPoint{Id: 3, Path: "/out"},
+			},
+		},
+	}
+	runs := map[string]domain.Run{
+		"run-train": {
+			RunBody: domain.RunBody{
+				Id: "run-train", Status: domain.Done,
+				PlanBody: domain.PlanBody{
+					PlanId: "plan-train", Active: true,
+					Image: &domain.ImageIdentifier{Image: "repo.invalid/train", Version: "v1"},
+				},
+			},
+			Inputs: []domain.Assignment{
+				{
+					MountPoint:   domain.MountPoint{Id: 2, Path: "/in"},
+					KnitDataBody: knitData["knit-1"].KnitDataBody,
+				},
+			},
+			Outputs: []domain.Assignment{
+				{
+					MountPoint:   domain.MountPoint{Id: 3, Path: "/out"},
+					KnitDataBody: knitData["knit-2"].KnitDataBody,
+				},
+			},
+		},
+	}
+
+	type When struct {
+		query   string
+		lineage domain.Lineage
+	}
+	type Then struct {
+		query domain.LineageQuery
+		body  lineage.Graph
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			mockData := dbdatamock.NewDataInterface()
+			mockData.Impl.Lineage = func(context.Context, string, domain.LineageQuery) (domain.Lineage, error) {
+				return when.lineage, nil
+			}
+			mockData.Impl.Get = func(_ context.Context, knitIds []string) (map[string]domain.KnitData, error) {
+				ret := map[string]domain.KnitData{}
+				for _, k := range knitIds {
+					if d, ok := knitData[k]; ok {
+						ret[k] = d
+					}
+				}
+				return ret, nil
+			}
+			mockRun := dbrunmock.NewRunInterface()
+			mockRun.Impl.Get = func(_ context.Context, runIds []string) (map[string]domain.Run, error) {
+				ret := map[string]domain.Run{}
+				for _, r := range runIds {
+					if run, ok := runs[r]; ok {
+						ret[r] = run
+					}
+				}
+				return ret, nil
+			}
+
+			e := echo.New()
+			c, respRec := httptestutil.Get(e, "/api/lineage/knit-1"+when.query)
+			c.SetPath("/api/lineage/:knitId")
+			c.SetParamNames("knitId")
+			c.SetParamValues("knit-1")
+
+			testee := handlers.GetLineageHandler(mockData, mockRun, "knitId")
+			if err := testee(c); err != nil {
+				t.Fatal(err)
+			}
+
+			if respRec.Code != http.StatusOK {
+				t.Errorf("status code: %d", respRec.Code)
+			}
+
+			{
+				expected := []struct {
+					KnitId string
+					Query  domain.LineageQuery
+				}{{KnitId: "knit-1", Query: then.query}}
+				if !cmp.SliceEq(mockData.Calls.Lineage, expected) {
+					t.Errorf(
+						"DataInterface.Lineage: (actual, expected) = (%+v, %+v)",
+						mockData.Calls.Lineage, expected,
+					)
+				}
+			}
+
+			actual := lineage.Graph{}
+			if err := json.Unmarshal(respRec.Body.Bytes(), &actual); err != nil {
+				t.Fatal(err)
+			}
+			if !actual.Equal(then.body) {
+				t.Errorf("response body:\n===actual===\n%+v\n===expected===\n%+v", actual, then.body)
+			}
+		}
+	}
+
+	t.Run("when no query parameters are given, it traces both directions without limit", theory(
+		When{
+			query: "",
+			lineage: domain.Lineage{
+				KnitIds: []string{"knit-1", "knit-2"},
+				RunIds:  []string{"run-train"},
+			},
+		},
+		Then{
+			query: domain.LineageQuery{Upstream: true, Downstream: true, Depth: -1},
+			body: lineage.Graph{
+				Root: "knit-1",
+				Data: []data.Detail{
+					binddata.ComposeDetail(knitData["knit-1"]),
+					binddata.ComposeDetail(knitData["knit-2"]),
+				},
+				Runs: []apiruns.Detail{bindruns.ComposeDetail(runs["run-train"])},
+			},
+		},
+	))
+
+	t.Run("when downstream and depth are given, it traces downstream with the depth", theory(
+		When{
+			query: "?downstream=true&depth=2",
+			lineage: domain.Lineage{
+				KnitIds: []string{"knit-1", "knit-2"},
+				RunIds:  []string{"run-train"},
+			},
+		},
+		Then{
+			query: domain.LineageQuery{Downstream: true, Depth: 2},
+			body: lineage.Graph{
+				Root: "knit-1",
+				Data: []data.Detail{
+					binddata.ComposeDetail(knitData["knit-1"]),
+					binddata.ComposeDetail(knitData["knit-2"]),
+				},
+				Runs: []apiruns.Detail{bindruns.ComposeDetail(runs["run-train"])},
+			},
+		},
+	))
+
+	t.Run("when upstream and depth=all are given, it traces upstream without limit", theory(
+		When{
+			query: "?upstream=true&depth=all",
+			lineage: domain.Lineage{
+				KnitIds: []string{"knit-1"},
+			},
+		},
+		Then{
+			query: domain.LineageQuery{Upstream: true, Depth: -1},
+			body: lineage.Graph{
+				Root: "knit-1",
+				Data: []data.Detail{binddata.ComposeDetail(knitData["knit-1"])},
+				Runs: []apiruns.Detail{},
+			},
+		},
+	))
+
+	t.Run("it responds error", func(t *testing.T) {
+		for name, testcase := range map[string]struct {
+			query      string
+			errLineage error
+			statusCode int
+		}{
+			"Bad Request: when upstream is not a boolean": {
+				query:      "?upstream=yes!",
+				statusCode: http.StatusBadRequest,
+			},
+			"Bad Request: when depth is not a number": {
+				query:      "?depth=deep",
+				statusCode: http.StatusBadRequest,
+			},
+			"Bad Request: when depth is zero": {
+				query:      "?depth=0",
+				statusCode: http.StatusBadRequest,
+			},
+			"Not Found: when the root data is missing": {
+				errLineage: kerr.ErrMissing,
+				statusCode: http.StatusNotFound,
+			},
+			"Internal Server Error: when DataInterface.Lineage causes error": {
+				errLineage: errors.New("fake error"),
+				statusCode: http.StatusInternalServerError,
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				mockData := dbdatamock.NewDataInterface()
+				mockData.Impl.Lineage = func(context.Context, string, domain.LineageQuery) (domain.Lineage, error) {
+					return domain.Lineage{}, testcase.errLineage
+				}
+				mockRun := dbrunmock.NewRunInterface()
+
+				e := echo.New()
+				c, _ := httptestutil.Get(e, "/api/lineage/knit-1"+testcase.query)
+				c.SetPath("/api/lineage/:knitId")
+				c.SetParamNames("knitId")
+				c.SetParamValues("knit-1")
+
+				testee := handlers.GetLineageHandler(mockData, mockRun, "knitId")
+				err := testee(c)
+				if httperr := new(echo.HTTPError); !errors.As(err, &httperr) {
+					t.Fatalf("error is not echo.HTTPError: %+v", err)
+				} else if httperr.Code != testcase.statusCode {
+					t.Errorf("status code: %d != %d", httperr.Code, testcase.statusCode)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	binderr "github.com/opst/knitfab/pkg/api-types-binding/errors"
+	"github.com/opst/knitfab/pkg/domain"
+)
+
+// queryParamToPage reads the page from query parameters.
+//
+// Query parameters (all are optional):
+//
+// - limit: max number of items in the page. if omitted, all items are in the page.
+//
+// - cursor: where the page starts after. it is given as the "next" link of the previous page.
+//
+// - order: "asc" (default) or "desc".
+func queryParamToPage(c echo.Context) (domain.Page, error) {
+	page := domain.Page{Cursor: c.QueryParam("cursor")}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l <= 0 {
+			return domain.Page{}, binderr.BadRequest(`"limit" should be a positive integer`, err)
+		}
+		page.Limit = l
+	}
+
+	if order := c.QueryParam("order"); order != "" {
+		o, err := domain.AsOrder(order)
+		if err != nil {
+			return domain.Page{}, binderr.BadRequest(`"order" should be one of "asc" or "desc"`, err)
+		}
+		page.Order = o
+	}
+
+	return page, nil
+}
+
+// pageError converts an error from Find into an HTTP error.
+func pageError(err error) error {
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		return binderr.BadRequest(`"cursor" is not understood`, err)
+	}
+	return binderr.InternalServerError(err)
+}
+
+// setNextLink sets the "Link" header pointing the next page.
+//
+// When next is empty, there are no next page and it does nothing.
+func setNextLink(c echo.Context, next string) {
+	if next == "" {
+		return
+	}
+
+	req := c.Request().URL
+	q := req.Query()
+	q.Set("cursor", next)
+	c.Response().Header().Add(
+		"Link", fmt.Sprintf(`<%s?%s>; rel="next"`, req.Path, q.Encode()),
+	)
+}
@@ -0,0 +1,170 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	handlers "github.com/opst/knitfab/cmd/knitd/handlers"
+	httptestutil "github.com/opst/knitfab/internal/testutils/http"
+	"github.com/opst/knitfab/pkg/domain"
+	dbdatamock "github.com/opst/knitfab/pkg/domain/data/db/mock"
+	dbplanmock "github.com/opst/knitfab/pkg/domain/plan/db/mock"
+	dbrunmock "github.com/opst/knitfab/pkg/domain/run/db/mock"
+	"github.com/opst/knitfab/pkg/utils/logic"
+)
+
+func TestFindHandlers_Page(t *testing.T) {
+	type When struct {
+		request string
+		next    string
+		err     error
+	}
+	type Then struct {
+		page       domain.Page
+		statusCode int
+		link       string
+	}
+
+	// each handler under test, with a function to take the page passed to Find.
+	type Testee struct {
+		handler echo.HandlerFunc
+		page    func() []domain.Page
+	}
+
+	for name, newTestee := range map[string]func(When) Testee{
+		"FindRunHandler": func(when When) Testee {
+			dbrun := dbrunmock.NewRunInterface()
+			dbrun.Impl.Find = func(ctx context.Context, q domain.RunFindQuery, page domain.Page) ([]string, string, error) {
+				return []string{}, when.next, when.err
+			}
+			dbrun.Impl.Get = func(ctx context.Context, runId []string) (map[string]domain.Run, error) {
+				return map[string]domain.Run{}, nil
+			}
+			return Testee{
+				handler: handlers.FindRunHandler(dbrun),
+				page: func() []domain.Page {
+					pages := []domain.Page{}
+					for _, c := range dbrun.Calls.Find {
+						pages = append(pages, c.Page)
+					}
+					return pages
+				},
+			}
+		},
+		"FindPlanHandler": func(when When) Testee {
+			dbplan := dbplanmock.NewPlanInteraface()
+			dbplan.Impl.Find = func(ctx context.Context, active logic.Ternary, imageVer domain.ImageIdentifier, inTag []domain.Tag, outTag []domain.Tag, page domain.Page) ([]string, string, error) {
+				return []string{}, when.next, when.err
+			}
+			dbplan.Impl.Get = func(ctx context.Context, planId []string) (map[string]*domain.Plan, error) {
+				return map[string]*domain.Plan{}, nil
+			}
+			return Testee{
+				handler: handlers.FindPlanHandler(dbplan),
+				page: func() []domain.Page {
+					pages := []domain.Page{}
+					for _, c := range dbplan.Calls.Find {
+						pages = append(pages, c.Page)
+					}
+					return pages
+				},
+			}
+		},
+		"GetDataForDataHandler": func(when When) Testee {
+			dbdata := dbdatamock.NewDataInterface()
+			dbdata.Impl.Find = func(ctx context.Context, tags []domain.Tag, since *time.Time, until *time.Time, page domain.Page) ([]string, string, error) {
+				return []string{}, when.next, when.err
+			}
+			return Testee{
+				handler: handlers.GetDataForDataHandler(dbdata),
+				page: func() []domain.Page {
+					pages := []domain.Page{}
+					for _, c := range dbdata.Calls.Find {
+						pages = append(pages, c.Page)
+					}
+					return pages
+				},
+			}
+		},
+	} {
+		theory := func(when When, then Then) func(*testing.T) {
+			return func(t *testing.T) {
+				testee := newTestee(when)
+
+				e := echo.New()
+				c, respRec := httptestutil.Get(e, when.request)
+
+				err := testee.handler(c)
+
+				statusCode := respRec.Result().StatusCode
+				if err != nil {
+					var echoErr *echo.HTTPError
+					if !errors.As(err, &echoErr) {
+						t.Fatalf("error is not echo.HTTPError. acutal = %#v", err)
+					}
+					statusCode = echoErr.Code
+				}
+				if statusCode != then.statusCode {
+					t.Errorf("status code: actual = %d, expected = %d", statusCode, then.statusCode)
+				}
+				if then.statusCode != http.StatusOK {
+					return
+				}
+
+				if pages := testee.page(); len(pages) != 1 || pages[0] != then.page {
+					t.Errorf("page: actual = %+v, expected = %+v", pages, then.page)
+				}
+				if link := respRec.Result().Header.Get("Link"); link != then.link {
+					t.Errorf("Link: actual = %q, expected = %q", link, then.link)
+				}
+			}
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Run("when no pages are specified, it finds all", theory(
+				When{request: "/api/items"},
+				Then{page: domain.Page{}, statusCode: http.StatusOK},
+			))
+
+			t.Run("when the page is specified, it finds the page and links the next page", theory(
+				When{
+					request: "/api/items?limit=10&order=desc&cursor=cursor-1",
+					next:    "cursor-2",
+				},
+				Then{
+					page:       domain.Page{Limit: 10, Order: domain.Descending, Cursor: "cursor-1"},
+					statusCode: http.StatusOK,
+					link: fmt.Sprintf(
+						`</api/items?%s>; rel="next"`,
+						url.Values{
+							"limit":  {"10"},
+							"order":  {"desc"},
+							"cursor": {"cursor-2"},
+						}.Encode(),
+					),
+				},
+			))
+
+			t.Run("when the limit is not a positive integer, it returns Bad Request", theory(
+				When{request: "/api/items?limit=0"},
+				Then{statusCode: http.StatusBadRequest},
+			))
+
+			t.Run("when the order is unknown, it returns Bad Request", theory(
+				When{request: "/api/items?order=random"},
+				Then{statusCode: http.StatusBadRequest},
+			))
+
+			t.Run("when the cursor is not understood, it returns Bad Request", theory(
+				When{request: "/api/items?cursor=broken", err: domain.ErrInvalidCursor},
+				Then{statusCode: http.StatusBadRequest},
+			))
+		})
+	}
+}
@@ -1,168 +1,469 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	apiplans "github.com/opst/knitfab-api-types/plans"
 	apitags "github.com/opst/knitfab-api-types/tags"
+	apiwebhooks "github.com/opst/knitfab-api-types/webhooks"
+	"github.com/opst/knitfab/pkg/admission"
 	binderr "github.com/opst/knitfab/pkg/api-types-binding/errors"
 	bindplan "github.com/opst/knitfab/pkg/api-types-binding/plans"
+	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
 	"github.com/opst/knitfab/pkg/domain"
 	kerr "github.com/opst/knitfab/pkg/domain/errors"
 	kdbplan "github.com/opst/knitfab/pkg/domain/plan/db"
+	"github.com/opst/knitfab/pkg/hook"
 	"github.com/opst/knitfab/pkg/utils/logic"
 	"github.com/opst/knitfab/pkg/utils/nils"
 	"github.com/opst/knitfab/pkg/utils/slices"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
-func PlanRegisterHandler(dbplan kdbplan.PlanInterface) echo.HandlerFunc {
+// PlanRegisterHandler returns a handler to register a new Plan.
+//
+// planHook is called before and after the registration as a "registered" event.
+//
+// Plans violating the policy are rejected with 403 Forbidden.
+func PlanRegisterHandler(dbplan kdbplan.PlanInterface, planHook hook.Hook[apiwebhooks.PlanEvent, struct{}], policy *admission.Policy) echo.HandlerFunc {
+	return registerPlanWith(
+		dbplan,
+		planHook,
+		policy,
+		func(c echo.Context, spec *domain.PlanSpec) (string, error) {
+			return dbplan.Register(c.Request().Context(), spec)
+		},
+	)
+}
+
+// PlanSupersedeHandler returns a handler to register a new Plan as the successor of the Plan.
+//
+// The predecessor is deactivated.
+// If query parameter "reroute" is true, Data waiting for the predecessor are dropped from it,
+// and no more Runs are made for the predecessor even if it is activated again.
+// The successor is nominated as a new Plan either way,
+// so Data matching its Inputs are processed by the successor.
+//
+// planHook is called before and after the registration as a "registered" event.
+//
+// Plans violating the policy are rejected with 403 Forbidden.
+func PlanSupersedeHandler(dbplan kdbplan.PlanInterface, planIdParam string, planHook hook.Hook[apiwebhooks.PlanEvent, struct{}], policy *admission.Policy) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		req := c.Request()
-		ctx := req.Context()
-		if strings.ToLower(req.Header.Get("content-type")) != "application/json" {
-			return binderr.BadRequest(
-				"unexpected content type. it shoule be application/json", nil,
-			)
+		reroute := false
+		if r := c.QueryParam("reroute"); r != "" {
+			b, err := strconv.ParseBool(r)
+			if err != nil {
+				return binderr.BadRequest(`"reroute" should be true or false`, err)
+			}
+			reroute = b
 		}
 
-		specInReq := new(apiplans.PlanSpec)
-		if err := json.NewDecoder(req.Body).Decode(specInReq); err != nil {
-			return binderr.BadRequest(
-				"can not understand the requested json", err,
-			)
+		return registerPlanWith(
+			dbplan,
+			planHook,
+			policy,
+			func(c echo.Context, spec *domain.PlanSpec) (string, error) {
+				return dbplan.Supersede(c.Request().Context(), c.Param(planIdParam), spec, reroute)
+			},
+		)(c)
+	}
+}
+
+func registerPlanWith(
+	dbplan kdbplan.PlanInterface,
+	planHook hook.Hook[apiwebhooks.PlanEvent, struct{}],
+	policy *admission.Policy,
+	register func(echo.Context, *domain.PlanSpec) (string, error),
+) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		specInReq, err := readPlanSpec(c)
+		if err != nil {
+			return err
+		}
+
+		spec, err := composePlanSpec(specInReq)
+		if err != nil {
+			return planError(err)
+		}
+
+		if err := admit(policy, admission.FromSpec(spec)); err != nil {
+			return err
+		}
+
+		if err := hookBefore(ctx, planHook, apiwebhooks.PlanEvent{
+			Event: apiwebhooks.PlanRegistered,
+			Spec:  specInReq,
+		}); err != nil {
+			return err
 		}
 
 		plan, err := func() (*domain.Plan, error) {
-			params := domain.PlanParam{
-				Image:      specInReq.Image.Repository,
-				Version:    specInReq.Image.Tag,
-				Active:     nils.Default(specInReq.Active, true),
-				Entrypoint: specInReq.Entrypoint,
-				Args:       specInReq.Args,
-				Inputs: slices.Map(
-					specInReq.Inputs,
-					func(mp apiplans.Mountpoint) domain.MountPointParam {
-						return domain.MountPointParam{
-							Path: mp.Path,
-							Tags: domain.NewTagSet(
-								slices.Map(mp.Tags, func(reqtag apitags.Tag) domain.Tag {
-									return domain.Tag{Key: reqtag.Key, Value: reqtag.Value}
-								}),
-							),
-						}
-					},
-				),
-				Resources: specInReq.Resources,
-				Outputs: slices.Map(
-					specInReq.Outputs,
-					func(mp apiplans.Mountpoint) domain.MountPointParam {
-						return domain.MountPointParam{
-							Path: mp.Path,
-							Tags: domain.NewTagSet(
-								slices.Map(mp.Tags, func(reqtag apitags.Tag) domain.Tag {
-									return domain.Tag{Key: reqtag.Key, Value: reqtag.Value}
-								}),
-							),
-						}
-					},
-				),
-				ServiceAccount: specInReq.ServiceAccount,
-				Annotations: slices.Map(specInReq.Annotations, func(a apiplans.Annotation) domain.Annotation {
-					return domain.Annotation{Key: a.Key, Value: a.Value}
-				}),
+			planId, err := register(c, spec)
+			if err != nil {
+				return nil, err
 			}
 
-			if params.Resources == nil {
-				params.Resources = map[string]resource.Quantity{}
-			}
-			if _, ok := params.Resources["cpu"]; !ok {
-				params.Resources["cpu"] = resource.MustParse("1")
+			plans, err := dbplan.Get(ctx, []string{planId})
+			if err != nil {
+				return nil, err
 			}
-			if _, ok := params.Resources["memory"]; !ok {
-				params.Resources["memory"] = resource.MustParse("1Gi")
+			return plans[planId], nil
+		}()
+
+		if err != nil {
+			return planError(err)
+		}
+
+		detail := bindplan.ComposeDetail(*plan)
+		hookAfter(c, planHook, apiwebhooks.PlanEvent{
+			Event: apiwebhooks.PlanRegistered,
+			Plan:  &detail,
+			Spec:  specInReq,
+		})
+
+		resp := c.Response()
+		resp.Header().Add("Content-Type", "application/json")
+
+		return c.JSON(
+			http.StatusOK,
+			detail,
+		)
+	}
+}
+
+// admit evaluates the Plan with the policy, and converts violations into an HTTP error.
+//
+// When the Plan violates the policy, it is 403 Forbidden.
+func admit(policy *admission.Policy, plan admission.Plan) error {
+	violations := policy.Admit(plan)
+	if len(violations) == 0 {
+		return nil
+	}
+	return binderr.NewErrorMessage(
+		http.StatusForbidden, "rejected by admission policy",
+		binderr.WithAdvice(strings.Join(
+			slices.Map(violations, admission.Violation.String), "; ",
+		)),
+	)
+}
+
+// currentPlan returns the Plan registered, or an HTTP error.
+func currentPlan(ctx context.Context, dbPlan kdbplan.PlanInterface, planId string) (*domain.Plan, error) {
+	plans, err := dbPlan.Get(ctx, []string{planId})
+	if errors.Is(err, kerr.ErrMissing) {
+		return nil, binderr.NotFound()
+	} else if err != nil {
+		return nil, binderr.InternalServerError(err)
+	}
+	p, ok := plans[planId]
+	if !ok {
+		return nil, binderr.NotFound()
+	}
+	return p, nil
+}
+
+// planHookBefore calls the "before" hook with the current Plan.
+func planHookBefore(
+	ctx context.Context,
+	planHook hook.Hook[apiwebhooks.PlanEvent, struct{}],
+	current *domain.Plan,
+	event apiwebhooks.PlanEvent,
+) error {
+	detail := bindplan.ComposeDetail(*current)
+	event.Plan = &detail
+	return hookBefore(ctx, planHook, event)
+}
+
+// PlanPreviewHandler returns a handler to preview Runs
+// which would be created when the Plan in the request is registered.
+//
+// The Plan is not registered actually.
+func PlanPreviewHandler(dbplan kdbplan.PlanInterface) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		specInReq, err := readPlanSpec(c)
+		if err != nil {
+			return err
+		}
+
+		previews, err := func() ([]domain.RunPreview, error) {
+			spec, err := composePlanSpec(specInReq)
+			if err != nil {
+				return nil, err
 			}
+			return dbplan.Preview(ctx, spec)
+		}()
+		if err != nil {
+			return planError(err)
+		}
+
+		return c.JSON(
+			http.StatusOK,
+			slices.Map(previews, bindruns.ComposePreview),
+		)
+	}
+}
+
+// readPlanSpec reads a PlanSpec from the request body.
+func readPlanSpec(c echo.Context) (*apiplans.PlanSpec, error) {
+	req := c.Request()
+	if strings.ToLower(req.Header.Get("content-type")) != "application/json" {
+		return nil, binderr.BadRequest(
+			"unexpected content type. it shoule be application/json", nil,
+		)
+	}
 
-			if l := specInReq.Log; l != nil {
-				params.Log = &domain.LogParam{
+	specInReq := new(apiplans.PlanSpec)
+	if err := json.NewDecoder(req.Body).Decode(specInReq); err != nil {
+		return nil, binderr.BadRequest(
+			"can not understand the requested json", err,
+		)
+	}
+	return specInReq, nil
+}
+
+// composePlanSpec converts PlanSpec in request to validated domain.PlanSpec.
+func composePlanSpec(specInReq *apiplans.PlanSpec) (*domain.PlanSpec, error) {
+	// digest, if any, is stored as the version of the image.
+	version := specInReq.Image.Tag
+	if d := specInReq.Image.Digest; d != "" {
+		version = d
+	}
+
+	params := domain.PlanParam{
+		Image:      specInReq.Image.Repository,
+		Version:    version,
+		Active:     nils.Default(specInReq.Active, true),
+		Entrypoint: specInReq.Entrypoint,
+		Args:       specInReq.Args,
+		Inputs: slices.Map(
+			specInReq.Inputs,
+			func(mp apiplans.Mountpoint) domain.MountPointParam {
+				return domain.MountPointParam{
+					Path: mp.Path,
 					Tags: domain.NewTagSet(
-						slices.Map(l.Tags, func(reqtag apitags.Tag) domain.Tag {
+						slices.Map(mp.Tags, func(reqtag apitags.Tag) domain.Tag {
 							return domain.Tag{Key: reqtag.Key, Value: reqtag.Value}
 						}),
 					),
 				}
-			}
-
-			if on := specInReq.OnNode; on != nil {
-				onNode := []domain.OnNode{}
-				for _, may := range on.May {
-					onNode = append(
-						onNode,
-						domain.OnNode{Mode: domain.MayOnNode, Key: may.Key, Value: may.Value},
-					)
-				}
-				for _, prefer := range on.Prefer {
-					onNode = append(
-						onNode,
-						domain.OnNode{Mode: domain.PreferOnNode, Key: prefer.Key, Value: prefer.Value},
-					)
-				}
-				for _, must := range on.Must {
-					onNode = append(
-						onNode,
-						domain.OnNode{Mode: domain.MustOnNode, Key: must.Key, Value: must.Value},
-					)
+			},
+		),
+		Resources: specInReq.Resources,
+		Outputs: slices.Map(
+			specInReq.Outputs,
+			func(mp apiplans.Mountpoint) domain.MountPointParam {
+				return domain.MountPointParam{
+					Path: mp.Path,
+					Tags: domain.NewTagSet(
+						slices.Map(mp.Tags, func(reqtag apitags.Tag) domain.Tag {
+							return domain.Tag{Key: reqtag.Key, Value: reqtag.Value}
+						}),
+					),
 				}
-				params.OnNode = onNode
+			},
+		),
+		ServiceAccount: specInReq.ServiceAccount,
+		Schedule:       specInReq.Schedule,
+		Priority:       specInReq.Priority,
+		MaxConcurrency: specInReq.MaxConcurrency,
+		Annotations: slices.Map(specInReq.Annotations, func(a apiplans.Annotation) domain.Annotation {
+			return domain.Annotation{Key: a.Key, Value: a.Value}
+		}),
+		ResourceRequests: specInReq.ResourceRequests,
+		Tolerations: slices.Map(specInReq.Tolerations, func(t apiplans.Toleration) domain.Toleration {
+			return domain.Toleration{
+				Key:      t.Key,
+				Operator: domain.TolerationOperator(t.Operator),
+				Value:    t.Value,
+				Effect:   domain.TaintEffect(t.Effect),
 			}
+		}),
+		PriorityClassName: specInReq.PriorityClassName,
+		RuntimeClassName:  specInReq.RuntimeClassName,
+	}
 
-			spec, err := params.Validate()
-			if err != nil {
-				return nil, err
-			}
+	if params.Resources == nil {
+		params.Resources = map[string]resource.Quantity{}
+	}
+	// default limits. when larger one is requested, it is the limit.
+	for typ, def := range map[string]resource.Quantity{
+		"cpu":    resource.MustParse("1"),
+		"memory": resource.MustParse("1Gi"),
+	} {
+		if _, ok := params.Resources[typ]; ok {
+			continue
+		}
+		if req, ok := params.ResourceRequests[typ]; ok && def.Cmp(req) < 0 {
+			def = req
+		}
+		params.Resources[typ] = def
+	}
+
+	if l := specInReq.Log; l != nil {
+		params.Log = &domain.LogParam{
+			Tags: domain.NewTagSet(
+				slices.Map(l.Tags, func(reqtag apitags.Tag) domain.Tag {
+					return domain.Tag{Key: reqtag.Key, Value: reqtag.Value}
+				}),
+			),
+		}
+	}
+
+	if on := specInReq.OnNode; on != nil {
+		onNode := []domain.OnNode{}
+		for _, may := range on.May {
+			onNode = append(
+				onNode,
+				domain.OnNode{Mode: domain.MayOnNode, Key: may.Key, Value: may.Value},
+			)
+		}
+		for _, prefer := range on.Prefer {
+			onNode = append(
+				onNode,
+				domain.OnNode{Mode: domain.PreferOnNode, Key: prefer.Key, Value: prefer.Value},
+			)
+		}
+		for _, must := range on.Must {
+			onNode = append(
+				onNode,
+				domain.OnNode{Mode: domain.MustOnNode, Key: must.Key, Value: must.Value},
+			)
+		}
+		params.OnNode = onNode
+	}
 
-			planId, err := dbplan.Register(ctx, spec)
+	if r := specInReq.Retry; r != nil {
+		retry := &domain.RetryPolicy{
+			MaxAttempts: r.MaxAttempts,
+			On:          slices.Map(r.On, func(c string) domain.ExitCause { return domain.ExitCause(c) }),
+		}
+		if r.Backoff != "" {
+			backoff, err := time.ParseDuration(r.Backoff)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("%w: backoff: %w", domain.ErrInvalidRetryPolicy, err)
+			}
+			retry.Backoff = backoff
+		}
+		for _, code := range r.ExitCodes {
+			if code < 0 || 255 < code {
+				return nil, fmt.Errorf("%w: exit code should be in 0-255: %d", domain.ErrInvalidRetryPolicy, code)
 			}
+			retry.ExitCodes = append(retry.ExitCodes, uint8(code))
+		}
+		params.Retry = retry
+	}
 
-			plans, err := dbplan.Get(ctx, []string{planId})
+	if d := specInReq.MaxDuration; d != "" {
+		maxDuration, err := time.ParseDuration(d)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", domain.ErrInvalidMaxDuration, err)
+		}
+		params.MaxDuration = maxDuration
+	}
+
+	for _, sc := range specInReq.Scratch {
+		scratch := domain.ScratchVolume{Path: sc.Path}
+		switch sc.Medium {
+		case "":
+		case apiplans.MediumMemory:
+			scratch.InMemory = true
+		default:
+			return nil, fmt.Errorf("%w: %s: medium should be empty or %s: %s", domain.ErrInvalidScratch, sc.Path, apiplans.MediumMemory, sc.Medium)
+		}
+		if l := sc.SizeLimit; l != "" {
+			sizeLimit, err := resource.ParseQuantity(l)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("%w: %s: size limit: %w", domain.ErrInvalidScratch, sc.Path, err)
 			}
-			return plans[planId], nil
-		}()
+			scratch.SizeLimit = sizeLimit
+		}
+		params.Scratches = append(params.Scratches, scratch)
+	}
 
+	if s := specInReq.ShmSize; s != "" {
+		shmSize, err := resource.ParseQuantity(s)
 		if err != nil {
-			if errors.Is(err, domain.ErrConflictingPlan) {
-				if planEx := new(domain.ErrEquivPlanExists); errors.As(err, &planEx) {
-					return binderr.Conflict(
-						"there are equiverent plan", binderr.WithSee(planEx.PlanId),
-					)
-				}
-				return binderr.Conflict("plan spec conflics with others", binderr.WithError(err))
+			return nil, fmt.Errorf("%w: shm size: %w", domain.ErrInvalidScratch, err)
+		}
+		params.ShmSize = shmSize
+	}
+
+	for _, e := range specInReq.Env {
+		env := domain.EnvVar{Name: e.Name, Value: e.Value}
+		if from := e.ValueFrom; from != nil {
+			if from.SecretKeyRef == nil && from.ConfigMapKeyRef == nil {
+				return nil, fmt.Errorf("%w: %s: value_from needs secret_key_ref or config_map_key_ref", domain.ErrInvalidEnv, e.Name)
 			}
-			if errors.Is(err, domain.ErrInvalidPlan) {
-				return binderr.BadRequest(err.Error(), err)
+			if ref := from.SecretKeyRef; ref != nil {
+				env.Secret = &domain.EnvVarKeyRef{Name: ref.Name, Key: ref.Key}
+			}
+			if ref := from.ConfigMapKeyRef; ref != nil {
+				env.ConfigMap = &domain.EnvVarKeyRef{Name: ref.Name, Key: ref.Key}
 			}
+		}
+		params.Env = append(params.Env, env)
+	}
 
-			return binderr.InternalServerError(err)
+	for nth, mp := range specInReq.Inputs {
+		sel, err := domain.ParseInputSelection(mp.Select)
+		if err != nil {
+			return nil, err
 		}
+		params.Inputs[nth].Selection = sel
+		params.Inputs[nth].Values = mp.Values
+	}
+	for nth, mp := range specInReq.Outputs {
+		sel, err := domain.ParseInputSelection(mp.Select)
+		if err != nil {
+			return nil, err
+		}
+		params.Outputs[nth].Selection = sel
+		params.Outputs[nth].Values = mp.Values
+	}
 
-		resp := c.Response()
-		resp.Header().Add("Content-Type", "application/json")
+	return params.Validate()
+}
 
-		return c.JSON(
-			http.StatusOK,
-			bindplan.ComposeDetail(*plan),
+// planError converts errors from registering Plan into error responses.
+func planError(err error) error {
+	if errors.Is(err, kerr.ErrMissing) {
+		return binderr.NotFound()
+	}
+	if errors.Is(err, domain.ErrPlanSuperseded) {
+		return binderr.Conflict(
+			"the plan is superseded already", binderr.WithError(err),
+			binderr.WithAdvice("supersede the latest version of the plan."),
 		)
 	}
+	if errors.Is(err, domain.ErrConflictingPlan) {
+		if planEx := new(domain.ErrEquivPlanExists); errors.As(err, &planEx) {
+			return binderr.Conflict(
+				"there are equiverent plan", binderr.WithSee(planEx.PlanId),
+			)
+		}
+		return binderr.Conflict("plan spec conflics with others", binderr.WithError(err))
+	}
+	if errors.Is(err, domain.ErrInvalidPlan) {
+		return binderr.BadRequest(err.Error(), err)
+	}
+
+	return binderr.InternalServerError(err)
 }
 
+// FindPlanHandler returns a handler to find Plans.
+//
+// Found Plans are paginated with query parameters "limit", "cursor" and "order" (see queryParamToPage).
+// When there are more Plans, the "Link" header points the next page.
 func FindPlanHandler(dbplan kdbplan.PlanInterface) echo.HandlerFunc {
 
 	type FindArgs struct {
@@ -221,12 +522,17 @@ func FindPlanHandler(dbplan kdbplan.PlanInterface) echo.HandlerFunc {
 		if err != nil {
 			return binderr.BadRequest("query specification is incorrect", err)
 		}
+		page, err := queryParamToPage(c)
+		if err != nil {
+			return err
+		}
 		ctx := c.Request().Context()
 
-		planIds, err := dbplan.Find(ctx, args.Active, args.ImageVer, args.InTag, args.OutTag)
+		planIds, next, err := dbplan.Find(ctx, args.Active, args.ImageVer, args.InTag, args.OutTag, page)
 		if err != nil {
-			return binderr.InternalServerError(err)
+			return pageError(err)
 		}
+		setNextLink(c, next)
 
 		ps, err := dbplan.Get(ctx, planIds)
 		if err != nil {
@@ -273,12 +579,29 @@ var (
 	errIncorrectQueryOutTag       = errors.New("incorrect query param out-tag")
 )
 
-func PutPlanForActivate(dbPlan kdbplan.PlanInterface, isActive bool) echo.HandlerFunc {
+// PutPlanForActivate returns a handler to activate or deactivate the Plan.
+//
+// planHook is called before and after the change as an "activated" or "deactivated" event.
+func PutPlanForActivate(dbPlan kdbplan.PlanInterface, isActive bool, planHook hook.Hook[apiwebhooks.PlanEvent, struct{}]) echo.HandlerFunc {
+	event := apiwebhooks.PlanDeactivated
+	if isActive {
+		event = apiwebhooks.PlanActivated
+	}
 
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 		planId := c.Param("planId")
 
+		current, err := currentPlan(ctx, dbPlan, planId)
+		if err != nil {
+			return err
+		}
+		if err := planHookBefore(
+			ctx, planHook, current, apiwebhooks.PlanEvent{Event: event},
+		); err != nil {
+			return err
+		}
+
 		if err := dbPlan.Activate(ctx, planId, isActive); errors.Is(err, kerr.ErrMissing) {
 			return binderr.NotFound()
 		} else if err != nil {
@@ -291,7 +614,9 @@ func PutPlanForActivate(dbPlan kdbplan.PlanInterface, isActive bool) echo.Handle
 		}
 
 		if p, ok := plans[planId]; ok {
-			return c.JSON(http.StatusOK, bindplan.ComposeDetail(*p))
+			detail := bindplan.ComposeDetail(*p)
+			hookAfter(c, planHook, apiwebhooks.PlanEvent{Event: event, Plan: &detail})
+			return c.JSON(http.StatusOK, detail)
 		} else {
 			return binderr.NotFound()
 		}
@@ -304,7 +629,12 @@ func Deref[T any, R any](f func(T) R) func(*T) R {
 	}
 }
 
-func PutPlanResource(dbPlan kdbplan.PlanInterface, planIdParam string) echo.HandlerFunc {
+// PutPlanResource returns a handler to change resources of the Plan.
+//
+// planHook is called before and after the change as a "resources" event.
+//
+// Changes making the Plan violate the policy are rejected with 403 Forbidden.
+func PutPlanResource(dbPlan kdbplan.PlanInterface, planIdParam string, planHook hook.Hook[apiwebhooks.PlanEvent, struct{}], policy *admission.Policy) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 		planId := c.Param(planIdParam)
@@ -314,6 +644,48 @@ func PutPlanResource(dbPlan kdbplan.PlanInterface, planIdParam string) echo.Hand
 			return binderr.BadRequest("can not understand the requested json", err)
 		}
 
+		current, err := currentPlan(ctx, dbPlan, planId)
+		if err != nil {
+			return err
+		}
+
+		changed := admission.FromPlan(current)
+		changed.Resources = map[string]resource.Quantity{}
+		for k, q := range current.Resources {
+			changed.Resources[k] = q
+		}
+		for k, q := range req.Set {
+			changed.Resources[k] = q
+		}
+		for _, k := range req.Unset {
+			delete(changed.Resources, k)
+		}
+
+		requests := map[string]resource.Quantity{}
+		for k, q := range current.ResourceRequests {
+			requests[k] = q
+		}
+		for k, q := range req.SetRequests {
+			requests[k] = q
+		}
+		for _, k := range req.UnsetRequests {
+			delete(requests, k)
+		}
+		if err := domain.ValidateResourceRequests(requests, changed.Resources); err != nil {
+			return binderr.BadRequest(err.Error(), err)
+		}
+
+		if err := admit(policy, changed); err != nil {
+			return err
+		}
+
+		if err := planHookBefore(
+			ctx, planHook, current,
+			apiwebhooks.PlanEvent{Event: apiwebhooks.PlanResourcesChanged, Resources: req},
+		); err != nil {
+			return err
+		}
+
 		if err := dbPlan.SetResourceLimit(ctx, planId, req.Set); err != nil {
 			if errors.Is(err, kerr.ErrMissing) {
 				return binderr.NotFound()
@@ -328,6 +700,24 @@ func PutPlanResource(dbPlan kdbplan.PlanInterface, planIdParam string) echo.Hand
 			return binderr.InternalServerError(err)
 		}
 
+		if 0 < len(req.SetRequests) {
+			if err := dbPlan.SetResourceRequest(ctx, planId, req.SetRequests); err != nil {
+				if errors.Is(err, kerr.ErrMissing) {
+					return binderr.NotFound()
+				}
+				return binderr.InternalServerError(err)
+			}
+		}
+
+		if 0 < len(req.UnsetRequests) {
+			if err := dbPlan.UnsetResourceRequest(ctx, planId, req.UnsetRequests); err != nil {
+				if errors.Is(err, kerr.ErrMissing) {
+					return binderr.NotFound()
+				}
+				return binderr.InternalServerError(err)
+			}
+		}
+
 		plans, err := dbPlan.Get(ctx, []string{planId})
 		if err != nil {
 			if errors.Is(err, kerr.ErrMissing) {
@@ -337,14 +727,24 @@ func PutPlanResource(dbPlan kdbplan.PlanInterface, planIdParam string) echo.Hand
 		}
 
 		if p, ok := plans[planId]; ok {
-			return c.JSON(http.StatusOK, bindplan.ComposeDetail(*p))
+			detail := bindplan.ComposeDetail(*p)
+			hookAfter(c, planHook, apiwebhooks.PlanEvent{
+				Event: apiwebhooks.PlanResourcesChanged, Plan: &detail, Resources: req,
+			})
+			return c.JSON(http.StatusOK, detail)
 		} else {
 			return binderr.NotFound()
 		}
 	}
 }
 
-func PutPlanAnnotations(dbPlan kdbplan.PlanInterface, planIdParam string) echo.HandlerFunc {
+// PutPlanAnnotations returns a handler to change annotations of the Plan.
+//
+// planHook is called before and after the change as an "annotations" event.
+//
+// Changes making the Plan violate the policy are rejected with 403 Forbidden,
+// since rules may depend on annotations.
+func PutPlanAnnotations(dbPlan kdbplan.PlanInterface, planIdParam string, planHook hook.Hook[apiwebhooks.PlanEvent, struct{}], policy *admission.Policy) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 		planId := c.Param(planIdParam)
@@ -364,6 +764,24 @@ func PutPlanAnnotations(dbPlan kdbplan.PlanInterface, planIdParam string) echo.H
 			RemoveKey: req.RemoveKey,
 		}
 
+		current, err := currentPlan(ctx, dbPlan, planId)
+		if err != nil {
+			return err
+		}
+
+		changed := admission.FromPlan(current)
+		changed.Annotations = applyAnnotationDelta(current.Annotations, delta)
+		if err := admit(policy, changed); err != nil {
+			return err
+		}
+
+		if err := planHookBefore(
+			ctx, planHook, current,
+			apiwebhooks.PlanEvent{Event: apiwebhooks.PlanAnnotationsChanged, Annotations: req},
+		); err != nil {
+			return err
+		}
+
 		if err := dbPlan.UpdateAnnotations(ctx, planId, delta); err != nil {
 			if errors.Is(err, kerr.ErrMissing) {
 				return binderr.NotFound()
@@ -377,14 +795,39 @@ func PutPlanAnnotations(dbPlan kdbplan.PlanInterface, planIdParam string) echo.H
 		}
 
 		if p, ok := plans[planId]; ok {
-			return c.JSON(http.StatusOK, bindplan.ComposeDetail(*p))
+			detail := bindplan.ComposeDetail(*p)
+			hookAfter(c, planHook, apiwebhooks.PlanEvent{
+				Event: apiwebhooks.PlanAnnotationsChanged, Plan: &detail, Annotations: req,
+			})
+			return c.JSON(http.StatusOK, detail)
 		} else {
 			return binderr.NotFound()
 		}
 	}
 }
 
-func PutPlanServiceAccount(dbPlan kdbplan.PlanInterface, planIdParam string) echo.HandlerFunc {
+// applyAnnotationDelta returns annotations which the delta is applied to,
+// in the same way as the database does.
+func applyAnnotationDelta(annotations []domain.Annotation, delta domain.AnnotationDelta) []domain.Annotation {
+	result := slices.Filter(annotations, func(a domain.Annotation) bool {
+		if _, ok := slices.First(delta.RemoveKey, func(k string) bool { return k == a.Key }); ok {
+			return false
+		}
+		_, ok := slices.First(delta.Remove, func(r domain.Annotation) bool { return r == a })
+		return !ok
+	})
+	for _, a := range delta.Add {
+		if _, ok := slices.First(result, func(r domain.Annotation) bool { return r == a }); !ok {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// PutPlanServiceAccount returns a handler to set the service account of the Plan.
+//
+// Changes making the Plan violate the policy are rejected with 403 Forbidden.
+func PutPlanServiceAccount(dbPlan kdbplan.PlanInterface, planIdParam string, policy *admission.Policy) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 		planId := c.Param(planIdParam)
@@ -394,6 +837,18 @@ func PutPlanServiceAccount(dbPlan kdbplan.PlanInterface, planIdParam string) ech
 			return binderr.BadRequest("can not understand the request", err)
 		}
 
+		if policy != nil {
+			current, err := currentPlan(ctx, dbPlan, planId)
+			if err != nil {
+				return err
+			}
+			changed := admission.FromPlan(current)
+			changed.ServiceAccount = req.ServiceAccount
+			if err := admit(policy, changed); err != nil {
+				return err
+			}
+		}
+
 		if err := dbPlan.SetServiceAccount(ctx, planId, req.ServiceAccount); err != nil {
 			if errors.Is(err, kerr.ErrMissing) {
 				return binderr.NotFound()
@@ -414,11 +869,64 @@ func PutPlanServiceAccount(dbPlan kdbplan.PlanInterface, planIdParam string) ech
 	}
 }
 
-func DeletePlanServiceAccount(dbPlan kdbplan.PlanInterface, planIdParam string) echo.HandlerFunc {
+// PutPlanPriority returns a handler to change the priority and the concurrency limit of the Plan.
+func PutPlanPriority(dbPlan kdbplan.PlanInterface, planIdParam string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		planId := c.Param(planIdParam)
+
+		req := new(apiplans.PriorityChange)
+		if err := c.Bind(req); err != nil {
+			return binderr.BadRequest("can not understand the request", err)
+		}
+
+		change := domain.PriorityChange{
+			Priority:       req.Priority,
+			MaxConcurrency: req.MaxConcurrency,
+		}
+		if err := dbPlan.UpdatePriority(ctx, planId, change); err != nil {
+			if errors.Is(err, kerr.ErrMissing) {
+				return binderr.NotFound()
+			}
+			if errors.Is(err, domain.ErrInvalidPlan) {
+				return binderr.BadRequest(err.Error(), err)
+			}
+			return binderr.InternalServerError(err)
+		}
+
+		plans, err := dbPlan.Get(ctx, []string{planId})
+		if err != nil {
+			return binderr.InternalServerError(err)
+		}
+
+		if p, ok := plans[planId]; ok {
+			return c.JSON(http.StatusOK, bindplan.ComposeDetail(*p))
+		} else {
+			return binderr.NotFound()
+		}
+	}
+}
+
+// DeletePlanServiceAccount returns a handler to unset the service account of the Plan.
+//
+// Changes making the Plan violate the policy are rejected with 403 Forbidden.
+func DeletePlanServiceAccount(dbPlan kdbplan.PlanInterface, planIdParam string, policy *admission.Policy) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
 		planId := c.Param(planIdParam)
 
+		if policy != nil {
+			current, err := currentPlan(ctx, dbPlan, planId)
+			if err != nil {
+				return err
+			}
+			changed := admission.FromPlan(current)
+			changed.ServiceAccount = ""
+			if err := admit(policy, changed); err != nil {
+				return err
+			}
+		}
+
 		if err := dbPlan.UnsetServiceAccount(ctx, planId); err != nil {
 			if errors.Is(err, kerr.ErrMissing) {
 				return binderr.NotFound()
@@ -438,3 +946,25 @@ func DeletePlanServiceAccount(dbPlan kdbplan.PlanInterface, planIdParam string)
 		}
 	}
 }
+
+// DeletePlanHandler returns a handler to delete the Plan which has no Runs.
+func DeletePlanHandler(dbPlan kdbplan.PlanInterface, planIdParam string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		planId := c.Param(planIdParam)
+
+		if err := dbPlan.Delete(ctx, planId); err != nil {
+			if errors.Is(err, kerr.ErrMissing) {
+				return binderr.NotFound()
+			} else if errors.Is(err, domain.ErrPlanHasRuns) {
+				return binderr.Conflict(
+					"the plan has runs", binderr.WithError(err),
+					binderr.WithAdvice("deactivate the plan instead, or supersede it."),
+				)
+			}
+			return binderr.InternalServerError(err)
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
@@ -10,16 +10,22 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	plans "github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab-api-types/runs"
 	apitag "github.com/opst/knitfab-api-types/tags"
+	apiwebhooks "github.com/opst/knitfab-api-types/webhooks"
 	handlers "github.com/opst/knitfab/cmd/knitd/handlers"
 	httptestutil "github.com/opst/knitfab/internal/testutils/http"
+	"github.com/opst/knitfab/pkg/admission"
 	bindplans "github.com/opst/knitfab/pkg/api-types-binding/plans"
+	cfg_admission "github.com/opst/knitfab/pkg/configs/admission"
 	"github.com/opst/knitfab/pkg/domain"
 	kerr "github.com/opst/knitfab/pkg/domain/errors"
 	mockdb "github.com/opst/knitfab/pkg/domain/plan/db/mock"
+	"github.com/opst/knitfab/pkg/hook"
 	"github.com/opst/knitfab/pkg/utils/cmp"
 	"github.com/opst/knitfab/pkg/utils/logic"
 	"github.com/opst/knitfab/pkg/utils/slices"
@@ -105,7 +111,23 @@ func TestRegisterPlan(t *testing.T) {
 	"annotations": [
 		"annot1=val1",
 		"annot2=val2"
-	]
+	],
+	"retry": {"max_attempts": 3, "backoff": "30s", "on": ["failed"], "exit_codes": [137]},
+	"max_duration": "1h30m",
+	"env": [
+		{"name": "MODE", "value": "train"},
+		{"name": "API_TOKEN", "value_from": {"secret_key_ref": {"name": "api", "key": "token"}}},
+		{"name": "ENDPOINT", "value_from": {"config_map_key_ref": {"name": "api", "key": "endpoint"}}}
+	],
+	"resource_requests": {"cpu": "500m"},
+	"tolerations": [{"key": "nvidia.com/gpu", "operator": "Exists", "effect": "NoSchedule"}],
+	"priority_class_name": "high-priority",
+	"runtime_class_name": "nvidia",
+	"scratch": [
+		{"path": "/cache", "medium": "Memory"},
+		{"path": "/tmp", "size_limit": "10Gi"}
+	],
+	"shm_size": "2Gi"
 }`,
 				},
 				registerResult{
@@ -132,6 +154,30 @@ func TestRegisterPlan(t *testing.T) {
 								{Key: "annot1", Value: "val1"},
 								{Key: "annot2", Value: "val2"},
 							},
+							Retry: &domain.RetryPolicy{
+								MaxAttempts: 3, Backoff: 30 * time.Second,
+								On:        []domain.ExitCause{domain.ExitByFailure},
+								ExitCodes: []uint8{137},
+							},
+							MaxDuration: 90 * time.Minute,
+							Env: []domain.EnvVar{
+								{Name: "MODE", Value: "train"},
+								{Name: "API_TOKEN", Secret: &domain.EnvVarKeyRef{Name: "api", Key: "token"}},
+								{Name: "ENDPOINT", ConfigMap: &domain.EnvVarKeyRef{Name: "api", Key: "endpoint"}},
+							},
+							ResourceRequests: map[string]resource.Quantity{
+								"cpu": resource.MustParse("500m"),
+							},
+							Tolerations: []domain.Toleration{
+								{Key: "nvidia.com/gpu", Operator: domain.TolerationOpExists, Effect: domain.TaintEffectNoSchedule},
+							},
+							PriorityClassName: "high-priority",
+							RuntimeClassName:  "nvidia",
+							Scratches: []domain.ScratchVolume{
+								{Path: "/cache", InMemory: true},
+								{Path: "/tmp", SizeLimit: resource.MustParse("10Gi")},
+							},
+							ShmSize: resource.MustParse("2Gi"),
 						},
 						Inputs: []domain.Input{
 							{
@@ -211,6 +257,30 @@ func TestRegisterPlan(t *testing.T) {
 							{Key: "annot1", Value: "val1"},
 							{Key: "annot2", Value: "val2"},
 						},
+						Retry: &domain.RetryPolicy{
+							MaxAttempts: 3, Backoff: 30 * time.Second,
+							On:        []domain.ExitCause{domain.ExitByFailure},
+							ExitCodes: []uint8{137},
+						},
+						MaxDuration: 90 * time.Minute,
+						Env: []domain.EnvVar{
+							{Name: "MODE", Value: "train"},
+							{Name: "API_TOKEN", Secret: &domain.EnvVarKeyRef{Name: "api", Key: "token"}},
+							{Name: "ENDPOINT", ConfigMap: &domain.EnvVarKeyRef{Name: "api", Key: "endpoint"}},
+						},
+						ResourceRequests: map[string]resource.Quantity{
+							"cpu": resource.MustParse("500m"),
+						},
+						Tolerations: []domain.Toleration{
+							{Key: "nvidia.com/gpu", Operator: domain.TolerationOpExists, Effect: domain.TaintEffectNoSchedule},
+						},
+						PriorityClassName: "high-priority",
+						RuntimeClassName:  "nvidia",
+						Scratches: []domain.ScratchVolume{
+							{Path: "/cache", InMemory: true},
+							{Path: "/tmp", SizeLimit: resource.MustParse("10Gi")},
+						},
+						ShmSize: resource.MustParse("2Gi"),
 					},
 				}),
 				Success: &resultSuccess{
@@ -277,6 +347,40 @@ func TestRegisterPlan(t *testing.T) {
 							"memory": resource.MustParse("1Gi"),
 						},
 						ServiceAccount: "example-service-account",
+						Retry: &plans.RetryPolicy{
+							MaxAttempts: 3, Backoff: "30s",
+							On:        []string{"failed"},
+							ExitCodes: []int{137},
+						},
+						MaxDuration: "1h30m0s",
+						Env: []plans.EnvVar{
+							{Name: "MODE", Value: plans.RedactedValue},
+							{
+								Name: "API_TOKEN",
+								ValueFrom: &plans.EnvVarSource{
+									SecretKeyRef: &plans.KeyRef{Name: "api", Key: "token"},
+								},
+							},
+							{
+								Name: "ENDPOINT",
+								ValueFrom: &plans.EnvVarSource{
+									ConfigMapKeyRef: &plans.KeyRef{Name: "api", Key: "endpoint"},
+								},
+							},
+						},
+						ResourceRequests: map[string]resource.Quantity{
+							"cpu": resource.MustParse("500m"),
+						},
+						Tolerations: []plans.Toleration{
+							{Key: "nvidia.com/gpu", Operator: "Exists", Effect: "NoSchedule"},
+						},
+						PriorityClassName: "high-priority",
+						RuntimeClassName:  "nvidia",
+						Scratch: []plans.ScratchVolume{
+							{Path: "/cache", Medium: "Memory"},
+							{Path: "/tmp", SizeLimit: "10Gi"},
+						},
+						ShmSize: "2Gi",
 					},
 				},
 			},
@@ -939,7 +1043,7 @@ func TestRegisterPlan(t *testing.T) {
 					return map[string]*domain.Plan{plan.PlanId: plan}, nil
 				}
 
-				testee := handlers.PlanRegisterHandler(mockPlan)
+				testee := handlers.PlanRegisterHandler(mockPlan, hook.None[apiwebhooks.PlanEvent]{}, nil)
 
 				e := echo.New()
 				c, respRec := httptestutil.Post(
@@ -1019,7 +1123,7 @@ func TestRegisterPlan(t *testing.T) {
 			mockPlan.Impl.Register = func(ctx context.Context, ps *domain.PlanSpec) (string, error) {
 				return "", when
 			}
-			testee := handlers.PlanRegisterHandler(mockPlan)
+			testee := handlers.PlanRegisterHandler(mockPlan, hook.None[apiwebhooks.PlanEvent]{}, nil)
 
 			e := echo.New()
 			c, _ := httptestutil.Post(
@@ -1131,6 +1235,169 @@ func TestRegisterPlan(t *testing.T) {
 	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
 	"outputs": [{"path": "./out", "tags": ["type:training data"]}],
 	"log": { "tags": ["type:log", "knit#id: something"] }
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has retry policy with bad backoff": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"retry": {"max_attempts": 3, "backoff": "a while"}
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has retry policy with out-of-range exit code": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"retry": {"max_attempts": 3, "exit_codes": [256]}
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has retry policy with unknown cause": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"retry": {"max_attempts": 3, "on": ["oom"]}
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has retry policy without attempts": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"retry": {"backoff": "10s"}
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has malformed max duration": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"max_duration": "forever"
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has negative max duration": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"max_duration": "-1h"
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has max duration with sub-seconds": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"max_duration": "1.5s"
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has resource requests exceeding limits": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"resources": {"cpu": "1"},
+	"resource_requests": {"cpu": "2"}
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has toleration with unknown operator": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"tolerations": [{"key": "gpu", "operator": "Gt", "value": "1"}]
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has malformed runtime class name": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"runtime_class_name": "Not_A_Name"
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has scratch overlapping with output": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"scratch": [{"path": "/out/tmp"}]
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has scratch with unknown medium": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"scratch": [{"path": "/tmp", "medium": "HugePages"}]
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has malformed shm size": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"shm_size": "two gigs"
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has env with malformed name": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"env": [{"name": "1ST", "value": "x"}]
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has duplicated env": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"env": [{"name": "MODE", "value": "x"}, {"name": "MODE", "value": "y"}]
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has env with both of value and reference": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"env": [{"name": "TOKEN", "value": "x", "value_from": {"secret_key_ref": {"name": "api", "key": "token"}}}]
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has env with empty reference": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"env": [{"name": "TOKEN", "value_from": {}}]
+}`,
+			then: http.StatusBadRequest,
+		},
+		"has env referring a secret without key": {
+			when: `{
+	"image": "repo.invalid/image-1:0.1.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out", "tags": ["type:training data"]}],
+	"env": [{"name": "TOKEN", "value_from": {"secret_key_ref": {"name": "api"}}}]
 }`,
 			then: http.StatusBadRequest,
 		},
@@ -1147,7 +1414,7 @@ func TestRegisterPlan(t *testing.T) {
 			mockPlan.Impl.Register = func(ctx context.Context, ps *domain.PlanSpec) (string, error) {
 				return "", errors.New("should not be reached")
 			}
-			testee := handlers.PlanRegisterHandler(mockPlan)
+			testee := handlers.PlanRegisterHandler(mockPlan, hook.None[apiwebhooks.PlanEvent]{}, nil)
 
 			e := echo.New()
 			c, _ := httptestutil.Post(
@@ -1451,9 +1718,9 @@ func TestFind(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 
 			mockPlan := mockdb.NewPlanInteraface()
-			mockPlan.Impl.Find = func(ctx context.Context, active logic.Ternary, imageVer domain.ImageIdentifier, inTag []domain.Tag, outTag []domain.Tag) ([]string, error) {
+			mockPlan.Impl.Find = func(ctx context.Context, active logic.Ternary, imageVer domain.ImageIdentifier, inTag []domain.Tag, outTag []domain.Tag, page domain.Page) ([]string, string, error) {
 				planIds := slices.Map(testcase.when.queryResult, func(p *domain.Plan) string { return p.PlanId })
-				return planIds, testcase.when.err
+				return planIds, "", testcase.when.err
 			}
 
 			mockPlan.Impl.Get = func(ctx context.Context, s []string) (map[string]*domain.Plan, error) {
@@ -1900,7 +2167,7 @@ func TestActivatePlan(t *testing.T) {
 			c.SetParamNames("planId")
 			c.SetParamValues(testcase.when.planId)
 
-			testee := handlers.PutPlanForActivate(mockPlan, testcase.when.isActive)
+			testee := handlers.PutPlanForActivate(mockPlan, testcase.when.isActive, hook.None[apiwebhooks.PlanEvent]{})
 			err := testee(c)
 
 			if testcase.then.shouldError {
@@ -1968,6 +2235,8 @@ func TestPutPlanResource(t *testing.T) {
 
 		setResource   map[string]resource.Quantity
 		unsetResource []string
+		setRequests   map[string]resource.Quantity
+		unsetRequests []string
 
 		shouldError bool
 		statusCode  int
@@ -1999,6 +2268,18 @@ func TestPutPlanResource(t *testing.T) {
 				}
 				return when.unsetResourceLimitError
 			}
+			mockPlan.Impl.SetResourceRequest = func(ctx context.Context, planId string, requests map[string]resource.Quantity) error {
+				if !cmp.MapEqWith(requests, then.setRequests, resource.Quantity.Equal) {
+					t.Errorf("unmatch: requests: (actual, expected) = (%v, %v)", requests, then.setRequests)
+				}
+				return nil
+			}
+			mockPlan.Impl.UnsetResourceRequest = func(ctx context.Context, planId string, resourceTypes []string) error {
+				if !cmp.SliceEq(resourceTypes, then.unsetRequests) {
+					t.Errorf("unmatch: request types: (actual, expected) = (%v, %v)", resourceTypes, then.unsetRequests)
+				}
+				return nil
+			}
 
 			e := echo.New()
 			c, respRec := httptestutil.Put(
@@ -2008,7 +2289,7 @@ func TestPutPlanResource(t *testing.T) {
 			c.SetParamNames("planId")
 			c.SetParamValues(when.planId)
 
-			testee := handlers.PutPlanResource(mockPlan, "planId")
+			testee := handlers.PutPlanResource(mockPlan, "planId", hook.None[apiwebhooks.PlanEvent]{}, nil)
 			err := testee(c)
 
 			if then.shouldError {
@@ -2168,6 +2449,49 @@ func TestPutPlanResource(t *testing.T) {
 		},
 	))
 
+	t.Run("When request has SetRequests and UnsetRequests, it should set and unset resource requests of the plan", theory(
+		When{
+			planId: "plan-1",
+			request: string(try.To(
+				json.Marshal(plans.ResourceLimitChange{
+					SetRequests:   map[string]resource.Quantity{"cpu": resource.MustParse("50m")},
+					UnsetRequests: []string{"memory"},
+				}),
+			).OrFatal(t)),
+			contentType: "application/json",
+
+			queryResult: []*domain.Plan{&planResult},
+		},
+		Then{
+			contentType:   "application/json",
+			setResource:   map[string]resource.Quantity{},
+			setRequests:   map[string]resource.Quantity{"cpu": resource.MustParse("50m")},
+			unsetRequests: []string{"memory"},
+			shouldError:   false,
+			statusCode:    http.StatusOK,
+			body:          bindplans.ComposeDetail(planResult),
+		},
+	))
+
+	t.Run("When requests would exceed limits, it should return 400 bad request error", theory(
+		When{
+			planId: "plan-1",
+			request: string(try.To(
+				json.Marshal(plans.ResourceLimitChange{
+					SetRequests: map[string]resource.Quantity{"cpu": resource.MustParse("200m")},
+				}),
+			).OrFatal(t)),
+			contentType: "application/json",
+
+			queryResult: []*domain.Plan{&planResult},
+		},
+		Then{
+			contentType: "application/json",
+			shouldError: true,
+			statusCode:  http.StatusBadRequest,
+		},
+	))
+
 	t.Run("When SetResource return Missing, it should return 404 not found error", theory(
 		When{
 			planId: "plan-1",
@@ -2373,7 +2697,7 @@ func TestPutAnnotations(t *testing.T) {
 			c.SetParamNames("planId")
 			c.SetParamValues(when.planId)
 
-			testee := handlers.PutPlanAnnotations(mockPlan, "planId")
+			testee := handlers.PutPlanAnnotations(mockPlan, "planId", hook.None[apiwebhooks.PlanEvent]{}, nil)
 			err := testee(c)
 
 			if err != nil {
@@ -2548,8 +2872,10 @@ func TestPutAnnotations(t *testing.T) {
 					Remove: plans.Annotations{{Key: "annot-2", Value: "val-2"}},
 				}),
 			).OrFatal(t)),
-			contentType:            "application/json",
-			queryResult:            map[string]*domain.Plan{},
+			contentType: "application/json",
+			queryResult: map[string]*domain.Plan{
+				"plan-1": {PlanBody: domain.PlanBody{PlanId: "plan-1", Hash: "hash-1", Active: true}},
+			},
 			updateAnnotationsError: errors.New("dummy error"),
 		},
 		Then{
@@ -2631,7 +2957,7 @@ func TestPutPlanServiceAccount(t *testing.T) {
 			c.SetParamNames("planId")
 			c.SetParamValues(when.planId)
 
-			testee := handlers.PutPlanServiceAccount(mockPlan, "planId")
+			testee := handlers.PutPlanServiceAccount(mockPlan, "planId", nil)
 			err := testee(c)
 
 			if err != nil {
@@ -2843,7 +3169,7 @@ func TestDeletePlanServiceAccount(t *testing.T) {
 			c.SetParamNames("planId")
 			c.SetParamValues(when.planId)
 
-			testee := handlers.DeletePlanServiceAccount(mockPlan, "planId")
+			testee := handlers.DeletePlanServiceAccount(mockPlan, "planId", nil)
 			err := testee(c)
 
 			if err != nil {
@@ -2991,3 +3317,610 @@ func TestDeletePlanServiceAccount(t *testing.T) {
 		},
 	))
 }
+
+func TestPlanSupersede(t *testing.T) {
+	planJson := `{
+	"image": "repo.invalid/image-1:0.2.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out/2", "tags": ["type:training data"]}],
+	"log": {"tags": ["type:log"]}
+}`
+
+	t.Run("it registers the plan as the successor", func(t *testing.T) {
+		mockPlan := mockdb.NewPlanInteraface()
+		mockPlan.Impl.Supersede = func(ctx context.Context, predecessor string, spec *domain.PlanSpec, reroute bool) (string, error) {
+			return "plan-2", nil
+		}
+		mockPlan.Impl.Get = func(ctx context.Context, planId []string) (map[string]*domain.Plan, error) {
+			return map[string]*domain.Plan{
+				"plan-2": {
+					PlanBody: domain.PlanBody{
+						PlanId: "plan-2", Active: true, Hash: "hash-2",
+						Image:      &domain.ImageIdentifier{Image: "repo.invalid/image-1", Version: "0.2.0"},
+						Supersedes: "plan-1",
+					},
+				},
+			}, nil
+		}
+
+		e := echo.New()
+		c, resprec := httptestutil.Post(
+			e, "/api/plans/:planId/successor?reroute=true", bytes.NewBufferString(planJson),
+			httptestutil.WithHeader("content-type", "application/json"),
+		)
+		c.SetParamNames("planId")
+		c.SetParamValues("plan-1")
+
+		if err := handlers.PlanSupersedeHandler(mockPlan, "planId", hook.None[apiwebhooks.PlanEvent]{}, nil)(c); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := mockPlan.Calls.Supersede; len(got) != 1 {
+			t.Fatalf("Supersede is called %d times", len(got))
+		} else if got[0].Predecessor != "plan-1" || !got[0].Reroute {
+			t.Errorf("unexpected args: %+v", got[0])
+		}
+
+		actual := plans.Detail{}
+		if err := json.Unmarshal(resprec.Body.Bytes(), &actual); err != nil {
+			t.Fatal(err)
+		}
+		if actual.PlanId != "plan-2" || actual.Supersedes != "plan-1" {
+			t.Errorf("unexpected response: %+v", actual)
+		}
+	})
+
+	for name, testcase := range map[string]struct {
+		query string
+		err   error
+		then  int
+	}{
+		"predecessor is missing": {
+			err: kerr.ErrMissing, then: http.StatusNotFound,
+		},
+		"predecessor is superseded already": {
+			err: domain.ErrPlanSuperseded, then: http.StatusConflict,
+		},
+		"successor conflicts with others": {
+			err: domain.ErrConflictingPlan, then: http.StatusConflict,
+		},
+		"reroute is not boolean": {
+			query: "?reroute=maybe", then: http.StatusBadRequest,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockPlan := mockdb.NewPlanInteraface()
+			mockPlan.Impl.Supersede = func(ctx context.Context, predecessor string, spec *domain.PlanSpec, reroute bool) (string, error) {
+				return "", testcase.err
+			}
+
+			e := echo.New()
+			c, _ := httptestutil.Post(
+				e, "/api/plans/:planId/successor"+testcase.query, bytes.NewBufferString(planJson),
+				httptestutil.WithHeader("content-type", "application/json"),
+			)
+			c.SetParamNames("planId")
+			c.SetParamValues("plan-1")
+
+			err := handlers.PlanSupersedeHandler(mockPlan, "planId", hook.None[apiwebhooks.PlanEvent]{}, nil)(c)
+			if got := statusOf(t, err); got != testcase.then {
+				t.Errorf("status code: got %d, want %d", got, testcase.then)
+			}
+		})
+	}
+}
+
+func TestDeletePlan(t *testing.T) {
+	for name, testcase := range map[string]struct {
+		err  error
+		then int
+	}{
+		"plan is deleted": {
+			then: http.StatusNoContent,
+		},
+		"plan is missing": {
+			err: kerr.ErrMissing, then: http.StatusNotFound,
+		},
+		"plan has runs": {
+			err: domain.ErrPlanHasRuns, then: http.StatusConflict,
+		},
+		"unexpected error": {
+			err: errors.New("fake error"), then: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockPlan := mockdb.NewPlanInteraface()
+			mockPlan.Impl.Delete = func(ctx context.Context, planId string) error {
+				return testcase.err
+			}
+
+			e := echo.New()
+			c, resprec := httptestutil.Delete(e, "/api/plans/:planId")
+			c.SetParamNames("planId")
+			c.SetParamValues("plan-1")
+
+			err := handlers.DeletePlanHandler(mockPlan, "planId")(c)
+
+			if got := mockPlan.Calls.Delete; len(got) != 1 || got[0] != "plan-1" {
+				t.Errorf("Delete is called with %v", got)
+			}
+			if testcase.err == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resprec.Code != testcase.then {
+					t.Errorf("status code: got %d, want %d", resprec.Code, testcase.then)
+				}
+				return
+			}
+			if got := statusOf(t, err); got != testcase.then {
+				t.Errorf("status code: got %d, want %d", got, testcase.then)
+			}
+		})
+	}
+}
+
+func TestPlanPreview(t *testing.T) {
+	planJson := `{
+	"image": "repo.invalid/image-1:0.2.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out/2", "tags": ["type:training data"]}],
+	"log": {"tags": ["type:log"]}
+}`
+
+	t.Run("it responses runs which would be created", func(t *testing.T) {
+		mockPlan := mockdb.NewPlanInteraface()
+		mockPlan.Impl.Preview = func(ctx context.Context, spec *domain.PlanSpec) ([]domain.RunPreview, error) {
+			return []domain.RunPreview{
+				{
+					Plan: domain.PlanBody{
+						PlanId: "plan-tmp", Active: true, Hash: "hash-2",
+						Image: &domain.ImageIdentifier{Image: "repo.invalid/image-1", Version: "0.2.0"},
+					},
+					Inputs: []domain.Assignment{
+						{
+							MountPoint: domain.MountPoint{
+								Id: 1, Path: "/in/1",
+								Tags: domain.NewTagSet([]domain.Tag{{Key: "type", Value: "raw data"}}),
+							},
+							KnitDataBody: domain.KnitDataBody{KnitId: "knit-1"},
+						},
+					},
+				},
+			}, nil
+		}
+
+		e := echo.New()
+		c, resprec := httptestutil.Post(
+			e, "/api/plans/preview", bytes.NewBufferString(planJson),
+			httptestutil.WithHeader("content-type", "application/json"),
+		)
+
+		if err := handlers.PlanPreviewHandler(mockPlan)(c); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := mockPlan.Calls.Preview; len(got) != 1 {
+			t.Fatalf("Preview is called %d times", len(got))
+		} else if got[0].Image() != "repo.invalid/image-1" {
+			t.Errorf("unexpected spec: %+v", got[0])
+		}
+
+		actual := []runs.Preview{}
+		if err := json.Unmarshal(resprec.Body.Bytes(), &actual); err != nil {
+			t.Fatal(err)
+		}
+		expected := []runs.Preview{
+			{
+				Plan: plans.Summary{
+					PlanId: "plan-tmp",
+					Image:  &plans.Image{Repository: "repo.invalid/image-1", Tag: "0.2.0"},
+				},
+				Inputs: []runs.Assignment{
+					{
+						Mountpoint: plans.Mountpoint{
+							Path: "/in/1", Tags: []apitag.Tag{{Key: "type", Value: "raw data"}},
+						},
+						KnitId: "knit-1",
+					},
+				},
+			},
+		}
+		if !cmp.SliceContentEqWith(actual, expected, runs.Preview.Equal) {
+			t.Errorf("unexpected response:\n===actual===\n%+v\n===expected===\n%+v", actual, expected)
+		}
+	})
+
+	for name, testcase := range map[string]struct {
+		body string
+		err  error
+		then int
+	}{
+		"plan is invalid": {
+			body: `{"image": "repo.invalid/image-1:0.2.0", "inputs": [], "outputs": []}`,
+			then: http.StatusBadRequest,
+		},
+		"plan conflicts with others": {
+			body: planJson, err: domain.ErrConflictingPlan, then: http.StatusConflict,
+		},
+		"unexpected error": {
+			body: planJson, err: errors.New("fake error"), then: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockPlan := mockdb.NewPlanInteraface()
+			mockPlan.Impl.Preview = func(ctx context.Context, spec *domain.PlanSpec) ([]domain.RunPreview, error) {
+				return nil, testcase.err
+			}
+
+			e := echo.New()
+			c, _ := httptestutil.Post(
+				e, "/api/plans/preview", bytes.NewBufferString(testcase.body),
+				httptestutil.WithHeader("content-type", "application/json"),
+			)
+
+			err := handlers.PlanPreviewHandler(mockPlan)(c)
+			if got := statusOf(t, err); got != testcase.then {
+				t.Errorf("status code: got %d, want %d", got, testcase.then)
+			}
+		})
+	}
+}
+
+func TestPutPlanPriority(t *testing.T) {
+	priority := 10
+	maxConcurrency := 2
+
+	for name, testcase := range map[string]struct {
+		err  error
+		then int
+	}{
+		"priority is updated": {
+			then: http.StatusOK,
+		},
+		"plan is missing": {
+			err: kerr.ErrMissing, then: http.StatusNotFound,
+		},
+		"change is invalid": {
+			err: domain.ErrInvalidMaxConcurrency, then: http.StatusBadRequest,
+		},
+		"unexpected error": {
+			err: errors.New("fake error"), then: http.StatusInternalServerError,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			plan := &domain.Plan{
+				PlanBody: domain.PlanBody{
+					PlanId: "plan-1", Active: true, Hash: "hash-1",
+					Image:          &domain.ImageIdentifier{Image: "image-1", Version: "ver-1"},
+					Priority:       priority,
+					MaxConcurrency: maxConcurrency,
+				},
+			}
+
+			mockPlan := mockdb.NewPlanInteraface()
+			mockPlan.Impl.UpdatePriority = func(ctx context.Context, planId string, change domain.PriorityChange) error {
+				return testcase.err
+			}
+			mockPlan.Impl.Get = func(ctx context.Context, planId []string) (map[string]*domain.Plan, error) {
+				return map[string]*domain.Plan{"plan-1": plan}, nil
+			}
+
+			e := echo.New()
+			payload := plans.PriorityChange{Priority: &priority, MaxConcurrency: &maxConcurrency}
+			c, resprec := httptestutil.Put(
+				e, "/api/plans/:planId/priority",
+				bytes.NewReader(try.To(json.Marshal(payload)).OrFatal(t)),
+				httptestutil.WithHeader("content-type", "application/json"),
+			)
+			c.SetParamNames("planId")
+			c.SetParamValues("plan-1")
+
+			err := handlers.PutPlanPriority(mockPlan, "planId")(c)
+
+			if got := mockPlan.Calls.UpdatePriority; len(got) != 1 {
+				t.Fatalf("UpdatePriority is called %d times", len(got))
+			} else if ch := got[0].Change; got[0].PlanId != "plan-1" ||
+				ch.Priority == nil || *ch.Priority != priority ||
+				ch.MaxConcurrency == nil || *ch.MaxConcurrency != maxConcurrency {
+				t.Errorf("UpdatePriority is called with %+v", got[0])
+			}
+
+			if testcase.err != nil {
+				if got := statusOf(t, err); got != testcase.then {
+					t.Errorf("status code: got %d, want %d", got, testcase.then)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resprec.Code != testcase.then {
+				t.Errorf("status code: got %d, want %d", resprec.Code, testcase.then)
+			}
+			got := plans.Detail{}
+			if err := json.Unmarshal(resprec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if want := bindplans.ComposeDetail(*plan); !got.Equal(want) {
+				t.Errorf("response: got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestPlanRegisterHandler_Hook(t *testing.T) {
+	planJson := `{
+	"image": "repo.invalid/image-1:0.2.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out/2", "tags": ["type:training data"]}],
+	"log": {"tags": ["type:log"]}
+}`
+
+	t.Run("it calls hooks before and after registration", func(t *testing.T) {
+		mockPlan := mockdb.NewPlanInteraface()
+		mockPlan.Impl.Register = func(ctx context.Context, spec *domain.PlanSpec) (string, error) {
+			return "plan-1", nil
+		}
+		mockPlan.Impl.Get = func(ctx context.Context, planId []string) (map[string]*domain.Plan, error) {
+			return map[string]*domain.Plan{
+				"plan-1": {
+					PlanBody: domain.PlanBody{
+						PlanId: "plan-1", Active: true, Hash: "hash-1",
+						Image: &domain.ImageIdentifier{Image: "repo.invalid/image-1", Version: "0.2.0"},
+					},
+				},
+			}, nil
+		}
+
+		events := []string{}
+		planHook := hook.Func[apiwebhooks.PlanEvent, struct{}]{
+			BeforeFn: func(ev apiwebhooks.PlanEvent) (struct{}, error) {
+				events = append(events, "before")
+				if len(mockPlan.Calls.Register) != 0 {
+					t.Error("before hook is called after registration")
+				}
+				if ev.Event != apiwebhooks.PlanRegistered || ev.Plan != nil || ev.Spec == nil || ev.Spec.Image.Repository != "repo.invalid/image-1" {
+					t.Errorf("unexpected event for before: %+v", ev)
+				}
+				return struct{}{}, nil
+			},
+			AfterFn: func(ev apiwebhooks.PlanEvent) error {
+				events = append(events, "after")
+				if ev.Event != apiwebhooks.PlanRegistered || ev.Plan == nil || ev.Plan.PlanId != "plan-1" || ev.Spec == nil {
+					t.Errorf("unexpected event for after: %+v", ev)
+				}
+				return nil
+			},
+		}
+
+		e := echo.New()
+		c, _ := httptestutil.Post(
+			e, "/api/plans", bytes.NewBufferString(planJson),
+			httptestutil.WithHeader("content-type", "application/json"),
+		)
+		if err := handlers.PlanRegisterHandler(mockPlan, planHook, nil)(c); err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.SliceEq(events, []string{"before", "after"}) {
+			t.Errorf("hook calls: %v", events)
+		}
+	})
+
+	for name, testcase := range map[string]struct {
+		err  error
+		then int
+	}{
+		"when the hook rejects, it responses Forbidden": {
+			err: hook.ErrHookRejected, then: http.StatusForbidden,
+		},
+		"when the hook fails, it responses Service Unavailable": {
+			err: errors.New("fake error"), then: http.StatusServiceUnavailable,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockPlan := mockdb.NewPlanInteraface()
+
+			e := echo.New()
+			c, _ := httptestutil.Post(
+				e, "/api/plans", bytes.NewBufferString(planJson),
+				httptestutil.WithHeader("content-type", "application/json"),
+			)
+			err := handlers.PlanRegisterHandler(
+				mockPlan,
+				hook.Func[apiwebhooks.PlanEvent, struct{}]{
+					BeforeFn: func(apiwebhooks.PlanEvent) (struct{}, error) { return struct{}{}, testcase.err },
+				},
+				nil,
+			)(c)
+
+			var echoErr *echo.HTTPError
+			if !errors.As(err, &echoErr) {
+				t.Fatalf("error is not echo.HTTPError. actual = %+v", err)
+			}
+			if echoErr.Code != testcase.then {
+				t.Errorf("unexpected status code: %d", echoErr.Code)
+			}
+			if len(mockPlan.Calls.Register) != 0 {
+				t.Error("the plan should not be registered")
+			}
+		})
+	}
+}
+
+func TestPlanHandlers_Admission(t *testing.T) {
+	policy := try.To(admission.New(cfg_admission.Config{
+		Rules: []cfg_admission.Rule{
+			{
+				Name:       "trusted-registry",
+				Expression: `plan.image.startsWith("registry.internal/")`,
+				Message:    "images must come from registry.internal",
+			},
+			{
+				Name: "memory-limit",
+				Expression: `!has(plan.resources.memory) || plan.resources.memory <= quantity("64Gi") ||
+					plan.annotations.exists(a, a.key == "team" && a.value == "research")`,
+			},
+			{
+				Name:       "service-account",
+				Expression: `plan.service_account in ["", "trainer"]`,
+			},
+		},
+	})).OrFatal(t)
+
+	registered := func(image string, annotations ...domain.Annotation) *domain.Plan {
+		return &domain.Plan{
+			PlanBody: domain.PlanBody{
+				PlanId: "plan-1", Active: true, Hash: "hash-1",
+				Image: &domain.ImageIdentifier{Image: image, Version: "v1"},
+				Resources: map[string]resource.Quantity{
+					"cpu": resource.MustParse("1"), "memory": resource.MustParse("1Gi"),
+				},
+				Annotations: annotations,
+			},
+		}
+	}
+
+	assertForbidden := func(t *testing.T, err error) {
+		t.Helper()
+		var echoErr *echo.HTTPError
+		if !errors.As(err, &echoErr) {
+			t.Fatalf("error is not echo.HTTPError. actual = %+v", err)
+		}
+		if echoErr.Code != http.StatusForbidden {
+			t.Errorf("unexpected status code: %d", echoErr.Code)
+		}
+	}
+
+	t.Run("PlanRegisterHandler rejects a Plan violating the policy", func(t *testing.T) {
+		mockPlan := mockdb.NewPlanInteraface()
+
+		e := echo.New()
+		c, _ := httptestutil.Post(
+			e, "/api/plans", bytes.NewBufferString(`{
+	"image": "docker.io/image-1:0.2.0", "active": true,
+	"inputs": [{"path": "/in/1", "tags": ["type:raw data"]}],
+	"outputs": [{"path": "/out/2", "tags": ["type:training data"]}]
+}`),
+			httptestutil.WithHeader("content-type", "application/json"),
+		)
+		err := handlers.PlanRegisterHandler(mockPlan, hook.None[apiwebhooks.PlanEvent]{}, policy)(c)
+		assertForbidden(t, err)
+		if !strings.Contains(fmt.Sprint(err), "images must come from registry.internal") {
+			t.Errorf("error should tell the violation: %s", err)
+		}
+		if len(mockPlan.Calls.Register) != 0 {
+			t.Error("the plan should not be registered")
+		}
+	})
+
+	for name, testcase := range map[string]struct {
+		current *domain.Plan
+		set     map[string]resource.Quantity
+		admit   bool
+	}{
+		"PutPlanResource rejects a change violating the policy": {
+			current: registered("registry.internal/image"),
+			set:     map[string]resource.Quantity{"memory": resource.MustParse("128Gi")},
+			admit:   false,
+		},
+		"PutPlanResource admits a change for the annotated Plan": {
+			current: registered(
+				"registry.internal/image", domain.Annotation{Key: "team", Value: "research"},
+			),
+			set:   map[string]resource.Quantity{"memory": resource.MustParse("128Gi")},
+			admit: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockPlan := mockdb.NewPlanInteraface()
+			mockPlan.Impl.Get = func(ctx context.Context, planId []string) (map[string]*domain.Plan, error) {
+				return map[string]*domain.Plan{"plan-1": testcase.current}, nil
+			}
+			updated := false
+			mockPlan.Impl.SetResourceLimit = func(ctx context.Context, planId string, rlimits map[string]resource.Quantity) error {
+				updated = true
+				return nil
+			}
+			mockPlan.Impl.UnsetResourceLimit = func(ctx context.Context, planId string, types []string) error {
+				return nil
+			}
+
+			e := echo.New()
+			payload := plans.ResourceLimitChange{Set: testcase.set}
+			c, _ := httptestutil.Put(
+				e, "/api/plans/:planId/resources",
+				bytes.NewReader(try.To(json.Marshal(payload)).OrFatal(t)),
+				httptestutil.WithHeader("content-type", "application/json"),
+			)
+			c.SetParamNames("planId")
+			c.SetParamValues("plan-1")
+
+			err := handlers.PutPlanResource(mockPlan, "planId", hook.None[apiwebhooks.PlanEvent]{}, policy)(c)
+			if testcase.admit {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !updated {
+					t.Error("resources should be updated")
+				}
+				return
+			}
+			assertForbidden(t, err)
+			if updated {
+				t.Error("resources should not be updated")
+			}
+		})
+	}
+
+	t.Run("PutPlanAnnotations rejects removing an annotation which the Plan needs", func(t *testing.T) {
+		current := registered(
+			"registry.internal/image", domain.Annotation{Key: "team", Value: "research"},
+		)
+		current.Resources = map[string]resource.Quantity{"memory": resource.MustParse("128Gi")}
+
+		mockPlan := mockdb.NewPlanInteraface()
+		mockPlan.Impl.Get = func(ctx context.Context, planId []string) (map[string]*domain.Plan, error) {
+			return map[string]*domain.Plan{"plan-1": current}, nil
+		}
+
+		e := echo.New()
+		payload := plans.AnnotationChange{RemoveKey: []string{"team"}}
+		c, _ := httptestutil.Put(
+			e, "/api/plans/:planId/annotations",
+			bytes.NewReader(try.To(json.Marshal(payload)).OrFatal(t)),
+			httptestutil.WithHeader("content-type", "application/json"),
+		)
+		c.SetParamNames("planId")
+		c.SetParamValues("plan-1")
+
+		err := handlers.PutPlanAnnotations(mockPlan, "planId", hook.None[apiwebhooks.PlanEvent]{}, policy)(c)
+		assertForbidden(t, err)
+		if len(mockPlan.Calls.UpdateAnnotations) != 0 {
+			t.Error("annotations should not be updated")
+		}
+	})
+
+	t.Run("PutPlanServiceAccount rejects a service account out of the allow-list", func(t *testing.T) {
+		mockPlan := mockdb.NewPlanInteraface()
+		mockPlan.Impl.Get = func(ctx context.Context, planId []string) (map[string]*domain.Plan, error) {
+			return map[string]*domain.Plan{"plan-1": registered("registry.internal/image")}, nil
+		}
+
+		e := echo.New()
+		payload := plans.SetServiceAccount{ServiceAccount: "admin"}
+		c, _ := httptestutil.Put(
+			e, "/api/plans/:planId/serviceaccount",
+			bytes.NewReader(try.To(json.Marshal(payload)).OrFatal(t)),
+			httptestutil.WithHeader("content-type", "application/json"),
+		)
+		c.SetParamNames("planId")
+		c.SetParamValues("plan-1")
+
+		err := handlers.PutPlanServiceAccount(mockPlan, "planId", policy)(c)
+		assertForbidden(t, err)
+		if len(mockPlan.Calls.SetServiceAccount) != 0 {
+			t.Error("service account should not be updated")
+		}
+	})
+}
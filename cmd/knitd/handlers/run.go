@@ -16,6 +16,10 @@ import (
 	kstrings "github.com/opst/knitfab/pkg/utils/strings"
 )
 
+// FindRunHandler returns a handler to find Runs.
+//
+// Found Runs are paginated with query parameters "limit", "cursor" and "order" (see queryParamToPage).
+// When there are more Runs, the "Link" header points the next page.
 func FindRunHandler(dbRun kdbrun.Interface) echo.HandlerFunc {
 
 	return func(c echo.Context) error {
@@ -42,6 +46,17 @@ func FindRunHandler(dbRun kdbrun.Interface) echo.HandlerFunc {
 				result.Status = append(result.Status, s)
 			}
 
+			for _, p := range kstrings.SplitIfNotEmpty(c.QueryParam("cause"), ",") {
+				cause, err := domain.AsExitCause(p)
+				if err != nil {
+					return domain.RunFindQuery{}, binderr.BadRequest(
+						`"cause" should be one of "failed", "stucking" or "timeout"`,
+						nil,
+					)
+				}
+				result.ExitCause = append(result.ExitCause, cause)
+			}
+
 			since := c.QueryParam("since")
 			if since != "" {
 				t, err := rfctime.ParseRFC3339DateTime(since)
@@ -71,15 +86,20 @@ func FindRunHandler(dbRun kdbrun.Interface) echo.HandlerFunc {
 			return result, nil
 		}(c)
 
+		if err != nil {
+			return err
+		}
+		page, err := queryParamToPage(c)
 		if err != nil {
 			return err
 		}
 		ctx := c.Request().Context()
 
-		runIds, err := dbRun.Find(ctx, query)
+		runIds, next, err := dbRun.Find(ctx, query, page)
 		if err != nil {
-			return binderr.InternalServerError(err)
+			return pageError(err)
 		}
+		setNextLink(c, next)
 
 		result, err := dbRun.Get(ctx, runIds)
 		if err != nil {
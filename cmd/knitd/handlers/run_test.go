@@ -410,6 +410,19 @@ func TestRunFindHandler(t *testing.T) {
 					body: []apiruns.Detail{},
 				},
 			},
+			"when it is queried about exit cause": {
+				when{
+					request: "/api/runs?cause=timeout,stucking",
+					Runs:    []domain.Run{},
+				},
+				then{
+					query: domain.RunFindQuery{
+						Status:    []domain.KnitRunStatus{},
+						ExitCause: []domain.ExitCause{domain.ExitByTimeout, domain.ExitByStucking},
+					},
+					body: []apiruns.Detail{},
+				},
+			},
 			"when it is queried about since": {
 				when{
 					request: "/api/runs?since=2024-04-01T12%3A00%3A00%2B00%3A00",
@@ -538,9 +551,9 @@ func TestRunFindHandler(t *testing.T) {
 
 				mockRun := mockdb.NewRunInterface()
 
-				mockRun.Impl.Find = func(ctx context.Context, q domain.RunFindQuery) ([]string, error) {
+				mockRun.Impl.Find = func(ctx context.Context, q domain.RunFindQuery, page domain.Page) ([]string, string, error) {
 					runIds := slices.Map(testcase.when.Runs, func(r domain.Run) string { return r.Id })
-					return runIds, nil
+					return runIds, "", nil
 				}
 				mockRun.Impl.Get = func(ctx context.Context, runId []string) (map[string]domain.Run, error) {
 					runs := slices.ToMap(testcase.when.Runs, func(r domain.Run) string { return r.Id })
@@ -558,7 +571,12 @@ func TestRunFindHandler(t *testing.T) {
 
 				if !cmp.SliceEqWith(
 					mockRun.Calls.Find, []domain.RunFindQuery{testcase.query},
-					domain.RunFindQuery.Equal,
+					func(a struct {
+						Query domain.RunFindQuery
+						Page  domain.Page
+					}, b domain.RunFindQuery) bool {
+						return a.Query.Equal(b) && a.Page == domain.Page{}
+					},
 				) {
 					t.Errorf(
 						"unmatch: params for RunInterface.Find:\n- actual:\n%+v\n- expected:\n%+v",
@@ -653,6 +671,14 @@ func TestRunFindHandler(t *testing.T) {
 					statusCode: http.StatusBadRequest,
 				},
 			},
+			"(Bad Request) when causes in query is unknown value": {
+				when{
+					request: "/api/runs?cause=timeout,oom",
+				},
+				then{
+					statusCode: http.StatusBadRequest,
+				},
+			},
 			"(Bad Request) when statuses in query is invalidated": {
 				when{
 					request: "/api/runs?status=" + strings.ToLower(string(domain.Invalidated)), // this is known value, but...
@@ -666,8 +692,8 @@ func TestRunFindHandler(t *testing.T) {
 
 				mockRun := mockdb.NewRunInterface()
 
-				mockRun.Impl.Find = func(ctx context.Context, q domain.RunFindQuery) ([]string, error) {
-					return nil, testcase.when.errorOnFind
+				mockRun.Impl.Find = func(ctx context.Context, q domain.RunFindQuery, page domain.Page) ([]string, string, error) {
+					return nil, "", testcase.when.errorOnFind
 				}
 				mockRun.Impl.Get = func(ctx context.Context, runId []string) (map[string]domain.Run, error) {
 					return nil, testcase.when.errorOnGet
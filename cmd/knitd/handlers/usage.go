@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	apiusage "github.com/opst/knitfab-api-types/usage"
+	binderr "github.com/opst/knitfab/pkg/api-types-binding/errors"
+	bindplan "github.com/opst/knitfab/pkg/api-types-binding/plans"
+	bindtags "github.com/opst/knitfab/pkg/api-types-binding/tags"
+	"github.com/opst/knitfab/pkg/domain"
+	kdbdata "github.com/opst/knitfab/pkg/domain/data/db"
+	kdbplan "github.com/opst/knitfab/pkg/domain/plan/db"
+	"github.com/opst/knitfab/pkg/utils/slices"
+)
+
+// GetUsageHandler returns a handler to aggregate storage usage of Data.
+//
+// Query parameters are:
+//
+// - by: "plan", "tag" or "time". Required.
+//
+// - key: Tag key to aggregate by, when by=tag. Optional; all Tags are used if empty.
+//
+// - interval: "day", "week" or "month", when by=time. Default is "day".
+func GetUsageHandler(dbData kdbdata.DataInterface, dbPlan kdbplan.PlanInterface) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+
+		by, err := domain.AsUsageGroup(c.QueryParam("by"))
+		if err != nil {
+			return binderr.BadRequest(
+				`query parameter "by" should be one of "plan", "tag" or "time"`, err,
+			)
+		}
+		query := domain.UsageQuery{By: by}
+		switch by {
+		case domain.UsageByTag:
+			query.TagKey = c.QueryParam("key")
+		case domain.UsageByTime:
+			interval := c.QueryParam("interval")
+			if interval == "" {
+				interval = string(domain.UsageDaily)
+			}
+			i, err := domain.AsUsageInterval(interval)
+			if err != nil {
+				return binderr.BadRequest(
+					`query parameter "interval" should be one of "day", "week" or "month"`, err,
+				)
+			}
+			query.Interval = i
+		}
+
+		usages, err := dbData.Usage(ctx, query)
+		if err != nil {
+			return binderr.InternalServerError(err)
+		}
+
+		plans := map[string]*domain.Plan{}
+		if by == domain.UsageByPlan && 0 < len(usages) {
+			plans, err = dbPlan.Get(ctx, slices.Map(usages, func(u domain.Usage) string { return u.PlanId }))
+			if err != nil {
+				return binderr.InternalServerError(err)
+			}
+		}
+
+		resp := make([]apiusage.Usage, 0, len(usages))
+		for _, u := range usages {
+			r := apiusage.Usage{
+				Bytes: u.Size.Bytes,
+				Files: u.Size.Files,
+				Data:  u.Data,
+			}
+			if u.PlanId != "" {
+				p, ok := plans[u.PlanId]
+				if !ok {
+					return binderr.InternalServerError(fmt.Errorf("plan %s is not found", u.PlanId))
+				}
+				summary := bindplan.ComposeSummary(p.PlanBody)
+				r.Plan = &summary
+			}
+			if u.Tag != nil {
+				t := bindtags.Compose(*u.Tag)
+				r.Tag = &t
+			}
+			if u.Since != nil {
+				since := rfctime.RFC3339(*u.Since)
+				r.Since = &since
+			}
+			resp = append(resp, r)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,156 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab-api-types/tags"
+	apiusage "github.com/opst/knitfab-api-types/usage"
+	handlers "github.com/opst/knitfab/cmd/knitd/handlers"
+	httptestutil "github.com/opst/knitfab/internal/testutils/http"
+	"github.com/opst/knitfab/pkg/domain"
+	dbdatamock "github.com/opst/knitfab/pkg/domain/data/db/mock"
+	dbplanmock "github.com/opst/knitfab/pkg/domain/plan/db/mock"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/pointer"
+)
+
+func TestGetUsageHandler(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, testcase := range map[string]struct {
+		query     string
+		wantQuery domain.UsageQuery
+		usages    []domain.Usage
+		want      []apiusage.Usage
+	}{
+		"by plan": {
+			query:     "?by=plan",
+			wantQuery: domain.UsageQuery{By: domain.UsageByPlan},
+			usages: []domain.Usage{
+				{PlanId: "plan-1", Size: domain.DataSize{Bytes: 2048, Files: 3}, Data: 2},
+				{PlanId: "plan-2", Size: domain.DataSize{Bytes: 1024, Files: 1}, Data: 1},
+			},
+			want: []apiusage.Usage{
+				{
+					Plan: &plans.Summary{
+						PlanId: "plan-1",
+						Image:  &plans.Image{Repository: "repo.invalid/train", Tag: "v1"},
+					},
+					Bytes: 2048, Files: 3, Data: 2,
+				},
+				{
+					Plan:  &plans.Summary{PlanId: "plan-2", Name: "knit#uploaded"},
+					Bytes: 1024, Files: 1, Data: 1,
+				},
+			},
+		},
+		"by tag": {
+			query:     "?by=tag&key=project",
+			wantQuery: domain.UsageQuery{By: domain.UsageByTag, TagKey: "project"},
+			usages: []domain.Usage{
+				{Tag: &domain.Tag{Key: "project", Value: "a"}, Size: domain.DataSize{Bytes: 300, Files: 3}, Data: 3},
+			},
+			want: []apiusage.Usage{
+				{Tag: &tags.Tag{Key: "project", Value: "a"}, Bytes: 300, Files: 3, Data: 3},
+			},
+		},
+		"by time, daily by default": {
+			query:     "?by=time",
+			wantQuery: domain.UsageQuery{By: domain.UsageByTime, Interval: domain.UsageDaily},
+			usages: []domain.Usage{
+				{Since: &since, Size: domain.DataSize{Bytes: 100, Files: 10}, Data: 1},
+			},
+			want: []apiusage.Usage{
+				{Since: pointer.Ref(rfctime.RFC3339(since)), Bytes: 100, Files: 10, Data: 1},
+			},
+		},
+		"by time, monthly": {
+			query:     "?by=time&interval=month",
+			wantQuery: domain.UsageQuery{By: domain.UsageByTime, Interval: domain.UsageMonthly},
+			usages:    []domain.Usage{},
+			want:      []apiusage.Usage{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dbdata := dbdatamock.NewDataInterface()
+			dbdata.Impl.Usage = func(ctx context.Context, query domain.UsageQuery) ([]domain.Usage, error) {
+				return testcase.usages, nil
+			}
+			dbplan := dbplanmock.NewPlanInteraface()
+			dbplan.Impl.Get = func(ctx context.Context, planIds []string) (map[string]*domain.Plan, error) {
+				return map[string]*domain.Plan{
+					"plan-1": {PlanBody: domain.PlanBody{
+						PlanId: "plan-1",
+						Image:  &domain.ImageIdentifier{Image: "repo.invalid/train", Version: "v1"},
+					}},
+					"plan-2": {PlanBody: domain.PlanBody{
+						PlanId: "plan-2",
+						Pseudo: &domain.PseudoPlanDetail{Name: domain.Uploaded},
+					}},
+				}, nil
+			}
+
+			e := echo.New()
+			c, resprec := httptestutil.Get(e, "/api/usage"+testcase.query)
+
+			if err := handlers.GetUsageHandler(dbdata, dbplan)(c); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := dbdata.Calls.Usage; len(got) != 1 || got[0] != testcase.wantQuery {
+				t.Errorf("unexpected Usage calls: %+v", got)
+			}
+
+			actual := []apiusage.Usage{}
+			if err := json.Unmarshal(resprec.Body.Bytes(), &actual); err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.SliceEqWith(actual, testcase.want, apiusage.Usage.Equal) {
+				t.Errorf("unexpected response:\n===actual===\n%+v\n===expected===\n%+v", actual, testcase.want)
+			}
+		})
+	}
+
+	for name, testcase := range map[string]struct {
+		query string
+		err   error
+		then  int
+	}{
+		"by is missing": {
+			query: "", then: http.StatusBadRequest,
+		},
+		"by is unknown": {
+			query: "?by=user", then: http.StatusBadRequest,
+		},
+		"interval is unknown": {
+			query: "?by=time&interval=year", then: http.StatusBadRequest,
+		},
+		"database causes an error": {
+			query: "?by=tag", err: errors.New("fake error"), then: http.StatusInternalServerError,
+		},
+	} {
+		t.Run("it responses error when "+name, func(t *testing.T) {
+			dbdata := dbdatamock.NewDataInterface()
+			dbdata.Impl.Usage = func(ctx context.Context, query domain.UsageQuery) ([]domain.Usage, error) {
+				return nil, testcase.err
+			}
+			dbplan := dbplanmock.NewPlanInteraface()
+
+			e := echo.New()
+			c, _ := httptestutil.Get(e, "/api/usage"+testcase.query)
+
+			err := handlers.GetUsageHandler(dbdata, dbplan)(c)
+			if got := statusOf(t, err); got != testcase.then {
+				t.Errorf("status code: got %d, want %d", got, testcase.then)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	apiwebhooks "github.com/opst/knitfab-api-types/webhooks"
+	binderr "github.com/opst/knitfab/pkg/api-types-binding/errors"
+	bindwebhooks "github.com/opst/knitfab/pkg/api-types-binding/webhooks"
+	"github.com/opst/knitfab/pkg/domain"
+	kdbwebhook "github.com/opst/knitfab/pkg/domain/webhook/db"
+	kstrings "github.com/opst/knitfab/pkg/utils/strings"
+)
+
+// FindWebhookDeliveriesHandler returns a handler to find webhook deliveries in the outbox.
+//
+// Query parameters (optional):
+//
+// - status: comma separated statuses. "pending" or "failed". Default is "failed".
+func FindWebhookDeliveriesHandler(dbwebhook kdbwebhook.WebhookInterface) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		status := []domain.WebhookDeliveryStatus{}
+		for _, s := range kstrings.SplitIfNotEmpty(c.QueryParam("status"), ",") {
+			switch st := domain.WebhookDeliveryStatus(s); st {
+			case domain.WebhookDeliveryPending, domain.WebhookDeliveryFailed:
+				status = append(status, st)
+			default:
+				return binderr.BadRequest(`"status" should be one of "pending" or "failed"`, nil)
+			}
+		}
+		if len(status) == 0 {
+			status = []domain.WebhookDeliveryStatus{domain.WebhookDeliveryFailed}
+		}
+
+		deliveries, err := dbwebhook.Find(c.Request().Context(), status)
+		if err != nil {
+			return binderr.InternalServerError(err)
+		}
+
+		resp := make([]apiwebhooks.Delivery, 0, len(deliveries))
+		for _, d := range deliveries {
+			resp = append(resp, bindwebhooks.Compose(d))
+		}
+		return c.JSON(http.StatusOK, resp)
+	}
+}
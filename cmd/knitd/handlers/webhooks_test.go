@@ -0,0 +1,108 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	apiwebhooks "github.com/opst/knitfab-api-types/webhooks"
+	handlers "github.com/opst/knitfab/cmd/knitd/handlers"
+	httptestutil "github.com/opst/knitfab/internal/testutils/http"
+	"github.com/opst/knitfab/pkg/domain"
+	mockdb "github.com/opst/knitfab/pkg/domain/webhook/db/mock"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestFindWebhookDeliveriesHandler(t *testing.T) {
+	createdAt := try.To(rfctime.ParseRFC3339DateTime("2024-01-02T03:04:05+00:00")).OrFatal(t).Time()
+
+	deliveries := []domain.WebhookDelivery{
+		{
+			Id:            3,
+			Hook:          "lifecycle-hooks",
+			URL:           "http://example.com/hook",
+			Payload:       []byte(`{"runId": "run-1"}`),
+			Status:        domain.WebhookDeliveryFailed,
+			Attempts:      5,
+			NextAttemptAt: createdAt.Add(time.Hour),
+			LastError:     "hook failed (http://example.com/hook 503)",
+			CreatedAt:     createdAt,
+			UpdatedAt:     createdAt.Add(time.Hour),
+		},
+	}
+
+	for name, testcase := range map[string]struct {
+		request string
+		status  []domain.WebhookDeliveryStatus
+	}{
+		"failed deliveries by default": {
+			request: "/api/webhooks/deliveries",
+			status:  []domain.WebhookDeliveryStatus{domain.WebhookDeliveryFailed},
+		},
+		"deliveries with specified status": {
+			request: "/api/webhooks/deliveries?status=pending,failed",
+			status:  []domain.WebhookDeliveryStatus{domain.WebhookDeliveryPending, domain.WebhookDeliveryFailed},
+		},
+	} {
+		t.Run("it responses "+name, func(t *testing.T) {
+			dbwebhook := mockdb.NewWebhookInterface()
+			dbwebhook.Impl.Find = func(ctx context.Context, status []domain.WebhookDeliveryStatus) ([]domain.WebhookDelivery, error) {
+				return deliveries, nil
+			}
+
+			e := echo.New()
+			c, resp := httptestutil.Get(e, testcase.request)
+			if err := handlers.FindWebhookDeliveriesHandler(dbwebhook)(c); err != nil {
+				t.Fatal(err)
+			}
+
+			if dbwebhook.Calls.Find.Times() != 1 {
+				t.Fatalf("Find is called %d times", dbwebhook.Calls.Find.Times())
+			}
+			if !cmp.SliceEq(dbwebhook.Calls.Find[0], testcase.status) {
+				t.Errorf("status: %v", dbwebhook.Calls.Find[0])
+			}
+
+			if resp.Code != http.StatusOK {
+				t.Errorf("status code: %d", resp.Code)
+			}
+			got := []apiwebhooks.Delivery{}
+			if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("unexpected response: %+v", got)
+			}
+			d := got[0]
+			if d.Id != 3 ||
+				d.Hook != "lifecycle-hooks" ||
+				d.URL != "http://example.com/hook" ||
+				d.Status != "failed" ||
+				d.Attempts != 5 ||
+				d.LastError != "hook failed (http://example.com/hook 503)" ||
+				!d.CreatedAt.Equal(rfctime.RFC3339(createdAt)) ||
+				!d.UpdatedAt.Equal(rfctime.RFC3339(createdAt.Add(time.Hour))) ||
+				string(d.Payload) != `{"runId":"run-1"}` {
+				t.Errorf("unexpected response: %+v", d)
+			}
+		})
+	}
+
+	t.Run("it responses BadRequest for unknown status", func(t *testing.T) {
+		dbwebhook := mockdb.NewWebhookInterface()
+		e := echo.New()
+		c, _ := httptestutil.Get(e, "/api/webhooks/deliveries?status=delivered")
+		err := handlers.FindWebhookDeliveriesHandler(dbwebhook)(c)
+		if code := statusOf(t, err); code != http.StatusBadRequest {
+			t.Errorf("unexpected status code: %d", code)
+		}
+		if dbwebhook.Calls.Find.Times() != 0 {
+			t.Errorf("Find is called")
+		}
+	})
+}
@@ -4,6 +4,7 @@ package main
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"flag"
 	"log"
 	"net/url"
@@ -15,9 +16,17 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	apiwebhooks "github.com/opst/knitfab-api-types/webhooks"
+	"github.com/opst/knitfab/pkg/admission"
+	cfg_admission "github.com/opst/knitfab/pkg/configs/admission"
 	kcx "github.com/opst/knitfab/pkg/configs/extras"
 	kcf "github.com/opst/knitfab/pkg/configs/frontend"
+	cfg_hook "github.com/opst/knitfab/pkg/configs/hook"
+	"github.com/opst/knitfab/pkg/domain"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
 	kpg "github.com/opst/knitfab/pkg/domain/knitfab/db/postgres"
+	kdbuser "github.com/opst/knitfab/pkg/domain/user/db"
+	"github.com/opst/knitfab/pkg/hook"
 	"github.com/opst/knitfab/pkg/utils/echoutil"
 	"github.com/opst/knitfab/pkg/utils/filewatch"
 	kstrings "github.com/opst/knitfab/pkg/utils/strings"
@@ -33,6 +42,8 @@ func main() {
 	configPath := flag.String("config-path", "", "frontend config path")
 	extraConfigPath := flag.String("extra-apis-config", "", "path to extra api config file")
 	schemaRepo := flag.String("schema-repo", os.Getenv("KNIT_SCHEMA"), "schema repository path")
+	phooks := flag.String("hooks", os.Getenv("KNIT_HOOK_CONFIG"), "path to hook config file")
+	padmission := flag.String("admission-policy", os.Getenv("KNIT_ADMISSION_POLICY"), "path to admission policy file for Plans")
 	loglevel := flag.String("loglevel", "info", "log level. debug|info|warn|error|off")
 	pcert := flag.String("cert", "", "certification file for TLS")
 	pkey := flag.String("certkey", "", "key of certification file for TLS")
@@ -124,6 +135,57 @@ func main() {
 		ctx = ctx_
 	}
 
+	hooks := cfg_hook.Config{}
+	if *phooks != "" {
+		hooks, err = cfg_hook.Load(*phooks)
+		if err != nil {
+			log.Fatalf("can not read hook configration: %s", err)
+		}
+	}
+
+	var policy *admission.Policy
+	if *padmission != "" {
+		cfg, err := cfg_admission.Load(*padmission)
+		if err != nil {
+			log.Fatalf("can not read admission policy: %s", err)
+		}
+		policy, err = admission.New(cfg)
+		if err != nil {
+			log.Fatalf("admission policy is invalid: %s", err)
+		}
+	}
+	outbox := func(ctx context.Context, hook string, urls []string, payload []byte) error {
+		return db.Webhook().Enqueue(ctx, hook, urls, payload)
+	}
+	merge := func(a, b struct{}) struct{} { return struct{}{} }
+	dataHook := hook.BuildWeb[apiwebhooks.DataEvent](hooks.Data, cfg_hook.DataHooks, merge, outbox)
+	planHook := hook.BuildWeb[apiwebhooks.PlanEvent](hooks.Plan, cfg_hook.PlanHooks, merge, outbox)
+
+	authn := handlers.Unauthenticated()
+	if conf.Auth.Enabled {
+		if adm := conf.Auth.Admin; adm != nil {
+			if err := ensureAdmin(ctx, db.User(), *adm); err != nil {
+				log.Fatalf("can not register initial admin: %s", err)
+			}
+		}
+		authn = handlers.Authenticate(db.User())
+
+		e.POST(api("auth/token"), handlers.IssueTokenHandler(db.User(), conf.Auth.TokenTTL))
+		e.POST(
+			api("auth/token/refresh"),
+			handlers.RefreshTokenHandler(db.User(), conf.Auth.TokenTTL),
+			authn,
+		)
+		e.POST(
+			api("users"),
+			handlers.RegisterUserHandler(db.User()),
+			authn, handlers.RequireRole(domain.RoleAdmin),
+		)
+	}
+	viewer := []echo.MiddlewareFunc{authn, handlers.RequireRole(domain.RoleViewer)}
+	planAuthor := []echo.MiddlewareFunc{authn, handlers.RequireRole(domain.RolePlanAuthor)}
+	admin := []echo.MiddlewareFunc{authn, handlers.RequireRole(domain.RoleAdmin)}
+
 	// handlers
 	{
 		knitid := "knitid"
@@ -135,39 +197,50 @@ func main() {
 		e.GET(
 			api("data"),
 			handlers.GetDataForDataHandler(db.Data()),
+			viewer...,
 		)
-		e.POST(api("data"), proxy)
+		e.POST(api("data"), proxy, planAuthor...)
 
-		e.GET(api("data/:knitid/"), proxy)
-		e.PUT(api("data/:knitid/"), handlers.PutTagForDataHandler(db.Data(), knitid))
+		e.GET(api("data/:knitid/"), proxy, viewer...)
+		e.PUT(api("data/:knitid/"), handlers.PutTagForDataHandler(db.Data(), knitid, dataHook), planAuthor...)
+		e.POST(api("data/:knitid/preview"), handlers.PreviewTagForDataHandler(db.Data(), knitid), planAuthor...)
+		e.DELETE(api("data/:knitid/"), handlers.DeleteDataHandler(db.Data(), db.Run(), knitid), admin...)
+
+		e.GET(api("lineage/:knitid"), handlers.GetLineageHandler(db.Data(), db.Run(), knitid), viewer...)
+		e.GET(api("usage"), handlers.GetUsageHandler(db.Data(), db.Plan()), viewer...)
 	}
 
 	{
 		e.GET(
 			api("plans"),
 			handlers.FindPlanHandler(db.Plan()),
+			viewer...,
 		)
-		e.POST(api("plans"), handlers.PlanRegisterHandler(db.Plan()))
-
-		e.GET(api("plans/:planId/"), handlers.GetPlanHandler(db.Plan()))
-
-		e.PUT(api("plans/:planId/active"), handlers.PutPlanForActivate(db.Plan(), true))
-		e.DELETE(api("plans/:planId/active"), handlers.PutPlanForActivate(db.Plan(), false))
-		e.PUT(api("plans/:planId/resources"), handlers.PutPlanResource(db.Plan(), "planId"))
-		e.PUT(api("plans/:planId/annotations"), handlers.PutPlanAnnotations(db.Plan(), "planId"))
-		e.PUT(api("plans/:planId/serviceaccount"), handlers.PutPlanServiceAccount(db.Plan(), "planId"))
-		e.DELETE(api("plans/:planId/serviceaccount"), handlers.DeletePlanServiceAccount(db.Plan(), "planId"))
+		e.POST(api("plans"), handlers.PlanRegisterHandler(db.Plan(), planHook, policy), planAuthor...)
+		e.POST(api("plans/preview"), handlers.PlanPreviewHandler(db.Plan()), planAuthor...)
+
+		e.GET(api("plans/:planId/"), handlers.GetPlanHandler(db.Plan()), viewer...)
+		e.DELETE(api("plans/:planId/"), handlers.DeletePlanHandler(db.Plan(), "planId"), admin...)
+		e.POST(api("plans/:planId/successor"), handlers.PlanSupersedeHandler(db.Plan(), "planId", planHook, policy), planAuthor...)
+
+		e.PUT(api("plans/:planId/active"), handlers.PutPlanForActivate(db.Plan(), true, planHook), planAuthor...)
+		e.DELETE(api("plans/:planId/active"), handlers.PutPlanForActivate(db.Plan(), false, planHook), planAuthor...)
+		e.PUT(api("plans/:planId/resources"), handlers.PutPlanResource(db.Plan(), "planId", planHook, policy), planAuthor...)
+		e.PUT(api("plans/:planId/annotations"), handlers.PutPlanAnnotations(db.Plan(), "planId", planHook, policy), planAuthor...)
+		e.PUT(api("plans/:planId/priority"), handlers.PutPlanPriority(db.Plan(), "planId"), planAuthor...)
+		e.PUT(api("plans/:planId/serviceaccount"), handlers.PutPlanServiceAccount(db.Plan(), "planId", policy), planAuthor...)
+		e.DELETE(api("plans/:planId/serviceaccount"), handlers.DeletePlanServiceAccount(db.Plan(), "planId", policy), planAuthor...)
 	}
 
 	{
 		runId := "runid"
-		e.GET(api("runs"), handlers.FindRunHandler(db.Run()))
-		e.GET(api("runs/:runId/"), handlers.GetRunHandler(db.Run()))
-		e.PUT(api("runs/:runId/abort"), handlers.AbortRunHandler(db.Run(), "runId"))
-		e.PUT(api("runs/:runId/tearoff"), handlers.TearoffRunHandler(db.Run(), "runId"))
-		e.PUT(api("runs/:runId/retry"), handlers.RetryRunHandler(db.Run(), "runId"))
+		e.GET(api("runs"), handlers.FindRunHandler(db.Run()), viewer...)
+		e.GET(api("runs/:runId/"), handlers.GetRunHandler(db.Run()), viewer...)
+		e.PUT(api("runs/:runId/abort"), handlers.AbortRunHandler(db.Run(), "runId"), planAuthor...)
+		e.PUT(api("runs/:runId/tearoff"), handlers.TearoffRunHandler(db.Run(), "runId"), planAuthor...)
+		e.PUT(api("runs/:runId/retry"), handlers.RetryRunHandler(db.Run(), "runId"), planAuthor...)
 
-		e.DELETE(api("runs/:runId/"), handlers.DeleteRunHandler(db.Run()))
+		e.DELETE(api("runs/:runId/"), handlers.DeleteRunHandler(db.Run()), admin...)
 
 		e.GET(api("runs/:runid/log"), func(c echo.Context) error {
 			url := backendApi("runs", c.Param(runId), "log")
@@ -176,8 +249,12 @@ func main() {
 			}
 
 			return echoutil.Proxy(&c, url)
-		})
+		}, viewer...)
 	}
+
+	e.GET(api("audit"), handlers.FindAuditHandler(db.Audit()), viewer...)
+	e.GET(api("webhooks/deliveries"), handlers.FindWebhookDeliveriesHandler(db.Webhook()), admin...)
+
 	log.Println("registred routes:")
 	for _, r := range e.Routes() {
 		log.Println(r.Method, r.Path)
@@ -256,6 +333,36 @@ func main() {
 	}
 }
 
+// ensureAdmin registers the initial admin user, if there are no users with the email.
+func ensureAdmin(ctx context.Context, dbuser kdbuser.UserInterface, adm kcf.InitialAdmin) error {
+	if _, _, err := dbuser.Lookup(ctx, adm.Email); err == nil {
+		return nil
+	} else if !errors.Is(err, kerr.ErrMissing) {
+		return err
+	}
+
+	password, err := os.ReadFile(adm.PasswordFile)
+	if err != nil {
+		return err
+	}
+	cred, err := domain.NewPasswordCredential(strings.TrimSpace(string(password)))
+	if err != nil {
+		return err
+	}
+
+	name := adm.Name
+	if name == "" {
+		name = adm.Email
+	}
+	if _, err := dbuser.Register(
+		ctx, domain.User{Email: adm.Email, Name: name, Role: domain.RoleAdmin}, cred,
+	); err != nil && !errors.Is(err, domain.ErrUserExists) {
+		return err
+	}
+	log.Printf("initial admin %s is registered", adm.Email)
+	return nil
+}
+
 // create api URL factory
 //
 // args:
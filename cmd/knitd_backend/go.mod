@@ -4,6 +4,10 @@ go 1.24.0
 
 replace github.com/opst/knitfab => ../..
 
+// WebAPI types not released yet are developed in ../../knitfab-api-types,
+// until they are released as a new version of knitfab-api-types (see ../../knitfab-api-types/README.md).
+replace github.com/opst/knitfab-api-types => ../../knitfab-api-types
+
 require (
 	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/google/uuid v1.6.0
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +12,8 @@ import (
 	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	apidata "github.com/opst/knitfab-api-types/data"
+	apiwebhooks "github.com/opst/knitfab-api-types/webhooks"
 	keyprovider "github.com/opst/knitfab/cmd/knitd_backend/provider/keyProvider"
 	binddata "github.com/opst/knitfab/pkg/api-types-binding/data"
 	binderr "github.com/opst/knitfab/pkg/api-types-binding/errors"
@@ -21,13 +24,22 @@ import (
 	k8serrors "github.com/opst/knitfab/pkg/domain/errors/k8serrors"
 	keychain "github.com/opst/knitfab/pkg/domain/keychain/k8s"
 	kdbrun "github.com/opst/knitfab/pkg/domain/run/db"
+	"github.com/opst/knitfab/pkg/hook"
 	"github.com/opst/knitfab/pkg/utils/echoutil"
 )
 
+// PostDataHandler returns a handler to upload a new Data.
+//
+// dataHook is called as an "uploaded" event before the upload is committed,
+// and after that.
+//
+// The size and the checksum of the Data reported by dataagt are recorded.
+// If they are not reported, the Data is left to be measured later.
 func PostDataHandler(
 	dbData kdbdata.DataInterface,
 	dbRun kdbrun.Interface,
 	k8sData k8sdata.Interface,
+	dataHook hook.Hook[apiwebhooks.DataEvent, struct{}],
 ) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
@@ -93,8 +105,38 @@ func PostDataHandler(
 		defer bresp.Body.Close()
 
 		newStatus := domain.Aborting
+		var hookErr error
 		if 200 <= bresp.StatusCode && bresp.StatusCode < 300 {
 			newStatus = domain.Completing
+
+			// the Data is not committed yet. It has no downstreams.
+			data := domain.KnitData{
+				KnitDataBody: out[0].KnitDataBody,
+				Upsteram: domain.DataSource{
+					RunBody:    run.RunBody,
+					MountPoint: &out[0].MountPoint,
+				},
+			}
+
+			m := apidata.Measurement{}
+			if err := json.NewDecoder(bresp.Body).Decode(&m); err == nil {
+				s := domain.DataSize{Bytes: m.Bytes, Files: m.Files}
+				if err := dbData.SetSize(ctx, data.KnitId, s); err == nil {
+					data.Size = &s
+				}
+				if m.Checksum != "" {
+					if err := dbData.SetChecksum(ctx, data.KnitId, m.Checksum); err == nil {
+						data.Checksum = m.Checksum
+					}
+				}
+			}
+			if err := hookBefore(ctx, dataHook, apiwebhooks.DataEvent{
+				Event: apiwebhooks.DataUploaded,
+				Data:  binddata.ComposeDetail(data),
+			}); err != nil {
+				hookErr = err
+				newStatus = domain.Aborting
+			}
 		}
 		if err := dbRun.SetStatus(ctx, runId, newStatus); err != nil {
 			return binderr.InternalServerError(err)
@@ -104,6 +146,10 @@ func PostDataHandler(
 		}
 		finished = true
 
+		if hookErr != nil {
+			return hookErr
+		}
+
 		if newStatus != domain.Completing {
 			// proxy dataagt response.  -- fixme: check & reword error message.
 			echoutil.CopyResponse(&c, bresp)
@@ -119,9 +165,14 @@ func PostDataHandler(
 			return binderr.InternalServerError(errors.New(`uploaded data "%s" is lost`))
 		}
 
+		detail := binddata.ComposeDetail(data)
+		hookAfter(c, dataHook, apiwebhooks.DataEvent{
+			Event: apiwebhooks.DataUploaded,
+			Data:  detail,
+		})
 		return c.JSON(
 			http.StatusOK,
-			binddata.ComposeDetail(data),
+			detail,
 		)
 	}
 }
@@ -238,11 +289,16 @@ func ImportDataBeginHandler(
 	}
 }
 
+// ImportDataEndHandler returns a handler to finish importing a Data.
+//
+// dataHook is called as an "imported" event before the import is committed,
+// and after that. When the hook rejects the Data, the import is aborted.
 func ImportDataEndHandler(
 	k8sData k8sdata.Interface,
 	kp keyprovider.KeyProvider,
 	dbRun kdbrun.Interface,
 	dbData kdbdata.DataInterface,
+	dataHook hook.Hook[apiwebhooks.DataEvent, struct{}],
 ) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		req := c.Request()
@@ -299,6 +355,18 @@ func ImportDataEndHandler(
 		}
 
 		runId := claims.RunId
+		if _, err := dataHook.Before(ctx, apiwebhooks.DataEvent{
+			Event: apiwebhooks.DataImported,
+			Data:  binddata.ComposeDetail(data[knitId]),
+		}); err != nil {
+			if errors.Is(err, hook.ErrHookRejected) {
+				if err := dbRun.SetStatus(ctx, runId, domain.Aborting); err != nil {
+					c.Logger().Errorf("failed to abort importing %s: %s", knitId, err)
+				}
+			}
+			return hookError(err)
+		}
+
 		if err := dbRun.SetStatus(ctx, runId, domain.Completing); err != nil {
 			if errors.Is(err, domain.ErrInvalidRunStateChanging) {
 				return binderr.Conflict("", binderr.WithError(err))
@@ -309,7 +377,12 @@ func ImportDataEndHandler(
 			return binderr.InternalServerError(err)
 		}
 
-		return c.JSON(http.StatusOK, binddata.ComposeDetail(data[knitId]))
+		detail := binddata.ComposeDetail(data[knitId])
+		hookAfter(c, dataHook, apiwebhooks.DataEvent{
+			Event: apiwebhooks.DataImported,
+			Data:  detail,
+		})
+		return c.JSON(http.StatusOK, detail)
 	}
 }
 
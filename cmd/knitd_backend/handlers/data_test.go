@@ -20,6 +20,7 @@ import (
 	"github.com/opst/knitfab-api-types/plans"
 	"github.com/opst/knitfab-api-types/runs"
 	apitag "github.com/opst/knitfab-api-types/tags"
+	apiwebhooks "github.com/opst/knitfab-api-types/webhooks"
 	"github.com/opst/knitfab/cmd/knitd_backend/handlers"
 	mockkeyprovider "github.com/opst/knitfab/cmd/knitd_backend/provider/keyProvider/mockKeyprovider"
 	httptestutil "github.com/opst/knitfab/internal/testutils/http"
@@ -35,6 +36,7 @@ import (
 	"github.com/opst/knitfab/pkg/domain/keychain/k8s/key"
 	mockkeychain "github.com/opst/knitfab/pkg/domain/keychain/k8s/mock"
 	dbrunmock "github.com/opst/knitfab/pkg/domain/run/db/mock"
+	"github.com/opst/knitfab/pkg/hook"
 	"github.com/opst/knitfab/pkg/utils/cmp"
 	"github.com/opst/knitfab/pkg/utils/try"
 )
@@ -385,8 +387,14 @@ func TestPostDataHandler(t *testing.T) {
 		respFromDataAgt responseDescriptor
 	}
 
+	type then struct {
+		size     *domain.DataSize
+		checksum string
+	}
+
 	type testcase struct {
 		when when
+		then then
 	}
 
 	for name, testcase := range map[string]testcase{
@@ -408,6 +416,22 @@ func TestPostDataHandler(t *testing.T) {
 				},
 			},
 		},
+		"dataagt reports size": {
+			when: when{
+				knitId: "test-knit-id",
+				respFromDataAgt: responseDescriptor{
+					code: 200,
+					header: map[string][]string{
+						"Content-Type": {"application/json"},
+					},
+					body: []byte(`{"bytes": 1024, "files": 3, "checksum": "sha256:0123abcd"}`),
+				},
+			},
+			then: then{
+				size:     &domain.DataSize{Bytes: 1024, Files: 3},
+				checksum: "sha256:0123abcd",
+			},
+		},
 		"not chunked, no trailer": {
 			when: when{
 				knitId: "test-knit-id",
@@ -519,6 +543,12 @@ func TestPostDataHandler(t *testing.T) {
 			iDataDB.Impl.RemoveAgent = func(context.Context, string) error {
 				return nil
 			}
+			iDataDB.Impl.SetSize = func(context.Context, string, domain.DataSize) error {
+				return nil
+			}
+			iDataDB.Impl.SetChecksum = func(context.Context, string, string) error {
+				return nil
+			}
 
 			iDataK8s := k8sdatamocks.New(t)
 			spawnDataagtCalled := 0
@@ -568,7 +598,7 @@ func TestPostDataHandler(t *testing.T) {
 			)
 			ectx.SetPath("/api/backends/data/")
 
-			testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s)
+			testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s, hook.None[apiwebhooks.DataEvent]{})
 			if err := testee(ectx); err != nil {
 				t.Fatalf("testee returns error unexpectedly. %v", err)
 			}
@@ -666,6 +696,40 @@ func TestPostDataHandler(t *testing.T) {
 				t.Errorf("DataInterface.RemoveAgent has not been called")
 			}
 
+			if size := testcase.then.size; size == nil {
+				if iDataDB.Calls.SetSize.Times() != 0 {
+					t.Errorf("DataInterface.SetSize is called unexpectedly: %+v", iDataDB.Calls.SetSize)
+				}
+			} else {
+				expected := []struct {
+					KnitId string
+					Size   domain.DataSize
+				}{{KnitId: knitId, Size: *size}}
+				if !cmp.SliceEq(iDataDB.Calls.SetSize, expected) {
+					t.Errorf(
+						"DataInterface.SetSize:\n===actual===\n%+v\n===expected===\n%+v",
+						iDataDB.Calls.SetSize, expected,
+					)
+				}
+			}
+
+			if checksum := testcase.then.checksum; checksum == "" {
+				if iDataDB.Calls.SetChecksum.Times() != 0 {
+					t.Errorf("DataInterface.SetChecksum is called unexpectedly: %+v", iDataDB.Calls.SetChecksum)
+				}
+			} else {
+				expected := []struct {
+					KnitId   string
+					Checksum string
+				}{{KnitId: knitId, Checksum: checksum}}
+				if !cmp.SliceEq(iDataDB.Calls.SetChecksum, expected) {
+					t.Errorf(
+						"DataInterface.SetChecksum:\n===actual===\n%+v\n===expected===\n%+v",
+						iDataDB.Calls.SetChecksum, expected,
+					)
+				}
+			}
+
 			// --- about response ---
 			expectedResponsePayload := apidata.Detail{
 				KnitId: knitId,
@@ -836,7 +900,7 @@ func TestPostDataHandler(t *testing.T) {
 			)
 			ectx.SetPath("/api/backends/data/")
 
-			testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s)
+			testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s, hook.None[apiwebhooks.DataEvent]{})
 			if err := testee(ectx); err != nil {
 				t.Fatalf("testee returns error unexpectedly. %v", err)
 			}
@@ -965,7 +1029,7 @@ func TestPostDataHandler(t *testing.T) {
 			iDataK8s.Impl.SpawnDataAgent = func(context.Context, domain.DataAgent, time.Time) (dataagt.DataAgent, error) {
 				return nil, testcase.errorValue
 			}
-			testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s)
+			testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s, hook.None[apiwebhooks.DataEvent]{})
 
 			e := echo.New()
 			ectx, resprec := httptestutil.Post(e, "/api/backends/data/", bytes.NewBuffer([]byte("n/a")))
@@ -1079,7 +1143,7 @@ func TestPostDataHandler(t *testing.T) {
 		iDataK8s.Impl.SpawnDataAgent = func(context.Context, domain.DataAgent, time.Time) (dataagt.DataAgent, error) {
 			return dagt, nil
 		}
-		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s)
+		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s, hook.None[apiwebhooks.DataEvent]{})
 
 		e := echo.New()
 		ectx, resprec := httptestutil.Post(e, "/api/backends/data/", bytes.NewBuffer([]byte("n/a")))
@@ -1161,7 +1225,7 @@ func TestPostDataHandler(t *testing.T) {
 		iDataK8s.Impl.SpawnDataAgent = func(context.Context, domain.DataAgent, time.Time) (dataagt.DataAgent, error) {
 			return dagt, nil
 		}
-		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s)
+		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s, hook.None[apiwebhooks.DataEvent]{})
 
 		e := echo.New()
 		ectx, resprec := httptestutil.Post(e, "/api/backends/data/", bytes.NewBuffer([]byte("n/a")))
@@ -1227,7 +1291,7 @@ func TestPostDataHandler(t *testing.T) {
 		iDataK8s.Impl.SpawnDataAgent = func(context.Context, domain.DataAgent, time.Time) (dataagt.DataAgent, error) {
 			return dagt, nil
 		}
-		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s)
+		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s, hook.None[apiwebhooks.DataEvent]{})
 
 		e := echo.New()
 		ectx, resprec := httptestutil.Post(e, "/api/backends/data/", bytes.NewBuffer([]byte("n/a")))
@@ -1374,7 +1438,7 @@ func TestPostDataHandler(t *testing.T) {
 		iDataK8s.Impl.SpawnDataAgent = func(context.Context, domain.DataAgent, time.Time) (dataagt.DataAgent, error) {
 			return dagt, nil
 		}
-		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s)
+		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s, hook.None[apiwebhooks.DataEvent]{})
 
 		e := echo.New()
 		ectx, resprec := httptestutil.Post(e, "/api/backends/data/", bytes.NewBuffer([]byte("n/a")))
@@ -1511,7 +1575,7 @@ func TestPostDataHandler(t *testing.T) {
 		iDataK8s.Impl.SpawnDataAgent = func(context.Context, domain.DataAgent, time.Time) (dataagt.DataAgent, error) {
 			return dagt, nil
 		}
-		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s)
+		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s, hook.None[apiwebhooks.DataEvent]{})
 
 		err := testee(ectx)
 		if err == nil {
@@ -1639,7 +1703,7 @@ func TestPostDataHandler(t *testing.T) {
 		iDataK8s.Impl.SpawnDataAgent = func(context.Context, domain.DataAgent, time.Time) (dataagt.DataAgent, error) {
 			return dagt, nil
 		}
-		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s)
+		testee := handlers.PostDataHandler(iDataDB, iRunDB, iDataK8s, hook.None[apiwebhooks.DataEvent]{})
 
 		ectx, _ := httptestutil.Post(
 			e, "/api/backends/data/", pr,
@@ -2037,7 +2101,7 @@ func TestImpoerDataEndHandler(t *testing.T) {
 			return true, nil
 		}
 
-		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData)
+		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, hook.None[apiwebhooks.DataEvent]{})
 		e := echo.New()
 		ectx, resprec := httptestutil.Post(
 			e, "/api/backends/data/import/end", bytes.NewBufferString(token),
@@ -2144,7 +2208,7 @@ func TestImpoerDataEndHandler(t *testing.T) {
 				return true, nil
 			}
 
-			testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData)
+			testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, hook.None[apiwebhooks.DataEvent]{})
 			e := echo.New()
 			ectx, _ := httptestutil.Post(
 				e, "/api/backends/data/import/end", bytes.NewBufferString(token),
@@ -2201,7 +2265,7 @@ func TestImpoerDataEndHandler(t *testing.T) {
 			return false, nil
 		}
 
-		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData)
+		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, hook.None[apiwebhooks.DataEvent]{})
 		e := echo.New()
 		ectx, _ := httptestutil.Post(
 			e, "/api/backends/data/import/end", bytes.NewBufferString(token),
@@ -2254,7 +2318,7 @@ func TestImpoerDataEndHandler(t *testing.T) {
 			return false, &k8serrors.ErrMissing{}
 		}
 
-		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData)
+		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, hook.None[apiwebhooks.DataEvent]{})
 		e := echo.New()
 		ectx, _ := httptestutil.Post(
 			e, "/api/backends/data/import/end", bytes.NewBufferString(token),
@@ -2308,7 +2372,7 @@ func TestImpoerDataEndHandler(t *testing.T) {
 			return false, expectedError
 		}
 
-		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData)
+		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, hook.None[apiwebhooks.DataEvent]{})
 		e := echo.New()
 		ectx, _ := httptestutil.Post(
 			e, "/api/backends/data/import/end", bytes.NewBufferString(token),
@@ -2362,7 +2426,7 @@ func TestImpoerDataEndHandler(t *testing.T) {
 
 		k8sData := k8sdatamocks.New(t)
 
-		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData)
+		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, hook.None[apiwebhooks.DataEvent]{})
 		e := echo.New()
 		ectx, _ := httptestutil.Post(
 			e, "/api/backends/data/import/end", bytes.NewBufferString(token),
@@ -2414,7 +2478,7 @@ func TestImpoerDataEndHandler(t *testing.T) {
 
 		k8sData := k8sdatamocks.New(t)
 
-		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData)
+		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, hook.None[apiwebhooks.DataEvent]{})
 		e := echo.New()
 		ectx, _ := httptestutil.Post(
 			e, "/api/backends/data/import/end", bytes.NewBufferString(token),
@@ -2462,7 +2526,7 @@ func TestImpoerDataEndHandler(t *testing.T) {
 
 		k8sData := k8sdatamocks.New(t)
 
-		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData)
+		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, hook.None[apiwebhooks.DataEvent]{})
 		e := echo.New()
 		ectx, _ := httptestutil.Post(
 			e, "/api/backends/data/import/end", bytes.NewBufferString(token),
@@ -2502,7 +2566,7 @@ func TestImpoerDataEndHandler(t *testing.T) {
 
 		k8sData := k8sdatamocks.New(t)
 
-		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData)
+		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, hook.None[apiwebhooks.DataEvent]{})
 		e := echo.New()
 		ectx, _ := httptestutil.Post(
 			e, "/api/backends/data/import/end", bytes.NewBufferString(token),
@@ -2524,7 +2588,7 @@ func TestImpoerDataEndHandler(t *testing.T) {
 		dbData := dbdatamock.NewDataInterface()
 		k8sData := k8sdatamocks.New(t)
 
-		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData)
+		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, hook.None[apiwebhooks.DataEvent]{})
 		e := echo.New()
 		ectx, _ := httptestutil.Post(e, "/api/backends/data/import/end", nil)
 		ectx.SetPath("/api/backends/data/import/end")
@@ -2544,7 +2608,7 @@ func TestImpoerDataEndHandler(t *testing.T) {
 
 		k8sData := k8sdatamocks.New(t)
 
-		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData)
+		testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, hook.None[apiwebhooks.DataEvent]{})
 		e := echo.New()
 		ectx, _ := httptestutil.Post(e, "/api/backends/data/import/end", nil, httptestutil.ContentType("application/json"))
 		ectx.SetPath("/api/backends/data/import/end")
@@ -2557,3 +2621,133 @@ func TestImpoerDataEndHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestImportDataEndHandler_Hook(t *testing.T) {
+	claim := &handlers.DataImportClaim{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:      "nonce",
+			Subject: "test-volume-ref",
+		},
+		RunId:  "test-run-id",
+		KnitId: "test-knit-id",
+	}
+	k := try.To(key.HS256(3*time.Hour, 2048/8).Issue()).OrFatal(t)
+	token := try.To(keychain.NewJWS("test-key-id", k, claim)).OrFatal(t)
+
+	data := domain.KnitData{
+		KnitDataBody: domain.KnitDataBody{
+			KnitId:    claim.KnitId,
+			VolumeRef: claim.Subject,
+		},
+		Upsteram: domain.DataSource{
+			MountPoint: &domain.MountPoint{Id: 1, Path: "/imported"},
+			RunBody: domain.RunBody{
+				Id: claim.RunId, Status: domain.Running,
+				PlanBody: domain.PlanBody{
+					PlanId: "test-plan-id",
+					Pseudo: &domain.PseudoPlanDetail{Name: domain.Imported},
+				},
+			},
+		},
+	}
+
+	for name, testcase := range map[string]struct {
+		hookErr    error
+		statusCode int
+		newStatus  []domain.KnitRunStatus
+		after      bool
+	}{
+		"when the hook accepts, the Data is imported": {
+			statusCode: http.StatusOK,
+			newStatus:  []domain.KnitRunStatus{domain.Completing},
+			after:      true,
+		},
+		"when the hook rejects, the import is aborted": {
+			hookErr:    hook.ErrHookRejected,
+			statusCode: http.StatusForbidden,
+			newStatus:  []domain.KnitRunStatus{domain.Aborting},
+		},
+		"when the hook fails, the import can be retried": {
+			hookErr:    errors.New("fake error"),
+			statusCode: http.StatusServiceUnavailable,
+			newStatus:  []domain.KnitRunStatus{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			kp := mockkeyprovider.New(t)
+			kp.Impl.GetKeychain = func(context.Context) (keychain.Keychain, error) {
+				mkc := mockkeychain.New(t)
+				mkc.Impl.GetKey = func(options ...keychain.KeyRequirement) (string, key.Key, bool) {
+					return "test-key", k, true
+				}
+				return mkc, nil
+			}
+
+			newStatus := []domain.KnitRunStatus{}
+			dbRun := dbrunmock.NewRunInterface()
+			dbRun.Impl.SetStatus = func(ctx context.Context, runId string, s domain.KnitRunStatus) error {
+				newStatus = append(newStatus, s)
+				return nil
+			}
+
+			dbData := dbdatamock.NewDataInterface()
+			dbData.Impl.Get = func(ctx context.Context, knitId []string) (map[string]domain.KnitData, error) {
+				return map[string]domain.KnitData{claim.KnitId: data}, nil
+			}
+
+			k8sData := k8sdatamocks.New(t)
+			k8sData.Impl.ChechDataisBound = func(ctx context.Context, da domain.KnitDataBody) (bool, error) {
+				return true, nil
+			}
+
+			afterCalled := false
+			dataHook := hook.Func[apiwebhooks.DataEvent, struct{}]{
+				BeforeFn: func(ev apiwebhooks.DataEvent) (struct{}, error) {
+					if ev.Event != apiwebhooks.DataImported || ev.Data.KnitId != claim.KnitId {
+						t.Errorf("unexpected event for before: %+v", ev)
+					}
+					if len(newStatus) != 0 {
+						t.Error("before hook is called after the status is changed")
+					}
+					return struct{}{}, testcase.hookErr
+				},
+				AfterFn: func(ev apiwebhooks.DataEvent) error {
+					afterCalled = true
+					if ev.Event != apiwebhooks.DataImported || ev.Data.KnitId != claim.KnitId {
+						t.Errorf("unexpected event for after: %+v", ev)
+					}
+					return nil
+				},
+			}
+
+			testee := handlers.ImportDataEndHandler(k8sData, kp, dbRun, dbData, dataHook)
+			e := echo.New()
+			ectx, resprec := httptestutil.Post(
+				e, "/api/backends/data/import/end", bytes.NewBufferString(token),
+				httptestutil.ContentType("application/jwt"),
+			)
+			ectx.SetPath("/api/backends/data/import/end")
+
+			err := testee(ectx)
+			if testcase.hookErr == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := resprec.Result().StatusCode; got != testcase.statusCode {
+					t.Errorf("status code: %d", got)
+				}
+			} else if herr := new(echo.HTTPError); !errors.As(err, &herr) {
+				t.Fatalf("error is not echo.HTTPError: %+v", err)
+			} else if herr.Code != testcase.statusCode {
+				t.Errorf("error code is not %d. actual = %d", testcase.statusCode, herr.Code)
+			}
+
+			if !cmp.SliceEq(newStatus, testcase.newStatus) {
+				t.Errorf("SetStatus: %v, want %v", newStatus, testcase.newStatus)
+			}
+			if afterCalled != testcase.after {
+				t.Errorf("after hook called: %v", afterCalled)
+			}
+		})
+	}
+}
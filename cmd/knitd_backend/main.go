@@ -13,6 +13,7 @@ import (
 	"time"
 
 	configs "github.com/opst/knitfab/pkg/configs/backend"
+	cfg_hook "github.com/opst/knitfab/pkg/configs/hook"
 
 	knitfab "github.com/opst/knitfab/pkg/domain/knitfab"
 )
@@ -28,6 +29,7 @@ func main() {
 	plic := flag.Bool("license", false, "show licenses of dependencies")
 	schemaRepo := flag.String("schema-repo", os.Getenv("KNIT_SCHEMA"), "schema repository path")
 	loglevel := flag.String("loglevel", "warn", "log level. debug|info|warn|error|off")
+	phooks := flag.String("hooks", os.Getenv("KNIT_HOOK_CONFIG"), "path to hook config file")
 
 	flag.Parse()
 
@@ -44,6 +46,14 @@ func main() {
 		panic(err)
 	}
 
+	hooks := cfg_hook.Config{}
+	if *phooks != "" {
+		hooks, err = cfg_hook.Load(*phooks)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	options := []knitfab.Option{}
 	if *schemaRepo != "" {
 		options = append(options, knitfab.WithSchemaRepository(*schemaRepo))
@@ -59,7 +69,7 @@ func main() {
 		ctx = ctx_
 	}
 
-	server := BuildServer(knitCluster, *loglevel)
+	server := BuildServer(knitCluster, hooks, *loglevel)
 	for _, r := range server.Routes() {
 		server.Logger.Debugf("- mount handler: %s %s", strings.ToUpper(r.Method), r.Path)
 	}
@@ -9,11 +9,14 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/labstack/gommon/log"
+	apiwebhooks "github.com/opst/knitfab-api-types/webhooks"
 	handlers "github.com/opst/knitfab/cmd/knitd_backend/handlers"
 	keyprovider "github.com/opst/knitfab/cmd/knitd_backend/provider/keyProvider"
+	cfg_hook "github.com/opst/knitfab/pkg/configs/hook"
 	keychain "github.com/opst/knitfab/pkg/domain/keychain/k8s"
 	"github.com/opst/knitfab/pkg/domain/keychain/k8s/key"
 	knit "github.com/opst/knitfab/pkg/domain/knitfab"
+	"github.com/opst/knitfab/pkg/hook"
 )
 
 var API_ROOT = "/api/backend"
@@ -25,7 +28,7 @@ func api(subpath string) string {
 	return fmt.Sprintf("%s/%s", API_ROOT, subpath)
 }
 
-func BuildServer(knit knit.Knitfab, loglevel string) *echo.Echo {
+func BuildServer(knit knit.Knitfab, hooks cfg_hook.Config, loglevel string) *echo.Echo {
 
 	e := echo.New()
 
@@ -78,10 +81,19 @@ func BuildServer(knit knit.Knitfab, loglevel string) *echo.Echo {
 		}
 	})
 
+	dataHook := hook.BuildWeb[apiwebhooks.DataEvent](
+		hooks.Data, cfg_hook.DataHooks,
+		func(a, b struct{}) struct{} { return struct{}{} },
+		func(ctx context.Context, name string, urls []string, payload []byte) error {
+			return knit.Webhook().Database().Enqueue(ctx, name, urls, payload)
+		},
+	)
+
 	e.POST(api("data"), handlers.PostDataHandler(
 		knit.Data().Database(),
 		knit.Run().Database(),
 		knit.Data().K8s(),
+		dataHook,
 	))
 
 	e.GET(api("data/:knitId"), handlers.GetDataHandler(
@@ -110,6 +122,7 @@ func BuildServer(knit knit.Knitfab, loglevel string) *echo.Echo {
 		keyProviderForImportToken,
 		knit.Run().Database(),
 		knit.Data().Database(),
+		dataHook,
 	))
 
 	e.GET(api("runs/:runid/log"), handlers.GetRunLogHandler(
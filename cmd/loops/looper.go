@@ -3,25 +3,33 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
 	"time"
 
-	"github.com/opst/knitfab/cmd/loops/hook"
+	apitags "github.com/opst/knitfab-api-types/tags"
 	"github.com/opst/knitfab/cmd/loops/loop"
 	"github.com/opst/knitfab/cmd/loops/loop/recurring"
 	"github.com/opst/knitfab/cmd/loops/tasks/finishing"
 	"github.com/opst/knitfab/cmd/loops/tasks/gc"
 	"github.com/opst/knitfab/cmd/loops/tasks/housekeeping"
 	"github.com/opst/knitfab/cmd/loops/tasks/initialize"
+	"github.com/opst/knitfab/cmd/loops/tasks/measure"
 	"github.com/opst/knitfab/cmd/loops/tasks/projection"
+	"github.com/opst/knitfab/cmd/loops/tasks/retention"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/manager"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/manager/image"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/manager/imported"
+	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/manager/parameter"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/manager/uploaded"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/runManagementHook"
+	"github.com/opst/knitfab/cmd/loops/tasks/schedule"
+	"github.com/opst/knitfab/cmd/loops/tasks/webhook"
+	binddata "github.com/opst/knitfab/pkg/api-types-binding/data"
 	cfg_hook "github.com/opst/knitfab/pkg/configs/hook"
 	"github.com/opst/knitfab/pkg/domain"
 	"github.com/opst/knitfab/pkg/domain/knitfab"
+	"github.com/opst/knitfab/pkg/hook"
 	"github.com/opst/knitfab/pkg/utils/slices"
 )
 
@@ -88,12 +96,51 @@ type LoopManifest struct {
 
 	// Hooks for the looping
 	Hooks cfg_hook.Config
+
+	// MaxConcurrentRuns is the max number of Runs being starting or running at once.
+	//
+	// 0 means unlimited. It is used by run management loop.
+	MaxConcurrentRuns int
+
+	// Quotas cap resources held by Runs being starting or running.
+	//
+	// It is used by run management loop.
+	Quotas []domain.Quota
+
+	// Retention rules expiring Data.
+	//
+	// It is used by retention loop.
+	Retention []domain.RetentionRule
 }
 
 func mergeEmptyStruct(a, b struct{}) struct{} {
 	return struct{}{}
 }
 
+// dataTags returns a function which looks up Tags of Data, for lifecycle events.
+func dataTags(ctx context.Context, knit knitfab.Knitfab) func([]string) (map[string][]apitags.Tag, error) {
+	return func(knitIds []string) (map[string][]apitags.Tag, error) {
+		data, err := knit.Data().Database().Get(ctx, knitIds)
+		if err != nil {
+			return nil, err
+		}
+		ret := map[string][]apitags.Tag{}
+		for knitId, d := range data {
+			ret[knitId] = binddata.ComposeSummary(d.KnitDataBody).Tags
+		}
+		return ret, nil
+	}
+}
+
+// webhookOutbox returns a function which queues payloads of webhooks into the outbox.
+//
+// Payloads are queued in the transaction bound to the context passed to the function, if any.
+func webhookOutbox(knit knitfab.Knitfab) func(context.Context, string, []string, []byte) error {
+	return func(ctx context.Context, hook string, urls []string, payload []byte) error {
+		return knit.Webhook().Database().Enqueue(ctx, hook, urls, payload)
+	}
+}
+
 // Start proection loop
 //
 // Args:
@@ -124,6 +171,28 @@ func StartProjectionLoop(
 	return err
 }
 
+// Start scheduling loop
+//
+// It creates runs for plans whose cron schedule has come.
+func StartSchedulingLoop(
+	ctx context.Context,
+	logger *log.Logger,
+	knit knitfab.Knitfab,
+	manifest LoopManifest,
+) error {
+	l := byLogger(logger, Copied(), WithPrefix("[scheduling loop]"))
+	_, err := loop.Start(
+		ctx, schedule.Seed(),
+		monitor(
+			l,
+			schedule.Task(
+				l, knit.Run().Database(),
+			).Applied(manifest.Policy),
+		),
+	)
+	return err
+}
+
 func StartInitializeLoop(
 	ctx context.Context,
 	logger *log.Logger,
@@ -137,7 +206,7 @@ func StartInitializeLoop(
 			initialize.Task(
 				knit.Run().Database(),
 				knit.Run().K8s(),
-				hook.Build(manifest.Hooks.Lifecycle, mergeEmptyStruct),
+				hook.Build(manifest.Hooks, mergeEmptyStruct, dataTags(ctx, knit), webhookOutbox(knit)),
 			).Applied(manifest.Policy),
 		),
 		loop.WithTimeout(30*time.Second),
@@ -157,7 +226,14 @@ func StartRunManagementLoop(
 			knit.Data().Database(),
 		),
 		domain.Imported: imported.New(),
+		domain.Parameter: parameter.New(
+			knit.Data().Database(),
+			knit.Data().K8s(),
+			http.DefaultClient,
+		),
 	}
+	tags := dataTags(ctx, knit)
+	outbox := webhookOutbox(knit)
 	_, err := loop.Start(
 		ctx,
 		// Initial RunCursor
@@ -172,15 +248,17 @@ func StartRunManagementLoop(
 				image.New(
 					knit.Run().K8s(),
 					knit.Run().Database(),
+					manifest.MaxConcurrentRuns,
+					manifest.Quotas,
 				),
 				// A map of psuedo plan name to the psuedo plan manager
 				pseudoPlanManagers,
 
 				runManagementHook.Hooks{
-					ToStarting:   hook.Build(manifest.Hooks.Lifecycle, runManagementHook.Merge), // ready -> starting
-					ToRunning:    hook.Build(manifest.Hooks.Lifecycle, mergeEmptyStruct),        // starting -> running
-					ToCompleting: hook.Build(manifest.Hooks.Lifecycle, mergeEmptyStruct),        // running -> completing
-					ToAborting:   hook.Build(manifest.Hooks.Lifecycle, mergeEmptyStruct),        // running -> aborting
+					ToStarting:   hook.Build(manifest.Hooks, runManagementHook.Merge, tags, outbox), // ready -> starting
+					ToRunning:    hook.Build(manifest.Hooks, mergeEmptyStruct, tags, outbox),        // starting -> running
+					ToCompleting: hook.Build(manifest.Hooks, mergeEmptyStruct, tags, outbox),        // running -> completing
+					ToAborting:   hook.Build(manifest.Hooks, mergeEmptyStruct, tags, outbox),        // running -> aborting
 				},
 			).Applied(manifest.Policy),
 		),
@@ -198,6 +276,7 @@ func StartFinishingLoop(
 	pseudoPlanNames := []domain.PseudoPlanName{
 		domain.Uploaded,
 		domain.Imported,
+		domain.Parameter,
 	}
 	// Initial RunCursor
 	runCursor := finishing.Seed(pseudoPlanNames)
@@ -210,7 +289,7 @@ func StartFinishingLoop(
 			finishing.Task(
 				knit.Run().Database(),
 				knit.Run().K8s(),
-				hook.Build(manifest.Hooks.Lifecycle, mergeEmptyStruct),
+				hook.Build(manifest.Hooks, mergeEmptyStruct, dataTags(ctx, knit), webhookOutbox(knit)),
 			).Applied(manifest.Policy),
 		),
 	)
@@ -254,3 +333,68 @@ func StartHousekeepingLoop(
 	)
 	return err
 }
+
+// Start retention loop
+//
+// It deletes Runs whose outputs are expired by retention rules.
+func StartRetentionLoop(
+	ctx context.Context,
+	logger *log.Logger,
+	knit knitfab.Knitfab,
+	manifest LoopManifest,
+) error {
+	_, err := loop.Start(
+		ctx, retention.Seed(),
+		monitor(
+			byLogger(logger, Copied(), WithPrefix("[retention loop]")),
+			retention.Task(
+				knit.Data().Database(),
+				knit.Run().Database(),
+				manifest.Retention,
+			).Applied(manifest.Policy),
+		),
+	)
+	return err
+}
+
+// Start webhook loop
+//
+// It delivers webhook calls queued in the outbox, and retries failed ones.
+func StartWebhookLoop(
+	ctx context.Context,
+	logger *log.Logger,
+	knit knitfab.Knitfab,
+	manifest LoopManifest,
+) error {
+	l := byLogger(logger, Copied(), WithPrefix("[webhook loop]"))
+	_, err := loop.Start(
+		ctx, webhook.Seed(),
+		monitor(
+			l,
+			webhook.Task(
+				l, knit.Webhook().Database(), manifest.Hooks,
+			).Applied(manifest.Policy),
+		),
+	)
+	return err
+}
+
+func StartMeasureLoop(
+	ctx context.Context,
+	logger *log.Logger,
+	knit knitfab.Knitfab,
+	manifest LoopManifest,
+) error {
+	_, err := loop.Start(
+		ctx, measure.Seed(),
+		monitor(
+			byLogger(logger, Copied(), WithPrefix("[measure loop]")),
+			measure.Task(
+				knit.Data().Database(),
+				knit.Data().K8s(),
+				30*time.Second,
+			).Applied(manifest.Policy),
+		),
+	)
+	return err
+}
@@ -19,6 +19,7 @@ import (
 	knitfab "github.com/opst/knitfab/pkg/domain/knitfab"
 	"github.com/opst/knitfab/pkg/utils/args"
 	"github.com/opst/knitfab/pkg/utils/filewatch"
+	"github.com/opst/knitfab/pkg/utils/slices"
 	"github.com/opst/knitfab/pkg/utils/try"
 )
 
@@ -100,7 +101,16 @@ func main() {
 		loopType.Value().String(), policy.Value().String(),
 	)
 
-	manifest := LoopManifest{Policy: policy.Value(), Hooks: hooks}
+	// changes made by loops are recorded in audit log as "loop/<loop type>".
+	ctx = domain.WithActor(ctx, "loop/"+loopType.Value().String())
+
+	manifest := LoopManifest{
+		Policy:            policy.Value(),
+		Hooks:             hooks,
+		MaxConcurrentRuns: conf.Cluster().Worker().MaxConcurrentRuns(),
+		Quotas:            slices.Map(conf.Cluster().Worker().Quotas(), asQuota),
+		Retention:         slices.Map(conf.Cluster().Retention(), asRetentionRule),
+	}
 	var err error
 	switch loopType.Value() {
 	case domain.Projection:
@@ -115,6 +125,14 @@ func main() {
 		err = StartGarbageCollectionLoop(ctx, logger, kcluster, manifest)
 	case domain.Housekeeping:
 		err = StartHousekeepingLoop(ctx, logger, kcluster, manifest)
+	case domain.Scheduling:
+		err = StartSchedulingLoop(ctx, logger, kcluster, manifest)
+	case domain.Webhook:
+		err = StartWebhookLoop(ctx, logger, kcluster, manifest)
+	case domain.Retention:
+		err = StartRetentionLoop(ctx, logger, kcluster, manifest)
+	case domain.Measure:
+		err = StartMeasureLoop(ctx, logger, kcluster, manifest)
 	default:
 		err = fmt.Errorf("unsupported loop type: %s", loopType.Value())
 	}
@@ -129,3 +147,27 @@ func main() {
 		logger.Fatal(err)
 	}
 }
+
+// asQuota converts the configuration of a quota into domain.Quota.
+func asQuota(qc *configs.QuotaConfig) domain.Quota {
+	q := domain.Quota{
+		Name:   qc.Name(),
+		User:   qc.User(),
+		Limits: qc.Limits(),
+	}
+	if key, value, ok := qc.Annotation(); ok {
+		q.Annotation = &domain.Annotation{Key: key, Value: value}
+	}
+	return q
+}
+
+// asRetentionRule converts the configuration of a retention rule into domain.RetentionRule.
+func asRetentionRule(rc *configs.RetentionConfig) domain.RetentionRule {
+	return domain.RetentionRule{
+		Name: rc.Name(),
+		Tags: slices.Map(rc.Tags(), func(t configs.RetentionTag) domain.Tag {
+			return domain.Tag{Key: t.Key, Value: t.Value}
+		}),
+		Age: rc.Age(),
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"testing"
 
 	apiruns "github.com/opst/knitfab-api-types/runs"
-	"github.com/opst/knitfab/cmd/loops/hook"
 	"github.com/opst/knitfab/cmd/loops/tasks/finishing"
 	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
 	"github.com/opst/knitfab/pkg/domain"
@@ -16,6 +15,7 @@ import (
 	kdbmock "github.com/opst/knitfab/pkg/domain/run/db/mock"
 	mockK8sRun "github.com/opst/knitfab/pkg/domain/run/k8s/mock"
 	"github.com/opst/knitfab/pkg/domain/run/k8s/worker"
+	"github.com/opst/knitfab/pkg/hook"
 )
 
 func TestTaskFinishing_Outside_PickAndSetStatus(t *testing.T) {
@@ -47,7 +47,8 @@ func TestTaskFinishing_Outside_PickAndSetStatus(t *testing.T) {
 			pickAndSetStatusCalled := false
 			iDbRun.Impl.PickAndSetStatus = func(
 				ctx context.Context, cursor domain.RunCursor,
-				_ func(domain.Run) (domain.KnitRunStatus, error), // ignore
+				_ func(context.Context, domain.Run) (domain.KnitRunStatus, error), // ignore
+				_ func(context.Context, domain.Run) error,
 			) (domain.RunCursor, bool, error) {
 				pickAndSetStatusCalled = true
 				return when.newCursor, when.statusChanged, when.err
@@ -400,9 +401,10 @@ func TestTaskFinishing_Inside_PickAndSetStatus(t *testing.T) {
 
 			iDbRun.Impl.PickAndSetStatus = func(
 				ctx context.Context, cursor domain.RunCursor,
-				callback func(domain.Run) (domain.KnitRunStatus, error), // ignore
+				callback func(context.Context, domain.Run) (domain.KnitRunStatus, error), // ignore
+				_ func(context.Context, domain.Run) error,
 			) (domain.RunCursor, bool, error) {
-				newStatus, err := callback(when.runPassedToCallback)
+				newStatus, err := callback(ctx, when.runPassedToCallback)
 
 				if then.wantAnyError && (err == nil) {
 					t.Errorf("err: actual=%+v, expect=%+v", err, then.wantError)
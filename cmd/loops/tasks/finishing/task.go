@@ -6,7 +6,6 @@ import (
 	"time"
 
 	apiruns "github.com/opst/knitfab-api-types/runs"
-	"github.com/opst/knitfab/cmd/loops/hook"
 	"github.com/opst/knitfab/cmd/loops/loop/recurring"
 	"github.com/opst/knitfab/pkg/api-types-binding/runs"
 	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
@@ -14,6 +13,8 @@ import (
 	k8serrors "github.com/opst/knitfab/pkg/domain/errors/k8serrors"
 	kdbrun "github.com/opst/knitfab/pkg/domain/run/db"
 	k8srun "github.com/opst/knitfab/pkg/domain/run/k8s"
+	"github.com/opst/knitfab/pkg/hook"
+	khook "github.com/opst/knitfab/pkg/hook"
 )
 
 // initial value for task
@@ -34,6 +35,8 @@ func Seed(pseudoPlans []domain.PseudoPlanName) domain.RunCursor {
 //
 // - task: let the Run finished (completing -> done, aborting -> failed) and
 // update run status.
+// Failed Runs are retried when the retry policy of their Plan allows,
+// along with the status change (see PickAndSetStatus).
 func Task(
 	iDbRun kdbrun.Interface,
 	iK8sRun k8srun.Interface,
@@ -42,7 +45,7 @@ func Task(
 	return func(ctx context.Context, cursor domain.RunCursor) (domain.RunCursor, bool, error) {
 		nextCursor, statusChanged, err := iDbRun.PickAndSetStatus(
 			ctx, cursor,
-			func(targetRun domain.Run) (domain.KnitRunStatus, error) {
+			func(ctx context.Context, targetRun domain.Run) (domain.KnitRunStatus, error) {
 				var nextState domain.KnitRunStatus
 				switch targetRun.Status {
 				case domain.Completing:
@@ -56,7 +59,7 @@ func Task(
 
 				hookValue := runs.ComposeDetail(targetRun)
 
-				if _, err := hook.Before(hookValue); err != nil {
+				if _, err := hook.Before(ctx, hookValue); err != nil {
 					return targetRun.Status, err
 				}
 
@@ -90,13 +93,17 @@ func Task(
 
 				return nextState, nil
 			},
+			func(ctx context.Context, r domain.Run) error {
+				// queue "after" webhooks along with the status change.
+				return khook.Enqueue(ctx, hook, bindruns.ComposeDetail(r))
+			},
 		)
 
 		if statusChanged {
 			if runs, _ := iDbRun.Get(ctx, []string{nextCursor.Head}); runs != nil {
 				if r, ok := runs[nextCursor.Head]; ok {
 					hookVal := bindruns.ComposeDetail(r)
-					hook.After(hookVal)
+					hook.After(ctx, hookVal)
 				}
 			}
 		}
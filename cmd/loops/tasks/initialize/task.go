@@ -5,12 +5,13 @@ import (
 	"errors"
 
 	apiruns "github.com/opst/knitfab-api-types/runs"
-	"github.com/opst/knitfab/cmd/loops/hook"
 	"github.com/opst/knitfab/cmd/loops/loop/recurring"
 	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
 	"github.com/opst/knitfab/pkg/domain"
 	kdbrun "github.com/opst/knitfab/pkg/domain/run/db"
 	k8srun "github.com/opst/knitfab/pkg/domain/run/k8s"
+	"github.com/opst/knitfab/pkg/hook"
+	khook "github.com/opst/knitfab/pkg/hook"
 )
 
 // initial value for task
@@ -40,9 +41,9 @@ func Task(
 	return func(ctx context.Context, value domain.RunCursor) (domain.RunCursor, bool, error) {
 		nextCursor, statusChanged, err := irun.PickAndSetStatus(
 			ctx, value,
-			func(r domain.Run) (domain.KnitRunStatus, error) {
+			func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
 				hookval := bindruns.ComposeDetail(r)
-				if _, err := hook.Before(hookval); err != nil {
+				if _, err := hook.Before(ctx, hookval); err != nil {
 					return r.Status, err
 				}
 
@@ -51,13 +52,17 @@ func Task(
 				}
 				return domain.Ready, nil
 			},
+			func(ctx context.Context, r domain.Run) error {
+				// queue "after" webhooks along with the status change.
+				return khook.Enqueue(ctx, hook, bindruns.ComposeDetail(r))
+			},
 		)
 
 		if statusChanged {
 			if runs, _ := irun.Get(ctx, []string{nextCursor.Head}); runs != nil {
 				if r, ok := runs[nextCursor.Head]; ok {
 					hookval := bindruns.ComposeDetail(r)
-					hook.After(hookval)
+					hook.After(ctx, hookval)
 				}
 			}
 		}
@@ -7,12 +7,12 @@ import (
 
 	"github.com/opst/knitfab-api-types/misc/rfctime"
 	apiruns "github.com/opst/knitfab-api-types/runs"
-	"github.com/opst/knitfab/cmd/loops/hook"
 	"github.com/opst/knitfab/cmd/loops/tasks/initialize"
 	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
 	types "github.com/opst/knitfab/pkg/domain"
 	kdbrunmock "github.com/opst/knitfab/pkg/domain/run/db/mock"
 	k8srunmock "github.com/opst/knitfab/pkg/domain/run/k8s/mock"
+	"github.com/opst/knitfab/pkg/hook"
 	"github.com/opst/knitfab/pkg/utils/try"
 )
 
@@ -39,7 +39,8 @@ func TestTask_Outside_of_PickAndSetStatus(t *testing.T) {
 			run := kdbrunmock.NewRunInterface()
 			run.Impl.PickAndSetStatus = func(
 				ctx context.Context, value types.RunCursor,
-				f func(types.Run) (types.KnitRunStatus, error),
+				f func(context.Context, types.Run) (types.KnitRunStatus, error),
+				_ func(context.Context, types.Run) error,
 			) (types.RunCursor, bool, error) {
 				return when.NextCursor, when.StatusChanged, when.Err
 			}
@@ -315,9 +316,10 @@ func TestTask_Inside_of_PickAndSetStatus(t *testing.T) {
 			run := kdbrunmock.NewRunInterface()
 			run.Impl.PickAndSetStatus = func(
 				ctx context.Context, value types.RunCursor,
-				f func(types.Run) (types.KnitRunStatus, error),
+				f func(context.Context, types.Run) (types.KnitRunStatus, error),
+				_ func(context.Context, types.Run) error,
 			) (types.RunCursor, bool, error) {
-				gotStatus, err := f(pickedRun)
+				gotStatus, err := f(ctx, pickedRun)
 
 				if when.BeforeErr != nil {
 					if !errors.Is(err, when.BeforeErr) {
@@ -0,0 +1,116 @@
+package measure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	apidata "github.com/opst/knitfab-api-types/data"
+	"github.com/opst/knitfab/cmd/loops/loop/recurring"
+	"github.com/opst/knitfab/pkg/domain"
+	kdbdata "github.com/opst/knitfab/pkg/domain/data/db"
+	k8sdata "github.com/opst/knitfab/pkg/domain/data/k8s"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+)
+
+// initial value for task
+func Seed() any {
+	return nil
+}
+
+// return:
+//
+// - task: measure sizes and checksums of Data which are not measured yet, and record them.
+//
+// Sizes and checksums of uploaded Data are recorded on upload.
+// This task covers the others, outputs of Runs and imported Data.
+//
+// To measure a Data, a data agent in read mode is spawned and asked the size and the checksum.
+// Data which can not be measured now are tried again in the next time.
+func Task(
+	dbData kdbdata.DataInterface,
+	k8sData k8sdata.Interface,
+	timeout time.Duration,
+) recurring.Task[any] {
+	return func(ctx context.Context, value any) (any, bool, error) {
+		knitIds, err := dbData.Unmeasured(ctx)
+		if err != nil {
+			return value, false, err
+		}
+
+		measured := false
+		for _, knitId := range knitIds {
+			m, err := measure(ctx, dbData, k8sData, knitId, timeout)
+			if err != nil {
+				continue
+			}
+			if err := dbData.SetSize(ctx, knitId, domain.DataSize{Bytes: m.Bytes, Files: m.Files}); err != nil {
+				if errors.Is(err, kerr.ErrMissing) {
+					continue
+				}
+				return value, measured, err
+			}
+			if err := dbData.SetChecksum(ctx, knitId, m.Checksum); err != nil {
+				if errors.Is(err, kerr.ErrMissing) {
+					continue
+				}
+				return value, measured, err
+			}
+			measured = true
+		}
+
+		return value, measured, nil
+	}
+}
+
+func measure(
+	ctx context.Context,
+	dbData kdbdata.DataInterface,
+	k8sData k8sdata.Interface,
+	knitId string,
+	timeout time.Duration,
+) (apidata.Measurement, error) {
+	deadline := time.Now().Add(timeout)
+
+	daRecord, err := dbData.NewAgent(ctx, knitId, domain.DataAgentRead, timeout)
+	if err != nil {
+		return apidata.Measurement{}, err
+	}
+
+	da, err := k8sData.SpawnDataAgent(ctx, daRecord, deadline)
+	if err != nil {
+		// the record is left to housekeeping loop.
+		return apidata.Measurement{}, err
+	}
+	defer func() {
+		if err := da.Close(); err != nil {
+			return
+		}
+		dbData.RemoveAgent(ctx, daRecord.Name)
+	}()
+
+	reqctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqctx, http.MethodGet, da.URL()+"?size", nil)
+	if err != nil {
+		return apidata.Measurement{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return apidata.Measurement{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return apidata.Measurement{}, fmt.Errorf("data agent for %s responses %d", knitId, resp.StatusCode)
+	}
+
+	m := apidata.Measurement{}
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return apidata.Measurement{}, err
+	}
+	return m, nil
+}
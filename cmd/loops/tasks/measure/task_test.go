@@ -0,0 +1,152 @@
+package measure_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/cmd/loops/tasks/measure"
+	"github.com/opst/knitfab/pkg/domain"
+	dbdatamocks "github.com/opst/knitfab/pkg/domain/data/db/mock"
+	"github.com/opst/knitfab/pkg/domain/data/k8s/dataagt"
+	k8sdatamocks "github.com/opst/knitfab/pkg/domain/data/k8s/mock"
+	"github.com/opst/knitfab/pkg/domain/knitfab/k8s/cluster"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+)
+
+type mockDataAgent struct {
+	name   string
+	url    string
+	knitId string
+	closed bool
+}
+
+func (m *mockDataAgent) Name() string               { return m.name }
+func (m *mockDataAgent) APIPort() int32             { return 8080 }
+func (m *mockDataAgent) URL() string                { return m.url }
+func (m *mockDataAgent) Mode() domain.DataAgentMode { return domain.DataAgentRead }
+func (m *mockDataAgent) KnitID() string             { return m.knitId }
+func (m *mockDataAgent) VolumeRef() string          { return "pvc-" + m.knitId }
+func (m *mockDataAgent) PodPhase() cluster.PodPhase { return cluster.PodRunning }
+func (m *mockDataAgent) String() string             { return m.name }
+func (m *mockDataAgent) Close() error               { m.closed = true; return nil }
+
+func TestTask(t *testing.T) {
+	t.Run("it measures data not measured yet", func(t *testing.T) {
+		ctx := context.Background()
+
+		svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !r.URL.Query().Has("size") {
+				t.Errorf("size is not queried: %s", r.URL)
+			}
+			switch r.URL.Path {
+			case "/data-1":
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"bytes": 100, "files": 2, "checksum": "sha256:0123abcd"}`))
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+		defer svr.Close()
+
+		dbData := dbdatamocks.NewDataInterface()
+		dbData.Impl.Unmeasured = func(context.Context) ([]string, error) {
+			return []string{"data-1", "data-2", "data-3"}, nil
+		}
+		dbData.Impl.NewAgent = func(_ context.Context, knitId string, mode domain.DataAgentMode, _ time.Duration) (domain.DataAgent, error) {
+			if mode != domain.DataAgentRead {
+				t.Errorf("unexpected mode: %s", mode)
+			}
+			return domain.DataAgent{
+				Name: "agent-" + knitId, Mode: mode,
+				KnitDataBody: domain.KnitDataBody{KnitId: knitId},
+			}, nil
+		}
+		dbData.Impl.RemoveAgent = func(context.Context, string) error { return nil }
+		dbData.Impl.SetSize = func(context.Context, string, domain.DataSize) error { return nil }
+		dbData.Impl.SetChecksum = func(context.Context, string, string) error { return nil }
+
+		agents := map[string]*mockDataAgent{}
+		k8sData := k8sdatamocks.New(t)
+		k8sData.Impl.SpawnDataAgent = func(_ context.Context, da domain.DataAgent, _ time.Time) (dataagt.DataAgent, error) {
+			if da.KnitDataBody.KnitId == "data-3" {
+				return nil, errors.New("fake error")
+			}
+			agt := &mockDataAgent{name: da.Name, url: svr.URL + "/" + da.KnitDataBody.KnitId, knitId: da.KnitDataBody.KnitId}
+			agents[da.KnitDataBody.KnitId] = agt
+			return agt, nil
+		}
+
+		testee := measure.Task(dbData, k8sData, time.Second)
+		_, updated, err := testee(ctx, measure.Seed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !updated {
+			t.Error("updated should be true")
+		}
+
+		want := []struct {
+			KnitId string
+			Size   domain.DataSize
+		}{{KnitId: "data-1", Size: domain.DataSize{Bytes: 100, Files: 2}}}
+		if !cmp.SliceEq(dbData.Calls.SetSize, want) {
+			t.Errorf("SetSize: (actual, expected) = (%+v, %+v)", dbData.Calls.SetSize, want)
+		}
+		wantChecksum := []struct {
+			KnitId   string
+			Checksum string
+		}{{KnitId: "data-1", Checksum: "sha256:0123abcd"}}
+		if !cmp.SliceEq(dbData.Calls.SetChecksum, wantChecksum) {
+			t.Errorf("SetChecksum: (actual, expected) = (%+v, %+v)", dbData.Calls.SetChecksum, wantChecksum)
+		}
+		for knitId, agt := range agents {
+			if !agt.closed {
+				t.Errorf("data agent for %s is not closed", knitId)
+			}
+		}
+		if got := dbData.Calls.RemoveAgent; !cmp.SliceContentEq(
+			got, []struct{ Name string }{{Name: "agent-data-1"}, {Name: "agent-data-2"}},
+		) {
+			t.Errorf("RemoveAgent: %+v", got)
+		}
+	})
+
+	t.Run("it does nothing when all data are measured", func(t *testing.T) {
+		ctx := context.Background()
+
+		dbData := dbdatamocks.NewDataInterface()
+		dbData.Impl.Unmeasured = func(context.Context) ([]string, error) {
+			return []string{}, nil
+		}
+		k8sData := k8sdatamocks.New(t)
+
+		testee := measure.Task(dbData, k8sData, time.Second)
+		_, updated, err := testee(ctx, measure.Seed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated {
+			t.Error("updated should be false")
+		}
+	})
+
+	t.Run("it returns error when Unmeasured fails", func(t *testing.T) {
+		ctx := context.Background()
+		expectedErr := errors.New("fake error")
+
+		dbData := dbdatamocks.NewDataInterface()
+		dbData.Impl.Unmeasured = func(context.Context) ([]string, error) {
+			return nil, expectedErr
+		}
+		k8sData := k8sdatamocks.New(t)
+
+		testee := measure.Task(dbData, k8sData, time.Second)
+		if _, _, err := testee(ctx, measure.Seed()); !errors.Is(err, expectedErr) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
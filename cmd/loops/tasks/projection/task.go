@@ -16,9 +16,16 @@ func Seed() struct{} {
 // return:
 //
 // - task : creating new runs in waiting/deactivated state.
+// Before that, values of parameter inputs are materialised as data.
 func Task(logger *log.Logger, dbrun kdbrun.Interface) recurring.Task[struct{}] {
 	return func(ctx context.Context, value struct{}) (struct{}, bool, error) {
 		logger.Printf("checking...")
+		if runIds, err := dbrun.NewParameters(ctx); err != nil {
+			return value, false, err
+		} else if 0 < len(runIds) {
+			logger.Printf("parameters are materialised: new run id(s) = %v\n", runIds)
+		}
+
 		runId, triggered, err := dbrun.New(ctx)
 
 		if triggered != nil {
@@ -0,0 +1,104 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/opst/knitfab/cmd/loops/loop/recurring"
+	"github.com/opst/knitfab/pkg/domain"
+	kdbdata "github.com/opst/knitfab/pkg/domain/data/db"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	kdbrun "github.com/opst/knitfab/pkg/domain/run/db"
+	"github.com/opst/knitfab/pkg/utils/slices"
+)
+
+// initial value for task
+func Seed() any {
+	return nil
+}
+
+// return:
+//
+// - task: delete Runs whose outputs are expired by rules, with their outputs.
+//
+// A Run is deleted when it is done or failed, and all of its outputs (except log) are expired.
+// Runs whose outputs are used by other Runs are kept.
+//
+// Deleted outputs go to garbage, and their PVCs are removed by the garbage collection loop.
+func Task(
+	dbData kdbdata.DataInterface,
+	dbRun kdbrun.Interface,
+	rules []domain.RetentionRule,
+) recurring.Task[any] {
+	return func(ctx context.Context, value any) (any, bool, error) {
+		if len(rules) == 0 {
+			return value, false, nil
+		}
+
+		now := time.Now()
+		expired := map[string]struct{}{}
+		for _, r := range rules {
+			if len(r.Tags) == 0 {
+				// a rule without tags would expire everything.
+				continue
+			}
+			until := now.Add(-r.Age)
+			knitIds, _, err := dbData.Find(ctx, r.Tags, nil, &until, domain.Page{})
+			if err != nil {
+				return value, false, err
+			}
+			for _, k := range knitIds {
+				expired[k] = struct{}{}
+			}
+		}
+		if len(expired) == 0 {
+			return value, false, nil
+		}
+
+		data, err := dbData.Get(ctx, slices.KeysOf(expired))
+		if err != nil {
+			return value, false, err
+		}
+		runIds := map[string]struct{}{}
+		for _, d := range data {
+			runIds[d.Upsteram.RunBody.Id] = struct{}{}
+		}
+
+		runs, err := dbRun.Get(ctx, slices.KeysOf(runIds))
+		if err != nil {
+			return value, false, err
+		}
+
+		deleted := false
+		for _, runId := range slices.Sorted(slices.KeysOf(runs), func(a, b string) bool { return a < b }) {
+			run := runs[runId]
+			switch run.Status {
+			case domain.Done, domain.Failed:
+			default:
+				continue
+			}
+
+			allExpired := true
+			for _, o := range run.Outputs {
+				if _, ok := expired[o.KnitDataBody.KnitId]; !ok {
+					allExpired = false
+					break
+				}
+			}
+			if !allExpired {
+				continue
+			}
+
+			if err := dbRun.Delete(ctx, runId); err != nil {
+				if errors.Is(err, domain.ErrRunIsProtected) || errors.Is(err, kerr.ErrMissing) {
+					continue
+				}
+				return value, deleted, err
+			}
+			deleted = true
+		}
+
+		return value, deleted, nil
+	}
+}
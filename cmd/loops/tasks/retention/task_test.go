@@ -0,0 +1,170 @@
+package retention_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/cmd/loops/tasks/retention"
+	"github.com/opst/knitfab/pkg/domain"
+	dbdatamocks "github.com/opst/knitfab/pkg/domain/data/db/mock"
+	dbrunmocks "github.com/opst/knitfab/pkg/domain/run/db/mock"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+)
+
+func TestTask(t *testing.T) {
+	tmp := domain.Tag{Key: "tmp", Value: "true"}
+	scratch := domain.Tag{Key: "type", Value: "scratch"}
+	rules := []domain.RetentionRule{
+		{Name: "tmp", Tags: []domain.Tag{tmp}, Age: 7 * 24 * time.Hour},
+		{Name: "scratch", Tags: []domain.Tag{scratch}, Age: time.Hour},
+	}
+
+	data := func(knitId, runId string) domain.KnitData {
+		return domain.KnitData{
+			KnitDataBody: domain.KnitDataBody{KnitId: knitId},
+			Upsteram:     domain.DataSource{RunBody: domain.RunBody{Id: runId}},
+		}
+	}
+	run := func(runId string, status domain.KnitRunStatus, outputs ...string) domain.Run {
+		r := domain.Run{RunBody: domain.RunBody{Id: runId, Status: status}}
+		for _, o := range outputs {
+			r.Outputs = append(r.Outputs, domain.Assignment{
+				KnitDataBody: domain.KnitDataBody{KnitId: o},
+			})
+		}
+		return r
+	}
+
+	t.Run("it deletes runs whose outputs are all expired", func(t *testing.T) {
+		ctx := context.Background()
+
+		dbData := dbdatamocks.NewDataInterface()
+		dbData.Impl.Find = func(ctx context.Context, tags []domain.Tag, since, until *time.Time, page domain.Page) ([]string, string, error) {
+			switch tags[0] {
+			case tmp:
+				return []string{"data-1", "data-2", "data-3"}, "", nil
+			case scratch:
+				return []string{"data-4", "data-5"}, "", nil
+			}
+			return []string{}, "", nil
+		}
+		dbData.Impl.Get = func(ctx context.Context, knitIds []string) (map[string]domain.KnitData, error) {
+			return map[string]domain.KnitData{
+				"data-1": data("data-1", "run-done"),
+				"data-2": data("data-2", "run-partial"),
+				"data-3": data("data-3", "run-running"),
+				"data-4": data("data-4", "run-failed"),
+				"data-5": data("data-5", "run-protected"),
+			}, nil
+		}
+
+		dbRun := dbrunmocks.NewRunInterface()
+		dbRun.Impl.Get = func(ctx context.Context, runIds []string) (map[string]domain.Run, error) {
+			return map[string]domain.Run{
+				"run-done":      run("run-done", domain.Done, "data-1"),
+				"run-partial":   run("run-partial", domain.Done, "data-2", "data-not-expired"),
+				"run-running":   run("run-running", domain.Running, "data-3"),
+				"run-failed":    run("run-failed", domain.Failed, "data-4"),
+				"run-protected": run("run-protected", domain.Done, "data-5"),
+			}, nil
+		}
+		dbRun.Impl.Delete = func(ctx context.Context, runId string) error {
+			if runId == "run-protected" {
+				return domain.ErrRunHasDownstreams
+			}
+			return nil
+		}
+
+		before := time.Now()
+		_, deleted, err := retention.Task(dbData, dbRun, rules)(ctx, retention.Seed())
+		after := time.Now()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !deleted {
+			t.Error("deleted should be true")
+		}
+
+		if len(dbData.Calls.Find) != 2 {
+			t.Fatalf("Find is called %d times", len(dbData.Calls.Find))
+		}
+		for i, r := range rules {
+			call := dbData.Calls.Find[i]
+			if !cmp.SliceEq(call.Tags, r.Tags) || call.Since != nil || call.Until == nil {
+				t.Errorf("unexpected Find call: %+v", call)
+				continue
+			}
+			if call.Until.Before(before.Add(-r.Age)) || call.Until.After(after.Add(-r.Age)) {
+				t.Errorf("until: %s is not %s before now", call.Until, r.Age)
+			}
+		}
+
+		want := []string{"run-done", "run-failed", "run-protected"}
+		if !cmp.SliceContentEq(dbRun.Calls.Delete, want) {
+			t.Errorf("deleted runs: %v, want %v", dbRun.Calls.Delete, want)
+		}
+	})
+
+	t.Run("it does nothing when nothing is expired", func(t *testing.T) {
+		ctx := context.Background()
+
+		dbData := dbdatamocks.NewDataInterface()
+		dbData.Impl.Find = func(ctx context.Context, tags []domain.Tag, since, until *time.Time, page domain.Page) ([]string, string, error) {
+			return []string{}, "", nil
+		}
+		dbRun := dbrunmocks.NewRunInterface()
+
+		_, deleted, err := retention.Task(dbData, dbRun, rules)(ctx, retention.Seed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted {
+			t.Error("deleted should be false")
+		}
+		if len(dbData.Calls.Get) != 0 || len(dbRun.Calls.Get) != 0 {
+			t.Error("data and runs should not be retrieved")
+		}
+	})
+
+	t.Run("it does nothing without rules", func(t *testing.T) {
+		ctx := context.Background()
+
+		dbData := dbdatamocks.NewDataInterface()
+		dbRun := dbrunmocks.NewRunInterface()
+
+		_, deleted, err := retention.Task(dbData, dbRun, nil)(ctx, retention.Seed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted {
+			t.Error("deleted should be false")
+		}
+	})
+
+	t.Run("it returns an error when deleting run fails unexpectedly", func(t *testing.T) {
+		ctx := context.Background()
+
+		dbData := dbdatamocks.NewDataInterface()
+		dbData.Impl.Find = func(ctx context.Context, tags []domain.Tag, since, until *time.Time, page domain.Page) ([]string, string, error) {
+			return []string{"data-1"}, "", nil
+		}
+		dbData.Impl.Get = func(ctx context.Context, knitIds []string) (map[string]domain.KnitData, error) {
+			return map[string]domain.KnitData{"data-1": data("data-1", "run-1")}, nil
+		}
+		dbRun := dbrunmocks.NewRunInterface()
+		dbRun.Impl.Get = func(ctx context.Context, runIds []string) (map[string]domain.Run, error) {
+			return map[string]domain.Run{"run-1": run("run-1", domain.Done, "data-1")}, nil
+		}
+		expectedErr := errors.New("fake error")
+		dbRun.Impl.Delete = func(ctx context.Context, runId string) error {
+			return expectedErr
+		}
+
+		_, _, err := retention.Task(dbData, dbRun, rules)(ctx, retention.Seed())
+		if !errors.Is(err, expectedErr) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
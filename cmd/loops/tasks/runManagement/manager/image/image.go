@@ -2,6 +2,7 @@ package image
 
 import (
 	"context"
+	"strings"
 
 	manager "github.com/opst/knitfab/cmd/loops/tasks/runManagement/manager"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/runManagementHook"
@@ -10,14 +11,38 @@ import (
 	"github.com/opst/knitfab/pkg/domain/knitfab/k8s/cluster"
 	"github.com/opst/knitfab/pkg/domain/run/db"
 	"github.com/opst/knitfab/pkg/domain/run/k8s"
+	kw "github.com/opst/knitfab/pkg/domain/run/k8s/worker"
+	"github.com/opst/knitfab/pkg/utils/slices"
 	kubeerr "k8s.io/apimachinery/pkg/api/errors"
 )
 
+// ReasonConcurrency is the hold reason of Runs kept ready by concurrency limits or priority.
+const ReasonConcurrency = "concurrency limit is reached, or runs with higher priority are waiting"
+
 // Returns a manager for starting a worker for a run.
+//
+// Ready Runs are kept ready while they cannot be started
+// because of concurrency limits or priority (see db.Interface.Startable),
+// or because quotas would be exceeded (see db.Interface.ExceededQuotas).
+// The reason is recorded as the hold reason of the Run.
+//
+// Once the worker reports the image ID which it runs, it is recorded as the image ID of the Run.
+//
+// maxConcurrentRuns is the max number of Runs being starting or running
+// in the whole of knitfab. 0 means unlimited.
 func New(
 	iK8sRun k8s.Interface,
 	iDBRun db.Interface,
+	maxConcurrentRuns int,
+	quotas []types.Quota,
 ) manager.Manager {
+	hold := func(ctx context.Context, r types.Run, reason string) error {
+		if r.HoldReason == reason {
+			return nil
+		}
+		return iDBRun.SetHoldReason(ctx, r.Id, reason)
+	}
+
 	return func(
 		ctx context.Context,
 		hooks runManagementHook.Hooks,
@@ -33,7 +58,32 @@ func New(
 			}
 
 			if r.Status == types.Ready {
-				resp, err := hooks.ToStarting.Before(bindruns.ComposeDetail(r))
+				startable, err := iDBRun.Startable(ctx, r.Id, maxConcurrentRuns)
+				if err != nil {
+					return r.Status, err
+				}
+				if !startable {
+					return r.Status, hold(ctx, r, ReasonConcurrency)
+				}
+
+				if 0 < len(quotas) {
+					exceeded, err := iDBRun.ExceededQuotas(ctx, r.Id, quotas)
+					if err != nil {
+						return r.Status, err
+					}
+					if 0 < len(exceeded) {
+						reason := strings.Join(
+							slices.Map(exceeded, types.QuotaExceeded.String), "; ",
+						)
+						return r.Status, hold(ctx, r, reason)
+					}
+				}
+
+				if err := hold(ctx, r, ""); err != nil {
+					return r.Status, err
+				}
+
+				resp, err := hooks.ToStarting.Before(ctx, bindruns.ComposeDetail(r))
 				if err != nil {
 					return r.Status, err
 				}
@@ -43,7 +93,7 @@ func New(
 				return types.Starting, nil
 			}
 
-			if _, err := hooks.ToAborting.Before(bindruns.ComposeDetail(r)); err != nil {
+			if _, err := hooks.ToAborting.Before(ctx, bindruns.ComposeDetail(r)); err != nil {
 				return r.Status, err
 			}
 			if err := iDBRun.SetExit(ctx, r.Id, types.RunExit{
@@ -59,12 +109,19 @@ func New(
 
 		s := w.JobStatus(ctx)
 
+		if id := s.ImageIDs[kw.MainContainer]; id != "" && id != r.ImageId {
+			if err := iDBRun.SetImageId(ctx, r.Id, id); err != nil {
+				return r.Status, err
+			}
+			r.ImageId = id
+		}
+
 		switch ty := s.Type; ty {
 		case cluster.Pending:
 			newStatus = types.Starting
 		case cluster.Running:
 			newStatus = types.Running
-		case cluster.Failed, cluster.Stucking:
+		case cluster.Failed, cluster.Stucking, cluster.DeadlineExceeded:
 			newStatus = types.Aborting
 		case cluster.Succeeded:
 			newStatus = types.Completing
@@ -81,16 +138,16 @@ func New(
 		case types.Starting:
 			// ignore. Since worker is already started, it should not be started again.
 		case types.Running:
-			if _, err := hooks.ToRunning.Before(bindruns.ComposeDetail(r)); err != nil {
+			if _, err := hooks.ToRunning.Before(ctx, bindruns.ComposeDetail(r)); err != nil {
 				return r.Status, err
 			}
 		case types.Aborting, types.Completing:
 			if newStatus == types.Completing {
-				if _, err := hooks.ToCompleting.Before(bindruns.ComposeDetail(r)); err != nil {
+				if _, err := hooks.ToCompleting.Before(ctx, bindruns.ComposeDetail(r)); err != nil {
 					return r.Status, err
 				}
 			} else {
-				if _, err := hooks.ToAborting.Before(bindruns.ComposeDetail(r)); err != nil {
+				if _, err := hooks.ToAborting.Before(ctx, bindruns.ComposeDetail(r)); err != nil {
 					return r.Status, err
 				}
 			}
@@ -99,6 +156,14 @@ func New(
 				Code:    s.Code,
 				Message: s.Message,
 			}
+			switch s.Type {
+			case cluster.Failed:
+				exit.Cause = types.ExitByFailure
+			case cluster.Stucking:
+				exit.Cause = types.ExitByStucking
+			case cluster.DeadlineExceeded:
+				exit.Cause = types.ExitByTimeout
+			}
 			if err := iDBRun.SetExit(ctx, r.Id, exit); err != nil {
 				return r.Status, err
 			}
@@ -7,7 +7,6 @@ import (
 	"testing"
 
 	apiruns "github.com/opst/knitfab-api-types/runs"
-	"github.com/opst/knitfab/cmd/loops/hook"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/manager/image"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/runManagementHook"
 	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
@@ -16,8 +15,10 @@ import (
 	"github.com/opst/knitfab/pkg/domain/run/db/mock"
 	k8sRunMocks "github.com/opst/knitfab/pkg/domain/run/k8s/mock"
 	kw "github.com/opst/knitfab/pkg/domain/run/k8s/worker"
+	"github.com/opst/knitfab/pkg/hook"
 	"github.com/opst/knitfab/pkg/utils/cmp"
 	kubeerr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	kubeshm "k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -55,6 +56,9 @@ func TestManager_GetWorkerHasFailed(t *testing.T) {
 		errSetExit     error
 		errGetWorker   error
 		errStartWorker error
+		notStartable   bool
+		quotas         []domain.Quota
+		exceeded       []domain.QuotaExceeded
 
 		respBeforeToStartingHook runManagementHook.HookResponse
 		errBeforeHook            error
@@ -68,6 +72,7 @@ func TestManager_GetWorkerHasFailed(t *testing.T) {
 
 		wantSetExitInvoked     bool
 		wantStartWorkerInvoked bool
+		wantHoldReason         string
 	}
 
 	theory := func(when When, then Then) func(t *testing.T) {
@@ -106,8 +111,28 @@ func TestManager_GetWorkerHasFailed(t *testing.T) {
 				}
 				return when.errSetExit
 			}
+			iDBRunMock.Impl.Startable = func(_ context.Context, runId string, maxConcurrentRuns int) (bool, error) {
+				if runId != when.run.Id {
+					t.Errorf("got runId %v, want %v", runId, when.run.Id)
+				}
+				return !when.notStartable, nil
+			}
+			iDBRunMock.Impl.ExceededQuotas = func(_ context.Context, runId string, quotas []domain.Quota) ([]domain.QuotaExceeded, error) {
+				if runId != when.run.Id {
+					t.Errorf("got runId %v, want %v", runId, when.run.Id)
+				}
+				return when.exceeded, nil
+			}
+			holdReason := when.run.HoldReason
+			iDBRunMock.Impl.SetHoldReason = func(_ context.Context, runId string, reason string) error {
+				if runId != when.run.Id {
+					t.Errorf("got runId %v, want %v", runId, when.run.Id)
+				}
+				holdReason = reason
+				return nil
+			}
 
-			testee := image.New(iK8sRunMock, iDBRunMock)
+			testee := image.New(iK8sRunMock, iDBRunMock, 0, when.quotas)
 
 			beforeToStartingHookInvoked := false
 			beforeToRunningHookInvoked := false
@@ -208,6 +233,10 @@ func TestManager_GetWorkerHasFailed(t *testing.T) {
 			if !errors.Is(gotError, then.wantError) {
 				t.Errorf("got error %v, want %v", gotError, then.wantError)
 			}
+
+			if holdReason != then.wantHoldReason {
+				t.Errorf("got hold reason %q, want %q", holdReason, then.wantHoldReason)
+			}
 		}
 	}
 
@@ -283,6 +312,120 @@ func TestManager_GetWorkerHasFailed(t *testing.T) {
 		))
 	}
 
+	{
+		t.Run("when getWorker for Ready Run returns NotFound error but the run cannot be started, it should keep the run ready", theory(
+			When{
+				run: domain.Run{
+					RunBody: domain.RunBody{
+						Id:         "run/ready",
+						Status:     domain.Ready,
+						WorkerName: "worker/ready",
+						PlanBody: domain.PlanBody{
+							PlanId: "plan/ready",
+							Image: &domain.ImageIdentifier{
+								Image:   "example.repo.invalid/ready",
+								Version: "v1.0.0",
+							},
+						},
+					},
+				},
+				errGetWorker: kubeerr.NewNotFound(
+					kubeshm.GroupResource{Resource: "job"}, "worker/ready",
+				),
+				notStartable: true,
+			},
+			Then{
+				wantStatus:             domain.Ready,
+				wantError:              nil,
+				wantBeforeHookInvoked:  false,
+				wantSetExitInvoked:     false,
+				wantStartWorkerInvoked: false,
+				wantHoldReason:         image.ReasonConcurrency,
+			},
+		))
+	}
+
+	{
+		quotas := []domain.Quota{
+			{
+				Name:   "research",
+				Limits: map[string]resource.Quantity{"nvidia.com/gpu": resource.MustParse("2")},
+			},
+		}
+		t.Run("when getWorker for Ready Run returns NotFound error but quotas are exhausted, it should keep the run ready with the reason", theory(
+			When{
+				run: domain.Run{
+					RunBody: domain.RunBody{
+						Id:         "run/ready",
+						Status:     domain.Ready,
+						WorkerName: "worker/ready",
+						PlanBody: domain.PlanBody{
+							PlanId: "plan/ready",
+							Image: &domain.ImageIdentifier{
+								Image:   "example.repo.invalid/ready",
+								Version: "v1.0.0",
+							},
+						},
+					},
+				},
+				errGetWorker: kubeerr.NewNotFound(
+					kubeshm.GroupResource{Resource: "job"}, "worker/ready",
+				),
+				quotas: quotas,
+				exceeded: []domain.QuotaExceeded{
+					{
+						Quota:     "research",
+						Resource:  "nvidia.com/gpu",
+						Limit:     resource.MustParse("2"),
+						Used:      resource.MustParse("2"),
+						Requested: resource.MustParse("1"),
+					},
+				},
+			},
+			Then{
+				wantStatus:             domain.Ready,
+				wantError:              nil,
+				wantBeforeHookInvoked:  false,
+				wantSetExitInvoked:     false,
+				wantStartWorkerInvoked: false,
+				wantHoldReason:         "quota research: nvidia.com/gpu 1 requested, but 2 of 2 is in use",
+			},
+		))
+
+		t.Run("when getWorker for Ready Run held by quotas returns NotFound error and quotas are available, it should start the run and clear the reason", theory(
+			When{
+				run: domain.Run{
+					RunBody: domain.RunBody{
+						Id:         "run/ready",
+						Status:     domain.Ready,
+						WorkerName: "worker/ready",
+						HoldReason: "quota research: nvidia.com/gpu 1 requested, but 2 of 2 is in use",
+						PlanBody: domain.PlanBody{
+							PlanId: "plan/ready",
+							Image: &domain.ImageIdentifier{
+								Image:   "example.repo.invalid/ready",
+								Version: "v1.0.0",
+							},
+						},
+					},
+				},
+				errGetWorker: kubeerr.NewNotFound(
+					kubeshm.GroupResource{Resource: "job"}, "worker/ready",
+				),
+				quotas:   quotas,
+				exceeded: []domain.QuotaExceeded{},
+			},
+			Then{
+				wantStatus:             domain.Starting,
+				wantError:              nil,
+				wantBeforeHookInvoked:  true,
+				wantSetExitInvoked:     false,
+				wantStartWorkerInvoked: true,
+				wantHoldReason:         "",
+			},
+		))
+	}
+
 	{
 		wantErr := errors.New("unexpected error")
 		t.Run("when setExit returns unexpected error, it should return the error", theory(
@@ -510,6 +653,14 @@ func TestManager_GetWorkerSucceeded(t *testing.T) {
 					Code:    when.jobStatus.Code,
 					Message: when.jobStatus.Message,
 				}
+				switch when.jobStatus.Type {
+				case cluster.Failed:
+					want.Cause = domain.ExitByFailure
+				case cluster.Stucking:
+					want.Cause = domain.ExitByStucking
+				case cluster.DeadlineExceeded:
+					want.Cause = domain.ExitByTimeout
+				}
 				if exit != want {
 					t.Errorf("got exit %v, want %v", exit, want)
 				}
@@ -579,7 +730,7 @@ func TestManager_GetWorkerSucceeded(t *testing.T) {
 				},
 			}
 
-			testee := image.New(iK8sRunMock, iDBRunMock)
+			testee := image.New(iK8sRunMock, iDBRunMock, 0, nil)
 			gotStatus, gotError := testee(ctx, hooks, run)
 
 			if setExitInvoked != then.wantSetExitInvoked {
@@ -775,6 +926,20 @@ func TestManager_GetWorkerSucceeded(t *testing.T) {
 
 	}
 
+	t.Run("When worker has exceeded its deadline, it translate run status to aborting", theory(
+		When{
+			runStatus: domain.Running,
+			jobStatus: cluster.JobStatus{Type: cluster.DeadlineExceeded, Code: cluster.ExitCodeDeadlineExceeded, Message: "DeadlineExceeded"},
+		},
+		Then{
+			wantBeforeHookInvoked: true,
+			wantSetExitInvoked:    true,
+
+			wantStatus: domain.Aborting,
+			wantErr:    nil,
+		},
+	))
+
 	{
 		wantErr := errors.New("unexpected error")
 		t.Run("When worker for Starting Run is Stucked and Before hook returns an error, it should return the error", theory(
@@ -824,3 +989,90 @@ func TestManager_GetWorkerSucceeded(t *testing.T) {
 		))
 	}
 }
+
+func TestManager_RecordsImageId(t *testing.T) {
+	type When struct {
+		imageId  string
+		imageIDs map[string]string
+	}
+	type Then struct {
+		wantImageIds []string
+	}
+
+	theory := func(when When, then Then) func(t *testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+
+			run := domain.Run{
+				RunBody: domain.RunBody{
+					Id:         "run/example",
+					Status:     domain.Running,
+					WorkerName: "worker/example",
+					ImageId:    when.imageId,
+					PlanBody: domain.PlanBody{
+						PlanId: "plan/example",
+						Image: &domain.ImageIdentifier{
+							Image:   "example.repo.invalid/running",
+							Version: "v1.0.0",
+						},
+					},
+				},
+			}
+
+			iK8sRunMock := k8sRunMocks.New(t)
+			iK8sRunMock.Impl.FindWorker = func(context.Context, domain.RunBody) (kw.Worker, error) {
+				return &FakeWorker{
+					runId:     run.Id,
+					jobStatus: cluster.JobStatus{Type: cluster.Running, ImageIDs: when.imageIDs},
+				}, nil
+			}
+
+			iDBRunMock := mock.NewRunInterface()
+			iDBRunMock.Impl.SetImageId = func(_ context.Context, runId string, imageId string) error {
+				if runId != run.Id {
+					t.Errorf("got runId %v, want %v", runId, run.Id)
+				}
+				return nil
+			}
+
+			testee := image.New(iK8sRunMock, iDBRunMock, 0, nil)
+			gotStatus, err := testee(ctx, runManagementHook.Hooks{}, run)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotStatus != domain.Running {
+				t.Errorf("got status %v, want %v", gotStatus, domain.Running)
+			}
+
+			got := []string{}
+			for _, c := range iDBRunMock.Calls.SetImageId {
+				got = append(got, c.ImageId)
+			}
+			if !cmp.SliceEq(got, then.wantImageIds) {
+				t.Errorf("SetImageId: got %v, want %v", got, then.wantImageIds)
+			}
+		}
+	}
+
+	imageId := "example.repo.invalid/running@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	t.Run("When the worker reports the image ID of the main container, it is recorded", theory(
+		When{
+			imageIDs: map[string]string{kw.MainContainer: imageId, "nurse": "example.repo.invalid/nurse@sha256:0000"},
+		},
+		Then{wantImageIds: []string{imageId}},
+	))
+
+	t.Run("When the image ID is recorded already, it is not recorded again", theory(
+		When{
+			imageId:  imageId,
+			imageIDs: map[string]string{kw.MainContainer: imageId},
+		},
+		Then{wantImageIds: []string{}},
+	))
+
+	t.Run("When the worker does not report the image ID, nothing is recorded", theory(
+		When{},
+		Then{wantImageIds: []string{}},
+	))
+}
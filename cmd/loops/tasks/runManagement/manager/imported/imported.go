@@ -23,7 +23,7 @@ func New() manager.Manager {
 		// Imported Runs comes here are expired its `"lifecycle_suspend_until"`.
 		// They should be aborted.
 		if r.Status == domain.Running {
-			if _, err := hooks.ToAborting.Before(bindruns.ComposeDetail(r)); err != nil {
+			if _, err := hooks.ToAborting.Before(ctx, bindruns.ComposeDetail(r)); err != nil {
 				return r.Status, err
 			}
 			return domain.Aborting, nil
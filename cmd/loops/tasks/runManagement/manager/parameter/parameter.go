@@ -0,0 +1,161 @@
+package parameter
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/manager"
+	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/runManagementHook"
+	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
+	"github.com/opst/knitfab/pkg/domain"
+	kdbdata "github.com/opst/knitfab/pkg/domain/data/db"
+	k8sdata "github.com/opst/knitfab/pkg/domain/data/k8s"
+	"github.com/opst/knitfab/pkg/utils/archive"
+)
+
+const PLAN_NAME = domain.Parameter
+
+// New returns a Manager for Runs of the pseudo plan "knit#parameter".
+//
+// The Manager writes the parameter value into the output Data of the Run
+// as a file named "value", then makes the Run completing.
+//
+// When the Data has content already (written in the last try),
+// the Manager verifies it with its checksum.
+// If it is not the value, the Run is aborted.
+func New(
+	dbdata kdbdata.DataInterface,
+	k8sdata k8sdata.Interface,
+	client *http.Client,
+) manager.Manager {
+	return func(
+		ctx context.Context,
+		hooks runManagementHook.Hooks,
+		r domain.Run,
+	) (
+		domain.KnitRunStatus,
+		error,
+	) {
+		if pp := r.RunBody.PlanBody.Pseudo; pp != nil && pp.Name != PLAN_NAME {
+			return r.Status, nil
+		}
+		if r.Status != domain.Running {
+			return r.Status, nil
+		}
+
+		if len(r.Outputs) != 1 {
+			return r.Status, fmt.Errorf(
+				"plan %s requires %d data, not 1", PLAN_NAME, len(r.Outputs),
+			)
+		}
+		knitId := r.Outputs[0].KnitDataBody.KnitId
+
+		value, err := dbdata.GetParameterValue(ctx, knitId)
+		if err != nil {
+			return r.Status, err
+		}
+		payload, err := Archive(value)
+		if err != nil {
+			return r.Status, err
+		}
+
+		deadline := time.Now().Add(20 * time.Second)
+		daRecord, err := dbdata.NewAgent(
+			ctx, knitId, domain.DataAgentWrite, time.Until(deadline),
+		)
+		if err != nil {
+			return r.Status, err
+		}
+
+		da, err := k8sdata.SpawnDataAgent(ctx, daRecord, deadline)
+		if err != nil {
+			return r.Status, err
+		}
+		defer func() {
+			if err := da.Close(); err != nil {
+				return
+			}
+			dbdata.RemoveAgent(ctx, daRecord.Name)
+		}()
+
+		req, err := http.NewRequestWithContext(
+			ctx, http.MethodPost, da.URL(), bytes.NewReader(payload),
+		)
+		if err != nil {
+			return r.Status, err
+		}
+		req.Header.Set("Content-Type", "application/tar+gzip")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return r.Status, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusConflict {
+			// something has been written in the last try. Is it the value?
+			expected, err := Checksum(value)
+			if err != nil {
+				return r.Status, err
+			}
+			if resp.Header.Get("x-checksum") != expected {
+				if _, err := hooks.ToAborting.Before(ctx, bindruns.ComposeDetail(r)); err != nil {
+					return r.Status, err
+				}
+				return domain.Aborting, nil
+			}
+		} else if resp.StatusCode < 200 || 300 <= resp.StatusCode {
+			return r.Status, fmt.Errorf(
+				"failed to write parameter to data %s: status %d", knitId, resp.StatusCode,
+			)
+		}
+
+		if _, err := hooks.ToCompleting.Before(ctx, bindruns.ComposeDetail(r)); err != nil {
+			return r.Status, err
+		}
+		return domain.Completing, nil
+	}
+}
+
+// Checksum returns the checksum of the content of Data written with Archive(value).
+func Checksum(value string) (string, error) {
+	c := archive.NewChecksum()
+	if err := c.AddFile(domain.ParameterFileName, strings.NewReader(value)); err != nil {
+		return "", err
+	}
+	return c.Sum(), nil
+}
+
+// Archive creates a tar.gz archive containing a file "value" with the value.
+func Archive(value string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	content := []byte(value)
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     domain.ParameterFileName,
+		Mode:     0o644,
+		Size:     int64(len(content)),
+		ModTime:  time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,200 @@
+package parameter_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apiruns "github.com/opst/knitfab-api-types/runs"
+	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/manager/parameter"
+	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/runManagementHook"
+	"github.com/opst/knitfab/pkg/domain"
+	dbdatamocks "github.com/opst/knitfab/pkg/domain/data/db/mock"
+	"github.com/opst/knitfab/pkg/domain/data/k8s/dataagt"
+	k8sdatamocks "github.com/opst/knitfab/pkg/domain/data/k8s/mock"
+	"github.com/opst/knitfab/pkg/domain/knitfab/k8s/cluster"
+	"github.com/opst/knitfab/pkg/hook"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+type mockDataAgent struct {
+	url    string
+	closed bool
+}
+
+func (m *mockDataAgent) Name() string               { return "agent" }
+func (m *mockDataAgent) APIPort() int32             { return 8080 }
+func (m *mockDataAgent) URL() string                { return m.url }
+func (m *mockDataAgent) Mode() domain.DataAgentMode { return domain.DataAgentWrite }
+func (m *mockDataAgent) KnitID() string             { return "knit-out" }
+func (m *mockDataAgent) VolumeRef() string          { return "pvc-knit-out" }
+func (m *mockDataAgent) PodPhase() cluster.PodPhase { return cluster.PodRunning }
+func (m *mockDataAgent) String() string             { return "mockDataAgent" }
+func (m *mockDataAgent) Close() error               { m.closed = true; return nil }
+
+var _ dataagt.DataAgent = &mockDataAgent{}
+
+func TestManager(t *testing.T) {
+	given := domain.Run{
+		RunBody: domain.RunBody{
+			Id:     "run-id",
+			Status: domain.Running,
+			PlanBody: domain.PlanBody{
+				Pseudo: &domain.PseudoPlanDetail{Name: domain.Parameter},
+			},
+		},
+		Outputs: []domain.Assignment{
+			{
+				MountPoint:   domain.MountPoint{Path: "/parameter", Id: 1},
+				KnitDataBody: domain.KnitDataBody{KnitId: "knit-out"},
+			},
+		},
+	}
+
+	type When struct {
+		agentStatus int
+
+		// checksum of the content existing already. used when agentStatus is Conflict.
+		existing string
+
+		errBeforeHook error
+	}
+	type Then struct {
+		status        domain.KnitRunStatus
+		wantErr       bool
+		invokedBefore bool
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			var written string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("unexpected method: %s", r.Method)
+				}
+				gz, err := gzip.NewReader(r.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tr := tar.NewReader(gz)
+				h, err := tr.Next()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if h.Name != domain.ParameterFileName {
+					t.Errorf("unexpected file name: %s", h.Name)
+				}
+				content, err := io.ReadAll(tr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				written = string(content)
+				if when.existing != "" {
+					w.Header().Set("x-checksum", when.existing)
+				}
+				w.WriteHeader(when.agentStatus)
+			}))
+			defer server.Close()
+
+			dbdata := dbdatamocks.NewDataInterface()
+			dbdata.Impl.GetParameterValue = func(ctx context.Context, knitId string) (string, error) {
+				if knitId != "knit-out" {
+					t.Errorf("unexpected knitId: %s", knitId)
+				}
+				return "0.01", nil
+			}
+			dbdata.Impl.NewAgent = func(
+				ctx context.Context, knitId string, mode domain.DataAgentMode, _ time.Duration,
+			) (domain.DataAgent, error) {
+				if mode != domain.DataAgentWrite {
+					t.Errorf("unexpected mode: %s", mode)
+				}
+				return domain.DataAgent{
+					Name: "agent", Mode: mode,
+					KnitDataBody: domain.KnitDataBody{KnitId: knitId},
+				}, nil
+			}
+			dbdata.Impl.RemoveAgent = func(ctx context.Context, name string) error {
+				return nil
+			}
+
+			agent := &mockDataAgent{url: server.URL}
+			k8sdata := k8sdatamocks.New(t)
+			k8sdata.Impl.SpawnDataAgent = func(
+				ctx context.Context, d domain.DataAgent, pendingDeadline time.Time,
+			) (dataagt.DataAgent, error) {
+				return agent, nil
+			}
+
+			invokedBefore := false
+			before := func(d apiruns.Detail) (struct{}, error) {
+				invokedBefore = true
+				return struct{}{}, when.errBeforeHook
+			}
+			hooks := runManagementHook.Hooks{
+				ToCompleting: hook.Func[apiruns.Detail, struct{}]{BeforeFn: before},
+				ToAborting:   hook.Func[apiruns.Detail, struct{}]{BeforeFn: before},
+			}
+
+			testee := parameter.New(dbdata, k8sdata, server.Client())
+			status, err := testee(context.Background(), hooks, given)
+
+			if then.wantErr {
+				if err == nil {
+					t.Errorf("expected error, but nil")
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if status != then.status {
+				t.Errorf("status: got %s, want %s", status, then.status)
+			}
+			if invokedBefore != then.invokedBefore {
+				t.Errorf("before hook invoked: got %v, want %v", invokedBefore, then.invokedBefore)
+			}
+			if written != "0.01" {
+				t.Errorf("written value: got %q", written)
+			}
+			if !agent.closed {
+				t.Errorf("data agent is not closed")
+			}
+		}
+	}
+
+	t.Run("when the value is written, it makes the run completing", theory(
+		When{agentStatus: http.StatusNoContent},
+		Then{status: domain.Completing, invokedBefore: true},
+	))
+	t.Run("when the value has been written already, it makes the run completing", theory(
+		When{
+			agentStatus: http.StatusConflict,
+			existing:    try.To(parameter.Checksum("0.01")).OrFatal(t),
+		},
+		Then{status: domain.Completing, invokedBefore: true},
+	))
+	t.Run("when other content has been written already, it makes the run aborting", theory(
+		When{
+			agentStatus: http.StatusConflict,
+			existing:    try.To(parameter.Checksum("0.02")).OrFatal(t),
+		},
+		Then{status: domain.Aborting, invokedBefore: true},
+	))
+	t.Run("when the existing content is not verifiable, it makes the run aborting", theory(
+		When{agentStatus: http.StatusConflict},
+		Then{status: domain.Aborting, invokedBefore: true},
+	))
+	t.Run("when the data agent fails, it keeps the status and returns error", theory(
+		When{agentStatus: http.StatusInternalServerError},
+		Then{status: domain.Running, wantErr: true},
+	))
+	t.Run("when the before hook fails, it keeps the status and returns error", theory(
+		When{agentStatus: http.StatusNoContent, errBeforeHook: errors.New("fake error")},
+		Then{status: domain.Running, wantErr: true, invokedBefore: true},
+	))
+}
@@ -48,7 +48,7 @@ func New(dbdata kdbdata.DataInterface) manager.Manager {
 			}
 		}
 
-		if _, err := hooks.ToAborting.Before(bindruns.ComposeDetail(r)); err != nil {
+		if _, err := hooks.ToAborting.Before(ctx, bindruns.ComposeDetail(r)); err != nil {
 			return r.Status, err
 		}
 		return domain.Aborting, nil
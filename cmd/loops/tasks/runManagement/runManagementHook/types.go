@@ -2,7 +2,7 @@ package runManagementHook
 
 import (
 	apiruns "github.com/opst/knitfab-api-types/runs"
-	"github.com/opst/knitfab/cmd/loops/hook"
+	"github.com/opst/knitfab/pkg/hook"
 )
 
 type KnitfabExtension struct {
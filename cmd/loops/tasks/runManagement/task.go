@@ -11,6 +11,7 @@ import (
 	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
 	"github.com/opst/knitfab/pkg/domain"
 	kdbrun "github.com/opst/knitfab/pkg/domain/run/db"
+	"github.com/opst/knitfab/pkg/hook"
 )
 
 // Return initial RunCursor value for task
@@ -37,7 +38,7 @@ func Task(
 		nextCursor, statusChanged, err := irun.PickAndSetStatus(
 			ctx, value,
 			// The last Status set by PickAndSetStatus() is the return value of func() below.
-			func(r domain.Run) (domain.KnitRunStatus, error) {
+			func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
 
 				var newStatus domain.KnitRunStatus
 				var err error
@@ -53,6 +54,21 @@ func Task(
 				}
 				return newStatus, err
 			},
+			func(ctx context.Context, r domain.Run) error {
+				// queue "after" webhooks along with the status change.
+				hookValue := bindruns.ComposeDetail(r)
+				switch r.Status {
+				case domain.Starting:
+					return hook.Enqueue(ctx, hooks.ToStarting, hookValue)
+				case domain.Running:
+					return hook.Enqueue(ctx, hooks.ToRunning, hookValue)
+				case domain.Completing:
+					return hook.Enqueue(ctx, hooks.ToCompleting, hookValue)
+				case domain.Aborting:
+					return hook.Enqueue(ctx, hooks.ToAborting, hookValue)
+				}
+				return nil
+			},
 		)
 
 		if statusChanged {
@@ -61,13 +77,13 @@ func Task(
 					hookValue := bindruns.ComposeDetail(r)
 					switch r.Status {
 					case domain.Starting:
-						hooks.ToStarting.After(hookValue)
+						hooks.ToStarting.After(ctx, hookValue)
 					case domain.Running:
-						hooks.ToRunning.After(hookValue)
+						hooks.ToRunning.After(ctx, hookValue)
 					case domain.Completing:
-						hooks.ToCompleting.After(hookValue)
+						hooks.ToCompleting.After(ctx, hookValue)
 					case domain.Aborting:
-						hooks.ToAborting.After(hookValue)
+						hooks.ToAborting.After(ctx, hookValue)
 					}
 				}
 			}
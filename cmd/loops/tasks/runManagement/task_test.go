@@ -6,13 +6,13 @@ import (
 	"testing"
 
 	api_runs "github.com/opst/knitfab-api-types/runs"
-	"github.com/opst/knitfab/cmd/loops/hook"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/manager"
 	"github.com/opst/knitfab/cmd/loops/tasks/runManagement/runManagementHook"
 	bindruns "github.com/opst/knitfab/pkg/api-types-binding/runs"
 	types "github.com/opst/knitfab/pkg/domain"
 	kdbrunmock "github.com/opst/knitfab/pkg/domain/run/db/mock"
+	"github.com/opst/knitfab/pkg/hook"
 	"github.com/opst/knitfab/pkg/utils/cmp"
 )
 
@@ -43,7 +43,8 @@ func TestTask_Outside_of_PickAndSetStatus(t *testing.T) {
 			irun := kdbrunmock.NewRunInterface()
 			irun.Impl.PickAndSetStatus = func(
 				ctx context.Context, cursor types.RunCursor,
-				_ func(types.Run) (types.KnitRunStatus, error),
+				_ func(context.Context, types.Run) (types.KnitRunStatus, error),
+				_ func(context.Context, types.Run) error,
 			) (types.RunCursor, bool, error) {
 				if !cursor.Equal(when.cursorToBePassed) {
 					t.Errorf(
@@ -364,9 +365,10 @@ func TestTask_Inside_of_PickAndSetStatus(t *testing.T) {
 			irun := kdbrunmock.NewRunInterface()
 			irun.Impl.PickAndSetStatus = func(
 				ctx context.Context, _ types.RunCursor,
-				f func(types.Run) (types.KnitRunStatus, error),
+				f func(context.Context, types.Run) (types.KnitRunStatus, error),
+				_ func(context.Context, types.Run) error,
 			) (types.RunCursor, bool, error) {
-				state, err := f(when.pickedRun)
+				state, err := f(ctx, when.pickedRun)
 				if state != then.newStatus {
 					t.Errorf("state: actual=%+v, expect=%+v", state, then.newStatus)
 				}
@@ -390,17 +392,17 @@ func TestTask_Inside_of_PickAndSetStatus(t *testing.T) {
 
 				// this test interests whether "`hooks` should be passed from caller" or not.
 				// So, we don't need to check the new run status, and here ToRunning is hard coded.
-				hooks.ToRunning.Before(bindruns.ComposeDetail(when.pickedRun))
+				hooks.ToRunning.Before(context.Background(), bindruns.ComposeDetail(when.pickedRun))
 				return when.newStatus, when.managerError
 			}
 			pseudoManagers := map[types.PseudoPlanName]manager.Manager{
 				planName1: func(_ context.Context, hooks runManagementHook.Hooks, _ types.Run) (types.KnitRunStatus, error) {
-					hooks.ToRunning.Before(bindruns.ComposeDetail(when.pickedRun))
+					hooks.ToRunning.Before(context.Background(), bindruns.ComposeDetail(when.pickedRun))
 					invokedPseudoManager = append(invokedPseudoManager, planName1)
 					return when.newStatus, when.managerError
 				},
 				planName2: func(_ context.Context, hooks runManagementHook.Hooks, _ types.Run) (types.KnitRunStatus, error) {
-					hooks.ToRunning.Before(bindruns.ComposeDetail(when.pickedRun))
+					hooks.ToRunning.Before(context.Background(), bindruns.ComposeDetail(when.pickedRun))
 					invokedPseudoManager = append(invokedPseudoManager, planName2)
 					return when.newStatus, when.managerError
 				},
@@ -534,3 +536,102 @@ func TestTask_Inside_of_PickAndSetStatus(t *testing.T) {
 	}
 
 }
+
+func TestTask_Notify_of_PickAndSetStatus(t *testing.T) {
+	type When struct {
+		updatedRun types.Run
+		enqueueErr error
+	}
+	type Then struct {
+		// name of the hook which the Run is queued for. Empty if none.
+		enqueuedTo string
+		err        error
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			var errFromNotify error
+			irun := kdbrunmock.NewRunInterface()
+			irun.Impl.PickAndSetStatus = func(
+				ctx context.Context, cursor types.RunCursor,
+				_ func(context.Context, types.Run) (types.KnitRunStatus, error),
+				notify func(context.Context, types.Run) error,
+			) (types.RunCursor, bool, error) {
+				errFromNotify = notify(ctx, when.updatedRun)
+				return cursor, false, nil
+			}
+
+			enqueuedTo := []string{}
+			enqueue := func(name string) func(api_runs.Detail) error {
+				return func(d api_runs.Detail) error {
+					enqueuedTo = append(enqueuedTo, name)
+					if want := bindruns.ComposeDetail(when.updatedRun); !d.Equal(want) {
+						t.Errorf("hookValue: actual=%+v, expect=%+v", d, want)
+					}
+					return when.enqueueErr
+				}
+			}
+			testee := runManagement.Task(
+				irun, nil, nil,
+				runManagementHook.Hooks{
+					ToStarting: hook.Func[api_runs.Detail, runManagementHook.HookResponse]{
+						EnqueueFn: enqueue("starting"),
+					},
+					ToRunning:    hook.Func[api_runs.Detail, struct{}]{EnqueueFn: enqueue("running")},
+					ToCompleting: hook.Func[api_runs.Detail, struct{}]{EnqueueFn: enqueue("completing")},
+					ToAborting:   hook.Func[api_runs.Detail, struct{}]{EnqueueFn: enqueue("aborting")},
+				},
+			)
+
+			if _, _, err := testee(context.Background(), types.RunCursor{}); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := []string{}
+			if then.enqueuedTo != "" {
+				expected = []string{then.enqueuedTo}
+			}
+			if !cmp.SliceEq(enqueuedTo, expected) {
+				t.Errorf("enqueued to: actual=%v, expect=%v", enqueuedTo, expected)
+			}
+			if !errors.Is(errFromNotify, then.err) {
+				t.Errorf("err from notify: actual=%+v, expect=%+v", errFromNotify, then.err)
+			}
+		}
+	}
+
+	run := func(status types.KnitRunStatus) types.Run {
+		return types.Run{
+			RunBody: types.RunBody{
+				Id:     "some-run-id",
+				Status: status,
+				PlanBody: types.PlanBody{
+					PlanId: "some-plan-id",
+					Image:  &types.ImageIdentifier{Image: "repo.invalid/image", Version: "v1.0"},
+				},
+			},
+		}
+	}
+
+	for _, status := range []types.KnitRunStatus{
+		types.Starting, types.Running, types.Completing, types.Aborting,
+	} {
+		t.Run("when the Run gets "+status.String()+", it is queued for the hook", theory(
+			When{updatedRun: run(status)},
+			Then{enqueuedTo: status.String()},
+		))
+	}
+
+	t.Run("when the Run gets ready, it is not queued", theory(
+		When{updatedRun: run(types.Ready)},
+		Then{},
+	))
+
+	{
+		fakeErr := errors.New("fake error")
+		t.Run("when queueing fails, the error is returned to roll back the status change", theory(
+			When{updatedRun: run(types.Running), enqueueErr: fakeErr},
+			Then{enqueuedTo: "running", err: fakeErr},
+		))
+	}
+}
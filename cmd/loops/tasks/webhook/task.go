@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/opst/knitfab/cmd/loops/loop/recurring"
+	cfg_hook "github.com/opst/knitfab/pkg/configs/hook"
+	"github.com/opst/knitfab/pkg/domain"
+	kdbwebhook "github.com/opst/knitfab/pkg/domain/webhook/db"
+	"github.com/opst/knitfab/pkg/hook"
+)
+
+// initial value for task
+func Seed() struct{} {
+	return struct{}{}
+}
+
+// return:
+//
+// - task : delivering a webhook call queued in the outbox.
+// Failed deliveries are retried with the retry policy of their hook configuration.
+func Task(logger *log.Logger, dbwebhook kdbwebhook.WebhookInterface, hooks cfg_hook.Config) recurring.Task[struct{}] {
+	deliver := func(ctx context.Context) func(domain.WebhookDelivery) error {
+		return func(d domain.WebhookDelivery) error {
+			cfg, ok := hooks.WebHook(d.Hook)
+			if !ok {
+				return fmt.Errorf("unknown hook: %s", d.Hook)
+			}
+			return hook.Sender{Secret: cfg.Secret, Timeout: cfg.Timeout}.Deliver(ctx, d.URL, d.Payload, d.Id)
+		}
+	}
+	retry := func(d domain.WebhookDelivery) (time.Duration, bool) {
+		cfg, ok := hooks.WebHook(d.Hook)
+		if !ok {
+			return 0, false
+		}
+		return cfg.Retry.Wait(d.Attempts)
+	}
+
+	return func(ctx context.Context, value struct{}) (struct{}, bool, error) {
+		d, err := dbwebhook.Deliver(ctx, deliver(ctx), retry)
+		if err != nil {
+			return value, false, err
+		}
+		if d == nil {
+			logger.Printf("nothing to deliver.")
+			return value, false, nil
+		}
+
+		switch {
+		case d.LastError == "":
+			logger.Printf("delivered: #%d (%s) -> %s", d.Id, d.Hook, d.URL)
+		case d.Status == domain.WebhookDeliveryFailed:
+			logger.Printf("gave up: #%d (%s) -> %s after %d attempts: %s", d.Id, d.Hook, d.URL, d.Attempts, d.LastError)
+		default:
+			logger.Printf("will retry: #%d (%s) -> %s at %s: %s", d.Id, d.Hook, d.URL, d.NextAttemptAt, d.LastError)
+		}
+		return value, true, nil
+	}
+}
@@ -0,0 +1,127 @@
+package webhook_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/cmd/loops/tasks/webhook"
+	cfg_hook "github.com/opst/knitfab/pkg/configs/hook"
+	"github.com/opst/knitfab/pkg/domain"
+	dbwebhookmocks "github.com/opst/knitfab/pkg/domain/webhook/db/mock"
+	"github.com/opst/knitfab/pkg/hook"
+)
+
+func TestTask(t *testing.T) {
+	hooks := cfg_hook.Config{
+		Lifecycle: cfg_hook.WebHook{
+			Secret:  "s3cr3t",
+			Timeout: time.Second,
+			Retry:   cfg_hook.RetryPolicy{MaxAttempts: 3, Backoff: time.Second},
+		},
+	}
+	logger := log.New(new(bytes.Buffer), "", 0)
+
+	t.Run("it delivers a queued payload with signature", func(t *testing.T) {
+		verified := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buf := new(bytes.Buffer)
+			buf.ReadFrom(r.Body)
+			verified = hook.Verify("s3cr3t", r.Header, buf.Bytes()) &&
+				r.Header.Get(hook.HeaderDelivery) == "7"
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		dbwebhook := dbwebhookmocks.NewWebhookInterface()
+		dbwebhook.Impl.Deliver = func(
+			ctx context.Context,
+			deliver func(domain.WebhookDelivery) error,
+			retry func(domain.WebhookDelivery) (time.Duration, bool),
+		) (*domain.WebhookDelivery, error) {
+			d := domain.WebhookDelivery{
+				Id: 7, Hook: cfg_hook.LifecycleHooks, URL: server.URL, Payload: []byte(`{}`),
+				Status: domain.WebhookDeliveryPending,
+			}
+			if err := deliver(d); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			d.Attempts = 1
+			return &d, nil
+		}
+
+		testee := webhook.Task(logger, dbwebhook, hooks)
+		_, next, err := testee(context.Background(), webhook.Seed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !next {
+			t.Error("it should continue when it delivered something")
+		}
+		if !verified {
+			t.Error("delivery is not verified")
+		}
+	})
+
+	t.Run("it retries with the policy of the hook", func(t *testing.T) {
+		dbwebhook := dbwebhookmocks.NewWebhookInterface()
+		dbwebhook.Impl.Deliver = func(
+			ctx context.Context,
+			deliver func(domain.WebhookDelivery) error,
+			retry func(domain.WebhookDelivery) (time.Duration, bool),
+		) (*domain.WebhookDelivery, error) {
+			for attempts, want := range map[int]struct {
+				wait time.Duration
+				ok   bool
+			}{
+				1: {wait: time.Second, ok: true},
+				2: {wait: 2 * time.Second, ok: true},
+				3: {ok: false},
+			} {
+				wait, ok := retry(domain.WebhookDelivery{Hook: cfg_hook.LifecycleHooks, Attempts: attempts})
+				if ok != want.ok || (ok && wait != want.wait) {
+					t.Errorf("attempts = %d: got (%s, %v)", attempts, wait, ok)
+				}
+			}
+
+			if _, ok := retry(domain.WebhookDelivery{Hook: "unknown-hooks", Attempts: 1}); ok {
+				t.Error("delivery of unknown hook should not be retried")
+			}
+			if err := deliver(domain.WebhookDelivery{Hook: "unknown-hooks"}); err == nil {
+				t.Error("delivery of unknown hook should fail")
+			}
+			return nil, nil
+		}
+
+		testee := webhook.Task(logger, dbwebhook, hooks)
+		_, next, err := testee(context.Background(), webhook.Seed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next {
+			t.Error("it should not continue when nothing is delivered")
+		}
+	})
+
+	t.Run("it returns error from database", func(t *testing.T) {
+		expectedErr := errors.New("fake error")
+		dbwebhook := dbwebhookmocks.NewWebhookInterface()
+		dbwebhook.Impl.Deliver = func(
+			ctx context.Context,
+			deliver func(domain.WebhookDelivery) error,
+			retry func(domain.WebhookDelivery) (time.Duration, bool),
+		) (*domain.WebhookDelivery, error) {
+			return nil, expectedErr
+		}
+
+		testee := webhook.Task(logger, dbwebhook, hooks)
+		if _, _, err := testee(context.Background(), webhook.Seed()); !errors.Is(err, expectedErr) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
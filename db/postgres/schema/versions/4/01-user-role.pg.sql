@@ -0,0 +1,13 @@
+create type "role" as enum(
+    'viewer',
+    'plan-author',
+    'admin'
+);
+
+-- users without role are treated as 'viewer'.
+create table if not exists "user_role" (
+    "user_id" char(36) not null,
+    "role" role not null default 'viewer',
+    PRIMARY KEY ("user_id"),
+    FOREIGN KEY ("user_id") REFERENCES "user" ("id")
+);
@@ -0,0 +1,19 @@
+-- append-only log of changes.
+create table if not exists "audit_log" (
+    "id" bigserial not null,
+    "timestamp" timestamp with time zone not null default now(),
+    "actor" varchar not null,
+    "action" varchar not null,
+    "target_type" varchar not null,
+    "target_id" varchar not null,
+    "before" jsonb,
+    "after" jsonb,
+    PRIMARY KEY ("id")
+);
+create index if not exists "audit_log__timestamp" on "audit_log" ("timestamp");
+create index if not exists "audit_log__target" on "audit_log" ("target_type", "target_id");
+create index if not exists "audit_log__actor" on "audit_log" ("actor");
+
+-- audit log is not modifiable.
+create or replace rule "audit_log__no_update" as on update to "audit_log" do instead nothing;
+create or replace rule "audit_log__no_delete" as on delete to "audit_log" do instead nothing;
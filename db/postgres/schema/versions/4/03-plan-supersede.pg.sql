@@ -0,0 +1,14 @@
+-- "plan_id" is the successor of the plan "supersedes".
+--
+-- A plan can be superseded by at most one plan.
+create table if not exists "plan_supersede" (
+    "plan_id" char(36) not null,
+    "supersedes" char(36) not null,
+    -- when true, nominations for the superseded plan are dropped and not made anymore.
+    -- nominations are not moved to the successor.
+    "reroute" boolean not null default false,
+    PRIMARY KEY ("plan_id"),
+    UNIQUE ("supersedes"),
+    FOREIGN KEY ("plan_id") REFERENCES "plan" ("plan_id"),
+    FOREIGN KEY ("supersedes") REFERENCES "plan" ("plan_id")
+);
@@ -0,0 +1,10 @@
+-- cron schedule of plans. Runs of the plan are triggered when "next" comes.
+create table if not exists "plan_schedule" (
+    "plan_id" char(36) not null,
+    "schedule" varchar not null,
+    -- the time when the plan should be triggered next.
+    "next" timestamp with time zone not null,
+    PRIMARY KEY ("plan_id"),
+    FOREIGN KEY ("plan_id") REFERENCES "plan" ("plan_id")
+);
+create index if not exists "plan_schedule__next" on "plan_schedule" ("next");
@@ -0,0 +1,10 @@
+-- policy to select data for inputs.
+--
+-- Inputs not in this table use all data matching with them.
+create table if not exists "input_selection" (
+    "input_id" int not null,
+    -- the number of the most recent data (ordered by knit#timestamp) to be used.
+    "latest" int not null check (0 < "latest"),
+    PRIMARY KEY ("input_id"),
+    FOREIGN KEY ("input_id") REFERENCES "input" ("input_id")
+);
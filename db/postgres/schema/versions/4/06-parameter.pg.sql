@@ -0,0 +1,43 @@
+-- pseudo plan "parameter"
+
+with
+    "new_pseudo" as (
+        insert into "plan" ("active", "hash")
+        values ('TRUE', md5(''))
+        returning "plan_id"
+    ),
+    "new_mountpoint" as (
+        insert into "output" ("plan_id", "path")
+        select
+            "plan_id",
+            '/parameter'
+        from "new_pseudo"
+        returning "plan_id"
+    )
+insert into "plan_pseudo" ("plan_id", "name")
+select "plan_id", 'knit#parameter' from "new_mountpoint";
+
+-- literal values of parameter inputs.
+create table if not exists "parameter_input" (
+    "input_id" int not null,
+    "value" varchar not null,
+    -- true if the value has been materialised as data.
+    "materialised" boolean not null default false,
+    PRIMARY KEY ("input_id", "value"),
+    FOREIGN KEY ("input_id") REFERENCES "input" ("input_id")
+);
+create index if not exists "parameter_input__pending"
+    on "parameter_input" ("input_id") where not "materialised";
+
+-- data materialised from parameter values.
+--
+-- "input_id" is the parameter input which the data is for.
+-- Such data are nominated only to their inputs, regardless of tags,
+-- and parameter inputs are nominated only with them.
+create table if not exists "parameter_data" (
+    "knit_id" char(36) not null,
+    "value" varchar not null,
+    "input_id" int references "input" ("input_id") on delete set null,
+    PRIMARY KEY ("knit_id"),
+    FOREIGN KEY ("knit_id") REFERENCES "data" ("knit_id") on delete cascade
+);
@@ -0,0 +1,26 @@
+-- priority and concurrency limit of plans.
+--
+-- Plans not in this table have priority 0 and no concurrency limit.
+create table if not exists "plan_priority" (
+    "plan_id" char(36) not null,
+    -- Ready runs of plans with higher priority are started first.
+    "priority" int not null default 0,
+    -- the max number of runs of the plan being starting or running at once.
+    -- null means unlimited.
+    "max_concurrency" int check (0 < "max_concurrency"),
+    PRIMARY KEY ("plan_id"),
+    FOREIGN KEY ("plan_id") REFERENCES "plan" ("plan_id")
+);
+
+-- runs which are going to be started.
+--
+-- Checking concurrency limits (and quotas) and starting runs are not done in a transaction,
+-- since webhooks are called and workers are spawned between them.
+-- Ready runs with effective reservations are counted as if they were starting.
+-- Reservations are removed when runs leave ready, or expire at "expires_at".
+create table if not exists "run_start_reservation" (
+    "run_id" char(36) not null,
+    "expires_at" timestamp with time zone not null,
+    PRIMARY KEY ("run_id"),
+    FOREIGN KEY ("run_id") REFERENCES "run" ("run_id") on delete cascade
+);
@@ -0,0 +1,34 @@
+-- policy to retry failed runs of plans automatically.
+--
+-- Plans not in this table are not retried automatically.
+create table if not exists "plan_retry" (
+    "plan_id" char(36) not null,
+    -- max number of attempts of a run, including the first one.
+    "max_attempts" int not null check (0 < "max_attempts"),
+    -- wait before the first retry. it is doubled for each retry.
+    "backoff_seconds" int not null default 0 check (0 <= "backoff_seconds"),
+    -- causes of exits to be retried ('failed' or 'stucking'). empty means any.
+    "retry_on" varchar[] not null default '{}',
+    -- exit codes of failures to be retried. empty means any.
+    "exit_codes" smallint[] not null default '{}',
+    PRIMARY KEY ("plan_id"),
+    FOREIGN KEY ("plan_id") REFERENCES "plan" ("plan_id")
+);
+
+-- cause of the exit: 'failed', 'stucking', or '' for others (e.g. aborted by user).
+alter table "run_exit" add column if not exists "cause" varchar not null default '';
+
+-- finished attempts of runs which have been retried.
+create table if not exists "run_attempt" (
+    "run_id" char(36) not null,
+    -- sequential number of the attempt, starting from 1.
+    "attempt" int not null,
+    "status" runStatus not null,
+    -- exit of the attempt. null if the attempt has no exit record.
+    "exit_code" smallint,
+    "message" varchar(1024),
+    "cause" varchar,
+    "updated_at" timestamp with time zone not null,
+    PRIMARY KEY ("run_id", "attempt"),
+    FOREIGN KEY ("run_id") REFERENCES "run" ("run_id")
+);
@@ -0,0 +1,10 @@
+-- time limit of runs of plans.
+--
+-- Runs of plans not in this table have no time limit.
+create table if not exists "plan_max_duration" (
+    "plan_id" char(36) not null,
+    -- max duration of a worker of the run, in seconds.
+    "seconds" int not null check (0 < "seconds"),
+    PRIMARY KEY ("plan_id"),
+    FOREIGN KEY ("plan_id") REFERENCES "plan" ("plan_id")
+);
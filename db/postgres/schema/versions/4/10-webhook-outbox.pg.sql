@@ -0,0 +1,29 @@
+-- status of webhook deliveries in the outbox.
+--
+-- 'pending': waiting to be (re)delivered.
+-- 'failed': given up after all attempts.
+--
+-- delivered ones are removed from the outbox.
+create type "webhook_delivery_status" as enum(
+    'pending',
+    'failed'
+);
+
+-- durable queue of webhook calls which should be delivered, surviving loop restarts.
+create table if not exists "webhook_outbox" (
+    "id" bigserial not null,
+    -- name of the hook configuration which the delivery belongs to. e.g. 'lifecycle-hooks'.
+    "hook" varchar not null,
+    "url" varchar not null,
+    "payload" bytea not null,
+    "status" "webhook_delivery_status" not null default 'pending',
+    -- number of attempts made so far.
+    "attempts" int not null default 0,
+    "next_attempt_at" timestamp with time zone not null default now(),
+    "last_error" varchar not null default '',
+    "created_at" timestamp with time zone not null default now(),
+    "updated_at" timestamp with time zone not null default now(),
+    PRIMARY KEY ("id")
+);
+create index if not exists "webhook_outbox__pending" on "webhook_outbox" ("next_attempt_at") where "status" = 'pending';
+create index if not exists "webhook_outbox__status" on "webhook_outbox" ("status", "id");
@@ -0,0 +1,22 @@
+-- users who registered plans.
+--
+-- Plans registered before this table or by loops have no records,
+-- and they are not counted in quotas of any users.
+-- (They cannot be backfilled: "audit_log" is introduced in this version, too.)
+create table if not exists "plan_owner" (
+    "plan_id" char(36) not null,
+    -- email address of the user.
+    "owner" varchar not null,
+    PRIMARY KEY ("plan_id"),
+    FOREIGN KEY ("plan_id") REFERENCES "plan" ("plan_id")
+);
+create index if not exists "plan_owner__owner" on "plan_owner" ("owner");
+
+-- reasons why ready runs are kept ready, e.g. quotas are exhausted.
+create table if not exists "run_hold" (
+    "run_id" char(36) not null,
+    "reason" varchar not null,
+    "since" timestamp with time zone not null default now(),
+    PRIMARY KEY ("run_id"),
+    FOREIGN KEY ("run_id") REFERENCES "run" ("run_id") on delete cascade
+);
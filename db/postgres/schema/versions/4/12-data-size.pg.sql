@@ -0,0 +1,13 @@
+-- sizes of data, measured when they are uploaded or their runs are finished.
+--
+-- Data not measured yet have no records.
+create table if not exists "data_size" (
+    "knit_id" char(36) not null,
+    -- total size of regular files in the data.
+    "bytes" bigint not null,
+    -- number of regular files in the data.
+    "files" bigint not null,
+    "measured_at" timestamp with time zone not null default now(),
+    PRIMARY KEY ("knit_id"),
+    FOREIGN KEY ("knit_id") REFERENCES "data" ("knit_id") on delete cascade
+);
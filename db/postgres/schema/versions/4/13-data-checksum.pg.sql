@@ -0,0 +1,11 @@
+-- checksums of content of data, computed when they are uploaded or their runs are finished.
+--
+-- Data whose checksums are not computed yet have no records.
+create table if not exists "data_checksum" (
+    "knit_id" char(36) not null,
+    -- checksum as a merkle tree over files, like "sha256:...".
+    "checksum" varchar not null,
+    "computed_at" timestamp with time zone not null default now(),
+    PRIMARY KEY ("knit_id"),
+    FOREIGN KEY ("knit_id") REFERENCES "data" ("knit_id") on delete cascade
+);
@@ -0,0 +1,24 @@
+-- environment variables set to workers of runs of plans.
+--
+-- each row has a static value, or refers a key of a secret or a config map.
+create table if not exists "plan_env" (
+    "plan_id" char(36) not null,
+    -- order of declaration in the plan.
+    "position" int not null,
+    "name" varchar not null,
+    -- static value. null if it refers a secret or a config map.
+    "value" varchar,
+    "secret_name" varchar,
+    "secret_key" varchar,
+    "config_map_name" varchar,
+    "config_map_key" varchar,
+    PRIMARY KEY ("plan_id", "position"),
+    UNIQUE ("plan_id", "name"),
+    FOREIGN KEY ("plan_id") REFERENCES "plan" ("plan_id"),
+    CHECK (
+        ("value" is not null)::int
+        + ("secret_name" is not null and "secret_key" is not null)::int
+        + ("config_map_name" is not null and "config_map_key" is not null)::int
+        = 1
+    )
+);
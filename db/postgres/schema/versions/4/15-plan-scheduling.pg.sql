@@ -0,0 +1,38 @@
+-- resources which workers of plans request for scheduling.
+--
+-- "plan_resource" holds limits of them.
+-- resources not in this table are requested as much as limits.
+create table if not exists "plan_resource_request" (
+    "plan_id" char(36) references "plan" ("plan_id"),
+    "type" varchar(1024) not null,
+    "value" varchar(1024) not null,
+    PRIMARY KEY ("plan_id", "type")
+);
+
+-- kubernetes tolerations of workers of plans,
+-- in addition to ones derived from "plan_on_node".
+create table if not exists "plan_toleration" (
+    "plan_id" char(36) not null,
+    -- order of declaration in the plan.
+    "position" int not null,
+    -- empty matches all keys.
+    "key" varchar not null default '',
+    -- 'Equal' or 'Exists'.
+    "operator" varchar not null,
+    "value" varchar not null default '',
+    -- empty matches all effects.
+    "effect" varchar not null default '',
+    PRIMARY KEY ("plan_id", "position"),
+    FOREIGN KEY ("plan_id") REFERENCES "plan" ("plan_id")
+);
+
+-- kubernetes PriorityClass and RuntimeClass of workers of plans.
+--
+-- empty means the default.
+create table if not exists "plan_pod_class" (
+    "plan_id" char(36) not null,
+    "priority_class_name" varchar not null default '',
+    "runtime_class_name" varchar not null default '',
+    PRIMARY KEY ("plan_id"),
+    FOREIGN KEY ("plan_id") REFERENCES "plan" ("plan_id")
+);
@@ -0,0 +1,25 @@
+-- ephemeral directories mounted in workers of plans.
+--
+-- they are empty when the worker starts, and discarded when it finishes.
+create table if not exists "plan_scratch" (
+    "plan_id" char(36) not null,
+    -- absolute path in the container.
+    "path" varchar not null,
+    -- size limit as kubernetes quantity. null means unlimited.
+    "size_limit" varchar(1024),
+    -- true if it is backed by memory (tmpfs).
+    "in_memory" boolean not null default false,
+    PRIMARY KEY ("plan_id", "path"),
+    FOREIGN KEY ("plan_id") REFERENCES "plan" ("plan_id")
+);
+
+-- size of shared memory (/dev/shm) of workers of plans.
+--
+-- plans not in this table use the default of the container runtime.
+create table if not exists "plan_shm" (
+    "plan_id" char(36) not null,
+    -- kubernetes quantity.
+    "size" varchar(1024) not null,
+    PRIMARY KEY ("plan_id"),
+    FOREIGN KEY ("plan_id") REFERENCES "plan" ("plan_id")
+);
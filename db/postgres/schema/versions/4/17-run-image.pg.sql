@@ -0,0 +1,10 @@
+-- image IDs which workers of runs have actually run, reported by the container runtime.
+--
+-- They are like "repo@sha256:...", and identify the image even if the plan refers it by tag.
+-- Runs whose workers have not started yet have no records.
+create table if not exists "run_image" (
+    "run_id" char(36) not null,
+    "image_id" varchar not null,
+    PRIMARY KEY ("run_id"),
+    FOREIGN KEY ("run_id") REFERENCES "run" ("run_id") on delete cascade
+);
@@ -0,0 +1,26 @@
+-- resources which workers of runs requested when they were started.
+--
+-- They are requests of the plan ("plan_resource_request"),
+-- or limits ("plan_resource") for resources without requests.
+-- Quotas are checked against them, so changing resources of the plan does not affect runs started already.
+-- Runs which have not been started yet have no records, unless they are reserved to be started
+-- (see "run_start_reservation").
+create table if not exists "run_resource" (
+    "run_id" char(36) not null,
+    "type" varchar(1024) not null,
+    "value" varchar(1024) not null,
+    PRIMARY KEY ("run_id", "type"),
+    FOREIGN KEY ("run_id") REFERENCES "run" ("run_id") on delete cascade
+);
+
+with "requested" as (
+    select "plan_id", "type", coalesce("req"."value", "lim"."value") as "value"
+    from "plan_resource" as "lim"
+    full outer join "plan_resource_request" as "req" using ("plan_id", "type")
+)
+insert into "run_resource" ("run_id", "type", "value")
+select "run_id", "type", "value"
+from "run"
+inner join "requested" using ("plan_id")
+where "status" in ('starting', 'running')
+on conflict do nothing;
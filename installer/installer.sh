@@ -96,6 +96,20 @@ knitd:
   # port: Port number of knit-api service, exposed from k8s cluster node.
   port: 30803
 
+  # # admission: admission policy for Plans.
+  # #
+  # # Plans which do not satisfy all of rules are rejected on registration,
+  # # and on changes of their resources, annotations and service account.
+  # #
+  # # "expression" is a CEL expression over the variable "plan", which has keys
+  # # image, tag, digest, entrypoint, args, resources, on_node, service_account and annotations.
+  # # quantity("64Gi") converts a Kubernetes quantity to a number.
+  # admission:
+  #   rules:
+  #     - name: trusted-registry
+  #       expression: 'plan.image.startsWith("registry.internal/")'
+  #       message: "images must come from registry.internal"
+
 vex:
   # use: If true, vex is deployed. default: false
   use: false
@@ -259,7 +273,97 @@ hooks:
     # # The webhook receives POST requests with JSON for each Runs whose status has been changed.
     # #
     # # Responses from these hooks are ignored.
+    # #
+    # # Calls to these hooks are queued in the database (outbox), and delivered by the webhook loop.
+    # # So, they are not lost even if Knitfab is restarted. Deliveries given up after all attempts
+    # # can be inspected with "GET /api/webhooks/deliveries" (admin only).
+    after: []
+
+    # # secret: a key to sign requests with HMAC-SHA256. If empty, requests are not signed.
+    # #
+    # # Signed requests have headers below:
+    # #
+    # # - X-Knitfab-Timestamp: the time when the request is sent, in Unix seconds.
+    # # - X-Knitfab-Signature: "sha256=" + hex(HMAC-SHA256(secret, "<X-Knitfab-Timestamp>.<request body>"))
+    # #
+    # # Requests from the outbox also have "X-Knitfab-Delivery" header, the id of the delivery.
+    secret: ""
+
+    # # timeout: timeout for each request.
+    timeout: 10s
+
+    # # retry: policy to retry failed requests, with exponential backoff.
+    # #
+    # # Requests are retried when they fail without response, or with the status 429 or 5xx.
+    retry:
+      # # max-attempts: max number of attempts, including the first one.
+      max-attempts: 5
+      # # backoff: wait before the first retry. It is doubled for each retry.
+      backoff: 1s
+      # # max-backoff: upper limit of the wait between retries.
+      max-backoff: 5m
+
+  # # lifecycle-events: receivers of lifecycle events of Runs in standard formats.
+  # #
+  # # Each URLs receives POST requests with an event, after status change of a Run.
+  # # Events are emitted when a Run gets "starting" (START), "running" (RUNNING),
+  # # "done" (COMPLETE), "failed" (FAIL) or "invalidated" (ABORT).
+  # #
+  # # Input and output datasets in events are identified by Knit Ids, with their Tags.
+  # #
+  # # Responses from these receivers are ignored.
+  lifecycle-events:
+
+    # # format: "openlineage" (OpenLineage RunEvent) or "cloudevents" (CloudEvents whose data is OpenLineage RunEvent).
+    format: openlineage
+
+    # # namespace: namespace of jobs and datasets in events.
+    namespace: knitfab
+
+    # # urls: URLs to receive events. For example, "http://marquez:5000/api/v1/lineage" .
+    urls: []
+
+  # # data-hooks: webhooks for events of Data.
+  # #
+  # # Each URLs receives POST requests with JSON like below, when a Data is
+  # # uploaded ("uploaded"), imported ("imported") or its Tags are changed ("tagged").
+  # #
+  # #     {"event": "tagged", "data": <Data, as output of \`knit data find\`>, "tags": {"add": [...], "remove": [...], "remove_key": [...]}}
+  # #
+  # # When "before" hooks respond 4xx status (except 429), the change is rejected with 403 Forbidden.
+  # # When they fail otherwise, the change is rejected with 503 Service Unavailable.
+  # #
+  # # "after" hooks, secret, timeout and retry work as same as lifecycle-hooks.
+  data-hooks:
+    before: []
+    after: []
+    secret: ""
+    timeout: 10s
+    retry:
+      max-attempts: 5
+      backoff: 1s
+      max-backoff: 5m
+
+  # # plan-hooks: webhooks for events of Plans.
+  # #
+  # # Each URLs receives POST requests with JSON like below, when a Plan is
+  # # registered ("registered"), activated ("activated"), deactivated ("deactivated"),
+  # # or its resources ("resources") or annotations ("annotations") are changed.
+  # #
+  # #     {"event": "registered", "plan": <Plan, as output of \`knit plan show\`>, "spec": <Plan definition>}
+  # #
+  # # "before" hooks of "registered" events receive only "spec", since the Plan is not registered yet.
+  # #
+  # # Rejection by "before" hooks and others work as same as data-hooks.
+  plan-hooks:
+    before: []
     after: []
+    secret: ""
+    timeout: 10s
+    retry:
+      max-attempts: 5
+      backoff: 1s
+      max-backoff: 5m
 
 EOF
 
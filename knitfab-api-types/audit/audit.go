@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+)
+
+// Entry is a record of a change in Knitfab.
+type Entry struct {
+	// Id is the id of the entry. Larger id is newer.
+	Id int64 `json:"id"`
+
+	// Timestamp is the time when the change is made.
+	Timestamp rfctime.RFC3339 `json:"timestamp"`
+
+	// Actor is who made the change.
+	//
+	// For changes via WebAPI, it is the email address of the user
+	// (or "anonymous" if authentication is disabled).
+	// For changes by Knitfab itself, it is "loop/<loop type>" or "system".
+	Actor string `json:"actor"`
+
+	// Action is what is done. For example, "data.tag", "plan.activate" or "run.status".
+	Action string `json:"action"`
+
+	// Target is the item which is changed.
+	Target Target `json:"target"`
+
+	// Before is the snapshot of the target before the change.
+	//
+	// It is absent when the target is created by the change.
+	Before json.RawMessage `json:"before,omitempty"`
+
+	// After is the snapshot of the target after the change.
+	//
+	// It is absent when the target is deleted by the change.
+	After json.RawMessage `json:"after,omitempty"`
+}
+
+// Target is an item changed.
+type Target struct {
+	// Type is the kind of the target. One of "data", "plan" or "run".
+	Type string `json:"type"`
+
+	// Id is the identifier of the target: Knit Id, Plan Id or Run Id.
+	Id string `json:"id"`
+}
+
+func (e Entry) Equal(o Entry) bool {
+	return e.Id == o.Id &&
+		e.Timestamp.Equal(o.Timestamp) &&
+		e.Actor == o.Actor &&
+		e.Action == o.Action &&
+		e.Target == o.Target &&
+		jsonEqual(e.Before, o.Before) &&
+		jsonEqual(e.After, o.After)
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	ca, cb := new(bytes.Buffer), new(bytes.Buffer)
+	if err := json.Compact(ca, a); err != nil {
+		return false
+	}
+	if err := json.Compact(cb, b); err != nil {
+		return false
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
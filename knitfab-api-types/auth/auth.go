@@ -0,0 +1,81 @@
+package auth
+
+import "github.com/opst/knitfab-api-types/misc/rfctime"
+
+// Role is a role of a user.
+//
+// Roles are ordered: "admin" can do everything "plan-author" can do,
+// and "plan-author" can do everything "viewer" can do.
+type Role string
+
+const (
+	// RoleViewer can read Data, Plans and Runs, but cannot change anything.
+	RoleViewer Role = "viewer"
+
+	// RolePlanAuthor can register or (de)activate Plans, upload or retag Data,
+	// and abort or retry Runs, in addition to RoleViewer.
+	RolePlanAuthor Role = "plan-author"
+
+	// RoleAdmin can do everything, including deleting Runs and registering users.
+	RoleAdmin Role = "admin"
+)
+
+// Credential is a request body to issue a new token.
+type Credential struct {
+	// Email is the email address of the user.
+	Email string `json:"email"`
+
+	// Password is the password of the user.
+	Password string `json:"password"`
+}
+
+// Token is a bearer token issued by Knitfab.
+//
+// Pass it to Knitfab WebAPI as "Authorization: Bearer <Token>" header.
+type Token struct {
+	// Token is the bearer token.
+	Token string `json:"token"`
+
+	// IssuedAt is the time when the token is issued.
+	IssuedAt rfctime.RFC3339 `json:"issuedAt"`
+
+	// ExpiresAt is the time when the token is expired.
+	ExpiresAt rfctime.RFC3339 `json:"expiresAt"`
+}
+
+// User is a user of Knitfab.
+type User struct {
+	// UserId is the id of the user.
+	UserId string `json:"userId"`
+
+	// Email is the email address of the user.
+	Email string `json:"email"`
+
+	// Name is the display name of the user.
+	Name string `json:"name"`
+
+	// Role is the role of the user.
+	Role Role `json:"role"`
+}
+
+func (u User) Equal(o User) bool {
+	return u.UserId == o.UserId &&
+		u.Email == o.Email &&
+		u.Name == o.Name &&
+		u.Role == o.Role
+}
+
+// UserSpec is a request body to register a new user.
+type UserSpec struct {
+	// Email is the email address of the user.
+	Email string `json:"email"`
+
+	// Name is the display name of the user.
+	Name string `json:"name"`
+
+	// Password is the initial password of the user.
+	Password string `json:"password"`
+
+	// Role is the role of the user.
+	Role Role `json:"role"`
+}
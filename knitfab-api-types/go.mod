@@ -0,0 +1,19 @@
+module github.com/opst/knitfab-api-types
+
+go 1.23.1
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require (
+	github.com/fxamacker/cbor/v2 v2.7.0 // indirect
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	gopkg.in/inf.v0 v0.9.1 // indirect
+	k8s.io/apimachinery v0.31.1
+)
+
+require (
+	github.com/google/go-containerregistry v0.20.2
+	github.com/opencontainers/go-digest v1.0.0 // indirect
+)
@@ -0,0 +1,31 @@
+package lineage
+
+import (
+	"github.com/opst/knitfab-api-types/data"
+	"github.com/opst/knitfab-api-types/internal/utils/cmp"
+	"github.com/opst/knitfab-api-types/runs"
+)
+
+// Graph is the format for response body from WebAPIs below:
+//
+// - GET /api/lineage/{knitId}[?...]
+//
+// It is a subgraph of the Data Lineage around the Data specified by Root.
+type Graph struct {
+	// Root is the Knit Id of the Data which the lineage is traced from.
+	Root string `json:"root"`
+
+	// Data are the Data in the subgraph, including the root.
+	Data []data.Detail `json:"data"`
+
+	// Runs are the Runs in the subgraph.
+	//
+	// Inputs and outputs of them are also in Data.
+	Runs []runs.Detail `json:"runs"`
+}
+
+func (g Graph) Equal(o Graph) bool {
+	return g.Root == o.Root &&
+		cmp.SliceEqualUnordered(g.Data, o.Data) &&
+		cmp.SliceEqualUnordered(g.Runs, o.Runs)
+}
@@ -0,0 +1,984 @@
+package plans
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/opst/knitfab-api-types/internal/utils/cmp"
+	"github.com/opst/knitfab-api-types/tags"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type Summary struct {
+	// PlanId is the id of the Plan.
+	PlanId string `json:"planId"`
+
+	// Image is the container image of the Plan.
+	//
+	// This is exclusive with Name.
+	Image *Image `json:"image,omitempty"`
+
+	// Entrypoint is the entrypoint of the container of the Plan.
+	Entrypoint []string `json:"entrypoint,omitempty"`
+
+	// Args are the arguments of the container of the Plan.
+	Args []string `json:"args,omitempty"`
+
+	// Name is the name of the Plan.
+	//
+	// This is exclusive with Image, and used only for the system-builtin Plan with no image.
+	Name string `json:"name,omitempty"`
+
+	// Annotations are the annotations of the Plan.
+	//
+	// In JSON format, it is a list of strings in the form of "key=value".
+	Annotations Annotations `json:"annotations,omitempty"`
+}
+
+func (s Summary) Equal(o Summary) bool {
+	return s.PlanId == o.PlanId &&
+		s.Image.Equal(o.Image) &&
+		cmp.SliceEqEq(s.Entrypoint, o.Entrypoint) &&
+		cmp.SliceEqEq(s.Args, o.Args) &&
+		s.Name == o.Name &&
+		s.Annotations.Equal(o.Annotations)
+}
+
+type Image struct {
+	Repository string
+	Tag        string
+
+	// Digest of the image, like "sha256:...".
+	//
+	// When this is set, the image is pinned by the digest and Tag is empty.
+	Digest string
+}
+
+func (i *Image) Equal(o *Image) bool {
+	if (i == nil) || (o == nil) {
+		return (i == nil) && (o == nil)
+	}
+	return i.Repository == o.Repository &&
+		i.Tag == o.Tag &&
+		i.Digest == o.Digest
+}
+
+// parse string as Image Tag or Digest, and upgate itself.
+//
+// this spec is based on docker image tag spec[^1].
+//
+// When both of tag and digest are given, the tag is ignored as container runtimes do.
+//
+// [^1]: https://docs.docker.com/engine/reference/commandline/tag/#description
+func (i *Image) Parse(s string) error {
+	// [<repository>[:<port>]/]<name>:<tag>
+	// [<repository>[:<port>]/]<name>[:<tag>]@<digest>
+
+	if strings.Contains(s, "@") {
+		ref, err := name.NewDigest(s, name.WithDefaultRegistry(""))
+		if err != nil {
+			return err
+		}
+		i.Repository = ref.Repository.Name()
+		i.Tag = ""
+		i.Digest = ref.DigestStr()
+		return nil
+	}
+
+	ref, err := name.NewTag(s, name.WithDefaultRegistry(""))
+	if err != nil {
+		return err
+	}
+
+	i.Repository = ref.Repository.Name()
+	i.Tag = ref.TagStr()
+	i.Digest = ""
+	return nil
+}
+
+func (i *Image) marshal() string {
+	if i.Repository == "" && i.Tag == "" && i.Digest == "" {
+		return ""
+	}
+	if i.Digest != "" {
+		return fmt.Sprintf(`%s@%s`, i.Repository, i.Digest)
+	}
+	return fmt.Sprintf(`%s:%s`, i.Repository, i.Tag)
+}
+
+func (i Image) MarshalJSON() ([]byte, error) {
+	b := bytes.NewBufferString(`"`)
+	b.WriteString(i.marshal())
+	b.WriteString(`"`)
+	return b.Bytes(), nil
+}
+
+func (i Image) MarshalYAML() (interface{}, error) {
+	n := yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Value: i.marshal(),
+		Style: yaml.DoubleQuotedStyle,
+	}
+	return n, nil
+}
+
+func (i *Image) UnmarshalYAML(node *yaml.Node) error {
+	expr := new(string)
+	err := node.Decode(expr)
+	if err != nil {
+		return err
+	}
+	return i.Parse(*expr)
+}
+
+func (i *Image) UnmarshalJSON(b []byte) error {
+	expr := new(string)
+	err := json.Unmarshal(b, expr)
+	if err != nil {
+		return err
+	}
+	return i.Parse(*expr)
+}
+
+func (i *Image) String() string {
+	return i.marshal()
+}
+
+type Annotations []Annotation
+
+func (ans Annotations) Equal(o Annotations) bool {
+	return cmp.SliceEqualUnordered(ans, o)
+}
+
+func (ans Annotations) marshal() []Annotation {
+	_ans := append([]Annotation{}, ans...)
+	slices.SortFunc(_ans, func(i, j Annotation) int {
+		if c := strings.Compare(i.Key, j.Key); c != 0 {
+			return c
+		}
+		return strings.Compare(i.Value, j.Value)
+	})
+	return _ans
+}
+
+func (ans Annotations) MarshalJSON() ([]byte, error) {
+	s := ans.marshal()
+	return json.Marshal(s)
+}
+
+type Annotation struct {
+	Key   string
+	Value string
+}
+
+func (an Annotation) String() string {
+	return fmt.Sprintf("%s=%s", an.Key, an.Value)
+}
+
+func (an Annotation) Equal(o Annotation) bool {
+	return an.Key == o.Key && an.Value == o.Value
+}
+
+func (an Annotation) MarshalJSON() ([]byte, error) {
+	s := an.String()
+	return json.Marshal(s)
+}
+
+func (an Annotation) MarshalYAML() (interface{}, error) {
+	n := yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Value: an.String(),
+		Style: yaml.DoubleQuotedStyle,
+	}
+	return n, nil
+}
+
+func (an *Annotation) parse(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("annotation format error (should be key=value): %s", s)
+	}
+
+	an.Key = strings.TrimSpace(k)
+	an.Value = strings.TrimSpace(v)
+	return nil
+}
+
+func (an *Annotation) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return an.parse(s)
+}
+
+func (an *Annotation) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+
+	return an.parse(s)
+}
+
+// Detail is the format for the response body from Knitfab APIs below:
+//
+// - GET  /api/plans/ (as list)
+//
+// - POST /api/plans/
+//
+// - GET  /api/plans/{planId}
+//
+// - PUT  /api/plans/{planId}/active
+//
+// - PUT  /api/plans/{planId}/resources
+//
+// - PUT  /api/plans/{planId}/priority
+//
+// - POST /api/plans/{planId}/successor
+type Detail struct {
+	Summary
+
+	// Inputs are the input mountpoints of the plan.
+	Inputs []Input `json:"inputs"`
+
+	// Outputs are the output mountpoints of the plan.
+	Outputs []Output `json:"outputs"`
+
+	// Log is the log point of the plan.
+	//
+	// If nil, the plan does not record logs.
+	Log *Log `json:"log,omitempty"`
+
+	// Active shows Plan's activeness.
+	//
+	// It is true if the plan is active and new runs can be created.
+	Active bool `json:"active"`
+
+	// OnNode is the node affinity/torelance of the plan.
+	//
+	// If nil, the plan does not have node affinity/torelance.
+	OnNode *OnNode `json:"on_node,omitempty"`
+
+	// Resources is the resource limits and requiremnts of the plan.
+	Resources Resources `json:"resources,omitempty"`
+
+	// ResourceRequests is the resources which Workers of the plan request for scheduling.
+	//
+	// Resources not in this are requested as much as Resources (limits).
+	ResourceRequests Resources `json:"resource_requests,omitempty"`
+
+	// Tolerations are the Kubernetes tolerations of Workers of the plan, in addition to ones from OnNode.
+	Tolerations []Toleration `json:"tolerations,omitempty"`
+
+	// PriorityClassName is the Kubernetes PriorityClass of Workers of the plan.
+	//
+	// If empty, the default of the cluster is used.
+	PriorityClassName string `json:"priority_class_name,omitempty"`
+
+	// RuntimeClassName is the Kubernetes RuntimeClass of Workers of the plan.
+	//
+	// If empty, the default runtime is used.
+	RuntimeClassName string `json:"runtime_class_name,omitempty"`
+
+	// Scratch are ephemeral directories mounted in Workers of the plan.
+	Scratch []ScratchVolume `json:"scratch,omitempty"`
+
+	// ShmSize is the size of shared memory (/dev/shm) of Workers of the plan, like "2Gi".
+	//
+	// If empty, the default of the container runtime is used.
+	ShmSize string `json:"shm_size,omitempty"`
+
+	// ServiceAccount is the ServiceAccount name of the plan.
+	//
+	// Workers of the Run based this Plan will run with this ServiceAccount.
+	ServiceAccount string `json:"service_account,omitempty"`
+
+	// Supersedes is the Plan Id of the predecessor of the Plan.
+	//
+	// If empty, the plan does not supersede any Plan.
+	Supersedes string `json:"supersedes,omitempty"`
+
+	// SupersededBy is the Plan Id of the successor of the Plan.
+	//
+	// If empty, the plan is the latest version.
+	SupersededBy string `json:"superseded_by,omitempty"`
+
+	// Schedule is the cron schedule of the Plan.
+	//
+	// If empty, the Plan is not scheduled.
+	Schedule string `json:"schedule,omitempty"`
+
+	// Priority is the priority of Runs of the Plan.
+	//
+	// Runs with higher priority are started earlier.
+	Priority int `json:"priority,omitempty"`
+
+	// MaxConcurrency is the maximum number of Runs of the Plan running at once.
+	//
+	// If 0, it is unlimited.
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
+
+	// Retry is the policy to retry failed Runs of the Plan automatically.
+	//
+	// If nil, failed Runs are not retried automatically.
+	Retry *RetryPolicy `json:"retry,omitempty"`
+
+	// MaxDuration is the time limit of a Run of the Plan, in Go duration format (e.g. "1h30m").
+	//
+	// Runs exceeding this are terminated and failed by timeout.
+	// If empty, it is unlimited.
+	MaxDuration string `json:"max_duration,omitempty"`
+
+	// Env is environment variables set to Workers of the Plan.
+	//
+	// Static values are redacted as RedactedValue. References to Secrets and ConfigMaps are shown as they are.
+	Env []EnvVar `json:"env,omitempty"`
+}
+
+func (d Detail) Equal(o Detail) bool {
+	logEq := d.Log == nil && o.Log == nil ||
+		(d.Log != nil && o.Log != nil && d.Log.Equal(*o.Log))
+	onnodeEq := d.OnNode == nil && o.OnNode == nil ||
+		(d.OnNode != nil && o.OnNode != nil && d.OnNode.Equal(*o.OnNode))
+
+	return d.Summary.Equal(o.Summary) &&
+		d.Active == o.Active &&
+		d.ServiceAccount == o.ServiceAccount &&
+		d.Supersedes == o.Supersedes &&
+		d.SupersededBy == o.SupersededBy &&
+		d.Schedule == o.Schedule &&
+		d.Priority == o.Priority &&
+		d.MaxConcurrency == o.MaxConcurrency &&
+		retryEq(d.Retry, o.Retry) &&
+		d.MaxDuration == o.MaxDuration &&
+		cmp.SliceEqual(d.Env, o.Env) &&
+		logEq && onnodeEq &&
+		cmp.MapEqual(d.Resources, o.Resources) &&
+		cmp.MapEqual(d.ResourceRequests, o.ResourceRequests) &&
+		cmp.SliceEqEq(d.Tolerations, o.Tolerations) &&
+		d.PriorityClassName == o.PriorityClassName &&
+		d.RuntimeClassName == o.RuntimeClassName &&
+		cmp.SliceEqEq(d.Scratch, o.Scratch) &&
+		d.ShmSize == o.ShmSize &&
+		cmp.SliceEqualUnordered(d.Inputs, o.Inputs) &&
+		cmp.SliceEqualUnordered(d.Outputs, o.Outputs)
+}
+
+// Mountpoint is the format for input/output mountpoints of a Plan.
+type Mountpoint struct {
+	// Path is the path of the mountpoint.
+	//
+	// This is the path in the container where the Data will be mounted
+	// when the Run starts.
+	Path string `json:"path"`
+
+	// Tags are the tags of the mountpoint.
+	//
+	// For input mountpoints, these are the required tags of the Data to be mounted.
+	// The Data with these all tags will be mounted to the Path when the Run starts.
+	//
+	// For output mountpoints, these are the tags to be attached to the Data mounted.
+	Tags []tags.Tag `json:"tags"`
+
+	// Select is the selection policy of Data for input mountpoints.
+	//
+	// - "all" (or empty): all Data with the Tags are used as inputs.
+	//
+	// - "latest": only the latest Data (by knit#timestamp) is used as inputs.
+	//
+	// - "latest:N": only the latest N Data (by knit#timestamp) are used as inputs.
+	//
+	// This should be empty for output mountpoints.
+	Select string `json:"select,omitempty" yaml:"select,omitempty"`
+
+	// Values are literal values of a parameter input.
+	//
+	// When it is not empty, the input mountpoint is a parameter input:
+	// each value is stored as a Data containing a file "value" whose content is the value,
+	// and the Data is assigned only to this input. Tags are not used to find Data for it.
+	// So, each value drives a Run of the Plan.
+	//
+	// Having two or more parameter inputs, Runs are created for
+	// all combinations of their values (grid).
+	//
+	// This should be empty for output mountpoints.
+	Values []string `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+func (m Mountpoint) Equal(o Mountpoint) bool {
+	return m.Path == o.Path &&
+		cmp.SliceEqualUnordered(m.Tags, o.Tags) &&
+		m.Select == o.Select &&
+		cmp.SliceEqEqUnordered(m.Values, o.Values)
+}
+
+// Upstream is the format for input dependencies of a Plan.
+type Upstream struct {
+	// Plan is the upstream Plan.
+	Plan Summary `json:"plan"`
+
+	// Mountpoint represents the Output which is directt upstream.
+	//
+	// Log and Mountpoint are mutually exclusive.
+	Mountpoint *Mountpoint `json:"mountpoint,omitempty"`
+
+	// Log represents the Log which is direct upstream.
+	//
+	// Log and Mountpoint are mutually exclusive.
+	Log *LogPoint `json:"log,omitempty"`
+}
+
+func (d Upstream) Equal(o Upstream) bool {
+	if (d.Mountpoint == nil) != (o.Mountpoint == nil) {
+		return false
+	}
+
+	if (d.Log == nil) != (o.Log == nil) {
+		return false
+	}
+
+	mountpointMatch := (d.Mountpoint == nil && o.Mountpoint == nil) ||
+		d.Mountpoint.Equal(*o.Mountpoint)
+
+	logMatch := (d.Log == nil && o.Log == nil) ||
+		d.Log.Equal(*o.Log)
+
+	return d.Plan.Equal(o.Plan) && mountpointMatch && logMatch
+}
+
+// Input is the format for input mountpoints of a Plan.
+type Input struct {
+	Mountpoint
+
+	// Upstreams are the upstream Plans and their output mountpoints
+	// whose output Data can be mounted to this input mountpoint.
+	Upstreams []Upstream `json:"upstreams"`
+}
+
+func (i Input) Equal(o Input) bool {
+	return i.Mountpoint.Equal(o.Mountpoint) &&
+		cmp.SliceEqualUnordered(i.Upstreams, o.Upstreams)
+}
+
+// Downstream is the format for output dependencies of a Plan.
+type Downstream struct {
+	// Plan is the downstream Plan.
+	Plan Summary `json:"plan"`
+
+	// Mountpoint represents the Input which is direct downstream.
+	Mountpoint Mountpoint `json:"mountpoint"`
+}
+
+func (d Downstream) Equal(o Downstream) bool {
+	return d.Plan.Equal(o.Plan) && d.Mountpoint.Equal(o.Mountpoint)
+}
+
+// Output is the format for output mountpoints of a Plan.
+type Output struct {
+	Mountpoint
+
+	// Downstreams are the downstream Plans and their input mountpoints
+	// can be assigned with Data from this output.
+	Downstreams []Downstream `json:"downstreams"`
+}
+
+func (o Output) Equal(oo Output) bool {
+	return o.Mountpoint.Equal(oo.Mountpoint) &&
+		cmp.SliceEqualUnordered(o.Downstreams, oo.Downstreams)
+}
+
+type Log struct {
+	LogPoint
+
+	// Downstreams are the downstream Plans and their input mountpoints
+	// can be assigned with Data from this output.
+	Downstreams []Downstream `json:"downstreams"`
+}
+
+func (l Log) Equal(ol Log) bool {
+	return l.LogPoint.Equal(ol.LogPoint) &&
+		cmp.SliceEqualUnordered(l.Downstreams, ol.Downstreams)
+}
+
+func (l Log) String() string {
+	return fmt.Sprintf("{LogPoint: %+v, Downstreams: %+v}", l.LogPoint, l.Downstreams)
+}
+
+type LogPoint struct {
+	Tags []tags.Tag `json:"tags"`
+}
+
+func (lp LogPoint) Equal(o LogPoint) bool {
+	return cmp.SliceEqualUnordered(lp.Tags, o.Tags)
+}
+
+func (lp LogPoint) String() string {
+	return fmt.Sprintf("{Tags: %+v}", lp.Tags)
+}
+
+type OnNode struct {
+	May    []OnSpecLabel `json:"may,omitempty" yaml:"may,omitempty"`
+	Prefer []OnSpecLabel `json:"prefer,omitempty" yaml:"prefer,omitempty"`
+	Must   []OnSpecLabel `json:"must,omitempty" yaml:"must,omitempty"`
+}
+
+func (o OnNode) Equal(oo OnNode) bool {
+	return cmp.SliceEqualUnordered(o.May, oo.May) &&
+		cmp.SliceEqualUnordered(o.Prefer, oo.Prefer) &&
+		cmp.SliceEqualUnordered(o.Must, oo.Must)
+}
+
+type OnSpecLabel struct {
+	Key   string
+	Value string
+}
+
+func (l OnSpecLabel) String() string {
+	return fmt.Sprintf("%s=%s", l.Key, l.Value)
+}
+
+func (l OnSpecLabel) Equal(o OnSpecLabel) bool {
+	return l.Key == o.Key && l.Value == o.Value
+}
+
+func (l *OnSpecLabel) Parse(s string) error {
+
+	k, v, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("label format error (should be key=value): %s", s)
+	}
+
+	l.Key = k
+	l.Value = v
+	return nil
+}
+
+func (l OnSpecLabel) MarshalJSON() ([]byte, error) {
+	b := bytes.NewBufferString(`"`)
+	b.WriteString(l.String())
+	b.WriteString(`"`)
+	return b.Bytes(), nil
+}
+
+func (l OnSpecLabel) MarshalYAML() (interface{}, error) {
+	n := yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Value: l.String(),
+		Style: yaml.DoubleQuotedStyle,
+	}
+	return n, nil
+}
+
+func (l *OnSpecLabel) UnmarshalJSON(value []byte) error {
+	expr := new(string)
+	err := json.Unmarshal(value, expr)
+	if err != nil {
+		return err
+	}
+	return l.Parse(*expr)
+}
+
+func (l *OnSpecLabel) UnmarshalYAML(node *yaml.Node) error {
+	expr := new(string)
+	err := node.Decode(expr)
+	if err != nil {
+		return err
+	}
+	return l.Parse(*expr)
+}
+
+type Resources map[string]resource.Quantity
+
+func (r Resources) Equal(o Resources) bool {
+	return cmp.MapEqual(r, o)
+}
+
+func (r Resources) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]resource.Quantity(r))
+}
+
+func (r Resources) MarshalYAML() (interface{}, error) {
+	jsonMap := map[string]string{}
+	jsonBytes, err := r.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(jsonBytes, &jsonMap)
+	if err != nil {
+		return nil, err
+	}
+	return jsonMap, nil
+}
+
+func (r *Resources) UnmarshalYAML(node *yaml.Node) error {
+	var m map[string]string
+	if err := node.Decode(&m); err != nil {
+		return err
+	}
+
+	jsonBytes, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := r.UnmarshalJSON(jsonBytes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Resources) UnmarshalJSON(b []byte) error {
+	var m map[string]resource.Quantity
+	err := json.Unmarshal(b, &m)
+	if err != nil {
+		return err
+	}
+	*r = Resources(m)
+	return nil
+}
+
+// PlanSpec is the format for request body to Knitfab APIs below:
+//
+// - POST /api/plans/
+//
+// - POST /api/plans/{planId}/successor
+type PlanSpec struct {
+	// Annotations are the annotations of the Plan.
+	//
+	// In JSON format, it is a list of strings in the form of "key=value".
+	//
+	// If same key is set multiple times, the last one is used.
+	Annotations Annotations `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+
+	// Image is the container image of the Plan.
+	Image Image `json:"image" yaml:"image"`
+
+	// Entrypoint is the entrypoint of the container of the Plan.
+	Entrypoint []string `json:"entrypoint,omitempty" yaml:"entrypoint,omitempty"`
+
+	// Args are the arguments of the container of the Plan.
+	Args []string `json:"args,omitempty" yaml:"args,omitempty"`
+
+	// Inputs are the input mountpoints of the plan.
+	//
+	// These describes "where should input Data be mounted to the container" and
+	// "what tags should be attached to the input Data".
+	//
+	// When Knitfab detect the Data with the tags, it will be mounted to the container and started as a Run.
+	Inputs []Mountpoint `json:"inputs" yaml:"inputs"`
+
+	// Outputs are the output mountpoints of the plan.
+	//
+	// These describes "where should output Data be mounted to the container" and
+	// "what tags will be attached to the output Data".
+	//
+	// On the Run start, Knitfab will create the mountpoints and attach the tags to the output Data.
+	Outputs []Mountpoint `json:"outputs" yaml:"outputs"`
+
+	// Log is the log point of the plan.
+	//
+	// "Log" means a Data containing standard output and standard error of the container.
+	// If nil, the plan does not record logs.
+	//
+	// As Outputs, Log can have Tags which will be attached to the log Data.
+	Log *LogPoint `json:"log,omitempty" yaml:"log,omitempty"`
+
+	// OnNode is the node affinity/torelance of the plan.
+	//
+	// If nil, the plan does not have node affinity/torelance.
+	OnNode *OnNode `json:"on_node,omitempty" yaml:"on_node,omitempty"`
+
+	// Resources is the conputational resource limits and requiremnts of the plan.
+	Resources Resources `json:"resources,omitempty" yaml:"resources,omitempty"`
+
+	// ResourceRequests is the resources which Workers of the plan request for scheduling.
+	//
+	// Each of them should not exceed the limit in Resources of the same type.
+	// Resources not in this are requested as much as Resources (limits).
+	// Requests lower than limits allow Workers to burst over them, when nodes have room.
+	ResourceRequests Resources `json:"resource_requests,omitempty" yaml:"resource_requests,omitempty"`
+
+	// Tolerations are the Kubernetes tolerations of Workers of the plan, in addition to ones from OnNode.
+	Tolerations []Toleration `json:"tolerations,omitempty" yaml:"tolerations,omitempty"`
+
+	// PriorityClassName is the Kubernetes PriorityClass of Workers of the plan.
+	//
+	// If empty, the default of the cluster is used.
+	PriorityClassName string `json:"priority_class_name,omitempty" yaml:"priority_class_name,omitempty"`
+
+	// RuntimeClassName is the Kubernetes RuntimeClass of Workers of the plan (e.g. "nvidia").
+	//
+	// If empty, the default runtime is used.
+	RuntimeClassName string `json:"runtime_class_name,omitempty" yaml:"runtime_class_name,omitempty"`
+
+	// Scratch are ephemeral directories mounted in Workers of the plan.
+	//
+	// They should not overlap with inputs, outputs, log and each other.
+	Scratch []ScratchVolume `json:"scratch,omitempty" yaml:"scratch,omitempty"`
+
+	// ShmSize is the size of shared memory (/dev/shm) of Workers of the plan, like "2Gi".
+	//
+	// If empty, the default of the container runtime is used.
+	ShmSize string `json:"shm_size,omitempty" yaml:"shm_size,omitempty"`
+
+	// ServiceAccount is the Kubernetes ServiceAccount name of the plan.
+	ServiceAccount string `json:"service_account,omitempty" yaml:"service_account,omitempty"`
+
+	// Schedule is the cron schedule of the Plan, like "0 3 * * *" (every day at 03:00 UTC).
+	//
+	// When the schedule comes, a new Run is created with the most recent Data for each input.
+	//
+	// If empty, the Plan is not scheduled.
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+
+	// Priority is the priority of Runs of the Plan.
+	//
+	// When there are Runs ready to start, Runs with higher priority are started earlier.
+	// Default is 0, and it can be negative.
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
+
+	// MaxConcurrency is the maximum number of Runs of the Plan running at once.
+	//
+	// If 0 (or omitted), it is unlimited.
+	MaxConcurrency int `json:"max_concurrency,omitempty" yaml:"max_concurrency,omitempty"`
+
+	// Retry is the policy to retry failed Runs of the Plan automatically.
+	//
+	// If nil, failed Runs are not retried automatically.
+	Retry *RetryPolicy `json:"retry,omitempty" yaml:"retry,omitempty"`
+
+	// MaxDuration is the time limit of a Run of the Plan, in Go duration format (e.g. "1h30m").
+	//
+	// Runs exceeding this are terminated and failed by timeout.
+	// If empty, it is unlimited.
+	MaxDuration string `json:"max_duration,omitempty" yaml:"max_duration,omitempty"`
+
+	// Env is environment variables set to Workers of the Plan.
+	//
+	// Each of them has a static value, or refers a key of a Secret or a ConfigMap
+	// in the namespace where Workers run. Use references for credentials, like API tokens.
+	//
+	// Env is not a part of the identity of the Plan:
+	// Plans differing only in Env are regarded as the same.
+	Env []EnvVar `json:"env,omitempty" yaml:"env,omitempty"`
+
+	// Active shows Plan's activeness.
+	//
+	// If true or nil, the Plan is active and new Runs based the Plan can be started.
+	//
+	// If false, the Plan is inactive and new Runs based the Plan are created but suspended to start.
+	Active *bool `json:"active" yaml:"active,omitempty"`
+}
+
+func (ps PlanSpec) Equal(o PlanSpec) bool {
+	logEq := ps.Log == nil && o.Log == nil || (ps.Log != nil && o.Log != nil && ps.Log.Equal(*o.Log))
+	onNodeEq := ps.OnNode == nil && o.OnNode == nil || (ps.OnNode != nil && o.OnNode != nil && ps.OnNode.Equal(*o.OnNode))
+	activeEq := ps.Active == nil && o.Active == nil || (ps.Active != nil && o.Active != nil && *ps.Active == *o.Active)
+
+	return ps.Annotations.Equal(o.Annotations) &&
+		ps.Image.Equal(&o.Image) &&
+		cmp.SliceEqEq(ps.Entrypoint, o.Entrypoint) &&
+		cmp.SliceEqEq(ps.Args, o.Args) &&
+		cmp.SliceEqualUnordered(ps.Inputs, o.Inputs) &&
+		cmp.SliceEqualUnordered(ps.Outputs, o.Outputs) &&
+		logEq &&
+		onNodeEq &&
+		cmp.MapEqual(ps.Resources, o.Resources) &&
+		cmp.MapEqual(ps.ResourceRequests, o.ResourceRequests) &&
+		cmp.SliceEqEq(ps.Tolerations, o.Tolerations) &&
+		ps.PriorityClassName == o.PriorityClassName &&
+		ps.RuntimeClassName == o.RuntimeClassName &&
+		cmp.SliceEqEq(ps.Scratch, o.Scratch) &&
+		ps.ShmSize == o.ShmSize &&
+		ps.ServiceAccount == o.ServiceAccount &&
+		ps.Schedule == o.Schedule &&
+		ps.Priority == o.Priority &&
+		ps.MaxConcurrency == o.MaxConcurrency &&
+		retryEq(ps.Retry, o.Retry) &&
+		ps.MaxDuration == o.MaxDuration &&
+		cmp.SliceEqual(ps.Env, o.Env) &&
+		activeEq
+}
+
+// ResourceLimitChange is a change of resource limit of plan.
+type ResourceLimitChange struct {
+
+	// Resource to be set.
+	Set Resources `json:"set,omitempty" yaml:"set,omitempty"`
+
+	// Resource types to be unset.
+	//
+	// If same type Set and Unset, Unset is affected.
+	Unset []string `json:"unset,omitempty" yaml:"unset,omitempty"`
+
+	// Resource requests to be set.
+	SetRequests Resources `json:"set_requests,omitempty" yaml:"set_requests,omitempty"`
+
+	// Resource types to be unset their requests.
+	//
+	// If same type SetRequests and UnsetRequests, UnsetRequests is affected.
+	UnsetRequests []string `json:"unset_requests,omitempty" yaml:"unset_requests,omitempty"`
+}
+
+// Toleration is a Kubernetes toleration of Workers of a Plan.
+type Toleration struct {
+	// Key of the taint. If empty, Operator should be "Exists" and it matches all keys.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+
+	// Operator is "Equal" or "Exists". If empty, it is "Equal".
+	Operator string `json:"operator,omitempty" yaml:"operator,omitempty"`
+
+	// Value of the taint. It should be empty when Operator is "Exists".
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// Effect of the taint to be tolerated; "NoSchedule", "PreferNoSchedule" or "NoExecute".
+	//
+	// If empty, it matches all effects.
+	Effect string `json:"effect,omitempty" yaml:"effect,omitempty"`
+}
+
+// ScratchVolume is an ephemeral directory mounted in Workers of a Plan.
+//
+// It is empty when a Worker starts, and discarded when the Worker finishes.
+// Files in it are not recorded as Data.
+type ScratchVolume struct {
+	// Path where the directory is mounted. It should be absolute.
+	Path string `json:"path" yaml:"path"`
+
+	// SizeLimit of the directory, like "10Gi". If empty, it is unlimited.
+	SizeLimit string `json:"size_limit,omitempty" yaml:"size_limit,omitempty"`
+
+	// Medium backing the directory; "" (node's disk) or "Memory" (tmpfs).
+	//
+	// Files in "Memory" count toward the memory limit of the Worker.
+	Medium string `json:"medium,omitempty" yaml:"medium,omitempty"`
+}
+
+// MediumMemory is the Medium of ScratchVolume backed by memory.
+const MediumMemory = "Memory"
+
+// RetryPolicy is the policy to retry failed Runs of a Plan automatically.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts of a Run, including the first one.
+	MaxAttempts int `json:"max_attempts" yaml:"max_attempts"`
+
+	// Backoff is the wait before the first retry, like "30s" or "5m".
+	//
+	// It is doubled for each retry, up to 1 hour. If empty, Runs are retried immediately.
+	Backoff string `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+
+	// On is the causes of exits to be retried.
+	//
+	// - "failed": the Worker of the Run has failed.
+	//
+	// - "stucking": the Worker of the Run has been stucking before running.
+	//
+	// If empty, both of them are retried.
+	On []string `json:"on,omitempty" yaml:"on,omitempty"`
+
+	// ExitCodes restricts retries on "failed" to exits with one of these codes.
+	//
+	// If empty, any exit code is retried.
+	ExitCodes []int `json:"exit_codes,omitempty" yaml:"exit_codes,omitempty"`
+}
+
+func (rp RetryPolicy) Equal(o RetryPolicy) bool {
+	return rp.MaxAttempts == o.MaxAttempts &&
+		rp.Backoff == o.Backoff &&
+		cmp.SliceEqEqUnordered(rp.On, o.On) &&
+		cmp.SliceEqEqUnordered(rp.ExitCodes, o.ExitCodes)
+}
+
+func retryEq(a, b *RetryPolicy) bool {
+	return a == nil && b == nil || (a != nil && b != nil && a.Equal(*b))
+}
+
+// RedactedValue is shown in place of static values of environment variables.
+const RedactedValue = "(redacted)"
+
+// EnvVar is an environment variable set to Workers of a Plan.
+//
+// One of Value or ValueFrom should be given.
+type EnvVar struct {
+	// Name of the environment variable.
+	Name string `json:"name" yaml:"name"`
+
+	// Value is the static value.
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// ValueFrom refers the value in a Secret or a ConfigMap.
+	ValueFrom *EnvVarSource `json:"value_from,omitempty" yaml:"value_from,omitempty"`
+}
+
+func (e EnvVar) Equal(o EnvVar) bool {
+	return e.Name == o.Name &&
+		e.Value == o.Value &&
+		(e.ValueFrom == nil && o.ValueFrom == nil ||
+			(e.ValueFrom != nil && o.ValueFrom != nil && e.ValueFrom.Equal(*o.ValueFrom)))
+}
+
+// EnvVarSource is the source of the value of an environment variable.
+//
+// One of SecretKeyRef or ConfigMapKeyRef should be given.
+type EnvVarSource struct {
+	// SecretKeyRef refers a key of a Secret.
+	SecretKeyRef *KeyRef `json:"secret_key_ref,omitempty" yaml:"secret_key_ref,omitempty"`
+
+	// ConfigMapKeyRef refers a key of a ConfigMap.
+	ConfigMapKeyRef *KeyRef `json:"config_map_key_ref,omitempty" yaml:"config_map_key_ref,omitempty"`
+}
+
+func (s EnvVarSource) Equal(o EnvVarSource) bool {
+	refEq := func(a, b *KeyRef) bool {
+		return a == nil && b == nil || (a != nil && b != nil && *a == *b)
+	}
+	return refEq(s.SecretKeyRef, o.SecretKeyRef) &&
+		refEq(s.ConfigMapKeyRef, o.ConfigMapKeyRef)
+}
+
+// KeyRef refers a key of a Secret or a ConfigMap.
+type KeyRef struct {
+	// Name of the Secret or the ConfigMap.
+	Name string `json:"name" yaml:"name"`
+
+	// Key in the Secret or the ConfigMap.
+	Key string `json:"key" yaml:"key"`
+}
+
+// PriorityChange is a change of the priority and the concurrency limit of a Plan.
+//
+// Fields left nil are not changed.
+type PriorityChange struct {
+	// Priority to be set.
+	Priority *int `json:"priority,omitempty" yaml:"priority,omitempty"`
+
+	// MaxConcurrency to be set. 0 means unlimited.
+	MaxConcurrency *int `json:"max_concurrency,omitempty" yaml:"max_concurrency,omitempty"`
+}
+
+// SetServiceccount declares new ServiceAccount name of a Plan.
+type SetServiceAccount struct {
+	ServiceAccount string `json:"service_account" yaml:"service_account"`
+}
+
+// AnnotationChange is a changeset of Annotations of a Plan.
+//
+// Knitfab WebAPI applies Remove first, then Add.
+type AnnotationChange struct {
+	// Annotations to be added.
+	//
+	// If the Plan to be annotated already has the key, the value is updated.
+	// If same key is set multiple times, the last one is used.
+	Add Annotations `json:"add,omitempty" yaml:"add,omitempty"`
+
+	// Keys of Annotations to be removed.
+	Remove Annotations `json:"remove,omitempty" yaml:"remove,omitempty"`
+
+	RemoveKey []string `json:"remove_key,omitempty" yaml:"remove_key,omitempty"`
+}
@@ -0,0 +1,50 @@
+package usage
+
+import (
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab-api-types/tags"
+)
+
+// Usage is the format for elements of response body from WebAPIs below:
+//
+// - GET /api/usage?by=...
+//
+// It is the storage usage of a group of Data.
+// Only Data which have been measured are counted.
+type Usage struct {
+	// Plan is the Plan generating Data in the group.
+	//
+	// This is set only when Data are grouped by Plans.
+	Plan *plans.Summary `json:"plan,omitempty"`
+
+	// Tag is the Tag which Data in the group have.
+	//
+	// This is set only when Data are grouped by Tags.
+	Tag *tags.Tag `json:"tag,omitempty"`
+
+	// Since is the start of the time range where Data in the group are generated.
+	//
+	// This is set only when Data are grouped by time.
+	Since *rfctime.RFC3339 `json:"since,omitempty"`
+
+	// Bytes is the total size of Data in the group.
+	Bytes int64 `json:"bytes"`
+
+	// Files is the total number of files in Data in the group.
+	Files int64 `json:"files"`
+
+	// Data is the number of Data in the group.
+	Data int64 `json:"data"`
+}
+
+func (u Usage) Equal(o Usage) bool {
+	planEq := (u.Plan == nil && o.Plan == nil) ||
+		(u.Plan != nil && o.Plan != nil && u.Plan.Equal(*o.Plan))
+	tagEq := (u.Tag == nil && o.Tag == nil) ||
+		(u.Tag != nil && o.Tag != nil && *u.Tag == *o.Tag)
+	sinceEq := (u.Since == nil && o.Since == nil) ||
+		(u.Since != nil && o.Since != nil && u.Since.Equal(*o.Since))
+	return planEq && tagEq && sinceEq &&
+		u.Bytes == o.Bytes && u.Files == o.Files && u.Data == o.Data
+}
@@ -0,0 +1,97 @@
+package webhooks
+
+import (
+	"github.com/opst/knitfab-api-types/data"
+	"github.com/opst/knitfab-api-types/plans"
+	"github.com/opst/knitfab-api-types/tags"
+)
+
+// Events of Data sent to "data-hooks".
+const (
+	// DataUploaded is the event that the Data is uploaded by a user.
+	DataUploaded = "uploaded"
+
+	// DataImported is the event that the Data is imported from a PersistentVolumeClaim.
+	DataImported = "imported"
+
+	// DataTagged is the event that Tags of the Data are changed.
+	DataTagged = "tagged"
+)
+
+// DataEvent is the payload of webhooks in "data-hooks".
+//
+// "before" webhooks are called before the change is committed.
+// If any of them responds with a non-2xx status, the change is rejected.
+//
+// "after" webhooks are called after the change is committed.
+type DataEvent struct {
+	// Event is the kind of the event.
+	//
+	// - "uploaded": the Data is uploaded.
+	//
+	// - "imported": the Data is imported.
+	//
+	// - "tagged": Tags of the Data are changed.
+	Event string `json:"event"`
+
+	// Data is the Data.
+	//
+	// For "before" webhooks, it is the Data before the change.
+	Data data.Detail `json:"data"`
+
+	// Tags is the change of Tags. It is set only for "tagged" events.
+	Tags *tags.Change `json:"tags,omitempty"`
+}
+
+// Events of Plans sent to "plan-hooks".
+const (
+	// PlanRegistered is the event that a Plan is registered.
+	PlanRegistered = "registered"
+
+	// PlanActivated is the event that the Plan is activated.
+	PlanActivated = "activated"
+
+	// PlanDeactivated is the event that the Plan is deactivated.
+	PlanDeactivated = "deactivated"
+
+	// PlanResourcesChanged is the event that resources of the Plan are changed.
+	PlanResourcesChanged = "resources"
+
+	// PlanAnnotationsChanged is the event that annotations of the Plan are changed.
+	PlanAnnotationsChanged = "annotations"
+)
+
+// PlanEvent is the payload of webhooks in "plan-hooks".
+//
+// "before" webhooks are called before the change is committed.
+// If any of them responds with a non-2xx status, the change is rejected.
+//
+// "after" webhooks are called after the change is committed.
+type PlanEvent struct {
+	// Event is the kind of the event.
+	//
+	// - "registered": a Plan is registered.
+	//
+	// - "activated" / "deactivated": the Plan is activated / deactivated.
+	//
+	// - "resources": resources of the Plan are changed.
+	//
+	// - "annotations": annotations of the Plan are changed.
+	Event string `json:"event"`
+
+	// Plan is the Plan.
+	//
+	// For "before" webhooks, it is the Plan before the change.
+	// For "before" webhooks of "registered" events, it is not set
+	// because the Plan is not registered yet.
+	Plan *plans.Detail `json:"plan,omitempty"`
+
+	// Spec is the requested Plan definition. It is set only for "registered" events.
+	Spec *plans.PlanSpec `json:"spec,omitempty"`
+
+	// Resources is the change of resources. It is set only for "resources" events.
+	Resources *plans.ResourceLimitChange `json:"resources,omitempty"`
+
+	// Annotations is the change of annotations. It is set only for "annotations" events.
+	Annotations *plans.AnnotationChange `json:"annotations,omitempty"`
+}
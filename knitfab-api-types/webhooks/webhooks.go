@@ -0,0 +1,53 @@
+package webhooks
+
+import (
+	"encoding/json"
+
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+)
+
+// Delivery is a webhook call queued in the webhook outbox of Knitfab.
+//
+// This is the format of elements of response body from WebAPIs below:
+//
+// - GET /api/webhooks/deliveries
+type Delivery struct {
+	// Id is the id of the delivery.
+	//
+	// It is sent to the receiver as X-Knitfab-Delivery header.
+	Id int64 `json:"id"`
+
+	// Hook is the name of the hook configuration which the delivery belongs to.
+	// For example, "lifecycle-hooks".
+	Hook string `json:"hook"`
+
+	// URL is the destination of the delivery.
+	URL string `json:"url"`
+
+	// Status is the status of the delivery.
+	//
+	// - "pending": waiting to be (re)delivered.
+	//
+	// - "failed": given up after all attempts.
+	Status string `json:"status"`
+
+	// Attempts is the number of attempts made so far.
+	Attempts int `json:"attempts"`
+
+	// NextAttemptAt is the time when the delivery is attempted next.
+	//
+	// It is meaningless when the Status is "failed".
+	NextAttemptAt rfctime.RFC3339 `json:"nextAttemptAt"`
+
+	// LastError is the error of the last attempt.
+	LastError string `json:"lastError,omitempty"`
+
+	// CreatedAt is the time when the delivery is queued.
+	CreatedAt rfctime.RFC3339 `json:"createdAt"`
+
+	// UpdatedAt is the time of the last attempt.
+	UpdatedAt rfctime.RFC3339 `json:"updatedAt"`
+
+	// Payload is the body of the request.
+	Payload json.RawMessage `json:"payload"`
+}
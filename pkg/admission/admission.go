@@ -0,0 +1,232 @@
+package admission
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	config "github.com/opst/knitfab/pkg/configs/admission"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/utils/slices"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Policy is an admission policy for Plans, compiled from the configuration.
+//
+// The nil Policy admits any Plans.
+type Policy struct {
+	rules []rule
+}
+
+type rule struct {
+	config.Rule
+	program cel.Program
+}
+
+// Violation is a rule which a Plan does not satisfy.
+type Violation struct {
+	// Rule is the name of the violated rule.
+	Rule string
+
+	// Message describes the violation.
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+// Env returns the CEL environment where rules are evaluated.
+//
+// Rules can use variables and functions below:
+//
+// - plan: the Plan, as a map with keys below:
+//
+//   - image (string): repository of the image, like "registry.example.com/repo"
+//
+//   - tag (string): tag of the image
+//
+//   - entrypoint, args (list of string)
+//
+//   - resources (map of string to double): resources the Plan requires.
+//     Quantities are converted to numbers, e.g. "500m" cpu is 0.5 and "1Ki" memory is 1024.
+//
+//   - on_node (list of map): each has "mode" ("may", "prefer" or "must"), "key" and "value".
+//
+//   - service_account (string): empty if not set.
+//
+//   - annotations (list of map): each has "key" and "value".
+//
+// - quantity(string) -> double: converts a Kubernetes quantity like "64Gi" to a number.
+func Env() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("plan", cel.MapType(cel.StringType, cel.DynType)),
+		cel.CrossTypeNumericComparisons(true),
+		cel.Function(
+			"quantity",
+			cel.Overload(
+				"quantity_string", []*cel.Type{cel.StringType}, cel.DoubleType,
+				cel.UnaryBinding(func(v ref.Val) ref.Val {
+					s, ok := v.(types.String)
+					if !ok {
+						return types.MaybeNoSuchOverloadErr(v)
+					}
+					q, err := resource.ParseQuantity(string(s))
+					if err != nil {
+						return types.NewErr("quantity: %s", err)
+					}
+					return types.Double(q.AsApproximateFloat64())
+				}),
+			),
+		),
+	)
+}
+
+// New compiles the admission policy.
+//
+// It returns an error if any of the rules is not a valid CEL expression evaluated as bool.
+// Expressions whose type is determined on evaluation are accepted,
+// and treated as violated if they are not evaluated as bool.
+func New(cfg config.Config) (*Policy, error) {
+	env, err := Env()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]rule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		ast, iss := env.Compile(r.Expression)
+		if err := iss.Err(); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+		if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+			return nil, fmt.Errorf(
+				"rule %s: expression should be evaluated as bool, but %s", r.Name, ast.OutputType(),
+			)
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+		rules = append(rules, rule{Rule: r, program: prg})
+	}
+
+	return &Policy{rules: rules}, nil
+}
+
+// Admit evaluates the Plan, and returns violated rules.
+//
+// If the Plan is admitted, it returns empty.
+//
+// Rules failed to be evaluated are treated as violated.
+func (p *Policy) Admit(plan Plan) []Violation {
+	if p == nil {
+		return nil
+	}
+
+	input := map[string]any{"plan": plan.value()}
+	violations := []Violation{}
+	for _, r := range p.rules {
+		out, _, err := r.program.Eval(input)
+		if err != nil {
+			violations = append(violations, Violation{
+				Rule:    r.Name,
+				Message: fmt.Sprintf("can not be evaluated: %s", err),
+			})
+			continue
+		}
+		if ok, isBool := out.Value().(bool); isBool && ok {
+			continue
+		}
+		msg := r.Message
+		if msg == "" {
+			msg = fmt.Sprintf("should satisfy %s", r.Expression)
+		}
+		violations = append(violations, Violation{Rule: r.Name, Message: msg})
+	}
+	return violations
+}
+
+// Plan is a Plan to be admitted.
+type Plan struct {
+	Image string
+
+	// Tag of the image. Empty when the image is pinned by Digest.
+	Tag string
+
+	// Digest of the image, like "sha256:...". Empty unless the image is pinned by digest.
+	Digest string
+
+	Entrypoint     []string
+	Args           []string
+	Resources      map[string]resource.Quantity
+	OnNode         []domain.OnNode
+	ServiceAccount string
+	Annotations    []domain.Annotation
+}
+
+// FromSpec returns the Plan to be registered.
+func FromSpec(spec *domain.PlanSpec) Plan {
+	p := Plan{
+		Entrypoint:     spec.Entrypoint(),
+		Args:           spec.Args(),
+		Resources:      spec.Resources(),
+		OnNode:         spec.OnNode(),
+		ServiceAccount: spec.ServiceAccount(),
+		Annotations:    spec.Annotations(),
+	}
+	p.setImage(domain.ImageIdentifier{Image: spec.Image(), Version: spec.Version()})
+	return p
+}
+
+// FromPlan returns the Plan registered.
+func FromPlan(plan *domain.Plan) Plan {
+	p := Plan{
+		Entrypoint:     plan.Entrypoint,
+		Args:           plan.Args,
+		Resources:      plan.Resources,
+		OnNode:         plan.OnNode,
+		ServiceAccount: plan.ServiceAccount,
+		Annotations:    plan.Annotations,
+	}
+	if plan.Image != nil {
+		p.setImage(*plan.Image)
+	}
+	return p
+}
+
+func (p *Plan) setImage(image domain.ImageIdentifier) {
+	p.Image = image.Image
+	if image.IsDigest() {
+		p.Digest = image.Version
+	} else {
+		p.Tag = image.Version
+	}
+}
+
+func (p Plan) value() map[string]any {
+	resources := map[string]any{}
+	for k, q := range p.Resources {
+		resources[k] = q.AsApproximateFloat64()
+	}
+	strs := func(ss []string) []any {
+		return slices.Map(ss, func(s string) any { return s })
+	}
+
+	return map[string]any{
+		"image":      p.Image,
+		"tag":        p.Tag,
+		"digest":     p.Digest,
+		"entrypoint": strs(p.Entrypoint),
+		"args":       strs(p.Args),
+		"resources":  resources,
+		"on_node": slices.Map(p.OnNode, func(o domain.OnNode) any {
+			return map[string]any{"mode": string(o.Mode), "key": o.Key, "value": o.Value}
+		}),
+		"service_account": p.ServiceAccount,
+		"annotations": slices.Map(p.Annotations, func(a domain.Annotation) any {
+			return map[string]any{"key": a.Key, "value": a.Value}
+		}),
+	}
+}
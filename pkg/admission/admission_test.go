@@ -0,0 +1,151 @@
+package admission_test
+
+import (
+	"testing"
+
+	"github.com/opst/knitfab/pkg/admission"
+	config "github.com/opst/knitfab/pkg/configs/admission"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/slices"
+	"github.com/opst/knitfab/pkg/utils/try"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestPolicy_Admit(t *testing.T) {
+	policy := try.To(admission.New(config.Config{
+		Rules: []config.Rule{
+			{
+				Name:       "trusted-registry",
+				Expression: `plan.image.startsWith("registry.internal/")`,
+				Message:    "images must come from registry.internal",
+			},
+			{
+				Name: "memory-limit",
+				Expression: `!has(plan.resources.memory) || plan.resources.memory <= quantity("64Gi") ||
+					plan.annotations.exists(a, a.key == "team" && a.value == "research")`,
+			},
+			{
+				Name:       "service-account",
+				Expression: `plan.service_account in ["", "trainer"]`,
+				Message:    "service account is not allowed",
+			},
+		},
+	})).OrFatal(t)
+
+	base := admission.Plan{
+		Image:     "registry.internal/image",
+		Tag:       "v1",
+		Resources: map[string]resource.Quantity{"cpu": resource.MustParse("1"), "memory": resource.MustParse("1Gi")},
+	}
+
+	for name, testcase := range map[string]struct {
+		plan func(admission.Plan) admission.Plan
+		then []string
+	}{
+		"it admits a plan satisfying all rules": {
+			plan: func(p admission.Plan) admission.Plan { return p },
+			then: []string{},
+		},
+		"it rejects an image from other registries": {
+			plan: func(p admission.Plan) admission.Plan {
+				p.Image = "docker.io/image"
+				return p
+			},
+			then: []string{"trusted-registry"},
+		},
+		"it rejects too much memory": {
+			plan: func(p admission.Plan) admission.Plan {
+				p.Resources = map[string]resource.Quantity{"memory": resource.MustParse("128Gi")}
+				return p
+			},
+			then: []string{"memory-limit"},
+		},
+		"it admits too much memory with the annotation": {
+			plan: func(p admission.Plan) admission.Plan {
+				p.Resources = map[string]resource.Quantity{"memory": resource.MustParse("128Gi")}
+				p.Annotations = []domain.Annotation{{Key: "team", Value: "research"}}
+				return p
+			},
+			then: []string{},
+		},
+		"it admits a plan without memory": {
+			plan: func(p admission.Plan) admission.Plan {
+				p.Resources = map[string]resource.Quantity{}
+				return p
+			},
+			then: []string{},
+		},
+		"it reports all violations": {
+			plan: func(p admission.Plan) admission.Plan {
+				p.Image = "docker.io/image"
+				p.ServiceAccount = "admin"
+				return p
+			},
+			then: []string{"trusted-registry", "service-account"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := policy.Admit(testcase.plan(base))
+			rules := slices.Map(got, func(v admission.Violation) string { return v.Rule })
+			if !cmp.SliceEq(rules, testcase.then) {
+				t.Errorf("violations: %+v, want %v", got, testcase.then)
+			}
+		})
+	}
+
+	t.Run("violation has the message of the rule", func(t *testing.T) {
+		p := base
+		p.ServiceAccount = "admin"
+		got := policy.Admit(p)
+		if len(got) != 1 || got[0].Message != "service account is not allowed" {
+			t.Errorf("violations: %+v", got)
+		}
+	})
+
+	t.Run("nil policy admits anything", func(t *testing.T) {
+		var nilPolicy *admission.Policy
+		if got := nilPolicy.Admit(admission.Plan{Image: "docker.io/image"}); len(got) != 0 {
+			t.Errorf("violations: %+v", got)
+		}
+	})
+}
+
+func TestFromPlan(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	t.Run("it takes the tag of the image", func(t *testing.T) {
+		got := admission.FromPlan(&domain.Plan{PlanBody: domain.PlanBody{
+			Image: &domain.ImageIdentifier{Image: "registry.internal/image", Version: "v1"},
+		}})
+		if got.Image != "registry.internal/image" || got.Tag != "v1" || got.Digest != "" {
+			t.Errorf("unexpected plan: %+v", got)
+		}
+	})
+
+	t.Run("it takes the digest of the image pinned by digest", func(t *testing.T) {
+		got := admission.FromPlan(&domain.Plan{PlanBody: domain.PlanBody{
+			Image: &domain.ImageIdentifier{Image: "registry.internal/image", Version: digest},
+		}})
+		if got.Image != "registry.internal/image" || got.Tag != "" || got.Digest != digest {
+			t.Errorf("unexpected plan: %+v", got)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	for name, expr := range map[string]string{
+		"syntax error":     `plan.image ==`,
+		"not bool":         `1 + 1`,
+		"unknown variable": `run.image == "x"`,
+	} {
+		t.Run("it rejects "+name, func(t *testing.T) {
+			_, err := admission.New(config.Config{
+				Rules: []config.Rule{{Name: "rule", Expression: expr}},
+			})
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package audit
+
+import (
+	"github.com/opst/knitfab-api-types/audit"
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	"github.com/opst/knitfab/pkg/domain"
+)
+
+func Compose(e domain.AuditEntry) audit.Entry {
+	return audit.Entry{
+		Id:        e.Id,
+		Timestamp: rfctime.RFC3339(e.Timestamp),
+		Actor:     e.Actor,
+		Action:    string(e.Action),
+		Target: audit.Target{
+			Type: string(e.TargetType),
+			Id:   e.TargetId,
+		},
+		Before: e.Before,
+		After:  e.After,
+	}
+}
@@ -59,5 +59,15 @@ func ComposeDetail(d domain.KnitData) data.Detail {
 		Upstream:    composeCreatedFrom(d.Upsteram),
 		Downstreams: slices.Map(downstreams, composeAssignTo),
 		Nomination:  slices.Map(d.NominatedBy, composeNominatedBy),
+		Size:        composeSize(d.Size),
+		Checksum:    d.Checksum,
+		Parameter:   d.Parameter,
 	}
 }
+
+func composeSize(s *domain.DataSize) *data.Size {
+	if s == nil {
+		return nil
+	}
+	return &data.Size{Bytes: s.Bytes, Files: s.Files}
+}
@@ -31,8 +31,13 @@ func ComposeDownstream(d domain.PlanDownstream) apiplans.Downstream {
 }
 
 func ComposeInputs(i domain.Input) apiplans.Input {
+	mountpoint := ComposeMountpoint(i.MountPoint)
+	if !i.Selection.IsAll() {
+		mountpoint.Select = i.Selection.String()
+	}
+	mountpoint.Values = i.Values
 	return apiplans.Input{
-		Mountpoint: ComposeMountpoint(i.MountPoint),
+		Mountpoint: mountpoint,
 		Upstreams: slices.Map(i.Upstreams, func(u domain.PlanUpstream) apiplans.Upstream {
 			var mp *apiplans.Mountpoint = nil
 			if u.Mountpoint != nil {
@@ -80,6 +85,16 @@ func ComposeDetail(plan domain.Plan) apiplans.Detail {
 		}
 	}
 
+	maxDuration := ""
+	if plan.MaxDuration != 0 {
+		maxDuration = plan.MaxDuration.String()
+	}
+
+	shmSize := ""
+	if !plan.ShmSize.IsZero() {
+		shmSize = plan.ShmSize.String()
+	}
+
 	return apiplans.Detail{
 		Summary:        ComposeSummary(plan.PlanBody),
 		Active:         plan.Active,
@@ -89,9 +104,85 @@ func ComposeDetail(plan domain.Plan) apiplans.Detail {
 		Log:            log,
 		OnNode:         onNode,
 		ServiceAccount: plan.ServiceAccount,
+		Supersedes:     plan.Supersedes,
+		SupersededBy:   plan.SupersededBy,
+		Schedule:       plan.Schedule,
+		Priority:       plan.Priority,
+		MaxConcurrency: plan.MaxConcurrency,
+		Retry:          ComposeRetryPolicy(plan.Retry),
+		MaxDuration:    maxDuration,
+		Env:            slices.Map(plan.Env, ComposeEnvVar),
+
+		ResourceRequests:  apiplans.Resources(plan.ResourceRequests),
+		Tolerations:       slices.Map(plan.Tolerations, ComposeToleration),
+		PriorityClassName: plan.PriorityClassName,
+		RuntimeClassName:  plan.RuntimeClassName,
+
+		Scratch: slices.Map(plan.Scratches, ComposeScratchVolume),
+		ShmSize: shmSize,
+	}
+}
+
+// ComposeScratchVolume converts domain.ScratchVolume to apiplans.ScratchVolume.
+func ComposeScratchVolume(s domain.ScratchVolume) apiplans.ScratchVolume {
+	ret := apiplans.ScratchVolume{Path: s.Path}
+	if !s.SizeLimit.IsZero() {
+		ret.SizeLimit = s.SizeLimit.String()
+	}
+	if s.InMemory {
+		ret.Medium = apiplans.MediumMemory
+	}
+	return ret
+}
+
+// ComposeToleration converts domain.Toleration to apiplans.Toleration.
+func ComposeToleration(t domain.Toleration) apiplans.Toleration {
+	return apiplans.Toleration{
+		Key:      t.Key,
+		Operator: string(t.Operator),
+		Value:    t.Value,
+		Effect:   string(t.Effect),
 	}
 }
 
+// ComposeEnvVar converts domain.EnvVar to apiplans.EnvVar.
+//
+// Static values are redacted, since they may be confidential.
+func ComposeEnvVar(e domain.EnvVar) apiplans.EnvVar {
+	ret := apiplans.EnvVar{Name: e.Name}
+	switch {
+	case e.Secret != nil:
+		ret.ValueFrom = &apiplans.EnvVarSource{
+			SecretKeyRef: &apiplans.KeyRef{Name: e.Secret.Name, Key: e.Secret.Key},
+		}
+	case e.ConfigMap != nil:
+		ret.ValueFrom = &apiplans.EnvVarSource{
+			ConfigMapKeyRef: &apiplans.KeyRef{Name: e.ConfigMap.Name, Key: e.ConfigMap.Key},
+		}
+	default:
+		ret.Value = apiplans.RedactedValue
+	}
+	return ret
+}
+
+// ComposeRetryPolicy converts domain.RetryPolicy to apiplans.RetryPolicy.
+//
+// It returns nil if the given policy is nil.
+func ComposeRetryPolicy(rp *domain.RetryPolicy) *apiplans.RetryPolicy {
+	if rp == nil {
+		return nil
+	}
+	ret := &apiplans.RetryPolicy{
+		MaxAttempts: rp.MaxAttempts,
+		On:          slices.Map(rp.On, func(c domain.ExitCause) string { return string(c) }),
+		ExitCodes:   slices.Map(rp.ExitCodes, func(c uint8) int { return int(c) }),
+	}
+	if rp.Backoff != 0 {
+		ret.Backoff = rp.Backoff.String()
+	}
+	return ret
+}
+
 func ComposeSummary(planBody domain.PlanBody) apiplans.Summary {
 	rst := apiplans.Summary{
 		PlanId:     planBody.PlanId,
@@ -99,7 +190,11 @@ func ComposeSummary(planBody domain.PlanBody) apiplans.Summary {
 		Args:       planBody.Args,
 	}
 	if i := planBody.Image; i != nil {
-		rst.Image = &apiplans.Image{Repository: i.Image, Tag: i.Version}
+		if i.IsDigest() {
+			rst.Image = &apiplans.Image{Repository: i.Image, Digest: i.Version}
+		} else {
+			rst.Image = &apiplans.Image{Repository: i.Image, Tag: i.Version}
+		}
 	}
 	if p := planBody.Pseudo; p != nil {
 		rst.Name = p.Name.String()
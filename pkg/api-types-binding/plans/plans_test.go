@@ -2,11 +2,13 @@ package plans_test
 
 import (
 	"testing"
+	"time"
 
 	apiplans "github.com/opst/knitfab-api-types/plans"
 	apitags "github.com/opst/knitfab-api-types/tags"
 	bindplan "github.com/opst/knitfab/pkg/api-types-binding/plans"
 	"github.com/opst/knitfab/pkg/domain"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestComposeDetail(t *testing.T) {
@@ -24,6 +26,34 @@ func TestComposeDetail(t *testing.T) {
 					Entrypoint:     []string{"python", "main.py"},
 					Args:           []string{"--arg1", "val1", "--arg2", "val2"},
 					ServiceAccount: "service-account-name",
+					Schedule:       "0 3 * * *",
+					Priority:       10,
+					MaxConcurrency: 2,
+					Retry: &domain.RetryPolicy{
+						MaxAttempts: 3,
+						Backoff:     30 * time.Second,
+						On:          []domain.ExitCause{domain.ExitByFailure},
+						ExitCodes:   []uint8{137},
+					},
+					MaxDuration: 2 * time.Hour,
+					Env: []domain.EnvVar{
+						{Name: "MODE", Value: "train"},
+						{Name: "API_TOKEN", Secret: &domain.EnvVarKeyRef{Name: "api", Key: "token"}},
+						{Name: "ENDPOINT", ConfigMap: &domain.EnvVarKeyRef{Name: "api", Key: "endpoint"}},
+					},
+					ResourceRequests: map[string]resource.Quantity{
+						"cpu": resource.MustParse("500m"),
+					},
+					Tolerations: []domain.Toleration{
+						{Key: "nvidia.com/gpu", Operator: domain.TolerationOpExists, Effect: domain.TaintEffectNoSchedule},
+					},
+					PriorityClassName: "high-priority",
+					RuntimeClassName:  "nvidia",
+					Scratches: []domain.ScratchVolume{
+						{Path: "/cache", InMemory: true},
+						{Path: "/tmp", SizeLimit: resource.MustParse("10Gi")},
+					},
+					ShmSize: resource.MustParse("2Gi"),
 					Annotations: []domain.Annotation{
 						{Key: "anno1", Value: "val1"},
 						{Key: "anno2", Value: "val2"},
@@ -37,6 +67,8 @@ func TestComposeDetail(t *testing.T) {
 								{Key: "key1", Value: "val1"},
 							}),
 						},
+						Selection: domain.InputSelection{Latest: 2},
+						Values:    []string{"0.1", "0.01"},
 						Upstreams: []domain.PlanUpstream{
 							{
 								PlanBody: domain.PlanBody{
@@ -166,6 +198,8 @@ func TestComposeDetail(t *testing.T) {
 							Tags: []apitags.Tag{
 								{Key: "key1", Value: "val1"},
 							},
+							Select: "latest:2",
+							Values: []string{"0.1", "0.01"},
 						},
 						Upstreams: []apiplans.Upstream{
 							{
@@ -290,6 +324,44 @@ func TestComposeDetail(t *testing.T) {
 				},
 				Active:         true,
 				ServiceAccount: "service-account-name",
+				Schedule:       "0 3 * * *",
+				Priority:       10,
+				MaxConcurrency: 2,
+				Retry: &apiplans.RetryPolicy{
+					MaxAttempts: 3,
+					Backoff:     "30s",
+					On:          []string{"failed"},
+					ExitCodes:   []int{137},
+				},
+				MaxDuration: "2h0m0s",
+				Env: []apiplans.EnvVar{
+					{Name: "MODE", Value: apiplans.RedactedValue},
+					{
+						Name: "API_TOKEN",
+						ValueFrom: &apiplans.EnvVarSource{
+							SecretKeyRef: &apiplans.KeyRef{Name: "api", Key: "token"},
+						},
+					},
+					{
+						Name: "ENDPOINT",
+						ValueFrom: &apiplans.EnvVarSource{
+							ConfigMapKeyRef: &apiplans.KeyRef{Name: "api", Key: "endpoint"},
+						},
+					},
+				},
+				ResourceRequests: apiplans.Resources{
+					"cpu": resource.MustParse("500m"),
+				},
+				Tolerations: []apiplans.Toleration{
+					{Key: "nvidia.com/gpu", Operator: "Exists", Effect: "NoSchedule"},
+				},
+				PriorityClassName: "high-priority",
+				RuntimeClassName:  "nvidia",
+				Scratch: []apiplans.ScratchVolume{
+					{Path: "/cache", Medium: "Memory"},
+					{Path: "/tmp", SizeLimit: "10Gi"},
+				},
+				ShmSize: "2Gi",
 			},
 		},
 		"When a plan without log is passed, it should compose a Detail corresponding to the plan.": {
@@ -10,23 +10,39 @@ import (
 	"github.com/opst/knitfab/pkg/utils/slices"
 )
 
-func ComposeSummary(r domain.RunBody) runs.Summary {
-	var exit *runs.Exit
-	if ex := r.Exit; ex != nil {
-		exit = &runs.Exit{
-			Code:    ex.Code,
-			Message: ex.Message,
-		}
+func composeExit(ex *domain.RunExit) *runs.Exit {
+	if ex == nil {
+		return nil
+	}
+	return &runs.Exit{
+		Code:    ex.Code,
+		Message: ex.Message,
+		Cause:   string(ex.Cause),
 	}
+}
+
+func ComposeSummary(r domain.RunBody) runs.Summary {
 	return runs.Summary{
 		RunId:     r.Id,
 		Plan:      bindplan.ComposeSummary(r.PlanBody),
 		Status:    string(r.Status),
-		Exit:      exit,
+		Exit:      composeExit(r.Exit),
+		Attempt:   r.Attempt,
+		Hold:      r.HoldReason,
+		ImageId:   r.ImageId,
 		UpdatedAt: rfctime.RFC3339(r.UpdatedAt),
 	}
 }
 
+func ComposeAttempt(a domain.RunAttempt) runs.Attempt {
+	return runs.Attempt{
+		Attempt:   a.Attempt,
+		Status:    string(a.Status),
+		UpdatedAt: rfctime.RFC3339(a.UpdatedAt),
+		Exit:      composeExit(a.Exit),
+	}
+}
+
 func ComposeDetail(r domain.Run) runs.Detail {
 	var logSummary *runs.LogSummary
 	if r.Log != nil {
@@ -56,6 +72,21 @@ func ComposeDetail(r domain.Run) runs.Detail {
 				}
 			},
 		),
-		Log: logSummary,
+		Log:      logSummary,
+		Attempts: slices.Map(r.Attempts, ComposeAttempt),
+	}
+}
+
+func ComposePreview(p domain.RunPreview) runs.Preview {
+	return runs.Preview{
+		Plan: bindplan.ComposeSummary(p.Plan),
+		Inputs: slices.Map(
+			p.Inputs, func(a domain.Assignment) runs.Assignment {
+				return runs.Assignment{
+					KnitId:     a.KnitDataBody.KnitId,
+					Mountpoint: bindplan.ComposeMountpoint(a.MountPoint),
+				}
+			},
+		),
 	}
 }
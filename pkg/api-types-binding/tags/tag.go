@@ -3,8 +3,18 @@ package tags
 import (
 	"github.com/opst/knitfab-api-types/tags"
 	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/utils/slices"
 )
 
 func Compose(dbtag domain.Tag) tags.Tag {
 	return tags.Tag(dbtag)
 }
+
+func ComposeChange(delta domain.TagDelta) tags.Change {
+	userTag := func(t domain.Tag) tags.UserTag { return tags.UserTag(Compose(t)) }
+	return tags.Change{
+		AddTags:    slices.Map(delta.Add, userTag),
+		RemoveTags: slices.Map(delta.Remove, userTag),
+		RemoveKey:  delta.RemoveKey,
+	}
+}
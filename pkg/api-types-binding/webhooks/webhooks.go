@@ -0,0 +1,29 @@
+package webhooks
+
+import (
+	"encoding/json"
+
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	"github.com/opst/knitfab-api-types/webhooks"
+	"github.com/opst/knitfab/pkg/domain"
+)
+
+func Compose(d domain.WebhookDelivery) webhooks.Delivery {
+	payload := json.RawMessage(d.Payload)
+	if !json.Valid(payload) {
+		// payloads are always JSON, but just in case.
+		payload, _ = json.Marshal(string(d.Payload))
+	}
+	return webhooks.Delivery{
+		Id:            d.Id,
+		Hook:          d.Hook,
+		URL:           d.URL,
+		Status:        string(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: rfctime.RFC3339(d.NextAttemptAt),
+		LastError:     d.LastError,
+		CreatedAt:     rfctime.RFC3339(d.CreatedAt),
+		UpdatedAt:     rfctime.RFC3339(d.UpdatedAt),
+		Payload:       payload,
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+func Load(filename string) (Config, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Config is an admission policy for Plans.
+//
+// A Plan is admitted if and only if it satisfies all of the rules.
+type Config struct {
+	Rules []Rule `yaml:"rules,omitempty"`
+}
+
+// Rule is a rule of admission policy.
+type Rule struct {
+	// Name is the name of the rule.
+	Name string `yaml:"name"`
+
+	// Expression is a CEL expression which should be evaluated to true for admitted Plans.
+	//
+	// The Plan is given as the variable "plan".
+	Expression string `yaml:"expression"`
+
+	// Message is the message shown to users when the rule is violated.
+	//
+	// If empty, a message is generated from the name and the expression.
+	Message string `yaml:"message,omitempty"`
+}
+
+func (r *Rule) UnmarshalYAML(node *yaml.Node) error {
+	type raw Rule
+	var rr raw
+	if err := node.Decode(&rr); err != nil {
+		return err
+	}
+	if rr.Name == "" {
+		return errors.New("rule: name is required")
+	}
+	if rr.Expression == "" {
+		return fmt.Errorf("rule %s: expression is required", rr.Name)
+	}
+	*r = Rule(rr)
+	return nil
+}
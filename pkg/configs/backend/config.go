@@ -1,6 +1,8 @@
 package backend
 
 import (
+	"time"
+
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -27,6 +29,7 @@ type KnitClusterConfig struct {
 	dataAgent *DataAgentConfig
 	worker    *WorkerConfig
 	keychains *KeychainsConfig
+	retention []*RetentionConfig
 }
 
 // k8s namespace where Knit is deploied.
@@ -58,6 +61,38 @@ func (l *KnitClusterConfig) Keychains() *KeychainsConfig {
 	return l.keychains
 }
 
+// Retention rules expiring Data.
+func (l *KnitClusterConfig) Retention() []*RetentionConfig {
+	return l.retention
+}
+
+// Retention rule expiring Data which have all of Tags, after Age has passed.
+type RetentionConfig struct {
+	name string
+	tags []RetentionTag
+	age  time.Duration
+}
+
+// Tag of Data in the scope of retention rule.
+type RetentionTag struct {
+	Key   string
+	Value string
+}
+
+func (rc *RetentionConfig) Name() string {
+	return rc.name
+}
+
+// Tags which Data in the scope have.
+func (rc *RetentionConfig) Tags() []RetentionTag {
+	return rc.tags
+}
+
+// Duration to keep Data, since their timestamp.
+func (rc *RetentionConfig) Age() time.Duration {
+	return rc.age
+}
+
 // Configuration for Dataagt
 type DataAgentConfig struct {
 	image  string
@@ -96,15 +131,27 @@ func (d *VolumeConfig) InitialCapacity() resource.Quantity {
 }
 
 type WorkerConfig struct {
-	priority string
-	init     *InitContainerConfig
-	nurse    *NurseContainerConfig
+	priority          string
+	maxConcurrentRuns int
+	quotas            []*QuotaConfig
+	init              *InitContainerConfig
+	nurse             *NurseContainerConfig
 }
 
 func (wc *WorkerConfig) Priority() string {
 	return wc.priority
 }
 
+// The max number of Runs being starting or running at once. 0 means unlimited.
+func (wc *WorkerConfig) MaxConcurrentRuns() int {
+	return wc.maxConcurrentRuns
+}
+
+// Quotas capping resources held by Runs being starting or running.
+func (wc *WorkerConfig) Quotas() []*QuotaConfig {
+	return wc.quotas
+}
+
 func (wc *WorkerConfig) Init() *InitContainerConfig {
 	return wc.init
 }
@@ -113,6 +160,38 @@ func (wc *WorkerConfig) Nurse() *NurseContainerConfig {
 	return wc.nurse
 }
 
+// Quota of resources for Runs of Plans in its scope.
+type QuotaConfig struct {
+	name            string
+	annotationKey   string
+	annotationValue string
+	user            string
+	limits          map[string]resource.Quantity
+}
+
+func (qc *QuotaConfig) Name() string {
+	return qc.name
+}
+
+// Annotation which Plans in the scope have.
+//
+// ok is false if the scope is not restricted by annotation.
+func (qc *QuotaConfig) Annotation() (key string, value string, ok bool) {
+	return qc.annotationKey, qc.annotationValue, qc.annotationKey != ""
+}
+
+// User (email address) who registered Plans in the scope.
+//
+// Empty if the scope is not restricted by user.
+func (qc *QuotaConfig) User() string {
+	return qc.user
+}
+
+// Limits of resources in total.
+func (qc *QuotaConfig) Limits() map[string]resource.Quantity {
+	return qc.limits
+}
+
 type InitContainerConfig struct {
 	image string
 }
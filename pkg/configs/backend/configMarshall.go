@@ -2,6 +2,8 @@ package backend
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 )
@@ -45,6 +47,9 @@ type KnitClusterConfigMarshall struct {
 	DataAgent *DataAgentConfigMarshall `yaml:"dataAgent"`
 	Worker    *WorkerConfigMarshall    `yaml:"worker"`
 	Keychains *KeychainsConfigMarshall `yaml:"keychains"`
+
+	// Retention rules expiring Data. Optional.
+	Retention []*RetentionConfigMarshall `yaml:"retention,omitempty"`
 }
 
 // verify configuration value and create "readonly" version of this.
@@ -59,6 +64,18 @@ func (km *KnitClusterConfigMarshall) trySeal(path string) *KnitClusterConfig {
 	if domain == "" {
 		domain = "cluster.local"
 	}
+	retention := make([]*RetentionConfig, 0, len(km.Retention))
+	names := map[string]struct{}{}
+	for i, r := range km.Retention {
+		rpath := fmt.Sprintf("%s.retention[%d]", path, i)
+		sealed := nonnil(r, rpath).trySeal(rpath)
+		if _, ok := names[sealed.name]; ok {
+			panic(fmt.Sprintf("%s.name: retention rule %s is duplicated", rpath, sealed.name))
+		}
+		names[sealed.name] = struct{}{}
+		retention = append(retention, sealed)
+	}
+
 	return &KnitClusterConfig{
 		namespace: required(km.Namespace, path+".namespace"),
 		domain:    required(domain, path+".domain"),
@@ -66,6 +83,45 @@ func (km *KnitClusterConfigMarshall) trySeal(path string) *KnitClusterConfig {
 		dataAgent: nonnil(km.DataAgent, path+".dataAgent").trySeal(path + ".dataAgent"),
 		worker:    nonnil(km.Worker, path+".worker").trySeal(path + ".worker"),
 		keychains: nonnil(km.Keychains, path+".keychain").trySeal(path + ".keychain"),
+		retention: retention,
+	}
+}
+
+type RetentionConfigMarshall struct {
+	Name string `yaml:"name"`
+
+	// Tags of Data in the scope, as "key:value". At least one tag is required.
+	Tags []string `yaml:"tags"`
+
+	// Duration to keep Data, like "168h".
+	Age string `yaml:"age"`
+}
+
+func (rc *RetentionConfigMarshall) trySeal(path string) *RetentionConfig {
+	if len(rc.Tags) == 0 {
+		panic(path + ".tags is required")
+	}
+	tags := make([]RetentionTag, 0, len(rc.Tags))
+	for i, t := range rc.Tags {
+		k, v, ok := strings.Cut(t, ":")
+		if !ok || k == "" {
+			panic(fmt.Sprintf(`%s.tags[%d] should be formatted as "key:value"`, path, i))
+		}
+		tags = append(tags, RetentionTag{Key: k, Value: v})
+	}
+
+	age, err := time.ParseDuration(required(rc.Age, path+".age"))
+	if err != nil {
+		panic(fmt.Errorf("%s.age can not be parsed: %w", path, err))
+	}
+	if age <= 0 {
+		panic(path + ".age should be positive")
+	}
+
+	return &RetentionConfig{
+		name: required(rc.Name, path+".name"),
+		tags: tags,
+		age:  age,
 	}
 }
 
@@ -103,16 +159,83 @@ func (dm *VolumeConfigMarshall) trySeal(path string) *VolumeConfig {
 }
 
 type WorkerConfigMarshall struct {
-	Priority string                        `yaml:"priority"`
-	Init     *InitContainerConfigMarshall  `yaml:"init"`
-	Nurse    *NurseContainerConfigMarshall `yaml:"nurse"`
+	Priority string `yaml:"priority"`
+
+	// The max number of Runs being starting or running at once. Optional. 0 means unlimited.
+	MaxConcurrentRuns int `yaml:"maxConcurrentRuns"`
+
+	// Quotas capping resources held by Runs being starting or running. Optional.
+	Quotas []*QuotaConfigMarshall        `yaml:"quotas,omitempty"`
+	Init   *InitContainerConfigMarshall  `yaml:"init"`
+	Nurse  *NurseContainerConfigMarshall `yaml:"nurse"`
 }
 
 func (wc *WorkerConfigMarshall) trySeal(path string) *WorkerConfig {
+	if wc.MaxConcurrentRuns < 0 {
+		panic(path + ".maxConcurrentRuns should not be negative")
+	}
+	quotas := make([]*QuotaConfig, 0, len(wc.Quotas))
+	names := map[string]struct{}{}
+	for i, q := range wc.Quotas {
+		qpath := fmt.Sprintf("%s.quotas[%d]", path, i)
+		sealed := nonnil(q, qpath).trySeal(qpath)
+		if _, ok := names[sealed.name]; ok {
+			panic(fmt.Sprintf("%s.name: quota %s is duplicated", qpath, sealed.name))
+		}
+		names[sealed.name] = struct{}{}
+		quotas = append(quotas, sealed)
+	}
+
 	return &WorkerConfig{
-		priority: required(wc.Priority, path+".priority"),
-		init:     nonnil(wc.Init, path+".init").trySeal(path + ".init"),
-		nurse:    nonnil(wc.Nurse, path+".nurse").trySeal(path + ".nurse"),
+		priority:          required(wc.Priority, path+".priority"),
+		maxConcurrentRuns: wc.MaxConcurrentRuns,
+		quotas:            quotas,
+		init:              nonnil(wc.Init, path+".init").trySeal(path + ".init"),
+		nurse:             nonnil(wc.Nurse, path+".nurse").trySeal(path + ".nurse"),
+	}
+}
+
+type QuotaConfigMarshall struct {
+	Name string `yaml:"name"`
+
+	// Annotation of Plans in the scope, as "key=value". Optional.
+	Annotation string `yaml:"annotation,omitempty"`
+
+	// User (email address) who registered Plans in the scope. Optional.
+	User string `yaml:"user,omitempty"`
+
+	// Limits of resources in total, like "cpu: 16" or "memory: 64Gi".
+	Limits map[string]string `yaml:"limits"`
+}
+
+func (qc *QuotaConfigMarshall) trySeal(path string) *QuotaConfig {
+	var key, value string
+	if qc.Annotation != "" {
+		k, v, ok := strings.Cut(qc.Annotation, "=")
+		if !ok || k == "" {
+			panic(path + `.annotation should be formatted as "key=value"`)
+		}
+		key, value = k, v
+	}
+
+	if len(qc.Limits) == 0 {
+		panic(path + ".limits is required")
+	}
+	limits := map[string]resource.Quantity{}
+	for typ, l := range qc.Limits {
+		q, err := resource.ParseQuantity(l)
+		if err != nil {
+			panic(fmt.Errorf("%s.limits.%s can not be parsed: %w", path, typ, err))
+		}
+		limits[typ] = q
+	}
+
+	return &QuotaConfig{
+		name:            required(qc.Name, path+".name"),
+		annotationKey:   key,
+		annotationValue: value,
+		user:            qc.User,
+		limits:          limits,
 	}
 }
 
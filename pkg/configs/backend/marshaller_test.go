@@ -2,8 +2,10 @@ package backend_test
 
 import (
 	"testing"
+	"time"
 
 	kback "github.com/opst/knitfab/pkg/configs/backend"
+	"github.com/opst/knitfab/pkg/utils/cmp"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -22,6 +24,17 @@ cluster:
       initialCapacity: 8Gi
   worker:
     priority: knit-worker-priority
+    maxConcurrentRuns: 10
+    quotas:
+      - name: research
+        annotation: team=research
+        limits:
+          cpu: "16"
+          nvidia.com/gpu: "2"
+      - name: alice
+        user: alice@example.com
+        limits:
+          memory: 64Gi
     init:
       image: knit-repo/init:v0.0.2
     nurse:
@@ -30,6 +43,13 @@ cluster:
   keychains:
     signKeyForImportToken:
       name: fake-sign-key-name
+  retention:
+    - name: tmp
+      tags: ["tmp:true"]
+      age: 168h
+    - name: scratch
+      tags: ["type:scratch", "project:example"]
+      age: 30m
 `)
 		result, err := kback.Unmarshal(backendYml)
 
@@ -109,6 +129,46 @@ cluster:
 			}
 		})
 
+		t.Run(".cluster.worker.maxConcurrentRuns", func(t *testing.T) {
+			actual := result.Cluster().Worker().MaxConcurrentRuns()
+			expected := 10
+			if actual != expected {
+				t.Errorf("mismatch. (expected, actual) = (%v, %v)", expected, actual)
+			}
+		})
+
+		t.Run(".cluster.worker.quotas", func(t *testing.T) {
+			quotas := result.Cluster().Worker().Quotas()
+			if len(quotas) != 2 {
+				t.Fatalf("unexpected quotas: %+v", quotas)
+			}
+
+			research := quotas[0]
+			if research.Name() != "research" || research.User() != "" {
+				t.Errorf("unexpected quota: %+v", research)
+			}
+			if key, value, ok := research.Annotation(); !ok || key != "team" || value != "research" {
+				t.Errorf("annotation: (key, value, ok) = (%s, %s, %v)", key, value, ok)
+			}
+			if cpu := research.Limits()["cpu"]; !cpu.Equal(resource.MustParse("16")) {
+				t.Errorf("cpu: %v", cpu)
+			}
+			if gpu := research.Limits()["nvidia.com/gpu"]; !gpu.Equal(resource.MustParse("2")) {
+				t.Errorf("gpu: %v", gpu)
+			}
+
+			alice := quotas[1]
+			if alice.Name() != "alice" || alice.User() != "alice@example.com" {
+				t.Errorf("unexpected quota: %+v", alice)
+			}
+			if _, _, ok := alice.Annotation(); ok {
+				t.Error("annotation should not be set")
+			}
+			if mem := alice.Limits()["memory"]; !mem.Equal(resource.MustParse("64Gi")) {
+				t.Errorf("memory: %v", mem)
+			}
+		})
+
 		t.Run(".cluster.worker.nurse.image", func(t *testing.T) {
 			actual := result.Cluster().Worker().Nurse().Image()
 			expected := "knit-repo/nurse:v0.0.3"
@@ -132,5 +192,32 @@ cluster:
 				t.Errorf("mismatch. (expected, actual) = (%v, %v)", expected, actual)
 			}
 		})
+
+		t.Run(".cluster.retention", func(t *testing.T) {
+			retention := result.Cluster().Retention()
+			if len(retention) != 2 {
+				t.Fatalf("unexpected retention: %+v", retention)
+			}
+
+			tmp := retention[0]
+			if tmp.Name() != "tmp" || tmp.Age() != 168*time.Hour {
+				t.Errorf("unexpected retention rule: %+v", tmp)
+			}
+			if want := []kback.RetentionTag{{Key: "tmp", Value: "true"}}; !cmp.SliceEq(tmp.Tags(), want) {
+				t.Errorf("tags: %+v, want %+v", tmp.Tags(), want)
+			}
+
+			scratch := retention[1]
+			if scratch.Name() != "scratch" || scratch.Age() != 30*time.Minute {
+				t.Errorf("unexpected retention rule: %+v", scratch)
+			}
+			want := []kback.RetentionTag{
+				{Key: "type", Value: "scratch"},
+				{Key: "project", Value: "example"},
+			}
+			if !cmp.SliceEq(scratch.Tags(), want) {
+				t.Errorf("tags: %+v, want %+v", scratch.Tags(), want)
+			}
+		})
 	})
 }
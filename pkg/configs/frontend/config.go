@@ -1,7 +1,33 @@
 package frontend
 
+import "time"
+
 type FrontendConfig struct {
-	DBURI          string `yaml:"dburi"`
-	BackendApiRoot string `yaml:"backendapiroot"`
-	ServerPort     string `yaml:"serverport"`
+	DBURI          string     `yaml:"dburi"`
+	BackendApiRoot string     `yaml:"backendapiroot"`
+	ServerPort     string     `yaml:"serverport"`
+	Auth           AuthConfig `yaml:"auth"`
+}
+
+type AuthConfig struct {
+	// Enabled is true when WebAPIs require bearer token.
+	//
+	// When false, every request is treated as admin's.
+	Enabled bool `yaml:"enabled"`
+
+	// TokenTTL is the lifetime of issued tokens. Default is 24h.
+	TokenTTL time.Duration `yaml:"tokenTTL"`
+
+	// Admin is the initial admin user.
+	//
+	// If there are no users with the email, knitd registers it on start up.
+	Admin *InitialAdmin `yaml:"admin,omitempty"`
+}
+
+type InitialAdmin struct {
+	Email string `yaml:"email"`
+	Name  string `yaml:"name"`
+
+	// PasswordFile is a path to file containing the password of the admin.
+	PasswordFile string `yaml:"passwordFile"`
 }
@@ -2,6 +2,7 @@ package frontend_test
 
 import (
 	"testing"
+	"time"
 
 	kcf "github.com/opst/knitfab/pkg/configs/frontend"
 )
@@ -29,4 +30,40 @@ func TestLoadFrontendConfig(t *testing.T) {
 
 	})
 
+	t.Run("it has auth disabled by default", func(t *testing.T) {
+		result, err := kcf.LoadFrontendConfig("./testdata/config.yaml")
+		if err != nil {
+			t.Fatalf("failed to parse config.: %v", err)
+		}
+		if result.Auth.Enabled {
+			t.Errorf("auth should be disabled")
+		}
+		if result.Auth.TokenTTL != 24*time.Hour {
+			t.Errorf("unmatch tokenTTL:%s, expected:%s", result.Auth.TokenTTL, 24*time.Hour)
+		}
+		if result.Auth.Admin != nil {
+			t.Errorf("unexpected admin: %+v", result.Auth.Admin)
+		}
+	})
+
+	t.Run("it can be created from a config file with auth", func(t *testing.T) {
+		result, err := kcf.LoadFrontendConfig("./testdata/config-with-auth.yaml")
+		if err != nil {
+			t.Fatalf("failed to parse config.: %v", err)
+		}
+		if !result.Auth.Enabled {
+			t.Errorf("auth should be enabled")
+		}
+		if result.Auth.TokenTTL != time.Hour {
+			t.Errorf("unmatch tokenTTL:%s, expected:%s", result.Auth.TokenTTL, time.Hour)
+		}
+		expectedAdmin := kcf.InitialAdmin{
+			Email:        "admin@example.com",
+			Name:         "admin",
+			PasswordFile: "/knit/secrets/admin-password",
+		}
+		if result.Auth.Admin == nil || *result.Auth.Admin != expectedAdmin {
+			t.Errorf("unmatch admin:%+v, expected:%+v", result.Auth.Admin, expectedAdmin)
+		}
+	})
 }
@@ -2,6 +2,7 @@ package frontend
 
 import (
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -20,5 +21,8 @@ func Unmarshal(conf []byte) (*FrontendConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	if out.Auth.TokenTTL <= 0 {
+		out.Auth.TokenTTL = 24 * time.Hour
+	}
 	return &out, nil
 }
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"net/url"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -22,22 +24,154 @@ func Load(filename string) (Config, error) {
 
 type Config struct {
 	Lifecycle WebHook `yaml:"lifecycle-hooks,omitempty"`
+
+	// LifecycleEvents are receivers of lifecycle events of Runs in a standard format.
+	LifecycleEvents EventHook `yaml:"lifecycle-events,omitempty"`
+
+	// Data are webhooks for events of Data: uploaded, imported and tagged.
+	Data WebHook `yaml:"data-hooks,omitempty"`
+
+	// Plan are webhooks for events of Plans: registered, (de)activated,
+	// and resources or annotations are changed.
+	Plan WebHook `yaml:"plan-hooks,omitempty"`
 }
 
+// Names of hook configurations.
+//
+// They are recorded with deliveries in the webhook outbox,
+// to find the configuration to deliver them.
+const (
+	LifecycleHooks = "lifecycle-hooks"
+	DataHooks      = "data-hooks"
+	PlanHooks      = "plan-hooks"
+)
+
+// WebHook finds the webhook configuration by its name.
+func (c Config) WebHook(name string) (WebHook, bool) {
+	switch name {
+	case LifecycleHooks:
+		return c.Lifecycle, true
+	case DataHooks:
+		return c.Data, true
+	case PlanHooks:
+		return c.Plan, true
+	default:
+		return WebHook{}, false
+	}
+}
+
+const (
+	// DefaultTimeout is the default timeout for each webhook request.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultMaxAttempts is the default max number of attempts of a webhook request.
+	DefaultMaxAttempts = 5
+
+	// DefaultBackoff is the default wait before the first retry of a webhook request.
+	DefaultBackoff = 1 * time.Second
+
+	// DefaultMaxBackoff is the default upper limit of the wait between retries.
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
 type WebHook struct {
 	Before []*url.URL
 	After  []*url.URL
+
+	// Secret is a key to sign payloads with HMAC-SHA256.
+	//
+	// If empty, payloads are not signed.
+	Secret string
+
+	// Timeout is the timeout for each request.
+	Timeout time.Duration
+
+	// Retry is the policy to retry failed requests.
+	Retry RetryPolicy
+}
+
+// RetryPolicy is a policy to retry failed webhook requests with exponential backoff.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts, including the first one.
+	MaxAttempts int
+
+	// Backoff is the wait before the first retry. It is doubled for each retry.
+	Backoff time.Duration
+
+	// MaxBackoff is the upper limit of the wait between retries.
+	MaxBackoff time.Duration
+}
+
+// Wait returns the wait before the next attempt, after the attempts have been made.
+//
+// If no more attempts should be made, it returns false.
+func (rp RetryPolicy) Wait(attempts int) (time.Duration, bool) {
+	if rp.MaxAttempts <= attempts {
+		return 0, false
+	}
+	wait := rp.Backoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if 0 < rp.MaxBackoff && rp.MaxBackoff <= wait {
+			break
+		}
+	}
+	if 0 < rp.MaxBackoff && rp.MaxBackoff < wait {
+		wait = rp.MaxBackoff
+	}
+	return wait, true
 }
 
 func (wh *WebHook) UnmarshalYAML(node *yaml.Node) error {
 	raw := struct {
-		Before []string `yaml:"before"`
-		After  []string `yaml:"after"`
+		Before  []string `yaml:"before"`
+		After   []string `yaml:"after"`
+		Secret  string   `yaml:"secret"`
+		Timeout string   `yaml:"timeout"`
+		Retry   struct {
+			MaxAttempts *int   `yaml:"max-attempts"`
+			Backoff     string `yaml:"backoff"`
+			MaxBackoff  string `yaml:"max-backoff"`
+		} `yaml:"retry"`
 	}{}
 	if err := node.Decode(&raw); err != nil {
 		return err
 	}
 
+	wh.Secret = raw.Secret
+
+	duration := func(name string, s string, def time.Duration) (time.Duration, error) {
+		if s == "" {
+			return def, nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+		if d < 0 {
+			return 0, fmt.Errorf("%s: should not be negative: %s", name, s)
+		}
+		return d, nil
+	}
+
+	var err error
+	if wh.Timeout, err = duration("timeout", raw.Timeout, DefaultTimeout); err != nil {
+		return err
+	}
+	if wh.Retry.Backoff, err = duration("retry.backoff", raw.Retry.Backoff, DefaultBackoff); err != nil {
+		return err
+	}
+	if wh.Retry.MaxBackoff, err = duration("retry.max-backoff", raw.Retry.MaxBackoff, DefaultMaxBackoff); err != nil {
+		return err
+	}
+	wh.Retry.MaxAttempts = DefaultMaxAttempts
+	if ma := raw.Retry.MaxAttempts; ma != nil {
+		if *ma < 1 {
+			return fmt.Errorf("retry.max-attempts: should be positive: %d", *ma)
+		}
+		wh.Retry.MaxAttempts = *ma
+	}
+
 	wh.Before = make([]*url.URL, len(raw.Before))
 	for i, u := range raw.Before {
 		parsed, err := url.Parse(u)
@@ -63,3 +197,69 @@ type Hooks struct {
 	RunManagement WebHook `yaml:"run_management,omitempty"`
 	Finishing     WebHook `yaml:"finishing,omitempty"`
 }
+
+// EventFormat is a format of events emitted by EventHook.
+type EventFormat string
+
+const (
+	// OpenLineage is a format of events as OpenLineage RunEvent.
+	//
+	// See: https://openlineage.io/docs/spec/object-model
+	OpenLineage EventFormat = "openlineage"
+
+	// CloudEvents is a format of events as CloudEvents (structured mode, JSON),
+	// whose data is an OpenLineage RunEvent.
+	//
+	// See: https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/formats/json-format.md
+	CloudEvents EventFormat = "cloudevents"
+)
+
+// EventHook is a configuration for receivers of lifecycle events.
+type EventHook struct {
+	// Format of events. Default is OpenLineage.
+	Format EventFormat
+
+	// Namespace of jobs and datasets in events. Default is "knitfab".
+	Namespace string
+
+	// URLs to which events are POSTed.
+	URLs []*url.URL
+}
+
+func (eh *EventHook) UnmarshalYAML(node *yaml.Node) error {
+	raw := struct {
+		Format    string   `yaml:"format"`
+		Namespace string   `yaml:"namespace"`
+		URLs      []string `yaml:"urls"`
+	}{}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+
+	switch f := EventFormat(raw.Format); f {
+	case "":
+		eh.Format = OpenLineage
+	case OpenLineage, CloudEvents:
+		eh.Format = f
+	default:
+		return fmt.Errorf(
+			"unknown event format: %s (should be one of %s or %s)",
+			raw.Format, OpenLineage, CloudEvents,
+		)
+	}
+
+	eh.Namespace = raw.Namespace
+	if eh.Namespace == "" {
+		eh.Namespace = "knitfab"
+	}
+
+	eh.URLs = make([]*url.URL, len(raw.URLs))
+	for i, u := range raw.URLs {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return err
+		}
+		eh.URLs[i] = parsed
+	}
+	return nil
+}
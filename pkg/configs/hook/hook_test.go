@@ -0,0 +1,203 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	config "github.com/opst/knitfab/pkg/configs/hook"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+)
+
+func TestLoad(t *testing.T) {
+	load := func(t *testing.T, content string) (config.Config, error) {
+		file := t.TempDir() + "/hooks.yaml"
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return config.Load(file)
+	}
+	urls := func(cfg config.EventHook) []string {
+		ret := []string{}
+		for _, u := range cfg.URLs {
+			ret = append(ret, u.String())
+		}
+		return ret
+	}
+
+	t.Run("it loads lifecycle-hooks and lifecycle-events", func(t *testing.T) {
+		got, err := load(t, `
+lifecycle-hooks:
+  before: ["http://before.example.com"]
+  after: ["http://after.example.com"]
+lifecycle-events:
+  format: cloudevents
+  namespace: example
+  urls: ["http://events.example.com/1", "http://events.example.com/2"]
+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Lifecycle.Before) != 1 || got.Lifecycle.Before[0].String() != "http://before.example.com" {
+			t.Errorf("unexpected lifecycle-hooks.before: %v", got.Lifecycle.Before)
+		}
+		if len(got.Lifecycle.After) != 1 || got.Lifecycle.After[0].String() != "http://after.example.com" {
+			t.Errorf("unexpected lifecycle-hooks.after: %v", got.Lifecycle.After)
+		}
+		if got.LifecycleEvents.Format != config.CloudEvents {
+			t.Errorf("unexpected format: %s", got.LifecycleEvents.Format)
+		}
+		if got.LifecycleEvents.Namespace != "example" {
+			t.Errorf("unexpected namespace: %s", got.LifecycleEvents.Namespace)
+		}
+		if want := []string{"http://events.example.com/1", "http://events.example.com/2"}; !cmp.SliceEq(urls(got.LifecycleEvents), want) {
+			t.Errorf("unexpected urls: %v", urls(got.LifecycleEvents))
+		}
+	})
+
+	t.Run("it loads secret, timeout and retry of webhooks", func(t *testing.T) {
+		got, err := load(t, `
+lifecycle-hooks:
+  before: []
+  after: []
+  secret: s3cr3t
+  timeout: 3s
+  retry:
+    max-attempts: 7
+    backoff: 2s
+    max-backoff: 1m
+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := config.WebHook{
+			Secret:  "s3cr3t",
+			Timeout: 3 * time.Second,
+			Retry:   config.RetryPolicy{MaxAttempts: 7, Backoff: 2 * time.Second, MaxBackoff: time.Minute},
+		}
+		if got.Lifecycle.Secret != want.Secret || got.Lifecycle.Timeout != want.Timeout || got.Lifecycle.Retry != want.Retry {
+			t.Errorf("lifecycle-hooks:\n===actual===\n%+v\n===expected===\n%+v", got.Lifecycle, want)
+		}
+	})
+
+	t.Run("it loads data-hooks and plan-hooks", func(t *testing.T) {
+		got, err := load(t, `
+data-hooks:
+  before: ["http://data.example.com/before"]
+  after: ["http://data.example.com/after"]
+plan-hooks:
+  before: ["http://plan.example.com/before"]
+  secret: plan-secret
+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Data.Before) != 1 || got.Data.Before[0].String() != "http://data.example.com/before" {
+			t.Errorf("unexpected data-hooks.before: %v", got.Data.Before)
+		}
+		if len(got.Data.After) != 1 || got.Data.After[0].String() != "http://data.example.com/after" {
+			t.Errorf("unexpected data-hooks.after: %v", got.Data.After)
+		}
+		if len(got.Plan.Before) != 1 || got.Plan.Before[0].String() != "http://plan.example.com/before" {
+			t.Errorf("unexpected plan-hooks.before: %v", got.Plan.Before)
+		}
+
+		for name, want := range map[string]config.WebHook{
+			config.DataHooks: got.Data,
+			config.PlanHooks: got.Plan,
+		} {
+			wh, ok := got.WebHook(name)
+			if !ok || wh.Secret != want.Secret || len(wh.Before) != len(want.Before) {
+				t.Errorf("WebHook(%s) = %+v, %v", name, wh, ok)
+			}
+		}
+		if _, ok := got.WebHook("unknown"); ok {
+			t.Error("WebHook(unknown) should not be found")
+		}
+	})
+
+	t.Run("webhooks have defaults", func(t *testing.T) {
+		got, err := load(t, `
+lifecycle-hooks:
+  before: []
+  after: []
+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := config.RetryPolicy{
+			MaxAttempts: config.DefaultMaxAttempts,
+			Backoff:     config.DefaultBackoff,
+			MaxBackoff:  config.DefaultMaxBackoff,
+		}
+		if got.Lifecycle.Secret != "" || got.Lifecycle.Timeout != config.DefaultTimeout || got.Lifecycle.Retry != want {
+			t.Errorf("unexpected lifecycle-hooks: %+v", got.Lifecycle)
+		}
+	})
+
+	for name, content := range map[string]string{
+		"broken timeout":     "lifecycle-hooks:\n  timeout: soon\n",
+		"negative backoff":   "lifecycle-hooks:\n  retry:\n    backoff: -1s\n",
+		"zero max-attempts":  "lifecycle-hooks:\n  retry:\n    max-attempts: 0\n",
+		"broken max-backoff": "lifecycle-hooks:\n  retry:\n    max-backoff: long\n",
+	} {
+		t.Run("it rejects "+name, func(t *testing.T) {
+			if _, err := load(t, content); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	t.Run("lifecycle-events has defaults", func(t *testing.T) {
+		got, err := load(t, `
+lifecycle-events:
+  urls: ["http://events.example.com"]
+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.LifecycleEvents.Format != config.OpenLineage {
+			t.Errorf("unexpected format: %s", got.LifecycleEvents.Format)
+		}
+		if got.LifecycleEvents.Namespace != "knitfab" {
+			t.Errorf("unexpected namespace: %s", got.LifecycleEvents.Namespace)
+		}
+	})
+
+	t.Run("it rejects unknown format of lifecycle-events", func(t *testing.T) {
+		if _, err := load(t, `
+lifecycle-events:
+  format: unknown
+  urls: ["http://events.example.com"]
+`); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestRetryPolicy_Wait(t *testing.T) {
+	testee := config.RetryPolicy{MaxAttempts: 5, Backoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	for attempts, want := range map[int]struct {
+		wait time.Duration
+		ok   bool
+	}{
+		1: {wait: 1 * time.Second, ok: true},
+		2: {wait: 2 * time.Second, ok: true},
+		3: {wait: 4 * time.Second, ok: true},
+		4: {wait: 5 * time.Second, ok: true}, // capped
+		5: {ok: false},
+		6: {ok: false},
+	} {
+		wait, ok := testee.Wait(attempts)
+		if ok != want.ok || (ok && wait != want.wait) {
+			t.Errorf("attempts = %d: got (%s, %v), want (%s, %v)", attempts, wait, ok, want.wait, want.ok)
+		}
+	}
+
+	t.Run("zero value does not retry", func(t *testing.T) {
+		if _, ok := (config.RetryPolicy{}).Wait(1); ok {
+			t.Error("zero value should not retry")
+		}
+	})
+}
@@ -253,3 +253,26 @@ func (p *pgxPool) Ping(ctx context.Context) error {
 func Wrap(p *pgxpool.Pool) Pool {
 	return &pgxPool{p}
 }
+
+// boundTx is the key of context values for the transaction bound with WithTx.
+type boundTx struct{}
+
+// WithTx binds the transaction to the context.
+//
+// Transactions begun by BeginIn with the context are nested in the transaction.
+func WithTx(ctx context.Context, tx Tx) context.Context {
+	return context.WithValue(ctx, boundTx{}, tx)
+}
+
+// BeginIn starts a transaction.
+//
+// When a transaction is bound to ctx with WithTx,
+// the new transaction is nested in it (as a savepoint),
+// so what is done in the new one is committed or rolled back together with the bound one.
+// Otherwise, it begins a transaction with b.
+func BeginIn(ctx context.Context, b Begin) (Tx, error) {
+	if tx, ok := ctx.Value(boundTx{}).(Tx); ok {
+		return tx.Begin(ctx)
+	}
+	return b.Begin(ctx)
+}
@@ -171,6 +171,9 @@ func ClearTables(ctx context.Context, p *pgxpool.Pool, t *testing.T) {
 		`truncate "knit_id" RESTART IDENTITY cascade`,
 		`truncate "tag_key" RESTART IDENTITY cascade`,
 		`truncate "keychain" RESTART IDENTITY cascade`,
+		`truncate "user" RESTART IDENTITY cascade`,
+		`truncate "audit_log" RESTART IDENTITY`,
+		`truncate "webhook_outbox" RESTART IDENTITY`,
 		// by cascade, all row in tables should be deleted.
 	} {
 		_, err = conn.Exec(ctx, command)
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AuditTargetType is a kind of item which is changed.
+type AuditTargetType string
+
+const (
+	AuditTargetData AuditTargetType = "data"
+	AuditTargetPlan AuditTargetType = "plan"
+	AuditTargetRun  AuditTargetType = "run"
+)
+
+// AuditAction is a name of the change.
+type AuditAction string
+
+const (
+	AuditDataTag AuditAction = "data.tag"
+
+	AuditPlanRegister       AuditAction = "plan.register"
+	AuditPlanActivate       AuditAction = "plan.activate"
+	AuditPlanResource       AuditAction = "plan.resource"
+	AuditPlanAnnotation     AuditAction = "plan.annotation"
+	AuditPlanServiceAccount AuditAction = "plan.serviceaccount"
+	AuditPlanSupersede      AuditAction = "plan.supersede"
+	AuditPlanDelete         AuditAction = "plan.delete"
+	AuditPlanPriority       AuditAction = "plan.priority"
+
+	AuditRunStatus AuditAction = "run.status"
+	AuditRunDelete AuditAction = "run.delete"
+	AuditRunRetry  AuditAction = "run.retry"
+)
+
+// AuditEntry is a record of a change.
+//
+// Audit entries are append-only. They are written in the same transaction as the change.
+type AuditEntry struct {
+	Id        int64
+	Timestamp time.Time
+
+	// Actor is who make the change.
+	//
+	// For changes via WebAPI, it is an email address of the user.
+	// For changes by loops, it is "loop/<loop type>".
+	Actor string
+
+	Action     AuditAction
+	TargetType AuditTargetType
+	TargetId   string
+
+	// Before and After are JSON snapshot of the target around the change.
+	//
+	// They can be nil, when the target is created or deleted by the change.
+	Before json.RawMessage
+	After  json.RawMessage
+}
+
+// AuditFindQuery is a query to find audit entries.
+//
+// Empty fields are not used as filter.
+type AuditFindQuery struct {
+	Actor      []string
+	Action     []AuditAction
+	TargetType []AuditTargetType
+	TargetId   []string
+
+	Since *time.Time
+	Until *time.Time
+}
+
+// ActorSystem is the actor when no actor is given in context.
+const ActorSystem = "system"
+
+type actorKey struct{}
+
+// WithActor returns a new context carrying who is making changes.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorOf returns the actor set by WithActor.
+//
+// If there are no actor, it returns ActorSystem.
+func ActorOf(ctx context.Context) string {
+	if a, ok := ctx.Value(actorKey{}).(string); ok && a != "" {
+		return a
+	}
+	return ActorSystem
+}
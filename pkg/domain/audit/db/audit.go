@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+
+	types "github.com/opst/knitfab/pkg/domain"
+)
+
+// AuditInterface reads audit log.
+//
+// Audit entries are written by other domains, in the same transaction as their changes.
+type AuditInterface interface {
+	// Find audit entries matching the query.
+	//
+	// # Args
+	//
+	// - context.Context
+	//
+	// - types.AuditFindQuery: filter of entries.
+	//
+	// # Returns
+	//
+	// - []types.AuditEntry: found entries, ordered by timestamp (older first).
+	//
+	// - error
+	Find(ctx context.Context, query types.AuditFindQuery) ([]types.AuditEntry, error)
+}
@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+
+	types "github.com/opst/knitfab/pkg/domain"
+	kdb "github.com/opst/knitfab/pkg/domain/audit/db"
+	kdbmock "github.com/opst/knitfab/pkg/domain/internal/db/mock"
+)
+
+type AuditInterface struct {
+	Impl struct {
+		Find func(context.Context, types.AuditFindQuery) ([]types.AuditEntry, error)
+	}
+	Calls struct {
+		Find kdbmock.CallLog[types.AuditFindQuery]
+	}
+}
+
+var _ kdb.AuditInterface = &AuditInterface{}
+
+func NewAuditInterface() *AuditInterface {
+	return &AuditInterface{}
+}
+
+func (m *AuditInterface) Find(ctx context.Context, query types.AuditFindQuery) ([]types.AuditEntry, error) {
+	m.Calls.Find = append(m.Calls.Find, query)
+	if m.Impl.Find != nil {
+		return m.Impl.Find(ctx, query)
+	}
+
+	panic(errors.New("should not be called"))
+}
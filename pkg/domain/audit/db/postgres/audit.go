@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+
+	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
+	types "github.com/opst/knitfab/pkg/domain"
+	kdbaudit "github.com/opst/knitfab/pkg/domain/audit/db"
+	xe "github.com/opst/knitfab/pkg/errors"
+	"github.com/opst/knitfab/pkg/utils/slices"
+)
+
+type auditPG struct {
+	pool kpool.Pool
+}
+
+func New(pool kpool.Pool) kdbaudit.AuditInterface {
+	return &auditPG{pool: pool}
+}
+
+func (a *auditPG) Find(ctx context.Context, query types.AuditFindQuery) ([]types.AuditEntry, error) {
+	conn, err := a.pool.Acquire(ctx)
+	if err != nil {
+		return nil, xe.Wrap(err)
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(
+		ctx,
+		`
+		select
+			"id", "timestamp", "actor", "action", "target_type", "target_id",
+			"before", "after"
+		from "audit_log"
+		where
+			(cardinality($1::varchar[]) = 0 or "actor" = any($1))
+			and (cardinality($2::varchar[]) = 0 or "action" = any($2))
+			and (cardinality($3::varchar[]) = 0 or "target_type" = any($3))
+			and (cardinality($4::varchar[]) = 0 or "target_id" = any($4))
+			and ($5::timestamp with time zone is null or $5 <= "timestamp")
+			and ($6::timestamp with time zone is null or "timestamp" < $6)
+		order by "timestamp", "id"
+		`,
+		nonNil(query.Actor),
+		nonNil(slices.Map(query.Action, func(a types.AuditAction) string { return string(a) })),
+		nonNil(slices.Map(query.TargetType, func(t types.AuditTargetType) string { return string(t) })),
+		nonNil(query.TargetId),
+		query.Since, query.Until,
+	)
+	if err != nil {
+		return nil, xe.Wrap(err)
+	}
+	defer rows.Close()
+
+	entries := []types.AuditEntry{}
+	for rows.Next() {
+		e := types.AuditEntry{}
+		var action, targetType string
+		var before, after []byte
+		if err := rows.Scan(
+			&e.Id, &e.Timestamp, &e.Actor, &action, &targetType, &e.TargetId,
+			&before, &after,
+		); err != nil {
+			return nil, xe.Wrap(err)
+		}
+		e.Action = types.AuditAction(action)
+		e.TargetType = types.AuditTargetType(targetType)
+		e.Before = before
+		e.After = after
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, xe.Wrap(err)
+	}
+
+	return entries, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,126 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/domain"
+	kpgaudit "github.com/opst/knitfab/pkg/domain/audit/db/postgres"
+	kpgintr "github.com/opst/knitfab/pkg/domain/internal/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/slices"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestAudit(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	ctx := context.Background()
+	pool := poolBroaker.GetPool(ctx, t)
+	testee := kpgaudit.New(pool)
+
+	for _, e := range []struct {
+		actor      string
+		action     domain.AuditAction
+		targetType domain.AuditTargetType
+		targetId   string
+		before     any
+		after      any
+	}{
+		{"alice@example.com", domain.AuditPlanActivate, domain.AuditTargetPlan, "plan-1", map[string]any{"active": true}, map[string]any{"active": false}},
+		{"bob@example.com", domain.AuditDataTag, domain.AuditTargetData, "knit-1", map[string]any{"tags": []string{}}, map[string]any{"tags": []string{"a:b"}}},
+		{"loop/run_management", domain.AuditRunStatus, domain.AuditTargetRun, "run-1", map[string]any{"status": "ready"}, map[string]any{"status": "running"}},
+		{"alice@example.com", domain.AuditRunDelete, domain.AuditTargetRun, "run-2", map[string]any{"status": "done"}, nil},
+	} {
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		if err := kpgintr.Audit(
+			domain.WithActor(ctx, e.actor), conn,
+			e.action, e.targetType, e.targetId, e.before, e.after,
+		); err != nil {
+			t.Fatal(err)
+		}
+		conn.Release()
+	}
+
+	idsOf := func(es []domain.AuditEntry) []string {
+		return slices.Map(es, func(e domain.AuditEntry) string { return e.TargetId })
+	}
+
+	for name, testcase := range map[string]struct {
+		query    domain.AuditFindQuery
+		expected []string
+	}{
+		"empty query finds all": {
+			query:    domain.AuditFindQuery{},
+			expected: []string{"plan-1", "knit-1", "run-1", "run-2"},
+		},
+		"by actor": {
+			query:    domain.AuditFindQuery{Actor: []string{"alice@example.com"}},
+			expected: []string{"plan-1", "run-2"},
+		},
+		"by action": {
+			query:    domain.AuditFindQuery{Action: []domain.AuditAction{domain.AuditDataTag, domain.AuditRunStatus}},
+			expected: []string{"knit-1", "run-1"},
+		},
+		"by target type and id": {
+			query: domain.AuditFindQuery{
+				TargetType: []domain.AuditTargetType{domain.AuditTargetRun},
+				TargetId:   []string{"run-2", "plan-1"},
+			},
+			expected: []string{"run-2"},
+		},
+		"by time range": {
+			query: domain.AuditFindQuery{
+				Since: func() *time.Time { t := time.Now().Add(time.Hour); return &t }(),
+			},
+			expected: []string{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual := try.To(testee.Find(ctx, testcase.query)).OrFatal(t)
+			if !cmp.SliceEq(idsOf(actual), testcase.expected) {
+				t.Errorf("found:\n===actual===\n%v\n===expected===\n%v", idsOf(actual), testcase.expected)
+			}
+		})
+	}
+
+	t.Run("entries keep actor and snapshots", func(t *testing.T) {
+		actual := try.To(testee.Find(ctx, domain.AuditFindQuery{TargetId: []string{"run-2"}})).OrFatal(t)
+		if len(actual) != 1 {
+			t.Fatalf("unexpected entries: %+v", actual)
+		}
+		e := actual[0]
+		if e.Actor != "alice@example.com" || e.Action != domain.AuditRunDelete || e.TargetType != domain.AuditTargetRun {
+			t.Errorf("unexpected entry: %+v", e)
+		}
+		if string(e.Before) != `{"status": "done"}` {
+			t.Errorf("before: %s", e.Before)
+		}
+		if e.After != nil {
+			t.Errorf("after: %s", e.After)
+		}
+	})
+
+	t.Run("entries cannot be deleted or updated", func(t *testing.T) {
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+		if _, err := conn.Exec(ctx, `delete from "audit_log"`); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Exec(ctx, `update "audit_log" set "actor" = 'mallory'`); err != nil {
+			t.Fatal(err)
+		}
+
+		actual := try.To(testee.Find(ctx, domain.AuditFindQuery{})).OrFatal(t)
+		if len(actual) != 4 {
+			t.Fatalf("entries are changed: %+v", actual)
+		}
+		for _, e := range actual {
+			if e.Actor == "mallory" {
+				t.Errorf("entry is updated: %+v", e)
+			}
+		}
+	})
+}
@@ -0,0 +1,19 @@
+package audit
+
+import "github.com/opst/knitfab/pkg/domain/audit/db"
+
+type Interface interface {
+	Database() db.AuditInterface
+}
+
+type impl struct {
+	db db.AuditInterface
+}
+
+func New(db db.AuditInterface) Interface {
+	return &impl{db: db}
+}
+
+func (i *impl) Database() db.AuditInterface {
+	return i.db
+}
@@ -0,0 +1,31 @@
+package domain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opst/knitfab/pkg/domain"
+)
+
+func TestActorOf(t *testing.T) {
+	t.Run("it returns ActorSystem when no actor is given", func(t *testing.T) {
+		if got := domain.ActorOf(context.Background()); got != domain.ActorSystem {
+			t.Errorf("unexpected actor: %s", got)
+		}
+	})
+
+	t.Run("it returns the actor given by WithActor", func(t *testing.T) {
+		ctx := domain.WithActor(context.Background(), "user@example.com")
+		if got := domain.ActorOf(ctx); got != "user@example.com" {
+			t.Errorf("unexpected actor: %s", got)
+		}
+	})
+
+	t.Run("the innermost actor wins", func(t *testing.T) {
+		ctx := domain.WithActor(context.Background(), "loop/projection")
+		ctx = domain.WithActor(ctx, "user@example.com")
+		if got := domain.ActorOf(ctx); got != "user@example.com" {
+			t.Errorf("unexpected actor: %s", got)
+		}
+	})
+}
@@ -73,6 +73,30 @@ type KnitData struct {
 	Upsteram    DataSource
 	Downstreams []DataSink
 	NominatedBy []Nomination
+
+	// Size of the Data.
+	//
+	// This is nil if the Data has not been measured yet.
+	Size *DataSize
+
+	// Checksum of the content of the Data, as a Merkle tree over files.
+	//
+	// It is formatted as "sha256:<hex>", or empty if it has not been computed yet.
+	Checksum string
+
+	// Parameter is the value of a parameter input which the Data is materialised from.
+	//
+	// This is nil if the Data is not materialised from a parameter.
+	Parameter *string
+}
+
+// DataSize is the size of the content of a Data.
+type DataSize struct {
+	// Bytes is the total size of regular files.
+	Bytes int64
+
+	// Files is the number of regular files.
+	Files int64
 }
 
 func (d *KnitData) Equal(other *KnitData) bool {
@@ -110,3 +134,33 @@ type DataAgentCursor struct {
 	// the interval to pick same Data Agent
 	Debounce time.Duration
 }
+
+// LineageQuery specifies the range of the Data Lineage to be traced from a Data.
+type LineageQuery struct {
+	// trace Runs using the Data, and Data generated by them, and so on.
+	Downstream bool
+
+	// trace the Run generating the Data, and Data used by it, and so on.
+	Upstream bool
+
+	// max number of Runs to be traced along a path, for each direction.
+	//
+	// Negative means unlimited.
+	Depth int
+}
+
+// Lineage is a subgraph of the Data Lineage.
+//
+// All inputs and outputs (including logs) of Runs in the Lineage are in the Lineage.
+type Lineage struct {
+	// KnitIds of Data in the subgraph.
+	KnitIds []string
+
+	// RunIds of Runs in the subgraph.
+	RunIds []string
+}
+
+func (l *Lineage) Equal(o *Lineage) bool {
+	return cmp.SliceContentEq(l.KnitIds, o.KnitIds) &&
+		cmp.SliceContentEq(l.RunIds, o.RunIds)
+}
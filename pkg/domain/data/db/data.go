@@ -27,12 +27,14 @@ type DataInterface interface {
 	//     - []Tag: specified tags
 	//     - *Time: start of the time range
 	//     - *Time: end of the time range
+	//     - Page: the page of results to be retrieved
 	//
 	// returns:
-	//     - []string: Knitid of the data that meets the conditions
-	//     - error
+	//     - []string: Knitid of the data that meets the conditions, in the page
+	//     - string: cursor of the next page. empty if there are no more data.
+	//     - error: domain.ErrInvalidCursor if the cursor of the page is not understood.
 	//
-	Find(context.Context, []domain.Tag, *time.Time, *time.Time) ([]string, error)
+	Find(context.Context, []domain.Tag, *time.Time, *time.Time, domain.Page) ([]string, string, error)
 
 	// update tags on data.
 	//
@@ -49,6 +51,27 @@ type DataInterface interface {
 	// - error
 	UpdateTag(context.Context, string, domain.TagDelta) error
 
+	// preview Runs which would be created when tags on data are updated.
+	//
+	// Nothing is written; nominations which the change would make are found
+	// with read-only queries, and Runs are composed from them.
+	// Nominations not projected yet before the change are not previewed.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string : knitId of target data
+	//
+	// - TagDelta : tags adding/removing from data
+	//
+	// Return
+	//
+	// - []RunPreview : Runs which would be created
+	//
+	// - error: same as UpdateTag
+	PreviewUpdateTag(context.Context, string, domain.TagDelta) ([]domain.RunPreview, error)
+
 	// Create and occupy a new DataAgent for the KnitData.
 	//
 	// Args
@@ -121,4 +144,102 @@ type DataInterface interface {
 	// - []string : names of DataAgents with the modes
 	//
 	GetAgentName(ctx context.Context, knitId string, modes []domain.DataAgentMode) ([]string, error)
+
+	// Get the value of the parameter which the KnitData is materialised from.
+	//
+	// Args
+	//
+	// - ctx context.Context
+	//
+	// - knitId string : knitId of target data
+	//
+	// Return
+	//
+	// - string : the parameter value
+	//
+	// - error : ErrMissing if the KnitData is not materialised from a parameter.
+	GetParameterValue(ctx context.Context, knitId string) (string, error)
+
+	// Trace the Data Lineage from the KnitData.
+	//
+	// Args
+	//
+	// - ctx context.Context
+	//
+	// - knitId string : knitId of the root data
+	//
+	// - query LineageQuery : directions and depth to be traced
+	//
+	// Return
+	//
+	// - Lineage : KnitIds and RunIds in the traced subgraph. It contains the root data always.
+	//
+	// - error : ErrMissing if the root data is not found.
+	Lineage(ctx context.Context, knitId string, query domain.LineageQuery) (domain.Lineage, error)
+
+	// Record the size of the KnitData.
+	//
+	// When the size has been recorded already, it is overwritten.
+	//
+	// Args
+	//
+	// - ctx context.Context
+	//
+	// - knitId string : knitId of target data
+	//
+	// - size DataSize : the size measured
+	//
+	// Return
+	//
+	// - error : ErrMissing if the KnitData is not found.
+	SetSize(ctx context.Context, knitId string, size domain.DataSize) error
+
+	// Record the checksum of the content of the KnitData.
+	//
+	// When the checksum has been recorded already, it is overwritten.
+	//
+	// Args
+	//
+	// - ctx context.Context
+	//
+	// - knitId string : knitId of target data
+	//
+	// - checksum string : the checksum computed
+	//
+	// Return
+	//
+	// - error : ErrMissing if the KnitData is not found.
+	SetChecksum(ctx context.Context, knitId string, checksum string) error
+
+	// Get KnitIds of the KnitData which should be measured.
+	//
+	// They are the KnitData which are not measured yet or have no checksums,
+	// and generated by Runs done or failed.
+	//
+	// Args
+	//
+	// - ctx context.Context
+	//
+	// Return
+	//
+	// - []string : KnitIds of the KnitData
+	//
+	// - error
+	Unmeasured(ctx context.Context) ([]string, error)
+
+	// Aggregate storage usage of the KnitData measured.
+	//
+	// Args
+	//
+	// - ctx context.Context
+	//
+	// - query UsageQuery : how to aggregate
+	//
+	// Return
+	//
+	// - []Usage : usage of each group. Groups are sorted by the total bytes, in descending order,
+	// except for aggregating by time. They are sorted by time, in ascending order.
+	//
+	// - error
+	Usage(ctx context.Context, query domain.UsageQuery) ([]domain.Usage, error)
 }
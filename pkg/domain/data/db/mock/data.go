@@ -13,12 +13,19 @@ import (
 type DataInterface struct {
 	Impl struct {
 		Get                func(context.Context, []string) (map[string]domain.KnitData, error)
-		Find               func(context.Context, []domain.Tag, *time.Time, *time.Time) ([]string, error)
+		Find               func(context.Context, []domain.Tag, *time.Time, *time.Time, domain.Page) ([]string, string, error)
 		UpdateTag          func(context.Context, string, domain.TagDelta) error
+		PreviewUpdateTag   func(context.Context, string, domain.TagDelta) ([]domain.RunPreview, error)
 		NewAgent           func(context.Context, string, domain.DataAgentMode, time.Duration) (domain.DataAgent, error)
 		RemoveAgent        func(context.Context, string) error
 		PickAndRemoveAgent func(context.Context, domain.DataAgentCursor, func(domain.DataAgent) (bool, error)) (domain.DataAgentCursor, error)
 		GetAgentName       func(context.Context, string, []domain.DataAgentMode) ([]string, error)
+		GetParameterValue  func(context.Context, string) (string, error)
+		Lineage            func(context.Context, string, domain.LineageQuery) (domain.Lineage, error)
+		SetSize            func(context.Context, string, domain.DataSize) error
+		SetChecksum        func(context.Context, string, string) error
+		Unmeasured         func(context.Context) ([]string, error)
+		Usage              func(context.Context, domain.UsageQuery) ([]domain.Usage, error)
 	}
 	Calls struct {
 		Get  dbmock.CallLog[struct{ KnitId []string }]
@@ -26,11 +33,16 @@ type DataInterface struct {
 			Tags  []domain.Tag
 			Since *time.Time
 			Until *time.Time
+			Page  domain.Page
 		}]
 		Updatetag dbmock.CallLog[struct {
 			KnitId string
 			Delta  domain.TagDelta
 		}]
+		PreviewUpdateTag dbmock.CallLog[struct {
+			KnitId string
+			Delta  domain.TagDelta
+		}]
 		NewAgent dbmock.CallLog[struct {
 			KnitId                string
 			Mode                  domain.DataAgentMode
@@ -44,6 +56,21 @@ type DataInterface struct {
 			KnitId string
 			Modes  []domain.DataAgentMode
 		}]
+		GetParameterValue dbmock.CallLog[struct{ KnitId string }]
+		Lineage           dbmock.CallLog[struct {
+			KnitId string
+			Query  domain.LineageQuery
+		}]
+		SetSize dbmock.CallLog[struct {
+			KnitId string
+			Size   domain.DataSize
+		}]
+		SetChecksum dbmock.CallLog[struct {
+			KnitId   string
+			Checksum string
+		}]
+		Unmeasured dbmock.CallLog[struct{}]
+		Usage      dbmock.CallLog[domain.UsageQuery]
 	}
 }
 
@@ -61,16 +88,17 @@ func (di *DataInterface) Get(ctx context.Context, knitId []string) (map[string]d
 	panic(errors.New("it should no be called"))
 }
 
-func (di *DataInterface) Find(ctx context.Context, tags []domain.Tag, since *time.Time, until *time.Time) ([]string, error) {
+func (di *DataInterface) Find(ctx context.Context, tags []domain.Tag, since *time.Time, until *time.Time, page domain.Page) ([]string, string, error) {
 	di.Calls.Find = append(di.Calls.Find, struct {
 		Tags  []domain.Tag
 		Since *time.Time
 		Until *time.Time
+		Page  domain.Page
 	}{
-		Tags: tags, Since: since, Until: until,
+		Tags: tags, Since: since, Until: until, Page: page,
 	})
 	if di.Impl.Find != nil {
-		return di.Impl.Find(ctx, tags, since, until)
+		return di.Impl.Find(ctx, tags, since, until, page)
 	}
 	panic(errors.New("it should no be called"))
 }
@@ -88,6 +116,19 @@ func (di *DataInterface) UpdateTag(ctx context.Context, knitId string, delta dom
 	panic(errors.New("it should not be called"))
 }
 
+func (di *DataInterface) PreviewUpdateTag(ctx context.Context, knitId string, delta domain.TagDelta) ([]domain.RunPreview, error) {
+	di.Calls.PreviewUpdateTag = append(di.Calls.PreviewUpdateTag, struct {
+		KnitId string
+		Delta  domain.TagDelta
+	}{
+		KnitId: knitId, Delta: delta,
+	})
+	if di.Impl.PreviewUpdateTag != nil {
+		return di.Impl.PreviewUpdateTag(ctx, knitId, delta)
+	}
+	panic(errors.New("it should not be called"))
+}
+
 func (di *DataInterface) NewAgent(ctx context.Context, knitId string, mode domain.DataAgentMode, lifecycleSuspend time.Duration) (domain.DataAgent, error) {
 	di.Calls.NewAgent = append(di.Calls.NewAgent, struct {
 		KnitId                string
@@ -134,3 +175,62 @@ func (di *DataInterface) GetAgentName(ctx context.Context, knitId string, modes
 	}
 	panic(errors.New("it should not be called"))
 }
+
+func (di *DataInterface) GetParameterValue(ctx context.Context, knitId string) (string, error) {
+	di.Calls.GetParameterValue = append(
+		di.Calls.GetParameterValue, struct{ KnitId string }{KnitId: knitId},
+	)
+	if di.Impl.GetParameterValue != nil {
+		return di.Impl.GetParameterValue(ctx, knitId)
+	}
+	panic(errors.New("it should not be called"))
+}
+
+func (di *DataInterface) Lineage(ctx context.Context, knitId string, query domain.LineageQuery) (domain.Lineage, error) {
+	di.Calls.Lineage = append(di.Calls.Lineage, struct {
+		KnitId string
+		Query  domain.LineageQuery
+	}{KnitId: knitId, Query: query})
+	if di.Impl.Lineage != nil {
+		return di.Impl.Lineage(ctx, knitId, query)
+	}
+	panic(errors.New("it should not be called"))
+}
+
+func (di *DataInterface) SetSize(ctx context.Context, knitId string, size domain.DataSize) error {
+	di.Calls.SetSize = append(di.Calls.SetSize, struct {
+		KnitId string
+		Size   domain.DataSize
+	}{KnitId: knitId, Size: size})
+	if di.Impl.SetSize != nil {
+		return di.Impl.SetSize(ctx, knitId, size)
+	}
+	panic(errors.New("it should not be called"))
+}
+
+func (di *DataInterface) SetChecksum(ctx context.Context, knitId string, checksum string) error {
+	di.Calls.SetChecksum = append(di.Calls.SetChecksum, struct {
+		KnitId   string
+		Checksum string
+	}{KnitId: knitId, Checksum: checksum})
+	if di.Impl.SetChecksum != nil {
+		return di.Impl.SetChecksum(ctx, knitId, checksum)
+	}
+	panic(errors.New("it should not be called"))
+}
+
+func (di *DataInterface) Unmeasured(ctx context.Context) ([]string, error) {
+	di.Calls.Unmeasured = append(di.Calls.Unmeasured, struct{}{})
+	if di.Impl.Unmeasured != nil {
+		return di.Impl.Unmeasured(ctx)
+	}
+	panic(errors.New("it should not be called"))
+}
+
+func (di *DataInterface) Usage(ctx context.Context, query domain.UsageQuery) ([]domain.Usage, error) {
+	di.Calls.Usage = append(di.Calls.Usage, query)
+	if di.Impl.Usage != nil {
+		return di.Impl.Usage(ctx, query)
+	}
+	panic(errors.New("it should not be called"))
+}
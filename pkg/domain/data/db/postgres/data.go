@@ -158,6 +158,67 @@ func (d *dataPG) get(ctx context.Context, conn kpool.Conn, knitIds []string) (ma
 		return nil, err
 	}
 
+	sizes := map[string]*domain.DataSize{}
+	{
+		rows, err := conn.Query(
+			ctx,
+			`select "knit_id", "bytes", "files" from "data_size" where "knit_id" = any($1)`,
+			knitIds,
+		)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var knitId string
+			size := domain.DataSize{}
+			if err := rows.Scan(&knitId, &size.Bytes, &size.Files); err != nil {
+				return nil, err
+			}
+			sizes[knitId] = &size
+		}
+	}
+
+	checksums := map[string]string{}
+	{
+		rows, err := conn.Query(
+			ctx,
+			`select "knit_id", "checksum" from "data_checksum" where "knit_id" = any($1)`,
+			knitIds,
+		)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var knitId, checksum string
+			if err := rows.Scan(&knitId, &checksum); err != nil {
+				return nil, err
+			}
+			checksums[knitId] = checksum
+		}
+	}
+
+	parameters := map[string]*string{}
+	{
+		rows, err := conn.Query(
+			ctx,
+			`select "knit_id", "value" from "parameter_data" where "knit_id" = any($1)`,
+			knitIds,
+		)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var knitId, value string
+			if err := rows.Scan(&knitId, &value); err != nil {
+				return nil, err
+			}
+			parameters[knitId] = &value
+		}
+	}
+
 	// resolve indirect references:
 	//
 	// knitId -> runId -> run body, for each data, upstream/downstream
@@ -184,6 +245,9 @@ func (d *dataPG) get(ctx context.Context, conn kpool.Conn, knitIds []string) (ma
 			},
 			Downstreams: []domain.DataSink{},
 			NominatedBy: nominations[knitId],
+			Size:        sizes[knitId],
+			Checksum:    checksums[knitId],
+			Parameter:   parameters[knitId],
 		}
 
 		for _, dn := range downstreamIds[knitId] {
@@ -200,15 +264,25 @@ func (d *dataPG) get(ctx context.Context, conn kpool.Conn, knitIds []string) (ma
 	return result, nil
 }
 
-func (d *dataPG) find(ctx context.Context, conn kpool.Queryer, query dataFindQuery) ([]string, error) {
+func (d *dataPG) find(ctx context.Context, conn kpool.Queryer, query dataFindQuery, page domain.Page) ([]string, string, error) {
 
-	knitIds := []string{}
+	cursor, err := kpgintr.ParseCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	var afterKnitId *string
+	var afterTimestamp *time.Time
+	if cursor != nil {
+		afterKnitId = &cursor.Id
+		afterTimestamp = cursor.Time
+	}
+	op, dir := kpgintr.PageDirection(page.Order)
 
 	var timestamp *time.Time
 	if query.sysKnitTimeStamp != nil {
 		t, err := rfctime.ParseRFC3339DateTime(*query.sysKnitTimeStamp)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		_t := t.Time()
 		timestamp = &_t
@@ -224,9 +298,10 @@ func (d *dataPG) find(ctx context.Context, conn kpool.Queryer, query dataFindQue
 		failedStatus = slices.Map(domain.FailedStatuses(), domain.KnitRunStatus.String)
 	}
 
+	// Data without timestamp are ordered at the last, as they are at the infinite future.
 	rows, err := conn.Query(
 		ctx,
-		`
+		fmt.Sprintf(`
 		with
 		"__data" as (
 			select
@@ -277,24 +352,37 @@ func (d *dataPG) find(ctx context.Context, conn kpool.Queryer, query dataFindQue
 			select "knit_id" from "_data"
 			where (select count(*) from "_query") = 0
 		)
-		select "knit_id" from "data"
+		select "knit_id", "raw_timestamp" from "data"
 		inner join "_data" using("knit_id")
-		order by "raw_timestamp" ASC NULLS LAST, "knit_id"
-		`,
+		where
+			$8::varchar is null
+			or (coalesce("raw_timestamp", 'infinity'), "knit_id") %[1]s (coalesce($9::timestamp with time zone, 'infinity'), $8::varchar)
+		order by coalesce("raw_timestamp", 'infinity') %[2]s, "knit_id" %[2]s
+		limit $10
+		`, op, dir),
 		query.sysKnitId, processingStatus, failedStatus, timestamp, query.updatedSince, query.updatedUntil,
 		slices.Map(query.userTag, func(t domain.Tag) [2]string { return [2]string{t.Key, t.Value} }),
+		afterKnitId, afterTimestamp, kpgintr.PageLimit(page),
 	)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
+
+	found := []kpgintr.Cursor{}
 	for rows.Next() {
-		var knitId string
-		rows.Scan(&knitId)
-		knitIds = append(knitIds, knitId)
+		var c kpgintr.Cursor
+		if err := rows.Scan(&c.Id, &c.Time); err != nil {
+			return nil, "", err
+		}
+		found = append(found, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
 	}
 
-	return knitIds, nil
+	found, next := kpgintr.NextPage(page, found, func(c kpgintr.Cursor) kpgintr.Cursor { return c })
+	return slices.Map(found, func(c kpgintr.Cursor) string { return c.Id }), next, nil
 }
 
 func makeDataFindQuery(tag []domain.Tag, since *time.Time, until *time.Time) *dataFindQuery {
@@ -368,21 +456,25 @@ func makeDataFindQuery(tag []domain.Tag, since *time.Time, until *time.Time) *da
 
 }
 
-func (d *dataPG) Find(ctx context.Context, tag []domain.Tag, since *time.Time, until *time.Time) ([]string, error) {
+func (d *dataPG) Find(ctx context.Context, tag []domain.Tag, since *time.Time, until *time.Time, page domain.Page) ([]string, string, error) {
+	if _, err := kpgintr.ParseCursor(page.Cursor); err != nil {
+		return nil, "", err
+	}
+
 	query := makeDataFindQuery(tag, since, until)
 	if query == nil {
 		// When nil returns, it returns an empty list
 		// because it is known that
 		// there is no corresponding data for the specified tag combination.
-		return []string{}, nil
+		return []string{}, "", nil
 	}
 
 	conn, err := d.pool.Acquire(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer conn.Release()
-	return d.find(ctx, conn, *query)
+	return d.find(ctx, conn, *query, page)
 }
 
 type dataFindQuery struct {
@@ -408,6 +500,187 @@ func (d *dataPG) UpdateTag(ctx context.Context, knitId string, delta domain.TagD
 	return tx.Commit(ctx)
 }
 
+func (d *dataPG) PreviewUpdateTag(ctx context.Context, knitId string, delta domain.TagDelta) ([]domain.RunPreview, error) {
+	tx, err := d.pool.BeginTx(
+		ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly},
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// step1: user tags of the data after the change.
+	var found bool
+	if err := tx.QueryRow(
+		ctx, `select exists (select 1 from "data" where "knit_id" = $1)`, knitId,
+	).Scan(&found); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, kpgerr.Missing{
+			Table: "data", Identity: fmt.Sprintf("knit_id='%s'", knitId),
+		}
+	}
+	utags, err := kpgintr.UserTagsOfData(ctx, tx, []string{knitId})
+	if err != nil {
+		return nil, err
+	}
+	removedKeys := map[string]struct{}{}
+	for _, k := range delta.RemoveKey {
+		removedKeys[k] = struct{}{}
+	}
+	tags := slices.Filter(utags[knitId], func(t domain.Tag) bool {
+		if _, ok := removedKeys[t.Key]; ok {
+			return false
+		}
+		_, removed := slices.First(delta.Remove, func(r domain.Tag) bool { return r.Equal(&t) })
+		return !removed
+	})
+	for _, t := range delta.Add {
+		if _, ok := slices.First(tags, func(o domain.Tag) bool { return o.Equal(&t) }); !ok {
+			tags = append(tags, t)
+		}
+	}
+
+	// step2: Inputs whose nominations may be changed.
+	//
+	// Those are Inputs which the data would be nominated to,
+	// and Inputs with InputSelection which the data is nominated to now
+	// (they would be nominated with other Data when the data is dropped).
+	inputIds, err := d.nominator.MatchingInputs(ctx, tx, knitId, tags)
+	if err != nil {
+		return nil, err
+	}
+	{
+		var selecting []int
+		if err := tx.QueryRow(
+			ctx,
+			`
+			select coalesce(array_agg("input_id"), '{}') from "nomination"
+			inner join "input_selection" using ("input_id")
+			where "knit_id" = $1 and not ("input_id" = any($2::int[]))
+			`,
+			knitId, inputIds,
+		).Scan(&selecting); err != nil {
+			return nil, err
+		}
+		inputIds = append(inputIds, selecting...)
+	}
+	if len(inputIds) == 0 {
+		return []domain.RunPreview{}, nil
+	}
+
+	// step3: nominations of the Inputs after the change.
+	affected, err := kpgintr.GetInputs(ctx, tx, inputIds)
+	if err != nil {
+		return nil, err
+	}
+	selections, err := kpgintr.GetInputSelections(ctx, tx, inputIds)
+	if err != nil {
+		return nil, err
+	}
+	nominated := map[int][]string{}
+	override := &kpgnom.TagsOverride{KnitId: knitId, UserTags: tags}
+	for inputId, in := range affected {
+		cond, err := kpgnom.ConditionOf(in.Tags, selections[inputId])
+		if err != nil {
+			return nil, err
+		}
+		knitIds, err := d.nominator.Match(ctx, tx, cond, override)
+		if err != nil {
+			return nil, err
+		}
+		nominated[inputId] = knitIds
+	}
+
+	// step4: current nominations of Plans with image having the Inputs.
+	current := map[string]map[int][]string{} // plan id -> input id -> knit ids
+	{
+		rows, err := tx.Query(
+			ctx,
+			`
+			with "plan" as (
+				select distinct "plan_id" from "input"
+				inner join "plan_image" using ("plan_id")
+				where "input_id" = any($1)
+			)
+			select "plan_id", "input_id", "knit_id" from "input"
+			inner join "plan" using ("plan_id")
+			left join "nomination" using ("input_id")
+			`,
+			inputIds,
+		)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var planId string
+			var inputId int
+			var knitId *string
+			if err := rows.Scan(&planId, &inputId, &knitId); err != nil {
+				return nil, err
+			}
+			if _, ok := current[planId]; !ok {
+				current[planId] = map[int][]string{}
+			}
+			if _, ok := current[planId][inputId]; !ok {
+				current[planId][inputId] = []string{}
+			}
+			if knitId != nil {
+				current[planId][inputId] = append(current[planId][inputId], *knitId)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		rows.Close()
+	}
+
+	// step5: project nominations which would be new.
+	planIds := slices.Sorted(slices.KeysOf(current), func(a, b string) bool { return a < b })
+	plans, err := kpgintr.GetPlanBody(ctx, tx, planIds)
+	if err != nil {
+		return nil, err
+	}
+	previews := []domain.RunPreview{}
+	for _, planId := range planIds {
+		nominations := current[planId]
+		updated := map[int][]string{}
+		hasUpdated := false
+		for inputId, before := range nominations {
+			after, ok := nominated[inputId]
+			if !ok {
+				continue
+			}
+			known := map[string]struct{}{}
+			for _, k := range before {
+				known[k] = struct{}{}
+			}
+			updated[inputId] = slices.Filter(after, func(k string) bool {
+				_, ok := known[k]
+				return !ok
+			})
+			hasUpdated = hasUpdated || 0 < len(updated[inputId])
+			nominations[inputId] = after
+		}
+		if !hasUpdated {
+			continue
+		}
+
+		inputs, err := kpgintr.GetInputs(ctx, tx, slices.KeysOf(nominations))
+		if err != nil {
+			return nil, err
+		}
+		p, err := kpgintr.PreviewProjection(ctx, tx, plans[planId], inputs, nominations, updated)
+		if err != nil {
+			return nil, err
+		}
+		previews = append(previews, p...)
+	}
+	return previews, nil
+}
+
 func lockData(ctx context.Context, conn kpool.Queryer, knitId string) error {
 	rows, err := conn.Query(
 		ctx,
@@ -427,6 +700,11 @@ func (d *dataPG) updateTag(ctx context.Context, tx kpool.Tx, knitId string, delt
 		return err
 	}
 
+	before, err := auditTagsOfData(ctx, tx, knitId)
+	if err != nil {
+		return err
+	}
+
 	if err := removeTagsFromData(ctx, tx, knitId, delta.Remove, delta.RemoveKey); err != nil {
 		return err
 	}
@@ -439,7 +717,25 @@ func (d *dataPG) updateTag(ctx context.Context, tx kpool.Tx, knitId string, delt
 		return err
 	}
 
-	return nil
+	after, err := auditTagsOfData(ctx, tx, knitId)
+	if err != nil {
+		return err
+	}
+
+	return kpgintr.Audit(
+		ctx, tx, domain.AuditDataTag, domain.AuditTargetData, knitId, before, after,
+	)
+}
+
+// auditTagsOfData returns a snapshot of user tags of the data for audit log.
+func auditTagsOfData(ctx context.Context, conn kpool.Queryer, knitId string) (map[string]any, error) {
+	utags, err := kpgintr.UserTagsOfData(ctx, conn, []string{knitId})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"tags": slices.Map(utags[knitId], domain.Tag.String),
+	}, nil
 }
 
 func addTagsForData(ctx context.Context, conn kpool.Queryer, knitId string, addTags []domain.Tag) error {
@@ -775,3 +1071,289 @@ func (m *dataPG) GetAgentName(ctx context.Context, knitId string, modes []domain
 
 	return names, nil
 }
+
+func (m *dataPG) GetParameterValue(ctx context.Context, knitId string) (string, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Release()
+
+	var value string
+	if err := conn.QueryRow(
+		ctx,
+		`select "value" from "parameter_data" where "knit_id" = $1`,
+		knitId,
+	).Scan(&value); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", kpgerr.Missing{
+				Table: "parameter_data", Identity: fmt.Sprintf("knit_id='%s'", knitId),
+			}
+		}
+		return "", err
+	}
+	return value, nil
+}
+
+func (m *dataPG) Lineage(ctx context.Context, knitId string, query domain.LineageQuery) (domain.Lineage, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return domain.Lineage{}, err
+	}
+	defer conn.Release()
+
+	var found int
+	if err := conn.QueryRow(
+		ctx, `select count(*) from "data" where "knit_id" = $1`, knitId,
+	).Scan(&found); err != nil {
+		return domain.Lineage{}, err
+	}
+	if found == 0 {
+		return domain.Lineage{}, kpgerr.Missing{
+			Table: "data", Identity: fmt.Sprintf("knit_id='%s'", knitId),
+		}
+	}
+
+	// Each of "upstream" and "downstream" holds Data reachable from the root
+	// with the number of Runs passed through ("depth").
+	//
+	// Runs are traced only from Data with depth less than the max depth,
+	// and all inputs and outputs of traced Runs are in the lineage.
+	rows, err := conn.Query(
+		ctx,
+		`
+		with recursive
+		"upstream" ("knit_id", "run_id", "depth") as (
+			select "knit_id", "run_id", 0 from "data"
+			where $2 and "knit_id" = $1
+			union
+			select "data"."knit_id", "data"."run_id", "upstream"."depth" + 1
+			from "upstream"
+			inner join "assign" on "assign"."run_id" = "upstream"."run_id"
+			inner join "data" on "data"."knit_id" = "assign"."knit_id"
+			where $4::int < 0 or "upstream"."depth" < $4::int
+		),
+		"downstream" ("knit_id", "depth") as (
+			select "knit_id", 0 from "data"
+			where $3 and "knit_id" = $1
+			union
+			select "data"."knit_id", "downstream"."depth" + 1
+			from "downstream"
+			inner join "assign" on "assign"."knit_id" = "downstream"."knit_id"
+			inner join "data" on "data"."run_id" = "assign"."run_id"
+			where $4::int < 0 or "downstream"."depth" < $4::int
+		),
+		"traced" as (
+			select "run_id" from "upstream"
+			where $4::int < 0 or "depth" < $4::int
+			union
+			select "run_id" from "assign"
+			inner join "downstream" using ("knit_id")
+			where $4::int < 0 or "depth" < $4::int
+		)
+		select "knit_id", "run_id" from "data" inner join "traced" using ("run_id")
+		union
+		select "knit_id", "run_id" from "assign" inner join "traced" using ("run_id")
+		`,
+		knitId, query.Upstream, query.Downstream, query.Depth,
+	)
+	if err != nil {
+		return domain.Lineage{}, err
+	}
+	defer rows.Close()
+
+	knitIds := map[string]struct{}{knitId: {}}
+	runIds := map[string]struct{}{}
+	for rows.Next() {
+		var k, r string
+		if err := rows.Scan(&k, &r); err != nil {
+			return domain.Lineage{}, err
+		}
+		knitIds[k] = struct{}{}
+		runIds[r] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return domain.Lineage{}, err
+	}
+
+	return domain.Lineage{
+		KnitIds: slices.KeysOf(knitIds),
+		RunIds:  slices.KeysOf(runIds),
+	}, nil
+}
+
+func (m *dataPG) SetSize(ctx context.Context, knitId string, size domain.DataSize) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	cmd, err := conn.Exec(
+		ctx,
+		`
+		insert into "data_size" ("knit_id", "bytes", "files")
+		select "knit_id", $2, $3 from "data" where "knit_id" = $1
+		on conflict ("knit_id") do update
+		set "bytes" = $2, "files" = $3, "measured_at" = now()
+		`,
+		knitId, size.Bytes, size.Files,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return kpgerr.Missing{
+			Table: "data", Identity: fmt.Sprintf("knit_id='%s'", knitId),
+		}
+	}
+	return nil
+}
+
+func (m *dataPG) SetChecksum(ctx context.Context, knitId string, checksum string) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	cmd, err := conn.Exec(
+		ctx,
+		`
+		insert into "data_checksum" ("knit_id", "checksum")
+		select "knit_id", $2 from "data" where "knit_id" = $1
+		on conflict ("knit_id") do update
+		set "checksum" = $2, "computed_at" = now()
+		`,
+		knitId, checksum,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return kpgerr.Missing{
+			Table: "data", Identity: fmt.Sprintf("knit_id='%s'", knitId),
+		}
+	}
+	return nil
+}
+
+func (m *dataPG) Unmeasured(ctx context.Context) ([]string, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(
+		ctx,
+		`
+		select "knit_id" from "data"
+		inner join "run" using ("run_id")
+		where
+			"status" = any($1::runStatus[])
+			and (
+				"knit_id" not in (select "knit_id" from "data_size")
+				or "knit_id" not in (select "knit_id" from "data_checksum")
+			)
+		order by "run"."updated_at", "knit_id"
+		`,
+		[]string{domain.Done.String(), domain.Failed.String()},
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	knitIds := []string{}
+	for rows.Next() {
+		var knitId string
+		if err := rows.Scan(&knitId); err != nil {
+			return nil, err
+		}
+		knitIds = append(knitIds, knitId)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return knitIds, nil
+}
+
+func (m *dataPG) Usage(ctx context.Context, query domain.UsageQuery) ([]domain.Usage, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+
+	var sql string
+	args := []any{}
+	switch query.By {
+	case domain.UsageByPlan:
+		sql = `
+		select "plan_id", sum("bytes"), sum("files"), count(*)
+		from "data_size"
+		inner join "data" using ("knit_id")
+		group by "plan_id"
+		order by sum("bytes") desc, "plan_id"
+		`
+	case domain.UsageByTag:
+		sql = `
+		select "key", "value", sum("bytes"), sum("files"), count(*)
+		from "data_size"
+		inner join "tag_data" using ("knit_id")
+		inner join "tag" on "tag"."id" = "tag_data"."tag_id"
+		inner join "tag_key" on "tag_key"."id" = "tag"."key_id"
+		where $1 = '' or "key" = $1
+		group by "key", "value"
+		order by sum("bytes") desc, "key", "value"
+		`
+		args = append(args, query.TagKey)
+	case domain.UsageByTime:
+		sql = `
+		select
+			date_trunc($1, coalesce("timestamp", "run"."updated_at")) as "since",
+			sum("bytes"), sum("files"), count(*)
+		from "data_size"
+		inner join "data" using ("knit_id")
+		inner join "run" using ("run_id")
+		left outer join "knit_timestamp" using ("knit_id")
+		group by "since"
+		order by "since"
+		`
+		args = append(args, string(query.Interval))
+	default:
+		return nil, fmt.Errorf("unknown usage group: %s", query.By)
+	}
+
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usages := []domain.Usage{}
+	for rows.Next() {
+		u := domain.Usage{}
+		var dest []any
+		switch query.By {
+		case domain.UsageByPlan:
+			dest = []any{&u.PlanId}
+		case domain.UsageByTag:
+			u.Tag = &domain.Tag{}
+			dest = []any{&u.Tag.Key, &u.Tag.Value}
+		case domain.UsageByTime:
+			u.Since = new(time.Time)
+			dest = []any{u.Since}
+		}
+		dest = append(dest, &u.Size.Bytes, &u.Size.Files, &u.Data)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		usages = append(usages, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
@@ -0,0 +1,168 @@
+package checksum_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	testenv "github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/domain"
+	kpgdata "github.com/opst/knitfab/pkg/domain/data/db/postgres"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	. "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+// givenData returns Data below.
+//
+// - knit-a, knit-b: uploaded. knit-b is uploaded later.
+//
+// - knit-c: being uploaded.
+func givenData() tables.Operation {
+	TIMESTAMP := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	op := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: Padding36("upload"), Active: true, Hash: Padding36("#upload")},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: Padding36("upload"), Name: "knit#upload"},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1, PlanId: Padding36("upload"), Path: "/out"}: {},
+		},
+	}
+
+	for _, d := range []struct {
+		knitId  string
+		status  domain.KnitRunStatus
+		updated time.Time
+	}{
+		{knitId: "knit-a", status: domain.Done, updated: TIMESTAMP},
+		{knitId: "knit-b", status: domain.Done, updated: TIMESTAMP.Add(time.Hour)},
+		{knitId: "knit-c", status: domain.Running, updated: TIMESTAMP.Add(2 * time.Hour)},
+	} {
+		runId := Padding36("run/" + d.knitId)
+		updated := d.updated
+		attr := tables.DataAttibutes{}
+		if d.status == domain.Done {
+			attr.Timestamp = &updated
+		}
+		op.Steps = append(op.Steps, tables.Step{
+			Run: tables.Run{
+				RunId: runId, PlanId: Padding36("upload"), Status: d.status,
+				UpdatedAt: updated, LifecycleSuspendUntil: updated,
+			},
+			Outcomes: map[tables.Data]tables.DataAttibutes{
+				{
+					KnitId: Padding36(d.knitId), VolumeRef: "vol/" + d.knitId,
+					OutputId: 1, RunId: runId, PlanId: Padding36("upload"),
+				}: attr,
+			},
+		})
+	}
+	return op
+}
+
+func TestSetChecksum(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+
+	t.Run("the checksum is recorded, and overwritten by the next one", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		given := givenData()
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgdata.New(pool)
+		for _, checksum := range []string{
+			"sha256:" + Padding64("first"),
+			"sha256:" + Padding64("second"),
+		} {
+			if err := testee.SetChecksum(ctx, Padding36("knit-a"), checksum); err != nil {
+				t.Fatal(err)
+			}
+
+			data := try.To(testee.Get(ctx, []string{Padding36("knit-a"), Padding36("knit-b")})).OrFatal(t)
+			if actual := data[Padding36("knit-a")].Checksum; actual != checksum {
+				t.Errorf("checksum of knit-a: actual = %s, expected = %s", actual, checksum)
+			}
+			if actual := data[Padding36("knit-b")].Checksum; actual != "" {
+				t.Errorf("checksum of knit-b: actual = %s, expected to be empty", actual)
+			}
+		}
+	})
+
+	t.Run("when the Data is not found, it returns ErrMissing", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		given := givenData()
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgdata.New(pool)
+		err := testee.SetChecksum(ctx, Padding36("no-such-data"), "sha256:"+Padding64("checksum"))
+		if !errors.Is(err, kerr.ErrMissing) {
+			t.Errorf("error: actual = %v, expected = %v", err, kerr.ErrMissing)
+		}
+	})
+}
+
+func TestUnmeasured_Checksum(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+
+	type When struct {
+		sized     []string
+		checksums []string
+	}
+
+	theory := func(when When, expected []string) func(*testing.T) {
+		return func(t *testing.T) {
+			pool := poolBroaker.GetPool(ctx, t)
+			given := givenData()
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			testee := kpgdata.New(pool)
+			for _, knitId := range when.sized {
+				if err := testee.SetSize(ctx, knitId, domain.DataSize{Bytes: 1, Files: 1}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, knitId := range when.checksums {
+				if err := testee.SetChecksum(ctx, knitId, "sha256:"+Padding64(knitId)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			actual := try.To(testee.Unmeasured(ctx)).OrFatal(t)
+			if !cmp.SliceEq(actual, expected) {
+				t.Errorf("unmeasured: actual = %v, expected = %v", actual, expected)
+			}
+		}
+	}
+
+	t.Run("when Data have a size but no checksums, they are unmeasured", theory(
+		When{sized: []string{Padding36("knit-a"), Padding36("knit-b")}},
+		[]string{Padding36("knit-a"), Padding36("knit-b")},
+	))
+
+	t.Run("when Data have a checksum but no sizes, they are unmeasured", theory(
+		When{checksums: []string{Padding36("knit-a"), Padding36("knit-b")}},
+		[]string{Padding36("knit-a"), Padding36("knit-b")},
+	))
+
+	t.Run("when Data have both of a size and a checksum, they are measured", theory(
+		When{
+			sized:     []string{Padding36("knit-a"), Padding36("knit-b")},
+			checksums: []string{Padding36("knit-b")},
+		},
+		[]string{Padding36("knit-a")},
+	))
+}
@@ -876,7 +876,10 @@ func TestData_Find(t *testing.T) {
 				}
 				testee := kpgdata.New(pool, kpgdata.WithNominator(nom))
 
-				actual := try.To(testee.Find(ctx, testcase.when.tags, testcase.updatedSince, testcase.updatedUntil)).OrFatal(t)
+				actual, _, err := testee.Find(ctx, testcase.when.tags, testcase.updatedSince, testcase.updatedUntil, types.Page{})
+				if err != nil {
+					t.Fatal(err)
+				}
 
 				if !cmp.SliceEq(actual, testcase.then.knitId) {
 					t.Errorf(
@@ -964,7 +967,7 @@ func TestData_Find(t *testing.T) {
 				"2022-10-11T12:13:14.567+09:00",
 			)).OrFatal(t).Time().Add(1 * time.Hour)
 
-			actual := try.To(testee.Find(
+			actual, _, err := testee.Find(
 				ctx,
 				[]types.Tag{
 					{Key: types.KeyKnitTransient, Value: types.ValueKnitTransientProcessing},
@@ -972,7 +975,11 @@ func TestData_Find(t *testing.T) {
 				},
 				&dummySince,
 				&dummyUntil,
-			)).OrFatal(t)
+				types.Page{},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
 
 			expected := []string{} // empty!
 			if !cmp.SliceContentEq(actual, expected) {
@@ -997,7 +1004,7 @@ func TestData_Find(t *testing.T) {
 				"2022-10-11T12:13:14.567+09:00",
 			)).OrFatal(t).Time().Add(1 * time.Hour)
 
-			actual := try.To(testee.Find(
+			actual, _, err := testee.Find(
 				ctx,
 				[]types.Tag{
 					{Key: types.KeyKnitTransient, Value: types.ValueKnitTransientFailed},
@@ -1005,7 +1012,11 @@ func TestData_Find(t *testing.T) {
 				},
 				&dummySince,
 				&dummyUntil,
-			)).OrFatal(t)
+				types.Page{},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
 
 			expected := []string{} // empty!
 			if !cmp.SliceContentEq(actual, expected) {
@@ -1030,7 +1041,7 @@ func TestData_Find(t *testing.T) {
 				"2022-10-11T12:13:14.567+09:00",
 			)).OrFatal(t).Time().Add(1 * time.Hour)
 
-			actual := try.To(testee.Find(
+			actual, _, err := testee.Find(
 				ctx,
 				[]types.Tag{
 					types.NewTimestampTag(oldTimestamp),
@@ -1038,7 +1049,11 @@ func TestData_Find(t *testing.T) {
 				},
 				&dummySince,
 				&dummyUntil,
-			)).OrFatal(t)
+				types.Page{},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
 
 			expected := []string{} // empty!
 			if !cmp.SliceContentEq(actual, expected) {
@@ -1112,14 +1127,18 @@ func TestData_Find(t *testing.T) {
 				"2022-10-11T12:13:14.567+09:00",
 			)).OrFatal(t).Time().Add(1 * time.Hour)
 
-			actual := try.To(testee.Find(
+			actual, _, err := testee.Find(
 				ctx,
 				[]types.Tag{
 					{Key: types.KeyKnitTransient, Value: types.ValueKnitTransientProcessing},
 				},
 				&dummySince,
 				&dummyUntil,
-			)).OrFatal(t)
+				types.Page{},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
 
 			expected := []string{} // empty!
 			if !cmp.SliceContentEq(actual, expected) {
@@ -1143,14 +1162,18 @@ func TestData_Find(t *testing.T) {
 				"2022-10-11T12:13:14.567+09:00",
 			)).OrFatal(t).Time().Add(1 * time.Hour)
 
-			actual := try.To(testee.Find(
+			actual, _, err := testee.Find(
 				ctx,
 				[]types.Tag{
 					{Key: types.KeyKnitTransient, Value: types.ValueKnitTransientFailed},
 				},
 				&dummySince,
 				&dummyUntil,
-			)).OrFatal(t)
+				types.Page{},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
 
 			expected := []string{} // empty!
 			if !cmp.SliceContentEq(actual, expected) {
@@ -0,0 +1,270 @@
+package lineage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	testenv "github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/domain"
+	kpgdata "github.com/opst/knitfab/pkg/domain/data/db/postgres"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	. "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+)
+
+func TestLineage(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	// lineage:
+	//
+	//   [upload] --> data-1 --> [train] --> data-2 --> [eval] --> data-3
+	//                       |            +-> log-2
+	//                       +-> [train] --> data-4
+	//                                    +-> log-4
+	updatedAt := time.Date(2022, 10, 11, 12, 13, 14, 0, time.UTC)
+	given := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: Padding36("plan-upload"), Active: true, Hash: "#upload"},
+			{PlanId: Padding36("plan-train"), Active: true, Hash: "#train"},
+			{PlanId: Padding36("plan-eval"), Active: true, Hash: "#eval"},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: Padding36("plan-upload"), Name: "knit#uploaded"},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: Padding36("plan-train"), Image: "repo.invalid/train", Version: "v1"},
+			{PlanId: Padding36("plan-eval"), Image: "repo.invalid/eval", Version: "v1"},
+		},
+		Inputs: map[tables.Input]tables.InputAttr{
+			{PlanId: Padding36("plan-train"), InputId: 2_100, Path: "/in"}: {},
+			{PlanId: Padding36("plan-eval"), InputId: 3_100, Path: "/in"}:  {},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{PlanId: Padding36("plan-upload"), OutputId: 1_010, Path: "/out"}: {},
+			{PlanId: Padding36("plan-train"), OutputId: 2_010, Path: "/out"}:  {},
+			{PlanId: Padding36("plan-train"), OutputId: 2_020, Path: "/log"}:  {IsLog: true},
+			{PlanId: Padding36("plan-eval"), OutputId: 3_010, Path: "/out"}:   {},
+		},
+		Steps: []tables.Step{
+			{
+				Run: tables.Run{
+					PlanId: Padding36("plan-upload"), RunId: Padding36("run-upload"),
+					Status: domain.Done, UpdatedAt: updatedAt,
+				},
+				Outcomes: map[tables.Data]tables.DataAttibutes{
+					{
+						KnitId: Padding36("data-1"), VolumeRef: "#data-1",
+						PlanId: Padding36("plan-upload"), RunId: Padding36("run-upload"), OutputId: 1_010,
+					}: {},
+				},
+			},
+			{
+				Run: tables.Run{
+					PlanId: Padding36("plan-train"), RunId: Padding36("run-train-1"),
+					Status: domain.Done, UpdatedAt: updatedAt,
+				},
+				Assign: []tables.Assign{
+					{
+						PlanId: Padding36("plan-train"), RunId: Padding36("run-train-1"),
+						InputId: 2_100, KnitId: Padding36("data-1"),
+					},
+				},
+				Outcomes: map[tables.Data]tables.DataAttibutes{
+					{
+						KnitId: Padding36("data-2"), VolumeRef: "#data-2",
+						PlanId: Padding36("plan-train"), RunId: Padding36("run-train-1"), OutputId: 2_010,
+					}: {},
+					{
+						KnitId: Padding36("log-2"), VolumeRef: "#log-2",
+						PlanId: Padding36("plan-train"), RunId: Padding36("run-train-1"), OutputId: 2_020,
+					}: {},
+				},
+			},
+			{
+				Run: tables.Run{
+					PlanId: Padding36("plan-train"), RunId: Padding36("run-train-2"),
+					Status: domain.Done, UpdatedAt: updatedAt,
+				},
+				Assign: []tables.Assign{
+					{
+						PlanId: Padding36("plan-train"), RunId: Padding36("run-train-2"),
+						InputId: 2_100, KnitId: Padding36("data-1"),
+					},
+				},
+				Outcomes: map[tables.Data]tables.DataAttibutes{
+					{
+						KnitId: Padding36("data-4"), VolumeRef: "#data-4",
+						PlanId: Padding36("plan-train"), RunId: Padding36("run-train-2"), OutputId: 2_010,
+					}: {},
+					{
+						KnitId: Padding36("log-4"), VolumeRef: "#log-4",
+						PlanId: Padding36("plan-train"), RunId: Padding36("run-train-2"), OutputId: 2_020,
+					}: {},
+				},
+			},
+			{
+				Run: tables.Run{
+					PlanId: Padding36("plan-eval"), RunId: Padding36("run-eval"),
+					Status: domain.Done, UpdatedAt: updatedAt,
+				},
+				Assign: []tables.Assign{
+					{
+						PlanId: Padding36("plan-eval"), RunId: Padding36("run-eval"),
+						InputId: 3_100, KnitId: Padding36("data-2"),
+					},
+				},
+				Outcomes: map[tables.Data]tables.DataAttibutes{
+					{
+						KnitId: Padding36("data-3"), VolumeRef: "#data-3",
+						PlanId: Padding36("plan-eval"), RunId: Padding36("run-eval"), OutputId: 3_010,
+					}: {},
+				},
+			},
+		},
+	}
+
+	type When struct {
+		KnitId string
+		Query  domain.LineageQuery
+	}
+
+	type Then struct {
+		Lineage domain.Lineage
+		Error   error
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			testee := kpgdata.New(pool)
+
+			actual, err := testee.Lineage(ctx, when.KnitId, when.Query)
+			if then.Error != nil {
+				if !errors.Is(err, then.Error) {
+					t.Errorf("expected error %v but got %v", then.Error, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !actual.Equal(&then.Lineage) {
+				t.Errorf(
+					"unmatch:\n===actual===\n%+v\n===expected===\n%+v",
+					actual, then.Lineage,
+				)
+			}
+		}
+	}
+
+	t.Run("when there is no such data, it returns Missing error", theory(
+		When{
+			KnitId: Padding36("no-such-data"),
+			Query:  domain.LineageQuery{Upstream: true, Downstream: true, Depth: -1},
+		},
+		Then{Error: kerr.ErrMissing},
+	))
+
+	t.Run("when it traces nothing, it returns the root only", theory(
+		When{
+			KnitId: Padding36("data-2"),
+			Query:  domain.LineageQuery{Depth: -1},
+		},
+		Then{
+			Lineage: domain.Lineage{
+				KnitIds: []string{Padding36("data-2")},
+				RunIds:  []string{},
+			},
+		},
+	))
+
+	t.Run("when it traces downstream for 1 step, it returns runs using the data and their outputs", theory(
+		When{
+			KnitId: Padding36("data-1"),
+			Query:  domain.LineageQuery{Downstream: true, Depth: 1},
+		},
+		Then{
+			Lineage: domain.Lineage{
+				KnitIds: []string{
+					Padding36("data-1"),
+					Padding36("data-2"), Padding36("log-2"),
+					Padding36("data-4"), Padding36("log-4"),
+				},
+				RunIds: []string{Padding36("run-train-1"), Padding36("run-train-2")},
+			},
+		},
+	))
+
+	t.Run("when it traces downstream without limit, it returns all downstreams", theory(
+		When{
+			KnitId: Padding36("data-1"),
+			Query:  domain.LineageQuery{Downstream: true, Depth: -1},
+		},
+		Then{
+			Lineage: domain.Lineage{
+				KnitIds: []string{
+					Padding36("data-1"),
+					Padding36("data-2"), Padding36("log-2"),
+					Padding36("data-4"), Padding36("log-4"),
+					Padding36("data-3"),
+				},
+				RunIds: []string{
+					Padding36("run-train-1"), Padding36("run-train-2"), Padding36("run-eval"),
+				},
+			},
+		},
+	))
+
+	t.Run("when it traces upstream for 1 step, it returns the run generating the data and its inputs and outputs", theory(
+		When{
+			KnitId: Padding36("data-3"),
+			Query:  domain.LineageQuery{Upstream: true, Depth: 1},
+		},
+		Then{
+			Lineage: domain.Lineage{
+				KnitIds: []string{Padding36("data-3"), Padding36("data-2")},
+				RunIds:  []string{Padding36("run-eval")},
+			},
+		},
+	))
+
+	t.Run("when it traces upstream without limit, it returns all upstreams", theory(
+		When{
+			KnitId: Padding36("data-3"),
+			Query:  domain.LineageQuery{Upstream: true, Depth: -1},
+		},
+		Then{
+			Lineage: domain.Lineage{
+				KnitIds: []string{
+					Padding36("data-3"), Padding36("data-2"), Padding36("log-2"), Padding36("data-1"),
+				},
+				RunIds: []string{
+					Padding36("run-eval"), Padding36("run-train-1"), Padding36("run-upload"),
+				},
+			},
+		},
+	))
+
+	t.Run("when it traces both directions, it returns upstreams and downstreams", theory(
+		When{
+			KnitId: Padding36("data-2"),
+			Query:  domain.LineageQuery{Upstream: true, Downstream: true, Depth: 1},
+		},
+		Then{
+			Lineage: domain.Lineage{
+				KnitIds: []string{
+					Padding36("data-1"), Padding36("data-2"), Padding36("log-2"), Padding36("data-3"),
+				},
+				RunIds: []string{Padding36("run-train-1"), Padding36("run-eval")},
+			},
+		},
+	))
+}
@@ -0,0 +1,109 @@
+package parameter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	testenv "github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/domain"
+	kpgdata "github.com/opst/knitfab/pkg/domain/data/db/postgres"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	. "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+)
+
+func TestParameterData(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+	pool := poolBroaker.GetPool(ctx, t)
+
+	TIMESTAMP := time.Date(2022, time.August, 1, 12, 13, 24, 0, time.UTC)
+
+	given := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: Padding36("parameter"), Active: true, Hash: "#parameter"},
+			{PlanId: Padding36("plan"), Active: true, Hash: "#plan"},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: Padding36("parameter"), Name: string(domain.Parameter)},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: Padding36("plan"), Image: "repo.invalid/image", Version: "v1"},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1, PlanId: Padding36("parameter"), Path: "/parameter"}: {},
+		},
+		Inputs: map[tables.Input]tables.InputAttr{
+			{InputId: 100, PlanId: Padding36("plan"), Path: "/in/lr"}: {
+				Values: []string{"0.1"},
+			},
+		},
+		Steps: []tables.Step{
+			{
+				Run: tables.Run{
+					RunId: Padding36("run-param"), PlanId: Padding36("parameter"),
+					Status: domain.Done, UpdatedAt: TIMESTAMP,
+				},
+				Outcomes: map[tables.Data]tables.DataAttibutes{
+					{
+						KnitId: Padding36("knit-param"), VolumeRef: "vol-param",
+						OutputId: 1, RunId: Padding36("run-param"), PlanId: Padding36("parameter"),
+					}: {Timestamp: &TIMESTAMP},
+				},
+			},
+			{
+				Run: tables.Run{
+					RunId: Padding36("run-other"), PlanId: Padding36("parameter"),
+					Status: domain.Done, UpdatedAt: TIMESTAMP,
+				},
+				Outcomes: map[tables.Data]tables.DataAttibutes{
+					{
+						KnitId: Padding36("knit-other"), VolumeRef: "vol-other",
+						OutputId: 1, RunId: Padding36("run-other"), PlanId: Padding36("parameter"),
+					}: {Timestamp: &TIMESTAMP},
+				},
+			},
+		},
+		ParameterData: []tables.ParameterData{
+			{KnitId: Padding36("knit-param"), Value: "0.1", InputId: 100},
+		},
+	}
+	if err := given.Apply(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+
+	testee := kpgdata.New(pool)
+
+	t.Run("Get returns the parameter value of Data", func(t *testing.T) {
+		ds, err := testee.Get(ctx, []string{Padding36("knit-param"), Padding36("knit-other")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if p := ds[Padding36("knit-param")].Parameter; p == nil || *p != "0.1" {
+			t.Errorf("parameter of knit-param: %v", p)
+		}
+		if p := ds[Padding36("knit-other")].Parameter; p != nil {
+			t.Errorf("parameter of knit-other: %v", *p)
+		}
+	})
+
+	t.Run("GetParameterValue returns the parameter value of Data", func(t *testing.T) {
+		value, err := testee.GetParameterValue(ctx, Padding36("knit-param"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != "0.1" {
+			t.Errorf("value: actual = %s, expected = 0.1", value)
+		}
+	})
+
+	t.Run("GetParameterValue returns ErrMissing for Data not from parameters", func(t *testing.T) {
+		_, err := testee.GetParameterValue(ctx, Padding36("knit-other"))
+		if !errors.Is(err, kerr.ErrMissing) {
+			t.Errorf("error: actual = %v, expected = %v", err, kerr.ErrMissing)
+		}
+	})
+}
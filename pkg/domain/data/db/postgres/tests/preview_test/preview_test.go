@@ -0,0 +1,217 @@
+package preview_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testenv "github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	kpgdata "github.com/opst/knitfab/pkg/domain/data/db/postgres"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	. "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+var (
+	TAG      = domain.Tag{Key: "tagkey", Value: "tagval"}
+	OTHER    = domain.Tag{Key: "other", Value: "tagval"}
+	SELECTED = domain.Tag{Key: "selected", Value: "tagval"}
+)
+
+// givenPlansAndData returns Plans and Data below.
+//
+// - plan: Plan with image. Input 100 takes TAG, and Input 101 takes OTHER.
+// It has a Run with knit-tagged and knit-other.
+//
+// - plan-latest: Plan with image. Input 200 takes SELECTED, only the latest one.
+//
+// - knit-tagged: with TAG. Nominated to Input 100.
+//
+// - knit-pending: with TAG. Nominated to Input 100, and not projected yet.
+//
+// - knit-other: with OTHER. Nominated to Input 101.
+//
+// - knit-untagged: without tags.
+//
+// - knit-old, knit-new: with SELECTED. knit-new is newer and nominated to Input 200.
+func givenPlansAndData() tables.Operation {
+	TIMESTAMP := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	op := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: Padding36("upload"), Active: true, Hash: Padding36("#upload")},
+			{PlanId: Padding36("plan"), Active: true, Hash: Padding36("#plan")},
+			{PlanId: Padding36("plan-latest"), Active: true, Hash: Padding36("#plan-latest")},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: Padding36("upload"), Name: "knit#upload"},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: Padding36("plan"), Image: "repo.invalid/plan", Version: "v1"},
+			{PlanId: Padding36("plan-latest"), Image: "repo.invalid/plan-latest", Version: "v1"},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1, PlanId: Padding36("upload"), Path: "/out"}: {},
+		},
+		Inputs: map[tables.Input]tables.InputAttr{
+			{InputId: 100, PlanId: Padding36("plan"), Path: "/in/1"}: {
+				UserTag: []domain.Tag{TAG},
+			},
+			{InputId: 101, PlanId: Padding36("plan"), Path: "/in/2"}: {
+				UserTag: []domain.Tag{OTHER},
+			},
+			{InputId: 200, PlanId: Padding36("plan-latest"), Path: "/in"}: {
+				UserTag: []domain.Tag{SELECTED}, Latest: 1,
+			},
+		},
+		Nomination: []tables.Nomination{
+			{KnitId: Padding36("knit-tagged"), InputId: 100},
+			{KnitId: Padding36("knit-pending"), InputId: 100, Updated: true},
+			{KnitId: Padding36("knit-other"), InputId: 101},
+			{KnitId: Padding36("knit-new"), InputId: 200},
+		},
+	}
+
+	for nth, d := range []struct {
+		knitId string
+		tags   []domain.Tag
+	}{
+		{knitId: "knit-tagged", tags: []domain.Tag{TAG}},
+		{knitId: "knit-pending", tags: []domain.Tag{TAG}},
+		{knitId: "knit-other", tags: []domain.Tag{OTHER}},
+		{knitId: "knit-untagged", tags: []domain.Tag{}},
+		{knitId: "knit-old", tags: []domain.Tag{SELECTED}},
+		{knitId: "knit-new", tags: []domain.Tag{SELECTED}},
+	} {
+		runId := Padding36("run/" + d.knitId)
+		timestamp := TIMESTAMP.Add(time.Duration(nth) * time.Hour)
+		op.Steps = append(op.Steps, tables.Step{
+			Run: tables.Run{
+				RunId: runId, PlanId: Padding36("upload"), Status: domain.Done,
+				UpdatedAt: timestamp, LifecycleSuspendUntil: timestamp,
+			},
+			Outcomes: map[tables.Data]tables.DataAttibutes{
+				{
+					KnitId: Padding36(d.knitId), VolumeRef: "vol/" + d.knitId,
+					OutputId: 1, RunId: runId, PlanId: Padding36("upload"),
+				}: {UserTag: d.tags, Timestamp: &timestamp},
+			},
+		})
+	}
+
+	op.Steps = append(op.Steps, tables.Step{
+		Run: tables.Run{
+			RunId: Padding36("run/plan"), PlanId: Padding36("plan"), Status: domain.Done,
+			UpdatedAt: TIMESTAMP, LifecycleSuspendUntil: TIMESTAMP,
+		},
+		Assign: []tables.Assign{
+			{RunId: Padding36("run/plan"), PlanId: Padding36("plan"), InputId: 100, KnitId: Padding36("knit-tagged")},
+			{RunId: Padding36("run/plan"), PlanId: Padding36("plan"), InputId: 101, KnitId: Padding36("knit-other")},
+		},
+	})
+
+	return op
+}
+
+func TestPreviewUpdateTag(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	type When struct {
+		knitId string
+		delta  domain.TagDelta
+	}
+	type Then struct {
+		// input id -> knit id, for each previewed Run
+		runs []map[int]string
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			given := givenPlansAndData()
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			testee := kpgdata.New(pool)
+			previews := try.To(testee.PreviewUpdateTag(ctx, when.knitId, when.delta)).OrFatal(t)
+
+			actual := []map[int]string{}
+			for _, p := range previews {
+				run := map[int]string{}
+				for _, a := range p.Inputs {
+					run[a.MountPoint.Id] = a.KnitDataBody.KnitId
+				}
+				actual = append(actual, run)
+			}
+			if !cmp.SliceContentEqWith(actual, then.runs, cmp.MapEq[int, string]) {
+				t.Errorf("previewed runs: actual = %v, expected = %v", actual, then.runs)
+			}
+
+			// nothing is changed.
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+			nominations := try.To(scanner.New[tables.Nomination]().QueryAll(
+				ctx, conn, `table "nomination"`,
+			)).OrFatal(t)
+			if !cmp.SliceContentEq(nominations, given.Nomination) {
+				t.Errorf("nominations are changed: %+v", nominations)
+			}
+			tagged := try.To(scanner.New[int]().QueryAll(
+				ctx, conn, `select count(*) from "tag_data" where "knit_id" = $1`, when.knitId,
+			)).OrFatal(t)
+			if len(tagged) != 1 || tagged[0] != tagCount(given, when.knitId) {
+				t.Errorf("tags of the data are changed: %v", tagged)
+			}
+		}
+	}
+
+	t.Run("when the data is tagged to be nominated, Runs with it and other nominations are previewed", theory(
+		When{
+			knitId: Padding36("knit-untagged"),
+			delta:  domain.TagDelta{Add: []domain.Tag{TAG}},
+		},
+		Then{
+			runs: []map[int]string{
+				{100: Padding36("knit-untagged"), 101: Padding36("knit-other")},
+			},
+		},
+	))
+
+	t.Run("when the data is already nominated, nothing is previewed", theory(
+		When{
+			knitId: Padding36("knit-tagged"),
+			delta:  domain.TagDelta{Add: []domain.Tag{{Key: "unrelated", Value: "tag"}}},
+		},
+		Then{runs: []map[int]string{}},
+	))
+
+	t.Run("when the latest data loses its tag, the next one is previewed", theory(
+		When{
+			knitId: Padding36("knit-new"),
+			delta:  domain.TagDelta{RemoveKey: []string{SELECTED.Key}},
+		},
+		Then{
+			runs: []map[int]string{
+				{200: Padding36("knit-old")},
+			},
+		},
+	))
+}
+
+// tagCount returns the number of user tags of the data in the Operation.
+func tagCount(op tables.Operation, knitId string) int {
+	for _, s := range op.Steps {
+		for d, attr := range s.Outcomes {
+			if d.KnitId == knitId {
+				return len(attr.UserTag)
+			}
+		}
+	}
+	return 0
+}
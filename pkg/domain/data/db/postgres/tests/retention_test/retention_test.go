@@ -0,0 +1,175 @@
+package retention_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	testenv "github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	kpgdata "github.com/opst/knitfab/pkg/domain/data/db/postgres"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	. "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgrun "github.com/opst/knitfab/pkg/domain/run/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+// Data are expired by retention rules through DataInterface.Find,
+// and deleted with their upstream Runs through RunInterface.Delete.
+func TestRetention(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+
+	NOW := time.Now()
+	OLD := NOW.Add(-40 * 24 * time.Hour)
+	NEW := NOW.Add(-1 * 24 * time.Hour)
+
+	DATASET := domain.Tag{Key: "type", Value: "dataset"}
+	PROJECT := domain.Tag{Key: "project", Value: "retention"}
+
+	RULE := domain.RetentionRule{
+		Name: "datasets", Tags: []domain.Tag{DATASET}, Age: 30 * 24 * time.Hour,
+	}
+
+	// - knit-old-used: old dataset, used by the run of "train".
+	// - knit-old-unused: old dataset, not used.
+	// - knit-new: new dataset.
+	// - knit-old-other: old, but not a dataset.
+	given := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: Padding36("upload"), Active: true, Hash: Padding36("#upload")},
+			{PlanId: Padding36("train"), Active: true, Hash: Padding36("#train")},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: Padding36("upload"), Name: "knit#upload"},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: Padding36("train"), Image: "repo.invalid/train", Version: "v1"},
+		},
+		Inputs: map[tables.Input]tables.InputAttr{
+			{InputId: 100, PlanId: Padding36("train"), Path: "/in"}: {
+				UserTag: []domain.Tag{DATASET},
+			},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1, PlanId: Padding36("upload"), Path: "/out"}:  {},
+			{OutputId: 110, PlanId: Padding36("train"), Path: "/out"}: {},
+		},
+	}
+	for _, d := range []struct {
+		knitId    string
+		timestamp time.Time
+		tags      []domain.Tag
+	}{
+		{knitId: "knit-old-used", timestamp: OLD, tags: []domain.Tag{DATASET, PROJECT}},
+		{knitId: "knit-old-unused", timestamp: OLD, tags: []domain.Tag{DATASET}},
+		{knitId: "knit-new", timestamp: NEW, tags: []domain.Tag{DATASET}},
+		{knitId: "knit-old-other", timestamp: OLD, tags: []domain.Tag{PROJECT}},
+	} {
+		runId := Padding36("run/" + d.knitId)
+		timestamp := d.timestamp
+		given.Steps = append(given.Steps, tables.Step{
+			Run: tables.Run{
+				RunId: runId, PlanId: Padding36("upload"), Status: domain.Done,
+				UpdatedAt: timestamp, LifecycleSuspendUntil: timestamp,
+			},
+			Outcomes: map[tables.Data]tables.DataAttibutes{
+				{
+					KnitId: Padding36(d.knitId), VolumeRef: "vol/" + d.knitId,
+					OutputId: 1, RunId: runId, PlanId: Padding36("upload"),
+				}: {UserTag: d.tags, Timestamp: &timestamp},
+			},
+		})
+	}
+	given.Steps = append(given.Steps, tables.Step{
+		Run: tables.Run{
+			RunId: Padding36("run/train"), PlanId: Padding36("train"), Status: domain.Done,
+			UpdatedAt: NEW, LifecycleSuspendUntil: NEW,
+		},
+		Assign: []tables.Assign{
+			{
+				RunId: Padding36("run/train"), PlanId: Padding36("train"),
+				InputId: 100, KnitId: Padding36("knit-old-used"),
+			},
+		},
+		Outcomes: map[tables.Data]tables.DataAttibutes{
+			{
+				KnitId: Padding36("knit-model"), VolumeRef: "vol/knit-model",
+				OutputId: 110, RunId: Padding36("run/train"), PlanId: Padding36("train"),
+			}: {Timestamp: &NEW},
+		},
+	})
+
+	t.Run("Data with all tags of the rule, older than its age, are expired", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		until := NOW.Add(-RULE.Age)
+		actual, _, err := kpgdata.New(pool).Find(ctx, RULE.Tags, nil, &until, domain.Page{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{Padding36("knit-old-used"), Padding36("knit-old-unused")}
+		if !cmp.SliceContentEq(actual, expected) {
+			t.Errorf("expired data: actual = %v, expected = %v", actual, expected)
+		}
+	})
+
+	t.Run("when expired Data are not used, they are deleted with their Run", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := kpgrun.New(pool).Delete(ctx, Padding36("run/knit-old-unused")); err != nil {
+			t.Fatal(err)
+		}
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+
+		garbage := try.To(scanner.New[string]().QueryAll(
+			ctx, conn, `select "knit_id" from "garbage"`,
+		)).OrFatal(t)
+		if !cmp.SliceContentEq(garbage, []string{Padding36("knit-old-unused")}) {
+			t.Errorf("garbage: %v", garbage)
+		}
+
+		data := try.To(kpgdata.New(pool).Get(ctx, []string{Padding36("knit-old-unused")})).OrFatal(t)
+		if len(data) != 0 {
+			t.Errorf("deleted data are found: %+v", data)
+		}
+	})
+
+	t.Run("when expired Data are used by other Runs, they are kept", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		err := kpgrun.New(pool).Delete(ctx, Padding36("run/knit-old-used"))
+		if !errors.Is(err, domain.ErrRunHasDownstreams) {
+			t.Errorf("error: actual = %v, expected = %v", err, domain.ErrRunHasDownstreams)
+		}
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+
+		garbage := try.To(scanner.New[string]().QueryAll(
+			ctx, conn, `select "knit_id" from "garbage"`,
+		)).OrFatal(t)
+		if len(garbage) != 0 {
+			t.Errorf("garbage: %v", garbage)
+		}
+
+		data := try.To(kpgdata.New(pool).Get(ctx, []string{Padding36("knit-old-used")})).OrFatal(t)
+		if _, ok := data[Padding36("knit-old-used")]; !ok {
+			t.Errorf("used data are deleted")
+		}
+	})
+}
@@ -0,0 +1,285 @@
+package size_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	testenv "github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/domain"
+	kpgdata "github.com/opst/knitfab/pkg/domain/data/db/postgres"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	. "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+var (
+	DAY1 = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	DAY2 = time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC)
+
+	DATASET = domain.Tag{Key: "type", Value: "dataset"}
+	MODEL   = domain.Tag{Key: "type", Value: "model"}
+	PROJECT = domain.Tag{Key: "project", Value: "size"}
+)
+
+// givenData returns Data below.
+//
+// - knit-a: uploaded at DAY1. tags: type=dataset, project=size.
+//
+// - knit-b: uploaded at DAY2. tags: type=dataset.
+//
+// - knit-c: output of a failed Run of "train", at DAY2 (later than knit-b). tags: type=model.
+//
+// - knit-d: output of a running Run of "train".
+func givenData() tables.Operation {
+	op := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: Padding36("upload"), Active: true, Hash: Padding36("#upload")},
+			{PlanId: Padding36("train"), Active: true, Hash: Padding36("#train")},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: Padding36("upload"), Name: "knit#upload"},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: Padding36("train"), Image: "repo.invalid/train", Version: "v1"},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1, PlanId: Padding36("upload"), Path: "/out"}:  {},
+			{OutputId: 110, PlanId: Padding36("train"), Path: "/out"}: {},
+		},
+	}
+
+	for _, d := range []struct {
+		knitId   string
+		planId   string
+		outputId int
+		status   domain.KnitRunStatus
+		updated  time.Time
+		tags     []domain.Tag
+	}{
+		{
+			knitId: "knit-a", planId: "upload", outputId: 1, status: domain.Done,
+			updated: DAY1, tags: []domain.Tag{DATASET, PROJECT},
+		},
+		{
+			knitId: "knit-b", planId: "upload", outputId: 1, status: domain.Done,
+			updated: DAY2, tags: []domain.Tag{DATASET},
+		},
+		{
+			knitId: "knit-c", planId: "train", outputId: 110, status: domain.Failed,
+			updated: DAY2.Add(time.Hour), tags: []domain.Tag{MODEL},
+		},
+		{
+			knitId: "knit-d", planId: "train", outputId: 110, status: domain.Running,
+			updated: DAY2.Add(time.Hour), tags: []domain.Tag{MODEL},
+		},
+	} {
+		runId := Padding36("run/" + d.knitId)
+		updated := d.updated
+		attr := tables.DataAttibutes{UserTag: d.tags}
+		if d.status == domain.Done || d.status == domain.Failed {
+			attr.Timestamp = &updated
+		}
+		op.Steps = append(op.Steps, tables.Step{
+			Run: tables.Run{
+				RunId: runId, PlanId: Padding36(d.planId), Status: d.status,
+				UpdatedAt: updated, LifecycleSuspendUntil: updated,
+			},
+			Outcomes: map[tables.Data]tables.DataAttibutes{
+				{
+					KnitId: Padding36(d.knitId), VolumeRef: "vol/" + d.knitId,
+					OutputId: d.outputId, RunId: runId, PlanId: Padding36(d.planId),
+				}: attr,
+			},
+		})
+	}
+	return op
+}
+
+func TestSetSize(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+
+	t.Run("the size is recorded, and overwritten by the next one", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		given := givenData()
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgdata.New(pool)
+		for _, size := range []domain.DataSize{
+			{Bytes: 100, Files: 2},
+			{Bytes: 120, Files: 3},
+		} {
+			if err := testee.SetSize(ctx, Padding36("knit-a"), size); err != nil {
+				t.Fatal(err)
+			}
+
+			data := try.To(testee.Get(ctx, []string{Padding36("knit-a"), Padding36("knit-b")})).OrFatal(t)
+			if actual := data[Padding36("knit-a")].Size; actual == nil || *actual != size {
+				t.Errorf("size of knit-a: actual = %+v, expected = %+v", actual, size)
+			}
+			if actual := data[Padding36("knit-b")].Size; actual != nil {
+				t.Errorf("size of knit-b: actual = %+v, expected = nil", actual)
+			}
+		}
+	})
+
+	t.Run("when the Data is not found, it returns ErrMissing", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		given := givenData()
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgdata.New(pool)
+		err := testee.SetSize(ctx, Padding36("no-such-data"), domain.DataSize{Bytes: 1, Files: 1})
+		if !errors.Is(err, kerr.ErrMissing) {
+			t.Errorf("error: actual = %v, expected = %v", err, kerr.ErrMissing)
+		}
+	})
+}
+
+func TestUnmeasured(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+	pool := poolBroaker.GetPool(ctx, t)
+
+	given := givenData()
+	if err := given.Apply(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+
+	testee := kpgdata.New(pool)
+
+	{
+		actual := try.To(testee.Unmeasured(ctx)).OrFatal(t)
+		expected := []string{Padding36("knit-a"), Padding36("knit-b"), Padding36("knit-c")}
+		if !cmp.SliceEq(actual, expected) {
+			t.Errorf("unmeasured: actual = %v, expected = %v", actual, expected)
+		}
+	}
+
+	// Data are measured when both of the size and the checksum are recorded.
+	if err := testee.SetSize(ctx, Padding36("knit-b"), domain.DataSize{Bytes: 1, Files: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := testee.SetChecksum(ctx, Padding36("knit-b"), "sha256:b"); err != nil {
+		t.Fatal(err)
+	}
+
+	{
+		actual := try.To(testee.Unmeasured(ctx)).OrFatal(t)
+		expected := []string{Padding36("knit-a"), Padding36("knit-c")}
+		if !cmp.SliceEq(actual, expected) {
+			t.Errorf("unmeasured after measured: actual = %v, expected = %v", actual, expected)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+	pool := poolBroaker.GetPool(ctx, t)
+
+	given := givenData()
+	if err := given.Apply(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+
+	testee := kpgdata.New(pool)
+	for knitId, size := range map[string]domain.DataSize{
+		"knit-a": {Bytes: 100, Files: 2},
+		"knit-b": {Bytes: 50, Files: 1},
+		"knit-c": {Bytes: 30, Files: 3},
+	} {
+		if err := testee.SetSize(ctx, Padding36(knitId), size); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+	defer conn.Release()
+
+	// buckets are truncated in the timezone of the database.
+	day := func(t *testing.T, ts time.Time) *time.Time {
+		var truncated time.Time
+		if err := conn.QueryRow(
+			ctx, `select date_trunc('day', $1::timestamp with time zone)`, ts,
+		).Scan(&truncated); err != nil {
+			t.Fatal(err)
+		}
+		return &truncated
+	}
+
+	eq := func(a, b domain.Usage) bool {
+		return a.PlanId == b.PlanId &&
+			cmp.PEqEq(a.Tag, b.Tag) &&
+			cmp.PEqualWith(a.Since, b.Since, time.Time.Equal) &&
+			a.Size == b.Size && a.Data == b.Data
+	}
+
+	for name, testcase := range map[string]struct {
+		query    domain.UsageQuery
+		expected func(*testing.T) []domain.Usage
+	}{
+		"by plan": {
+			query: domain.UsageQuery{By: domain.UsageByPlan},
+			expected: func(*testing.T) []domain.Usage {
+				return []domain.Usage{
+					{PlanId: Padding36("upload"), Size: domain.DataSize{Bytes: 150, Files: 3}, Data: 2},
+					{PlanId: Padding36("train"), Size: domain.DataSize{Bytes: 30, Files: 3}, Data: 1},
+				}
+			},
+		},
+		"by tag": {
+			query: domain.UsageQuery{By: domain.UsageByTag},
+			expected: func(*testing.T) []domain.Usage {
+				return []domain.Usage{
+					{Tag: &DATASET, Size: domain.DataSize{Bytes: 150, Files: 3}, Data: 2},
+					{Tag: &PROJECT, Size: domain.DataSize{Bytes: 100, Files: 2}, Data: 1},
+					{Tag: &MODEL, Size: domain.DataSize{Bytes: 30, Files: 3}, Data: 1},
+				}
+			},
+		},
+		"by tag with key": {
+			query: domain.UsageQuery{By: domain.UsageByTag, TagKey: "type"},
+			expected: func(*testing.T) []domain.Usage {
+				return []domain.Usage{
+					{Tag: &DATASET, Size: domain.DataSize{Bytes: 150, Files: 3}, Data: 2},
+					{Tag: &MODEL, Size: domain.DataSize{Bytes: 30, Files: 3}, Data: 1},
+				}
+			},
+		},
+		"by day": {
+			query: domain.UsageQuery{By: domain.UsageByTime, Interval: domain.UsageDaily},
+			expected: func(t *testing.T) []domain.Usage {
+				return []domain.Usage{
+					{Since: day(t, DAY1), Size: domain.DataSize{Bytes: 100, Files: 2}, Data: 1},
+					{Since: day(t, DAY2), Size: domain.DataSize{Bytes: 80, Files: 4}, Data: 2},
+				}
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, err := testee.Usage(ctx, testcase.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := testcase.expected(t)
+			if !cmp.SliceEqWith(actual, expected, eq) {
+				t.Errorf("usage:\n===actual===\n%+v\n===expected===\n%+v", actual, expected)
+			}
+		})
+	}
+
+	t.Run("when the group is unknown, it returns an error", func(t *testing.T) {
+		if _, err := testee.Usage(ctx, domain.UsageQuery{By: "unknown"}); err == nil {
+			t.Error("no errors are returned")
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
+	"github.com/opst/knitfab/pkg/domain"
+)
+
+// Audit appends an audit entry.
+//
+// The actor is taken from ctx (see domain.WithActor).
+// Call this in the same transaction as the change to be audited.
+//
+// # Args
+//
+// - before, after: snapshot of the target. They are marshalled into JSON. nil is stored as NULL.
+func Audit(
+	ctx context.Context, conn kpool.Queryer,
+	action domain.AuditAction, targetType domain.AuditTargetType, targetId string,
+	before any, after any,
+) error {
+	b, err := marshalSnapshot(before)
+	if err != nil {
+		return err
+	}
+	a, err := marshalSnapshot(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Exec(
+		ctx,
+		`
+		insert into "audit_log"
+			("actor", "action", "target_type", "target_id", "before", "after")
+		values ($1, $2, $3, $4, $5, $6)
+		`,
+		domain.ActorOf(ctx), string(action), string(targetType), targetId, b, a,
+	)
+	return err
+}
+
+func marshalSnapshot(v any) (*string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := string(buf)
+	return &s, nil
+}
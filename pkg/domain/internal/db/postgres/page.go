@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opst/knitfab/pkg/domain"
+)
+
+// Cursor is a position in results ordered by (Time, Id).
+//
+// Time is nil when results are ordered by Id only, or the time of the position is null.
+type Cursor struct {
+	Time *time.Time `json:"t,omitempty"`
+	Id   string     `json:"id"`
+}
+
+// String encodes the cursor into an opaque string.
+func (c Cursor) String() string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		// Cursor is always marshalable.
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseCursor decodes the cursor encoded by Cursor.String.
+//
+// When s is empty, it returns nil.
+//
+// # Returns
+//
+// - *Cursor: decoded cursor, or nil.
+//
+// - error: domain.ErrInvalidCursor, if s is not a cursor.
+func ParseCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidCursor, err)
+	}
+	c := Cursor{}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidCursor, err)
+	}
+	if c.Id == "" {
+		return nil, fmt.Errorf("%w: no id", domain.ErrInvalidCursor)
+	}
+	return &c, nil
+}
+
+// PageDirection returns the comparison operator to select results after the cursor,
+// and the direction to sort them, in SQL.
+func PageDirection(o domain.Order) (op string, dir string) {
+	if o == domain.Descending {
+		return "<", "desc"
+	}
+	return ">", "asc"
+}
+
+// PageLimit returns the limit of the query for the page.
+//
+// It is one more than the Limit of the page, to know whether the next page exists.
+// It is nil when the page is unlimited.
+func PageLimit(p domain.Page) *int {
+	if p.Limit <= 0 {
+		return nil
+	}
+	l := p.Limit + 1
+	return &l
+}
+
+// NextPage trims results queried with PageLimit into the page,
+// and returns the cursor of the next page.
+//
+// The cursor is empty when there are no more results.
+func NextPage[T any](p domain.Page, found []T, key func(T) Cursor) ([]T, string) {
+	if p.Limit <= 0 || len(found) <= p.Limit {
+		return found, ""
+	}
+	found = found[:p.Limit]
+	return found, key(found[len(found)-1]).String()
+}
@@ -55,17 +55,36 @@ func GetPlanBody(ctx context.Context, conn kpool.Queryer, planIds []string) (map
 		),
 		"plan_args" as (
 			select "plan_id", "args" from "plan_args" where "plan_id" = any($1)
+		),
+		"predecessor" as (
+			select "plan_id", "supersedes" from "plan_supersede" where "plan_id" = any($1)
+		),
+		"successor" as (
+			select "supersedes" as "plan_id", "plan_id" as "superseded_by"
+			from "plan_supersede" where "supersedes" = any($1)
 		)
 		select
 			"plan_id", "active", "hash", "entrypoint", "args",
 			"image" is not null as "is_image", coalesce("image", ''), coalesce("version", ''),
-			"name" is not null as "is_pseudo", coalesce("name", ''), coalesce("service_account", '')
+			"name" is not null as "is_pseudo", coalesce("name", ''), coalesce("service_account", ''),
+			coalesce("supersedes", ''), coalesce("superseded_by", ''), coalesce("schedule", ''),
+			coalesce("priority", 0), coalesce("max_concurrency", 0),
+			coalesce("plan_max_duration"."seconds", 0),
+			coalesce("priority_class_name", ''), coalesce("runtime_class_name", ''),
+			coalesce("plan_shm"."size", '0')
 		from "plan"
 		left outer join "plan_image" using ("plan_id")
 		left outer join "plan_pseudo" using ("plan_id")
 		left outer join "plan_service_account" using ("plan_id")
 		left outer join "plan_entrypoint" using ("plan_id")
 		left outer join "plan_args" using ("plan_id")
+		left outer join "predecessor" using ("plan_id")
+		left outer join "successor" using ("plan_id")
+		left outer join "plan_schedule" using ("plan_id")
+		left outer join "plan_priority" using ("plan_id")
+		left outer join "plan_max_duration" using ("plan_id")
+		left outer join "plan_pod_class" using ("plan_id")
+		left outer join "plan_shm" using ("plan_id")
 		`,
 		planIds,
 	)
@@ -78,17 +97,27 @@ func GetPlanBody(ctx context.Context, conn kpool.Queryer, planIds []string) (map
 	for rows.Next() {
 		var isImage, isPseudo bool
 		plan := domain.PlanBody{
-			Resources: map[string]resource.Quantity{},
+			Resources:        map[string]resource.Quantity{},
+			ResourceRequests: map[string]resource.Quantity{},
 		}
 		image := domain.ImageIdentifier{}
 		pseudoDetail := domain.PseudoPlanDetail{}
+		var maxDurationSeconds int
+		var shmSize ResourceQuantity
 		if err := rows.Scan(
 			&plan.PlanId, &plan.Active, &plan.Hash, &plan.Entrypoint, &plan.Args,
 			&isImage, &image.Image, &image.Version,
 			&isPseudo, &pseudoDetail.Name, &plan.ServiceAccount,
+			&plan.Supersedes, &plan.SupersededBy, &plan.Schedule,
+			&plan.Priority, &plan.MaxConcurrency,
+			&maxDurationSeconds,
+			&plan.PriorityClassName, &plan.RuntimeClassName,
+			&shmSize,
 		); err != nil {
 			return nil, err
 		}
+		plan.MaxDuration = time.Duration(maxDurationSeconds) * time.Second
+		plan.ShmSize = resource.Quantity(shmSize)
 		if isImage {
 			plan.Image = &image
 		}
@@ -173,6 +202,161 @@ func GetPlanBody(ctx context.Context, conn kpool.Queryer, planIds []string) (map
 		result[planId] = plan
 	}
 
+	requests_rows, err := conn.Query(
+		ctx,
+		`
+		select "plan_id", "type", "value" from "plan_resource_request"
+		where "plan_id" = any($1)
+		`,
+		planIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer requests_rows.Close()
+
+	for requests_rows.Next() {
+		var planId, typ string
+		var value ResourceQuantity
+		if err := requests_rows.Scan(&planId, &typ, &value); err != nil {
+			return nil, err
+		}
+		plan := result[planId]
+		plan.ResourceRequests[typ] = resource.Quantity(value)
+		result[planId] = plan
+	}
+
+	toleration_rows, err := conn.Query(
+		ctx,
+		`
+		select "plan_id", "key", "operator", "value", "effect" from "plan_toleration"
+		where "plan_id" = any($1)
+		order by "plan_id", "position"
+		`,
+		planIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer toleration_rows.Close()
+
+	for toleration_rows.Next() {
+		var planId, key, operator, value, effect string
+		if err := toleration_rows.Scan(&planId, &key, &operator, &value, &effect); err != nil {
+			return nil, err
+		}
+		plan := result[planId]
+		plan.Tolerations = append(plan.Tolerations, domain.Toleration{
+			Key:      key,
+			Operator: domain.TolerationOperator(operator),
+			Value:    value,
+			Effect:   domain.TaintEffect(effect),
+		})
+		result[planId] = plan
+	}
+
+	scratch_rows, err := conn.Query(
+		ctx,
+		`
+		select "plan_id", "path", coalesce("size_limit", '0'), "in_memory" from "plan_scratch"
+		where "plan_id" = any($1)
+		order by "plan_id", "path"
+		`,
+		planIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer scratch_rows.Close()
+
+	for scratch_rows.Next() {
+		var planId, path string
+		var sizeLimit ResourceQuantity
+		var inMemory bool
+		if err := scratch_rows.Scan(&planId, &path, &sizeLimit, &inMemory); err != nil {
+			return nil, err
+		}
+		plan := result[planId]
+		plan.Scratches = append(plan.Scratches, domain.ScratchVolume{
+			Path:      path,
+			SizeLimit: resource.Quantity(sizeLimit),
+			InMemory:  inMemory,
+		})
+		result[planId] = plan
+	}
+
+	retry_rows, err := conn.Query(
+		ctx,
+		`
+		select "plan_id", "max_attempts", "backoff_seconds", "retry_on", "exit_codes"
+		from "plan_retry"
+		where "plan_id" = any($1)
+		`,
+		planIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer retry_rows.Close()
+
+	for retry_rows.Next() {
+		var planId string
+		var maxAttempts, backoffSeconds int
+		var on []string
+		var exitCodes []int16
+		if err := retry_rows.Scan(&planId, &maxAttempts, &backoffSeconds, &on, &exitCodes); err != nil {
+			return nil, err
+		}
+		plan := result[planId]
+		plan.Retry = &domain.RetryPolicy{
+			MaxAttempts: maxAttempts,
+			Backoff:     time.Duration(backoffSeconds) * time.Second,
+			On:          slices.Map(on, func(c string) domain.ExitCause { return domain.ExitCause(c) }),
+			ExitCodes:   slices.Map(exitCodes, func(c int16) uint8 { return uint8(c) }),
+		}
+		result[planId] = plan
+	}
+
+	env_rows, err := conn.Query(
+		ctx,
+		`
+		select
+			"plan_id", "name", "value",
+			"secret_name", "secret_key", "config_map_name", "config_map_key"
+		from "plan_env"
+		where "plan_id" = any($1)
+		order by "plan_id", "position"
+		`,
+		planIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer env_rows.Close()
+
+	for env_rows.Next() {
+		var planId, name string
+		var value, secretName, secretKey, configMapName, configMapKey *string
+		if err := env_rows.Scan(
+			&planId, &name, &value,
+			&secretName, &secretKey, &configMapName, &configMapKey,
+		); err != nil {
+			return nil, err
+		}
+		env := domain.EnvVar{Name: name}
+		switch {
+		case secretName != nil && secretKey != nil:
+			env.Secret = &domain.EnvVarKeyRef{Name: *secretName, Key: *secretKey}
+		case configMapName != nil && configMapKey != nil:
+			env.ConfigMap = &domain.EnvVarKeyRef{Name: *configMapName, Key: *configMapKey}
+		case value != nil:
+			env.Value = *value
+		}
+		plan := result[planId]
+		plan.Env = append(plan.Env, env)
+		result[planId] = plan
+	}
+
 	return result, nil
 }
 
@@ -305,12 +489,34 @@ func GetPlan(ctx context.Context, conn kpool.Queryer, planIds []string) (map[str
 
 		}
 
+		selections := map[int]domain.InputSelection{}
+		parameters := map[int][]string{}
+		{
+			inputIds := []int{}
+			for _, mps := range _inputMps {
+				inputIds = append(inputIds, slices.KeysOf(mps)...)
+			}
+			_selections, err := GetInputSelections(ctx, conn, inputIds)
+			if err != nil {
+				return nil, err
+			}
+			selections = _selections
+
+			_parameters, err := GetParameterValues(ctx, conn, inputIds)
+			if err != nil {
+				return nil, err
+			}
+			parameters = _parameters
+		}
+
 		for planId, mps := range _inputMps {
 			inputs[planId] = slices.Map(
 				slices.ValuesOf(mps),
 				func(mp domain.MountPoint) domain.Input {
 					return domain.Input{
 						MountPoint: mp,
+						Selection:  selections[mp.Id],
+						Values:     parameters[mp.Id],
 						Upstreams:  upstreams[mp.Id],
 					}
 				},
@@ -526,6 +732,66 @@ func GetInputs(
 	return bodies, nil
 }
 
+// GetInputSelections returns selection policies of inputs.
+//
+// Inputs selecting all data are not included in the result.
+func GetInputSelections(
+	ctx context.Context, conn kpool.Queryer, inputIds []int,
+) (map[int]domain.InputSelection, error) {
+	rows, err := conn.Query(
+		ctx,
+		`select "input_id", "latest" from "input_selection" where "input_id" = any($1)`,
+		inputIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	selections := map[int]domain.InputSelection{}
+	for rows.Next() {
+		var inputId int
+		sel := domain.InputSelection{}
+		if err := rows.Scan(&inputId, &sel.Latest); err != nil {
+			return nil, err
+		}
+		selections[inputId] = sel
+	}
+	return selections, rows.Err()
+}
+
+// GetParameterValues returns values of parameter inputs.
+//
+// Inputs which are not parameter inputs are not included in the result.
+func GetParameterValues(
+	ctx context.Context, conn kpool.Queryer, inputIds []int,
+) (map[int][]string, error) {
+	rows, err := conn.Query(
+		ctx,
+		`
+		select "input_id", "value" from "parameter_input"
+		where "input_id" = any($1)
+		order by "input_id", "value"
+		`,
+		inputIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := map[int][]string{}
+	for rows.Next() {
+		var inputId int
+		var value string
+		if err := rows.Scan(&inputId, &value); err != nil {
+			return nil, err
+		}
+		values[inputId] = append(values[inputId], value)
+	}
+	return values, rows.Err()
+}
+
 type OutputPoint struct {
 	domain.MountPoint
 	ForLog bool
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+
+	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/utils/combination"
+	"github.com/opst/knitfab/pkg/utils/slices"
+)
+
+// PreviewProjection returns Runs of the Plan which projection would create with the nominations.
+//
+// It yields the same Runs as performing projection repeatedly until no updated nominations remain:
+// all combinations of nominated Data containing at least one updated nomination,
+// except combinations which have been registered as Runs of the Plan already.
+//
+// This function does not modify the database.
+//
+// # Args
+//
+// - context.Context
+//
+// - Queryer
+//
+// - PlanBody: the Plan. PlanId is empty for Plans not registered.
+//
+// - map[int]MountPoint: Inputs of the Plan. input id -> Input.
+//
+// - map[int][]string: nominations of Inputs. input id -> knit ids.
+//
+// - map[int][]string: updated nominations in them. input id -> knit ids.
+func PreviewProjection(
+	ctx context.Context, conn kpool.Queryer,
+	plan domain.PlanBody,
+	inputs map[int]domain.MountPoint,
+	nominations map[int][]string,
+	updated map[int][]string,
+) ([]domain.RunPreview, error) {
+	basis := map[int][]string{}
+	for inputId := range inputs {
+		knitIds := nominations[inputId]
+		if len(knitIds) == 0 {
+			// some input has no data. no runs can be created.
+			return []domain.RunPreview{}, nil
+		}
+		basis[inputId] = knitIds
+	}
+	if len(basis) == 0 {
+		return []domain.RunPreview{}, nil
+	}
+
+	isUpdated := map[int]map[string]struct{}{}
+	for inputId, knitIds := range updated {
+		isUpdated[inputId] = map[string]struct{}{}
+		for _, k := range knitIds {
+			isUpdated[inputId][k] = struct{}{}
+		}
+	}
+
+	patterns := []map[int]string{}
+	for _, pat := range combination.MapCartesian(basis) {
+		hasUpdated := false
+		for inputId, knitId := range pat {
+			if _, ok := isUpdated[inputId][knitId]; ok {
+				hasUpdated = true
+				break
+			}
+		}
+		if !hasUpdated {
+			continue
+		}
+
+		if plan.PlanId != "" {
+			inputIds := make([]int, 0, len(pat))
+			knitIds := make([]string, 0, len(pat))
+			for inputId, knitId := range pat {
+				inputIds = append(inputIds, inputId)
+				knitIds = append(knitIds, knitId)
+			}
+
+			var n int
+			if err := conn.QueryRow(
+				ctx,
+				// same as the check in projection: is there a Run having all of the pattern?
+				`
+				with "assign_pattern" as (
+					select
+						unnest($1::int[]) as "input_id",
+						unnest($2::varchar[]) as "knit_id"
+				),
+				"known" as (
+					select "run_id", count(*) as "overlapped"
+					from "assign"
+					where "plan_id" = $3
+						and ("input_id", "knit_id") in (table "assign_pattern")
+					group by "run_id"
+				)
+				select count(*) from "known"
+				where "overlapped" = (select count(*) from "assign_pattern")
+				`,
+				inputIds, knitIds, plan.PlanId,
+			).Scan(&n); err != nil {
+				return nil, err
+			}
+			if 0 < n {
+				continue
+			}
+		}
+		patterns = append(patterns, pat)
+	}
+
+	if len(patterns) == 0 {
+		return []domain.RunPreview{}, nil
+	}
+
+	knitIds := map[string]struct{}{}
+	for _, pat := range patterns {
+		for _, knitId := range pat {
+			knitIds[knitId] = struct{}{}
+		}
+	}
+	data, err := GetDataBody(ctx, conn, slices.KeysOf(knitIds))
+	if err != nil {
+		return nil, err
+	}
+
+	previews := make([]domain.RunPreview, 0, len(patterns))
+	for _, pat := range patterns {
+		inputIds := slices.Sorted(slices.KeysOf(pat), func(a, b int) bool { return a < b })
+
+		preview := domain.RunPreview{Plan: plan}
+		for _, inputId := range inputIds {
+			preview.Inputs = append(preview.Inputs, domain.Assignment{
+				MountPoint:   inputs[inputId],
+				KnitDataBody: data[pat[inputId]],
+			})
+		}
+		previews = append(previews, preview)
+	}
+
+	return previews, nil
+}
@@ -113,7 +113,7 @@ func GetRunBody(ctx context.Context, conn kpool.Queryer, runIds []string) (map[s
 	rows, err := conn.Query(
 		ctx,
 		`
-		select "run_id", "exit_code", "message" from "run_exit"
+		select "run_id", "exit_code", "message", "cause" from "run_exit"
 		where "run_id" = any($1)
 		`,
 		runIds,
@@ -128,12 +128,81 @@ func GetRunBody(ctx context.Context, conn kpool.Queryer, runIds []string) (map[s
 	for rows.Next() {
 		var runId string
 		exit := domain.RunExit{}
-		if err := rows.Scan(&runId, &exit.Code, &exit.Message); err != nil {
+		if err := rows.Scan(&runId, &exit.Code, &exit.Message, &exit.Cause); err != nil {
 			return nil, err
 		}
 		runExits[runId] = exit
 	}
 
+	attempts := map[string]int{}
+	{
+		rows, err := conn.Query(
+			ctx,
+			`
+			select "run_id", count(*) from "run_attempt"
+			where "run_id" = any($1)
+			group by "run_id"
+			`,
+			runIds,
+		)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var runId string
+			var n int
+			if err := rows.Scan(&runId, &n); err != nil {
+				return nil, err
+			}
+			attempts[runId] = n
+		}
+	}
+
+	holds := map[string]string{}
+	{
+		rows, err := conn.Query(
+			ctx,
+			`
+			select "run_id", "reason" from "run_hold"
+			inner join "run" using ("run_id")
+			where "run_id" = any($1) and "status" = $2
+			`,
+			runIds, domain.Ready,
+		)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var runId, reason string
+			if err := rows.Scan(&runId, &reason); err != nil {
+				return nil, err
+			}
+			holds[runId] = reason
+		}
+	}
+
+	imageIds := map[string]string{}
+	{
+		rows, err := conn.Query(
+			ctx,
+			`select "run_id", "image_id" from "run_image" where "run_id" = any($1)`,
+			runIds,
+		)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var runId, imageId string
+			if err := rows.Scan(&runId, &imageId); err != nil {
+				return nil, err
+			}
+			imageIds[runId] = imageId
+		}
+	}
+
 	result := map[string]domain.RunBody{}
 	for _, rd := range runDescriptors {
 		var exit *domain.RunExit
@@ -144,6 +213,9 @@ func GetRunBody(ctx context.Context, conn kpool.Queryer, runIds []string) (map[s
 			Id:         rd.Id,
 			Status:     domain.KnitRunStatus(rd.Status),
 			Exit:       exit,
+			Attempt:    attempts[rd.Id] + 1,
+			HoldReason: holds[rd.Id],
+			ImageId:    imageIds[rd.Id],
 			WorkerName: rd.WorkerName,
 			UpdatedAt:  rd.UpdatedAt,
 			PlanBody:   planBodies[rd.PlanId],
@@ -259,6 +331,51 @@ func GetRun(ctx context.Context, conn kpool.Queryer, runIds []string) (map[strin
 		dataBodies = b
 	}
 
+	// runId -> attempts, in order
+	attempts := map[string][]domain.RunAttempt{}
+	{
+		rows, err := conn.Query(
+			ctx,
+			`
+			select
+				"run_id", "attempt", "status", "updated_at",
+				"exit_code", "message", "cause"
+			from "run_attempt"
+			where "run_id" = any($1)
+			order by "run_id", "attempt"
+			`,
+			runIds,
+		)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var runId string
+			var status KnitRunStatus
+			var code *int16
+			var message, cause *string
+			a := domain.RunAttempt{}
+			if err := rows.Scan(
+				&runId, &a.Attempt, &status, &a.UpdatedAt,
+				&code, &message, &cause,
+			); err != nil {
+				return nil, err
+			}
+			a.Status = domain.KnitRunStatus(status)
+			if code != nil {
+				a.Exit = &domain.RunExit{Code: uint8(*code)}
+				if message != nil {
+					a.Exit.Message = *message
+				}
+				if cause != nil {
+					a.Exit.Cause = domain.ExitCause(*cause)
+				}
+			}
+			attempts[runId] = append(attempts[runId], a)
+		}
+	}
+
 	// zip-up all!
 	result := map[string]domain.Run{}
 	for runId := range runBodies {
@@ -301,7 +418,8 @@ func GetRun(ctx context.Context, conn kpool.Queryer, runIds []string) (map[strin
 
 		result[rb.Id] = domain.Run{
 			Inputs: in, Outputs: out, Log: log,
-			RunBody: rb,
+			RunBody:  rb,
+			Attempts: attempts[rb.Id],
 		}
 	}
 
@@ -16,6 +16,14 @@ type InputAttr struct {
 	UserTag   []domain.Tag
 	Timestamp []time.Time
 	KnitId    []string
+
+	// Latest is the number of the most recent Data selected for the Input.
+	//
+	// 0 means no InputSelection.
+	Latest int
+
+	// Values are values of the parameter Input, not materialised yet.
+	Values []string
 }
 
 type OutputAttr struct {
@@ -95,20 +103,33 @@ func (step *Step) apply(tbls *Tables) error {
 
 // Declare premise of test.
 type Operation struct {
-	Plan               []Plan
-	PlanEntrypoint     []PlanEntrypoint
-	PlanArgs           []PlanArgs
-	PlanResources      []PlanResource
-	OnNode             []PlanOnNode
-	PlanImage          []PlanImage
-	PlanPseudo         []PlanPseudo
-	Inputs             map[Input]InputAttr
-	Outputs            map[Output]OutputAttr
-	PlanAnnotations    []Annotation
-	PlanServiceAccount []ServiceAccount
+	Plan                 []Plan
+	PlanEntrypoint       []PlanEntrypoint
+	PlanArgs             []PlanArgs
+	PlanResources        []PlanResource
+	PlanResourceRequests []PlanResourceRequest
+	OnNode               []PlanOnNode
+	PlanImage            []PlanImage
+	PlanPseudo           []PlanPseudo
+	PlanPriority         []PlanPriority
+	PlanRetry            []PlanRetry
+	PlanSchedule         []PlanSchedule
+	Inputs               map[Input]InputAttr
+	Outputs              map[Output]OutputAttr
+	PlanAnnotations      []Annotation
+	PlanServiceAccount   []ServiceAccount
 
 	Steps []Step
 
+	// resources recorded for Runs in Steps.
+	RunResources []RunResource
+
+	// finished attempts of Runs in Steps.
+	RunAttempts []RunAttempt
+
+	// Data in Steps materialised from parameter values.
+	ParameterData []ParameterData
+
 	Nomination []Nomination
 	Garbage    []Garbage
 
@@ -135,6 +156,12 @@ func (prem *Operation) Apply(ctx context.Context, pool kpool.Pool) error {
 		}
 	}
 
+	for _, req := range prem.PlanResourceRequests {
+		if err := tbls.InsertPlanResourceRequest(req); err != nil {
+			return err
+		}
+	}
+
 	for _, im := range prem.PlanImage {
 		if err := tbls.InsertPlanImage(&im); err != nil {
 			return err
@@ -159,6 +186,24 @@ func (prem *Operation) Apply(ctx context.Context, pool kpool.Pool) error {
 		}
 	}
 
+	for _, pp := range prem.PlanPriority {
+		if err := tbls.InsertPlanPriority(&pp); err != nil {
+			return err
+		}
+	}
+
+	for _, ps := range prem.PlanSchedule {
+		if err := tbls.InsertPlanSchedule(&ps); err != nil {
+			return err
+		}
+	}
+
+	for _, pr := range prem.PlanRetry {
+		if err := tbls.InsertPlanRetry(&pr); err != nil {
+			return err
+		}
+	}
+
 	for _, on := range prem.OnNode {
 		if err := tbls.InsertPlanOnNode(&on); err != nil {
 			return err
@@ -182,6 +227,16 @@ func (prem *Operation) Apply(ctx context.Context, pool kpool.Pool) error {
 				return err
 			}
 		}
+		if 0 < tags.Latest {
+			if err := tbls.InsertInputSelection(in.InputId, tags.Latest); err != nil {
+				return err
+			}
+		}
+		for _, v := range tags.Values {
+			if err := tbls.InsertParameterInput(in.InputId, v); err != nil {
+				return err
+			}
+		}
 	}
 
 	for out, attr := range prem.Outputs {
@@ -214,6 +269,24 @@ func (prem *Operation) Apply(ctx context.Context, pool kpool.Pool) error {
 		}
 	}
 
+	for _, res := range prem.RunResources {
+		if err := tbls.InsertRunResource(res); err != nil {
+			return err
+		}
+	}
+
+	for _, ra := range prem.RunAttempts {
+		if err := tbls.InsertRunAttempt(&ra); err != nil {
+			return err
+		}
+	}
+
+	for _, pd := range prem.ParameterData {
+		if err := tbls.InsertParameterData(&pd); err != nil {
+			return err
+		}
+	}
+
 	for _, nom := range prem.Nomination {
 		if err := tbls.InsertNomination(&nom); err != nil {
 			return err
@@ -73,6 +73,11 @@ type PlanResource struct {
 	Type   string
 	Value  postgres.ResourceQuantity
 }
+type PlanResourceRequest struct {
+	PlanId string
+	Type   string
+	Value  postgres.ResourceQuantity
+}
 type PlanImage struct {
 	PlanId  string
 	Image   string
@@ -103,6 +108,23 @@ type PlanOnNode struct {
 	Key    string
 	Value  string
 }
+type PlanPriority struct {
+	PlanId         string
+	Priority       int
+	MaxConcurrency *int
+}
+type PlanSchedule struct {
+	PlanId   string
+	Schedule string
+	Next     time.Time
+}
+type PlanRetry struct {
+	PlanId         string
+	MaxAttempts    int
+	BackoffSeconds int
+	RetryOn        []string
+	ExitCodes      []int16
+}
 type PlanPseudo struct {
 	PlanId string
 	Name   string
@@ -157,10 +179,31 @@ func (a *Run) Equiv(b *Run) bool {
 		a.UpdatedAt.Equal(b.UpdatedAt)
 }
 
+type ParameterData struct {
+	KnitId  string
+	Value   string
+	InputId int
+}
+
+type RunResource struct {
+	RunId string
+	Type  string
+	Value postgres.ResourceQuantity
+}
+
 type RunExit struct {
 	RunId    string
 	ExitCode uint8
 	Message  string
+	Cause    string
+}
+
+// finished attempt of a Run, without its exit.
+type RunAttempt struct {
+	RunId     string
+	Attempt   int
+	Status    domain.KnitRunStatus
+	UpdatedAt time.Time
 }
 
 type Worker struct {
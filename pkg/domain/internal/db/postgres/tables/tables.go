@@ -332,6 +332,90 @@ func (f *Tables) InsertPlanResource(res PlanResource) error {
 	return shouldEffect(ctag, 1)
 }
 
+func (f *Tables) InsertPlanResourceRequest(req PlanResourceRequest) error {
+	conn, err := f.acquire()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ctag, err := conn.Exec(
+		f.ctx,
+		`
+		insert into "plan_resource_request" ("plan_id", "type", "value")
+		values ($1, $2, $3);
+		`,
+		req.PlanId, req.Type, req.Value,
+	)
+	if err != nil {
+		return withCause(req, err)
+	}
+	return shouldEffect(ctag, 1)
+}
+
+func (f *Tables) InsertPlanPriority(pp *PlanPriority) error {
+	conn, err := f.acquire()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ctag, err := conn.Exec(
+		f.ctx,
+		`
+		insert into "plan_priority" ("plan_id", "priority", "max_concurrency")
+		values ($1, $2, $3);
+		`,
+		pp.PlanId, pp.Priority, pp.MaxConcurrency,
+	)
+	if err != nil {
+		return withCause(pp, err)
+	}
+	return shouldEffect(ctag, 1)
+}
+
+func (f *Tables) InsertPlanSchedule(ps *PlanSchedule) error {
+	conn, err := f.acquire()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ctag, err := conn.Exec(
+		f.ctx,
+		`
+		insert into "plan_schedule" ("plan_id", "schedule", "next")
+		values ($1, $2, $3);
+		`,
+		ps.PlanId, ps.Schedule, ps.Next,
+	)
+	if err != nil {
+		return withCause(ps, err)
+	}
+	return shouldEffect(ctag, 1)
+}
+
+func (f *Tables) InsertPlanRetry(pr *PlanRetry) error {
+	conn, err := f.acquire()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ctag, err := conn.Exec(
+		f.ctx,
+		`
+		insert into "plan_retry" ("plan_id", "max_attempts", "backoff_seconds", "retry_on", "exit_codes")
+		values ($1, $2, $3, coalesce($4::varchar[], '{}'), coalesce($5::smallint[], '{}'));
+		`,
+		pr.PlanId, pr.MaxAttempts, pr.BackoffSeconds, pr.RetryOn, pr.ExitCodes,
+	)
+	if err != nil {
+		return withCause(pr, err)
+	}
+	return shouldEffect(ctag, 1)
+}
+
 func (f *Tables) InsertPlanOnNode(p *PlanOnNode) error {
 	conn, err := f.acquire()
 	if err != nil {
@@ -639,6 +723,68 @@ func (f *Tables) InsertKnitIdInput(inputId int, knitId string) error {
 	return shouldEffect(ctag, 1)
 }
 
+func (f *Tables) InsertInputSelection(inputId int, latest int) error {
+	conn, err := f.acquire()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ctag, err := conn.Exec(
+		f.ctx,
+		`insert into "input_selection" ("input_id", "latest") values ($1, $2)`,
+		inputId, latest,
+	)
+	if err != nil {
+		return withCause(struct {
+			InputId int
+			Latest  int
+		}{InputId: inputId, Latest: latest}, err)
+	}
+
+	return shouldEffect(ctag, 1)
+}
+
+func (f *Tables) InsertParameterInput(inputId int, value string) error {
+	conn, err := f.acquire()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ctag, err := conn.Exec(
+		f.ctx,
+		`insert into "parameter_input" ("input_id", "value") values ($1, $2)`,
+		inputId, value,
+	)
+	if err != nil {
+		return withCause(struct {
+			InputId int
+			Value   string
+		}{InputId: inputId, Value: value}, err)
+	}
+
+	return shouldEffect(ctag, 1)
+}
+
+func (f *Tables) InsertParameterData(pd *ParameterData) error {
+	conn, err := f.acquire()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ctag, err := conn.Exec(
+		f.ctx,
+		`insert into "parameter_data" ("knit_id", "value", "input_id") values ($1, $2, $3)`,
+		pd.KnitId, pd.Value, pd.InputId,
+	)
+	if err != nil {
+		return withCause(pd, err)
+	}
+	return shouldEffect(ctag, 1)
+}
+
 func (f *Tables) InsertTimestampInput(inputId int, timestamp time.Time) error {
 	conn, err := f.acquire()
 	if err != nil {
@@ -683,7 +829,7 @@ func (f *Tables) InsertRun(r *Run) error {
 	return shouldEffect(ctag, 1)
 }
 
-func (f *Tables) InsertRunExit(re *RunExit) error {
+func (f *Tables) InsertRunResource(res RunResource) error {
 	conn, err := f.acquire()
 	if err != nil {
 		return err
@@ -693,10 +839,31 @@ func (f *Tables) InsertRunExit(re *RunExit) error {
 	ctag, err := conn.Exec(
 		f.ctx,
 		`
-		insert into "run_exit" ("run_id", "exit_code", "message")
+		insert into "run_resource" ("run_id", "type", "value")
 		values ($1, $2, $3);
 		`,
-		re.RunId, re.ExitCode, re.Message,
+		res.RunId, res.Type, res.Value,
+	)
+	if err != nil {
+		return withCause(res, err)
+	}
+	return shouldEffect(ctag, 1)
+}
+
+func (f *Tables) InsertRunExit(re *RunExit) error {
+	conn, err := f.acquire()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ctag, err := conn.Exec(
+		f.ctx,
+		`
+		insert into "run_exit" ("run_id", "exit_code", "message", "cause")
+		values ($1, $2, $3, $4);
+		`,
+		re.RunId, re.ExitCode, re.Message, re.Cause,
 	)
 	if err != nil {
 		return withCause(re, err)
@@ -704,6 +871,27 @@ func (f *Tables) InsertRunExit(re *RunExit) error {
 	return shouldEffect(ctag, 1)
 }
 
+func (f *Tables) InsertRunAttempt(ra *RunAttempt) error {
+	conn, err := f.acquire()
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	ctag, err := conn.Exec(
+		f.ctx,
+		`
+		insert into "run_attempt" ("run_id", "attempt", "status", "updated_at")
+		values ($1, $2, $3, $4);
+		`,
+		ra.RunId, ra.Attempt, ra.Status, ra.UpdatedAt,
+	)
+	if err != nil {
+		return withCause(ra, err)
+	}
+	return shouldEffect(ctag, 1)
+}
+
 func (f *Tables) InsertAssign(assign *Assign) error {
 	conn, err := f.acquire()
 	if err != nil {
@@ -1,12 +1,15 @@
 package db
 
 import (
+	kaudit "github.com/opst/knitfab/pkg/domain/audit/db"
 	kdata "github.com/opst/knitfab/pkg/domain/data/db"
 	kgarbage "github.com/opst/knitfab/pkg/domain/garbage/db"
 	kkeychain "github.com/opst/knitfab/pkg/domain/keychain/db"
 	kplan "github.com/opst/knitfab/pkg/domain/plan/db"
 	krun "github.com/opst/knitfab/pkg/domain/run/db"
 	kschema "github.com/opst/knitfab/pkg/domain/schema/db"
+	kuser "github.com/opst/knitfab/pkg/domain/user/db"
+	kwebhook "github.com/opst/knitfab/pkg/domain/webhook/db"
 )
 
 type KnitDatabase interface {
@@ -16,5 +19,8 @@ type KnitDatabase interface {
 	Garbage() kgarbage.Interface
 	Schema() kschema.SchemaInterface
 	Keychain() kkeychain.KeychainInterface
+	User() kuser.UserInterface
+	Audit() kaudit.AuditInterface
+	Webhook() kwebhook.WebhookInterface
 	Close() error
 }
@@ -5,6 +5,8 @@ import (
 
 	"github.com/jackc/pgx/v4/pgxpool"
 	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
+	kaudit "github.com/opst/knitfab/pkg/domain/audit/db"
+	kpgaudit "github.com/opst/knitfab/pkg/domain/audit/db/postgres"
 	kdata "github.com/opst/knitfab/pkg/domain/data/db"
 	kpgdata "github.com/opst/knitfab/pkg/domain/data/db/postgres"
 	kgarbage "github.com/opst/knitfab/pkg/domain/garbage/db"
@@ -19,6 +21,10 @@ import (
 	kpgrun "github.com/opst/knitfab/pkg/domain/run/db/postgres"
 	kschema "github.com/opst/knitfab/pkg/domain/schema/db"
 	kpgschema "github.com/opst/knitfab/pkg/domain/schema/db/postgres"
+	kuser "github.com/opst/knitfab/pkg/domain/user/db"
+	kpguser "github.com/opst/knitfab/pkg/domain/user/db/postgres"
+	kwebhook "github.com/opst/knitfab/pkg/domain/webhook/db"
+	kpgwebhook "github.com/opst/knitfab/pkg/domain/webhook/db/postgres"
 	xe "github.com/opst/knitfab/pkg/errors"
 )
 
@@ -30,6 +36,9 @@ type knitDBPostgres struct {
 	garbage  kgarbage.Interface
 	keychain kkeychain.KeychainInterface
 	schema   kschema.SchemaInterface
+	user     kuser.UserInterface
+	audit    kaudit.AuditInterface
+	webhook  kwebhook.WebhookInterface
 }
 
 type Config struct {
@@ -88,6 +97,9 @@ func New(
 		schema:   schema,
 		garbage:  kpggbg.New(p),
 		keychain: kpgkeychain.New(p),
+		user:     kpguser.New(p),
+		audit:    kpgaudit.New(p),
+		webhook:  kpgwebhook.New(p),
 	}, nil
 }
 
@@ -115,6 +127,18 @@ func (k *knitDBPostgres) Keychain() kkeychain.KeychainInterface {
 	return k.keychain
 }
 
+func (k *knitDBPostgres) User() kuser.UserInterface {
+	return k.user
+}
+
+func (k *knitDBPostgres) Audit() kaudit.AuditInterface {
+	return k.audit
+}
+
+func (k *knitDBPostgres) Webhook() kwebhook.WebhookInterface {
+	return k.webhook
+}
+
 func (k *knitDBPostgres) Close() error {
 	k.pool.Close()
 	return nil
@@ -332,12 +332,28 @@ const (
 	//
 	// In case of parallel, some pods can be succeeded.
 	Failed JobStatusType = "Failed"
+
+	// the job is failed because it has been active longer than its active deadline.
+	DeadlineExceeded JobStatusType = "DeadlineExceeded"
 )
 
+// ExitCodeDeadlineExceeded is the exit code of jobs killed by their active deadline (DeadlineExceeded).
+//
+// Kubernetes kills such jobs without their exit codes, so this code is reported instead.
+// It is out of exit codes of processes killed by signals (128 + signal number, up to 128 + 64),
+// so Runs timed out can be distinguished from Runs failed by themselves.
+const ExitCodeDeadlineExceeded uint8 = 252
+
 type JobStatus struct {
 	Type    JobStatusType
 	Code    uint8
 	Message string
+
+	// ImageIDs are image IDs of containers which have been started, by container name.
+	//
+	// They are reported by the container runtime, like "repo@sha256:...",
+	// and identify the image which is actually run even if it is referred by tag.
+	ImageIDs map[string]string
 }
 
 // abstraction of k8s job.
@@ -358,6 +374,8 @@ type Job interface {
 	// - Succeeded, Failed : it is succeeded or failed as a job.
 	// In case of parallel jobs, some pods can be failed/succeeded inspite of the Status().
 	//
+	// - DeadlineExceeded : it is failed as a job, because it has been active longer than its deadline.
+	//
 	// - Running : (At least) one pod has been started.
 	// It can be no pods are running if some pods have run to be terminated
 	// and more pods are pending to be started.
@@ -405,6 +423,30 @@ func (j *job) Namespace() string {
 }
 
 func (j *job) Status(ctx context.Context) JobStatus {
+	s := j.status(ctx)
+	s.ImageIDs = j.imageIDs()
+	return s
+}
+
+// imageIDs returns image IDs of containers in pods of the job, by container name.
+func (j *job) imageIDs() map[string]string {
+	ids := map[string]string{}
+	for _, p := range j.pods {
+		for _, statuses := range [][]kubecore.ContainerStatus{
+			p.Status.InitContainerStatuses, p.Status.ContainerStatuses,
+		} {
+			for _, c := range statuses {
+				if c.ImageID == "" {
+					continue
+				}
+				ids[c.Name] = c.ImageID
+			}
+		}
+	}
+	return ids
+}
+
+func (j *job) status(ctx context.Context) JobStatus {
 	// If the job is completed or failed, return the status.
 	// If some pods are scheduled,
 	// 	If at least one pod is Pending AND has Warning events
@@ -422,6 +464,13 @@ func (j *job) Status(ctx context.Context) JobStatus {
 		case kubebatch.JobComplete:
 			return JobStatus{Type: Succeeded}
 		case kubebatch.JobFailed:
+			if sc.Reason == kubebatch.JobReasonDeadlineExceeded {
+				return JobStatus{
+					Type:    DeadlineExceeded,
+					Code:    ExitCodeDeadlineExceeded,
+					Message: fmt.Sprintf("[%s] %s", sc.Reason, sc.Message),
+				}
+			}
 			var code uint8
 			message := ""
 			for _, p := range j.pods {
@@ -1814,7 +1814,10 @@ func TestK8sCluster_GetJob_with_mock(t *testing.T) {
 				t.Errorf("namespace: not match: (got, want) = (%s, %s)", gotNamespace, then.Namespace)
 			}
 
-			if gotStatus := got.Value.Status(ctx); gotStatus != then.Status {
+			if gotStatus := got.Value.Status(ctx); gotStatus.Type != then.Status.Type ||
+				gotStatus.Code != then.Status.Code ||
+				gotStatus.Message != then.Status.Message ||
+				!cmp.MapEq(gotStatus.ImageIDs, then.Status.ImageIDs) {
 				t.Errorf("status: not match: (got, want) = (%+v, %+v)", gotStatus, then.Status)
 			}
 
@@ -1941,7 +1944,8 @@ this is succeeded pod`,
 						Phase: kubecore.PodFailed,
 						ContainerStatuses: []kubecore.ContainerStatus{
 							{
-								Name: "main",
+								Name:    "main",
+								ImageID: "repo.invalid/image@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 								State: kubecore.ContainerState{
 									Terminated: &kubecore.ContainerStateTerminated{ExitCode: 1, Reason: "Crashed"},
 								},
@@ -1955,9 +1959,75 @@ this is failed pod
 `,
 		},
 		Then{
-			Name:             "fake-job",
-			Namespace:        namespace,
-			Status:           cluster.JobStatus{Type: cluster.Failed, Code: 1, Message: "(container main) Crashed"},
+			Name:      "fake-job",
+			Namespace: namespace,
+			Status: cluster.JobStatus{
+				Type: cluster.Failed, Code: 1, Message: "(container main) Crashed",
+				ImageIDs: map[string]string{
+					"main": "repo.invalid/image@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				},
+			},
+			LogSourcePodName: "fake-job-pod-1",
+		},
+	))
+
+	t.Run("failed job by deadline", theory(
+		When{
+			Job: &kubebatch.Job{
+				ObjectMeta: kubeapimeta.ObjectMeta{
+					Name:      "fake-job",
+					Namespace: namespace,
+				},
+				Spec: kubebatch.JobSpec{
+					Selector: &kubeapimeta.LabelSelector{
+						MatchLabels: map[string]string{
+							"controller":   "fake-job",
+							"custom-label": "condition",
+						},
+					},
+				},
+				Status: kubebatch.JobStatus{
+					Conditions: []kubebatch.JobCondition{
+						{
+							Status:  "True",
+							Type:    kubebatch.JobFailed,
+							Reason:  kubebatch.JobReasonDeadlineExceeded,
+							Message: "Job was active longer than specified deadline",
+						},
+					},
+				},
+			},
+			Pods: []kubecore.Pod{
+				{
+					ObjectMeta: kubeapimeta.ObjectMeta{
+						Name:      "fake-job-pod-1",
+						Namespace: namespace,
+					},
+					Status: kubecore.PodStatus{
+						Phase: kubecore.PodFailed,
+						ContainerStatuses: []kubecore.ContainerStatus{
+							{
+								Name: "main",
+								State: kubecore.ContainerState{
+									Terminated: &kubecore.ContainerStateTerminated{ExitCode: 137, Reason: "Error"},
+								},
+							},
+						},
+					},
+				},
+			},
+			Log: `hello world
+this is a pod running too long
+`,
+		},
+		Then{
+			Name:      "fake-job",
+			Namespace: namespace,
+			Status: cluster.JobStatus{
+				Type:    cluster.DeadlineExceeded,
+				Code:    cluster.ExitCodeDeadlineExceeded,
+				Message: "[DeadlineExceeded] Job was active longer than specified deadline",
+			},
 			LogSourcePodName: "fake-job-pod-1",
 		},
 	))
@@ -5,6 +5,7 @@ import (
 
 	bconf "github.com/opst/knitfab/pkg/configs/backend"
 	connk8s "github.com/opst/knitfab/pkg/conn/k8s"
+	"github.com/opst/knitfab/pkg/domain/audit"
 	"github.com/opst/knitfab/pkg/domain/data"
 	"github.com/opst/knitfab/pkg/domain/garbage"
 	"github.com/opst/knitfab/pkg/domain/keychain"
@@ -14,6 +15,8 @@ import (
 	"github.com/opst/knitfab/pkg/domain/plan"
 	"github.com/opst/knitfab/pkg/domain/run"
 	"github.com/opst/knitfab/pkg/domain/schema"
+	"github.com/opst/knitfab/pkg/domain/user"
+	"github.com/opst/knitfab/pkg/domain/webhook"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -27,6 +30,9 @@ type Knitfab interface {
 	Garbage() garbage.Interface
 	Schema() schema.Interface
 	Keychain() keychain.Interface
+	User() user.Interface
+	Audit() audit.Interface
+	Webhook() webhook.Interface
 }
 
 type knitfab struct {
@@ -40,6 +46,9 @@ type knitfab struct {
 	garbage  garbage.Interface
 	schema   schema.Interface
 	keychain keychain.Interface
+	user     user.Interface
+	audit    audit.Interface
+	webhook  webhook.Interface
 }
 
 func Default(
@@ -83,6 +92,9 @@ func New(
 		garbage:  garbage.New(pg.Garbage(), k8sifs.Garbage()),
 		schema:   schema.New(pg.Schema()),
 		keychain: keychain.New(pg.Keychain(), k8sifs.KeyChain()),
+		user:     user.New(pg.User()),
+		audit:    audit.New(pg.Audit()),
+		webhook:  webhook.New(pg.Webhook()),
 	}, nil
 }
 
@@ -132,3 +144,15 @@ func (k *knitfab) Schema() schema.Interface {
 func (k *knitfab) Keychain() keychain.Interface {
 	return k.keychain
 }
+
+func (k *knitfab) User() user.Interface {
+	return k.user
+}
+
+func (k *knitfab) Audit() audit.Interface {
+	return k.audit
+}
+
+func (k *knitfab) Webhook() webhook.Interface {
+	return k.webhook
+}
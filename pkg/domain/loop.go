@@ -14,6 +14,10 @@ const (
 	Finishing         LoopType = "finishing"
 	GarbageCollection LoopType = "garbage_collection"
 	Housekeeping      LoopType = "housekeeping"
+	Scheduling        LoopType = "scheduling"
+	Webhook           LoopType = "webhook"
+	Retention         LoopType = "retention"
+	Measure           LoopType = "measure"
 )
 
 // NOTE: we define them here, because...
@@ -29,7 +33,7 @@ func (lt LoopType) String() string {
 
 func (lt LoopType) IsKnown() bool {
 	switch lt {
-	case Projection, Initialize, RunManagement, Finishing, GarbageCollection, Housekeeping:
+	case Projection, Initialize, RunManagement, Finishing, GarbageCollection, Housekeeping, Scheduling, Webhook, Retention, Measure:
 		return true
 	default:
 		return false
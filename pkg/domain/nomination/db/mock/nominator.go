@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
+	"github.com/opst/knitfab/pkg/domain"
 	kpgnom "github.com/opst/knitfab/pkg/domain/nomination/db/postgres"
 )
 
@@ -24,11 +25,15 @@ type MockNominator struct {
 		NominateData        func(ctx context.Context, conn kpool.Tx, knitIds []string) error
 		NominateMountpoints func(ctx context.Context, conn kpool.Tx, mountpointIds []int) error
 		DropData            func(ctx context.Context, conn kpool.Tx, knitIds []string) error
+		Match               func(ctx context.Context, conn kpool.Queryer, cond kpgnom.Condition, override *kpgnom.TagsOverride) ([]string, error)
+		MatchingInputs      func(ctx context.Context, conn kpool.Queryer, knitId string, userTags []domain.Tag) ([]int, error)
 	}
 	Calls struct {
 		NominateData        Call[[]string]
 		NominateMountpoints Call[[]int]
 		DropData            Call[[]string]
+		Match               Call[kpgnom.Condition]
+		MatchingInputs      Call[string]
 	}
 }
 
@@ -88,3 +93,35 @@ func (n *MockNominator) DropData(ctx context.Context, conn kpool.Tx, knitIds []s
 	n.panic("should not be called")
 	return nil
 }
+
+func (n *MockNominator) Match(
+	ctx context.Context, conn kpool.Queryer, cond kpgnom.Condition, override *kpgnom.TagsOverride,
+) ([]string, error) {
+	if n.t != nil {
+		n.t.Helper()
+	}
+
+	n.Calls.Match = append(n.Calls.Match, cond)
+	if n.Impl.Match != nil {
+		return n.Impl.Match(ctx, conn, cond, override)
+	}
+
+	n.panic("should not be called")
+	return nil, nil
+}
+
+func (n *MockNominator) MatchingInputs(
+	ctx context.Context, conn kpool.Queryer, knitId string, userTags []domain.Tag,
+) ([]int, error) {
+	if n.t != nil {
+		n.t.Helper()
+	}
+
+	n.Calls.MatchingInputs = append(n.Calls.MatchingInputs, knitId)
+	if n.Impl.MatchingInputs != nil {
+		return n.Impl.MatchingInputs(ctx, conn, knitId, userTags)
+	}
+
+	n.panic("should not be called")
+	return nil, nil
+}
@@ -2,14 +2,26 @@ package nominator
 
 import (
 	"context"
+	"time"
 
+	"github.com/opst/knitfab-api-types/misc/rfctime"
 	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
 	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/utils/pointer"
 )
 
 type Nominator interface {
 	// nominate with data.
 	//
+	// Inputs of Plans superseded with reroute are not nominated.
+	//
+	// Data materialised from parameter values are nominated only to their parameter Inputs,
+	// and parameter Inputs are nominated only with them.
+	//
+	// For Inputs with InputSelection, only the most recent Data are kept nominated.
+	// When Data lose nominations of such Inputs (e.g. their tags are removed),
+	// the Inputs are nominated again, so that the next recent Data are selected.
+	//
 	// args:
 	//    - context.Context
 	//    - pgxQueryer: (transactional )connection operating data or data tag.
@@ -17,6 +29,12 @@ type Nominator interface {
 	NominateData(context.Context, kpool.Tx, []string) error
 	// nominate with Mountpoints.
 	//
+	// Inputs of Plans superseded with reroute are not nominated.
+	//
+	// Parameter Inputs are nominated only with Data materialised from their values.
+	//
+	// For Inputs with InputSelection, only the most recent Data are kept nominated.
+	//
 	// args:
 	//    - context.Context
 	//    - pgxQueryer: (transactional )connection operating data or data tag.
@@ -24,11 +42,85 @@ type Nominator interface {
 	NominateMountpoints(context.Context, kpool.Tx, []int) error
 	// drop nomination for specified data.
 	//
+	// Inputs with InputSelection which have lost nominations are nominated again,
+	// so that the next recent Data are selected.
+	//
 	// args:
 	//    - context.Context
 	//    - pgxQueryer: (transactional )connection operating data.
 	//    - []string: knitIds
 	DropData(context.Context, kpool.Tx, []string) error
+
+	// find Data to be nominated to an Input with the condition.
+	//
+	// It only reads the database. Inputs and Data do not need to be registered,
+	// so that the result can be used to preview changes.
+	//
+	// Data materialised from parameter values are not found.
+	//
+	// args:
+	//    - context.Context
+	//    - pgxQueryer: connection
+	//    - Condition: condition of the Input
+	//    - *TagsOverride: if not nil, user tags of the Data are replaced with it.
+	// return:
+	//    - []string: knit ids of Data, selected by the condition.
+	Match(context.Context, kpool.Queryer, Condition, *TagsOverride) ([]string, error)
+
+	// find Inputs which the Data would be nominated to, if it had the user tags.
+	//
+	// It only reads the database.
+	//
+	// Inputs of Plans superseded with reroute and parameter Inputs are not found.
+	// InputSelection is not considered.
+	//
+	// args:
+	//    - context.Context
+	//    - pgxQueryer: connection
+	//    - string: knit id of the Data
+	//    - []Tag: user tags of the Data
+	// return:
+	//    - []int: input ids
+	MatchingInputs(context.Context, kpool.Queryer, string, []domain.Tag) ([]int, error)
+}
+
+// Condition is a condition of Data to be nominated to an Input.
+type Condition struct {
+	// UserTags are tags which Data should have all of.
+	UserTags []domain.Tag
+
+	// KnitId is the knit#id which Data should have. Empty means any.
+	KnitId string
+
+	// Timestamp is the knit#timestamp which Data should have. nil means any.
+	Timestamp *time.Time
+
+	// Selection is the policy to select Data.
+	Selection domain.InputSelection
+}
+
+// ConditionOf returns the Condition of an Input with tags and selection.
+func ConditionOf(tags *domain.TagSet, selection domain.InputSelection) (Condition, error) {
+	cond := Condition{UserTags: tags.UserTag(), Selection: selection}
+	for _, t := range tags.SystemTag() {
+		switch t.Key {
+		case domain.KeyKnitId:
+			cond.KnitId = t.Value
+		case domain.KeyKnitTimestamp:
+			ts, err := rfctime.ParseRFC3339DateTime(t.Value)
+			if err != nil {
+				return Condition{}, domain.NewErrBadFormatKnitTimestamp(t.Value)
+			}
+			cond.Timestamp = pointer.Ref(ts.Time())
+		}
+	}
+	return cond, nil
+}
+
+// TagsOverride replaces user tags of the Data, as if they were changed.
+type TagsOverride struct {
+	KnitId   string
+	UserTags []domain.Tag
 }
 
 type nominator struct {
@@ -49,6 +141,25 @@ func (n *nominator) NominateData(ctx context.Context, conn kpool.Tx, knitIds []s
 		return err
 	}
 
+	// nominations for Inputs with InputSelection may be lost by changing tags.
+	// They should be filled with older Data, like DropData.
+	var selectingInputs []int
+	var selectedData []string
+	if err := conn.QueryRow(
+		ctx,
+		`
+		select
+			coalesce(array_agg("input_id"), '{}'),
+			coalesce(array_agg("knit_id"), '{}')
+		from "nomination"
+		inner join "input_selection" using ("input_id")
+		where "knit_id" = ANY($1::varchar[])
+		`,
+		knitIds,
+	).Scan(&selectingInputs, &selectedData); err != nil {
+		return err
+	}
+
 	if _, err := conn.Exec(
 		ctx,
 		`
@@ -122,10 +233,25 @@ func (n *nominator) NominateData(ctx context.Context, conn kpool.Tx, knitIds []s
 			) "d" on ("tag_knit_id" is null or "tag_knit_id" = "knit_id")
 				 and ("tag_timestamp" is null or "tag_timestamp" = "timestamp")
 		),
+		"rerouted_input" as (
+			select "input_id" from "input"
+			inner join "plan_supersede" on "plan_supersede"."supersedes" = "input"."plan_id"
+			where "plan_supersede"."reroute"
+		),
 		"match" as (
-			select "input_id", "knit_id" from "match_with_usertag"
+			select "input_id", "knit_id" from (
+				select "input_id", "knit_id" from "match_with_usertag"
+				union
+				select "input_id", "knit_id" from "match_only_systemtag"
+			) as "m"
+			where "input_id" not in (table "rerouted_input")
+				-- parameter inputs and their data are bound, regardless of tags.
+				and "input_id" not in (select "input_id" from "parameter_input")
+				and "knit_id" not in (select "knit_id" from "parameter_data")
 			union
-			select "input_id", "knit_id" from "match_only_systemtag"
+			select "input_id", "knit_id" from "parameter_data"
+			inner join "data" using("knit_id")
+			where "input_id" not in (table "rerouted_input")
 		),
 		"remove_unmatch" as (
 			delete from "nomination"
@@ -142,7 +268,31 @@ func (n *nominator) NominateData(ctx context.Context, conn kpool.Tx, knitIds []s
 	); err != nil {
 		return err
 	}
-	return nil
+	if err := n.dropUnselected(ctx, conn); err != nil {
+		return err
+	}
+
+	if len(selectingInputs) == 0 {
+		return nil
+	}
+	var lostInputs []int
+	if err := conn.QueryRow(
+		ctx,
+		`
+		select coalesce(array_agg(distinct "input_id"), '{}')
+		from unnest($1::int[], $2::varchar[]) as "s" ("input_id", "knit_id")
+		where ("input_id", "knit_id") not in (
+			select "input_id", "knit_id" from "nomination"
+		)
+		`,
+		selectingInputs, selectedData,
+	).Scan(&lostInputs); err != nil {
+		return err
+	}
+	if len(lostInputs) == 0 {
+		return nil
+	}
+	return n.NominateMountpoints(ctx, conn, lostInputs)
 }
 
 func (n *nominator) NominateMountpoints(ctx context.Context, conn kpool.Tx, inputIds []int) error {
@@ -230,10 +380,26 @@ func (n *nominator) NominateMountpoints(ctx context.Context, conn kpool.Tx, inpu
 				 and ("tag_timestamp" is null or "tag_timestamp" = "timestamp")
 		),
 
+		"rerouted_input" as (
+			select "input_id" from "input"
+			inner join "plan_supersede" on "plan_supersede"."supersedes" = "input"."plan_id"
+			where "plan_supersede"."reroute"
+		),
 		"match" as (
-			select "input_id", "knit_id" from "match_with_usertag"
+			select "input_id", "knit_id" from (
+				select "input_id", "knit_id" from "match_with_usertag"
+				union
+				select "input_id", "knit_id" from "match_only_systemtag"
+			) as "m"
+			where "input_id" not in (table "rerouted_input")
+				-- parameter inputs and their data are bound, regardless of tags.
+				and "input_id" not in (select "input_id" from "parameter_input")
+				and "knit_id" not in (select "knit_id" from "parameter_data")
 			union
-			select "input_id", "knit_id" from "match_only_systemtag"
+			select "input_id", "knit_id" from "parameter_data"
+			inner join "data" using("knit_id")
+			where "input_id" = any($2::int[])
+				and "input_id" not in (table "rerouted_input")
 		),
 		"remove_unmatch" as (
 			delete from "nomination"
@@ -252,7 +418,7 @@ func (n *nominator) NominateMountpoints(ctx context.Context, conn kpool.Tx, inpu
 		return err
 	}
 
-	return nil
+	return n.dropUnselected(ctx, conn)
 }
 
 func (n *nominator) DropData(ctx context.Context, conn kpool.Tx, knitIds []string) error {
@@ -261,11 +427,209 @@ func (n *nominator) DropData(ctx context.Context, conn kpool.Tx, knitIds []strin
 		return err
 	}
 
+	var selectingInputs []int
+	if err := conn.QueryRow(
+		ctx,
+		`
+		select coalesce(array_agg(distinct "input_id"), '{}')
+		from "nomination"
+		inner join "input_selection" using ("input_id")
+		where "knit_id" = ANY($1::varchar[])
+		`,
+		knitIds,
+	).Scan(&selectingInputs); err != nil {
+		return err
+	}
+
 	if _, err := conn.Exec(
 		ctx, `delete from "nomination" where "knit_id" = ANY($1::varchar[])`, knitIds,
 	); err != nil {
 		return err
 	}
 
-	return nil
+	if len(selectingInputs) == 0 {
+		return nil
+	}
+	return n.NominateMountpoints(ctx, conn, selectingInputs)
+}
+
+// dropUnselected drops nominations not selected by InputSelection.
+//
+// For each Input with InputSelection, nominations other than
+// the most recent N Data (ordered by knit#timestamp) are dropped.
+func (n *nominator) dropUnselected(ctx context.Context, conn kpool.Tx) error {
+	_, err := conn.Exec(
+		ctx,
+		`
+		with "ranked" as (
+			select
+				"input_id", "knit_id", "latest",
+				row_number() over (
+					partition by "input_id"
+					order by "timestamp" desc nulls last, "knit_id" desc
+				) as "rank"
+			from "nomination"
+			inner join "input_selection" using ("input_id")
+			left join "knit_timestamp" using ("knit_id")
+		)
+		delete from "nomination"
+		where ("input_id", "knit_id") in (
+			select "input_id", "knit_id" from "ranked" where "latest" < "rank"
+		)
+		`,
+	)
+	return err
+}
+
+func (n *nominator) Match(ctx context.Context, conn kpool.Queryer, cond Condition, override *TagsOverride) ([]string, error) {
+	if len(cond.UserTags) == 0 && cond.KnitId == "" && cond.Timestamp == nil {
+		// Inputs without tags are not nominated.
+		return []string{}, nil
+	}
+
+	var overriding *string
+	overrideKeys, overrideValues := []string{}, []string{}
+	if override != nil {
+		overriding = &override.KnitId
+		for _, t := range override.UserTags {
+			overrideKeys = append(overrideKeys, t.Key)
+			overrideValues = append(overrideValues, t.Value)
+		}
+	}
+	wantKeys, wantValues := []string{}, []string{}
+	for _, t := range cond.UserTags {
+		wantKeys = append(wantKeys, t.Key)
+		wantValues = append(wantValues, t.Value)
+	}
+	var knitId *string
+	if cond.KnitId != "" {
+		knitId = &cond.KnitId
+	}
+	var latest *int
+	if !cond.Selection.IsAll() {
+		latest = &cond.Selection.Latest
+	}
+
+	rows, err := conn.Query(
+		ctx,
+		`
+		with
+		"want" as (
+			select unnest($1::varchar[]) as "key", unnest($2::varchar[]) as "value"
+		),
+		"candidate" as (
+			select "knit_id", "timestamp" from "data"
+			inner join "run" using ("run_id")
+			left join "knit_timestamp" using ("knit_id")
+			where "status" = $3
+				and "knit_id" not in (select "knit_id" from "parameter_data")
+				and ($4::varchar is null or "knit_id" = $4)
+				and ($5::timestamp with time zone is null or "timestamp" = $5)
+		),
+		"d_tags" as (
+			select "knit_id", "tag_key"."key", "tag"."value" from "tag_data"
+			inner join "tag" on "tag"."id" = "tag_data"."tag_id"
+			inner join "tag_key" on "tag_key"."id" = "tag"."key_id"
+			where "knit_id" in (select "knit_id" from "candidate")
+				and "knit_id" is distinct from $6::varchar
+			union all
+			select $6::varchar, unnest($7::varchar[]), unnest($8::varchar[])
+			where $6::varchar is not null
+		)
+		select "knit_id" from "candidate" as "c"
+		where not exists (
+			select 1 from "want"
+			where ("key", "value") not in (
+				select "key", "value" from "d_tags" where "d_tags"."knit_id" = "c"."knit_id"
+			)
+		)
+		order by "timestamp" desc nulls last, "knit_id" desc
+		limit $9::int
+		`,
+		wantKeys, wantValues, domain.Done, knitId, cond.Timestamp,
+		overriding, overrideKeys, overrideValues, latest,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	knitIds := []string{}
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, err
+		}
+		knitIds = append(knitIds, k)
+	}
+	return knitIds, rows.Err()
+}
+
+func (n *nominator) MatchingInputs(ctx context.Context, conn kpool.Queryer, knitId string, userTags []domain.Tag) ([]int, error) {
+	keys, values := []string{}, []string{}
+	for _, t := range userTags {
+		keys = append(keys, t.Key)
+		values = append(values, t.Value)
+	}
+
+	rows, err := conn.Query(
+		ctx,
+		`
+		with
+		"d_tags" as (
+			select unnest($2::varchar[]) as "key", unnest($3::varchar[]) as "value"
+		),
+		"d" as (
+			select "knit_id", "timestamp" from "data"
+			inner join "run" using ("run_id")
+			left join "knit_timestamp" using ("knit_id")
+			where "knit_id" = $1 and "status" = $4
+				and "knit_id" not in (select "knit_id" from "parameter_data")
+		),
+		"i_tags" as (
+			select "input_id", "tag_key"."key", "tag"."value" from "tag_input"
+			inner join "tag" on "tag"."id" = "tag_input"."tag_id"
+			inner join "tag_key" on "tag_key"."id" = "tag"."key_id"
+		),
+		"rerouted_input" as (
+			select "input_id" from "input"
+			inner join "plan_supersede" on "plan_supersede"."supersedes" = "input"."plan_id"
+			where "plan_supersede"."reroute"
+		)
+		select "i"."input_id" from "input" as "i"
+		cross join "d"
+		left join "knitid_input" as "ki" on "ki"."input_id" = "i"."input_id"
+		left join "timestamp_input" as "ti" on "ti"."input_id" = "i"."input_id"
+		where
+			("ki"."knit_id" is null or "ki"."knit_id" = "d"."knit_id")
+			and ("ti"."timestamp" is null or "ti"."timestamp" = "d"."timestamp")
+			-- Inputs without tags are not nominated.
+			and (
+				"ki"."knit_id" is not null or "ti"."timestamp" is not null
+				or "i"."input_id" in (select "input_id" from "i_tags")
+			)
+			and not exists (
+				select 1 from "i_tags"
+				where "i_tags"."input_id" = "i"."input_id"
+					and ("key", "value") not in (table "d_tags")
+			)
+			and "i"."input_id" not in (table "rerouted_input")
+			and "i"."input_id" not in (select "input_id" from "parameter_input")
+		`,
+		knitId, keys, values, domain.Done,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inputIds := []int{}
+	for rows.Next() {
+		var i int
+		if err := rows.Scan(&i); err != nil {
+			return nil, err
+		}
+		inputIds = append(inputIds, i)
+	}
+	return inputIds, rows.Err()
 }
@@ -2040,3 +2040,375 @@ func TestDropData(t *testing.T) {
 		})
 	}
 }
+
+func TestNominator_InputSelection(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	DAY_1 := time.Date(2022, time.August, 1, 12, 13, 24, 0, time.UTC)
+
+	TAG := domain.Tag{Key: "tagkey", Value: "tagval"}
+
+	// uploaded Data with TAG, in order of knit#timestamp.
+	uploaded := func(knitIds ...string) []tables.Step {
+		steps := []tables.Step{}
+		for nth, knitId := range knitIds {
+			timestamp := DAY_1.Add(time.Duration(nth) * 24 * time.Hour)
+			runId := Padding36("run/" + knitId)
+			steps = append(steps, tables.Step{
+				Run: tables.Run{
+					RunId: runId, PlanId: Padding36("pseudo"), Status: domain.Done, UpdatedAt: timestamp,
+				},
+				Outcomes: map[tables.Data]tables.DataAttibutes{
+					{
+						KnitId: Padding36(knitId), VolumeRef: "vol/" + knitId,
+						OutputId: 1, RunId: runId, PlanId: Padding36("pseudo"),
+					}: {
+						UserTag:   []domain.Tag{TAG},
+						Timestamp: &timestamp,
+					},
+				},
+			})
+		}
+		return steps
+	}
+
+	// Input 100 selects the latest Data with TAG, and Input 101 uses all of them.
+	given := func(steps []tables.Step, nominations []tables.Nomination) tables.Operation {
+		return tables.Operation{
+			Plan: []tables.Plan{
+				{PlanId: Padding36("pseudo"), Active: true, Hash: "hash"},
+				{PlanId: Padding36("plan-1"), Active: true, Hash: "hash"},
+			},
+			PlanPseudo: []tables.PlanPseudo{
+				{PlanId: Padding36("pseudo"), Name: "upload"},
+			},
+			PlanImage: []tables.PlanImage{
+				{PlanId: Padding36("plan-1"), Image: "repo.invalid/image", Version: "v0.1"},
+			},
+			Outputs: map[tables.Output]tables.OutputAttr{
+				{OutputId: 1, PlanId: Padding36("pseudo"), Path: "/out"}: {},
+			},
+			Inputs: map[tables.Input]tables.InputAttr{
+				{InputId: 100, PlanId: Padding36("plan-1"), Path: "/in/latest"}: {
+					UserTag: []domain.Tag{TAG},
+					Latest:  1,
+				},
+				{InputId: 101, PlanId: Padding36("plan-1"), Path: "/in/all"}: {
+					UserTag: []domain.Tag{TAG},
+				},
+			},
+			Steps:      steps,
+			Nomination: nominations,
+		}
+	}
+
+	type When struct {
+		// prepare changes Data before nominating in the transaction.
+		prepare func(context.Context, kpool.Tx) error
+
+		// nominate calls the testee.
+		nominate func(context.Context, kpool.Tx, kpgnom.Nominator) error
+	}
+
+	theory := func(given tables.Operation, when When, then []tables.Nomination) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			tx := try.To(pool.Begin(ctx)).OrFatal(t)
+			defer tx.Rollback(ctx)
+
+			if when.prepare != nil {
+				if err := when.prepare(ctx, tx); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := when.nominate(ctx, tx, kpgnom.DefaultNominator()); err != nil {
+				t.Fatal(err)
+			}
+			if err := tx.Commit(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			actual := try.To(scanner.New[tables.Nomination]().QueryAll(
+				ctx, conn, `table "nomination"`,
+			)).OrFatal(t)
+			if !cmp.SliceContentEq(actual, then) {
+				t.Errorf(
+					"unmatch\n===actual===\n%+v\n===expected===\n%+v",
+					actual, then,
+				)
+			}
+		}
+	}
+
+	t.Run("when newer Data is nominated, older Data is unselected", theory(
+		given(
+			uploaded("knit-1", "knit-2", "knit-3"),
+			[]tables.Nomination{
+				{KnitId: Padding36("knit-2"), InputId: 100, Updated: false},
+				{KnitId: Padding36("knit-1"), InputId: 101, Updated: false},
+				{KnitId: Padding36("knit-2"), InputId: 101, Updated: false},
+			},
+		),
+		When{
+			nominate: func(ctx context.Context, tx kpool.Tx, testee kpgnom.Nominator) error {
+				return testee.NominateData(ctx, tx, []string{Padding36("knit-3")})
+			},
+		},
+		[]tables.Nomination{
+			{KnitId: Padding36("knit-3"), InputId: 100, Updated: true},
+			{KnitId: Padding36("knit-1"), InputId: 101, Updated: false},
+			{KnitId: Padding36("knit-2"), InputId: 101, Updated: false},
+			{KnitId: Padding36("knit-3"), InputId: 101, Updated: true},
+		},
+	))
+
+	t.Run("when older Data is nominated, it is not selected", theory(
+		given(
+			uploaded("knit-1", "knit-2"),
+			[]tables.Nomination{
+				{KnitId: Padding36("knit-2"), InputId: 100, Updated: false},
+				{KnitId: Padding36("knit-2"), InputId: 101, Updated: false},
+			},
+		),
+		When{
+			nominate: func(ctx context.Context, tx kpool.Tx, testee kpgnom.Nominator) error {
+				return testee.NominateData(ctx, tx, []string{Padding36("knit-1")})
+			},
+		},
+		[]tables.Nomination{
+			{KnitId: Padding36("knit-2"), InputId: 100, Updated: false},
+			{KnitId: Padding36("knit-1"), InputId: 101, Updated: true},
+			{KnitId: Padding36("knit-2"), InputId: 101, Updated: false},
+		},
+	))
+
+	t.Run("when a tag of the selected Data is removed, the next recent Data is selected", theory(
+		given(
+			uploaded("knit-1", "knit-2", "knit-3"),
+			[]tables.Nomination{
+				{KnitId: Padding36("knit-3"), InputId: 100, Updated: false},
+				{KnitId: Padding36("knit-1"), InputId: 101, Updated: false},
+				{KnitId: Padding36("knit-2"), InputId: 101, Updated: false},
+				{KnitId: Padding36("knit-3"), InputId: 101, Updated: false},
+			},
+		),
+		When{
+			prepare: func(ctx context.Context, tx kpool.Tx) error {
+				_, err := tx.Exec(
+					ctx, `delete from "tag_data" where "knit_id" = $1`, Padding36("knit-3"),
+				)
+				return err
+			},
+			nominate: func(ctx context.Context, tx kpool.Tx, testee kpgnom.Nominator) error {
+				return testee.NominateData(ctx, tx, []string{Padding36("knit-3")})
+			},
+		},
+		[]tables.Nomination{
+			{KnitId: Padding36("knit-2"), InputId: 100, Updated: true},
+			{KnitId: Padding36("knit-1"), InputId: 101, Updated: false},
+			{KnitId: Padding36("knit-2"), InputId: 101, Updated: false},
+		},
+	))
+
+	t.Run("when a tag of unselected Data is removed, selection is not changed", theory(
+		given(
+			uploaded("knit-1", "knit-2"),
+			[]tables.Nomination{
+				{KnitId: Padding36("knit-2"), InputId: 100, Updated: false},
+				{KnitId: Padding36("knit-1"), InputId: 101, Updated: false},
+				{KnitId: Padding36("knit-2"), InputId: 101, Updated: false},
+			},
+		),
+		When{
+			prepare: func(ctx context.Context, tx kpool.Tx) error {
+				_, err := tx.Exec(
+					ctx, `delete from "tag_data" where "knit_id" = $1`, Padding36("knit-1"),
+				)
+				return err
+			},
+			nominate: func(ctx context.Context, tx kpool.Tx, testee kpgnom.Nominator) error {
+				return testee.NominateData(ctx, tx, []string{Padding36("knit-1")})
+			},
+		},
+		[]tables.Nomination{
+			{KnitId: Padding36("knit-2"), InputId: 100, Updated: false},
+			{KnitId: Padding36("knit-2"), InputId: 101, Updated: false},
+		},
+	))
+
+	t.Run("when the selected Data is deleted, the next recent Data is selected", theory(
+		given(
+			uploaded("knit-1", "knit-2"),
+			[]tables.Nomination{
+				{KnitId: Padding36("knit-2"), InputId: 100, Updated: false},
+				{KnitId: Padding36("knit-1"), InputId: 101, Updated: false},
+				{KnitId: Padding36("knit-2"), InputId: 101, Updated: false},
+			},
+		),
+		When{
+			prepare: func(ctx context.Context, tx kpool.Tx) error {
+				// Data being deleted lose their tags before nominations are dropped.
+				if _, err := tx.Exec(
+					ctx, `delete from "tag_data" where "knit_id" = $1`, Padding36("knit-2"),
+				); err != nil {
+					return err
+				}
+				_, err := tx.Exec(
+					ctx, `delete from "knit_timestamp" where "knit_id" = $1`, Padding36("knit-2"),
+				)
+				return err
+			},
+			nominate: func(ctx context.Context, tx kpool.Tx, testee kpgnom.Nominator) error {
+				return testee.DropData(ctx, tx, []string{Padding36("knit-2")})
+			},
+		},
+		[]tables.Nomination{
+			{KnitId: Padding36("knit-1"), InputId: 100, Updated: true},
+			{KnitId: Padding36("knit-1"), InputId: 101, Updated: false},
+		},
+	))
+}
+
+func TestNominator_Parameter(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	TIMESTAMP := time.Date(2022, time.August, 1, 12, 13, 24, 0, time.UTC)
+	TAG := domain.Tag{Key: "type", Value: "lr"}
+
+	// Input 100 is a parameter input, and Input 200 is not. Both of them have TAG.
+	//
+	// Data "knit-param" is materialised from a value of Input 100, and "knit-upload" is uploaded.
+	// Both of them have TAG also.
+	given := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: Padding36("parameter"), Active: true, Hash: "hash"},
+			{PlanId: Padding36("upload"), Active: true, Hash: "hash"},
+			{PlanId: Padding36("plan-1"), Active: true, Hash: "hash"},
+			{PlanId: Padding36("plan-2"), Active: true, Hash: "hash"},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: Padding36("parameter"), Name: string(domain.Parameter)},
+			{PlanId: Padding36("upload"), Name: string(domain.Uploaded)},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: Padding36("plan-1"), Image: "repo.invalid/image", Version: "v0.1"},
+			{PlanId: Padding36("plan-2"), Image: "repo.invalid/image", Version: "v0.1"},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1, PlanId: Padding36("parameter"), Path: "/parameter"}: {},
+			{OutputId: 2, PlanId: Padding36("upload"), Path: "/out"}:          {},
+		},
+		Inputs: map[tables.Input]tables.InputAttr{
+			{InputId: 100, PlanId: Padding36("plan-1"), Path: "/in/lr"}: {
+				UserTag: []domain.Tag{TAG},
+				Values:  []string{"0.1"},
+			},
+			{InputId: 200, PlanId: Padding36("plan-2"), Path: "/in/lr"}: {
+				UserTag: []domain.Tag{TAG},
+			},
+		},
+		Steps: []tables.Step{
+			{
+				Run: tables.Run{
+					RunId: Padding36("run-param"), PlanId: Padding36("parameter"),
+					Status: domain.Done, UpdatedAt: TIMESTAMP,
+				},
+				Outcomes: map[tables.Data]tables.DataAttibutes{
+					{
+						KnitId: Padding36("knit-param"), VolumeRef: "vol-param",
+						OutputId: 1, RunId: Padding36("run-param"), PlanId: Padding36("parameter"),
+					}: {UserTag: []domain.Tag{TAG}},
+				},
+			},
+			{
+				Run: tables.Run{
+					RunId: Padding36("run-upload"), PlanId: Padding36("upload"),
+					Status: domain.Done, UpdatedAt: TIMESTAMP,
+				},
+				Outcomes: map[tables.Data]tables.DataAttibutes{
+					{
+						KnitId: Padding36("knit-upload"), VolumeRef: "vol-upload",
+						OutputId: 2, RunId: Padding36("run-upload"), PlanId: Padding36("upload"),
+					}: {UserTag: []domain.Tag{TAG}},
+				},
+			},
+		},
+		ParameterData: []tables.ParameterData{
+			{KnitId: Padding36("knit-param"), Value: "0.1", InputId: 100},
+		},
+	}
+
+	theory := func(
+		nominate func(context.Context, kpool.Tx, kpgnom.Nominator) error,
+		then []tables.Nomination,
+	) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			tx := try.To(pool.Begin(ctx)).OrFatal(t)
+			defer tx.Rollback(ctx)
+
+			if err := nominate(ctx, tx, kpgnom.DefaultNominator()); err != nil {
+				t.Fatal(err)
+			}
+			if err := tx.Commit(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			actual := try.To(scanner.New[tables.Nomination]().QueryAll(
+				ctx, conn, `table "nomination"`,
+			)).OrFatal(t)
+			if !cmp.SliceContentEq(actual, then) {
+				t.Errorf(
+					"unmatch\n===actual===\n%+v\n===expected===\n%+v",
+					actual, then,
+				)
+			}
+		}
+	}
+
+	t.Run("when Data materialised from a parameter is nominated, it is nominated only to its input", theory(
+		func(ctx context.Context, tx kpool.Tx, testee kpgnom.Nominator) error {
+			return testee.NominateData(ctx, tx, []string{Padding36("knit-param")})
+		},
+		[]tables.Nomination{
+			{KnitId: Padding36("knit-param"), InputId: 100, Updated: true},
+		},
+	))
+
+	t.Run("when other Data is nominated, it is not nominated to parameter inputs", theory(
+		func(ctx context.Context, tx kpool.Tx, testee kpgnom.Nominator) error {
+			return testee.NominateData(ctx, tx, []string{Padding36("knit-upload")})
+		},
+		[]tables.Nomination{
+			{KnitId: Padding36("knit-upload"), InputId: 200, Updated: true},
+		},
+	))
+
+	t.Run("when inputs are nominated, parameter inputs get only their parameter Data", theory(
+		func(ctx context.Context, tx kpool.Tx, testee kpgnom.Nominator) error {
+			return testee.NominateMountpoints(ctx, tx, []int{100, 200})
+		},
+		[]tables.Nomination{
+			{KnitId: Padding36("knit-param"), InputId: 100, Updated: true},
+			{KnitId: Padding36("knit-upload"), InputId: 200, Updated: true},
+		},
+	))
+}
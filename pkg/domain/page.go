@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Order is the order of results of Find.
+type Order string
+
+const (
+	Ascending  Order = "asc"
+	Descending Order = "desc"
+)
+
+func AsOrder(s string) (Order, error) {
+	switch o := Order(s); o {
+	case Ascending, Descending:
+		return o, nil
+	default:
+		return o, fmt.Errorf("unknown order: %s", s)
+	}
+}
+
+// Page specifies a part of results of Find.
+//
+// Results are found with keyset pagination: a page starts after the Cursor,
+// so pages are not shifted by results added or removed in the meantime.
+type Page struct {
+	// Limit is the max number of results in the page.
+	//
+	// Zero means unlimited.
+	Limit int
+
+	// Cursor is where the page starts after.
+	//
+	// It should be the one returned by Find with the same conditions and Order.
+	// Empty means the page starts from the first.
+	Cursor string
+
+	// Order of results. Empty means Ascending.
+	Order Order
+}
+
+// ErrInvalidCursor is returned when the Cursor of Page is not understood.
+var ErrInvalidCursor = errors.New("invalid cursor")
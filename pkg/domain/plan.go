@@ -7,9 +7,13 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/cron"
 	"github.com/opst/knitfab/pkg/utils/slices"
 	kstr "github.com/opst/knitfab/pkg/utils/strings"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -26,6 +30,14 @@ const (
 	// File upload plan
 	Uploaded PseudoPlanName = "knit#uploaded"
 	Imported PseudoPlanName = "knit#imported"
+
+	// Plan materialising values of parameter inputs as Data
+	Parameter PseudoPlanName = "knit#parameter"
+)
+
+const (
+	// Name of the file in the Data materialised from parameter.
+	ParameterFileName = "value"
 )
 
 type Annotation struct {
@@ -51,6 +63,189 @@ type AnnotationDelta struct {
 	RemoveKey []string
 }
 
+// PriorityChange is a change of priority and concurrency limit of a Plan.
+//
+// Nil fields are left unchanged.
+type PriorityChange struct {
+	// Priority of Runs of the Plan.
+	Priority *int
+
+	// MaxConcurrency is the max number of Runs of the Plan starting or running at once.
+	//
+	// 0 means unlimited.
+	MaxConcurrency *int
+}
+
+func (pc PriorityChange) Validate() error {
+	if pc.MaxConcurrency != nil && *pc.MaxConcurrency < 0 {
+		return fmt.Errorf(
+			"%w: it should be 0 (unlimited) or positive: %d", ErrInvalidMaxConcurrency, *pc.MaxConcurrency,
+		)
+	}
+	return nil
+}
+
+// RetryPolicy describes how failed Runs of a Plan are retried automatically.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts of a Run, including the first one.
+	MaxAttempts int
+
+	// Backoff is the wait before the first retry.
+	//
+	// It is doubled for each retry, up to 1 hour (or Backoff itself, if longer).
+	Backoff time.Duration
+
+	// On is causes of exits to be retried.
+	//
+	// Empty means both of ExitByFailure and ExitByStucking.
+	On []ExitCause
+
+	// ExitCodes restricts retries on ExitByFailure to exits with one of these codes.
+	//
+	// Empty means any exit code.
+	ExitCodes []uint8
+}
+
+func (rp *RetryPolicy) Equal(o *RetryPolicy) bool {
+	if rp == nil || o == nil {
+		return rp == nil && o == nil
+	}
+	return rp.MaxAttempts == o.MaxAttempts &&
+		rp.Backoff == o.Backoff &&
+		cmp.SliceContentEq(rp.On, o.On) &&
+		cmp.SliceContentEq(rp.ExitCodes, o.ExitCodes)
+}
+
+func (rp *RetryPolicy) Validate() error {
+	if rp == nil {
+		return nil
+	}
+	if rp.MaxAttempts < 1 {
+		return fmt.Errorf("%w: max attempts should be positive: %d", ErrInvalidRetryPolicy, rp.MaxAttempts)
+	}
+	if rp.Backoff < 0 {
+		return fmt.Errorf("%w: backoff should not be negative: %s", ErrInvalidRetryPolicy, rp.Backoff)
+	}
+	for _, c := range rp.On {
+		switch c {
+		case ExitByFailure, ExitByStucking:
+		default:
+			return fmt.Errorf(
+				"%w: unknown cause %q (should be %q or %q)",
+				ErrInvalidRetryPolicy, c, ExitByFailure, ExitByStucking,
+			)
+		}
+	}
+	return nil
+}
+
+// ShouldRetry reports whether a Run should be retried
+// when its attempt-th attempt has exited with exit.
+//
+// Runs exited without causes (e.g. aborted by user) are not retried.
+func (rp *RetryPolicy) ShouldRetry(attempt int, exit *RunExit) bool {
+	if rp == nil || exit == nil || rp.MaxAttempts <= attempt {
+		return false
+	}
+
+	switch exit.Cause {
+	case ExitByFailure, ExitByStucking:
+	default:
+		return false
+	}
+	if 0 < len(rp.On) {
+		if _, ok := slices.First(rp.On, func(c ExitCause) bool { return c == exit.Cause }); !ok {
+			return false
+		}
+	}
+	if exit.Cause == ExitByFailure && 0 < len(rp.ExitCodes) {
+		_, ok := slices.First(rp.ExitCodes, func(c uint8) bool { return c == exit.Code })
+		return ok
+	}
+	return true
+}
+
+// BackoffFor returns the wait before retrying a Run whose attempt-th attempt has failed.
+func (rp *RetryPolicy) BackoffFor(attempt int) time.Duration {
+	if rp == nil {
+		return 0
+	}
+	limit := max(rp.Backoff, time.Hour)
+	b := rp.Backoff
+	for i := 1; i < attempt && b < limit; i++ {
+		b *= 2
+	}
+	return min(b, limit)
+}
+
+// EnvVar is an environment variable set to workers of Runs of a Plan.
+//
+// It has a static Value, or refers a key of a Secret or a ConfigMap
+// in the namespace where workers run.
+type EnvVar struct {
+	// Name of the environment variable.
+	Name string
+
+	// Value is the static value.
+	//
+	// It is empty when the EnvVar refers a Secret or a ConfigMap.
+	Value string
+
+	// Secret is the key of the Secret whose value is set. Nil if not referred.
+	Secret *EnvVarKeyRef
+
+	// ConfigMap is the key of the ConfigMap whose value is set. Nil if not referred.
+	ConfigMap *EnvVarKeyRef
+}
+
+// EnvVarKeyRef refers a key of a Secret or a ConfigMap.
+type EnvVarKeyRef struct {
+	// Name of the Secret or the ConfigMap.
+	Name string
+
+	// Key in the Secret or the ConfigMap.
+	Key string
+}
+
+func (e EnvVar) Equal(o EnvVar) bool {
+	refEq := func(a, b *EnvVarKeyRef) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return *a == *b
+	}
+	return e.Name == o.Name &&
+		e.Value == o.Value &&
+		refEq(e.Secret, o.Secret) &&
+		refEq(e.ConfigMap, o.ConfigMap)
+}
+
+var reEnvVarName = regexp.MustCompile(`^[-._a-zA-Z][-._a-zA-Z0-9]*$`)
+
+func (e EnvVar) Validate() error {
+	if !reEnvVarName.MatchString(e.Name) {
+		return fmt.Errorf("%w: malformed name: %q", ErrInvalidEnv, e.Name)
+	}
+
+	refs := 0
+	for kind, ref := range map[string]*EnvVarKeyRef{"secret": e.Secret, "config map": e.ConfigMap} {
+		if ref == nil {
+			continue
+		}
+		refs += 1
+		if ref.Name == "" || ref.Key == "" {
+			return fmt.Errorf("%w: %s: %s reference needs name and key", ErrInvalidEnv, e.Name, kind)
+		}
+	}
+	if 1 < refs {
+		return fmt.Errorf("%w: %s: it can not refer both of a secret and a config map", ErrInvalidEnv, e.Name)
+	}
+	if 0 < refs && e.Value != "" {
+		return fmt.Errorf("%w: %s: it can not have both of a value and a reference", ErrInvalidEnv, e.Name)
+	}
+	return nil
+}
+
 // Main body of plan, describes "what it is".
 //
 // Use Plan if you need PlanBody & relationship with others.
@@ -87,15 +282,87 @@ type PlanBody struct {
 	//
 	// key is resource name (cpu, memory, ...), value is quantity.
 	// Key and Value are follow k8s resource requirements specs.
+	//
+	// They are limits of resources.
 	Resources map[string]resource.Quantity
 
+	// ResourceRequests are resources which Workers of this plan request for scheduling.
+	//
+	// Resources not requested here are requested as much as limits (k8s default).
+	// So, requests lower than limits allow overcommit of nodes.
+	ResourceRequests map[string]resource.Quantity
+
 	OnNode []OnNode
 
+	// Tolerations are kubernetes tolerations of Workers of this Plan,
+	// in addition to ones derived from OnNode.
+	Tolerations []Toleration
+
+	// PriorityClassName is the kubernetes PriorityClass of Workers of this Plan.
+	//
+	// Empty means the default of the cluster configuration.
+	PriorityClassName string
+
+	// RuntimeClassName is the kubernetes RuntimeClass of Workers of this Plan.
+	//
+	// Empty means the default runtime.
+	RuntimeClassName string
+
+	// Scratches are ephemeral directories for Workers of this Plan.
+	Scratches []ScratchVolume
+
+	// ShmSize is the size of shared memory (/dev/shm) of Workers of this Plan.
+	//
+	// Zero means the default of the container runtime.
+	ShmSize resource.Quantity
+
 	// ServiceAccount is the name of the service agent that Workers of this Plan should use.
 	ServiceAccount string
 
 	// Annotations is a list of annotations for this Plan.
 	Annotations []Annotation
+
+	// Supersedes is the Plan Id of the predecessor of this Plan.
+	//
+	// Empty if this Plan does not supersede any Plan.
+	Supersedes string
+
+	// SupersededBy is the Plan Id of the successor of this Plan.
+	//
+	// Empty if this Plan is not superseded.
+	SupersededBy string
+
+	// Schedule is the cron expression when Runs of this Plan are triggered periodically.
+	//
+	// Empty if this Plan is not scheduled.
+	Schedule string
+
+	// Priority of Runs of this Plan.
+	//
+	// Runs of Plans with higher priority are started earlier.
+	Priority int
+
+	// MaxConcurrency is the max number of Runs of this Plan
+	// which can be starting or running at once.
+	//
+	// 0 means unlimited.
+	MaxConcurrency int
+
+	// Retry is the policy to retry failed Runs of this Plan automatically.
+	//
+	// Nil if Runs of this Plan are not retried automatically.
+	Retry *RetryPolicy
+
+	// MaxDuration is the time limit of a Run of this Plan.
+	//
+	// When a worker of a Run has been active for longer than this,
+	// it is terminated and the Run is failed by timeout.
+	//
+	// 0 means unlimited.
+	MaxDuration time.Duration
+
+	// Env is environment variables set to workers of Runs of this Plan, in declared order.
+	Env []EnvVar
 }
 
 // true iff pb and other are equal, means they represent same entity
@@ -113,8 +380,20 @@ func (pb *PlanBody) Equiv(other *PlanBody) bool {
 		pb.Pseudo.Equal(other.Pseudo) &&
 		cmp.SliceContentEq(pb.OnNode, other.OnNode) &&
 		cmp.MapEqWith(pb.Resources, other.Resources, resource.Quantity.Equal) &&
+		cmp.MapEqWith(pb.ResourceRequests, other.ResourceRequests, resource.Quantity.Equal) &&
+		cmp.SliceEq(pb.Tolerations, other.Tolerations) &&
+		pb.PriorityClassName == other.PriorityClassName &&
+		pb.RuntimeClassName == other.RuntimeClassName &&
+		cmp.SliceContentEqWith(pb.Scratches, other.Scratches, ScratchVolume.Equal) &&
+		pb.ShmSize.Equal(other.ShmSize) &&
 		pb.ServiceAccount == other.ServiceAccount &&
-		cmp.SliceContentEq(pb.Annotations, other.Annotations)
+		cmp.SliceContentEq(pb.Annotations, other.Annotations) &&
+		pb.Schedule == other.Schedule &&
+		pb.Priority == other.Priority &&
+		pb.MaxConcurrency == other.MaxConcurrency &&
+		pb.Retry.Equal(other.Retry) &&
+		pb.MaxDuration == other.MaxDuration &&
+		cmp.SliceEqWith(pb.Env, other.Env, EnvVar.Equal)
 }
 
 // how to schedule the run of this plan
@@ -176,6 +455,118 @@ func (o OnNode) String() string {
 	return fmt.Sprintf("%s=%s:%s", o.Key, o.Value, o.Mode)
 }
 
+// operator of kubernetes toleration
+type TolerationOperator string
+
+const (
+	// toleration matches taints with same key and value.
+	TolerationOpEqual TolerationOperator = "Equal"
+
+	// toleration matches taints with same key, whatever its value is.
+	TolerationOpExists TolerationOperator = "Exists"
+)
+
+// effect of kubernetes taint
+type TaintEffect string
+
+const (
+	TaintEffectNoSchedule       TaintEffect = "NoSchedule"
+	TaintEffectPreferNoSchedule TaintEffect = "PreferNoSchedule"
+	TaintEffectNoExecute        TaintEffect = "NoExecute"
+)
+
+// kubernetes toleration, which Workers of a Plan have.
+//
+// In addition to tolerations derived from OnNode,
+// it makes Workers tolerate taints not related to node labels (e.g. of GPU nodes).
+type Toleration struct {
+	// key of taint. Empty matches all keys, only with TolerationOpExists.
+	Key string
+
+	// Operator. Empty means TolerationOpEqual.
+	Operator TolerationOperator
+
+	// value of taint. It should be empty with TolerationOpExists.
+	Value string
+
+	// Effect of taint to be tolerated. Empty matches all effects.
+	Effect TaintEffect
+}
+
+func (t Toleration) Validate() error {
+	switch t.Operator {
+	case "", TolerationOpEqual:
+		if t.Key == "" {
+			return fmt.Errorf("%w: key is required for operator Equal", ErrInvalidToleration)
+		}
+	case TolerationOpExists:
+		if t.Value != "" {
+			return fmt.Errorf("%w: %s: value should be empty for operator Exists", ErrInvalidToleration, t.Key)
+		}
+	default:
+		return fmt.Errorf("%w: %s: unknown operator: %s", ErrInvalidToleration, t.Key, t.Operator)
+	}
+
+	if t.Key != "" && !reLabelKey.MatchString(t.Key) {
+		return fmt.Errorf("%w: bad pattern: %s", ErrInvalidToleration, t.Key)
+	}
+	if t.Value != "" && !reLabelVal.MatchString(t.Value) {
+		return fmt.Errorf("%w: %s: bad pattern: %s", ErrInvalidToleration, t.Key, t.Value)
+	}
+
+	switch t.Effect {
+	case "", TaintEffectNoSchedule, TaintEffectPreferNoSchedule, TaintEffectNoExecute:
+	default:
+		return fmt.Errorf("%w: %s: unknown effect: %s", ErrInvalidToleration, t.Key, t.Effect)
+	}
+	return nil
+}
+
+// ScratchVolume is an ephemeral directory for Workers of a Plan.
+//
+// It is empty when a Worker starts, and discarded when the Worker finishes.
+// Contents of it are not recorded as Data.
+type ScratchVolume struct {
+	// Path where the directory is mounted in the container.
+	Path string
+
+	// SizeLimit of the directory. Zero means unlimited.
+	SizeLimit resource.Quantity
+
+	// InMemory is true if the directory is backed by memory (tmpfs).
+	//
+	// Files in it count toward the memory limit of the Worker.
+	InMemory bool
+}
+
+func (s ScratchVolume) Equal(o ScratchVolume) bool {
+	return s.Path == o.Path &&
+		s.SizeLimit.Equal(o.SizeLimit) &&
+		s.InMemory == o.InMemory
+}
+
+// ShmPath is the path of the shared memory mounted when ShmSize of a Plan is set.
+const ShmPath = "/dev/shm"
+
+// ValidateResourceRequests checks resource requests against resource limits.
+//
+// Requests should not be negative, and should not exceed limits of the same type.
+// Types without limits can be requested.
+func ValidateResourceRequests(requests map[string]resource.Quantity, limits map[string]resource.Quantity) error {
+	for typ, req := range requests {
+		if req.Sign() < 0 {
+			return fmt.Errorf("%w: %s: it should not be negative: %s", ErrInvalidResourceRequests, typ, req.String())
+		}
+		if limit, ok := limits[typ]; ok && limit.Cmp(req) < 0 {
+			return fmt.Errorf(
+				"%w: %s: request %s exceeds limit %s",
+				ErrInvalidResourceRequests, typ, req.String(), limit.String(),
+			)
+		}
+	}
+	return nil
+}
+
 type Plan struct {
 	PlanBody
 	Inputs  []Input
@@ -216,17 +607,31 @@ func (p *Plan) Equiv(other *Plan) bool {
 
 // container image identifier
 type ImageIdentifier struct {
-	Image   string
+	Image string
+
+	// Version is the tag of the image,
+	// or the digest (like "sha256:...") when the image is pinned by digest.
 	Version string
 }
 
+// IsDigest returns true if Version is a digest, not a tag.
+//
+// Tags cannot contain ":", so a Version with ":" is a digest.
+func (ii ImageIdentifier) IsDigest() bool {
+	return strings.Contains(ii.Version, ":")
+}
+
 func (ii *ImageIdentifier) Fulfilled() bool {
 	return ii != nil &&
 		ii.Image != "" &&
 		ii.Version != ""
 }
 
+// String returns the image reference, as "IMAGE:TAG" or "IMAGE@DIGEST".
 func (ii ImageIdentifier) String() string {
+	if ii.IsDigest() {
+		return fmt.Sprintf("%s@%s", ii.Image, ii.Version)
+	}
 	return fmt.Sprintf("%s:%s", ii.Image, ii.Version)
 }
 
@@ -243,18 +648,25 @@ func (ii *ImageIdentifier) Parse(s string) error {
 	//
 	// image can be formatted in...
 	//
-	//  [REGISTRY:[PORT]][/NAMESPACE[/...]/]IMAGE_NAME[:TAG]
+	//  [REGISTRY:[PORT]][/NAMESPACE[/...]/]IMAGE_NAME[:TAG][@DIGEST]
 	//
 	// We would like to split this into two parts:
 	//
 	// - image: REGISTRY/NAMESPACE/.../IMAGE_NAME
-	// - tag: TAG
+	// - tag: DIGEST if any, otherwise TAG
 	//
+	// When both of TAG and DIGEST are given, TAG is ignored as container runtimes do.
 
 	if s == "" {
 		return fmt.Errorf("%w: empty string", ErrInvalidImageIdentifier)
 	}
 
+	// 0. split digest
+	s, digest, pinned := strings.Cut(s, "@")
+	if pinned && !reDigest.MatchString(digest) {
+		return fmt.Errorf("%w: malformed digest: %s", ErrInvalidImageIdentifier, digest)
+	}
+
 	// 1. split namespace and image name
 	namespace := ""
 	_image := ""
@@ -278,6 +690,10 @@ func (ii *ImageIdentifier) Parse(s string) error {
 		image = strings.Join([]string{namespace, img}, "/")
 	}
 
+	if pinned {
+		tag = digest
+	}
+
 	*ii = ImageIdentifier{
 		Image:   image,
 		Version: tag,
@@ -285,6 +701,9 @@ func (ii *ImageIdentifier) Parse(s string) error {
 	return nil
 }
 
+// digest of an image, pinned by sha256.
+var reDigest = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
 var ErrInvalidImageIdentifier = errors.New("invalid image name")
 
 type PseudoPlanDetail struct {
@@ -367,18 +786,97 @@ func (d PlanDownstream) Equal(other PlanDownstream) bool {
 type Input struct {
 	MountPoint
 
+	// Selection is the policy to select Data for this Input.
+	Selection InputSelection
+
+	// Values are literal values of parameter input.
+	//
+	// If empty, this Input is not a parameter input.
+	Values []string
+
 	Upstreams []PlanUpstream
 }
 
 func (i Input) Equal(other Input) bool {
 	return i.MountPoint.Equal(&other.MountPoint) &&
+		i.Selection == other.Selection &&
+		cmp.SliceContentEq(i.Values, other.Values) &&
 		cmp.SliceContentEqWith(
 			i.Upstreams, other.Upstreams, PlanUpstream.Equal,
 		)
 }
 
 func (i Input) Equiv(other Input) bool {
-	return i.MountPoint.Equiv(&other.MountPoint)
+	return i.MountPoint.Equiv(&other.MountPoint) &&
+		i.Selection == other.Selection &&
+		cmp.SliceContentEq(i.Values, other.Values)
+}
+
+// IsParameter returns true if the Input is a parameter input.
+func (i Input) IsParameter() bool {
+	return 0 < len(i.Values)
+}
+
+const (
+	// SelectAll is the InputSelection which uses all Data matching with the Input.
+	SelectAll = "all"
+
+	// SelectLatest is the InputSelection which uses the most recent Data matching with the Input.
+	//
+	// "latest:N" means that the N most recent Data are used.
+	SelectLatest = "latest"
+)
+
+// InputSelection is a policy to select Data for an Input, among Data matching with the Input.
+//
+// Runs are created for each combination of the selected Data of Inputs.
+type InputSelection struct {
+	// Latest is the number of the most recent Data (ordered by knit#timestamp) to be used.
+	//
+	// If 0, all Data are used.
+	Latest int
+}
+
+// IsAll returns true if all Data are selected.
+func (s InputSelection) IsAll() bool {
+	return s.Latest <= 0
+}
+
+func (s InputSelection) String() string {
+	switch {
+	case s.IsAll():
+		return SelectAll
+	case s.Latest == 1:
+		return SelectLatest
+	default:
+		return fmt.Sprintf("%s:%d", SelectLatest, s.Latest)
+	}
+}
+
+// ParseInputSelection parses a string representation of InputSelection.
+//
+// It accepts "all" (or empty), "latest" or "latest:N" (N is a positive integer).
+func ParseInputSelection(s string) (InputSelection, error) {
+	switch s {
+	case "", SelectAll:
+		return InputSelection{}, nil
+	case SelectLatest:
+		return InputSelection{Latest: 1}, nil
+	}
+
+	policy, n, ok := strings.Cut(s, ":")
+	if !ok || policy != SelectLatest {
+		return InputSelection{}, fmt.Errorf(
+			`%w: %s (should be "all", "latest" or "latest:N")`, ErrInvalidInputSelection, s,
+		)
+	}
+	latest, err := strconv.Atoi(n)
+	if err != nil || latest <= 0 {
+		return InputSelection{}, fmt.Errorf(
+			"%w: %s (N in latest:N should be a positive integer)", ErrInvalidInputSelection, s,
+		)
+	}
+	return InputSelection{Latest: latest}, nil
 }
 
 type Output struct {
@@ -411,6 +909,21 @@ type PlanParam struct {
 	Resources      map[string]resource.Quantity
 	ServiceAccount string
 	Annotations    []Annotation
+	Schedule       string
+
+	ResourceRequests  map[string]resource.Quantity
+	Tolerations       []Toleration
+	PriorityClassName string
+	RuntimeClassName  string
+
+	Scratches []ScratchVolume
+	ShmSize   resource.Quantity
+
+	Priority       int
+	MaxConcurrency int
+	Retry          *RetryPolicy
+	MaxDuration    time.Duration
+	Env            []EnvVar
 }
 
 // validate parameters and create PlanSpec.
@@ -437,6 +950,16 @@ func (pp PlanParam) Validate() (*PlanSpec, error) {
 	for k, v := range pp.Resources {
 		resources[k] = v
 	}
+	env := make([]EnvVar, len(pp.Env))
+	copy(env, pp.Env)
+	requests := make(map[string]resource.Quantity, len(pp.ResourceRequests))
+	for k, v := range pp.ResourceRequests {
+		requests[k] = v
+	}
+	tolerations := make([]Toleration, len(pp.Tolerations))
+	copy(tolerations, pp.Tolerations)
+	scratches := make([]ScratchVolume, len(pp.Scratches))
+	copy(scratches, pp.Scratches)
 
 	// take snapshot to guard from changing pp.mountpoint after return this method.
 
@@ -453,6 +976,20 @@ func (pp PlanParam) Validate() (*PlanSpec, error) {
 		resources:      resources,
 		serviceaccount: pp.ServiceAccount,
 		annotations:    annotations,
+		schedule:       pp.Schedule,
+		priority:       pp.Priority,
+		maxConcurrency: pp.MaxConcurrency,
+		retry:          pp.Retry,
+		maxDuration:    pp.MaxDuration,
+		env:            env,
+
+		resourceRequests:  requests,
+		tolerations:       tolerations,
+		priorityClassName: pp.PriorityClassName,
+		runtimeClassName:  pp.RuntimeClassName,
+
+		scratches: scratches,
+		shmSize:   pp.ShmSize,
 	}
 	if err := ret.Validate(); err != nil {
 		return nil, err
@@ -476,6 +1013,10 @@ func BypassValidation(hash string, err error, pp PlanParam) *PlanSpec {
 	for k, v := range pp.Resources {
 		resources[k] = v
 	}
+	requests := make(map[string]resource.Quantity, len(pp.ResourceRequests))
+	for k, v := range pp.ResourceRequests {
+		requests[k] = v
+	}
 	// take snapshot to guard from changing pp.mountpoint after return this method.
 
 	ps := &PlanSpec{
@@ -493,6 +1034,20 @@ func BypassValidation(hash string, err error, pp PlanParam) *PlanSpec {
 
 		serviceaccount: pp.ServiceAccount,
 		annotations:    annotations,
+		schedule:       pp.Schedule,
+		priority:       pp.Priority,
+		maxConcurrency: pp.MaxConcurrency,
+		retry:          pp.Retry,
+		maxDuration:    pp.MaxDuration,
+		env:            pp.Env,
+
+		resourceRequests:  requests,
+		tolerations:       pp.Tolerations,
+		priorityClassName: pp.PriorityClassName,
+		runtimeClassName:  pp.RuntimeClassName,
+
+		scratches: pp.Scratches,
+		shmSize:   pp.ShmSize,
 
 		validated: true,
 		vErr:      err,
@@ -520,8 +1075,21 @@ type PlanSpec struct {
 
 	serviceaccount string
 	annotations    []Annotation
-
-	resources map[string]resource.Quantity
+	schedule       string
+	priority       int
+	maxConcurrency int
+	retry          *RetryPolicy
+	maxDuration    time.Duration
+	env            []EnvVar
+
+	resources         map[string]resource.Quantity
+	resourceRequests  map[string]resource.Quantity
+	tolerations       []Toleration
+	priorityClassName string
+	runtimeClassName  string
+
+	scratches []ScratchVolume
+	shmSize   resource.Quantity
 
 	validated bool
 	vErr      error
@@ -567,6 +1135,40 @@ func (ps *PlanSpec) Resources() map[string]resource.Quantity {
 	return ps.resources
 }
 
+// ResourceRequests returns resources which Workers of the Plan request for scheduling.
+func (ps *PlanSpec) ResourceRequests() map[string]resource.Quantity {
+	return ps.resourceRequests
+}
+
+// Tolerations returns kubernetes tolerations of Workers of the Plan.
+func (ps *PlanSpec) Tolerations() []Toleration {
+	return ps.tolerations
+}
+
+// PriorityClassName returns kubernetes PriorityClass of Workers of the Plan.
+// Empty means the default.
+func (ps *PlanSpec) PriorityClassName() string {
+	return ps.priorityClassName
+}
+
+// RuntimeClassName returns kubernetes RuntimeClass of Workers of the Plan.
+// Empty means the default.
+func (ps *PlanSpec) RuntimeClassName() string {
+	return ps.runtimeClassName
+}
+
+// Scratches returns ephemeral directories for Workers of the Plan.
+func (ps *PlanSpec) Scratches() []ScratchVolume {
+	return ps.scratches
+}
+
+// ShmSize returns the size of shared memory of Workers of the Plan.
+//
+// Zero means the default of the container runtime.
+func (ps *PlanSpec) ShmSize() resource.Quantity {
+	return ps.shmSize
+}
+
 func (ps *PlanSpec) Annotations() []Annotation {
 	return ps.annotations
 }
@@ -575,6 +1177,38 @@ func (ps *PlanSpec) ServiceAccount() string {
 	return ps.serviceaccount
 }
 
+// Schedule returns cron expression of the Plan. Empty if the Plan is not scheduled.
+func (ps *PlanSpec) Schedule() string {
+	return ps.schedule
+}
+
+// Priority returns priority of Runs of the Plan.
+func (ps *PlanSpec) Priority() int {
+	return ps.priority
+}
+
+// MaxConcurrency returns the max number of Runs of the Plan starting or running at once.
+// 0 means unlimited.
+func (ps *PlanSpec) MaxConcurrency() int {
+	return ps.maxConcurrency
+}
+
+// Retry returns the policy to retry failed Runs of the Plan. Nil if they are not retried.
+func (ps *PlanSpec) Retry() *RetryPolicy {
+	return ps.retry
+}
+
+// MaxDuration returns the time limit of a Run of the Plan.
+// 0 means unlimited.
+func (ps *PlanSpec) MaxDuration() time.Duration {
+	return ps.maxDuration
+}
+
+// Env returns environment variables set to workers of the Plan, in declared order.
+func (ps *PlanSpec) Env() []EnvVar {
+	return ps.env
+}
+
 func (ps *PlanSpec) Equal(other *PlanSpec) bool {
 	return ps.image == other.image &&
 		ps.version == other.version &&
@@ -588,9 +1222,21 @@ func (ps *PlanSpec) Equal(other *PlanSpec) bool {
 		ps.log.Equal(other.log) &&
 		cmp.SliceContentEq(ps.onNode, other.onNode) &&
 		cmp.MapEqWith(ps.resources, other.resources, resource.Quantity.Equal) &&
+		cmp.MapEqWith(ps.resourceRequests, other.resourceRequests, resource.Quantity.Equal) &&
+		cmp.SliceEq(ps.tolerations, other.tolerations) &&
+		ps.priorityClassName == other.priorityClassName &&
+		ps.runtimeClassName == other.runtimeClassName &&
+		cmp.SliceContentEqWith(ps.scratches, other.scratches, ScratchVolume.Equal) &&
+		ps.shmSize.Equal(other.shmSize) &&
 		ps.Hash() == other.Hash() &&
 		ps.serviceaccount == other.serviceaccount &&
-		cmp.SliceContentEq(ps.annotations, other.annotations)
+		cmp.SliceContentEq(ps.annotations, other.annotations) &&
+		ps.schedule == other.schedule &&
+		ps.priority == other.priority &&
+		ps.maxConcurrency == other.maxConcurrency &&
+		ps.retry.Equal(other.retry) &&
+		ps.maxDuration == other.maxDuration &&
+		cmp.SliceEqWith(ps.env, other.env, EnvVar.Equal)
 }
 
 // true, iff this PlanSpec is equiverent with `plan`. otherwise false.
@@ -610,7 +1256,11 @@ func (ps *PlanSpec) EquivPlan(plan *Plan) bool {
 
 	if !cmp.SliceContentEqWith(
 		ps.inputs, plan.Inputs,
-		func(mpp MountPointParam, in Input) bool { return mpp.EquivMountPoint(&in.MountPoint) },
+		func(mpp MountPointParam, in Input) bool {
+			return mpp.EquivMountPoint(&in.MountPoint) &&
+				mpp.Selection == in.Selection &&
+				cmp.SliceContentEq(mpp.Values, in.Values)
+		},
 	) {
 		return false
 	}
@@ -666,6 +1316,9 @@ func (ps *PlanSpec) Validate() error {
 	return ps.validate()
 }
 
+// https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#dns-subdomain-names
+var reDNSSubdomain = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+
 var reLabelKey = regexp.MustCompile(`^([a-zA-Z0-9]([-a-zA-Z0-9]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([-a-zA-Z0-9]{0,61}[a-zA-Z0-9])?)*/)?[a-zA-Z0-9]([-a-zA-Z0-9]{0,61}[a-zA-Z0-9])?$`)
 var reLabelVal = regexp.MustCompile(`^[a-zA-Z0-9]([-._a-zA-Z0-9]{0,61}[a-zA-Z0-9])?$`)
 
@@ -724,6 +1377,76 @@ func (ps *PlanSpec) validate() error {
 	if ps.image == "" || ps.version == "" {
 		return record(NewErrPlanNamelessImage(ps.image + ":" + ps.version))
 	}
+	if (ImageIdentifier{Image: ps.image, Version: ps.version}).IsDigest() && !reDigest.MatchString(ps.version) {
+		return record(fmt.Errorf(
+			"%w: %w: malformed digest: %s", ErrInvalidPlan, ErrInvalidImageIdentifier, ps.version,
+		))
+	}
+
+	ps.schedule = strings.TrimSpace(ps.schedule)
+	if ps.schedule != "" {
+		sched, err := cron.Parse(ps.schedule)
+		if err != nil {
+			return record(fmt.Errorf("%w: %w", ErrInvalidSchedule, err))
+		}
+		if sched.Next(time.Now()).IsZero() {
+			return record(fmt.Errorf("%w: it never comes: %s", ErrInvalidSchedule, ps.schedule))
+		}
+	}
+
+	if ps.maxConcurrency < 0 {
+		return record(fmt.Errorf(
+			"%w: it should be 0 (unlimited) or positive: %d", ErrInvalidMaxConcurrency, ps.maxConcurrency,
+		))
+	}
+
+	if err := ps.retry.Validate(); err != nil {
+		return record(err)
+	}
+
+	if ps.maxDuration < 0 {
+		return record(fmt.Errorf(
+			"%w: it should be 0 (unlimited) or positive: %s", ErrInvalidMaxDuration, ps.maxDuration,
+		))
+	}
+	if ps.maxDuration%time.Second != 0 {
+		return record(fmt.Errorf(
+			"%w: it should be in whole seconds: %s", ErrInvalidMaxDuration, ps.maxDuration,
+		))
+	}
+
+	if err := ValidateResourceRequests(ps.resourceRequests, ps.resources); err != nil {
+		return record(err)
+	}
+
+	for _, t := range ps.tolerations {
+		if err := t.Validate(); err != nil {
+			return record(err)
+		}
+	}
+
+	for what, name := range map[string]string{
+		"priority class name": ps.priorityClassName,
+		"runtime class name":  ps.runtimeClassName,
+	} {
+		if name == "" {
+			continue
+		}
+		if 253 < len(name) || !reDNSSubdomain.MatchString(name) {
+			return record(fmt.Errorf("%w: %s: bad pattern: %s", ErrInvalidClassName, what, name))
+		}
+	}
+
+	envNames := map[string]struct{}{}
+	for _, e := range ps.env {
+		if err := e.Validate(); err != nil {
+			return record(err)
+		}
+		if _, ok := envNames[e.Name]; ok {
+			return record(fmt.Errorf("%w: %s is duplicated", ErrInvalidEnv, e.Name))
+		}
+		envNames[e.Name] = struct{}{}
+	}
 
 	inputs := slices.Sorted(
 		ps.inputs,
@@ -767,6 +1490,30 @@ func (ps *PlanSpec) validate() error {
 			return record(NewErrBadMountpontTag(in.Path, "no tags for input"))
 		}
 
+		if in.Selection.Latest < 0 {
+			return record(fmt.Errorf(
+				"%w: %s: latest should not be negative", ErrInvalidInputSelection, in.Path,
+			))
+		}
+
+		if 0 < len(in.Values) {
+			if len(in.Tags.SystemTag()) != 0 {
+				return record(fmt.Errorf(
+					`%w: %s: parameter input cannot have tag starting with "knit#"`,
+					ErrInvalidParameter, in.Path,
+				))
+			}
+			seen := map[string]struct{}{}
+			for _, v := range in.Values {
+				if _, ok := seen[v]; ok {
+					return record(fmt.Errorf(
+						"%w: %s: value %q is duplicated", ErrInvalidParameter, in.Path, v,
+					))
+				}
+				seen[v] = struct{}{}
+			}
+		}
+
 		var knitId *Tag
 		var timestamp *Tag
 		for _, t := range in.Tags.SystemTag() {
@@ -819,6 +1566,16 @@ func (ps *PlanSpec) validate() error {
 				out.Path, `output cannot have tag starting with "knit#" (reserved by system)`,
 			))
 		}
+		if !out.Selection.IsAll() {
+			return record(fmt.Errorf(
+				"%w: %s: output cannot have selection", ErrInvalidInputSelection, out.Path,
+			))
+		}
+		if 0 < len(out.Values) {
+			return record(fmt.Errorf(
+				"%w: %s: output cannot have values", ErrInvalidParameter, out.Path,
+			))
+		}
 		for _, other := range inputs {
 			if pathOverlap(out.Path, other.Path) {
 				return record(NewErrOverlappedMountpoints(out.Path, other.Path))
@@ -839,6 +1596,45 @@ func (ps *PlanSpec) validate() error {
 		}
 	}
 
+	if ps.shmSize.Sign() < 0 {
+		return record(fmt.Errorf("%w: shm size should not be negative: %s", ErrInvalidScratch, ps.shmSize.String()))
+	}
+
+	// paths mounted in the container, other than scratches.
+	mounted := slices.Concat(
+		slices.Map(ps.inputs, func(mp MountPointParam) string { return mp.Path }),
+		slices.Map(ps.outputs, func(mp MountPointParam) string { return mp.Path }),
+	)
+	if ps.log != nil {
+		mounted = append(mounted, "/log")
+	}
+	if !ps.shmSize.IsZero() {
+		mounted = append(mounted, ShmPath)
+	}
+
+	scratches := slices.Sorted(
+		ps.scratches,
+		func(a, b ScratchVolume) bool { return a.Path < b.Path },
+	)
+	for nth := range scratches {
+		sc := scratches[nth]
+		sc.Path = strings.TrimSuffix(sc.Path, "/")
+		if p := sc.Path; p == "" || !filepath.IsAbs(p) || filepath.Clean(p) != p {
+			return record(NewErrBadMountpointPath(sc.Path, "scratch should be absolute and clean"))
+		}
+		if sc.SizeLimit.Sign() < 0 {
+			return record(fmt.Errorf("%w: %s: size limit should not be negative", ErrInvalidScratch, sc.Path))
+		}
+		for _, other := range mounted {
+			if pathOverlap(sc.Path, other) {
+				return record(NewErrOverlappedMountpoints(sc.Path, other))
+			}
+		}
+		mounted = append(mounted, sc.Path)
+		scratches[nth] = sc
+	}
+	ps.scratches = scratches
+
 	return record(nil)
 }
 
@@ -850,6 +1646,11 @@ func pathOverlap(a, b string) bool {
 
 // calcurate plan hash
 //
+// The hash covers image, on_node, inputs, outputs, log, entrypoint and args,
+// which decide what the Plan computes.
+// Other properties, like resources, tolerations, annotations, service account and env, are not covered:
+// Plans differing only in them are equivalent, and can not be registered together.
+//
 // # Return
 //
 // calcurated hash in hex string
@@ -871,6 +1672,18 @@ func (ps *PlanSpec) Hash() string {
 		for _, t := range mp.Tags.Slice() {
 			shahash.Write([]byte(t.String()))
 		}
+		if !mp.Selection.IsAll() {
+			shahash.Write([]byte("[select]"))
+			shahash.Write([]byte(mp.Selection.String()))
+		}
+		if 0 < len(mp.Values) {
+			shahash.Write([]byte("[values]"))
+			values := append([]string{}, mp.Values...)
+			sort.Strings(values)
+			for _, v := range values {
+				shahash.Write([]byte(v))
+			}
+		}
 	}
 	for _, mp := range ps.outputs {
 		shahash.Write([]byte(mp.Path))
@@ -908,10 +1721,18 @@ type MountPointParam struct {
 
 	// tags for this mountpoint
 	Tags *TagSet
+
+	// policy to select Data. Only for inputs.
+	Selection InputSelection
+
+	// literal values of parameter. Only for inputs.
+	Values []string
 }
 
 func (mps MountPointParam) Equal(other MountPointParam) bool {
 	return mps.Path == other.Path &&
+		mps.Selection == other.Selection &&
+		cmp.SliceContentEq(mps.Values, other.Values) &&
 		cmp.SliceContentEqWith(
 			slices.RefOf(mps.Tags.Slice()), slices.RefOf(other.Tags.Slice()),
 			(*Tag).Equal,
@@ -1019,6 +1840,20 @@ var (
 	ErrConflictingPlan    = errors.New("plan spec is conflicting with other plan")
 	ErrInvalidOnNodeKey   = fmt.Errorf("%w: on_node: invalid key", ErrInvalidPlan)
 	ErrInvalidOnNodeValue = fmt.Errorf("%w: on_node: invalid value", ErrInvalidPlan)
+	ErrInvalidSchedule    = fmt.Errorf("%w: invalid schedule", ErrInvalidPlan)
+
+	ErrInvalidMaxConcurrency = fmt.Errorf("%w: invalid max concurrency", ErrInvalidPlan)
+	ErrInvalidRetryPolicy    = fmt.Errorf("%w: invalid retry policy", ErrInvalidPlan)
+	ErrInvalidMaxDuration    = fmt.Errorf("%w: invalid max duration", ErrInvalidPlan)
+	ErrInvalidEnv            = fmt.Errorf("%w: invalid env", ErrInvalidPlan)
+
+	ErrInvalidResourceRequests = fmt.Errorf("%w: invalid resource requests", ErrInvalidPlan)
+	ErrInvalidToleration       = fmt.Errorf("%w: invalid toleration", ErrInvalidPlan)
+	ErrInvalidClassName        = fmt.Errorf("%w: invalid class name", ErrInvalidPlan)
+	ErrInvalidScratch          = fmt.Errorf("%w: invalid scratch", ErrInvalidPlan)
+
+	ErrInvalidInputSelection = fmt.Errorf("%w: invalid input selection", ErrInvalidPlan)
+	ErrInvalidParameter      = fmt.Errorf("%w: invalid parameter", ErrInvalidPlan)
 
 	// path of mountpoint spec is not absolute or contains "../"
 	ErrBadMountpointPath = fmt.Errorf("%w: bad mountpoint path", ErrInvalidPlan)
@@ -1037,6 +1872,12 @@ var (
 
 	// if the plan is registered, plan dependencies make cycle, means it will leads infinity loop
 	ErrCyclicPlan = fmt.Errorf("%w: plan's tag dependency makes cycle", ErrConflictingPlan)
+
+	// the plan to be superseded is superseded by another plan already
+	ErrPlanSuperseded = fmt.Errorf("%w: plan is superseded already", ErrConflictingPlan)
+
+	// the plan to be deleted has runs
+	ErrPlanHasRuns = errors.New("plan has runs")
 )
 
 // already there have been a plan which has same image:version and equiverent mountpoints.
@@ -18,6 +18,7 @@ type PlanFindArgs struct {
 	ImageVer types.ImageIdentifier
 	InTag    []types.Tag
 	OutTag   []types.Tag
+	Page     types.Page
 }
 
 func (s *PlanFindArgs) Equal(d *PlanFindArgs) bool {
@@ -29,7 +30,7 @@ func (s *PlanFindArgs) Equal(d *PlanFindArgs) bool {
 		return false
 	}
 
-	return s.Active == d.Active && s.ImageVer == d.ImageVer
+	return s.Active == d.Active && s.ImageVer == d.ImageVer && s.Page == d.Page
 }
 
 type UpdateAnnotationsArgs struct {
@@ -42,26 +43,47 @@ type SetServiceAccountArgs struct {
 	ServiceAccount string
 }
 
+type UpdatePriorityArgs struct {
+	PlanId string
+	Change types.PriorityChange
+}
+
+type SupersedeArgs struct {
+	Predecessor string
+	Spec        *types.PlanSpec
+	Reroute     bool
+}
+
 type PlanInterface struct {
 	Impl struct {
-		Get                 func(context.Context, []string) (map[string]*types.Plan, error)
-		Register            func(context.Context, *types.PlanSpec) (string, error)
-		Activate            func(context.Context, string, bool) error
-		SetResourceLimit    func(context.Context, string, map[string]resource.Quantity) error
-		UnsetResourceLimit  func(context.Context, string, []string) error
-		Find                func(context.Context, logic.Ternary, types.ImageIdentifier, []types.Tag, []types.Tag) ([]string, error)
-		UpdateAnnotations   func(context.Context, string, types.AnnotationDelta) error
-		SetServiceAccount   func(context.Context, string, string) error
-		UnsetServiceAccount func(context.Context, string) error
+		Get                  func(context.Context, []string) (map[string]*types.Plan, error)
+		Register             func(context.Context, *types.PlanSpec) (string, error)
+		Preview              func(context.Context, *types.PlanSpec) ([]types.RunPreview, error)
+		Activate             func(context.Context, string, bool) error
+		SetResourceLimit     func(context.Context, string, map[string]resource.Quantity) error
+		UnsetResourceLimit   func(context.Context, string, []string) error
+		SetResourceRequest   func(context.Context, string, map[string]resource.Quantity) error
+		UnsetResourceRequest func(context.Context, string, []string) error
+		Find                 func(context.Context, logic.Ternary, types.ImageIdentifier, []types.Tag, []types.Tag, types.Page) ([]string, string, error)
+		UpdateAnnotations    func(context.Context, string, types.AnnotationDelta) error
+		SetServiceAccount    func(context.Context, string, string) error
+		UnsetServiceAccount  func(context.Context, string) error
+		UpdatePriority       func(context.Context, string, types.PriorityChange) error
+		Supersede            func(context.Context, string, *types.PlanSpec, bool) (string, error)
+		Delete               func(context.Context, string) error
 	}
 	Calls struct {
 		Get                 kdbmock.CallLog[[]string]
 		Register            kdbmock.CallLog[*types.PlanSpec]
+		Preview             kdbmock.CallLog[*types.PlanSpec]
 		Activate            kdbmock.CallLog[string]
 		Find                kdbmock.CallLog[PlanFindArgs]
 		UpdateAnnotations   kdbmock.CallLog[UpdateAnnotationsArgs]
 		SetServiceAccount   kdbmock.CallLog[SetServiceAccountArgs]
 		UnsetServiceAccount kdbmock.CallLog[string]
+		UpdatePriority      kdbmock.CallLog[UpdatePriorityArgs]
+		Supersede           kdbmock.CallLog[SupersedeArgs]
+		Delete              kdbmock.CallLog[string]
 	}
 }
 
@@ -89,6 +111,15 @@ func (m *PlanInterface) Register(ctx context.Context, spec *types.PlanSpec) (str
 	panic(errors.New("should not be called"))
 }
 
+func (m *PlanInterface) Preview(ctx context.Context, spec *types.PlanSpec) ([]types.RunPreview, error) {
+	m.Calls.Preview = append(m.Calls.Preview, spec)
+	if m.Impl.Preview != nil {
+		return m.Impl.Preview(ctx, spec)
+	}
+
+	panic(errors.New("should not be called"))
+}
+
 func (m *PlanInterface) Activate(ctx context.Context, planId string, isActive bool) error {
 	m.Calls.Activate = append(m.Calls.Activate, planId)
 	if m.Impl.Activate != nil {
@@ -114,7 +145,23 @@ func (m *PlanInterface) UnsetResourceLimit(ctx context.Context, planId string, r
 	panic(errors.New("should not be called"))
 }
 
-func (m *PlanInterface) Find(ctx context.Context, active logic.Ternary, imageVer types.ImageIdentifier, inTag []types.Tag, outTag []types.Tag) ([]string, error) {
+func (m *PlanInterface) SetResourceRequest(ctx context.Context, planId string, requests map[string]resource.Quantity) error {
+	if m.Impl.SetResourceRequest != nil {
+		return m.Impl.SetResourceRequest(ctx, planId, requests)
+	}
+
+	panic(errors.New("should not be called"))
+}
+
+func (m *PlanInterface) UnsetResourceRequest(ctx context.Context, planId string, resources []string) error {
+	if m.Impl.UnsetResourceRequest != nil {
+		return m.Impl.UnsetResourceRequest(ctx, planId, resources)
+	}
+
+	panic(errors.New("should not be called"))
+}
+
+func (m *PlanInterface) Find(ctx context.Context, active logic.Ternary, imageVer types.ImageIdentifier, inTag []types.Tag, outTag []types.Tag, page types.Page) ([]string, string, error) {
 	m.Calls.Find = append(m.Calls.Find, PlanFindArgs{
 		Active: active,
 		ImageVer: types.ImageIdentifier{
@@ -123,9 +170,10 @@ func (m *PlanInterface) Find(ctx context.Context, active logic.Ternary, imageVer
 		},
 		InTag:  inTag,
 		OutTag: outTag,
+		Page:   page,
 	})
 	if m.Impl.Find != nil {
-		return m.Impl.Find(ctx, active, imageVer, inTag, outTag)
+		return m.Impl.Find(ctx, active, imageVer, inTag, outTag, page)
 	}
 
 	panic(errors.New("should not be called"))
@@ -163,3 +211,34 @@ func (m *PlanInterface) UnsetServiceAccount(ctx context.Context, planId string)
 
 	panic(errors.New("should not be called"))
 }
+
+func (m *PlanInterface) UpdatePriority(ctx context.Context, planId string, change types.PriorityChange) error {
+	m.Calls.UpdatePriority = append(m.Calls.UpdatePriority, UpdatePriorityArgs{
+		PlanId: planId, Change: change,
+	})
+	if m.Impl.UpdatePriority != nil {
+		return m.Impl.UpdatePriority(ctx, planId, change)
+	}
+
+	panic(errors.New("should not be called"))
+}
+
+func (m *PlanInterface) Supersede(ctx context.Context, predecessor string, spec *types.PlanSpec, reroute bool) (string, error) {
+	m.Calls.Supersede = append(m.Calls.Supersede, SupersedeArgs{
+		Predecessor: predecessor, Spec: spec, Reroute: reroute,
+	})
+	if m.Impl.Supersede != nil {
+		return m.Impl.Supersede(ctx, predecessor, spec, reroute)
+	}
+
+	panic(errors.New("should not be called"))
+}
+
+func (m *PlanInterface) Delete(ctx context.Context, planId string) error {
+	m.Calls.Delete = append(m.Calls.Delete, planId)
+	if m.Impl.Delete != nil {
+		return m.Impl.Delete(ctx, planId)
+	}
+
+	panic(errors.New("should not be called"))
+}
@@ -38,6 +38,26 @@ type PlanInterface interface {
 	// - error: ErrInvalidPlan or ErrConflictingPlan
 	Register(context.Context, *types.PlanSpec) (string, error)
 
+	// Preview Runs which would be created when the plan is registered.
+	//
+	// Nothing is written; Data which would be nominated to the plan are found
+	// with read-only queries, and Runs are composed from them.
+	// Checks needing registration (e.g. cyclic dependencies) are not performed.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - *PlanSpec: specification of plan to be previewed
+	//
+	// Return
+	//
+	// - []RunPreview: Runs which would be created.
+	// Plan ids in them are empty, and Ids of Inputs are not valid.
+	//
+	// - error: same as Register, except errors needing registration.
+	Preview(context.Context, *types.PlanSpec) ([]types.RunPreview, error)
+
 	// Activate Plans by its id
 	//
 	// This method SHOULD NOT effect to pseudo plan.
@@ -89,6 +109,41 @@ type PlanInterface interface {
 	// - error
 	UnsetResourceLimit(ctx context.Context, planId string, types []string) error
 
+	// SetResourceRequest set resource requests for plan.
+	//
+	// Requests are resources which Workers of the plan request for scheduling,
+	// while SetResourceLimit sets limits of them.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: plan id to be set
+	//
+	// - map[string]resource.Quantity: resource name to its quantity.
+	// If a resource name in this map is already set, it will be updated.
+	// If a resource name in this map is not set, it will be created.
+	//
+	// Returns
+	//
+	// - error
+	SetResourceRequest(ctx context.Context, planId string, requests map[string]resource.Quantity) error
+
+	// UnsetResourceRequest unset resource requests for plan.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: plan id to be unset
+	//
+	// - []string: resource names to be unset
+	//
+	// Returns
+	//
+	// - error
+	UnsetResourceRequest(ctx context.Context, planId string, types []string) error
+
 	// Retreive plans that satisfies all the conditions specified in the following items:
 	// tags specified in mountpoint, container image and its version, activity status of plan.
 	//
@@ -109,12 +164,16 @@ type PlanInterface interface {
 	// - TagSet : tags specified in output mountpoint
 	//  retreive plans with input mountpoint containing all of the specified tags.
 	//
+	// - Page : the page of results to be retrieved. Plans are ordered by their ids.
+	//
 	// Returns
 	//
-	// - []string : found plan ids
+	// - []string : found plan ids, in the page
 	//
-	// - error
-	Find(context.Context, logic.Ternary, types.ImageIdentifier, []types.Tag, []types.Tag) ([]string, error)
+	// - string : cursor of the next page. empty if there are no more plans.
+	//
+	// - error : types.ErrInvalidCursor if the cursor of the page is not understood.
+	Find(context.Context, logic.Ternary, types.ImageIdentifier, []types.Tag, []types.Tag, types.Page) ([]string, string, error)
 
 	// UpdateAnnotations updates Annotations of a Plan.
 	//
@@ -158,4 +217,61 @@ type PlanInterface interface {
 	//
 	// - error
 	UnsetServiceAccount(ctx context.Context, planId string) error
+
+	// UpdatePriority changes priority and concurrency limit of a Plan.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string : Plan ID
+	//
+	// - PriorityChange : new priority and/or concurrency limit
+	//
+	// Returns
+	//
+	// - error : ErrMissing if the Plan is not found or is pseudo,
+	// or ErrInvalidPlan if the change is invalid.
+	UpdatePriority(ctx context.Context, planId string, change types.PriorityChange) error
+
+	// Supersede registers a new Plan as the successor of an existing Plan,
+	// and deactivates the predecessor in the same transaction.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string : Plan ID of the predecessor. It should not be a pseudo Plan.
+	//
+	// - *PlanSpec : specification of the successor
+	//
+	// - bool : reroute. If true, nominations for the predecessor, including ones
+	// not projected yet, are dropped and will not be made anymore.
+	// Data are not moved to the successor: the successor is nominated as a new Plan
+	// either way, and takes Data matching its Inputs.
+	//
+	// Returns
+	//
+	// - string : Plan ID of the successor
+	//
+	// - error : ErrMissing if the predecessor is not found,
+	// ErrPlanSuperseded if the predecessor is superseded already,
+	// or errors same as Register.
+	Supersede(ctx context.Context, predecessor string, plan *types.PlanSpec, reroute bool) (string, error)
+
+	// Delete removes a Plan which has no Runs.
+	//
+	// If the Plan is in the middle of a version chain,
+	// its successor becomes the successor of its predecessor.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string : Plan ID. It should not be a pseudo Plan.
+	//
+	// Returns
+	//
+	// - error : ErrMissing if the plan is not found, or ErrPlanHasRuns.
+	Delete(ctx context.Context, planId string) error
 }
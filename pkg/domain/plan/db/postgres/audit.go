@@ -0,0 +1,63 @@
+package plan
+
+import (
+	"context"
+
+	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
+	types "github.com/opst/knitfab/pkg/domain"
+	kpgintr "github.com/opst/knitfab/pkg/domain/internal/db/postgres"
+)
+
+// auditSnapshot returns a snapshot of mutable properties of the plan for audit log.
+//
+// When the plan is not found, it returns nil.
+func auditSnapshot(ctx context.Context, conn kpool.Queryer, planId string) (map[string]any, error) {
+	bodies, err := kpgintr.GetPlanBody(ctx, conn, []string{planId})
+	if err != nil {
+		return nil, err
+	}
+	body, ok := bodies[planId]
+	if !ok {
+		return nil, nil
+	}
+
+	snapshot := map[string]any{
+		"active":          body.Active,
+		"service_account": body.ServiceAccount,
+		"supersedes":      body.Supersedes,
+		"superseded_by":   body.SupersededBy,
+		"schedule":        body.Schedule,
+		"priority":        body.Priority,
+		"max_concurrency": body.MaxConcurrency,
+	}
+	if body.Image != nil {
+		snapshot["image"] = body.Image.String()
+	}
+
+	resources := map[string]string{}
+	for typ, q := range body.Resources {
+		resources[typ] = q.String()
+	}
+	snapshot["resources"] = resources
+
+	annotations := []string{}
+	for _, a := range body.Annotations {
+		annotations = append(annotations, a.Key+"="+a.Value)
+	}
+	snapshot["annotations"] = annotations
+
+	return snapshot, nil
+}
+
+// auditChange records the change of the plan into audit log.
+//
+// before should be a snapshot taken with auditSnapshot before the change.
+func auditChange(
+	ctx context.Context, conn kpool.Queryer, action types.AuditAction, planId string, before map[string]any,
+) error {
+	after, err := auditSnapshot(ctx, conn, planId)
+	if err != nil {
+		return err
+	}
+	return kpgintr.Audit(ctx, conn, action, types.AuditTargetPlan, planId, before, after)
+}
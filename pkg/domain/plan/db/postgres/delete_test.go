@@ -0,0 +1,319 @@
+package plan_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgnommock "github.com/opst/knitfab/pkg/domain/nomination/db/mock"
+	kpgplan "github.com/opst/knitfab/pkg/domain/plan/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// countReferrers counts rows in all tables having the column, whose value is one of ids.
+//
+// return: table name -> the number of rows. Tables without such rows are not included.
+func countReferrers[T any](ctx context.Context, t *testing.T, conn kpool.Conn, column string, ids []T) map[string]int {
+	t.Helper()
+
+	tableNames := try.To(scanner.New[string]().QueryAll(
+		ctx, conn,
+		`
+		select "columns"."table_name"
+		from "information_schema"."columns" as "columns"
+		inner join "information_schema"."tables" as "tables"
+			on "tables"."table_schema" = "columns"."table_schema"
+			and "tables"."table_name" = "columns"."table_name"
+		where "columns"."table_schema" = current_schema()
+			and "tables"."table_type" = 'BASE TABLE'
+			and "columns"."column_name" = $1
+		`,
+		column,
+	)).OrFatal(t)
+
+	counts := map[string]int{}
+	for _, table := range tableNames {
+		var n int
+		if err := conn.QueryRow(
+			ctx,
+			fmt.Sprintf(`select count(*) from "%s" where "%s" = any($1)`, table, column),
+			ids,
+		).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if 0 < n {
+			counts[table] = n
+		}
+	}
+	return counts
+}
+
+func TestPlan_Delete(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	newTestee := func(t *testing.T, pool kpool.Pool) interface {
+		Register(context.Context, *domain.PlanSpec) (string, error)
+		Supersede(context.Context, string, *domain.PlanSpec, bool) (string, error)
+		Delete(context.Context, string) error
+	} {
+		nomi := kpgnommock.New(t)
+		nomi.Impl.NominateMountpoints = func(ctx context.Context, conn kpool.Tx, mountpointIds []int) error {
+			return nil
+		}
+		return kpgplan.New(pool, kpgplan.WithNominator(nomi))
+	}
+
+	t.Run("it removes the plan and all rows depending on it", func(t *testing.T) {
+		ctx := domain.WithActor(context.Background(), "user@example.invalid")
+		pool := poolBroaker.GetPool(ctx, t)
+
+		given := givenPlanWithNomination()
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := newTestee(t, pool)
+		planId := try.To(testee.Register(ctx, domain.BypassValidation(
+			th.Padding64("#to-be-deleted"), nil,
+			domain.PlanParam{
+				Image: "repo.invalid/image", Version: "v2", Active: true,
+				Entrypoint: []string{"python", "main.py"},
+				Args:       []string{"--lr", "/in/lr"},
+				Inputs: []domain.MountPointParam{
+					{
+						Path:      "/in",
+						Tags:      domain.NewTagSet([]domain.Tag{{Key: "type", Value: "dataset"}}),
+						Selection: domain.InputSelection{Latest: 1},
+					},
+					{
+						Path:   "/in/lr",
+						Tags:   domain.NewTagSet([]domain.Tag{{Key: "type", Value: "lr"}}),
+						Values: []string{"0.1", "0.01"},
+					},
+				},
+				Outputs: []domain.MountPointParam{
+					{
+						Path: "/out",
+						Tags: domain.NewTagSet([]domain.Tag{{Key: "type", Value: "model"}}),
+					},
+				},
+				Log: &domain.LogParam{
+					Tags: domain.NewTagSet([]domain.Tag{{Key: "type", Value: "log"}}),
+				},
+				OnNode: []domain.OnNode{
+					{Mode: domain.MustOnNode, Key: "accelerator", Value: "gpu"},
+				},
+				Resources: map[string]resource.Quantity{
+					"cpu": resource.MustParse("2"),
+				},
+				ResourceRequests: map[string]resource.Quantity{
+					"cpu": resource.MustParse("1"),
+				},
+				ServiceAccount: "service-account",
+				Annotations:    []domain.Annotation{{Key: "key", Value: "value"}},
+				Schedule:       "0 0 * * *",
+				Priority:       1,
+				MaxConcurrency: 2,
+				Retry:          &domain.RetryPolicy{MaxAttempts: 3, Backoff: time.Minute},
+				MaxDuration:    time.Hour,
+				Env:            []domain.EnvVar{{Name: "MODE", Value: "train"}},
+				Tolerations: []domain.Toleration{
+					{Key: "gpu", Operator: domain.TolerationOpExists, Effect: domain.TaintEffectNoSchedule},
+				},
+				PriorityClassName: "high",
+				RuntimeClassName:  "nvidia",
+				Scratches: []domain.ScratchVolume{
+					{Path: "/work", SizeLimit: resource.MustParse("1Gi")},
+				},
+				ShmSize: resource.MustParse("64Mi"),
+			},
+		))).OrFatal(t)
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+
+		inputIds := try.To(scanner.New[int]().QueryAll(
+			ctx, conn, `select "input_id" from "input" where "plan_id" = $1`, planId,
+		)).OrFatal(t)
+		outputIds := try.To(scanner.New[int]().QueryAll(
+			ctx, conn, `select "output_id" from "output" where "plan_id" = $1`, planId,
+		)).OrFatal(t)
+		if _, err := conn.Exec(
+			ctx,
+			`
+			insert into "nomination" ("knit_id", "input_id", "updated")
+			select $1, "input_id", false from "input"
+			where "plan_id" = $2 and "path" = '/in'
+			`,
+			th.Padding36("knit-1"), planId,
+		); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := testee.Delete(ctx, planId); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, column := range []string{"plan_id", "supersedes"} {
+			if actual := countReferrers(ctx, t, conn, column, []string{planId}); len(actual) != 0 {
+				t.Errorf("rows referring %s = %s are left: %v", column, planId, actual)
+			}
+		}
+		for column, ids := range map[string][]int{
+			"input_id":  inputIds,
+			"output_id": outputIds,
+		} {
+			if actual := countReferrers(ctx, t, conn, column, ids); len(actual) != 0 {
+				t.Errorf("rows referring %s in %v are left: %v", column, ids, actual)
+			}
+		}
+
+		// other plans are not affected.
+		{
+			actual := try.To(scanner.New[tables.Nomination]().QueryAll(
+				ctx, conn, `table "nomination"`,
+			)).OrFatal(t)
+			if !cmp.SliceContentEq(actual, given.Nomination) {
+				t.Errorf("nominations: actual = %+v, expected = %+v", actual, given.Nomination)
+			}
+		}
+
+		{
+			actions := try.To(scanner.New[string]().QueryAll(
+				ctx, conn,
+				`select "action" from "audit_log" where "target_id" = $1 order by "id"`,
+				planId,
+			)).OrFatal(t)
+			expected := []string{string(domain.AuditPlanRegister), string(domain.AuditPlanDelete)}
+			if !cmp.SliceEq(actions, expected) {
+				t.Errorf("audit log: actual = %v, expected = %v", actions, expected)
+			}
+		}
+	})
+
+	t.Run("when the plan has runs, it returns ErrPlanHasRuns and keeps the plan", func(t *testing.T) {
+		ctx := context.Background()
+		pool := poolBroaker.GetPool(ctx, t)
+
+		given := givenPlanWithNomination()
+		given.Steps = append(given.Steps, tables.Step{
+			Run: tables.Run{
+				RunId: th.Padding36("run-plan"), PlanId: th.Padding36("plan"), Status: domain.Done,
+				UpdatedAt: time.Now(), LifecycleSuspendUntil: time.Now(),
+			},
+		})
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := newTestee(t, pool)
+		if err := testee.Delete(ctx, th.Padding36("plan")); !errors.Is(err, domain.ErrPlanHasRuns) {
+			t.Errorf("error: actual = %v, expected = %v", err, domain.ErrPlanHasRuns)
+		}
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+
+		planIds := try.To(scanner.New[string]().QueryAll(
+			ctx, conn, `select "plan_id" from "plan_image"`,
+		)).OrFatal(t)
+		if !cmp.SliceEq(planIds, []string{th.Padding36("plan")}) {
+			t.Errorf("plans with image: %v", planIds)
+		}
+		nominations := try.To(scanner.New[tables.Nomination]().QueryAll(
+			ctx, conn, `table "nomination"`,
+		)).OrFatal(t)
+		if !cmp.SliceContentEq(nominations, given.Nomination) {
+			t.Errorf("nominations: actual = %+v, expected = %+v", nominations, given.Nomination)
+		}
+	})
+
+	for name, planId := range map[string]string{
+		"when the plan is not found, it returns ErrMissing":     th.Padding36("no-such-plan"),
+		"when the plan is a pseudo plan, it returns ErrMissing": th.Padding36("pseudo"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			given := givenPlanWithNomination()
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			testee := newTestee(t, pool)
+			if err := testee.Delete(ctx, planId); !errors.Is(err, kerr.ErrMissing) {
+				t.Errorf("error: actual = %v, expected = %v", err, kerr.ErrMissing)
+			}
+		})
+	}
+
+	type Supersede struct {
+		PlanId     string
+		Supersedes string
+		Reroute    bool
+	}
+
+	// the chain is: plan <- v2 <- v3
+	chainTheory := func(deleted func(v2, v3 string) string, expected func(v2, v3 string) []Supersede) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			given := givenPlanWithNomination()
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			testee := newTestee(t, pool)
+			v2 := try.To(testee.Supersede(ctx, th.Padding36("plan"), successorSpec("v2"), false)).OrFatal(t)
+			v3 := try.To(testee.Supersede(ctx, v2, successorSpec("v3"), true)).OrFatal(t)
+
+			if err := testee.Delete(ctx, deleted(v2, v3)); err != nil {
+				t.Fatal(err)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			actual := try.To(scanner.New[Supersede]().QueryAll(
+				ctx, conn, `table "plan_supersede"`,
+			)).OrFatal(t)
+			if want := expected(v2, v3); !cmp.SliceContentEq(actual, want) {
+				t.Errorf("plan_supersede: actual = %+v, expected = %+v", actual, want)
+			}
+		}
+	}
+
+	t.Run("when a plan in the middle of a chain is deleted, its successor supersedes its predecessor", chainTheory(
+		func(v2, v3 string) string { return v2 },
+		func(v2, v3 string) []Supersede {
+			return []Supersede{{PlanId: v3, Supersedes: th.Padding36("plan"), Reroute: true}}
+		},
+	))
+
+	t.Run("when the head of a chain is deleted, the rest of the chain is kept", chainTheory(
+		func(v2, v3 string) string { return th.Padding36("plan") },
+		func(v2, v3 string) []Supersede {
+			return []Supersede{{PlanId: v3, Supersedes: v2, Reroute: true}}
+		},
+	))
+
+	t.Run("when the tail of a chain is deleted, the rest of the chain is kept", chainTheory(
+		func(v2, v3 string) string { return v3 },
+		func(v2, v3 string) []Supersede {
+			return []Supersede{{PlanId: v2, Supersedes: th.Padding36("plan"), Reroute: false}}
+		},
+	))
+}
@@ -0,0 +1,99 @@
+package plan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestPlan_Env(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	type Env struct {
+		Position      int
+		Name          string
+		Value         *string
+		SecretName    *string
+		SecretKey     *string
+		ConfigMapName *string
+		ConfigMapKey  *string
+	}
+	eq := func(a, b Env) bool {
+		return a.Position == b.Position && a.Name == b.Name &&
+			cmp.PEqEq(a.Value, b.Value) &&
+			cmp.PEqEq(a.SecretName, b.SecretName) && cmp.PEqEq(a.SecretKey, b.SecretKey) &&
+			cmp.PEqEq(a.ConfigMapName, b.ConfigMapName) && cmp.PEqEq(a.ConfigMapKey, b.ConfigMapKey)
+	}
+	ref := func(s string) *string { return &s }
+
+	given := givenRegisteredPlan()
+
+	type When struct {
+		env []domain.EnvVar
+	}
+	type Then struct {
+		// records of the registered Plan in "plan_env".
+		env []Env
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			plan := registerAndGet(ctx, t, pool, given, specWith("#env", func(pp *domain.PlanParam) {
+				pp.Env = when.env
+			}))
+
+			if !cmp.SliceEqWith(plan.Env, when.env, domain.EnvVar.Equal) {
+				t.Errorf("env: actual = %+v, expected = %+v", plan.Env, when.env)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			actual := try.To(scanner.New[Env]().QueryAll(
+				ctx, conn,
+				`
+				select
+					"position", "name", "value",
+					"secret_name", "secret_key", "config_map_name", "config_map_key"
+				from "plan_env" where "plan_id" = $1
+				`,
+				plan.PlanId,
+			)).OrFatal(t)
+			if !cmp.SliceContentEqWith(actual, then.env, eq) {
+				t.Errorf("plan_env: actual = %+v, expected = %+v", actual, then.env)
+			}
+		}
+	}
+
+	t.Run("when a plan with env is registered, they are recorded in declared order", theory(
+		When{
+			env: []domain.EnvVar{
+				{Name: "MODE", Value: "train"},
+				{Name: "API_TOKEN", Secret: &domain.EnvVarKeyRef{Name: "credentials", Key: "token"}},
+				{Name: "LOG_LEVEL", ConfigMap: &domain.EnvVarKeyRef{Name: "settings", Key: "log-level"}},
+				{Name: "EMPTY", Value: ""},
+			},
+		},
+		Then{
+			env: []Env{
+				{Position: 0, Name: "MODE", Value: ref("train")},
+				{Position: 1, Name: "API_TOKEN", SecretName: ref("credentials"), SecretKey: ref("token")},
+				{Position: 2, Name: "LOG_LEVEL", ConfigMapName: ref("settings"), ConfigMapKey: ref("log-level")},
+				{Position: 3, Name: "EMPTY", Value: ref("")},
+			},
+		},
+	))
+
+	t.Run("when a plan without env is registered, no env are recorded", theory(
+		When{env: nil},
+		Then{env: []Env{}},
+	))
+}
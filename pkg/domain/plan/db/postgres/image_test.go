@@ -0,0 +1,71 @@
+package plan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestPlan_ImageDigest(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	DIGEST := "sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	given := givenRegisteredPlan()
+
+	type When struct {
+		version string
+	}
+	type Then struct {
+		// reference of the image of the registered Plan.
+		ref string
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			plan := registerAndGet(ctx, t, pool, given, specWith("#image-"+when.version, func(pp *domain.PlanParam) {
+				pp.Version = when.version
+			}))
+
+			expected := &domain.ImageIdentifier{Image: "repo.invalid/image", Version: when.version}
+			if !plan.Image.Equal(expected) {
+				t.Errorf("image: actual = %+v, expected = %+v", plan.Image, expected)
+			}
+			if actual := plan.Image.String(); actual != then.ref {
+				t.Errorf("image reference: actual = %s, expected = %s", actual, then.ref)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			actual := try.To(scanner.New[tables.PlanImage]().QueryAll(
+				ctx, conn, `table "plan_image"`,
+			)).OrFatal(t)
+			expectedRecords := append([]tables.PlanImage{
+				{PlanId: plan.PlanId, Image: "repo.invalid/image", Version: when.version},
+			}, given.PlanImage...)
+			if !cmp.SliceContentEq(actual, expectedRecords) {
+				t.Errorf("plan_image: actual = %+v, expected = %+v", actual, expectedRecords)
+			}
+		}
+	}
+
+	t.Run("when a plan pinned by digest is registered, the digest is recorded as its version", theory(
+		When{version: DIGEST},
+		Then{ref: "repo.invalid/image@" + DIGEST},
+	))
+
+	t.Run("when a plan with tag is registered, the tag is recorded as its version", theory(
+		When{version: "v1"},
+		Then{ref: "repo.invalid/image:v1"},
+	))
+}
@@ -0,0 +1,64 @@
+package plan_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestPlan_MaxDuration(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	given := givenRegisteredPlan()
+
+	type When struct {
+		maxDuration time.Duration
+	}
+	type Then struct {
+		// seconds recorded for the registered Plan.
+		seconds []int
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			plan := registerAndGet(ctx, t, pool, given, specWith("#max-duration", func(pp *domain.PlanParam) {
+				pp.MaxDuration = when.maxDuration
+			}))
+
+			if plan.MaxDuration != when.maxDuration {
+				t.Errorf("max duration: actual = %s, expected = %s", plan.MaxDuration, when.maxDuration)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			actual := try.To(scanner.New[int]().QueryAll(
+				ctx, conn,
+				`select "seconds" from "plan_max_duration" where "plan_id" = $1`,
+				plan.PlanId,
+			)).OrFatal(t)
+			if !cmp.SliceEq(actual, then.seconds) {
+				t.Errorf("plan_max_duration: actual = %v, expected = %v", actual, then.seconds)
+			}
+		}
+	}
+
+	t.Run("when a plan with max duration is registered, it is recorded in seconds", theory(
+		When{maxDuration: 90 * time.Minute},
+		Then{seconds: []int{5400}},
+	))
+
+	t.Run("when a plan without max duration is registered, nothing is recorded", theory(
+		When{maxDuration: 0},
+		Then{seconds: []int{}},
+	))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v4"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -16,6 +17,7 @@ import (
 	kpgintr "github.com/opst/knitfab/pkg/domain/internal/db/postgres"
 	kpgnom "github.com/opst/knitfab/pkg/domain/nomination/db/postgres"
 	xe "github.com/opst/knitfab/pkg/errors"
+	"github.com/opst/knitfab/pkg/utils/cron"
 	"github.com/opst/knitfab/pkg/utils/logic"
 	"github.com/opst/knitfab/pkg/utils/slices"
 )
@@ -73,6 +75,100 @@ func (m *planPG) Register(ctx context.Context, plan *types.PlanSpec) (string, er
 	}
 	defer tx.Rollback(ctx)
 
+	created, err := m.register(ctx, tx, plan)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return created, nil
+}
+
+func (m *planPG) Preview(ctx context.Context, plan *types.PlanSpec) ([]types.RunPreview, error) {
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := m.pool.BeginTx(
+		ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly},
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// step1: make sure that there are no equivarent plans, like register.
+	planHashConflicted, err := m.getPlansByHash(ctx, tx, plan.Hash())
+	if err != nil {
+		return nil, xe.Wrap(err)
+	}
+	if found, ok := slices.First(
+		planHashConflicted,
+		func(p types.Plan) bool { return plan.EquivPlan(&p) },
+	); ok {
+		return nil, xe.Wrap(types.NewErrEquivPlanExists(found.PlanId))
+	}
+
+	// step2: find Data to be nominated to Inputs.
+	//
+	// The plan is not registered, so Inputs are identified by their index.
+	inputs := map[int]types.MountPoint{}
+	nominations := map[int][]string{}
+	for nth, in := range plan.Inputs() {
+		inputs[nth] = types.MountPoint{Path: in.Path, Tags: in.Tags}
+		if _, ok := slices.First(
+			in.Tags.SystemTag(), func(t types.Tag) bool { return t.Key == types.KeyKnitTransient },
+		); ok {
+			return nil, types.NewErrUnacceptableTag(
+				in.Path, `data with "knit#transient" is never used for input tag`,
+			)
+		}
+		if 0 < len(in.Values) {
+			// Data of parameter Inputs are materialised after the plan is registered.
+			continue
+		}
+
+		cond, err := kpgnom.ConditionOf(in.Tags, in.Selection)
+		if err != nil {
+			return nil, err
+		}
+		knitIds, err := m.nominator.Match(ctx, tx, cond, nil)
+		if err != nil {
+			return nil, err
+		}
+		nominations[nth] = knitIds
+	}
+
+	// step3: all nominations are new for the plan.
+	body := types.PlanBody{
+		Hash:              plan.Hash(),
+		Active:            plan.Active(),
+		Image:             &types.ImageIdentifier{Image: plan.Image(), Version: plan.Version()},
+		Entrypoint:        plan.Entrypoint(),
+		Args:              plan.Args(),
+		Resources:         plan.Resources(),
+		ResourceRequests:  plan.ResourceRequests(),
+		OnNode:            plan.OnNode(),
+		Tolerations:       plan.Tolerations(),
+		PriorityClassName: plan.PriorityClassName(),
+		RuntimeClassName:  plan.RuntimeClassName(),
+		Scratches:         plan.Scratches(),
+		ShmSize:           plan.ShmSize(),
+		ServiceAccount:    plan.ServiceAccount(),
+		Annotations:       plan.Annotations(),
+		Schedule:          plan.Schedule(),
+		Priority:          plan.Priority(),
+		MaxConcurrency:    plan.MaxConcurrency(),
+		Retry:             plan.Retry(),
+		MaxDuration:       plan.MaxDuration(),
+		Env:               plan.Env(),
+	}
+	return kpgintr.PreviewProjection(ctx, tx, body, inputs, nominations, nominations)
+}
+
+func (m *planPG) register(ctx context.Context, tx kpool.Tx, plan *types.PlanSpec) (string, error) {
 	if _, err := tx.Exec(ctx, `lock table "plan" in EXCLUSIVE mode;`); err != nil {
 		return "", err
 	}
@@ -105,9 +201,21 @@ func (m *planPG) Register(ctx context.Context, plan *types.PlanSpec) (string, er
 		return "", xe.Wrap(err)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return "", err
+	// step4. record who registered it, for quotas scoped by users.
+	if actor := types.ActorOf(ctx); actor != types.ActorSystem {
+		if _, err := tx.Exec(
+			ctx,
+			`insert into "plan_owner" ("plan_id", "owner") values ($1, $2)`,
+			created, actor,
+		); err != nil {
+			return "", xe.Wrap(err)
+		}
+	}
+
+	if err := auditChange(ctx, tx, types.AuditPlanRegister, created, nil); err != nil {
+		return "", xe.Wrap(err)
 	}
+
 	return created, nil
 }
 
@@ -122,10 +230,19 @@ func (m *planPG) Activate(ctx context.Context, planId string, activenessToBe boo
 	}
 	defer tx.Rollback(ctx)
 
+	before, err := auditSnapshot(ctx, tx, planId)
+	if err != nil {
+		return err
+	}
+
 	if err := m.activate(ctx, tx, planId, activenessToBe); err != nil {
 		return err
 	}
 
+	if err := auditChange(ctx, tx, types.AuditPlanActivate, planId, before); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
@@ -417,6 +534,168 @@ func registerPlan(ctx context.Context, tx kpool.Tx, plan *types.PlanSpec) (strin
 				return "", xe.Wrap(err)
 			}
 		}
+
+		if expr := plan.Schedule(); expr != "" {
+			schedule, err := cron.Parse(expr)
+			if err != nil {
+				return "", xe.Wrap(err)
+			}
+			if _, err := tx.Exec(
+				ctx,
+				`
+				insert into "plan_schedule" ("plan_id", "schedule", "next")
+				values ($1, $2, $3)
+				`,
+				planId, schedule.String(), schedule.Next(time.Now()),
+			); err != nil {
+				return "", xe.Wrap(err)
+			}
+		}
+
+		if priority, maxConcurrency := plan.Priority(), plan.MaxConcurrency(); priority != 0 || maxConcurrency != 0 {
+			if _, err := tx.Exec(
+				ctx,
+				`
+				insert into "plan_priority" ("plan_id", "priority", "max_concurrency")
+				values ($1, $2, nullif($3, 0))
+				`,
+				planId, priority, maxConcurrency,
+			); err != nil {
+				return "", xe.Wrap(err)
+			}
+		}
+
+		if retry := plan.Retry(); retry != nil {
+			if _, err := tx.Exec(
+				ctx,
+				`
+				insert into "plan_retry"
+					("plan_id", "max_attempts", "backoff_seconds", "retry_on", "exit_codes")
+				values ($1, $2, $3, $4, $5)
+				`,
+				planId, retry.MaxAttempts, int(retry.Backoff/time.Second),
+				slices.Map(retry.On, func(c types.ExitCause) string { return string(c) }),
+				slices.Map(retry.ExitCodes, func(c uint8) int16 { return int16(c) }),
+			); err != nil {
+				return "", xe.Wrap(err)
+			}
+		}
+
+		if maxDuration := plan.MaxDuration(); maxDuration != 0 {
+			if _, err := tx.Exec(
+				ctx,
+				`
+				insert into "plan_max_duration" ("plan_id", "seconds")
+				values ($1, $2)
+				`,
+				planId, int(maxDuration/time.Second),
+			); err != nil {
+				return "", xe.Wrap(err)
+			}
+		}
+
+		if requests := plan.ResourceRequests(); 0 < len(requests) {
+			requestTypes := []string{}
+			requestValues := []kpgintr.ResourceQuantity{}
+			for typ, val := range requests {
+				requestTypes = append(requestTypes, typ)
+				requestValues = append(requestValues, kpgintr.ResourceQuantity(val))
+			}
+			if _, err := tx.Exec(
+				ctx,
+				`
+				insert into "plan_resource_request" ("plan_id", "type", "value")
+				select $1, unnest($2::varchar[]), unnest($3::varchar[])
+				`,
+				planId, requestTypes, requestValues,
+			); err != nil {
+				return "", xe.Wrap(err)
+			}
+		}
+
+		for i, t := range plan.Tolerations() {
+			operator := t.Operator
+			if operator == "" {
+				operator = types.TolerationOpEqual
+			}
+			if _, err := tx.Exec(
+				ctx,
+				`
+				insert into "plan_toleration" ("plan_id", "position", "key", "operator", "value", "effect")
+				values ($1, $2, $3, $4, $5, $6)
+				`,
+				planId, i, t.Key, string(operator), t.Value, string(t.Effect),
+			); err != nil {
+				return "", xe.Wrap(err)
+			}
+		}
+
+		if pc, rc := plan.PriorityClassName(), plan.RuntimeClassName(); pc != "" || rc != "" {
+			if _, err := tx.Exec(
+				ctx,
+				`
+				insert into "plan_pod_class" ("plan_id", "priority_class_name", "runtime_class_name")
+				values ($1, $2, $3)
+				`,
+				planId, pc, rc,
+			); err != nil {
+				return "", xe.Wrap(err)
+			}
+		}
+
+		for _, sc := range plan.Scratches() {
+			var sizeLimit *kpgintr.ResourceQuantity
+			if !sc.SizeLimit.IsZero() {
+				q := kpgintr.ResourceQuantity(sc.SizeLimit)
+				sizeLimit = &q
+			}
+			if _, err := tx.Exec(
+				ctx,
+				`
+				insert into "plan_scratch" ("plan_id", "path", "size_limit", "in_memory")
+				values ($1, $2, $3, $4)
+				`,
+				planId, sc.Path, sizeLimit, sc.InMemory,
+			); err != nil {
+				return "", xe.Wrap(err)
+			}
+		}
+
+		if shm := plan.ShmSize(); !shm.IsZero() {
+			if _, err := tx.Exec(
+				ctx,
+				`insert into "plan_shm" ("plan_id", "size") values ($1, $2)`,
+				planId, kpgintr.ResourceQuantity(shm),
+			); err != nil {
+				return "", xe.Wrap(err)
+			}
+		}
+
+		for i, env := range plan.Env() {
+			var value, secretName, secretKey, configMapName, configMapKey *string
+			switch {
+			case env.Secret != nil:
+				secretName, secretKey = &env.Secret.Name, &env.Secret.Key
+			case env.ConfigMap != nil:
+				configMapName, configMapKey = &env.ConfigMap.Name, &env.ConfigMap.Key
+			default:
+				value = &env.Value
+			}
+			if _, err := tx.Exec(
+				ctx,
+				`
+				insert into "plan_env" (
+					"plan_id", "position", "name", "value",
+					"secret_name", "secret_key", "config_map_name", "config_map_key"
+				)
+				values ($1, $2, $3, $4, $5, $6, $7, $8)
+				`,
+				planId, i, env.Name, value,
+				secretName, secretKey, configMapName, configMapKey,
+			); err != nil {
+				return "", xe.Wrap(err)
+			}
+		}
 		return
 	}
 
@@ -606,6 +885,24 @@ func registerPlan(ctx context.Context, tx kpool.Tx, plan *types.PlanSpec) (strin
 		if err != nil {
 			return "", mountpointIds{}, err
 		}
+		if !mp.Selection.IsAll() {
+			if _, err := tx.Exec(
+				ctx,
+				`insert into "input_selection" ("input_id", "latest") values ($1, $2)`,
+				mpid, mp.Selection.Latest,
+			); err != nil {
+				return "", mountpointIds{}, err
+			}
+		}
+		for _, v := range mp.Values {
+			if _, err := tx.Exec(
+				ctx,
+				`insert into "parameter_input" ("input_id", "value") values ($1, $2)`,
+				mpid, v,
+			); err != nil {
+				return "", mountpointIds{}, err
+			}
+		}
 		mountpoints.Inputs = append(mountpoints.Inputs, mpid)
 	}
 
@@ -629,16 +926,39 @@ func registerPlan(ctx context.Context, tx kpool.Tx, plan *types.PlanSpec) (strin
 }
 
 func (m *planPG) SetResourceLimit(ctx context.Context, planId string, resource map[string]resource.Quantity) error {
+	return m.setResource(ctx, planId, "plan_resource", resource)
+}
+
+func (m *planPG) UnsetResourceLimit(ctx context.Context, planId string, resourceType []string) error {
+	return m.unsetResource(ctx, planId, "plan_resource", resourceType)
+}
+
+func (m *planPG) SetResourceRequest(ctx context.Context, planId string, resource map[string]resource.Quantity) error {
+	return m.setResource(ctx, planId, "plan_resource_request", resource)
+}
+
+func (m *planPG) UnsetResourceRequest(ctx context.Context, planId string, resourceType []string) error {
+	return m.unsetResource(ctx, planId, "plan_resource_request", resourceType)
+}
+
+// setResource upserts resources of the plan into the table,
+// which is "plan_resource" (limits) or "plan_resource_request" (requests).
+func (m *planPG) setResource(ctx context.Context, planId string, table string, resource map[string]resource.Quantity) error {
 	tx, err := m.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	types := []string{}
+	before, err := auditSnapshot(ctx, tx, planId)
+	if err != nil {
+		return err
+	}
+
+	resourceTypes := []string{}
 	quants := []kpgintr.ResourceQuantity{}
 	for typ, val := range resource {
-		types = append(types, typ)
+		resourceTypes = append(resourceTypes, typ)
 		quant := kpgintr.ResourceQuantity(val)
 		quants = append(quants, quant)
 	}
@@ -646,19 +966,22 @@ func (m *planPG) SetResourceLimit(ctx context.Context, planId string, resource m
 	found := 0
 	if err := tx.QueryRow(
 		ctx,
-		`
-		with "plan" as (
-			select "plan_id" from "plan" where "plan_id" = $1
+		fmt.Sprintf(
+			`
+			with "plan" as (
+				select "plan_id" from "plan" where "plan_id" = $1
+			),
+			"ins" as (
+				insert into %s ("plan_id", "type", "value")
+				select "plan_id", unnest($2::varchar[]) as "type", unnest($3::varchar[]) as "value"
+				from "plan"
+				on conflict ("plan_id", "type") do update set "value" = excluded."value"
+			)
+			select count("plan_id") from "plan"
+			`,
+			pgx.Identifier{table}.Sanitize(),
 		),
-		"ins" as (
-			insert into "plan_resource" ("plan_id", "type", "value")
-			select "plan_id", unnest($2::varchar[]) as "type", unnest($3::varchar[]) as "value"
-			from "plan"
-			on conflict ("plan_id", "type") do update set "value" = excluded."value"
-		)
-		select count("plan_id") from "plan"
-		`,
-		planId, types, quants,
+		planId, resourceTypes, quants,
 	).Scan(&found); err != nil {
 		return err
 	}
@@ -670,33 +993,47 @@ func (m *planPG) SetResourceLimit(ctx context.Context, planId string, resource m
 		}
 	}
 
+	if err := auditChange(ctx, tx, types.AuditPlanResource, planId, before); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (m *planPG) UnsetResourceLimit(ctx context.Context, planId string, resourceType []string) error {
+// unsetResource deletes resources of the plan from the table,
+// which is "plan_resource" (limits) or "plan_resource_request" (requests).
+func (m *planPG) unsetResource(ctx context.Context, planId string, table string, resourceType []string) error {
 	tx, err := m.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
+	before, err := auditSnapshot(ctx, tx, planId)
+	if err != nil {
+		return err
+	}
+
 	found := 0
 	if err := tx.QueryRow(
 		ctx,
-		`
-		with "plan" as (
-			select "plan_id" from "plan" where "plan_id" = $1
-			for update of "plan"
+		fmt.Sprintf(
+			`
+			with "plan" as (
+				select "plan_id" from "plan" where "plan_id" = $1
+				for update of "plan"
+			),
+			"del" as (
+				delete from %s
+				where "plan_id" in (select "plan_id" from "plan") and "type" = any($2)
+			)
+			select count("plan_id") from "plan"
+			`,
+			pgx.Identifier{table}.Sanitize(),
 		),
-		"del" as (
-			delete from "plan_resource"
-			where "plan_id" in (select "plan_id" from "plan") and "type" = any($2)
-		)
-		select count("plan_id") from "plan"
-		`,
 		planId, resourceType,
 	).Scan(&found); err != nil {
 		return err
@@ -708,6 +1045,10 @@ func (m *planPG) UnsetResourceLimit(ctx context.Context, planId string, resource
 		}
 	}
 
+	if err := auditChange(ctx, tx, types.AuditPlanResource, planId, before); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
@@ -849,13 +1190,18 @@ func planDependencyIsCyclic(ctx context.Context, conn kpool.Queryer, plan string
 	return nil
 }
 
-func (m *planPG) Find(ctx context.Context, active logic.Ternary, imageVer types.ImageIdentifier, inTag []types.Tag, outTag []types.Tag) ([]string, error) {
+func (m *planPG) Find(ctx context.Context, active logic.Ternary, imageVer types.ImageIdentifier, inTag []types.Tag, outTag []types.Tag, page types.Page) ([]string, string, error) {
 
 	// "in_tag" and "out_tag" are deduplicated by the normalization of TagSet.
 
+	cursor, err := kpgintr.ParseCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
 	conn, err := m.pool.Acquire(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer conn.Release()
 
@@ -864,9 +1210,9 @@ func (m *planPG) Find(ctx context.Context, active logic.Ternary, imageVer types.
 		// When nil returns, it returns an empty list
 		// because it is known that
 		// there is no corresponding plan for the specified tag combination.
-		return []string{}, nil
+		return []string{}, "", nil
 	}
-	return m.find(ctx, conn, active, imageVer, *tagQuery)
+	return m.find(ctx, conn, active, imageVer, *tagQuery, page, cursor)
 }
 
 func (m *planPG) makeTagQuery(inTag []types.Tag, outTag []types.Tag) *findTagQuery {
@@ -943,10 +1289,18 @@ func (m *planPG) find(
 	active logic.Ternary,
 	imageVer types.ImageIdentifier,
 	tagQuery findTagQuery,
-) ([]string, error) {
+	page types.Page,
+	cursor *kpgintr.Cursor,
+) ([]string, string, error) {
+	var afterPlanId *string
+	if cursor != nil {
+		afterPlanId = &cursor.Id
+	}
+	op, dir := kpgintr.PageDirection(page.Order)
+
 	rows, err := conn.Query(
 		ctx,
-		`
+		fmt.Sprintf(`
 		with
 		"required_output_tags" as (
 			select distinct "tag"."id" as "tag_id"
@@ -1023,24 +1377,30 @@ func (m *planPG) find(
 			where
 				"input_id" in (select "input_id" from "ii")
 				or ($7::varchar is null and $8::timestamp with time zone is null)
+		),
+		"found" as (
+			select "plan_id" from "plan"
+			intersect
+			select "plan_id" from "output_match"
+			intersect
+			select "plan_id" from "input_match_user_tag"
+			intersect
+			select "plan_id" from "input_match_system_tag"
 		)
-		select "plan_id" from "plan"
-		intersect
-		select "plan_id" from "output_match"
-		intersect
-		select "plan_id" from "input_match_user_tag"
-		intersect
-		select "plan_id" from "input_match_system_tag"
-		order by "plan_id"
-		`,
+		select "plan_id" from "found"
+		where $9::varchar is null or "plan_id" %[1]s $9::varchar
+		order by "plan_id" %[2]s
+		limit $10
+		`, op, dir),
 		slices.Map(tagQuery.outUserTag, func(v types.Tag) [2]string { return [2]string{v.Key, v.Value} }),
 		slices.Map(tagQuery.inUserTag, func(v types.Tag) [2]string { return [2]string{v.Key, v.Value} }),
 		imageVer.Image, imageVer.Version,
 		(active == logic.Indeterminate), (active == logic.True),
 		tagQuery.inSysKnitId, tagQuery.inSysTimestamp,
+		afterPlanId, kpgintr.PageLimit(page),
 	)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
@@ -1048,12 +1408,16 @@ func (m *planPG) find(
 	for rows.Next() {
 		var p string
 		if err := rows.Scan(&p); err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		planIds = append(planIds, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
 
-	return planIds, nil
+	planIds, next := kpgintr.NextPage(page, planIds, func(p string) kpgintr.Cursor { return kpgintr.Cursor{Id: p} })
+	return planIds, next, nil
 }
 
 func (m *planPG) UpdateAnnotations(ctx context.Context, planId string, delta types.AnnotationDelta) error {
@@ -1063,6 +1427,11 @@ func (m *planPG) UpdateAnnotations(ctx context.Context, planId string, delta typ
 	}
 	defer tx.Rollback(ctx)
 
+	before, err := auditSnapshot(ctx, tx, planId)
+	if err != nil {
+		return err
+	}
+
 	{ // check plan existence
 		found := 0
 		if err := tx.QueryRow(
@@ -1165,6 +1534,10 @@ on conflict do nothing
 		}
 	}
 
+	if err := auditChange(ctx, tx, types.AuditPlanAnnotation, planId, before); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
@@ -1178,6 +1551,11 @@ func (m *planPG) SetServiceAccount(ctx context.Context, planId string, serviceAc
 	}
 	defer tx.Rollback(ctx)
 
+	before, err := auditSnapshot(ctx, tx, planId)
+	if err != nil {
+		return err
+	}
+
 	err = tx.QueryRow(
 		ctx,
 		`
@@ -1202,12 +1580,71 @@ func (m *planPG) SetServiceAccount(ctx context.Context, planId string, serviceAc
 		return err
 	}
 
+	if err := auditChange(ctx, tx, types.AuditPlanServiceAccount, planId, before); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
 	return nil
 }
 
+func (m *planPG) UpdatePriority(ctx context.Context, planId string, change types.PriorityChange) error {
+	if err := change.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := auditSnapshot(ctx, tx, planId)
+	if err != nil {
+		return err
+	}
+
+	// pseudo plans are not started by workers. they are treated as missing.
+	if err := tx.QueryRow(
+		ctx,
+		`select "plan_id" from "plan_image" where "plan_id" = $1 for key share`,
+		planId,
+	).Scan(nil); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return kpgerr.Missing{
+				Table:    "plan",
+				Identity: fmt.Sprintf("plan_id='%s'", planId),
+			}
+		}
+		return err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`
+		insert into "plan_priority" as "p" ("plan_id", "priority", "max_concurrency")
+		values ($1, coalesce($2, 0), nullif(coalesce($3, 0), 0))
+		on conflict ("plan_id") do update set
+			"priority" = coalesce($2, "p"."priority"),
+			"max_concurrency" = case
+				when $3::int is null then "p"."max_concurrency"
+				else nullif($3, 0)
+			end
+		`,
+		planId, change.Priority, change.MaxConcurrency,
+	); err != nil {
+		return err
+	}
+
+	if err := auditChange(ctx, tx, types.AuditPlanPriority, planId, before); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
 func (m *planPG) UnsetServiceAccount(ctx context.Context, planId string) error {
 	tx, err := m.pool.Begin(ctx)
 	if err != nil {
@@ -1215,6 +1652,11 @@ func (m *planPG) UnsetServiceAccount(ctx context.Context, planId string) error {
 	}
 	defer tx.Rollback(ctx)
 
+	before, err := auditSnapshot(ctx, tx, planId)
+	if err != nil {
+		return err
+	}
+
 	{
 		if err := tx.QueryRow(
 			ctx,
@@ -1241,6 +1683,291 @@ func (m *planPG) UnsetServiceAccount(ctx context.Context, planId string) error {
 		return err
 	}
 
+	if err := auditChange(ctx, tx, types.AuditPlanServiceAccount, planId, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *planPG) Supersede(ctx context.Context, predecessor string, plan *types.PlanSpec, reroute bool) (string, error) {
+	if err := plan.Validate(); err != nil {
+		return "", err
+	}
+
+	tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return "", xe.Wrap(err)
+	}
+	defer tx.Rollback(ctx)
+
+	{
+		// pseudo plans cannot be superseded. they are treated as missing, like Activate.
+		var successor string
+		if err := tx.QueryRow(
+			ctx,
+			`
+			select coalesce("plan_supersede"."plan_id", '')
+			from "plan"
+			inner join "plan_image" using ("plan_id")
+			left join "plan_supersede" on "plan_supersede"."supersedes" = "plan"."plan_id"
+			where "plan"."plan_id" = $1
+			for update of "plan"
+			`,
+			predecessor,
+		).Scan(&successor); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", kpgerr.Missing{
+					Table:    "plan",
+					Identity: fmt.Sprintf("plan_id='%s'", predecessor),
+				}
+			}
+			return "", err
+		}
+		if successor != "" {
+			return "", xe.Wrap(fmt.Errorf(
+				"%w: plan_id='%s' is superseded by plan_id='%s'",
+				types.ErrPlanSuperseded, predecessor, successor,
+			))
+		}
+	}
+
+	before, err := auditSnapshot(ctx, tx, predecessor)
+	if err != nil {
+		return "", err
+	}
+
+	created, err := m.register(ctx, tx, plan)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`insert into "plan_supersede" ("plan_id", "supersedes", "reroute") values ($1, $2, $3)`,
+		created, predecessor, reroute,
+	); err != nil {
+		return "", err
+	}
+
+	if reroute {
+		// Nominator ignores inputs of superseded plans with reroute,
+		// so dropping nominations here stops new runs of the predecessor.
+		// Pending work of the predecessor is dropped, not moved:
+		// the successor has been nominated by register like any new plan.
+		if _, err := tx.Exec(ctx, `lock table "nomination" in EXCLUSIVE mode;`); err != nil {
+			return "", err
+		}
+		if _, err := tx.Exec(
+			ctx,
+			`
+			delete from "nomination"
+			where "input_id" in (select "input_id" from "input" where "plan_id" = $1)
+			`,
+			predecessor,
+		); err != nil {
+			return "", err
+		}
+	}
+
+	if err := m.activate(ctx, tx, predecessor, false); err != nil {
+		return "", err
+	}
+
+	if err := auditChange(ctx, tx, types.AuditPlanSupersede, predecessor, before); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return created, nil
+}
+
+func (m *planPG) Delete(ctx context.Context, planId string) error {
+	tx, err := m.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return xe.Wrap(err)
+	}
+	defer tx.Rollback(ctx)
+
+	{
+		// pseudo plans cannot be deleted. they are treated as missing, like Activate.
+		var runs int
+		if err := tx.QueryRow(
+			ctx,
+			`
+			with "plan" as (
+				select "plan_id" from "plan"
+				inner join "plan_image" using ("plan_id")
+				where "plan_id" = $1
+				for update of "plan"
+			)
+			select
+				(select count(*) from "run" where "plan_id" = $1)
+			from "plan"
+			`,
+			planId,
+		).Scan(&runs); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return kpgerr.Missing{
+					Table:    "plan",
+					Identity: fmt.Sprintf("plan_id='%s'", planId),
+				}
+			}
+			return err
+		}
+		if 0 < runs {
+			return xe.Wrap(fmt.Errorf("%w: plan_id='%s' has %d runs", types.ErrPlanHasRuns, planId, runs))
+		}
+	}
+
+	before, err := auditSnapshot(ctx, tx, planId)
+	if err != nil {
+		return err
+	}
+
+	// keep the version chain: the successor of this plan supersedes the predecessor of this.
+	{
+		var predecessor *string
+		if err := tx.QueryRow(
+			ctx,
+			`delete from "plan_supersede" where "plan_id" = $1 returning "supersedes"`,
+			planId,
+		).Scan(&predecessor); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if predecessor == nil {
+			if _, err := tx.Exec(
+				ctx, `delete from "plan_supersede" where "supersedes" = $1`, planId,
+			); err != nil {
+				return err
+			}
+		} else if _, err := tx.Exec(
+			ctx,
+			`update "plan_supersede" set "supersedes" = $2 where "supersedes" = $1`,
+			planId, *predecessor,
+		); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `lock table "nomination" in EXCLUSIVE mode;`); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`
+		with
+		"input" as (select "input_id" from "input" where "plan_id" = $1),
+		"output" as (select "output_id" from "output" where "plan_id" = $1),
+		"del_nomination" as (
+			delete from "nomination" where "input_id" in (table "input")
+		),
+		"del_tag_input" as (
+			delete from "tag_input" where "input_id" in (table "input")
+		),
+		"del_input_selection" as (
+			delete from "input_selection" where "input_id" in (table "input")
+		),
+		"del_parameter_input" as (
+			delete from "parameter_input" where "input_id" in (table "input")
+		),
+		"del_knitid_input" as (
+			delete from "knitid_input" where "input_id" in (table "input")
+		),
+		"del_timestamp_input" as (
+			delete from "timestamp_input" where "input_id" in (table "input")
+		),
+		"del_tag_output" as (
+			delete from "tag_output" where "output_id" in (table "output")
+		),
+		"del_log" as (
+			delete from "log" where "plan_id" = $1
+		),
+		"del_resource" as (
+			delete from "plan_resource" where "plan_id" = $1
+		),
+		"del_image" as (
+			delete from "plan_image" where "plan_id" = $1
+		),
+		"del_on_node" as (
+			delete from "plan_on_node" where "plan_id" = $1
+		),
+		"del_annotation" as (
+			delete from "plan_annotation" where "plan_id" = $1
+		),
+		"del_service_account" as (
+			delete from "plan_service_account" where "plan_id" = $1
+		),
+		"del_entrypoint" as (
+			delete from "plan_entrypoint" where "plan_id" = $1
+		),
+		"del_args" as (
+			delete from "plan_args" where "plan_id" = $1
+		),
+		"del_schedule" as (
+			delete from "plan_schedule" where "plan_id" = $1
+		),
+		"del_priority" as (
+			delete from "plan_priority" where "plan_id" = $1
+		),
+		"del_retry" as (
+			delete from "plan_retry" where "plan_id" = $1
+		),
+		"del_max_duration" as (
+			delete from "plan_max_duration" where "plan_id" = $1
+		),
+		"del_env" as (
+			delete from "plan_env" where "plan_id" = $1
+		),
+		"del_resource_request" as (
+			delete from "plan_resource_request" where "plan_id" = $1
+		),
+		"del_toleration" as (
+			delete from "plan_toleration" where "plan_id" = $1
+		),
+		"del_pod_class" as (
+			delete from "plan_pod_class" where "plan_id" = $1
+		),
+		"del_scratch" as (
+			delete from "plan_scratch" where "plan_id" = $1
+		),
+		"del_shm" as (
+			delete from "plan_shm" where "plan_id" = $1
+		),
+		"del_owner" as (
+			delete from "plan_owner" where "plan_id" = $1
+		)
+		select 1
+		`,
+		planId,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`
+		with
+		"del_input" as (delete from "input" where "plan_id" = $1),
+		"del_output" as (delete from "output" where "plan_id" = $1)
+		delete from "plan" where "plan_id" = $1
+		`,
+		planId,
+	); err != nil {
+		return err
+	}
+
+	if err := kpgintr.Audit(ctx, tx, types.AuditPlanDelete, types.AuditTargetPlan, planId, before, nil); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
@@ -1443,7 +1443,7 @@ func TestPlan_Find(t *testing.T) {
 			nom := kpgnommock.New(t)
 			testee := kpgplan.New(pgpool, kpgplan.WithNominator(nom))
 
-			actual, err := testee.Find(ctx, when.active, when.imageVer, when.inTag, when.outTag)
+			actual, _, err := testee.Find(ctx, when.active, when.imageVer, when.inTag, when.outTag, domain.Page{})
 			if !errors.Is(err, then.wantErr) {
 				t.Fatal(err)
 			}
@@ -0,0 +1,120 @@
+package plan_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgplan "github.com/opst/knitfab/pkg/domain/plan/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestPlan_Preview(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	TAG := domain.Tag{Key: "type", Value: "dataset"}
+	TIMESTAMP := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	// - knit-old, knit-new: uploaded with TAG. knit-new is newer.
+	//
+	// - knit-untagged: uploaded without tags.
+	given := func() tables.Operation {
+		op := tables.Operation{
+			Plan: []tables.Plan{
+				{PlanId: th.Padding36("upload"), Active: true, Hash: th.Padding36("#upload")},
+			},
+			PlanPseudo: []tables.PlanPseudo{
+				{PlanId: th.Padding36("upload"), Name: "knit#upload"},
+			},
+			Outputs: map[tables.Output]tables.OutputAttr{
+				{OutputId: 1, PlanId: th.Padding36("upload"), Path: "/out"}: {},
+			},
+		}
+		for nth, d := range []struct {
+			knitId string
+			tags   []domain.Tag
+		}{
+			{knitId: "knit-old", tags: []domain.Tag{TAG}},
+			{knitId: "knit-new", tags: []domain.Tag{TAG}},
+			{knitId: "knit-untagged", tags: []domain.Tag{}},
+		} {
+			runId := th.Padding36("run/" + d.knitId)
+			timestamp := TIMESTAMP.Add(time.Duration(nth) * time.Hour)
+			op.Steps = append(op.Steps, tables.Step{
+				Run: tables.Run{
+					RunId: runId, PlanId: th.Padding36("upload"), Status: domain.Done,
+					UpdatedAt: timestamp, LifecycleSuspendUntil: timestamp,
+				},
+				Outcomes: map[tables.Data]tables.DataAttibutes{
+					{
+						KnitId: th.Padding36(d.knitId), VolumeRef: "vol/" + d.knitId,
+						OutputId: 1, RunId: runId, PlanId: th.Padding36("upload"),
+					}: {UserTag: d.tags, Timestamp: &timestamp},
+				},
+			})
+		}
+		return op
+	}
+
+	theory := func(spec *domain.PlanSpec, expected []string) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+			op := given()
+			if err := op.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			testee := kpgplan.New(pool)
+			previews := try.To(testee.Preview(ctx, spec)).OrFatal(t)
+
+			actual := []string{}
+			for _, p := range previews {
+				if p.Plan.PlanId != "" {
+					t.Errorf("plan id of the preview: %s", p.Plan.PlanId)
+				}
+				if len(p.Inputs) != 1 {
+					t.Fatalf("inputs of the preview: %+v", p.Inputs)
+				}
+				actual = append(actual, p.Inputs[0].KnitDataBody.KnitId)
+			}
+			if !cmp.SliceContentEq(actual, expected) {
+				t.Errorf("previewed runs: actual = %v, expected = %v", actual, expected)
+			}
+
+			// nothing is registered.
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+			planIds := try.To(scanner.New[string]().QueryAll(
+				ctx, conn, `select "plan_id" from "plan"`,
+			)).OrFatal(t)
+			if !cmp.SliceEq(planIds, []string{th.Padding36("upload")}) {
+				t.Errorf("plans: %v", planIds)
+			}
+			nominations := try.To(scanner.New[tables.Nomination]().QueryAll(
+				ctx, conn, `table "nomination"`,
+			)).OrFatal(t)
+			if len(nominations) != 0 {
+				t.Errorf("nominations: %+v", nominations)
+			}
+		}
+	}
+
+	t.Run("when a plan is previewed, Runs for each Data matching its input are previewed", theory(
+		specWith("#preview", func(pp *domain.PlanParam) {}),
+		[]string{th.Padding36("knit-old"), th.Padding36("knit-new")},
+	))
+
+	t.Run("when a plan selecting the latest Data is previewed, Runs only for the latest one are previewed", theory(
+		specWith("#preview-latest", func(pp *domain.PlanParam) {
+			pp.Inputs[0].Selection = domain.InputSelection{Latest: 1}
+		}),
+		[]string{th.Padding36("knit-new")},
+	))
+}
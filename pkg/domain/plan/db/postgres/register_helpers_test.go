@@ -0,0 +1,78 @@
+package plan_test
+
+import (
+	"context"
+	"testing"
+
+	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgnommock "github.com/opst/knitfab/pkg/domain/nomination/db/mock"
+	kpgplan "github.com/opst/knitfab/pkg/domain/plan/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+// givenRegisteredPlan returns an Operation with a Plan "plan-registered" registered already.
+//
+// Tests of registering Plans apply it to show that registering does not affect other Plans.
+func givenRegisteredPlan() tables.Operation {
+	return tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: th.Padding36("plan-registered"), Active: true, Hash: th.Padding64("#registered")},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: th.Padding36("plan-registered"), Image: "repo.invalid/registered", Version: "v1"},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1, PlanId: th.Padding36("plan-registered"), Path: "/out"}: {},
+		},
+	}
+}
+
+// registerAndGet applies given, registers a Plan with the spec, and reads it back.
+func registerAndGet(
+	ctx context.Context, t *testing.T, pool kpool.Pool,
+	given tables.Operation, spec *domain.PlanSpec,
+) *domain.Plan {
+	t.Helper()
+
+	if err := given.Apply(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+
+	nomi := kpgnommock.New(t)
+	nomi.Impl.NominateMountpoints = func(ctx context.Context, conn kpool.Tx, mountpointIds []int) error {
+		return nil
+	}
+	testee := kpgplan.New(pool, kpgplan.WithNominator(nomi))
+
+	planId := try.To(testee.Register(ctx, spec)).OrFatal(t)
+	plans := try.To(testee.Get(ctx, []string{planId})).OrFatal(t)
+	plan, ok := plans[planId]
+	if !ok {
+		t.Fatalf("plan is registered, but missing: plan id = %s", planId)
+	}
+	return plan
+}
+
+// specWith returns a PlanSpec with an input and an output, modified by with.
+func specWith(hash string, with func(*domain.PlanParam)) *domain.PlanSpec {
+	param := domain.PlanParam{
+		Image: "repo.invalid/image", Version: "v1", Active: true,
+		Inputs: []domain.MountPointParam{
+			{
+				Path: "/in",
+				Tags: domain.NewTagSet([]domain.Tag{{Key: "type", Value: "dataset"}}),
+			},
+		},
+		Outputs: []domain.MountPointParam{
+			{
+				Path: "/out",
+				Tags: domain.NewTagSet([]domain.Tag{{Key: "type", Value: "model"}}),
+			},
+		},
+	}
+	with(&param)
+	return domain.BypassValidation(th.Padding64(hash), nil, param)
+}
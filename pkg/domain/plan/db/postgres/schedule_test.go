@@ -0,0 +1,100 @@
+package plan_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestPlan_Schedule(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	NEXT := time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC)
+	given := givenRegisteredPlan()
+	given.PlanSchedule = []tables.PlanSchedule{
+		{PlanId: th.Padding36("plan-registered"), Schedule: "0 * * * *", Next: NEXT},
+	}
+
+	type When struct {
+		spec *domain.PlanSpec
+	}
+	type Then struct {
+		// schedule of the registered Plan. empty if not scheduled.
+		schedule string
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			before := time.Now()
+			plan := registerAndGet(ctx, t, pool, given, when.spec)
+
+			if plan.Schedule != then.schedule {
+				t.Errorf("schedule: actual = %s, expected = %s", plan.Schedule, then.schedule)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			actual := try.To(scanner.New[tables.PlanSchedule]().QueryAll(
+				ctx, conn, `select "plan_id", "schedule", "next" from "plan_schedule"`,
+			)).OrFatal(t)
+
+			others := []tables.PlanSchedule{}
+			registered := []tables.PlanSchedule{}
+			for _, s := range actual {
+				if s.PlanId == plan.PlanId {
+					registered = append(registered, s)
+				} else {
+					others = append(others, s)
+				}
+			}
+
+			if !cmp.SliceContentEqWith(others, given.PlanSchedule, func(a, b tables.PlanSchedule) bool {
+				return a.PlanId == b.PlanId && a.Schedule == b.Schedule && a.Next.Equal(b.Next)
+			}) {
+				t.Errorf("schedules of other plans are changed: %+v", others)
+			}
+
+			if then.schedule == "" {
+				if len(registered) != 0 {
+					t.Errorf("plan_schedule: %+v", registered)
+				}
+				return
+			}
+			if len(registered) != 1 {
+				t.Fatalf("plan_schedule: %+v", registered)
+			}
+			if registered[0].Schedule != then.schedule {
+				t.Errorf("schedule: actual = %s, expected = %s", registered[0].Schedule, then.schedule)
+			}
+			if next := registered[0].Next; !next.After(before) || before.Add(24*time.Hour).Before(next) {
+				t.Errorf("next: %s is not the next midnight after %s", next, before)
+			}
+		}
+	}
+
+	t.Run("when a plan with schedule is registered, its next schedule is recorded", theory(
+		When{
+			spec: specWith("#scheduled", func(pp *domain.PlanParam) {
+				pp.Schedule = "0 0 * * *"
+			}),
+		},
+		Then{schedule: "0 0 * * *"},
+	))
+
+	t.Run("when a plan without schedule is registered, no schedules are recorded", theory(
+		When{spec: specWith("#not-scheduled", func(*domain.PlanParam) {})},
+		Then{schedule: ""},
+	))
+}
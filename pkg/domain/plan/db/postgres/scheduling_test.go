@@ -0,0 +1,395 @@
+package plan_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	marshal "github.com/opst/knitfab/pkg/domain/internal/db/postgres"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgplan "github.com/opst/knitfab/pkg/domain/plan/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func quantity(q string) marshal.ResourceQuantity {
+	return marshal.ResourceQuantity(resource.MustParse(q))
+}
+
+func TestPlan_SchedulingFields(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	type Toleration struct {
+		Position int
+		Key      string
+		Operator string
+		Value    string
+		Effect   string
+	}
+	type PodClass struct {
+		PriorityClassName string
+		RuntimeClassName  string
+	}
+
+	type Request struct {
+		Type  string
+		Value marshal.ResourceQuantity
+	}
+
+	given := givenRegisteredPlan()
+
+	type When struct {
+		resources         map[string]resource.Quantity
+		requests          map[string]resource.Quantity
+		tolerations       []domain.Toleration
+		priorityClassName string
+		runtimeClassName  string
+	}
+	type Then struct {
+		// tolerations of the registered Plan, as read back.
+		tolerations []domain.Toleration
+
+		// records of the registered Plan in "plan_resource_request".
+		requestRecords []Request
+
+		// records of the registered Plan in "plan_toleration".
+		tolerationRecords []Toleration
+
+		// records of the registered Plan in "plan_pod_class".
+		podClassRecords []PodClass
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			plan := registerAndGet(ctx, t, pool, given, specWith("#scheduling", func(pp *domain.PlanParam) {
+				pp.Resources = when.resources
+				pp.ResourceRequests = when.requests
+				pp.Tolerations = when.tolerations
+				pp.PriorityClassName = when.priorityClassName
+				pp.RuntimeClassName = when.runtimeClassName
+			}))
+
+			{
+				// limits are kept apart from requests.
+				if !cmp.MapEqWith(plan.Resources, when.resources, resource.Quantity.Equal) {
+					t.Errorf("limits: actual = %v, expected = %v", plan.Resources, when.resources)
+				}
+				if !cmp.MapEqWith(plan.ResourceRequests, when.requests, resource.Quantity.Equal) {
+					t.Errorf("requests: actual = %v, expected = %v", plan.ResourceRequests, when.requests)
+				}
+				if !cmp.SliceEq(plan.Tolerations, then.tolerations) {
+					t.Errorf("tolerations: actual = %+v, expected = %+v", plan.Tolerations, then.tolerations)
+				}
+				if plan.PriorityClassName != when.priorityClassName || plan.RuntimeClassName != when.runtimeClassName {
+					t.Errorf(
+						"pod class: priority class = %s, runtime class = %s",
+						plan.PriorityClassName, plan.RuntimeClassName,
+					)
+				}
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			{
+				actual := try.To(scanner.New[Request]().QueryAll(
+					ctx, conn,
+					`select "type", "value" from "plan_resource_request" where "plan_id" = $1`,
+					plan.PlanId,
+				)).OrFatal(t)
+				if !cmp.SliceContentEq(actual, then.requestRecords) {
+					t.Errorf("plan_resource_request: actual = %+v, expected = %+v", actual, then.requestRecords)
+				}
+			}
+			{
+				actual := try.To(scanner.New[Toleration]().QueryAll(
+					ctx, conn,
+					`
+					select "position", "key", "operator", "value", "effect"
+					from "plan_toleration" where "plan_id" = $1
+					`,
+					plan.PlanId,
+				)).OrFatal(t)
+				if !cmp.SliceContentEq(actual, then.tolerationRecords) {
+					t.Errorf("plan_toleration: actual = %+v, expected = %+v", actual, then.tolerationRecords)
+				}
+			}
+			{
+				actual := try.To(scanner.New[PodClass]().QueryAll(
+					ctx, conn,
+					`
+					select "priority_class_name", "runtime_class_name"
+					from "plan_pod_class" where "plan_id" = $1
+					`,
+					plan.PlanId,
+				)).OrFatal(t)
+				if !cmp.SliceEq(actual, then.podClassRecords) {
+					t.Errorf("plan_pod_class: actual = %+v, expected = %+v", actual, then.podClassRecords)
+				}
+			}
+		}
+	}
+
+	t.Run("when a plan with requests, tolerations and pod classes is registered, they are recorded", theory(
+		When{
+			resources: map[string]resource.Quantity{
+				"cpu": resource.MustParse("2"), "memory": resource.MustParse("4Gi"),
+			},
+			requests: map[string]resource.Quantity{
+				"cpu": resource.MustParse("500m"),
+			},
+			tolerations: []domain.Toleration{
+				{Key: "gpu", Value: "a100", Effect: domain.TaintEffectNoSchedule},
+				{Key: "preemptible", Operator: domain.TolerationOpExists},
+			},
+			priorityClassName: "high",
+			runtimeClassName:  "nvidia",
+		},
+		Then{
+			// operators are stored explicitly, so the default is read back.
+			tolerations: []domain.Toleration{
+				{Key: "gpu", Operator: domain.TolerationOpEqual, Value: "a100", Effect: domain.TaintEffectNoSchedule},
+				{Key: "preemptible", Operator: domain.TolerationOpExists},
+			},
+			requestRecords: []Request{
+				{Type: "cpu", Value: quantity("500m")},
+			},
+			tolerationRecords: []Toleration{
+				{Position: 0, Key: "gpu", Operator: "Equal", Value: "a100", Effect: "NoSchedule"},
+				{Position: 1, Key: "preemptible", Operator: "Exists"},
+			},
+			podClassRecords: []PodClass{{PriorityClassName: "high", RuntimeClassName: "nvidia"}},
+		},
+	))
+
+	t.Run("when a plan has only a runtime class, the priority class is empty", theory(
+		When{runtimeClassName: "gvisor"},
+		Then{
+			requestRecords:    []Request{},
+			tolerationRecords: []Toleration{},
+			podClassRecords:   []PodClass{{PriorityClassName: "", RuntimeClassName: "gvisor"}},
+		},
+	))
+
+	t.Run("when a plan without them is registered, nothing are recorded", theory(
+		When{},
+		Then{
+			requestRecords:    []Request{},
+			tolerationRecords: []Toleration{},
+			podClassRecords:   []PodClass{},
+		},
+	))
+}
+
+func TestSetResourceRequest(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	given := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: th.Padding36("plan-1"), Active: true, Hash: th.Padding64("xxx-hash-xxx")},
+			{PlanId: th.Padding36("plan-2"), Active: false, Hash: th.Padding64("yyy-hash-yyy")},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: th.Padding36("plan-1"), Image: "repo.invalid/image1", Version: "v0.1"},
+			{PlanId: th.Padding36("plan-2"), Image: "repo.invalid/image2", Version: "v0.2"},
+		},
+		PlanResources: []tables.PlanResource{
+			{PlanId: th.Padding36("plan-1"), Type: "cpu", Value: quantity("2")},
+		},
+		PlanResourceRequests: []tables.PlanResourceRequest{
+			{PlanId: th.Padding36("plan-1"), Type: "cpu", Value: quantity("1")},
+		},
+	}
+
+	type When struct {
+		planId   string
+		requests map[string]resource.Quantity
+	}
+
+	type Then struct {
+		requests []tables.PlanResourceRequest
+		err      error
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			testee := kpgplan.New(pool)
+			if err := testee.SetResourceRequest(
+				ctx, when.planId, when.requests,
+			); !errors.Is(err, then.err) {
+				t.Fatalf("error: actual = %v, expected = %v", err, then.err)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			actual := try.To(scanner.New[tables.PlanResourceRequest]().QueryAll(
+				ctx, conn, `table "plan_resource_request"`,
+			)).OrFatal(t)
+			if !cmp.SliceContentEq(actual, then.requests) {
+				t.Errorf("plan_resource_request: actual = %+v, expected = %+v", actual, then.requests)
+			}
+
+			// limits are not changed by requests.
+			limits := try.To(scanner.New[tables.PlanResource]().QueryAll(
+				ctx, conn, `table "plan_resource"`,
+			)).OrFatal(t)
+			if !cmp.SliceContentEq(limits, given.PlanResources) {
+				t.Errorf("plan_resource: actual = %+v, expected = %+v", limits, given.PlanResources)
+			}
+
+			actions := try.To(scanner.New[string]().QueryAll(
+				ctx, conn,
+				`select "action" from "audit_log" where "target_id" = $1`,
+				when.planId,
+			)).OrFatal(t)
+			expectedActions := []string{}
+			if then.err == nil {
+				expectedActions = []string{string(domain.AuditPlanResource)}
+			}
+			if !cmp.SliceEq(actions, expectedActions) {
+				t.Errorf("audit log: actual = %v, expected = %v", actions, expectedActions)
+			}
+		}
+	}
+
+	t.Run("when setting requests to a plan, they are updated or inserted", theory(
+		When{
+			planId: th.Padding36("plan-1"),
+			requests: map[string]resource.Quantity{
+				"cpu": resource.MustParse("1500m"), "memory": resource.MustParse("1Gi"),
+			},
+		},
+		Then{
+			requests: []tables.PlanResourceRequest{
+				{PlanId: th.Padding36("plan-1"), Type: "cpu", Value: quantity("1500m")},
+				{PlanId: th.Padding36("plan-1"), Type: "memory", Value: quantity("1Gi")},
+			},
+		},
+	))
+
+	t.Run("when setting requests to a plan without requests, they are inserted", theory(
+		When{
+			planId:   th.Padding36("plan-2"),
+			requests: map[string]resource.Quantity{"cpu": resource.MustParse("1")},
+		},
+		Then{
+			requests: []tables.PlanResourceRequest{
+				{PlanId: th.Padding36("plan-1"), Type: "cpu", Value: quantity("1")},
+				{PlanId: th.Padding36("plan-2"), Type: "cpu", Value: quantity("1")},
+			},
+		},
+	))
+
+	t.Run("when setting requests to a non existing plan, it returns ErrMissing", theory(
+		When{
+			planId:   th.Padding36("plan-3"),
+			requests: map[string]resource.Quantity{"cpu": resource.MustParse("1")},
+		},
+		Then{
+			requests: []tables.PlanResourceRequest{
+				{PlanId: th.Padding36("plan-1"), Type: "cpu", Value: quantity("1")},
+			},
+			err: kerr.ErrMissing,
+		},
+	))
+}
+
+func TestUnsetResourceRequest(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	given := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: th.Padding36("plan-1"), Active: true, Hash: th.Padding64("xxx-hash-xxx")},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: th.Padding36("plan-1"), Image: "repo.invalid/image1", Version: "v0.1"},
+		},
+		PlanResources: []tables.PlanResource{
+			{PlanId: th.Padding36("plan-1"), Type: "cpu", Value: quantity("2")},
+		},
+		PlanResourceRequests: []tables.PlanResourceRequest{
+			{PlanId: th.Padding36("plan-1"), Type: "cpu", Value: quantity("1")},
+			{PlanId: th.Padding36("plan-1"), Type: "memory", Value: quantity("1Gi")},
+		},
+	}
+
+	type When struct {
+		planId string
+		types  []string
+	}
+
+	type Then struct {
+		requests []tables.PlanResourceRequest
+		err      error
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			testee := kpgplan.New(pool)
+			if err := testee.UnsetResourceRequest(
+				ctx, when.planId, when.types,
+			); !errors.Is(err, then.err) {
+				t.Fatalf("error: actual = %v, expected = %v", err, then.err)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			actual := try.To(scanner.New[tables.PlanResourceRequest]().QueryAll(
+				ctx, conn, `table "plan_resource_request"`,
+			)).OrFatal(t)
+			if !cmp.SliceContentEq(actual, then.requests) {
+				t.Errorf("plan_resource_request: actual = %+v, expected = %+v", actual, then.requests)
+			}
+
+			// limits are not changed by requests.
+			limits := try.To(scanner.New[tables.PlanResource]().QueryAll(
+				ctx, conn, `table "plan_resource"`,
+			)).OrFatal(t)
+			if !cmp.SliceContentEq(limits, given.PlanResources) {
+				t.Errorf("plan_resource: actual = %+v, expected = %+v", limits, given.PlanResources)
+			}
+		}
+	}
+
+	t.Run("when unsetting a request of a plan, it is removed", theory(
+		When{planId: th.Padding36("plan-1"), types: []string{"cpu"}},
+		Then{
+			requests: []tables.PlanResourceRequest{
+				{PlanId: th.Padding36("plan-1"), Type: "memory", Value: quantity("1Gi")},
+			},
+		},
+	))
+
+	t.Run("when unsetting a request which is not set, nothing is changed", theory(
+		When{planId: th.Padding36("plan-1"), types: []string{"gpu"}},
+		Then{requests: given.PlanResourceRequests},
+	))
+
+	t.Run("when unsetting requests of a non existing plan, it returns ErrMissing", theory(
+		When{planId: th.Padding36("plan-2"), types: []string{"cpu"}},
+		Then{requests: given.PlanResourceRequests, err: kerr.ErrMissing},
+	))
+}
@@ -0,0 +1,131 @@
+package plan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	marshal "github.com/opst/knitfab/pkg/domain/internal/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestPlan_Scratch(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	type Scratch struct {
+		Path      string
+		SizeLimit *marshal.ResourceQuantity
+		InMemory  bool
+	}
+	eqScratch := func(a, b Scratch) bool {
+		return a.Path == b.Path && a.InMemory == b.InMemory &&
+			cmp.PEqualWith(a.SizeLimit, b.SizeLimit, func(x, y marshal.ResourceQuantity) bool {
+				qx, qy := resource.Quantity(x), resource.Quantity(y)
+				return qx.Cmp(qy) == 0
+			})
+	}
+	eqVolume := func(a, b domain.ScratchVolume) bool {
+		return a.Path == b.Path && a.InMemory == b.InMemory && a.SizeLimit.Cmp(b.SizeLimit) == 0
+	}
+
+	given := givenRegisteredPlan()
+
+	type When struct {
+		scratches []domain.ScratchVolume
+		shmSize   resource.Quantity
+	}
+	type Then struct {
+		// scratches of the registered Plan, in order of paths.
+		scratches []domain.ScratchVolume
+
+		// records of the registered Plan in "plan_scratch".
+		records []Scratch
+
+		// records of the registered Plan in "plan_shm".
+		shm []resource.Quantity
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			plan := registerAndGet(ctx, t, pool, given, specWith("#scratch", func(pp *domain.PlanParam) {
+				pp.Scratches = when.scratches
+				pp.ShmSize = when.shmSize
+			}))
+
+			if !cmp.SliceEqWith(plan.Scratches, then.scratches, eqVolume) {
+				t.Errorf("scratches: actual = %+v, expected = %+v", plan.Scratches, then.scratches)
+			}
+			if plan.ShmSize.Cmp(when.shmSize) != 0 {
+				t.Errorf("shm size: %s", plan.ShmSize.String())
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			{
+				actual := try.To(scanner.New[Scratch]().QueryAll(
+					ctx, conn,
+					`select "path", "size_limit", "in_memory" from "plan_scratch" where "plan_id" = $1`,
+					plan.PlanId,
+				)).OrFatal(t)
+				if !cmp.SliceContentEqWith(actual, then.records, eqScratch) {
+					t.Errorf("plan_scratch: actual = %+v, expected = %+v", actual, then.records)
+				}
+			}
+			{
+				actual := try.To(scanner.New[marshal.ResourceQuantity]().QueryAll(
+					ctx, conn,
+					`select "size" from "plan_shm" where "plan_id" = $1`,
+					plan.PlanId,
+				)).OrFatal(t)
+				if !cmp.SliceEqWith(actual, then.shm, func(a marshal.ResourceQuantity, b resource.Quantity) bool {
+					qa := resource.Quantity(a)
+					return qa.Cmp(b) == 0
+				}) {
+					t.Errorf("plan_shm: actual = %v, expected = %v", actual, then.shm)
+				}
+			}
+		}
+	}
+
+	{
+		limit := quantity("10Gi")
+		t.Run("when a plan with scratches and shm is registered, they are recorded", theory(
+			When{
+				scratches: []domain.ScratchVolume{
+					{Path: "/tmp/work", SizeLimit: resource.MustParse("10Gi")},
+					{Path: "/cache", InMemory: true},
+				},
+				shmSize: resource.MustParse("2Gi"),
+			},
+			Then{
+				scratches: []domain.ScratchVolume{
+					{Path: "/cache", InMemory: true},
+					{Path: "/tmp/work", SizeLimit: resource.MustParse("10Gi")},
+				},
+				records: []Scratch{
+					{Path: "/tmp/work", SizeLimit: &limit},
+					// unlimited scratch has no size limits.
+					{Path: "/cache", InMemory: true},
+				},
+				shm: []resource.Quantity{resource.MustParse("2Gi")},
+			},
+		))
+	}
+
+	t.Run("when a plan without scratches nor shm is registered, nothing are recorded", theory(
+		When{},
+		Then{
+			scratches: []domain.ScratchVolume{},
+			records:   []Scratch{},
+			shm:       []resource.Quantity{},
+		},
+	))
+}
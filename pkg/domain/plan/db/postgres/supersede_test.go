@@ -0,0 +1,257 @@
+package plan_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgnommock "github.com/opst/knitfab/pkg/domain/nomination/db/mock"
+	kpgplan "github.com/opst/knitfab/pkg/domain/plan/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+// givenPlanWithNomination returns an uploaded Data and a Plan nominating it.
+//
+// - plan: active, with input 100 (tag: type=dataset) and output 110.
+//
+// - knit-1: uploaded, tagged type=dataset and nominated to input 100.
+func givenPlanWithNomination() tables.Operation {
+	TAG := domain.Tag{Key: "type", Value: "dataset"}
+	TIMESTAMP := time.Date(2022, time.August, 1, 12, 13, 24, 0, time.UTC)
+
+	return tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: th.Padding36("pseudo"), Active: true, Hash: th.Padding64("#pseudo")},
+			{PlanId: th.Padding36("plan"), Active: true, Hash: th.Padding64("#plan")},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: th.Padding36("pseudo"), Name: "knit#upload"},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: th.Padding36("plan"), Image: "repo.invalid/image", Version: "v1"},
+		},
+		Inputs: map[tables.Input]tables.InputAttr{
+			{InputId: 100, PlanId: th.Padding36("plan"), Path: "/in"}: {
+				UserTag: []domain.Tag{TAG},
+			},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1, PlanId: th.Padding36("pseudo"), Path: "/out"}: {},
+			{OutputId: 110, PlanId: th.Padding36("plan"), Path: "/out"}: {},
+		},
+		Steps: []tables.Step{
+			{
+				Run: tables.Run{
+					RunId: th.Padding36("run-upload"), PlanId: th.Padding36("pseudo"),
+					Status: domain.Done, UpdatedAt: TIMESTAMP, LifecycleSuspendUntil: TIMESTAMP,
+				},
+				Outcomes: map[tables.Data]tables.DataAttibutes{
+					{
+						KnitId: th.Padding36("knit-1"), VolumeRef: "vol-1",
+						OutputId: 1, RunId: th.Padding36("run-upload"), PlanId: th.Padding36("pseudo"),
+					}: {UserTag: []domain.Tag{TAG}},
+				},
+			},
+		},
+		Nomination: []tables.Nomination{
+			{KnitId: th.Padding36("knit-1"), InputId: 100},
+		},
+	}
+}
+
+// successorSpec returns a PlanSpec which can supersede the Plan in givenPlanWithNomination.
+func successorSpec(version string) *domain.PlanSpec {
+	return domain.BypassValidation(
+		th.Padding64("#successor-"+version), nil,
+		domain.PlanParam{
+			Image: "repo.invalid/image", Version: version, Active: true,
+			Inputs: []domain.MountPointParam{
+				{
+					Path: "/in",
+					Tags: domain.NewTagSet([]domain.Tag{{Key: "type", Value: "dataset"}}),
+				},
+			},
+			Outputs: []domain.MountPointParam{
+				{
+					Path: "/out",
+					Tags: domain.NewTagSet([]domain.Tag{{Key: "type", Value: "model"}}),
+				},
+			},
+		},
+	)
+}
+
+func TestPlan_Supersede(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	type Supersede struct {
+		PlanId     string
+		Supersedes string
+		Reroute    bool
+	}
+
+	theory := func(reroute bool, expectedNominations []tables.Nomination) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			given := givenPlanWithNomination()
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			nomi := kpgnommock.New(t)
+			nomi.Impl.NominateMountpoints = func(ctx context.Context, conn kpool.Tx, mountpointIds []int) error {
+				return nil
+			}
+			testee := kpgplan.New(pool, kpgplan.WithNominator(nomi))
+
+			successor, err := testee.Supersede(ctx, th.Padding36("plan"), successorSpec("v2"), reroute)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			{
+				actual := try.To(scanner.New[Supersede]().QueryAll(
+					ctx, conn, `table "plan_supersede"`,
+				)).OrFatal(t)
+				expected := []Supersede{
+					{PlanId: successor, Supersedes: th.Padding36("plan"), Reroute: reroute},
+				}
+				if !cmp.SliceContentEq(actual, expected) {
+					t.Errorf("plan_supersede: actual = %+v, expected = %+v", actual, expected)
+				}
+			}
+
+			{
+				actual := try.To(scanner.New[tables.Plan]().QueryAll(
+					ctx, conn,
+					`select * from "plan" where "plan_id" = any($1)`,
+					[]string{th.Padding36("plan"), successor},
+				)).OrFatal(t)
+				expected := []tables.Plan{
+					{PlanId: th.Padding36("plan"), Active: false, Hash: th.Padding64("#plan")},
+					{PlanId: successor, Active: true, Hash: th.Padding64("#successor-v2")},
+				}
+				if !cmp.SliceContentEq(actual, expected) {
+					t.Errorf("plans: actual = %+v, expected = %+v", actual, expected)
+				}
+			}
+
+			{
+				actual := try.To(scanner.New[tables.Nomination]().QueryAll(
+					ctx, conn, `select * from "nomination" where "input_id" = 100`,
+				)).OrFatal(t)
+				if !cmp.SliceContentEq(actual, expectedNominations) {
+					t.Errorf(
+						"nominations of the predecessor: actual = %+v, expected = %+v",
+						actual, expectedNominations,
+					)
+				}
+			}
+
+			{
+				actions := try.To(scanner.New[string]().QueryAll(
+					ctx, conn,
+					`select "action" from "audit_log" where "target_id" = $1 order by "id"`,
+					th.Padding36("plan"),
+				)).OrFatal(t)
+				if !cmp.SliceEq(actions, []string{string(domain.AuditPlanSupersede)}) {
+					t.Errorf("audit log of the predecessor: %v", actions)
+				}
+			}
+		}
+	}
+
+	t.Run("when it supersedes without reroute, nominations of the predecessor are kept", theory(
+		false, []tables.Nomination{{KnitId: th.Padding36("knit-1"), InputId: 100}},
+	))
+
+	t.Run("when it supersedes with reroute, nominations of the predecessor are dropped", theory(
+		true, []tables.Nomination{},
+	))
+
+	t.Run("when the plan is superseded already, it returns ErrPlanSuperseded", func(t *testing.T) {
+		ctx := context.Background()
+		pool := poolBroaker.GetPool(ctx, t)
+
+		given := givenPlanWithNomination()
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		nomi := kpgnommock.New(t)
+		nomi.Impl.NominateMountpoints = func(ctx context.Context, conn kpool.Tx, mountpointIds []int) error {
+			return nil
+		}
+		testee := kpgplan.New(pool, kpgplan.WithNominator(nomi))
+
+		first, err := testee.Supersede(ctx, th.Padding36("plan"), successorSpec("v2"), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := testee.Supersede(
+			ctx, th.Padding36("plan"), successorSpec("v3"), false,
+		); !errors.Is(err, domain.ErrPlanSuperseded) {
+			t.Errorf("error: actual = %v, expected = %v", err, domain.ErrPlanSuperseded)
+		}
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+
+		// the second successor is not registered.
+		planIds := try.To(scanner.New[string]().QueryAll(
+			ctx, conn, `select "plan_id" from "plan_image"`,
+		)).OrFatal(t)
+		if !cmp.SliceContentEq(planIds, []string{th.Padding36("plan"), first}) {
+			t.Errorf("plans with image: %v", planIds)
+		}
+	})
+
+	for name, planId := range map[string]string{
+		"when the plan is not found, it returns ErrMissing":     th.Padding36("no-such-plan"),
+		"when the plan is a pseudo plan, it returns ErrMissing": th.Padding36("pseudo"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			given := givenPlanWithNomination()
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			nomi := kpgnommock.New(t)
+			testee := kpgplan.New(pool, kpgplan.WithNominator(nomi))
+
+			if _, err := testee.Supersede(
+				ctx, planId, successorSpec("v2"), false,
+			); !errors.Is(err, kerr.ErrMissing) {
+				t.Errorf("error: actual = %v, expected = %v", err, kerr.ErrMissing)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			supersedes := try.To(scanner.New[Supersede]().QueryAll(
+				ctx, conn, `table "plan_supersede"`,
+			)).OrFatal(t)
+			if len(supersedes) != 0 {
+				t.Errorf("plan_supersede: %+v", supersedes)
+			}
+		})
+	}
+}
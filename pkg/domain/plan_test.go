@@ -6,8 +6,10 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/utils/cmp"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -85,6 +87,31 @@ func TestImageIdentifier(t *testing.T) {
 			},
 		))
 
+		t.Run("when it is passed valid image identifier (with digest), it parses it", theory(
+			When{Image: "repo.invalid:5000/image-name@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+			Then{
+				ImageIdentfier: domain.ImageIdentifier{
+					Image:   "repo.invalid:5000/image-name",
+					Version: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				},
+			},
+		))
+
+		t.Run("when it is passed valid image identifier (with tag and digest), it takes the digest", theory(
+			When{Image: "repo.invalid/image-name:v0.0-alpha@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+			Then{
+				ImageIdentfier: domain.ImageIdentifier{
+					Image:   "repo.invalid/image-name",
+					Version: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				},
+			},
+		))
+
+		t.Run("when it is passed invalid image identifier (malformed digest), it returns error", theory(
+			When{Image: "repo.invalid/image-name@sha256:not-a-digest"},
+			Then{Err: domain.ErrInvalidImageIdentifier},
+		))
+
 		t.Run("when it is passed invalid image identifier (there are repo but no image name), it returns error", theory(
 			When{Image: "repo/:v0.0-alpha"},
 			Then{Err: domain.ErrInvalidImageIdentifier},
@@ -101,6 +128,26 @@ func TestImageIdentifier(t *testing.T) {
 		))
 
 	})
+
+	t.Run("String", func(t *testing.T) {
+		for _, testcase := range []struct {
+			when domain.ImageIdentifier
+			then string
+		}{
+			{
+				when: domain.ImageIdentifier{Image: "repo.invalid/image-name", Version: "v0.0-alpha"},
+				then: "repo.invalid/image-name:v0.0-alpha",
+			},
+			{
+				when: domain.ImageIdentifier{Image: "repo.invalid/image-name", Version: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+				then: "repo.invalid/image-name@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			},
+		} {
+			if actual := testcase.when.String(); actual != testcase.then {
+				t.Errorf("String(): (actual, expected) = (%s, %s)", actual, testcase.then)
+			}
+		}
+	})
 }
 
 func TestPlanParam_Validation(t *testing.T) {
@@ -1076,4 +1123,530 @@ func TestPlanParam_Validation(t *testing.T) {
 		},
 		then{err: domain.ErrPlanNamelessImage},
 	))
+
+	t.Run("when it is passed a schedule, it creates PlanSpec with the schedule", theory(
+		domain.PlanParam{
+			Image:    "repo.invalid/image-name",
+			Version:  "v0.0-alpha",
+			Active:   true,
+			Schedule: "0 3 * * *",
+			Inputs: []domain.MountPointParam{
+				{
+					Path: "/in/data",
+					Tags: domain.NewTagSet([]domain.Tag{
+						{Key: "some", Value: "tag"},
+					}),
+				},
+			},
+		},
+		then{
+			hash: sha256hash(
+				"repo.invalid/image-name", "v0.0-alpha",
+				"/in/data", "some:tag",
+			),
+		},
+	))
+
+	for name, schedule := range map[string]string{
+		"malformed":   "every day",
+		"never comes": "0 0 30 2 *",
+	} {
+		t.Run("when it is passed "+name+" schedule, it causes ErrInvalidSchedule", theory(
+			domain.PlanParam{
+				Image:    "repo.invalid/image-name",
+				Version:  "v0.0-alpha",
+				Active:   true,
+				Schedule: schedule,
+				Inputs: []domain.MountPointParam{
+					{
+						Path: "/in/data",
+						Tags: domain.NewTagSet([]domain.Tag{
+							{Key: "some", Value: "tag"},
+						}),
+					},
+				},
+			},
+			then{err: domain.ErrInvalidSchedule},
+		))
+	}
+
+	t.Run("when it is passed an input with negative selection, it causes ErrInvalidInputSelection", theory(
+		domain.PlanParam{
+			Image:   "repo.invalid/image-name",
+			Version: "v0.0-alpha",
+			Active:  true,
+			Inputs: []domain.MountPointParam{
+				{
+					Path: "/in/data",
+					Tags: domain.NewTagSet([]domain.Tag{
+						{Key: "some", Value: "tag"},
+					}),
+					Selection: domain.InputSelection{Latest: -1},
+				},
+			},
+		},
+		then{err: domain.ErrInvalidInputSelection},
+	))
+
+	t.Run("when it is passed an output with selection, it causes ErrInvalidInputSelection", theory(
+		domain.PlanParam{
+			Image:   "repo.invalid/image-name",
+			Version: "v0.0-alpha",
+			Active:  true,
+			Inputs: []domain.MountPointParam{
+				{
+					Path: "/in/data",
+					Tags: domain.NewTagSet([]domain.Tag{
+						{Key: "some", Value: "tag"},
+					}),
+				},
+			},
+			Outputs: []domain.MountPointParam{
+				{
+					Path: "/out/data",
+					Tags: domain.NewTagSet([]domain.Tag{
+						{Key: "some", Value: "tag"},
+					}),
+					Selection: domain.InputSelection{Latest: 1},
+				},
+			},
+		},
+		then{err: domain.ErrInvalidInputSelection},
+	))
+}
+
+func TestPlanParam_Validation_Parameter(t *testing.T) {
+	base := func() domain.PlanParam {
+		return domain.PlanParam{
+			Image:   "repo.invalid/image-name",
+			Version: "v0.0-alpha",
+			Active:  true,
+			Inputs: []domain.MountPointParam{
+				{
+					Path: "/in/lr",
+					Tags: domain.NewTagSet([]domain.Tag{
+						{Key: "sweep", Value: "lr"},
+					}),
+					Values: []string{"0.1", "0.01"},
+				},
+			},
+		}
+	}
+
+	t.Run("it accepts parameter inputs", func(t *testing.T) {
+		spec, err := base().Validate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		other := base()
+		other.Inputs[0].Values = []string{"0.01", "0.1"}
+		otherSpec, err := other.Validate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if spec.Hash() != otherSpec.Hash() {
+			t.Errorf("hash should not depend on the order of values")
+		}
+
+		notParam := base()
+		notParam.Inputs[0].Values = nil
+		notParamSpec, err := notParam.Validate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if spec.Hash() == notParamSpec.Hash() {
+			t.Errorf("hash should depend on values")
+		}
+	})
+
+	for name, modify := range map[string]func(*domain.PlanParam){
+		"duplicated values": func(p *domain.PlanParam) {
+			p.Inputs[0].Values = []string{"0.1", "0.1"}
+		},
+		"system tag": func(p *domain.PlanParam) {
+			p.Inputs[0].Tags = domain.NewTagSet([]domain.Tag{
+				{Key: "sweep", Value: "lr"},
+				{Key: domain.KeyKnitId, Value: "some-knit-id"},
+			})
+		},
+		"values on output": func(p *domain.PlanParam) {
+			p.Outputs = []domain.MountPointParam{
+				{
+					Path: "/out",
+					Tags: domain.NewTagSet([]domain.Tag{
+						{Key: "type", Value: "model"},
+					}),
+					Values: []string{"x"},
+				},
+			}
+		},
+	} {
+		t.Run("it rejects "+name, func(t *testing.T) {
+			p := base()
+			modify(&p)
+			if _, err := p.Validate(); !errors.Is(err, domain.ErrInvalidParameter) {
+				t.Errorf("expected ErrInvalidParameter, but got %v", err)
+			}
+		})
+	}
+}
+
+func TestPlanParam_Validation_Scratch(t *testing.T) {
+	base := func() domain.PlanParam {
+		return domain.PlanParam{
+			Image:   "repo.invalid/image-name",
+			Version: "v0.0-alpha",
+			Active:  true,
+			Inputs: []domain.MountPointParam{
+				{
+					Path: "/in/1",
+					Tags: domain.NewTagSet([]domain.Tag{{Key: "type", Value: "dataset"}}),
+				},
+			},
+			Outputs: []domain.MountPointParam{
+				{
+					Path: "/out/1",
+					Tags: domain.NewTagSet([]domain.Tag{{Key: "type", Value: "model"}}),
+				},
+			},
+			Log: &domain.LogParam{
+				Tags: domain.NewTagSet([]domain.Tag{{Key: "type", Value: "log"}}),
+			},
+		}
+	}
+
+	t.Run("it accepts scratches and shm size", func(t *testing.T) {
+		p := base()
+		p.Scratches = []domain.ScratchVolume{
+			{Path: "/tmp/", SizeLimit: resource.MustParse("10Gi")},
+			{Path: "/cache", InMemory: true},
+		}
+		p.ShmSize = resource.MustParse("2Gi")
+
+		spec, err := p.Validate()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []domain.ScratchVolume{
+			{Path: "/cache", InMemory: true},
+			{Path: "/tmp", SizeLimit: resource.MustParse("10Gi")},
+		}
+		if !cmp.SliceEqWith(spec.Scratches(), want, domain.ScratchVolume.Equal) {
+			t.Errorf("scratches:\n===actual===\n%+v\n===expected===\n%+v", spec.Scratches(), want)
+		}
+		if shm := spec.ShmSize(); !shm.Equal(resource.MustParse("2Gi")) {
+			t.Errorf("shm size: %s", shm.String())
+		}
+	})
+
+	for name, testcase := range map[string]struct {
+		modify func(*domain.PlanParam)
+		err    error
+	}{
+		"scratch is relative": {
+			modify: func(p *domain.PlanParam) {
+				p.Scratches = []domain.ScratchVolume{{Path: "tmp"}}
+			},
+			err: domain.ErrBadMountpointPath,
+		},
+		"scratch is not clean": {
+			modify: func(p *domain.PlanParam) {
+				p.Scratches = []domain.ScratchVolume{{Path: "/tmp/../cache"}}
+			},
+			err: domain.ErrBadMountpointPath,
+		},
+		"scratch overlaps with input": {
+			modify: func(p *domain.PlanParam) {
+				p.Scratches = []domain.ScratchVolume{{Path: "/in"}}
+			},
+			err: domain.ErrOverlappedMountpoints,
+		},
+		"scratch overlaps with output": {
+			modify: func(p *domain.PlanParam) {
+				p.Scratches = []domain.ScratchVolume{{Path: "/out/1/tmp"}}
+			},
+			err: domain.ErrOverlappedMountpoints,
+		},
+		"scratch overlaps with log": {
+			modify: func(p *domain.PlanParam) {
+				p.Scratches = []domain.ScratchVolume{{Path: "/log"}}
+			},
+			err: domain.ErrOverlappedMountpoints,
+		},
+		"scratch overlaps with shm": {
+			modify: func(p *domain.PlanParam) {
+				p.Scratches = []domain.ScratchVolume{{Path: "/dev/shm"}}
+				p.ShmSize = resource.MustParse("1Gi")
+			},
+			err: domain.ErrOverlappedMountpoints,
+		},
+		"scratches overlap with each other": {
+			modify: func(p *domain.PlanParam) {
+				p.Scratches = []domain.ScratchVolume{{Path: "/tmp"}, {Path: "/tmp/a"}}
+			},
+			err: domain.ErrOverlappedMountpoints,
+		},
+		"size limit is negative": {
+			modify: func(p *domain.PlanParam) {
+				p.Scratches = []domain.ScratchVolume{{Path: "/tmp", SizeLimit: resource.MustParse("-1Gi")}}
+			},
+			err: domain.ErrInvalidScratch,
+		},
+		"shm size is negative": {
+			modify: func(p *domain.PlanParam) {
+				p.ShmSize = resource.MustParse("-1Gi")
+			},
+			err: domain.ErrInvalidScratch,
+		},
+	} {
+		t.Run("it rejects when "+name, func(t *testing.T) {
+			p := base()
+			testcase.modify(&p)
+			if _, err := p.Validate(); !errors.Is(err, testcase.err) {
+				t.Errorf("expected %v, but got %v", testcase.err, err)
+			}
+		})
+	}
+}
+
+func TestParseInputSelection(t *testing.T) {
+	for expr, want := range map[string]domain.InputSelection{
+		"":         {},
+		"all":      {},
+		"latest":   {Latest: 1},
+		"latest:1": {Latest: 1},
+		"latest:3": {Latest: 3},
+	} {
+		t.Run("it parses "+expr, func(t *testing.T) {
+			got, err := domain.ParseInputSelection(expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	for _, expr := range []string{"latest:0", "latest:-1", "latest:x", "oldest", "all:1"} {
+		t.Run("it rejects "+expr, func(t *testing.T) {
+			if _, err := domain.ParseInputSelection(expr); !errors.Is(err, domain.ErrInvalidInputSelection) {
+				t.Errorf("expected ErrInvalidInputSelection, but got %v", err)
+			}
+		})
+	}
+}
+
+func TestInputSelection_String(t *testing.T) {
+	for want, sel := range map[string]domain.InputSelection{
+		"all":      {},
+		"latest":   {Latest: 1},
+		"latest:3": {Latest: 3},
+	} {
+		if got := sel.String(); got != want {
+			t.Errorf("String: got %s, want %s", got, want)
+		}
+	}
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	type When struct {
+		policy  *domain.RetryPolicy
+		attempt int
+		exit    *domain.RunExit
+	}
+
+	theory := func(when When, then bool) func(*testing.T) {
+		return func(t *testing.T) {
+			if got := when.policy.ShouldRetry(when.attempt, when.exit); got != then {
+				t.Errorf("ShouldRetry: got %v, want %v", got, then)
+			}
+		}
+	}
+
+	failed := &domain.RunExit{Code: 1, Message: "Error", Cause: domain.ExitByFailure}
+	stucking := &domain.RunExit{Code: 255, Message: "ImagePullBackOff", Cause: domain.ExitByStucking}
+	aborted := &domain.RunExit{Code: 253, Message: "aborted"}
+
+	t.Run("nil policy does not retry", theory(
+		When{policy: nil, attempt: 1, exit: failed}, false,
+	))
+	t.Run("it retries failed run within max attempts", theory(
+		When{policy: &domain.RetryPolicy{MaxAttempts: 3}, attempt: 2, exit: failed}, true,
+	))
+	t.Run("it does not retry when attempts reach max attempts", theory(
+		When{policy: &domain.RetryPolicy{MaxAttempts: 3}, attempt: 3, exit: failed}, false,
+	))
+	t.Run("it does not retry run without exit", theory(
+		When{policy: &domain.RetryPolicy{MaxAttempts: 3}, attempt: 1, exit: nil}, false,
+	))
+	t.Run("it does not retry run exited without cause", theory(
+		When{policy: &domain.RetryPolicy{MaxAttempts: 3}, attempt: 1, exit: aborted}, false,
+	))
+	t.Run("it retries stucking run when on is empty", theory(
+		When{policy: &domain.RetryPolicy{MaxAttempts: 3}, attempt: 1, exit: stucking}, true,
+	))
+	t.Run("it does not retry stucking run when on is failed only", theory(
+		When{
+			policy:  &domain.RetryPolicy{MaxAttempts: 3, On: []domain.ExitCause{domain.ExitByFailure}},
+			attempt: 1, exit: stucking,
+		},
+		false,
+	))
+	t.Run("it retries failed run with listed exit code", theory(
+		When{
+			policy:  &domain.RetryPolicy{MaxAttempts: 3, ExitCodes: []uint8{1, 137}},
+			attempt: 1, exit: failed,
+		},
+		true,
+	))
+	t.Run("it does not retry failed run with unlisted exit code", theory(
+		When{
+			policy:  &domain.RetryPolicy{MaxAttempts: 3, ExitCodes: []uint8{137}},
+			attempt: 1, exit: failed,
+		},
+		false,
+	))
+	t.Run("exit codes do not restrict stucking run", theory(
+		When{
+			policy:  &domain.RetryPolicy{MaxAttempts: 3, ExitCodes: []uint8{137}},
+			attempt: 1, exit: stucking,
+		},
+		true,
+	))
+}
+
+func TestRetryPolicy_BackoffFor(t *testing.T) {
+	policy := &domain.RetryPolicy{MaxAttempts: 10, Backoff: 20 * time.Minute}
+	for attempt, want := range map[int]time.Duration{
+		1: 20 * time.Minute,
+		2: 40 * time.Minute,
+		3: time.Hour,
+		5: time.Hour,
+	} {
+		if got := policy.BackoffFor(attempt); got != want {
+			t.Errorf("BackoffFor(%d): got %s, want %s", attempt, got, want)
+		}
+	}
+
+	if got := (&domain.RetryPolicy{MaxAttempts: 2}).BackoffFor(3); got != 0 {
+		t.Errorf("BackoffFor without backoff: got %s, want 0", got)
+	}
+}
+
+func TestEnvVar_Validate(t *testing.T) {
+	for name, e := range map[string]domain.EnvVar{
+		"name is empty":        {Name: "", Value: "x"},
+		"name starts by digit": {Name: "1ST", Value: "x"},
+		"name has space":       {Name: "MY VAR", Value: "x"},
+		"value and reference are both given": {
+			Name: "TOKEN", Value: "x",
+			Secret: &domain.EnvVarKeyRef{Name: "api", Key: "token"},
+		},
+		"secret and config map are both referred": {
+			Name:      "TOKEN",
+			Secret:    &domain.EnvVarKeyRef{Name: "api", Key: "token"},
+			ConfigMap: &domain.EnvVarKeyRef{Name: "api", Key: "token"},
+		},
+		"secret has no key":      {Name: "TOKEN", Secret: &domain.EnvVarKeyRef{Name: "api"}},
+		"config map has no name": {Name: "TOKEN", ConfigMap: &domain.EnvVarKeyRef{Key: "token"}},
+	} {
+		t.Run("it rejects when "+name, func(t *testing.T) {
+			if err := e.Validate(); !errors.Is(err, domain.ErrInvalidEnv) {
+				t.Errorf("expected ErrInvalidEnv, but got %v", err)
+			}
+		})
+	}
+
+	for _, e := range []domain.EnvVar{
+		{Name: "MODE", Value: "train"},
+		{Name: "EMPTY"},
+		{Name: "my.var-1", Value: "x"},
+		{Name: "TOKEN", Secret: &domain.EnvVarKeyRef{Name: "api", Key: "token"}},
+		{Name: "ENDPOINT", ConfigMap: &domain.EnvVarKeyRef{Name: "api", Key: "endpoint"}},
+	} {
+		if err := e.Validate(); err != nil {
+			t.Errorf("unexpected error: %s: %v", e.Name, err)
+		}
+	}
+}
+
+func TestToleration_Validate(t *testing.T) {
+	for name, tol := range map[string]domain.Toleration{
+		"key is empty for Equal":  {Value: "x"},
+		"value is given to Exist": {Key: "gpu", Operator: domain.TolerationOpExists, Value: "x"},
+		"operator is unknown":     {Key: "gpu", Operator: "Gt", Value: "1"},
+		"effect is unknown":       {Key: "gpu", Value: "x", Effect: "NoRun"},
+		"key is malformed":        {Key: "-gpu", Value: "x"},
+		"value is malformed":      {Key: "gpu", Value: "a b"},
+	} {
+		t.Run("it rejects when "+name, func(t *testing.T) {
+			if err := tol.Validate(); !errors.Is(err, domain.ErrInvalidToleration) {
+				t.Errorf("expected ErrInvalidToleration, but got %v", err)
+			}
+		})
+	}
+
+	for _, tol := range []domain.Toleration{
+		{Key: "dedicated", Value: "training"},
+		{Key: "nvidia.com/gpu", Operator: domain.TolerationOpExists, Effect: domain.TaintEffectNoSchedule},
+		{Operator: domain.TolerationOpExists},
+	} {
+		if err := tol.Validate(); err != nil {
+			t.Errorf("unexpected error: %+v: %v", tol, err)
+		}
+	}
+}
+
+func TestValidateResourceRequests(t *testing.T) {
+	limits := map[string]resource.Quantity{
+		"cpu":    resource.MustParse("2"),
+		"memory": resource.MustParse("4Gi"),
+	}
+
+	for name, requests := range map[string]map[string]resource.Quantity{
+		"request exceeds limit": {"cpu": resource.MustParse("2500m")},
+		"request is negative":   {"memory": resource.MustParse("-1Gi")},
+	} {
+		t.Run("it rejects when "+name, func(t *testing.T) {
+			err := domain.ValidateResourceRequests(requests, limits)
+			if !errors.Is(err, domain.ErrInvalidResourceRequests) {
+				t.Errorf("expected ErrInvalidResourceRequests, but got %v", err)
+			}
+		})
+	}
+
+	if err := domain.ValidateResourceRequests(
+		map[string]resource.Quantity{
+			"cpu":               resource.MustParse("500m"),
+			"memory":            resource.MustParse("4Gi"),
+			"ephemeral-storage": resource.MustParse("10Gi"),
+		},
+		limits,
+	); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	for name, rp := range map[string]*domain.RetryPolicy{
+		"max attempts is zero": {MaxAttempts: 0},
+		"backoff is negative":  {MaxAttempts: 1, Backoff: -time.Second},
+		"cause is unknown":     {MaxAttempts: 1, On: []domain.ExitCause{"oom"}},
+	} {
+		t.Run("it rejects when "+name, func(t *testing.T) {
+			if err := rp.Validate(); !errors.Is(err, domain.ErrInvalidRetryPolicy) {
+				t.Errorf("expected ErrInvalidRetryPolicy, but got %v", err)
+			}
+		})
+	}
+
+	if err := (&domain.RetryPolicy{
+		MaxAttempts: 3, Backoff: time.Minute,
+		On: []domain.ExitCause{domain.ExitByFailure, domain.ExitByStucking},
+	}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
 }
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Quota caps the total resources held by Runs being starting or running,
+// whose Plans are in the scope of the Quota.
+//
+// Resources held by a Run are what its Plan requested when the Run was started:
+// resource requests, or limits for resources without requests.
+//
+// The scope is Plans which have Annotation and are registered by User.
+// Empty conditions are not used, so the Quota without both covers all Plans.
+type Quota struct {
+	// Name of the Quota.
+	Name string
+
+	// Annotation which Plans in the scope have. Optional.
+	Annotation *Annotation
+
+	// User who registered Plans in the scope. Optional.
+	//
+	// It is an email address of the user.
+	User string
+
+	// Limits are the max total of resources, like "cpu", "memory" or "nvidia.com/gpu".
+	//
+	// Resources not in Limits are unlimited.
+	Limits map[string]resource.Quantity
+}
+
+// QuotaExceeded is a resource of Quota which would be exceeded when a Run is started.
+type QuotaExceeded struct {
+	// Quota is the name of the Quota.
+	Quota string
+
+	// Resource is the type of the resource, like "cpu".
+	Resource string
+
+	// Limit is the limit of the resource in the Quota.
+	Limit resource.Quantity
+
+	// Used is the total of the resource held by Runs being starting or running.
+	Used resource.Quantity
+
+	// Requested is the resource which the Run requires.
+	Requested resource.Quantity
+}
+
+func (q QuotaExceeded) String() string {
+	return fmt.Sprintf(
+		"quota %s: %s %s requested, but %s of %s is in use",
+		q.Quota, q.Resource, q.Requested.String(), q.Used.String(), q.Limit.String(),
+	)
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// RetentionRule expires Data which have all of Tags,
+// after Age has passed since their timestamp (knit#timestamp).
+//
+// Expired Data are deleted together with the Run which has generated them,
+// only when all outputs of the Run are expired and no other Runs use them.
+type RetentionRule struct {
+	// Name of the rule.
+	Name string
+
+	// Tags which Data in the scope have. They should not be empty.
+	Tags []Tag
+
+	// Age is the duration to keep Data.
+	Age time.Duration
+}
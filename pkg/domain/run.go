@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/slices"
 )
 
 type KnitRunStatus string
@@ -143,6 +144,45 @@ func (pt *ProjectionTrigger) String() string {
 	)
 }
 
+// ScheduleTrigger describes a schedule of a Plan which has come.
+type ScheduleTrigger struct {
+	PlanId string
+
+	// Schedule is the cron expression of the Plan.
+	Schedule string
+
+	// At is the time when the schedule has come.
+	At time.Time
+}
+
+func (st *ScheduleTrigger) String() string {
+	if st == nil {
+		return "(not triggered)"
+	}
+	return fmt.Sprintf(
+		"plan{%s}.schedule{%s} at %s",
+		st.PlanId, st.Schedule, st.At.Format(time.RFC3339),
+	)
+}
+
+// RunPreview is a Run which would be created by projection.
+//
+// It is not registered actually.
+type RunPreview struct {
+	// Plan of the Run.
+	Plan PlanBody
+
+	// Inputs of the Run.
+	Inputs []Assignment
+}
+
+func (rp *RunPreview) Equal(o *RunPreview) bool {
+	return rp.Plan.Equal(&o.Plan) &&
+		cmp.SliceContentEqWith(
+			slices.RefOf(rp.Inputs), slices.RefOf(o.Inputs), (*Assignment).Equal,
+		)
+}
+
 type RunCursor struct {
 	// Id of run which is picked at last time
 	Head string
@@ -198,6 +238,11 @@ type RunFindQuery struct {
 
 	// match if run's updated time is earlier than this UpdatedUntil.
 	UpdatedUntil *time.Time
+
+	// match if run has exited with one of these causes.
+	//
+	// If it is nil or empty, it means "match any".
+	ExitCause []ExitCause
 }
 
 func (rfq RunFindQuery) Equal(other RunFindQuery) bool {
@@ -205,6 +250,7 @@ func (rfq RunFindQuery) Equal(other RunFindQuery) bool {
 		cmp.SliceContentEq(rfq.InputKnitId, other.InputKnitId) &&
 		cmp.SliceContentEq(rfq.OutputKnitId, other.OutputKnitId) &&
 		cmp.SliceContentEq(rfq.Status, other.Status) &&
+		cmp.SliceContentEq(rfq.ExitCause, other.ExitCause) &&
 		((rfq.UpdatedSince == nil && other.UpdatedSince == nil) ||
 			(rfq.UpdatedSince != nil && other.UpdatedSince != nil && rfq.UpdatedSince.Equal(*other.UpdatedSince))) &&
 		((rfq.UpdatedUntil == nil && other.UpdatedUntil == nil) ||
@@ -261,6 +307,22 @@ type RunBody struct {
 	// Exit status of the run, if any.
 	Exit *RunExit
 
+	// Attempt is the sequential number of the current attempt of the run, starting from 1.
+	//
+	// It is incremented when the run is retried.
+	Attempt int
+
+	// HoldReason is why the Run is kept ready, e.g. quotas are exhausted.
+	//
+	// It is empty unless the Run is ready and held.
+	HoldReason string
+
+	// ImageId is the ID of the image which the worker of the Run actually runs,
+	// reported by the container runtime (like "repo@sha256:...").
+	//
+	// It is empty until the worker starts.
+	ImageId string
+
 	// plan which the run is based.
 	PlanBody
 }
@@ -268,6 +330,61 @@ type RunBody struct {
 type RunExit struct {
 	Code    uint8
 	Message string
+
+	// Cause of the exit.
+	//
+	// Empty if the Run exited by other causes than its worker, e.g. aborted by user.
+	Cause ExitCause
+}
+
+// ExitCause is the cause of the exit of a Run.
+type ExitCause string
+
+const (
+	// The worker of the Run has failed.
+	ExitByFailure ExitCause = "failed"
+
+	// The worker of the Run has been stucking before running.
+	ExitByStucking ExitCause = "stucking"
+
+	// The worker of the Run has been terminated because it exceeded the max duration of its Plan.
+	//
+	// The exit code is cluster.ExitCodeDeadlineExceeded (252).
+	ExitByTimeout ExitCause = "timeout"
+)
+
+// AsExitCause parses a string as ExitCause.
+func AsExitCause(cause string) (ExitCause, error) {
+	switch c := ExitCause(cause); c {
+	case ExitByFailure, ExitByStucking, ExitByTimeout:
+		return c, nil
+	default:
+		return "", fmt.Errorf("'%s' is not ExitCause", cause)
+	}
+}
+
+// RunAttempt is a finished attempt of a Run which has been retried.
+type RunAttempt struct {
+	// Attempt is the sequential number of the attempt, starting from 1.
+	Attempt int
+
+	// Status of the Run when the attempt was finished.
+	Status KnitRunStatus
+
+	// Exit of the attempt, if any.
+	Exit *RunExit
+
+	// UpdatedAt is the time when the attempt was finished.
+	UpdatedAt time.Time
+}
+
+func (ra *RunAttempt) Equal(o *RunAttempt) bool {
+	exitEq := ra.Exit == nil && o.Exit == nil ||
+		(ra.Exit != nil && o.Exit != nil && *ra.Exit == *o.Exit)
+	return ra.Attempt == o.Attempt &&
+		ra.Status == o.Status &&
+		exitEq &&
+		ra.UpdatedAt.Equal(o.UpdatedAt)
 }
 
 func (rb *RunBody) Equal(o *RunBody) bool {
@@ -291,6 +408,9 @@ type Run struct {
 	Inputs  []Assignment
 	Outputs []Assignment
 	Log     *Log
+
+	// Attempts are the finished attempts before the current one, in order.
+	Attempts []RunAttempt
 }
 
 func (r *Run) Equal(other *Run) bool {
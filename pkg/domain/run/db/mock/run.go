@@ -14,12 +14,18 @@ type RunInterface struct {
 	Impl struct {
 		NewPseudo        func(ctx context.Context, planName domain.PseudoPlanName, lifecyclSuspend time.Duration) (string, error)
 		New              func(context.Context) ([]string, *domain.ProjectionTrigger, error)
+		NewScheduled     func(context.Context) ([]string, *domain.ScheduleTrigger, error)
+		NewParameters    func(context.Context) ([]string, error)
 		Finish           func(ctx context.Context, runId string) error
-		Find             func(ctx context.Context, query domain.RunFindQuery) ([]string, error)
+		Find             func(ctx context.Context, query domain.RunFindQuery, page domain.Page) ([]string, string, error)
 		Get              func(ctx context.Context, runId []string) (map[string]domain.Run, error)
 		SetStatus        func(ctx context.Context, runId string, newStatus domain.KnitRunStatus) error
 		SetExit          func(ctx context.Context, runId string, exit domain.RunExit) error
-		PickAndSetStatus func(ctx context.Context, cursor domain.RunCursor, callback func(domain.Run) (domain.KnitRunStatus, error)) (domain.RunCursor, bool, error)
+		Startable        func(ctx context.Context, runId string, maxConcurrentRuns int) (bool, error)
+		ExceededQuotas   func(ctx context.Context, runId string, quotas []domain.Quota) ([]domain.QuotaExceeded, error)
+		SetHoldReason    func(ctx context.Context, runId string, reason string) error
+		SetImageId       func(ctx context.Context, runId string, imageId string) error
+		PickAndSetStatus func(ctx context.Context, cursor domain.RunCursor, callback func(context.Context, domain.Run) (domain.KnitRunStatus, error), notify func(context.Context, domain.Run) error) (domain.RunCursor, bool, error)
 		Delete           func(ctx context.Context, runId string) error
 		DeleteWorker     func(ctx context.Context, runId string) error
 		Retry            func(ctx context.Context, runId string) error
@@ -30,9 +36,14 @@ type RunInterface struct {
 			planName         domain.PseudoPlanName
 			lifecycleSuspend time.Duration
 		}]
-		New       dbmock.CallLog[struct{}]
-		Finish    dbmock.CallLog[string]
-		Find      dbmock.CallLog[domain.RunFindQuery]
+		New           dbmock.CallLog[struct{}]
+		NewScheduled  dbmock.CallLog[struct{}]
+		NewParameters dbmock.CallLog[struct{}]
+		Finish        dbmock.CallLog[string]
+		Find          dbmock.CallLog[struct {
+			Query domain.RunFindQuery
+			Page  domain.Page
+		}]
 		Get       dbmock.CallLog[[]string]
 		SetStatus dbmock.CallLog[struct {
 			RunId     string
@@ -42,6 +53,22 @@ type RunInterface struct {
 			RunId string
 			Exit  domain.RunExit
 		}]
+		Startable dbmock.CallLog[struct {
+			RunId             string
+			MaxConcurrentRuns int
+		}]
+		ExceededQuotas dbmock.CallLog[struct {
+			RunId  string
+			Quotas []domain.Quota
+		}]
+		SetHoldReason dbmock.CallLog[struct {
+			RunId  string
+			Reason string
+		}]
+		SetImageId dbmock.CallLog[struct {
+			RunId   string
+			ImageId string
+		}]
 		PickAndSetStatus dbmock.CallLog[domain.RunCursor]
 		Delete           dbmock.CallLog[string]
 		DeleteWorker     dbmock.CallLog[string]
@@ -75,6 +102,24 @@ func (m *RunInterface) New(ctx context.Context) ([]string, *domain.ProjectionTri
 	panic(errors.New("it should not be called"))
 }
 
+func (m *RunInterface) NewScheduled(ctx context.Context) ([]string, *domain.ScheduleTrigger, error) {
+	m.Calls.NewScheduled = append(m.Calls.NewScheduled, struct{}{})
+	if m.Impl.NewScheduled != nil {
+		return m.Impl.NewScheduled(ctx)
+	}
+
+	panic(errors.New("it should not be called"))
+}
+
+func (m *RunInterface) NewParameters(ctx context.Context) ([]string, error) {
+	m.Calls.NewParameters = append(m.Calls.NewParameters, struct{}{})
+	if m.Impl.NewParameters != nil {
+		return m.Impl.NewParameters(ctx)
+	}
+
+	panic(errors.New("it should not be called"))
+}
+
 func (m *RunInterface) Finish(ctx context.Context, runId string) error {
 	m.Calls.Finish = append(m.Calls.Finish, runId)
 	if m.Impl.Finish != nil {
@@ -101,11 +146,12 @@ func (m *RunInterface) SetStatus(ctx context.Context, runId string, newStatus do
 func (m *RunInterface) PickAndSetStatus(
 	ctx context.Context,
 	cursor domain.RunCursor,
-	callback func(domain.Run) (domain.KnitRunStatus, error),
+	callback func(context.Context, domain.Run) (domain.KnitRunStatus, error),
+	notify func(context.Context, domain.Run) error,
 ) (domain.RunCursor, bool, error) {
 	m.Calls.PickAndSetStatus = append(m.Calls.PickAndSetStatus, cursor)
 	if m.Impl.PickAndSetStatus != nil {
-		return m.Impl.PickAndSetStatus(ctx, cursor, callback)
+		return m.Impl.PickAndSetStatus(ctx, cursor, callback, notify)
 	}
 
 	panic(errors.New("it should no be called"))
@@ -127,10 +173,76 @@ func (m *RunInterface) SetExit(ctx context.Context, runId string, exit domain.Ru
 
 }
 
-func (m *RunInterface) Find(ctx context.Context, query domain.RunFindQuery) ([]string, error) {
-	m.Calls.Find = append(m.Calls.Find, query)
+func (m *RunInterface) Startable(ctx context.Context, runId string, maxConcurrentRuns int) (bool, error) {
+	m.Calls.Startable = append(m.Calls.Startable, struct {
+		RunId             string
+		MaxConcurrentRuns int
+	}{
+		RunId:             runId,
+		MaxConcurrentRuns: maxConcurrentRuns,
+	})
+	if m.Impl.Startable != nil {
+		return m.Impl.Startable(ctx, runId, maxConcurrentRuns)
+	}
+
+	panic(errors.New("it should no be called"))
+}
+
+func (m *RunInterface) ExceededQuotas(ctx context.Context, runId string, quotas []domain.Quota) ([]domain.QuotaExceeded, error) {
+	m.Calls.ExceededQuotas = append(m.Calls.ExceededQuotas, struct {
+		RunId  string
+		Quotas []domain.Quota
+	}{
+		RunId:  runId,
+		Quotas: quotas,
+	})
+	if m.Impl.ExceededQuotas != nil {
+		return m.Impl.ExceededQuotas(ctx, runId, quotas)
+	}
+
+	panic(errors.New("it should no be called"))
+}
+
+func (m *RunInterface) SetHoldReason(ctx context.Context, runId string, reason string) error {
+	m.Calls.SetHoldReason = append(m.Calls.SetHoldReason, struct {
+		RunId  string
+		Reason string
+	}{
+		RunId:  runId,
+		Reason: reason,
+	})
+	if m.Impl.SetHoldReason != nil {
+		return m.Impl.SetHoldReason(ctx, runId, reason)
+	}
+
+	panic(errors.New("it should no be called"))
+}
+
+func (m *RunInterface) SetImageId(ctx context.Context, runId string, imageId string) error {
+	m.Calls.SetImageId = append(m.Calls.SetImageId, struct {
+		RunId   string
+		ImageId string
+	}{
+		RunId:   runId,
+		ImageId: imageId,
+	})
+	if m.Impl.SetImageId != nil {
+		return m.Impl.SetImageId(ctx, runId, imageId)
+	}
+
+	panic(errors.New("it should no be called"))
+}
+
+func (m *RunInterface) Find(ctx context.Context, query domain.RunFindQuery, page domain.Page) ([]string, string, error) {
+	m.Calls.Find = append(m.Calls.Find, struct {
+		Query domain.RunFindQuery
+		Page  domain.Page
+	}{
+		Query: query,
+		Page:  page,
+	})
 	if m.Impl.Find != nil {
-		return m.Impl.Find(ctx, query)
+		return m.Impl.Find(ctx, query, page)
 	}
 
 	panic(errors.New("it should no be called"))
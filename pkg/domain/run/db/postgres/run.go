@@ -15,7 +15,9 @@ import (
 	krun "github.com/opst/knitfab/pkg/domain/run/db"
 	xe "github.com/opst/knitfab/pkg/errors"
 	"github.com/opst/knitfab/pkg/utils/combination"
+	"github.com/opst/knitfab/pkg/utils/cron"
 	"github.com/opst/knitfab/pkg/utils/slices"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type NamingConvention interface {
@@ -96,16 +98,8 @@ func (m *runPG) NewPseudo(
 	}
 	defer tx.Rollback(ctx)
 
-	var pseudoPlanId string
-	if err := tx.QueryRow(
-		ctx, `select "plan_id" from "plan_pseudo" where "name" = $1;`, string(planName),
-	).Scan(&pseudoPlanId); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return "", kpgerr.Missing{
-				Table:    "plan",
-				Identity: fmt.Sprintf("System defined pseudo plan '%s'", planName),
-			}
-		}
+	pseudoPlanId, err := pseudoPlanIdOf(ctx, tx, planName)
+	if err != nil {
 		return "", err
 	}
 
@@ -190,6 +184,27 @@ func (m *runPG) New(ctx context.Context) ([]string, *domain.ProjectionTrigger, e
 		return nil, nil, err
 	}
 
+	newRunIds, trigger, err := m.projectPlan(ctx, tx, planId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if trigger == nil {
+		return nil, nil, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return newRunIds, trigger, nil
+}
+
+// projectPlan performs projection for one of updated nominations of the plan,
+// and marks the nomination as not updated.
+//
+// Returns ids of new Runs and the nomination triggering projection.
+// If there are no updated nominations, the trigger is nil.
+func (m *runPG) projectPlan(ctx context.Context, tx kpool.Tx, planId string) ([]string, *domain.ProjectionTrigger, error) {
 	// step 2. fetch nominations
 	nominations := map[int][]string{}     // mountpointId -> knitIds, nominated
 	var trigger *domain.ProjectionTrigger // mountpontId, knitId nominated
@@ -274,10 +289,6 @@ func (m *runPG) New(ctx context.Context) ([]string, *domain.ProjectionTrigger, e
 		return nil, nil, err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return nil, nil, err
-	}
-
 	return newRunIds, trigger, nil
 }
 
@@ -382,6 +393,292 @@ func (r *runPG) project(
 	return runIds, nil
 }
 
+func pseudoPlanIdOf(ctx context.Context, conn kpool.Queryer, planName domain.PseudoPlanName) (string, error) {
+	var pseudoPlanId string
+	if err := conn.QueryRow(
+		ctx, `select "plan_id" from "plan_pseudo" where "name" = $1;`, string(planName),
+	).Scan(&pseudoPlanId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", kpgerr.Missing{
+				Table:    "plan",
+				Identity: fmt.Sprintf("System defined pseudo plan '%s'", planName),
+			}
+		}
+		return "", err
+	}
+	return pseudoPlanId, nil
+}
+
+func (m *runPG) NewParameters(ctx context.Context) ([]string, error) {
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	type parameter struct {
+		inputId int
+		value   string
+	}
+	pending := []parameter{}
+	{
+		rows, err := tx.Query(
+			ctx,
+			`
+			select "input_id", "value" from "parameter_input"
+			where not "materialised"
+			order by "input_id", "value"
+			for update skip locked
+			`,
+		)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			p := parameter{}
+			if err := rows.Scan(&p.inputId, &p.value); err != nil {
+				return nil, err
+			}
+			pending = append(pending, p)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	pseudoPlanId, err := pseudoPlanIdOf(ctx, tx, domain.Parameter)
+	if err != nil {
+		return nil, err
+	}
+
+	runIds := []string{}
+	for _, p := range pending {
+		runId, err := m.register(ctx, tx, pseudoPlanId, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var knitId string
+		if err := tx.QueryRow(
+			ctx, `select "knit_id" from "data" where "run_id" = $1`, runId,
+		).Scan(&knitId); err != nil {
+			return nil, err
+		}
+
+		// the Data is nominated only to the input, when it is done.
+		if _, err := tx.Exec(
+			ctx,
+			`insert into "parameter_data" ("knit_id", "value", "input_id") values ($1, $2, $3)`,
+			knitId, p.value, p.inputId,
+		); err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.Exec(
+			ctx,
+			`
+			update "parameter_input" set "materialised" = true
+			where "input_id" = $1 and "value" = $2
+			`,
+			p.inputId, p.value,
+		); err != nil {
+			return nil, err
+		}
+
+		runIds = append(runIds, runId)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return runIds, nil
+}
+
+func (m *runPG) NewScheduled(ctx context.Context) ([]string, *domain.ScheduleTrigger, error) {
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// step 1. pick a plan whose schedule has come, and lock it.
+	var planId, expr string
+	var next time.Time
+	var active bool
+	if err := tx.QueryRow(
+		ctx,
+		`
+		select "plan_id", "schedule", "next", "active"
+		from "plan_schedule"
+		inner join "plan" using ("plan_id")
+		where "next" <= now()
+		order by "next"
+		limit 1
+		for update of "plan_schedule", "plan" skip locked
+		`,
+	).Scan(&planId, &expr, &next, &active); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, nil // nothing to do
+		}
+		return nil, nil, err
+	}
+	trigger := &domain.ScheduleTrigger{PlanId: planId, Schedule: expr, At: next}
+
+	// step 2. create a run with the latest data, if possible.
+	var runIds []string
+	if active {
+		pattern, err := m.latestInputs(ctx, tx, planId)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pattern != nil {
+			runId, err := m.registerIfNew(ctx, tx, planId, pattern)
+			if err != nil {
+				return nil, nil, err
+			}
+			if runId != "" {
+				runIds = append(runIds, runId)
+			}
+		}
+	}
+
+	// step final. reschedule.
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return nil, nil, xe.Wrap(err)
+	}
+	nextTime := schedule.Next(time.Now())
+	if nextTime.IsZero() {
+		// the schedule will not come anymore, in practice.
+		nextTime = time.Now().AddDate(5, 0, 0)
+	}
+	if _, err := tx.Exec(
+		ctx,
+		`update "plan_schedule" set "next" = $2 where "plan_id" = $1`,
+		planId, nextTime,
+	); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return runIds, trigger, nil
+}
+
+// latestInputs returns the most recent data nominated for each input of the plan.
+//
+// "most recent" means that the run generated the data has been updated most recently.
+//
+// # Returns
+//
+// - map[int]string: input id -> knit id. If some input has no data, it is nil.
+//
+// - error
+func (m *runPG) latestInputs(ctx context.Context, tx kpool.Tx, planId string) (map[int]string, error) {
+	rows, err := tx.Query(
+		ctx,
+		`
+		with "latest" as (
+			select distinct on ("input"."input_id")
+				"input"."input_id", "nomination"."knit_id"
+			from "input"
+			left outer join "nomination" using ("input_id")
+			left outer join "data" using ("knit_id")
+			left outer join "run" on "run"."run_id" = "data"."run_id"
+			where "input"."plan_id" = $1
+			order by "input"."input_id", "run"."updated_at" desc nulls last, "nomination"."knit_id" desc
+		)
+		select "input_id", "knit_id" from "latest"
+		`,
+		planId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pattern := map[int]string{}
+	for rows.Next() {
+		var inputId int
+		var knitId *string
+		if err := rows.Scan(&inputId, &knitId); err != nil {
+			return nil, err
+		}
+		if knitId == nil {
+			return nil, nil
+		}
+		pattern[inputId] = *knitId
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(pattern) == 0 {
+		return nil, nil
+	}
+	return pattern, nil
+}
+
+// registerIfNew registers a new run of the plan with the inputs,
+// unless a run with the same inputs exists already.
+//
+// # Returns
+//
+// - string: run id of the new run. If the run exists already, it is empty.
+//
+// - error
+func (m *runPG) registerIfNew(
+	ctx context.Context, tx kpool.Tx, planId string, pattern map[int]string,
+) (string, error) {
+	inputIds := make([]int, 0, len(pattern))
+	knitIds := make([]string, 0, len(pattern))
+	for inputId, knitId := range pattern {
+		inputIds = append(inputIds, inputId)
+		knitIds = append(knitIds, knitId)
+	}
+
+	var n int
+	if err := tx.QueryRow(
+		ctx,
+		`
+		with "assign_pattern" as (
+			select
+				unnest($2::int[]) as "input_id",
+				unnest($3::varchar[]) as "knit_id"
+		),
+		"known" as (
+			select "run_id", count(*) as "overlapped"
+			from "assign"
+			where "plan_id" = $1 and ("input_id", "knit_id") = any(table "assign_pattern")
+			group by "run_id"
+		)
+		select count(*) from "known"
+		where "overlapped" = (select count(*) from "assign_pattern")
+		`,
+		planId, inputIds, knitIds,
+	).Scan(&n); err != nil {
+		return "", err
+	}
+	if 0 < n {
+		return "", nil
+	}
+
+	runId, err := m.register(ctx, tx, planId, pattern)
+	if err != nil {
+		return "", err
+	}
+	if err := m.setWorker(ctx, tx, runId); err != nil {
+		return "", err
+	}
+	return runId, nil
+}
+
 // finish specified run.
 //
 // # Args
@@ -519,18 +816,29 @@ func (m *runPG) Finish(ctx context.Context, runId string) error {
 	return nil
 }
 
-func (m *runPG) find(ctx context.Context, conn kpool.Conn, query domain.RunFindQuery) ([]string, error) {
+func (m *runPG) find(ctx context.Context, conn kpool.Conn, query domain.RunFindQuery, page domain.Page) ([]string, string, error) {
 
-	runIds := []string{}
+	cursor, err := kpgintr.ParseCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	var afterRunId *string
+	if cursor != nil {
+		afterRunId = &cursor.Id
+	}
+	op, dir := kpgintr.PageDirection(page.Order)
 
 	rows, err := conn.Query(
 		ctx,
-		`
+		fmt.Sprintf(`
 		with "run" as (
 			select "run_id", "updated_at" from "run"
 			where
 			($1 or "plan_id" = ANY($2::varchar[]))
 			and ($3 or "status" = ANY($4::runStatus[]))
+			and ($11 or "run_id" in (
+				select "run_id" from "run_exit" where "cause" = ANY($12::varchar[])
+			))
 		),
 		"assign" as (
 			select distinct "run_id", "updated_at"
@@ -549,8 +857,10 @@ func (m *runPG) find(ctx context.Context, conn kpool.Conn, query domain.RunFindQ
 		where
 			($9::timestamp with time zone is null or "updated_at" >= $9::timestamp with time zone)
 			and ($10::timestamp with time zone is null or "updated_at" < $10::timestamp with time zone)
-		order by "updated_at", "run_id"
-		`,
+			and ($13::varchar is null or "run_id" %[1]s $13::varchar)
+		order by "run_id" %[2]s
+		limit $14
+		`, op, dir),
 		len(query.PlanId) == 0, query.PlanId,
 		len(query.Status) == 0, slices.Map(
 			query.Status, func(s domain.KnitRunStatus) string { return string(s) },
@@ -558,31 +868,42 @@ func (m *runPG) find(ctx context.Context, conn kpool.Conn, query domain.RunFindQ
 		len(query.InputKnitId) == 0, query.InputKnitId,
 		len(query.OutputKnitId) == 0, query.OutputKnitId,
 		query.UpdatedSince, query.UpdatedUntil,
+		len(query.ExitCause) == 0, slices.Map(
+			query.ExitCause, func(c domain.ExitCause) string { return string(c) },
+		),
+		afterRunId, kpgintr.PageLimit(page),
 	)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
+
+	runIds := []string{}
 	for rows.Next() {
-		var r string
-		if err := rows.Scan(&r); err != nil {
-			return nil, err
+		var runId string
+		if err := rows.Scan(&runId); err != nil {
+			return nil, "", err
 		}
-		runIds = append(runIds, r)
+		runIds = append(runIds, runId)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
 	}
 
-	return runIds, nil
+	// page by run id. it never changes, unlike "updated_at" changing on every status transition.
+	runIds, next := kpgintr.NextPage(page, runIds, func(r string) kpgintr.Cursor { return kpgintr.Cursor{Id: r} })
+	return runIds, next, nil
 }
 
-func (m *runPG) Find(ctx context.Context, query domain.RunFindQuery) ([]string, error) {
+func (m *runPG) Find(ctx context.Context, query domain.RunFindQuery, page domain.Page) ([]string, string, error) {
 
 	conn, err := m.pool.Acquire(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer conn.Release()
 
-	return m.find(ctx, conn, query)
+	return m.find(ctx, conn, query, page)
 }
 
 func (m *runPG) Get(ctx context.Context, runId []string) (map[string]domain.Run, error) {
@@ -937,7 +1258,7 @@ func (m *runPG) setStatus(
 			if err != nil {
 				return err
 			}
-			return nil
+			return auditRunStatus(ctx, tx, runId, current, newRunStatus)
 		}
 	case domain.Completing:
 		if newRunStatus == domain.Done {
@@ -945,7 +1266,7 @@ func (m *runPG) setStatus(
 			if err != nil {
 				return err
 			}
-			return nil
+			return auditRunStatus(ctx, tx, runId, current, newRunStatus)
 		}
 	}
 	if !allowed {
@@ -977,20 +1298,94 @@ func (m *runPG) setStatus(
 		}
 	}
 
-	return nil
+	if current == domain.Ready {
+		if err := recordResources(ctx, tx, runId); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			ctx, `delete from "run_start_reservation" where "run_id" = $1`, runId,
+		); err != nil {
+			return err
+		}
+	}
+
+	return auditRunStatus(ctx, tx, runId, current, newRunStatus)
 }
 
-// select the run which satisfies the specified condition, and change its status.
-func (m *runPG) PickAndSetStatus(
-	ctx context.Context,
-	cursor domain.RunCursor,
-	task func(r domain.Run) (domain.KnitRunStatus, error),
-) (domain.RunCursor, bool, error) {
-	tx, err := m.pool.Begin(ctx)
-	if err != nil {
-		return cursor, false, err
-	}
-	defer tx.Rollback(ctx)
+// recordResources records resources which the worker of the run requests, as the run is started.
+//
+// They are requests of the plan, or limits for resources without requests.
+func recordResources(ctx context.Context, tx kpool.Tx, runId string) error {
+	_, err := tx.Exec(
+		ctx,
+		`
+		with
+		"run" as (
+			select "plan_id" from "run" where "run_id" = $1
+		),
+		"requested" as (
+			select "type", coalesce("req"."value", "lim"."value") as "value"
+			from (
+				select "type", "value" from "plan_resource"
+				where "plan_id" in (table "run")
+			) as "lim"
+			full outer join (
+				select "type", "value" from "plan_resource_request"
+				where "plan_id" in (table "run")
+			) as "req" using ("type")
+		)
+		insert into "run_resource" ("run_id", "type", "value")
+		select $1, "type", "value" from "requested"
+		on conflict ("run_id", "type") do update set "value" = excluded."value"
+		`,
+		runId,
+	)
+	return err
+}
+
+func auditRunStatus(ctx context.Context, conn kpool.Queryer, runId string, before, after domain.KnitRunStatus) error {
+	return kpgintr.Audit(
+		ctx, conn, domain.AuditRunStatus, domain.AuditTargetRun, runId,
+		map[string]any{"status": before}, map[string]any{"status": after},
+	)
+}
+
+// begin starts a transaction.
+//
+// When ctx is passed to the task of PickAndSetStatus,
+// the transaction is nested in the transaction picking the Run (as a savepoint).
+// So, what is done in the transaction is committed or rolled back together with the status change of the Run.
+func (m *runPG) begin(ctx context.Context) (kpool.Tx, error) {
+	return kpool.BeginIn(ctx, m.pool)
+}
+
+// lockToStart serializes checks to start Runs until the end of the transaction.
+//
+// When planId is not empty, it locks for Runs of the Plan.
+// Otherwise, it locks for Runs of all Plans.
+//
+// To avoid deadlocks, take the lock for a Plan before the lock for all Plans.
+func lockToStart(ctx context.Context, tx kpool.Tx, planId string) error {
+	_, err := tx.Exec(
+		ctx,
+		`select pg_advisory_xact_lock(hashtext('knit#start-run'), hashtext($1))`,
+		planId,
+	)
+	return err
+}
+
+// select the run which satisfies the specified condition, and change its status.
+func (m *runPG) PickAndSetStatus(
+	ctx context.Context,
+	cursor domain.RunCursor,
+	task func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error),
+	notify func(ctx context.Context, r domain.Run) error,
+) (domain.RunCursor, bool, error) {
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return cursor, false, err
+	}
+	defer tx.Rollback(ctx)
 
 	var run domain.Run
 	{
@@ -1019,14 +1414,30 @@ func (m *runPG) PickAndSetStatus(
 					"plan_image"."plan_id" = "_run"."plan_id"
 					and not $3
 			),
+			"candidate" as (
+				table "run_image" union table "run_pseudo"
+			),
+			"next_run" as (
+				select "status" = $5 as "ready" from "run"
+				where "run_id" in (table "candidate")
+				order by "run_id" <= $4, "run_id"
+				limit 1
+			),
 			"target_run" as (
+				-- When the next Run in the cursor order is ready (that is, to be started),
+				-- ready Runs of Plans with higher priority go first.
+				-- Otherwise, Runs are picked in the cursor order.
 				select "run_id" from "run"
-				where "run_id" in (
-					table "run_image" union table "run_pseudo"
-				)
-				order by "run_id" <= $4, "run_id"
+				left outer join "plan_priority" using ("plan_id")
+				where "run_id" in (table "candidate")
+				order by
+					case
+						when (select "ready" from "next_run") and "status" = $5
+						then coalesce("priority", 0)
+					end desc nulls last,
+					"run_id" <= $4, "run_id"
 				limit 1
-				for no key update skip locked
+				for no key update of "run" skip locked
 			),
 			"assign" as (
 				select "knit_id" from "assign"
@@ -1048,6 +1459,7 @@ func (m *runPG) PickAndSetStatus(
 			slices.Map(cursor.Pseudo, domain.PseudoPlanName.String),
 			cursor.PseudoOnly,
 			cursor.Head,
+			domain.Ready,
 		).Scan(&runId, nil); err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
 				return cursor, false, nil
@@ -1072,7 +1484,7 @@ func (m *runPG) PickAndSetStatus(
 	}
 
 	// exec task() and get its result.
-	newStatus, err := task(run)
+	newStatus, err := task(kpool.WithTx(ctx, tx), run)
 	if err != nil {
 		return cursor, false, err
 	}
@@ -1080,6 +1492,22 @@ func (m *runPG) PickAndSetStatus(
 	if err := m.setStatus(ctx, tx, run.Id, newStatus, cursor.Debounce); err != nil {
 		return cursor, false, err
 	}
+	if notify != nil && run.Status != newStatus {
+		r, err := kpgintr.GetRun(ctx, tx, []string{run.Id})
+		if err != nil {
+			return cursor, false, err
+		}
+		if err := notify(kpool.WithTx(ctx, tx), r[run.Id]); err != nil {
+			return cursor, false, err
+		}
+	}
+	if run.Status != domain.Failed && newStatus == domain.Failed {
+		// decide to retry along with the status change,
+		// so that the retry is not lost when the process stops after committing.
+		if _, err := m.autoRetry(ctx, tx, run.Id); err != nil {
+			return cursor, false, err
+		}
+	}
 	// commit the transaction
 	if err := tx.Commit(ctx); err != nil {
 		return cursor, false, err
@@ -1087,6 +1515,397 @@ func (m *runPG) PickAndSetStatus(
 	return cursor, run.Status != newStatus, nil
 }
 
+func (m *runPG) Startable(ctx context.Context, runId string, maxConcurrentRuns int) (bool, error) {
+	// Not nested in the transaction picking the Run:
+	// locks should be released before webhooks are called and the worker is spawned.
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var planId string
+	if err := tx.QueryRow(
+		ctx, `select "plan_id" from "run" where "run_id" = $1`, runId,
+	).Scan(&planId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, kpgerr.Missing{
+				Table:    "run",
+				Identity: fmt.Sprintf("run_id = %s", runId),
+			}
+		}
+		return false, err
+	}
+
+	ok, err := startable(ctx, tx, runId, planId, maxConcurrentRuns)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		if err := reserveToStart(ctx, tx, runId); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// startReservationTTL is how long a reservation to start a Run is effective.
+//
+// Reservations are dropped when the Run leaves ready.
+// This is a fallback for Runs failed to be started after reserved (e.g. webhooks or spawning workers have failed).
+const startReservationTTL = 10 * time.Minute
+
+// activeToStart is the condition of Runs which are counted to decide to start another Run.
+//
+// They are starting, running, or ready and reserved to be started (see reserveToStart).
+const activeToStart = `(
+	"status" in ('starting', 'running')
+	or (
+		"status" = 'ready'
+		and "run_id" in (
+			select "run_id" from "run_start_reservation" where now() < "expires_at"
+		)
+	)
+)`
+
+// reserveToStart records that the Run is going to be started.
+//
+// Checks to start other Runs count the Run as active while it is ready and reserved,
+// so locks with lockToStart need not to be kept until the Run gets starting.
+func reserveToStart(ctx context.Context, tx kpool.Tx, runId string) error {
+	_, err := tx.Exec(
+		ctx,
+		`
+		insert into "run_start_reservation" ("run_id", "expires_at")
+		values ($1, now() + $2::interval)
+		on conflict ("run_id") do update set "expires_at" = excluded."expires_at"
+		`,
+		runId, startReservationTTL,
+	)
+	return err
+}
+
+// startable reports whether a ready Run of the Plan can be started now.
+//
+// Active Runs (see activeToStart) are counted after taking locks with lockToStart,
+// so that other transactions reserving Runs to start are committed already.
+//
+// The reservation of the Run itself, if any, is dropped.
+func startable(ctx context.Context, tx kpool.Tx, runId string, planId string, maxConcurrentRuns int) (bool, error) {
+	if err := lockToStart(ctx, tx, planId); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(
+		ctx, `delete from "run_start_reservation" where "run_id" = $1`, runId,
+	); err != nil {
+		return false, err
+	}
+
+	var priority int
+	var maxConcurrency *int
+	if err := tx.QueryRow(
+		ctx,
+		`
+		select coalesce("priority", 0), "max_concurrency"
+		from "plan"
+		left outer join "plan_priority" using ("plan_id")
+		where "plan_id" = $1
+		`,
+		planId,
+	).Scan(&priority, &maxConcurrency); err != nil {
+		return false, err
+	}
+
+	if maxConcurrency != nil {
+		var n int
+		if err := tx.QueryRow(
+			ctx,
+			`
+			select count(*) from "run"
+			where "plan_id" = $1 and `+activeToStart,
+			planId,
+		).Scan(&n); err != nil {
+			return false, err
+		}
+		if *maxConcurrency <= n {
+			return false, nil
+		}
+	}
+
+	if maxConcurrentRuns <= 0 {
+		return true, nil
+	}
+
+	if err := lockToStart(ctx, tx, ""); err != nil {
+		return false, err
+	}
+
+	var n int
+	if err := tx.QueryRow(
+		ctx,
+		`
+		select count(*) from "run"
+		inner join "plan_image" using ("plan_id")
+		where `+activeToStart,
+	).Scan(&n); err != nil {
+		return false, err
+	}
+	if maxConcurrentRuns <= n {
+		return false, nil
+	}
+
+	// give way to ready runs of plans with higher priority.
+	var preceded bool
+	if err := tx.QueryRow(
+		ctx,
+		`
+		with
+		"candidate" as (
+			select distinct "plan_id", "max_concurrency" from "run"
+			inner join "plan_image" using ("plan_id")
+			left outer join "plan_priority" using ("plan_id")
+			where "status" = $1 and $2 < coalesce("priority", 0)
+		),
+		"active" as (
+			select "plan_id", count(*) as "n" from "run"
+			where
+				"plan_id" in (select "plan_id" from "candidate")
+				and `+activeToStart+`
+			group by "plan_id"
+		)
+		select exists (
+			select 1 from "candidate"
+			left outer join "active" using ("plan_id")
+			where "max_concurrency" is null or coalesce("n", 0) < "max_concurrency"
+		)
+		`,
+		domain.Ready, priority,
+	).Scan(&preceded); err != nil {
+		return false, err
+	}
+
+	return !preceded, nil
+}
+
+func (m *runPG) ExceededQuotas(ctx context.Context, runId string, quotas []domain.Quota) ([]domain.QuotaExceeded, error) {
+	// Not nested in the transaction picking the Run, as Startable.
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var planId string
+	if err := tx.QueryRow(
+		ctx, `select "plan_id" from "run" where "run_id" = $1`, runId,
+	).Scan(&planId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, kpgerr.Missing{
+				Table:    "run",
+				Identity: fmt.Sprintf("run_id = %s", runId),
+			}
+		}
+		return nil, err
+	}
+
+	// Quotas cover Runs of many Plans. Usage should be summed after all other Runs being reserved are committed.
+	if err := lockToStart(ctx, tx, planId); err != nil {
+		return nil, err
+	}
+	if err := lockToStart(ctx, tx, ""); err != nil {
+		return nil, err
+	}
+
+	exceeded := []domain.QuotaExceeded{}
+	for _, q := range quotas {
+		var annotationKey, annotationValue, owner *string
+		if q.Annotation != nil {
+			annotationKey = &q.Annotation.Key
+			annotationValue = &q.Annotation.Value
+		}
+		if q.User != "" {
+			owner = &q.User
+		}
+
+		// resources to be requested by the run itself, and resources requested by active runs,
+		// in the scope of the quota.
+		//
+		// When the plan of the run is out of the scope, the run itself is not in the result,
+		// so nothing is requested.
+		rows, err := tx.Query(
+			ctx,
+			`
+			with
+			"scope" as (
+				select "plan_id" from "plan"
+				where
+					($1::varchar is null or "plan_id" in (
+						select "plan_id" from "plan_annotation"
+						where "key" = $1 and "value" = $2
+					))
+					and ($3::varchar is null or "plan_id" in (
+						select "plan_id" from "plan_owner" where "owner" = $3
+					))
+			),
+			"requested" as (
+				select "type", coalesce("req"."value", "lim"."value") as "value"
+				from (
+					select "type", "value" from "plan_resource" where "plan_id" = $4
+				) as "lim"
+				full outer join (
+					select "type", "value" from "plan_resource_request" where "plan_id" = $4
+				) as "req" using ("type")
+			)
+			select true, "type", "value" from "requested"
+			where $4 in (table "scope")
+			union all
+			select false, "type", "value"
+			from "run_resource"
+			inner join "run" using ("run_id")
+			where
+				"plan_id" in (table "scope")
+				and `+activeToStart+`
+				and "run_id" <> $5
+			`,
+			annotationKey, annotationValue, owner, planId, runId,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		used := map[string]resource.Quantity{}
+		requested := map[string]resource.Quantity{}
+		for rows.Next() {
+			var self bool
+			var typ string
+			var value kpgintr.ResourceQuantity
+			if err := rows.Scan(&self, &typ, &value); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			sum := used
+			if self {
+				sum = requested
+			}
+			total := sum[typ]
+			total.Add(resource.Quantity(value))
+			sum[typ] = total
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		limited := slices.Sorted(slices.KeysOf(q.Limits), func(a, b string) bool { return a < b })
+		for _, typ := range limited {
+			req, ok := requested[typ]
+			if !ok || req.IsZero() {
+				continue
+			}
+			limit := q.Limits[typ]
+			total := used[typ].DeepCopy()
+			total.Add(req)
+			if limit.Cmp(total) < 0 {
+				exceeded = append(exceeded, domain.QuotaExceeded{
+					Quota:     q.Name,
+					Resource:  typ,
+					Limit:     limit,
+					Used:      used[typ],
+					Requested: req,
+				})
+			}
+		}
+	}
+
+	if len(exceeded) == 0 {
+		// Resources of the Run are counted by other Runs while it is reserved.
+		if err := reserveToStart(ctx, tx, runId); err != nil {
+			return nil, err
+		}
+		if err := recordResources(ctx, tx, runId); err != nil {
+			return nil, err
+		}
+	} else if _, err := tx.Exec(
+		ctx, `delete from "run_start_reservation" where "run_id" = $1`, runId,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return exceeded, nil
+}
+
+func (m *runPG) SetHoldReason(ctx context.Context, runId string, reason string) error {
+	tx, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if reason == "" {
+		if _, err := tx.Exec(ctx, `delete from "run_hold" where "run_id" = $1`, runId); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
+	cmd, err := tx.Exec(
+		ctx,
+		`
+		insert into "run_hold" ("run_id", "reason")
+		select "run_id", $2 from "run" where "run_id" = $1
+		on conflict ("run_id") do update
+		set "reason" = $2, "since" = now()
+		`,
+		runId, reason,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return kpgerr.Missing{
+			Table:    "run",
+			Identity: fmt.Sprintf("run_id = %s", runId),
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (m *runPG) SetImageId(ctx context.Context, runId string, imageId string) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	cmd, err := conn.Exec(
+		ctx,
+		`
+		insert into "run_image" ("run_id", "image_id")
+		select "run_id", $2 from "run" where "run_id" = $1
+		on conflict ("run_id") do update set "image_id" = $2
+		`,
+		runId, imageId,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return kpgerr.Missing{
+			Table:    "run",
+			Identity: fmt.Sprintf("run_id = %s", runId),
+		}
+	}
+	return nil
+}
+
 func (m *runPG) SetExit(ctx context.Context, runId string, exit domain.RunExit) error {
 	tx, err := m.pool.Begin(ctx)
 	if err != nil {
@@ -1097,14 +1916,15 @@ func (m *runPG) SetExit(ctx context.Context, runId string, exit domain.RunExit)
 	if _, err := tx.Exec(
 		ctx,
 		`
-		insert into "run_exit" ("run_id", "exit_code", "message")
-		values ($1, $2, $3)
+		insert into "run_exit" ("run_id", "exit_code", "message", "cause")
+		values ($1, $2, $3, $4)
 		on conflict ("run_id") do update
 		set
 			"exit_code" = $2,
-			"message" = $3
+			"message" = $3,
+			"cause" = $4
 		`,
-		runId, exit.Code, exit.Message,
+		runId, exit.Code, exit.Message, string(exit.Cause),
 	); err != nil {
 		return err
 	}
@@ -1166,6 +1986,9 @@ func (m *runPG) delete(ctx context.Context, tx kpool.Tx, runId string) error {
 			with
 			"drop_assign" as (
 				delete from "assign" where "run_id" = $1
+			),
+			"drop_attempt" as (
+				delete from "run_attempt" where "run_id" = $1
 			)
 			delete from "run" where "run_id" = $1
 			`, runId,
@@ -1187,7 +2010,10 @@ func (m *runPG) delete(ctx context.Context, tx kpool.Tx, runId string) error {
 		}
 	}
 
-	return nil
+	return kpgintr.Audit(
+		ctx, tx, domain.AuditRunDelete, domain.AuditTargetRun, runId,
+		map[string]any{"status": runStatus}, nil,
+	)
 }
 
 func (m *runPG) Delete(ctx context.Context, runId string) error {
@@ -1242,6 +2068,88 @@ func (r *runPG) Retry(ctx context.Context, runId string) error {
 	}
 	defer tx.Rollback(ctx)
 
+	if err := r.retry(ctx, tx, runId, 0); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// autoRetry retries the failed run according to the retry policy of its plan.
+//
+// It returns true if the run is retried.
+// Runs protected from retry are left failed.
+func (r *runPG) autoRetry(ctx context.Context, tx kpool.Tx, runId string) (bool, error) {
+	if _, err := tx.Exec(
+		ctx, `select 1 from "run" where "run_id" = $1 for update`, runId,
+	); err != nil {
+		return false, err
+	}
+
+	bodies, err := kpgintr.GetRunBody(ctx, tx, []string{runId})
+	if err != nil {
+		return false, err
+	}
+	rb, ok := bodies[runId]
+	if !ok {
+		return false, kpgerr.Missing{
+			Table:    "run",
+			Identity: fmt.Sprintf("run_id=%s", runId),
+		}
+	}
+
+	if rb.Status != domain.Failed || !rb.Retry.ShouldRetry(rb.Attempt, rb.Exit) {
+		return false, nil
+	}
+
+	if err := r.retry(ctx, tx, runId, rb.Retry.BackoffFor(rb.Attempt)); err != nil {
+		if errors.Is(err, domain.ErrRunIsProtected) {
+			// it cannot be retried. leave it failed.
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// retry records the current attempt of the run into its history,
+// discards outputs of the run and make it "waiting" again.
+//
+// The run will be picked up after backoff has passed.
+func (r *runPG) retry(ctx context.Context, tx kpool.Tx, runId string, backoff time.Duration) error {
+	// record the attempt before truncateRun drops "run_exit".
+	if _, err := tx.Exec(
+		ctx,
+		`
+		insert into "run_attempt"
+			("run_id", "attempt", "status", "updated_at", "exit_code", "message", "cause")
+		select
+			"run_id",
+			(select count(*) from "run_attempt" where "run_id" = $1) + 1,
+			"status", "updated_at",
+			"exit_code", "message", "cause"
+		from "run"
+		left outer join "run_exit" using ("run_id")
+		where "run_id" = $1
+		`,
+		runId,
+	); err != nil {
+		return err
+	}
+
+	// the next attempt may run another image, if the plan refers it by tag.
+	if _, err := tx.Exec(ctx, `delete from "run_image" where "run_id" = $1`, runId); err != nil {
+		return err
+	}
+	// resources are recorded again when the next attempt is started.
+	if _, err := tx.Exec(ctx, `delete from "run_resource" where "run_id" = $1`, runId); err != nil {
+		return err
+	}
+
 	if err := r.truncateRun(ctx, tx, runId); err != nil {
 		return err
 	}
@@ -1300,18 +2208,18 @@ func (r *runPG) Retry(ctx context.Context, runId string) error {
 		update "run" set
 			"status" = $1,
 			"updated_at" = now(),
-			"lifecycle_suspend_until" = now()
+			"lifecycle_suspend_until" = now() + $3
 		where "run_id" = $2
 		`,
-		domain.Waiting, runId,
+		domain.Waiting, runId, backoff,
 	); err != nil {
 		return err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return err
-	}
-	return nil
+	return kpgintr.Audit(
+		ctx, tx, domain.AuditRunRetry, domain.AuditTargetRun, runId,
+		map[string]any{"status": status}, map[string]any{"status": domain.Waiting},
+	)
 }
 
 // truncateRun truncates the downward resources of the run.
@@ -1395,6 +2303,10 @@ func (m *runPG) truncateRun(
 			delete from "run_exit"
 			where "run_id" in (select "run_id" from "drop_invalidated_assignment")
 		),
+		"drop_invalidated_run_attempt" as (
+			delete from "run_attempt"
+			where "run_id" in (select "run_id" from "drop_invalidated_assignment")
+		),
 		"drop_invalidated_run" as (
 			delete from "run"
 			where "run_id" in (select "run_id" from "drop_invalidated_assignment")
@@ -0,0 +1,315 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgrun "github.com/opst/knitfab/pkg/domain/run/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+// givenRunsWithExit returns a Plan with image and a pseudo Plan, each of them has a Run in the status.
+//
+// Retry policies, exits and past attempts are up to callers.
+func givenRunsWithExit(
+	status domain.KnitRunStatus,
+	retry []tables.PlanRetry,
+	exit tables.RunExit,
+	attempts int,
+) tables.Operation {
+	TIMESTAMP := time.Now().Add(-time.Hour)
+
+	op := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: th.Padding36("plan"), Active: true, Hash: th.Padding36("#plan")},
+			{PlanId: th.Padding36("pseudo"), Active: true, Hash: th.Padding36("#pseudo")},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: th.Padding36("plan"), Image: "repo.invalid/image", Version: "v1"},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: th.Padding36("pseudo"), Name: "pseudo"},
+		},
+		PlanRetry: retry,
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1_010, PlanId: th.Padding36("plan"), Path: "/out/1"}:   {},
+			{OutputId: 2_010, PlanId: th.Padding36("pseudo"), Path: "/out/1"}: {},
+		},
+	}
+
+	for _, r := range []struct {
+		planId   string
+		runId    string
+		outputId int
+	}{
+		{planId: "plan", runId: "plan/run-failed", outputId: 1_010},
+		{planId: "pseudo", runId: "pseudo/run-failed", outputId: 2_010},
+	} {
+		runId := th.Padding36(r.runId)
+		exit := exit
+		exit.RunId = runId
+		op.Steps = append(op.Steps, tables.Step{
+			Run: tables.Run{
+				RunId: runId, PlanId: th.Padding36(r.planId), Status: status,
+				UpdatedAt: TIMESTAMP, LifecycleSuspendUntil: TIMESTAMP,
+			},
+			Outcomes: map[tables.Data]tables.DataAttibutes{
+				{
+					KnitId: th.Padding36(r.runId + "/out/1"), VolumeRef: r.runId + "/out/1",
+					OutputId: r.outputId, RunId: runId, PlanId: th.Padding36(r.planId),
+				}: {},
+			},
+			Exit: &exit,
+		})
+		for n := 1; n <= attempts; n++ {
+			op.RunAttempts = append(op.RunAttempts, tables.RunAttempt{
+				RunId: runId, Attempt: n, Status: domain.Failed, UpdatedAt: TIMESTAMP,
+			})
+		}
+	}
+
+	return op
+}
+
+func TestRun_PickAndSetStatus_AutoRetry(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	FAILED := tables.RunExit{ExitCode: 1, Message: "failed", Cause: string(domain.ExitByFailure)}
+
+	type Attempt struct {
+		Attempt  int
+		Status   domain.KnitRunStatus
+		ExitCode *int16
+		Cause    *string
+	}
+
+	type When struct {
+		runId string
+
+		// pick Runs of pseudo Plans, instead of Plans with image.
+		pseudo bool
+	}
+	type Then struct {
+		retried bool
+
+		// the backoff set to lifecycle_suspend_until. Only when retried.
+		backoff time.Duration
+
+		// attempts recorded for the Run.
+		attempts []Attempt
+	}
+
+	theory := func(given tables.Operation, when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			cursor := domain.RunCursor{Status: []domain.KnitRunStatus{domain.Aborting}}
+			if when.pseudo {
+				cursor.Pseudo = []domain.PseudoPlanName{"pseudo"}
+				cursor.PseudoOnly = true
+			}
+
+			testee := kpgrun.New(pool)
+			picked, changed, err := testee.PickAndSetStatus(
+				ctx, cursor,
+				func(context.Context, domain.Run) (domain.KnitRunStatus, error) {
+					return domain.Failed, nil
+				},
+				nil,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if picked.Head != when.runId || !changed {
+				t.Fatalf("picked: %s (changed: %v), expected = %s", picked.Head, changed, when.runId)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			{
+				type Run struct {
+					Status  domain.KnitRunStatus
+					Backoff float64
+				}
+				actual := try.To(scanner.New[Run]().QueryAll(
+					ctx, conn,
+					`
+					select
+						"status",
+						extract(epoch from "lifecycle_suspend_until" - "updated_at")::float8 as "backoff"
+					from "run" where "run_id" = $1
+					`,
+					when.runId,
+				)).OrFatal(t)
+				if len(actual) != 1 {
+					t.Fatalf("run: %+v", actual)
+				}
+
+				if then.retried {
+					expected := Run{Status: domain.Waiting, Backoff: then.backoff.Seconds()}
+					if actual[0] != expected {
+						t.Errorf("run: actual = %+v, expected = %+v", actual[0], expected)
+					}
+				} else if actual[0].Status != domain.Failed {
+					t.Errorf("status: actual = %s, expected = %s", actual[0].Status, domain.Failed)
+				}
+			}
+
+			{
+				actual := try.To(scanner.New[Attempt]().QueryAll(
+					ctx, conn,
+					`
+					select "attempt", "status", "exit_code", "cause" from "run_attempt"
+					where "run_id" = $1
+					`,
+					when.runId,
+				)).OrFatal(t)
+				if !cmp.SliceContentEqWith(actual, then.attempts, func(a, b Attempt) bool {
+					return a.Attempt == b.Attempt && a.Status == b.Status &&
+						cmp.PEqEq(a.ExitCode, b.ExitCode) && cmp.PEqEq(a.Cause, b.Cause)
+				}) {
+					t.Errorf("attempts: actual = %+v, expected = %+v", actual, then.attempts)
+				}
+			}
+
+			{
+				// outputs of the failed attempt are discarded only when retried.
+				actual := try.To(scanner.New[string]().QueryAll(
+					ctx, conn, `select "knit_id" from "garbage"`,
+				)).OrFatal(t)
+				if then.retried != (len(actual) == 1) {
+					t.Errorf("garbage: %v", actual)
+				}
+			}
+		}
+	}
+
+	exitCode := func(c int16) *int16 { return &c }
+	cause := func(c domain.ExitCause) *string { s := string(c); return &s }
+
+	t.Run("when the Run fails first, it is retried after the backoff", theory(
+		givenRunsWithExit(
+			domain.Aborting,
+			[]tables.PlanRetry{{PlanId: th.Padding36("plan"), MaxAttempts: 3, BackoffSeconds: 60}},
+			FAILED, 0,
+		),
+		When{runId: th.Padding36("plan/run-failed")},
+		Then{
+			retried: true,
+			backoff: 60 * time.Second,
+			attempts: []Attempt{
+				{Attempt: 1, Status: domain.Failed, ExitCode: exitCode(1), Cause: cause(domain.ExitByFailure)},
+			},
+		},
+	))
+
+	t.Run("when the Run has failed before, the backoff is doubled", theory(
+		givenRunsWithExit(
+			domain.Aborting,
+			[]tables.PlanRetry{{PlanId: th.Padding36("plan"), MaxAttempts: 3, BackoffSeconds: 60}},
+			FAILED, 1,
+		),
+		When{runId: th.Padding36("plan/run-failed")},
+		Then{
+			retried: true,
+			backoff: 120 * time.Second,
+			attempts: []Attempt{
+				{Attempt: 1, Status: domain.Failed},
+				{Attempt: 2, Status: domain.Failed, ExitCode: exitCode(1), Cause: cause(domain.ExitByFailure)},
+			},
+		},
+	))
+
+	t.Run("when the Run has reached the max attempts, it is not retried", theory(
+		givenRunsWithExit(
+			domain.Aborting,
+			[]tables.PlanRetry{{PlanId: th.Padding36("plan"), MaxAttempts: 2, BackoffSeconds: 60}},
+			FAILED, 1,
+		),
+		When{runId: th.Padding36("plan/run-failed")},
+		Then{
+			retried:  false,
+			attempts: []Attempt{{Attempt: 1, Status: domain.Failed}},
+		},
+	))
+
+	t.Run("when the Plan has no retry policy, it is not retried", theory(
+		givenRunsWithExit(domain.Aborting, nil, FAILED, 0),
+		When{runId: th.Padding36("plan/run-failed")},
+		Then{retried: false, attempts: []Attempt{}},
+	))
+
+	t.Run("when the Run exited without causes, it is not retried", theory(
+		givenRunsWithExit(
+			domain.Aborting,
+			[]tables.PlanRetry{{PlanId: th.Padding36("plan"), MaxAttempts: 3}},
+			tables.RunExit{ExitCode: 1, Message: "aborted"}, 0,
+		),
+		When{runId: th.Padding36("plan/run-failed")},
+		Then{retried: false, attempts: []Attempt{}},
+	))
+
+	t.Run("when the cause of the exit is not retried by the policy, it is not retried", theory(
+		givenRunsWithExit(
+			domain.Aborting,
+			[]tables.PlanRetry{{
+				PlanId: th.Padding36("plan"), MaxAttempts: 3,
+				RetryOn: []string{string(domain.ExitByStucking)},
+			}},
+			FAILED, 0,
+		),
+		When{runId: th.Padding36("plan/run-failed")},
+		Then{retried: false, attempts: []Attempt{}},
+	))
+
+	t.Run("when the exit code is not retried by the policy, it is not retried", theory(
+		givenRunsWithExit(
+			domain.Aborting,
+			[]tables.PlanRetry{{
+				PlanId: th.Padding36("plan"), MaxAttempts: 3, ExitCodes: []int16{2},
+			}},
+			FAILED, 0,
+		),
+		When{runId: th.Padding36("plan/run-failed")},
+		Then{retried: false, attempts: []Attempt{}},
+	))
+
+	t.Run("when the exit code is retried by the policy, it is retried", theory(
+		givenRunsWithExit(
+			domain.Aborting,
+			[]tables.PlanRetry{{
+				PlanId: th.Padding36("plan"), MaxAttempts: 3, ExitCodes: []int16{1, 2},
+			}},
+			FAILED, 0,
+		),
+		When{runId: th.Padding36("plan/run-failed")},
+		Then{
+			retried: true,
+			attempts: []Attempt{
+				{Attempt: 1, Status: domain.Failed, ExitCode: exitCode(1), Cause: cause(domain.ExitByFailure)},
+			},
+		},
+	))
+
+	t.Run("when the Run is protected from retry, it is not retried and nothing is changed", theory(
+		givenRunsWithExit(
+			domain.Aborting,
+			[]tables.PlanRetry{{PlanId: th.Padding36("pseudo"), MaxAttempts: 3}},
+			FAILED, 0,
+		),
+		When{runId: th.Padding36("pseudo/run-failed"), pseudo: true},
+		Then{retried: false, attempts: []Attempt{}},
+	))
+}
@@ -63,10 +63,11 @@ func TestRun_ChangingStatus_DatabaseIsEmpty(t *testing.T) {
 				wpool := proxy.Wrap(pgpool)
 				testee := kpgrun.New(wpool) // no new run
 				nextCursor, statusChanged, err := testee.PickAndSetStatus(
-					ctx, c, func(r domain.Run) (domain.KnitRunStatus, error) {
+					ctx, c, func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
 						t.Fatal("callback should not be called")
 						return domain.Aborting, nil
 					},
+					nil,
 				)
 				if err != nil {
 					t.Fatal(err)
@@ -184,10 +185,11 @@ func TestRun_ChangingStatus_DatabaseHasRuns_AllRunsAreLocked(t *testing.T) {
 				}
 				nextCursor, statusChanged, err := testee.PickAndSetStatus(
 					ctx, cursor,
-					func(r domain.Run) (domain.KnitRunStatus, error) {
+					func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
 						t.Fatal("callback should not be called")
 						return domain.Failed, nil
 					},
+					nil,
 				)
 				if err != nil {
 					t.Fatal(err)
@@ -250,10 +252,11 @@ func TestRun_ChangingStatus_DatabaseHasRuns_AllRunsAreSuspended(t *testing.T) {
 				}
 				nextCursor, statusChanged, err := testee.PickAndSetStatus(
 					ctx, cursor,
-					func(r domain.Run) (domain.KnitRunStatus, error) {
+					func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
 						t.Fatal("callback should not be called")
 						return domain.Failed, nil
 					},
+					nil,
 				)
 				if err != nil {
 					t.Fatal(err)
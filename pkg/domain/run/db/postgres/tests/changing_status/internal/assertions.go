@@ -252,9 +252,10 @@ func CanBeChanged(
 
 		before := try.To(th.PGNow(ctx, conn)).OrFatal(t)
 		called := false
+		notified := false
 		nextCursor, statusChanged, err := testee.PickAndSetStatus(
 			ctx, when.Cursor,
-			func(r domain.Run) (domain.KnitRunStatus, error) {
+			func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
 				called = true
 				if !r.Equal(&when.Target) {
 					t.Errorf(
@@ -264,6 +265,16 @@ func CanBeChanged(
 				}
 				return then.NewStatus, nil
 			},
+			func(ctx context.Context, r domain.Run) error {
+				notified = true
+				if r.Id != when.Target.Id || r.Status != then.NewStatus {
+					t.Errorf(
+						"unmatch run (passed to notify): id = %s, status = %s (expected: id = %s, status = %s)",
+						r.Id, r.Status, when.Target.Id, then.NewStatus,
+					)
+				}
+				return nil
+			},
 		)
 		after := try.To(th.PGNow(ctx, conn)).OrFatal(t)
 		if err != nil {
@@ -272,6 +283,9 @@ func CanBeChanged(
 		if wantStatusChanged := when.Target.Status != then.NewStatus; statusChanged != wantStatusChanged {
 			t.Errorf("unexpectedly status changed: want %+v, but got %+v", wantStatusChanged, statusChanged)
 		}
+		if notified != statusChanged {
+			t.Errorf("notified: want %+v, but got %+v", statusChanged, notified)
+		}
 		{
 			expected := domain.RunCursor{
 				Status:     when.Cursor.Status,
@@ -417,7 +431,7 @@ func CanBeChanged(
 		expectedError := errors.New("fake error")
 		nextCursor, statusChanged, err := testee.PickAndSetStatus(
 			ctx, when.Cursor,
-			func(r domain.Run) (domain.KnitRunStatus, error) {
+			func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
 				if !r.Equal(&when.Target) {
 					t.Errorf(
 						"unmatch run (passed to callback)\n===actual===\n%+v\n===expected===\n%+v",
@@ -426,6 +440,10 @@ func CanBeChanged(
 				}
 				return then.NewStatus, expectedError
 			},
+			func(ctx context.Context, r domain.Run) error {
+				t.Errorf("notify should not be called")
+				return nil
+			},
 		)
 		if statusChanged {
 			t.Errorf("unexpectedly status changed")
@@ -673,9 +691,13 @@ func ShouldNotBeChanged(
 
 		nextCursor, statusChanged, err := testee.PickAndSetStatus(
 			ctx, when.Cursor,
-			func(r domain.Run) (domain.KnitRunStatus, error) {
+			func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
 				return then.NewStatus, nil
 			},
+			func(ctx context.Context, r domain.Run) error {
+				t.Errorf("notify should not be called")
+				return nil
+			},
 		)
 		if err == nil || !errors.Is(err, domain.ErrInvalidRunStateChanging) {
 			t.Errorf("unexpected error: %+v", err)
@@ -799,9 +821,13 @@ func ShouldNotBeChanged(
 		expectedError := errors.New("fake error")
 		nextCursor, statusChanged, err := testee.PickAndSetStatus(
 			ctx, when.Cursor,
-			func(r domain.Run) (domain.KnitRunStatus, error) {
+			func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
 				return then.NewStatus, expectedError
 			},
+			func(ctx context.Context, r domain.Run) error {
+				t.Errorf("notify should not be called")
+				return nil
+			},
 		)
 		if statusChanged {
 			t.Errorf("unexpectedly status changed")
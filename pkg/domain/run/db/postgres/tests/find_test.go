@@ -2,6 +2,7 @@ package tests_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -1114,7 +1115,10 @@ func TestRun_Find(t *testing.T) {
 			}
 
 			testee := kpgrun.New(pgpool) // only finding. no new items.
-			actual := try.To(testee.Find(ctx, data.when)).OrFatal(t)
+			actual, _, err := testee.Find(ctx, data.when, domain.Page{})
+			if err != nil {
+				t.Fatal(err)
+			}
 
 			if !cmp.SliceEq(actual, data.then.run) {
 				t.Errorf(
@@ -1304,7 +1308,10 @@ func TestRun_Find_Since_and_Duration(t *testing.T) {
 			}
 
 			testee := kpgrun.New(pgpool) // only finding. no new items.
-			actual := try.To(testee.Find(ctx, data.when)).OrFatal(t)
+			actual, _, err := testee.Find(ctx, data.when, domain.Page{})
+			if err != nil {
+				t.Fatal(err)
+			}
 
 			if !cmp.SliceEq(actual, data.then.run) {
 				t.Errorf(
@@ -1316,3 +1323,138 @@ func TestRun_Find_Since_and_Duration(t *testing.T) {
 	}
 
 }
+
+func TestRun_Find_Page(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	baseTime := try.To(rfctime.ParseRFC3339DateTime(
+		"2023-04-01T13:34:45+00:00",
+	)).OrFatal(t).Time()
+
+	runIds := []string{
+		th.Padding36("plan-1-pseudo/run-1"),
+		th.Padding36("plan-1-pseudo/run-2"),
+		th.Padding36("plan-1-pseudo/run-3"),
+		th.Padding36("plan-1-pseudo/run-4"),
+		th.Padding36("plan-1-pseudo/run-5"),
+	}
+
+	given := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: th.Padding36("plan-1-pseudo"), Hash: "#pseudo", Active: true},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: th.Padding36("plan-1-pseudo"), Name: "uploaded"},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1_010, PlanId: th.Padding36("plan-1-pseudo"), Path: "/1/out/1"}: {},
+		},
+	}
+	for i, runId := range runIds {
+		// Runs are updated in the reverse order of their ids, and they are still ordered by their ids.
+		updatedAt := baseTime.Add(-time.Duration(i) * time.Minute)
+		given.Steps = append(given.Steps, tables.Step{
+			Run: tables.Run{
+				RunId:     runId,
+				PlanId:    th.Padding36("plan-1-pseudo"),
+				Status:    domain.Done,
+				UpdatedAt: updatedAt,
+			},
+			Outcomes: map[tables.Data]tables.DataAttibutes{
+				{
+					KnitId:    runId + "/out/1",
+					VolumeRef: "#" + runId + "/out/1",
+					RunId:     runId,
+					PlanId:    th.Padding36("plan-1-pseudo"),
+					OutputId:  1_010,
+				}: {},
+			},
+		})
+	}
+
+	for name, testcase := range map[string]struct {
+		order domain.Order
+
+		// runs whose status change between pages.
+		changing []string
+
+		want [][]string
+	}{
+		"pages in ascending order": {
+			order: domain.Ascending,
+			want: [][]string{
+				{runIds[0], runIds[1]},
+				{runIds[2], runIds[3]},
+				{runIds[4]},
+			},
+		},
+		"pages in descending order": {
+			order: domain.Descending,
+			want: [][]string{
+				{runIds[4], runIds[3]},
+				{runIds[2], runIds[1]},
+				{runIds[0]},
+			},
+		},
+		"pages do not shift when Runs change their status between pages": {
+			order:    domain.Ascending,
+			changing: []string{runIds[0], runIds[3]},
+			want: [][]string{
+				{runIds[0], runIds[1]},
+				{runIds[2], runIds[3]},
+				{runIds[4]},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			pgpool := poolBroaker.GetPool(ctx, t)
+			if err := given.Apply(ctx, pgpool); err != nil {
+				t.Fatal(err)
+			}
+
+			testee := kpgrun.New(pgpool)
+
+			page := domain.Page{Limit: 2, Order: testcase.order}
+			for nth, want := range testcase.want {
+				actual, next, err := testee.Find(ctx, domain.RunFindQuery{}, page)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !cmp.SliceEq(actual, want) {
+					t.Errorf("page #%d: (actual, expected) = (%v, %v)", nth, actual, want)
+				}
+
+				last := nth == len(testcase.want)-1
+				if last != (next == "") {
+					t.Fatalf("page #%d: unexpected cursor of the next page: %q", nth, next)
+				}
+				page.Cursor = next
+
+				for _, runId := range testcase.changing {
+					conn := try.To(pgpool.Acquire(ctx)).OrFatal(t)
+					_, err := conn.Exec(
+						ctx,
+						`update "run" set "status" = $2::runStatus, "updated_at" = now() where "run_id" = $1`,
+						runId, string(domain.Failed),
+					)
+					conn.Release()
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("when the cursor is broken, it returns ErrInvalidCursor", func(t *testing.T) {
+		ctx := context.Background()
+		pgpool := poolBroaker.GetPool(ctx, t)
+		testee := kpgrun.New(pgpool)
+
+		_, _, err := testee.Find(ctx, domain.RunFindQuery{}, domain.Page{Cursor: "not a cursor!"})
+		if !errors.Is(err, domain.ErrInvalidCursor) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
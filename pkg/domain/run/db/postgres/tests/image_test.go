@@ -0,0 +1,103 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgrun "github.com/opst/knitfab/pkg/domain/run/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestRun_SetImageId(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	FAILED := tables.RunExit{ExitCode: 1, Message: "failed", Cause: string(domain.ExitByFailure)}
+	IMAGE_ID_1 := "repo.invalid/image@sha256:" + th.Padding64("image-1")
+	IMAGE_ID_2 := "repo.invalid/image@sha256:" + th.Padding64("image-2")
+
+	t.Run("the image id is recorded, and overwritten by the next one", func(t *testing.T) {
+		ctx := context.Background()
+		pool := poolBroaker.GetPool(ctx, t)
+		given := givenRunsWithExit(domain.Failed, nil, FAILED, 0)
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgrun.New(pool)
+
+		runs := try.To(testee.Get(ctx, []string{th.Padding36("plan/run-failed")})).OrFatal(t)
+		if actual := runs[th.Padding36("plan/run-failed")].ImageId; actual != "" {
+			t.Errorf("image id before started: actual = %s, expected to be empty", actual)
+		}
+
+		for _, imageId := range []string{IMAGE_ID_1, IMAGE_ID_2} {
+			if err := testee.SetImageId(ctx, th.Padding36("plan/run-failed"), imageId); err != nil {
+				t.Fatal(err)
+			}
+
+			runs := try.To(testee.Get(ctx, []string{
+				th.Padding36("plan/run-failed"), th.Padding36("pseudo/run-failed"),
+			})).OrFatal(t)
+			if actual := runs[th.Padding36("plan/run-failed")].ImageId; actual != imageId {
+				t.Errorf("image id: actual = %s, expected = %s", actual, imageId)
+			}
+			if actual := runs[th.Padding36("pseudo/run-failed")].ImageId; actual != "" {
+				t.Errorf("image id of another run: actual = %s, expected to be empty", actual)
+			}
+		}
+	})
+
+	t.Run("when the Run is retried, the image id is cleared", func(t *testing.T) {
+		ctx := context.Background()
+		pool := poolBroaker.GetPool(ctx, t)
+		given := givenRunsWithExit(domain.Failed, nil, FAILED, 0)
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgrun.New(pool)
+		if err := testee.SetImageId(ctx, th.Padding36("plan/run-failed"), IMAGE_ID_1); err != nil {
+			t.Fatal(err)
+		}
+		if err := testee.Retry(ctx, th.Padding36("plan/run-failed")); err != nil {
+			t.Fatal(err)
+		}
+
+		runs := try.To(testee.Get(ctx, []string{th.Padding36("plan/run-failed")})).OrFatal(t)
+		if actual := runs[th.Padding36("plan/run-failed")].ImageId; actual != "" {
+			t.Errorf("image id after retry: actual = %s, expected to be empty", actual)
+		}
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+		actual := try.To(scanner.New[string]().QueryAll(
+			ctx, conn, `select "image_id" from "run_image"`,
+		)).OrFatal(t)
+		if len(actual) != 0 {
+			t.Errorf("run_image: %v", actual)
+		}
+	})
+
+	t.Run("when the Run is not found, it returns ErrMissing", func(t *testing.T) {
+		ctx := context.Background()
+		pool := poolBroaker.GetPool(ctx, t)
+		given := givenRunsWithExit(domain.Failed, nil, FAILED, 0)
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgrun.New(pool)
+		if err := testee.SetImageId(
+			ctx, th.Padding36("no-such-run"), IMAGE_ID_1,
+		); !errors.Is(err, kerr.ErrMissing) {
+			t.Errorf("error: actual = %v, expected = %v", err, kerr.ErrMissing)
+		}
+	})
+}
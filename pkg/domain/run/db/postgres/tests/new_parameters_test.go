@@ -0,0 +1,157 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgrun "github.com/opst/knitfab/pkg/domain/run/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestRun_NewParameters(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+
+	given := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: th.Padding36("pseudo-parameter"), Active: true, Hash: "#parameter"},
+			{PlanId: th.Padding36("plan"), Active: true, Hash: "#plan"},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: th.Padding36("pseudo-parameter"), Name: string(domain.Parameter)},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: th.Padding36("plan"), Image: "repo.invalid/image", Version: "v1"},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1, PlanId: th.Padding36("pseudo-parameter"), Path: "/parameter"}: {},
+		},
+		Inputs: map[tables.Input]tables.InputAttr{
+			{InputId: 100, PlanId: th.Padding36("plan"), Path: "/in/lr"}: {
+				UserTag: []domain.Tag{{Key: "type", Value: "lr"}},
+				Values:  []string{"0.1", "0.01"},
+			},
+			{InputId: 101, PlanId: th.Padding36("plan"), Path: "/in/dataset"}: {
+				UserTag: []domain.Tag{{Key: "type", Value: "dataset"}},
+			},
+		},
+	}
+
+	t.Run("it creates a Run of the parameter pseudo plan for each value", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgrun.New(pool)
+		runIds, err := testee.NewParameters(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(runIds) != 2 {
+			t.Fatalf("run ids: %v", runIds)
+		}
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+
+		{
+			runs := try.To(scanner.New[tables.Run]().QueryAll(
+				ctx, conn, `select * from "run" where "run_id" = any($1)`, runIds,
+			)).OrFatal(t)
+			if len(runs) != 2 {
+				t.Fatalf("runs: %+v", runs)
+			}
+			for _, r := range runs {
+				if r.PlanId != th.Padding36("pseudo-parameter") || r.Status != domain.Running {
+					t.Errorf("run: %+v", r)
+				}
+			}
+		}
+
+		type ParameterData struct {
+			Value   string
+			InputId int
+		}
+		{
+			actual := try.To(scanner.New[ParameterData]().QueryAll(
+				ctx, conn,
+				`
+				select "value", "input_id" from "parameter_data"
+				inner join "data" using ("knit_id")
+				where "run_id" = any($1)
+				`,
+				runIds,
+			)).OrFatal(t)
+			expected := []ParameterData{
+				{Value: "0.1", InputId: 100},
+				{Value: "0.01", InputId: 100},
+			}
+			if !cmp.SliceContentEq(actual, expected) {
+				t.Errorf("parameter data: actual = %+v, expected = %+v", actual, expected)
+			}
+		}
+
+		{
+			// Data are bound to the input, not by tags.
+			tagged := try.To(scanner.New[string]().QueryAll(
+				ctx, conn,
+				`
+				select "knit_id" from "tag_data"
+				inner join "data" using ("knit_id")
+				where "run_id" = any($1)
+				`,
+				runIds,
+			)).OrFatal(t)
+			if len(tagged) != 0 {
+				t.Errorf("parameter data are tagged: %v", tagged)
+			}
+		}
+
+		{
+			pending := try.To(scanner.New[string]().QueryAll(
+				ctx, conn, `select "value" from "parameter_input" where not "materialised"`,
+			)).OrFatal(t)
+			if len(pending) != 0 {
+				t.Errorf("values not materialised: %v", pending)
+			}
+		}
+
+		// values are materialised only once.
+		again, err := testee.NewParameters(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(again) != 0 {
+			t.Errorf("run ids created again: %v", again)
+		}
+	})
+
+	t.Run("when no values are pending, it creates nothing", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		given := given
+		given.Inputs = map[tables.Input]tables.InputAttr{
+			{InputId: 101, PlanId: th.Padding36("plan"), Path: "/in/dataset"}: {
+				UserTag: []domain.Tag{{Key: "type", Value: "dataset"}},
+			},
+		}
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgrun.New(pool)
+		runIds, err := testee.NewParameters(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(runIds) != 0 {
+			t.Errorf("run ids: %v", runIds)
+		}
+	})
+}
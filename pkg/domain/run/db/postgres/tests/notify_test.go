@@ -0,0 +1,97 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgrun "github.com/opst/knitfab/pkg/domain/run/db/postgres"
+	kpgwebhook "github.com/opst/knitfab/pkg/domain/webhook/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestRun_PickAndSetStatus_Notify(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	FAILED := tables.RunExit{ExitCode: 1, Message: "failed", Cause: string(domain.ExitByFailure)}
+
+	type Then struct {
+		err error
+
+		// status of the Run after PickAndSetStatus.
+		status domain.KnitRunStatus
+
+		// number of deliveries in the outbox.
+		queued int
+	}
+
+	theory := func(notifyErr error, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+			given := givenRunsWithExit(domain.Aborting, nil, FAILED, 0)
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			outbox := kpgwebhook.New(pool)
+			testee := kpgrun.New(pool)
+			_, _, err := testee.PickAndSetStatus(
+				ctx, domain.RunCursor{Status: []domain.KnitRunStatus{domain.Aborting}},
+				func(context.Context, domain.Run) (domain.KnitRunStatus, error) {
+					return domain.Failed, nil
+				},
+				func(ctx context.Context, r domain.Run) error {
+					if r.Status != domain.Failed {
+						t.Errorf("status of the notified Run: %s", r.Status)
+					}
+					if err := outbox.Enqueue(
+						ctx, "lifecycle-hooks", []string{"https://hook.invalid/after"}, []byte(`{}`),
+					); err != nil {
+						return err
+					}
+					return notifyErr
+				},
+			)
+			if !errors.Is(err, then.err) {
+				t.Errorf("error: actual = %v, expected = %v", err, then.err)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			status := try.To(scanner.New[string]().QueryAll(
+				ctx, conn, `select "status"::text from "run" where "run_id" = $1`,
+				th.Padding36("plan/run-failed"),
+			)).OrFatal(t)
+			if len(status) != 1 || status[0] != string(then.status) {
+				t.Errorf("status: actual = %v, expected = %s", status, then.status)
+			}
+
+			queued := try.To(scanner.New[int]().QueryAll(
+				ctx, conn, `select count(*) from "webhook_outbox"`,
+			)).OrFatal(t)
+			if len(queued) != 1 || queued[0] != then.queued {
+				t.Errorf("queued: actual = %v, expected = %d", queued, then.queued)
+			}
+		}
+	}
+
+	t.Run("when notify succeeds, things done in it are committed along with the status change", theory(
+		nil,
+		Then{status: domain.Failed, queued: 1},
+	))
+
+	{
+		fakeErr := errors.New("fake error")
+		t.Run("when notify fails, the status change and things done in it are rolled back", theory(
+			fakeErr,
+			Then{err: fakeErr, status: domain.Aborting, queued: 0},
+		))
+	}
+}
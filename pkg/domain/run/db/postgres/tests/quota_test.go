@@ -0,0 +1,351 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	kpgintr "github.com/opst/knitfab/pkg/domain/internal/db/postgres"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgrun "github.com/opst/knitfab/pkg/domain/run/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func quantity(s string) kpgintr.ResourceQuantity {
+	return kpgintr.ResourceQuantity(resource.MustParse(s))
+}
+
+// givenPlansWithResources returns Plans with image and their Runs.
+//
+// - plan-team: annotated "team=a". cpu limit 2, memory limit 4Gi and cpu request 1.
+// It has a running Run which requested cpu 3 when it was started, and a ready Run.
+//
+// - plan-other: not annotated. cpu limit 4. It has a running Run which requested cpu 4.
+//
+// - plan-team-done: annotated "team=a". It has a done Run which requested cpu 8.
+func givenPlansWithResources() tables.Operation {
+	op := tables.Operation{
+		PlanResources: []tables.PlanResource{
+			{PlanId: th.Padding36("plan-team"), Type: "cpu", Value: quantity("2")},
+			{PlanId: th.Padding36("plan-team"), Type: "memory", Value: quantity("4Gi")},
+			{PlanId: th.Padding36("plan-other"), Type: "cpu", Value: quantity("4")},
+		},
+		PlanResourceRequests: []tables.PlanResourceRequest{
+			{PlanId: th.Padding36("plan-team"), Type: "cpu", Value: quantity("1")},
+		},
+		PlanAnnotations: []tables.Annotation{
+			{PlanId: th.Padding36("plan-team"), Key: "team", Value: "a"},
+			{PlanId: th.Padding36("plan-team-done"), Key: "team", Value: "a"},
+		},
+		RunResources: []tables.RunResource{
+			// resources of the plan have been changed after the run started.
+			{RunId: th.Padding36("plan-team/run-running"), Type: "cpu", Value: quantity("3")},
+			{RunId: th.Padding36("plan-other/run-running"), Type: "cpu", Value: quantity("4")},
+			{RunId: th.Padding36("plan-team-done/run-done"), Type: "cpu", Value: quantity("8")},
+		},
+	}
+	for _, p := range []string{"plan-team", "plan-other", "plan-team-done"} {
+		op.Plan = append(op.Plan, tables.Plan{
+			PlanId: th.Padding36(p), Active: true, Hash: th.Padding36("#" + p),
+		})
+		op.PlanImage = append(op.PlanImage, tables.PlanImage{
+			PlanId: th.Padding36(p), Image: "repo.invalid/" + p, Version: "v1",
+		})
+	}
+
+	for _, r := range []struct {
+		planId string
+		runId  string
+		status domain.KnitRunStatus
+	}{
+		{planId: "plan-team", runId: "plan-team/run-running", status: domain.Running},
+		{planId: "plan-team", runId: "plan-team/run-ready", status: domain.Ready},
+		{planId: "plan-other", runId: "plan-other/run-running", status: domain.Running},
+		{planId: "plan-team-done", runId: "plan-team-done/run-done", status: domain.Done},
+	} {
+		op.Steps = append(op.Steps, tables.Step{
+			Run: tables.Run{
+				RunId:                 th.Padding36(r.runId),
+				PlanId:                th.Padding36(r.planId),
+				Status:                r.status,
+				UpdatedAt:             time.Now().Add(-time.Hour),
+				LifecycleSuspendUntil: time.Now().Add(-time.Hour),
+			},
+		})
+	}
+
+	return op
+}
+
+func TestRun_ExceededQuotas(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	type When struct {
+		runId  string
+		quotas []domain.Quota
+	}
+	type Then struct {
+		exceeded []domain.QuotaExceeded
+		err      error
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			given := givenPlansWithResources()
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			testee := kpgrun.New(pool)
+			actual, err := testee.ExceededQuotas(ctx, when.runId, when.quotas)
+			if then.err != nil {
+				if !errors.Is(err, then.err) {
+					t.Errorf("error: actual = %v, expected = %v", err, then.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.SliceEqWith(actual, then.exceeded, func(a, b domain.QuotaExceeded) bool {
+				return a.Quota == b.Quota &&
+					a.Resource == b.Resource &&
+					a.Limit.Cmp(b.Limit) == 0 &&
+					a.Used.Cmp(b.Used) == 0 &&
+					a.Requested.Cmp(b.Requested) == 0
+			}) {
+				t.Errorf("exceeded: actual = %v, expected = %v", actual, then.exceeded)
+			}
+		}
+	}
+
+	teamA := &domain.Annotation{Key: "team", Value: "a"}
+
+	t.Run("when the quota has room for the request, nothing is exceeded", theory(
+		When{
+			runId: th.Padding36("plan-team/run-ready"),
+			quotas: []domain.Quota{
+				{Name: "team-a", Annotation: teamA, Limits: map[string]resource.Quantity{
+					"cpu": resource.MustParse("4"),
+				}},
+			},
+		},
+		Then{exceeded: []domain.QuotaExceeded{}},
+	))
+
+	t.Run("when the request exceeds the quota, it is reported with resources recorded for running runs in the scope", theory(
+		When{
+			runId: th.Padding36("plan-team/run-ready"),
+			quotas: []domain.Quota{
+				{Name: "team-a", Annotation: teamA, Limits: map[string]resource.Quantity{
+					"cpu":    resource.MustParse("3.5"),
+					"memory": resource.MustParse("8Gi"),
+				}},
+			},
+		},
+		Then{exceeded: []domain.QuotaExceeded{
+			{
+				Quota:     "team-a",
+				Resource:  "cpu",
+				Limit:     resource.MustParse("3.5"),
+				Used:      resource.MustParse("3"),
+				Requested: resource.MustParse("1"),
+			},
+		}},
+	))
+
+	t.Run("when the quota covers all plans, runs of all plans are counted", theory(
+		When{
+			runId: th.Padding36("plan-team/run-ready"),
+			quotas: []domain.Quota{
+				{Name: "all", Limits: map[string]resource.Quantity{
+					"cpu":    resource.MustParse("7"),
+					"memory": resource.MustParse("2Gi"),
+				}},
+			},
+		},
+		Then{exceeded: []domain.QuotaExceeded{
+			{
+				Quota:     "all",
+				Resource:  "cpu",
+				Limit:     resource.MustParse("7"),
+				Used:      resource.MustParse("7"),
+				Requested: resource.MustParse("1"),
+			},
+			{
+				Quota:     "all",
+				Resource:  "memory",
+				Limit:     resource.MustParse("2Gi"),
+				Used:      resource.MustParse("0"),
+				Requested: resource.MustParse("4Gi"),
+			},
+		}},
+	))
+
+	t.Run("when the plan of the run is out of the scope, the quota is ignored", theory(
+		When{
+			runId: th.Padding36("plan-team/run-ready"),
+			quotas: []domain.Quota{
+				{Name: "team-b", Annotation: &domain.Annotation{Key: "team", Value: "b"}, Limits: map[string]resource.Quantity{
+					"cpu": resource.MustParse("0"),
+				}},
+			},
+		},
+		Then{exceeded: []domain.QuotaExceeded{}},
+	))
+
+	t.Run("when the run is not found, it returns ErrMissing", theory(
+		When{
+			runId: th.Padding36("no-such-run"),
+			quotas: []domain.Quota{
+				{Name: "all", Limits: map[string]resource.Quantity{"cpu": resource.MustParse("1")}},
+			},
+		},
+		Then{err: kerr.ErrMissing},
+	))
+}
+
+func TestRun_RunResource(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+
+	type Resource struct {
+		Type  string
+		Value string
+	}
+
+	t.Run("when a ready run is started, resources requested by its plan are recorded", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		given := givenPlansWithResources()
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgrun.New(pool)
+		if _, _, err := testee.PickAndSetStatus(
+			ctx, domain.RunCursor{Status: []domain.KnitRunStatus{domain.Ready}},
+			func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
+				return domain.Starting, nil
+			},
+			nil,
+		); err != nil {
+			t.Fatal(err)
+		}
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+		actual := try.To(scanner.New[Resource]().QueryAll(
+			ctx, conn,
+			`select "type", "value" from "run_resource" where "run_id" = $1`,
+			th.Padding36("plan-team/run-ready"),
+		)).OrFatal(t)
+
+		// cpu is requested, and memory is not requested but limited.
+		expected := []Resource{
+			{Type: "cpu", Value: "1"},
+			{Type: "memory", Value: "4Gi"},
+		}
+		if !cmp.SliceContentEq(actual, expected) {
+			t.Errorf("run_resource: actual = %v, expected = %v", actual, expected)
+		}
+	})
+
+	t.Run("when the run is retried, its resources are removed", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		given := givenPlansWithResources()
+		given.Steps = append(given.Steps, tables.Step{
+			Run: tables.Run{
+				RunId:                 th.Padding36("plan-team/run-failed"),
+				PlanId:                th.Padding36("plan-team"),
+				Status:                domain.Failed,
+				UpdatedAt:             time.Now().Add(-time.Hour),
+				LifecycleSuspendUntil: time.Now().Add(-time.Hour),
+			},
+		})
+		given.RunResources = append(given.RunResources, tables.RunResource{
+			RunId: th.Padding36("plan-team/run-failed"), Type: "cpu", Value: quantity("1"),
+		})
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgrun.New(pool)
+		if err := testee.Retry(ctx, th.Padding36("plan-team/run-failed")); err != nil {
+			t.Fatal(err)
+		}
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+		actual := try.To(scanner.New[Resource]().QueryAll(
+			ctx, conn,
+			`select "type", "value" from "run_resource" where "run_id" = $1`,
+			th.Padding36("plan-team/run-failed"),
+		)).OrFatal(t)
+		if len(actual) != 0 {
+			t.Errorf("run_resource: %v", actual)
+		}
+	})
+}
+
+func TestRun_SetHoldReason(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+
+	t.Run("it records and clears the reason", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		given := givenPlansWithResources()
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgrun.New(pool)
+		runId := th.Padding36("plan-team/run-ready")
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+		reasons := func() []string {
+			return try.To(scanner.New[string]().QueryAll(
+				ctx, conn, `select "reason" from "run_hold" where "run_id" = $1`, runId,
+			)).OrFatal(t)
+		}
+
+		if err := testee.SetHoldReason(ctx, runId, "quota exceeded"); err != nil {
+			t.Fatal(err)
+		}
+		if err := testee.SetHoldReason(ctx, runId, "quota exceeded again"); err != nil {
+			t.Fatal(err)
+		}
+		if actual := reasons(); !cmp.SliceEq(actual, []string{"quota exceeded again"}) {
+			t.Errorf("reasons: %v", actual)
+		}
+
+		if err := testee.SetHoldReason(ctx, runId, ""); err != nil {
+			t.Fatal(err)
+		}
+		if actual := reasons(); len(actual) != 0 {
+			t.Errorf("reasons: %v", actual)
+		}
+	})
+
+	t.Run("when the run is not found, it returns ErrMissing", func(t *testing.T) {
+		pool := poolBroaker.GetPool(ctx, t)
+		testee := kpgrun.New(pool)
+
+		err := testee.SetHoldReason(ctx, th.Padding36("no-such-run"), "quota exceeded")
+		if !errors.Is(err, kerr.ErrMissing) {
+			t.Errorf("error: actual = %v, expected = %v", err, kerr.ErrMissing)
+		}
+	})
+}
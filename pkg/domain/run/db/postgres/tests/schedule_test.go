@@ -0,0 +1,220 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgrun "github.com/opst/knitfab/pkg/domain/run/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+// givenScheduledPlan returns a Plan scheduled at due, and uploaded Data.
+//
+// - plan: with image, input 100 and output 110. Its schedule comes at due.
+//
+// - knit-old, knit-new: uploaded and nominated to input 100. knit-new is uploaded later.
+func givenScheduledPlan(due time.Time) tables.Operation {
+	op := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: th.Padding36("pseudo"), Active: true, Hash: th.Padding36("#pseudo")},
+			{PlanId: th.Padding36("plan"), Active: true, Hash: th.Padding36("#plan")},
+		},
+		PlanPseudo: []tables.PlanPseudo{
+			{PlanId: th.Padding36("pseudo"), Name: "knit#upload"},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: th.Padding36("plan"), Image: "repo.invalid/image", Version: "v1"},
+		},
+		PlanSchedule: []tables.PlanSchedule{
+			{PlanId: th.Padding36("plan"), Schedule: "0 0 * * *", Next: due},
+		},
+		Inputs: map[tables.Input]tables.InputAttr{
+			{InputId: 100, PlanId: th.Padding36("plan"), Path: "/in"}: {},
+		},
+		Outputs: map[tables.Output]tables.OutputAttr{
+			{OutputId: 1, PlanId: th.Padding36("pseudo"), Path: "/out"}: {},
+			{OutputId: 110, PlanId: th.Padding36("plan"), Path: "/out"}: {},
+		},
+	}
+
+	for _, d := range []struct {
+		knitId  string
+		updated time.Time
+	}{
+		{knitId: "knit-old", updated: time.Now().Add(-2 * time.Hour)},
+		{knitId: "knit-new", updated: time.Now().Add(-1 * time.Hour)},
+	} {
+		runId := th.Padding36("run/" + d.knitId)
+		op.Steps = append(op.Steps, tables.Step{
+			Run: tables.Run{
+				RunId: runId, PlanId: th.Padding36("pseudo"), Status: domain.Done,
+				UpdatedAt: d.updated, LifecycleSuspendUntil: d.updated,
+			},
+			Outcomes: map[tables.Data]tables.DataAttibutes{
+				{
+					KnitId: th.Padding36(d.knitId), VolumeRef: "vol/" + d.knitId,
+					OutputId: 1, RunId: runId, PlanId: th.Padding36("pseudo"),
+				}: {},
+			},
+		})
+		op.Nomination = append(op.Nomination, tables.Nomination{
+			KnitId: th.Padding36(d.knitId), InputId: 100,
+		})
+	}
+	return op
+}
+
+func TestRun_NewScheduled(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	DUE := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	type Then struct {
+		// trigger is expected to be returned.
+		triggered bool
+
+		// Data assigned to the new Run. Empty means no Runs are created.
+		assigned []tables.Assign
+	}
+
+	theory := func(given tables.Operation, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+			defer conn.Release()
+
+			runsBefore := try.To(scanner.New[string]().QueryAll(
+				ctx, conn, `select "run_id" from "run" where "plan_id" = $1`, th.Padding36("plan"),
+			)).OrFatal(t)
+
+			before := time.Now()
+			testee := kpgrun.New(pool)
+			runIds, trigger, err := testee.NewScheduled(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !then.triggered {
+				if trigger != nil || len(runIds) != 0 {
+					t.Errorf("triggered: run ids = %v, trigger = %+v", runIds, trigger)
+				}
+			} else {
+				if trigger == nil {
+					t.Fatal("trigger is nil")
+				}
+				if trigger.PlanId != th.Padding36("plan") ||
+					trigger.Schedule != "0 0 * * *" ||
+					!trigger.At.Equal(DUE) {
+					t.Errorf("trigger: %+v", trigger)
+				}
+
+				// the next schedule comes after now, regardless runs are created or not.
+				next := try.To(scanner.New[time.Time]().QueryAll(
+					ctx, conn,
+					`select "next" from "plan_schedule" where "plan_id" = $1`,
+					th.Padding36("plan"),
+				)).OrFatal(t)
+				if len(next) != 1 || !next[0].After(before) {
+					t.Errorf("next schedule: %v", next)
+				}
+			}
+
+			runsAfter := try.To(scanner.New[string]().QueryAll(
+				ctx, conn, `select "run_id" from "run" where "plan_id" = $1`, th.Padding36("plan"),
+			)).OrFatal(t)
+
+			if len(then.assigned) == 0 {
+				if len(runIds) != 0 {
+					t.Errorf("run ids: %v", runIds)
+				}
+				if !cmp.SliceContentEq(runsAfter, runsBefore) {
+					t.Errorf("runs: actual = %v, expected = %v", runsAfter, runsBefore)
+				}
+				return
+			}
+
+			if len(runIds) != 1 {
+				t.Fatalf("run ids: %v", runIds)
+			}
+			if !cmp.SliceContentEq(runsAfter, append(runsBefore, runIds[0])) {
+				t.Errorf("runs: actual = %v, before = %v, created = %v", runsAfter, runsBefore, runIds)
+			}
+
+			actual := try.To(scanner.New[tables.Assign]().QueryAll(
+				ctx, conn,
+				`select "run_id", "input_id", "plan_id", "knit_id" from "assign" where "run_id" = $1`,
+				runIds[0],
+			)).OrFatal(t)
+			expected := []tables.Assign{}
+			for _, a := range then.assigned {
+				a.RunId = runIds[0]
+				expected = append(expected, a)
+			}
+			if !cmp.SliceContentEq(actual, expected) {
+				t.Errorf("assign: actual = %+v, expected = %+v", actual, expected)
+			}
+		}
+	}
+
+	t.Run("when the schedule has come, it creates a Run with the latest Data", theory(
+		givenScheduledPlan(DUE),
+		Then{
+			triggered: true,
+			assigned: []tables.Assign{
+				{InputId: 100, PlanId: th.Padding36("plan"), KnitId: th.Padding36("knit-new")},
+			},
+		},
+	))
+
+	t.Run("when the schedule has not come, it does nothing", theory(
+		givenScheduledPlan(time.Now().Add(time.Hour)),
+		Then{triggered: false},
+	))
+
+	t.Run("when the plan is deactivated, it creates no Runs but reschedules", func() func(*testing.T) {
+		given := givenScheduledPlan(DUE)
+		given.Plan = []tables.Plan{
+			{PlanId: th.Padding36("pseudo"), Active: true, Hash: th.Padding36("#pseudo")},
+			{PlanId: th.Padding36("plan"), Active: false, Hash: th.Padding36("#plan")},
+		}
+		return theory(given, Then{triggered: true})
+	}())
+
+	t.Run("when some input has no Data, it creates no Runs but reschedules", func() func(*testing.T) {
+		given := givenScheduledPlan(DUE)
+		given.Inputs = map[tables.Input]tables.InputAttr{
+			{InputId: 100, PlanId: th.Padding36("plan"), Path: "/in"}:   {},
+			{InputId: 200, PlanId: th.Padding36("plan"), Path: "/in/2"}: {},
+		}
+		return theory(given, Then{triggered: true})
+	}())
+
+	t.Run("when a Run with the latest Data exists, it creates no Runs but reschedules", func() func(*testing.T) {
+		given := givenScheduledPlan(DUE)
+		given.Steps = append(given.Steps, tables.Step{
+			Run: tables.Run{
+				RunId: th.Padding36("plan/run-done"), PlanId: th.Padding36("plan"), Status: domain.Done,
+				UpdatedAt: DUE, LifecycleSuspendUntil: DUE,
+			},
+			Assign: []tables.Assign{
+				{
+					RunId: th.Padding36("plan/run-done"), InputId: 100,
+					PlanId: th.Padding36("plan"), KnitId: th.Padding36("knit-new"),
+				},
+			},
+		})
+		return theory(given, Then{triggered: true})
+	}())
+}
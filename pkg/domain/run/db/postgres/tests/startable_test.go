@@ -0,0 +1,438 @@
+package tests_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/conn/db/postgres/scanner"
+	"github.com/opst/knitfab/pkg/domain"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgrun "github.com/opst/knitfab/pkg/domain/run/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/pointer"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+// givenPrioritizedPlans returns Plans with image and their Runs.
+//
+// - plan-limited: priority 0, max concurrency 1. It has a running Run and a ready Run.
+//
+// - plan-high: priority 10, no max concurrency. It has a ready Run.
+//
+// - plan-default: no records in plan_priority. It has a ready Run.
+func givenPrioritizedPlans() tables.Operation {
+	plans := []string{"plan-limited", "plan-high", "plan-default"}
+
+	op := tables.Operation{
+		PlanPriority: []tables.PlanPriority{
+			{PlanId: th.Padding36("plan-limited"), Priority: 0, MaxConcurrency: pointer.Ref(1)},
+			{PlanId: th.Padding36("plan-high"), Priority: 10},
+		},
+	}
+	for _, p := range plans {
+		op.Plan = append(op.Plan, tables.Plan{
+			PlanId: th.Padding36(p), Active: true, Hash: th.Padding36("#" + p),
+		})
+		op.PlanImage = append(op.PlanImage, tables.PlanImage{
+			PlanId: th.Padding36(p), Image: "repo.invalid/" + p, Version: "v1",
+		})
+	}
+
+	for _, r := range []struct {
+		planId string
+		runId  string
+		status domain.KnitRunStatus
+	}{
+		{planId: "plan-limited", runId: "plan-limited/run-running", status: domain.Running},
+		{planId: "plan-limited", runId: "plan-limited/run-ready", status: domain.Ready},
+		{planId: "plan-high", runId: "plan-high/run-ready", status: domain.Ready},
+		{planId: "plan-default", runId: "plan-default/run-ready", status: domain.Ready},
+	} {
+		op.Steps = append(op.Steps, tables.Step{
+			Run: tables.Run{
+				RunId:                 th.Padding36(r.runId),
+				PlanId:                th.Padding36(r.planId),
+				Status:                r.status,
+				UpdatedAt:             time.Now().Add(-time.Hour),
+				LifecycleSuspendUntil: time.Now().Add(-time.Hour),
+			},
+		})
+	}
+
+	return op
+}
+
+func TestRun_Startable(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	type When struct {
+		runId             string
+		maxConcurrentRuns int
+	}
+	type Then struct {
+		startable bool
+		err       error
+	}
+
+	theory := func(when When, then Then) func(*testing.T) {
+		return func(t *testing.T) {
+			ctx := context.Background()
+			pool := poolBroaker.GetPool(ctx, t)
+
+			given := givenPrioritizedPlans()
+			if err := given.Apply(ctx, pool); err != nil {
+				t.Fatal(err)
+			}
+
+			testee := kpgrun.New(pool)
+			startable, err := testee.Startable(ctx, when.runId, when.maxConcurrentRuns)
+			if then.err != nil {
+				if !errors.Is(err, then.err) {
+					t.Errorf("error: actual = %v, expected = %v", err, then.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if startable != then.startable {
+				t.Errorf("startable: actual = %v, expected = %v", startable, then.startable)
+			}
+		}
+	}
+
+	t.Run("when the max concurrency of the plan is reached, it is not startable", theory(
+		When{runId: th.Padding36("plan-limited/run-ready"), maxConcurrentRuns: 0},
+		Then{startable: false},
+	))
+
+	t.Run("when the plan has no limits and maxConcurrentRuns is unlimited, it is startable", theory(
+		When{runId: th.Padding36("plan-default/run-ready"), maxConcurrentRuns: 0},
+		Then{startable: true},
+	))
+
+	t.Run("when maxConcurrentRuns is reached, it is not startable", theory(
+		When{runId: th.Padding36("plan-high/run-ready"), maxConcurrentRuns: 1},
+		Then{startable: false},
+	))
+
+	t.Run("when a ready run of a plan with higher priority is waiting, it is not startable", theory(
+		When{runId: th.Padding36("plan-default/run-ready"), maxConcurrentRuns: 10},
+		Then{startable: false},
+	))
+
+	t.Run("when no ready runs of plans with higher priority are waiting, it is startable", theory(
+		When{runId: th.Padding36("plan-high/run-ready"), maxConcurrentRuns: 10},
+		Then{startable: true},
+	))
+
+	t.Run("when the run is not found, it returns ErrMissing", theory(
+		When{runId: th.Padding36("no-such-run"), maxConcurrentRuns: 10},
+		Then{err: kerr.ErrMissing},
+	))
+
+	t.Run("when ready runs of the plan are being started at once, only runs within the max concurrency are started", func(t *testing.T) {
+		ctx := context.Background()
+		pool := poolBroaker.GetPool(ctx, t)
+
+		given := tables.Operation{
+			Plan: []tables.Plan{
+				{PlanId: th.Padding36("plan"), Active: true, Hash: th.Padding36("#plan")},
+			},
+			PlanImage: []tables.PlanImage{
+				{PlanId: th.Padding36("plan"), Image: "repo.invalid/plan", Version: "v1"},
+			},
+			PlanPriority: []tables.PlanPriority{
+				{PlanId: th.Padding36("plan"), MaxConcurrency: pointer.Ref(1)},
+			},
+		}
+		for _, runId := range []string{"plan/run-1", "plan/run-2"} {
+			given.Steps = append(given.Steps, tables.Step{
+				Run: tables.Run{
+					RunId:                 th.Padding36(runId),
+					PlanId:                th.Padding36("plan"),
+					Status:                domain.Ready,
+					UpdatedAt:             time.Now().Add(-time.Hour),
+					LifecycleSuspendUntil: time.Now().Add(-time.Hour),
+				},
+			})
+		}
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgrun.New(pool)
+		cursor := domain.RunCursor{Status: []domain.KnitRunStatus{domain.Ready}}
+
+		// start a Run only when it is startable, like the run management loop.
+		start := func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
+			startable, err := testee.Startable(ctx, r.Id, 0)
+			if err != nil {
+				return r.Status, err
+			}
+			if !startable {
+				return r.Status, nil
+			}
+			return domain.Starting, nil
+		}
+
+		firstChecked := make(chan struct{})
+		firstDone := make(chan error, 1)
+		go func() {
+			_, _, err := testee.PickAndSetStatus(
+				ctx, cursor,
+				func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
+					status, err := start(ctx, r)
+					close(firstChecked)
+
+					// keep the picking transaction open while the other Run is checked.
+					time.Sleep(500 * time.Millisecond)
+					return status, err
+				},
+				nil,
+			)
+			firstDone <- err
+		}()
+
+		<-firstChecked
+		if _, _, err := testee.PickAndSetStatus(ctx, cursor, start, nil); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-firstDone:
+			t.Error("checking the other Run waited for the picking transaction")
+			return
+		default:
+		}
+		if err := <-firstDone; err != nil {
+			t.Fatal(err)
+		}
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+		statuses := try.To(scanner.New[string]().QueryAll(
+			ctx, conn, `select "status" from "run" order by "run_id"`,
+		)).OrFatal(t)
+
+		if !cmp.SliceContentEq(statuses, []string{domain.Starting.String(), domain.Ready.String()}) {
+			t.Errorf("statuses of runs: %v", statuses)
+		}
+	})
+
+	t.Run("a run found to be startable is reserved, and counted in checks for other runs", func(t *testing.T) {
+		ctx := context.Background()
+		pool := poolBroaker.GetPool(ctx, t)
+
+		given := tables.Operation{
+			Plan: []tables.Plan{
+				{PlanId: th.Padding36("plan"), Active: true, Hash: th.Padding36("#plan")},
+			},
+			PlanImage: []tables.PlanImage{
+				{PlanId: th.Padding36("plan"), Image: "repo.invalid/plan", Version: "v1"},
+			},
+			PlanPriority: []tables.PlanPriority{
+				{PlanId: th.Padding36("plan"), MaxConcurrency: pointer.Ref(1)},
+			},
+		}
+		for _, runId := range []string{"plan/run-1", "plan/run-2"} {
+			given.Steps = append(given.Steps, tables.Step{
+				Run: tables.Run{
+					RunId:                 th.Padding36(runId),
+					PlanId:                th.Padding36("plan"),
+					Status:                domain.Ready,
+					UpdatedAt:             time.Now().Add(-time.Hour),
+					LifecycleSuspendUntil: time.Now().Add(-time.Hour),
+				},
+			})
+		}
+		if err := given.Apply(ctx, pool); err != nil {
+			t.Fatal(err)
+		}
+
+		testee := kpgrun.New(pool)
+		for _, step := range []struct {
+			runId     string
+			startable bool
+		}{
+			{runId: "plan/run-1", startable: true},
+			{runId: "plan/run-2", startable: false},
+			// the reservation of the run itself is not counted.
+			{runId: "plan/run-1", startable: true},
+		} {
+			startable := try.To(testee.Startable(ctx, th.Padding36(step.runId), 0)).OrFatal(t)
+			if startable != step.startable {
+				t.Errorf("startable %s: actual = %v, expected = %v", step.runId, startable, step.startable)
+			}
+		}
+
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		defer conn.Release()
+		reserved := try.To(scanner.New[string]().QueryAll(
+			ctx, conn, `select "run_id" from "run_start_reservation"`,
+		)).OrFatal(t)
+		if !cmp.SliceEq(reserved, []string{th.Padding36("plan/run-1")}) {
+			t.Errorf("reserved runs: %v", reserved)
+		}
+
+		// the reservation is dropped when the run leaves ready.
+		if err := testee.SetStatus(ctx, th.Padding36("plan/run-1"), domain.Aborting); err != nil {
+			t.Fatal(err)
+		}
+		reserved = try.To(scanner.New[string]().QueryAll(
+			ctx, conn, `select "run_id" from "run_start_reservation"`,
+		)).OrFatal(t)
+		if len(reserved) != 0 {
+			t.Errorf("reserved runs: %v", reserved)
+		}
+	})
+}
+
+func TestRun_PickAndSetStatus_Priority(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+	pool := poolBroaker.GetPool(ctx, t)
+
+	given := tables.Operation{
+		PlanPriority: []tables.PlanPriority{
+			{PlanId: th.Padding36("plan-high"), Priority: 10},
+		},
+	}
+	for _, p := range []string{"plan-high", "plan-default"} {
+		given.Plan = append(given.Plan, tables.Plan{
+			PlanId: th.Padding36(p), Active: true, Hash: th.Padding36("#" + p),
+		})
+		given.PlanImage = append(given.PlanImage, tables.PlanImage{
+			PlanId: th.Padding36(p), Image: "repo.invalid/" + p, Version: "v1",
+		})
+	}
+	for _, r := range []struct {
+		planId string
+		runId  string
+	}{
+		{planId: "plan-high", runId: "run-1"},
+		{planId: "plan-default", runId: "run-2"},
+		{planId: "plan-default", runId: "run-3"},
+	} {
+		given.Steps = append(given.Steps, tables.Step{
+			Run: tables.Run{
+				RunId:                 th.Padding36(r.runId),
+				PlanId:                th.Padding36(r.planId),
+				Status:                domain.Ready,
+				UpdatedAt:             time.Now().Add(-time.Hour),
+				LifecycleSuspendUntil: time.Now().Add(-time.Hour),
+			},
+		})
+	}
+	if err := given.Apply(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+
+	testee := kpgrun.New(pool)
+
+	// The cursor is after the Run of the Plan with higher priority,
+	// but the Run should be picked first.
+	cursor := domain.RunCursor{
+		Head:   th.Padding36("run-1"),
+		Status: []domain.KnitRunStatus{domain.Ready},
+	}
+
+	picked := []string{}
+	for range 4 {
+		next, _, err := testee.PickAndSetStatus(
+			ctx, cursor,
+			func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
+				picked = append(picked, r.Id)
+				return domain.Starting, nil
+			},
+			nil,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cursor = next
+	}
+
+	expected := []string{th.Padding36("run-1"), th.Padding36("run-2"), th.Padding36("run-3")}
+	if !cmp.SliceEq(picked, expected) {
+		t.Errorf("picked runs: actual = %v, expected = %v", picked, expected)
+	}
+}
+
+func TestRun_PickAndSetStatus_PriorityOnlyForReady(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+	pool := poolBroaker.GetPool(ctx, t)
+
+	given := tables.Operation{
+		PlanPriority: []tables.PlanPriority{
+			{PlanId: th.Padding36("plan-high"), Priority: 10},
+		},
+	}
+	for _, p := range []string{"plan-high", "plan-default"} {
+		given.Plan = append(given.Plan, tables.Plan{
+			PlanId: th.Padding36(p), Active: true, Hash: th.Padding36("#" + p),
+		})
+		given.PlanImage = append(given.PlanImage, tables.PlanImage{
+			PlanId: th.Padding36(p), Image: "repo.invalid/" + p, Version: "v1",
+		})
+	}
+	for _, r := range []struct {
+		planId string
+		runId  string
+		status domain.KnitRunStatus
+	}{
+		{planId: "plan-default", runId: "run-1", status: domain.Running},
+		{planId: "plan-high", runId: "run-2", status: domain.Ready},
+		{planId: "plan-default", runId: "run-3", status: domain.Starting},
+	} {
+		given.Steps = append(given.Steps, tables.Step{
+			Run: tables.Run{
+				RunId:                 th.Padding36(r.runId),
+				PlanId:                th.Padding36(r.planId),
+				Status:                r.status,
+				UpdatedAt:             time.Now().Add(-time.Hour),
+				LifecycleSuspendUntil: time.Now().Add(-time.Hour),
+			},
+		})
+	}
+	if err := given.Apply(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+
+	testee := kpgrun.New(pool)
+
+	// Runs not being ready are picked in the cursor order,
+	// even if there is a ready Run of the Plan with higher priority.
+	cursor := domain.RunCursor{
+		Status: []domain.KnitRunStatus{domain.Ready, domain.Starting, domain.Running},
+	}
+
+	picked := []string{}
+	for range 4 {
+		next, _, err := testee.PickAndSetStatus(
+			ctx, cursor,
+			func(ctx context.Context, r domain.Run) (domain.KnitRunStatus, error) {
+				picked = append(picked, r.Id)
+				return r.Status, nil
+			},
+			nil,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cursor = next
+	}
+
+	expected := []string{
+		th.Padding36("run-1"), th.Padding36("run-2"), th.Padding36("run-3"), th.Padding36("run-1"),
+	}
+	if !cmp.SliceEq(picked, expected) {
+		t.Errorf("picked runs: actual = %v, expected = %v", picked, expected)
+	}
+}
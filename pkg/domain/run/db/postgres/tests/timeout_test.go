@@ -0,0 +1,107 @@
+package tests_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/domain/internal/db/postgres/tables"
+	th "github.com/opst/knitfab/pkg/domain/internal/db/postgres/testhelpers"
+	kpgrun "github.com/opst/knitfab/pkg/domain/run/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestRun_Timeout(t *testing.T) {
+	ctx := context.Background()
+	poolBroaker := testenv.NewPoolBroaker(ctx, t)
+	pool := poolBroaker.GetPool(ctx, t)
+
+	TIMESTAMP := time.Now().Add(-time.Hour)
+
+	given := tables.Operation{
+		Plan: []tables.Plan{
+			{PlanId: th.Padding36("plan"), Active: true, Hash: th.Padding36("#plan")},
+		},
+		PlanImage: []tables.PlanImage{
+			{PlanId: th.Padding36("plan"), Image: "repo.invalid/image", Version: "v1"},
+		},
+	}
+	for _, runId := range []string{"run-timeout", "run-failed", "run-done"} {
+		status := domain.Failed
+		if runId == "run-done" {
+			status = domain.Done
+		}
+		given.Steps = append(given.Steps, tables.Step{
+			Run: tables.Run{
+				RunId: th.Padding36(runId), PlanId: th.Padding36("plan"), Status: status,
+				UpdatedAt: TIMESTAMP, LifecycleSuspendUntil: TIMESTAMP,
+			},
+		})
+	}
+	if err := given.Apply(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+
+	testee := kpgrun.New(pool)
+	exits := map[string]domain.RunExit{
+		th.Padding36("run-timeout"): {Code: 1, Message: "deadline exceeded", Cause: domain.ExitByTimeout},
+		th.Padding36("run-failed"):  {Code: 2, Message: "failed", Cause: domain.ExitByFailure},
+		th.Padding36("run-done"):    {Code: 0, Message: "succeeded"},
+	}
+	for runId, exit := range exits {
+		if err := testee.SetExit(ctx, runId, exit); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("causes of exits are read back", func(t *testing.T) {
+		runs := try.To(testee.Get(ctx, []string{
+			th.Padding36("run-timeout"), th.Padding36("run-failed"), th.Padding36("run-done"),
+		})).OrFatal(t)
+		for runId, expected := range exits {
+			r, ok := runs[runId]
+			if !ok {
+				t.Errorf("run %s is missing", runId)
+				continue
+			}
+			if r.Exit == nil || *r.Exit != expected {
+				t.Errorf("exit of %s: actual = %+v, expected = %+v", runId, r.Exit, expected)
+			}
+		}
+	})
+
+	for name, testcase := range map[string]struct {
+		causes   []domain.ExitCause
+		expected []string
+	}{
+		"when runs are found by timeout, only timed-out runs are found": {
+			causes:   []domain.ExitCause{domain.ExitByTimeout},
+			expected: []string{th.Padding36("run-timeout")},
+		},
+		"when runs are found by some causes, runs exited by one of them are found": {
+			causes:   []domain.ExitCause{domain.ExitByTimeout, domain.ExitByFailure},
+			expected: []string{th.Padding36("run-timeout"), th.Padding36("run-failed")},
+		},
+		"when runs are found without causes, all runs are found": {
+			causes: nil,
+			expected: []string{
+				th.Padding36("run-timeout"), th.Padding36("run-failed"), th.Padding36("run-done"),
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, _, err := testee.Find(
+				ctx, domain.RunFindQuery{ExitCause: testcase.causes}, domain.Page{},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.SliceContentEq(actual, testcase.expected) {
+				t.Errorf("found runs: actual = %v, expected = %v", actual, testcase.expected)
+			}
+		})
+	}
+}
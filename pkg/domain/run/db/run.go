@@ -55,6 +55,40 @@ type Interface interface {
 	// Both []string and error can be nil when no runs are created.
 	New(context.Context) (runId []string, triggeredBy *domain.ProjectionTrigger, err error)
 
+	// create a new Run for a Plan whose cron schedule has come.
+	//
+	// This method picks a Plan whose schedule has come,
+	// and creates a new Run with the most recent Data nominated for each input of the Plan.
+	// The next schedule of the Plan is updated regardless a Run is created or not.
+	//
+	// No Runs are created when the Plan is deactivated,
+	// when some input of the Plan has no Data,
+	// or when a Run with the same inputs exists already.
+	//
+	// Returns
+	//
+	// - []string: created run ids
+	//
+	// - *ScheduleTrigger: the schedule which has come. If there are no schedule to be processed, this is nil.
+	//
+	// - error
+	NewScheduled(context.Context) (runId []string, triggeredBy *domain.ScheduleTrigger, err error)
+
+	// create new Runs materialising values of parameter inputs as Data.
+	//
+	// For each value of parameter inputs which is not materialised yet,
+	// this method creates a Run of the pseudo plan "knit#parameter" in Running state.
+	// The output Data of the Run has no Tags, and is nominated only to the input.
+	//
+	// Writing the value into the Data is a job of the pseudo plan manager.
+	//
+	// Returns
+	//
+	// - []string: created run ids
+	//
+	// - error
+	NewParameters(context.Context) (runId []string, err error)
+
 	// update run status.
 	//
 	// To change state to Invalidated, use Delete.
@@ -77,8 +111,119 @@ type Interface interface {
 	// update run exit.
 	SetExit(ctx context.Context, runId string, exit domain.RunExit) error
 
+	// Startable reports whether the ready Run can be started now.
+	//
+	// The Run cannot be started when...
+	//
+	// - active Runs of its Plan reach the max concurrency of the Plan, or
+	//
+	// - maxConcurrentRuns is positive, and active Runs of Plans with image reach it, or
+	//
+	// - maxConcurrentRuns is positive, and there is another ready Run
+	// whose Plan has higher priority and does not reach its max concurrency.
+	//
+	// Active Runs are ones being starting or running, and ones reserved to be started.
+	//
+	// When the Run can be started, it is reserved to be started until it leaves ready
+	// (or the reservation expires), so checks for other Runs count it as active.
+	// Otherwise, the reservation of the Run is dropped.
+	//
+	// The check and the reservation are committed in its own transaction, even if ctx is given to the task of PickAndSetStatus.
+	// So locks to serialize checks are not kept while webhooks are called and workers are spawned.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: runId to be started
+	//
+	// - int: the max number of Runs being starting or running in the whole of knitfab. 0 means unlimited.
+	//
+	// Returns
+	//
+	// - bool: true if the Run can be started.
+	//
+	// - error: ErrMissing (when run is not found for given runId)
+	Startable(ctx context.Context, runId string, maxConcurrentRuns int) (bool, error)
+
+	// ExceededQuotas returns resources of Quotas which would be exceeded when the ready Run is started.
+	//
+	// Quotas whose scope does not cover the Plan of the Run are ignored.
+	// Usage of a Quota is the total of resources of active Runs (see Startable)
+	// whose Plans are in the scope of the Quota.
+	// Resources of a Run are ones its Plan requested when the Run was reserved or started,
+	// that is, resource requests, or limits for resources without requests.
+	// The Run to be started is checked with the current resources of its Plan.
+	//
+	// When no quotas are exceeded, the Run is reserved to be started with its resources.
+	// Otherwise, the reservation of the Run is dropped.
+	// Like Startable, it is committed in its own transaction.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: runId to be started
+	//
+	// - []Quota: quotas to be checked
+	//
+	// Returns
+	//
+	// - []QuotaExceeded: exceeded resources. Empty if the Run can be started.
+	//
+	// - error: ErrMissing (when run is not found for given runId)
+	ExceededQuotas(ctx context.Context, runId string, quotas []domain.Quota) ([]domain.QuotaExceeded, error)
+
+	// SetHoldReason records why the ready Run is kept ready.
+	//
+	// Empty reason clears the record.
+	//
+	// The reason is shown only while the Run is ready.
+	//
+	// Pass the context given to the task of PickAndSetStatus to record in the transaction picking the Run.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: runId
+	//
+	// - string: reason
+	//
+	// Returns
+	//
+	// - error: ErrMissing (when run is not found for given runId)
+	SetHoldReason(ctx context.Context, runId string, reason string) error
+
+	// SetImageId records the image ID which the worker of the Run actually runs,
+	// as reported by the container runtime (like "repo@sha256:...").
+	//
+	// The record is replaced if any, and cleared when the Run is retried.
+	//
+	// Args
+	//
+	// - context.Context
+	//
+	// - string: runId
+	//
+	// - string: imageId
+	//
+	// Returns
+	//
+	// - error: ErrMissing (when run is not found for given runId)
+	SetImageId(ctx context.Context, runId string, imageId string) error
+
 	// pick next run of cursor, and change its status to the return value of func()
 	//
+	// Runs are picked in the order of run id, from the cursor.
+	// Only when the next run is ready, ready runs of plans with higher priority are picked first.
+	//
+	// When the run gets failed, it is retried in the same transaction
+	// if the retry policy of its plan allows (see Retry).
+	// The current attempt is recorded into the history of the run,
+	// and the run will be started again after the backoff of the policy.
+	// So, the retry is not lost even if the process is stopped after the status is changed.
+	//
 	// Args
 	//
 	// - context.Context
@@ -87,8 +232,16 @@ type Interface interface {
 	//
 	// - KnitRunStatus: status which run should be picked is.
 	//
-	// - func(Run) (KnitRunStatus, error): some task should occur along with Run state is transiting.
+	// - func(context.Context, Run) (KnitRunStatus, error): some task should occur along with Run state is transiting.
 	//             The return value of this func is to be the next state of the run.
+	//             The context is bound to the transaction picking the run.
+	//             Startable and ExceededQuotas called with it work in their own transactions, anyway.
+	//
+	// - func(context.Context, Run) error: called with the run after its status is changed, before committing.
+	//             It is not called when the status is not changed. It can be nil.
+	//             The context is bound to the transaction picking the run,
+	//             so things done with it (e.g. queueing webhooks) are committed along with the status change.
+	//             If it returns an error, the status change is rolled back.
 	//
 	// Return
 	//
@@ -99,7 +252,12 @@ type Interface interface {
 	//
 	// - error
 	// ErrInvalidRunStateChanging (when the run with given runId is not completing nor aborting),
-	PickAndSetStatus(ctx context.Context, cursorFrom domain.RunCursor, task func(domain.Run) (domain.KnitRunStatus, error)) (domain.RunCursor, bool, error)
+	PickAndSetStatus(
+		ctx context.Context,
+		cursorFrom domain.RunCursor,
+		task func(context.Context, domain.Run) (domain.KnitRunStatus, error),
+		notify func(context.Context, domain.Run) error,
+	) (domain.RunCursor, bool, error)
 
 	// update run status as "done" when completing or "failed" when aborting.
 	//
@@ -140,12 +298,17 @@ type Interface interface {
 	//
 	// - RunFindQuery: find runs which the query mathces
 	//
+	// - Page: the page of results to be retrieved. Runs are ordered by their run ids.
+	// Run ids never change, so pages do not shift even while Runs change their status.
+	//
 	// Returns
 	//
-	// - []string: found runIds.
+	// - []string: found runIds, in the page.
 	//
-	// - error
-	Find(context.Context, domain.RunFindQuery) ([]string, error)
+	// - string: cursor of the next page. empty if there are no more runs.
+	//
+	// - error: domain.ErrInvalidCursor if the cursor of the page is not understood.
+	Find(context.Context, domain.RunFindQuery, domain.Page) ([]string, string, error)
 
 	// Retreive Run
 	//
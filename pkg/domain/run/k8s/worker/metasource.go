@@ -3,6 +3,7 @@ package worker
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	bconf "github.com/opst/knitfab/pkg/configs/backend"
 	"github.com/opst/knitfab/pkg/domain"
@@ -100,6 +101,9 @@ type Executable struct {
 	Outputs        []domain.Assignment
 	Log            *domain.Assignment
 	ServiceAccount string
+
+	// MaxDuration is the time limit of the worker. 0 means unlimited.
+	MaxDuration time.Duration
 }
 
 type counter[T comparable] map[T]uint
@@ -224,6 +228,7 @@ func New(ex *domain.Run, envvars map[string]string) (*Executable, error) {
 		Outputs:        ex.Outputs,
 		Log:            log,
 		ServiceAccount: ex.ServiceAccount,
+		MaxDuration:    ex.MaxDuration,
 	}, nil
 }
 
@@ -273,25 +278,101 @@ func (r *Executable) Build(conf *bconf.KnitClusterConfig) *kubebatch.Job {
 		toVolumeMount,
 	))
 
+	// ephemeral directories, discarded with the pod.
+	scratches := []kubecore.Volume{}
+	scratchesMount := []kubecore.VolumeMount{}
+	for nth, sc := range r.PlanBody.Scratches {
+		emptyDir := &kubecore.EmptyDirVolumeSource{}
+		if sc.InMemory {
+			emptyDir.Medium = kubecore.StorageMediumMemory
+		}
+		if !sc.SizeLimit.IsZero() {
+			emptyDir.SizeLimit = ptr.Ref(sc.SizeLimit)
+		}
+		name := fmt.Sprintf("scratch-%d", nth)
+		scratches = append(scratches, kubecore.Volume{
+			Name:         name,
+			VolumeSource: kubecore.VolumeSource{EmptyDir: emptyDir},
+		})
+		scratchesMount = append(scratchesMount, kubecore.VolumeMount{
+			Name:      name,
+			MountPath: sc.Path,
+		})
+	}
+	if shm := r.PlanBody.ShmSize; !shm.IsZero() {
+		scratches = append(scratches, kubecore.Volume{
+			Name: "shm",
+			VolumeSource: kubecore.VolumeSource{
+				EmptyDir: &kubecore.EmptyDirVolumeSource{
+					Medium:    kubecore.StorageMediumMemory,
+					SizeLimit: ptr.Ref(shm),
+				},
+			},
+		})
+		scratchesMount = append(scratchesMount, kubecore.VolumeMount{
+			Name:      "shm",
+			MountPath: domain.ShmPath,
+		})
+	}
+
 	// setup minimal components
-	volumes := slices.Concat(inputs, outputs, logs)
+	volumes := slices.Concat(inputs, outputs, logs, scratches)
 
 	init := []kubecore.Container{}
 
-	resLimits := kubecore.ResourceList{}
-	for typ, val := range r.PlanBody.Resources {
-		switch typ {
-		case "cpu":
-			resLimits[kubecore.ResourceCPU] = val
-		case "memory":
-			resLimits[kubecore.ResourceMemory] = val
-		default:
-			resLimits[kubecore.ResourceName(typ)] = val
+	resourceList := func(resources map[string]resource.Quantity) kubecore.ResourceList {
+		if len(resources) == 0 {
+			return nil
 		}
-
+		list := kubecore.ResourceList{}
+		for typ, val := range resources {
+			switch typ {
+			case "cpu":
+				list[kubecore.ResourceCPU] = val
+			case "memory":
+				list[kubecore.ResourceMemory] = val
+			default:
+				list[kubecore.ResourceName(typ)] = val
+			}
+		}
+		return list
 	}
+	resLimits := resourceList(r.PlanBody.Resources)
+	if resLimits == nil {
+		resLimits = kubecore.ResourceList{}
+	}
+	resRequests := resourceList(r.PlanBody.ResourceRequests)
 
 	env := []kubecore.EnvVar{}
+	for _, e := range r.PlanBody.Env {
+		if _, ok := r.EnvVars[e.Name]; ok {
+			continue // overridden by hooks
+		}
+		ev := kubecore.EnvVar{Name: e.Name, Value: e.Value}
+		switch {
+		case e.Secret != nil:
+			ev = kubecore.EnvVar{
+				Name: e.Name,
+				ValueFrom: &kubecore.EnvVarSource{
+					SecretKeyRef: &kubecore.SecretKeySelector{
+						LocalObjectReference: kubecore.LocalObjectReference{Name: e.Secret.Name},
+						Key:                  e.Secret.Key,
+					},
+				},
+			}
+		case e.ConfigMap != nil:
+			ev = kubecore.EnvVar{
+				Name: e.Name,
+				ValueFrom: &kubecore.EnvVarSource{
+					ConfigMapKeyRef: &kubecore.ConfigMapKeySelector{
+						LocalObjectReference: kubecore.LocalObjectReference{Name: e.ConfigMap.Name},
+						Key:                  e.ConfigMap.Key,
+					},
+				},
+			}
+		}
+		env = append(env, ev)
+	}
 	if r.EnvVars != nil {
 		for k, v := range r.EnvVars {
 			env = append(env, kubecore.EnvVar{Name: k, Value: v})
@@ -310,13 +391,14 @@ func (r *Executable) Build(conf *bconf.KnitClusterConfig) *kubebatch.Job {
 
 	containers := []kubecore.Container{
 		{
-			Name:         "main",
-			Image:        fmt.Sprintf("%s:%s", r.Image.Image, r.Image.Version),
+			Name:         MainContainer,
+			Image:        r.Image.String(),
 			Command:      command,
 			Args:         args,
-			VolumeMounts: slices.Concat(readonly(inputsMount), writable(outputsMount)),
+			VolumeMounts: slices.Concat(readonly(inputsMount), writable(outputsMount), writable(scratchesMount)),
 			Resources: kubecore.ResourceRequirements{
-				Limits: resLimits,
+				Limits:   resLimits,
+				Requests: resRequests,
 			},
 			Env: env,
 		},
@@ -381,7 +463,7 @@ func (r *Executable) Build(conf *bconf.KnitClusterConfig) *kubebatch.Job {
 				Name:  "nurse",
 				Image: je.Nurse().Image(),
 				Args: slices.Concat(
-					[]string{"main"},
+					[]string{MainContainer},
 					slices.Map(logsMount, func(v kubecore.VolumeMount) string {
 						return filepath.Join(v.MountPath, "log")
 					}),
@@ -492,6 +574,19 @@ func (r *Executable) Build(conf *bconf.KnitClusterConfig) *kubebatch.Job {
 		}
 	}
 
+	for _, t := range r.Tolerations {
+		operator := kubecore.TolerationOperator(t.Operator)
+		if operator == "" {
+			operator = kubecore.TolerationOpEqual
+		}
+		tolerationSet[simpleTorelation{
+			Key:      t.Key,
+			Value:    t.Value,
+			Operator: operator,
+			Effect:   kubecore.TaintEffect(t.Effect),
+		}] = struct{}{}
+	}
+
 	var affinity *kubecore.Affinity
 	if 0 < len(aggregateAffinity.prefered)+len(aggregateAffinity.required) {
 		nodeAffinity := &kubecore.NodeAffinity{}
@@ -556,12 +651,28 @@ func (r *Executable) Build(conf *bconf.KnitClusterConfig) *kubebatch.Job {
 		automount = true
 	}
 
+	var activeDeadlineSeconds *int64
+	if 0 < r.MaxDuration {
+		activeDeadlineSeconds = ptr.Ref(int64(r.MaxDuration / time.Second))
+	}
+
+	priorityClassName := je.Priority()
+	if r.PriorityClassName != "" {
+		priorityClassName = r.PriorityClassName
+	}
+
+	var runtimeClassName *string
+	if r.RuntimeClassName != "" {
+		runtimeClassName = ptr.Ref(r.RuntimeClassName)
+	}
+
 	// compose!
 	return &kubebatch.Job{
 		ObjectMeta: r.ObjectMeta(conf.Namespace()),
 		Spec: kubebatch.JobSpec{
-			Parallelism:  ptr.Ref[int32](1),
-			BackoffLimit: ptr.Ref[int32](0),
+			Parallelism:           ptr.Ref[int32](1),
+			BackoffLimit:          ptr.Ref[int32](0),
+			ActiveDeadlineSeconds: activeDeadlineSeconds,
 			Template: kubecore.PodTemplateSpec{
 				Spec: kubecore.PodSpec{
 					RestartPolicy:                kubecore.RestartPolicyNever,
@@ -573,7 +684,8 @@ func (r *Executable) Build(conf *bconf.KnitClusterConfig) *kubebatch.Job {
 					Volumes:                      volumes,
 					Tolerations:                  tolerations,
 					Affinity:                     affinity,
-					PriorityClassName:            je.Priority(),
+					PriorityClassName:            priorityClassName,
+					RuntimeClassName:             runtimeClassName,
 				},
 			},
 		},
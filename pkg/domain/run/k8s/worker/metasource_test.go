@@ -3,6 +3,7 @@ package worker_test
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	bconf "github.com/opst/knitfab/pkg/configs/backend"
 	"github.com/opst/knitfab/pkg/domain"
@@ -92,6 +93,13 @@ func TestRunExecutable(t *testing.T) {
 				}
 			}
 
+			if !cmp.PEqEq(testee.Spec.ActiveDeadlineSeconds, then.ActiveDeadlineSeconds) {
+				t.Errorf(
+					"ActiveDeadlineSeconds: (actual, expected) = (%v, %v)",
+					testee.Spec.ActiveDeadlineSeconds, then.ActiveDeadlineSeconds,
+				)
+			}
+
 			{
 				actual := testee.Spec.Template.Spec.RestartPolicy
 				expected := then.Template.Spec.RestartPolicy
@@ -139,7 +147,10 @@ func TestRunExecutable(t *testing.T) {
 
 			{
 				actual := testee.Spec.Template.Spec.PriorityClassName
-				expected := "knit-worker-priority" // comes from config
+				expected := then.Template.Spec.PriorityClassName
+				if expected == "" {
+					expected = "knit-worker-priority" // comes from config
+				}
 				if actual != expected {
 					t.Errorf(
 						"PriorityCalssName: (actual, expected) = (%s, %s)",
@@ -148,6 +159,13 @@ func TestRunExecutable(t *testing.T) {
 				}
 			}
 
+			if !cmp.PEqEq(testee.Spec.Template.Spec.RuntimeClassName, then.Template.Spec.RuntimeClassName) {
+				t.Errorf(
+					"RuntimeClassName: (actual, expected) = (%v, %v)",
+					testee.Spec.Template.Spec.RuntimeClassName, then.Template.Spec.RuntimeClassName,
+				)
+			}
+
 			{
 				actual := testee.Spec.Template.Spec.InitContainers
 				expected := then.Template.Spec.InitContainers
@@ -185,6 +203,7 @@ func TestRunExecutable(t *testing.T) {
 							return reflect.DeepEqual(a, b)
 						}) &&
 						cmp.MapEqWith(a.Resources.Limits, b.Resources.Limits, resource.Quantity.Equal) &&
+						cmp.MapEqWith(a.Resources.Requests, b.Resources.Requests, resource.Quantity.Equal) &&
 						cmp.SliceContentEqWith(a.Env, b.Env, func(a, b kubecore.EnvVar) bool {
 							return a.Name == b.Name && a.Value == b.Value
 						})
@@ -1116,6 +1135,369 @@ func TestRunExecutable(t *testing.T) {
 		},
 	))
 
+	t.Run("when it builds with max duration, it sets active deadline of job spec", theoryOk(
+		When{
+			run: domain.Run{
+				RunBody: domain.RunBody{
+					Id: "test-run-id",
+					PlanBody: domain.PlanBody{
+						PlanId: "test-plan-id",
+						Image: &domain.ImageIdentifier{
+							Image: "repo.invalid/image-name", Version: "1.0",
+						},
+						MaxDuration: 90 * time.Minute,
+					},
+				},
+				Inputs: []domain.Assignment{
+					{
+						KnitDataBody: dsIn1,
+						MountPoint:   domain.MountPoint{Id: 1, Path: "/in/1"},
+					},
+					{
+						KnitDataBody: dsIn2,
+						MountPoint:   domain.MountPoint{Id: 2, Path: "/in/2"},
+					},
+				},
+			},
+		},
+		kubebatch.JobSpec{
+			Parallelism:           ptr.Ref[int32](1),
+			BackoffLimit:          ptr.Ref[int32](0),
+			ActiveDeadlineSeconds: ptr.Ref[int64](5400),
+			Template: kubecore.PodTemplateSpec{
+				Spec: kubecore.PodSpec{
+					ServiceAccountName:           "",
+					AutomountServiceAccountToken: ptr.Ref(false),
+					EnableServiceLinks:           ptr.Ref(false),
+					RestartPolicy:                kubecore.RestartPolicyNever,
+					Containers: []kubecore.Container{
+						{
+							Name:  "main",
+							Image: "repo.invalid/image-name:1.0",
+							VolumeMounts: []kubecore.VolumeMount{
+								{
+									Name: dsIn1.KnitId, MountPath: "/in/1",
+									ReadOnly: true,
+								},
+								{
+									Name: dsIn2.KnitId, MountPath: "/in/2",
+									ReadOnly: true,
+								},
+							},
+						},
+					},
+					Volumes: []kubecore.Volume{
+						{
+							Name: dsIn1.KnitId,
+							VolumeSource: kubecore.VolumeSource{
+								PersistentVolumeClaim: &kubecore.PersistentVolumeClaimVolumeSource{
+									ClaimName: dsIn1.VolumeRef,
+								},
+							},
+						},
+						{
+							Name: dsIn2.KnitId,
+							VolumeSource: kubecore.VolumeSource{
+								PersistentVolumeClaim: &kubecore.PersistentVolumeClaimVolumeSource{
+									ClaimName: dsIn2.VolumeRef,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	))
+
+	t.Run("when it builds with env of plan, it sets env of main container", theoryOk(
+		When{
+			run: domain.Run{
+				RunBody: domain.RunBody{
+					Id: "test-run-id",
+					PlanBody: domain.PlanBody{
+						PlanId: "test-plan-id",
+						Image: &domain.ImageIdentifier{
+							Image: "repo.invalid/image-name", Version: "1.0",
+						},
+						Env: []domain.EnvVar{
+							{Name: "STATIC", Value: "static-value"},
+							{Name: "TOKEN", Secret: &domain.EnvVarKeyRef{Name: "api-secret", Key: "token"}},
+							{Name: "ENDPOINT", ConfigMap: &domain.EnvVarKeyRef{Name: "api-config", Key: "endpoint"}},
+							{Name: "OVERRIDDEN", Value: "by-plan"},
+						},
+					},
+				},
+				Inputs: []domain.Assignment{
+					{
+						KnitDataBody: dsIn1,
+						MountPoint:   domain.MountPoint{Id: 1, Path: "/in/1"},
+					},
+				},
+			},
+			envvar: map[string]string{
+				"OVERRIDDEN": "by-hook",
+			},
+		},
+		kubebatch.JobSpec{
+			Parallelism:  ptr.Ref[int32](1),
+			BackoffLimit: ptr.Ref[int32](0),
+			Template: kubecore.PodTemplateSpec{
+				Spec: kubecore.PodSpec{
+					ServiceAccountName:           "",
+					AutomountServiceAccountToken: ptr.Ref(false),
+					EnableServiceLinks:           ptr.Ref(false),
+					RestartPolicy:                kubecore.RestartPolicyNever,
+					Containers: []kubecore.Container{
+						{
+							Name:  "main",
+							Image: "repo.invalid/image-name:1.0",
+							Env: []kubecore.EnvVar{
+								{Name: "STATIC", Value: "static-value"},
+								{
+									Name: "TOKEN",
+									ValueFrom: &kubecore.EnvVarSource{
+										SecretKeyRef: &kubecore.SecretKeySelector{
+											LocalObjectReference: kubecore.LocalObjectReference{Name: "api-secret"},
+											Key:                  "token",
+										},
+									},
+								},
+								{
+									Name: "ENDPOINT",
+									ValueFrom: &kubecore.EnvVarSource{
+										ConfigMapKeyRef: &kubecore.ConfigMapKeySelector{
+											LocalObjectReference: kubecore.LocalObjectReference{Name: "api-config"},
+											Key:                  "endpoint",
+										},
+									},
+								},
+								{Name: "OVERRIDDEN", Value: "by-hook"},
+							},
+							VolumeMounts: []kubecore.VolumeMount{
+								{
+									Name: dsIn1.KnitId, MountPath: "/in/1",
+									ReadOnly: true,
+								},
+							},
+						},
+					},
+					Volumes: []kubecore.Volume{
+						{
+							Name: dsIn1.KnitId,
+							VolumeSource: kubecore.VolumeSource{
+								PersistentVolumeClaim: &kubecore.PersistentVolumeClaimVolumeSource{
+									ClaimName: dsIn1.VolumeRef,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	))
+
+	t.Run("when it builds with scheduling properties of plan, it reflects to job spec", theoryOk(
+		When{
+			run: domain.Run{
+				RunBody: domain.RunBody{
+					Id: "test-run-id",
+					PlanBody: domain.PlanBody{
+						PlanId: "test-plan-id",
+						Image: &domain.ImageIdentifier{
+							Image: "repo.invalid/image-name", Version: "1.0",
+						},
+						Resources: map[string]resource.Quantity{
+							"cpu":            resource.MustParse("4"),
+							"memory":         resource.MustParse("8Gi"),
+							"nvidia.com/gpu": resource.MustParse("1"),
+						},
+						ResourceRequests: map[string]resource.Quantity{
+							"cpu":    resource.MustParse("500m"),
+							"memory": resource.MustParse("2Gi"),
+						},
+						OnNode: []domain.OnNode{
+							{Mode: domain.MayOnNode, Key: "accelerator", Value: "gpu"},
+						},
+						Tolerations: []domain.Toleration{
+							{Key: "nvidia.com/gpu", Operator: domain.TolerationOpExists, Effect: domain.TaintEffectNoSchedule},
+							{Key: "dedicated", Value: "training"},
+						},
+						PriorityClassName: "high-priority",
+						RuntimeClassName:  "nvidia",
+					},
+				},
+				Inputs: []domain.Assignment{
+					{
+						KnitDataBody: dsIn1,
+						MountPoint:   domain.MountPoint{Id: 1, Path: "/in/1"},
+					},
+				},
+			},
+		},
+		kubebatch.JobSpec{
+			Parallelism:  ptr.Ref[int32](1),
+			BackoffLimit: ptr.Ref[int32](0),
+			Template: kubecore.PodTemplateSpec{
+				Spec: kubecore.PodSpec{
+					ServiceAccountName:           "",
+					AutomountServiceAccountToken: ptr.Ref(false),
+					EnableServiceLinks:           ptr.Ref(false),
+					RestartPolicy:                kubecore.RestartPolicyNever,
+					PriorityClassName:            "high-priority",
+					RuntimeClassName:             ptr.Ref("nvidia"),
+					Tolerations: []kubecore.Toleration{
+						{
+							Key: "accelerator", Value: "gpu",
+							Operator: kubecore.TolerationOpEqual,
+							Effect:   kubecore.TaintEffectNoSchedule,
+						},
+						{
+							Key:      "nvidia.com/gpu",
+							Operator: kubecore.TolerationOpExists,
+							Effect:   kubecore.TaintEffectNoSchedule,
+						},
+						{
+							Key: "dedicated", Value: "training",
+							Operator: kubecore.TolerationOpEqual,
+						},
+					},
+					Containers: []kubecore.Container{
+						{
+							Name:  "main",
+							Image: "repo.invalid/image-name:1.0",
+							Resources: kubecore.ResourceRequirements{
+								Limits: kubecore.ResourceList{
+									kubecore.ResourceCPU:    resource.MustParse("4"),
+									kubecore.ResourceMemory: resource.MustParse("8Gi"),
+									"nvidia.com/gpu":        resource.MustParse("1"),
+								},
+								Requests: kubecore.ResourceList{
+									kubecore.ResourceCPU:    resource.MustParse("500m"),
+									kubecore.ResourceMemory: resource.MustParse("2Gi"),
+								},
+							},
+							VolumeMounts: []kubecore.VolumeMount{
+								{
+									Name: dsIn1.KnitId, MountPath: "/in/1",
+									ReadOnly: true,
+								},
+							},
+						},
+					},
+					Volumes: []kubecore.Volume{
+						{
+							Name: dsIn1.KnitId,
+							VolumeSource: kubecore.VolumeSource{
+								PersistentVolumeClaim: &kubecore.PersistentVolumeClaimVolumeSource{
+									ClaimName: dsIn1.VolumeRef,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	))
+
+	t.Run("when it builds with scratches and shm size of plan, it mounts emptyDir volumes", theoryOk(
+		When{
+			run: domain.Run{
+				RunBody: domain.RunBody{
+					Id: "test-run-id",
+					PlanBody: domain.PlanBody{
+						PlanId: "test-plan-id",
+						Image: &domain.ImageIdentifier{
+							Image: "repo.invalid/image-name", Version: "1.0",
+						},
+						Resources: map[string]resource.Quantity{
+							"cpu":    resource.MustParse("1"),
+							"memory": resource.MustParse("4Gi"),
+						},
+						Scratches: []domain.ScratchVolume{
+							{Path: "/cache", InMemory: true},
+							{Path: "/tmp", SizeLimit: resource.MustParse("10Gi")},
+						},
+						ShmSize: resource.MustParse("2Gi"),
+					},
+				},
+				Inputs: []domain.Assignment{
+					{
+						KnitDataBody: dsIn1,
+						MountPoint:   domain.MountPoint{Id: 1, Path: "/in/1"},
+					},
+				},
+			},
+		},
+		kubebatch.JobSpec{
+			Parallelism:  ptr.Ref[int32](1),
+			BackoffLimit: ptr.Ref[int32](0),
+			Template: kubecore.PodTemplateSpec{
+				Spec: kubecore.PodSpec{
+					ServiceAccountName:           "",
+					AutomountServiceAccountToken: ptr.Ref(false),
+					EnableServiceLinks:           ptr.Ref(false),
+					RestartPolicy:                kubecore.RestartPolicyNever,
+					Containers: []kubecore.Container{
+						{
+							Name:  "main",
+							Image: "repo.invalid/image-name:1.0",
+							Resources: kubecore.ResourceRequirements{
+								Limits: kubecore.ResourceList{
+									kubecore.ResourceCPU:    resource.MustParse("1"),
+									kubecore.ResourceMemory: resource.MustParse("4Gi"),
+								},
+							},
+							VolumeMounts: []kubecore.VolumeMount{
+								{
+									Name: dsIn1.KnitId, MountPath: "/in/1",
+									ReadOnly: true,
+								},
+								{Name: "scratch-0", MountPath: "/cache"},
+								{Name: "scratch-1", MountPath: "/tmp"},
+								{Name: "shm", MountPath: "/dev/shm"},
+							},
+						},
+					},
+					Volumes: []kubecore.Volume{
+						{
+							Name: dsIn1.KnitId,
+							VolumeSource: kubecore.VolumeSource{
+								PersistentVolumeClaim: &kubecore.PersistentVolumeClaimVolumeSource{
+									ClaimName: dsIn1.VolumeRef,
+								},
+							},
+						},
+						{
+							Name: "scratch-0",
+							VolumeSource: kubecore.VolumeSource{
+								EmptyDir: &kubecore.EmptyDirVolumeSource{
+									Medium: kubecore.StorageMediumMemory,
+								},
+							},
+						},
+						{
+							Name: "scratch-1",
+							VolumeSource: kubecore.VolumeSource{
+								EmptyDir: &kubecore.EmptyDirVolumeSource{
+									SizeLimit: ptr.Ref(resource.MustParse("10Gi")),
+								},
+							},
+						},
+						{
+							Name: "shm",
+							VolumeSource: kubecore.VolumeSource{
+								EmptyDir: &kubecore.EmptyDirVolumeSource{
+									Medium:    kubecore.StorageMediumMemory,
+									SizeLimit: ptr.Ref(resource.MustParse("2Gi")),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	))
+
 	theoryErr := func(when When) func(*testing.T) {
 		return func(t *testing.T) {
 			if testee, err := worker.New(&when.run, when.envvar); err == nil {
@@ -13,6 +13,9 @@ import (
 	kubebatch "k8s.io/api/batch/v1"
 )
 
+// MainContainer is the name of the container running the image of the Plan.
+const MainContainer = "main"
+
 type Worker interface {
 	// RunId returns the run ID of the worker
 	RunId() string
@@ -47,7 +50,7 @@ func (w *worker) JobStatus(ctx context.Context) cluster.JobStatus {
 }
 
 func (w *worker) Log(ctx context.Context) (io.ReadCloser, error) {
-	return w.job.Log(ctx, "main")
+	return w.job.Log(ctx, MainContainer)
 }
 
 func (w *worker) Close() error {
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// UsageGroup is the way to aggregate storage usage.
+type UsageGroup string
+
+const (
+	// aggregate by Plans generating Data.
+	UsageByPlan UsageGroup = "plan"
+
+	// aggregate by Tags of Data.
+	UsageByTag UsageGroup = "tag"
+
+	// aggregate by the time when Data are generated.
+	UsageByTime UsageGroup = "time"
+)
+
+func AsUsageGroup(s string) (UsageGroup, error) {
+	switch g := UsageGroup(s); g {
+	case UsageByPlan, UsageByTag, UsageByTime:
+		return g, nil
+	default:
+		return g, fmt.Errorf("unknown usage group: %s", s)
+	}
+}
+
+// UsageInterval is the length of time buckets to aggregate storage usage by time.
+type UsageInterval string
+
+const (
+	UsageDaily   UsageInterval = "day"
+	UsageWeekly  UsageInterval = "week"
+	UsageMonthly UsageInterval = "month"
+)
+
+func AsUsageInterval(s string) (UsageInterval, error) {
+	switch i := UsageInterval(s); i {
+	case UsageDaily, UsageWeekly, UsageMonthly:
+		return i, nil
+	default:
+		return i, fmt.Errorf("unknown usage interval: %s", s)
+	}
+}
+
+// UsageQuery specifies how storage usage is aggregated.
+type UsageQuery struct {
+	// By is the way to aggregate.
+	By UsageGroup
+
+	// TagKey restricts Tags to be aggregated by, when By is UsageByTag.
+	//
+	// If empty, all user Tags are aggregated by.
+	TagKey string
+
+	// Interval is the length of time buckets, when By is UsageByTime.
+	Interval UsageInterval
+}
+
+// Usage is the storage usage of a group of Data.
+//
+// Only Data which have been measured are aggregated.
+type Usage struct {
+	// PlanId is the Plan generating Data in the group, when aggregated by Plans.
+	PlanId string
+
+	// Tag is the Tag of Data in the group, when aggregated by Tags.
+	Tag *Tag
+
+	// Since is the start of the time bucket, when aggregated by time.
+	Since *time.Time
+
+	// Size is the total size of Data in the group.
+	Size DataSize
+
+	// Data is the number of Data in the group.
+	Data int64
+}
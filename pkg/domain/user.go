@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+type Role string
+
+const (
+	// RoleViewer can read Data, Plans and Runs.
+	RoleViewer Role = "viewer"
+
+	// RolePlanAuthor can change Data, Plans and Runs, in addition to RoleViewer.
+	RolePlanAuthor Role = "plan-author"
+
+	// RoleAdmin can do everything.
+	RoleAdmin Role = "admin"
+)
+
+var (
+	ErrUnknownRole = errors.New("unknown role")
+
+	// user having same email address exists already.
+	ErrUserExists = errors.New("user already exists")
+)
+
+func (r Role) String() string {
+	return string(r)
+}
+
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RolePlanAuthor:
+		return 2
+	case RoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func (r Role) IsKnown() bool {
+	return 0 < r.rank()
+}
+
+// Includes returns true if a user with this role can do what a user with other role can do.
+func (r Role) Includes(other Role) bool {
+	return r.IsKnown() && other.rank() <= r.rank()
+}
+
+func AsRole(s string) (Role, error) {
+	r := Role(s)
+	if r.IsKnown() {
+		return r, nil
+	}
+	return r, fmt.Errorf(`%w: "%s"`, ErrUnknownRole, s)
+}
+
+type User struct {
+	Id    string
+	Email string
+	Name  string
+	Role  Role
+}
+
+func (u User) Equal(o User) bool {
+	return u.Id == o.Id &&
+		u.Email == o.Email &&
+		u.Name == o.Name &&
+		u.Role == o.Role
+}
+
+// PasswordCredential is a password of a user, stored in "auth_password" table.
+type PasswordCredential struct {
+	// Salt for the password hash, in hexstring.
+	Salt string
+
+	// Hash of the password by argon2id, in hexstring.
+	Hash string
+
+	// Key is a HMAC key to sign tokens for the user, in hexstring.
+	Key string
+
+	// Revoked is true if the credential cannot be used anymore.
+	Revoked bool
+
+	// UpdatedAt is the time when the credential is updated.
+	//
+	// Tokens issued before this time are not valid.
+	UpdatedAt time.Time
+}
+
+const (
+	passwordSaltLength = 16  // bytes. "salt" column is varchar(32) in hexstring.
+	passwordHashLength = 256 // bytes. "hash" column is char(512) in hexstring.
+	passwordKeyLength  = 32  // bytes. "key" column is char(64) in hexstring.
+)
+
+func hashPassword(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, 1, 64*1024, 4, passwordHashLength)
+}
+
+// NewPasswordCredential creates a new PasswordCredential with random salt and key.
+func NewPasswordCredential(password string) (PasswordCredential, error) {
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return PasswordCredential{}, err
+	}
+	key := make([]byte, passwordKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return PasswordCredential{}, err
+	}
+
+	return PasswordCredential{
+		Salt:      hex.EncodeToString(salt),
+		Hash:      hex.EncodeToString(hashPassword(password, salt)),
+		Key:       hex.EncodeToString(key),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// Verify returns true if the password matches the credential, and the credential is not revoked.
+func (c PasswordCredential) Verify(password string) bool {
+	if c.Revoked {
+		return false
+	}
+	salt, err := hex.DecodeString(c.Salt)
+	if err != nil {
+		return false
+	}
+	hash, err := hex.DecodeString(c.Hash)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(hash, hashPassword(password, salt)) == 1
+}
+
+// SigningKey returns the key to sign tokens for the user.
+func (c PasswordCredential) SigningKey() ([]byte, error) {
+	return hex.DecodeString(c.Key)
+}
@@ -0,0 +1,61 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+
+	types "github.com/opst/knitfab/pkg/domain"
+	kdbmock "github.com/opst/knitfab/pkg/domain/internal/db/mock"
+	kdb "github.com/opst/knitfab/pkg/domain/user/db"
+)
+
+type RegisterArgs struct {
+	User       types.User
+	Credential types.PasswordCredential
+}
+
+type UserInterface struct {
+	Impl struct {
+		Get      func(context.Context, string) (types.User, types.PasswordCredential, error)
+		Lookup   func(context.Context, string) (types.User, types.PasswordCredential, error)
+		Register func(context.Context, types.User, types.PasswordCredential) (types.User, error)
+	}
+	Calls struct {
+		Get      kdbmock.CallLog[string]
+		Lookup   kdbmock.CallLog[string]
+		Register kdbmock.CallLog[RegisterArgs]
+	}
+}
+
+var _ kdb.UserInterface = &UserInterface{}
+
+func NewUserInterface() *UserInterface {
+	return &UserInterface{}
+}
+
+func (m *UserInterface) Get(ctx context.Context, userId string) (types.User, types.PasswordCredential, error) {
+	m.Calls.Get = append(m.Calls.Get, userId)
+	if m.Impl.Get != nil {
+		return m.Impl.Get(ctx, userId)
+	}
+
+	panic(errors.New("should not be called"))
+}
+
+func (m *UserInterface) Lookup(ctx context.Context, email string) (types.User, types.PasswordCredential, error) {
+	m.Calls.Lookup = append(m.Calls.Lookup, email)
+	if m.Impl.Lookup != nil {
+		return m.Impl.Lookup(ctx, email)
+	}
+
+	panic(errors.New("should not be called"))
+}
+
+func (m *UserInterface) Register(ctx context.Context, user types.User, cred types.PasswordCredential) (types.User, error) {
+	m.Calls.Register = append(m.Calls.Register, RegisterArgs{User: user, Credential: cred})
+	if m.Impl.Register != nil {
+		return m.Impl.Register(ctx, user, cred)
+	}
+
+	panic(errors.New("should not be called"))
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	pgerrcode "github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
+	types "github.com/opst/knitfab/pkg/domain"
+	kpgerr "github.com/opst/knitfab/pkg/domain/errors/dberrors/postgres"
+	kdbuser "github.com/opst/knitfab/pkg/domain/user/db"
+	xe "github.com/opst/knitfab/pkg/errors"
+)
+
+type userPG struct {
+	pool kpool.Pool
+}
+
+func New(pool kpool.Pool) kdbuser.UserInterface {
+	return &userPG{pool: pool}
+}
+
+func (u *userPG) Get(ctx context.Context, userId string) (types.User, types.PasswordCredential, error) {
+	return u.get(ctx, `"user"."id" = $1`, userId)
+}
+
+func (u *userPG) Lookup(ctx context.Context, email string) (types.User, types.PasswordCredential, error) {
+	return u.get(ctx, `"user"."email" = $1`, email)
+}
+
+func (u *userPG) get(ctx context.Context, cond string, identity string) (types.User, types.PasswordCredential, error) {
+	conn, err := u.pool.Acquire(ctx)
+	if err != nil {
+		return types.User{}, types.PasswordCredential{}, xe.Wrap(err)
+	}
+	defer conn.Release()
+
+	user := types.User{}
+	cred := types.PasswordCredential{}
+	var role string
+	if err := conn.QueryRow(
+		ctx,
+		`
+		select
+			"user"."id", "user"."email", "user"."name",
+			coalesce("user_role"."role"::text, ''),
+			"auth_password"."salt", "auth_password"."hash", "auth_password"."key",
+			"auth_password"."revoked", "auth_password"."update_at"
+		from "user"
+		inner join "auth_password" on "auth_password"."id" = "user"."id"
+		left join "user_role" on "user_role"."user_id" = "user"."id"
+		where `+cond,
+		identity,
+	).Scan(
+		&user.Id, &user.Email, &user.Name,
+		&role,
+		&cred.Salt, &cred.Hash, &cred.Key,
+		&cred.Revoked, &cred.UpdatedAt,
+	); errors.Is(err, pgx.ErrNoRows) {
+		return types.User{}, types.PasswordCredential{}, xe.Wrap(kpgerr.Missing{
+			Table: "user", Identity: identity,
+		})
+	} else if err != nil {
+		return types.User{}, types.PasswordCredential{}, xe.Wrap(err)
+	}
+
+	// users without role can see, but cannot change anything.
+	user.Role = types.RoleViewer
+	if r, err := types.AsRole(role); err == nil {
+		user.Role = r
+	}
+	cred.Salt = strings.TrimSpace(cred.Salt)
+	cred.Hash = strings.TrimSpace(cred.Hash)
+	cred.Key = strings.TrimSpace(cred.Key)
+
+	return user, cred, nil
+}
+
+func (u *userPG) Register(ctx context.Context, user types.User, cred types.PasswordCredential) (types.User, error) {
+	if !user.Role.IsKnown() {
+		return types.User{}, xe.Wrap(types.ErrUnknownRole)
+	}
+
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return types.User{}, xe.Wrap(err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userId string
+	if err := tx.QueryRow(
+		ctx,
+		`insert into "user" ("email", "name") values ($1, $2) returning "id"`,
+		user.Email, user.Name,
+	).Scan(&userId); err != nil {
+		if pgErr := new(pgconn.PgError); errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return types.User{}, xe.Wrap(types.ErrUserExists)
+		}
+		return types.User{}, xe.Wrap(err)
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`
+		insert into "auth_password" ("id", "revoked", "salt", "hash", "key", "update_at")
+		values ($1, $2, $3, $4, $5, $6)
+		`,
+		userId, cred.Revoked, cred.Salt, cred.Hash, cred.Key, cred.UpdatedAt.UTC(),
+	); err != nil {
+		return types.User{}, xe.Wrap(err)
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`insert into "user_role" ("user_id", "role") values ($1, $2)`,
+		userId, string(user.Role),
+	); err != nil {
+		return types.User{}, xe.Wrap(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return types.User{}, xe.Wrap(err)
+	}
+
+	return types.User{
+		Id:    userId,
+		Email: user.Email,
+		Name:  user.Name,
+		Role:  user.Role,
+	}, nil
+}
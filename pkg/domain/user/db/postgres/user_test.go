@@ -0,0 +1,85 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/domain"
+	kerr "github.com/opst/knitfab/pkg/domain/errors"
+	kpguser "github.com/opst/knitfab/pkg/domain/user/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestUser(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	t.Run("Registered user can be found by Get and Lookup", func(t *testing.T) {
+		ctx := context.Background()
+		pool := poolBroaker.GetPool(ctx, t)
+		testee := kpguser.New(pool)
+
+		cred := try.To(domain.NewPasswordCredential("p@ssw0rd")).OrFatal(t)
+		cred.UpdatedAt = cred.UpdatedAt.Truncate(time.Second)
+
+		registered := try.To(testee.Register(
+			ctx,
+			domain.User{Email: "user@example.com", Name: "user", Role: domain.RolePlanAuthor},
+			cred,
+		)).OrFatal(t)
+		if registered.Id == "" {
+			t.Fatal("user id is not assigned")
+		}
+
+		for name, get := range map[string]func() (domain.User, domain.PasswordCredential, error){
+			"Get":    func() (domain.User, domain.PasswordCredential, error) { return testee.Get(ctx, registered.Id) },
+			"Lookup": func() (domain.User, domain.PasswordCredential, error) { return testee.Lookup(ctx, "user@example.com") },
+		} {
+			t.Run(name, func(t *testing.T) {
+				user, actualCred, err := get()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !user.Equal(registered) {
+					t.Errorf("user:\n===actual===\n%+v\n===expected===\n%+v", user, registered)
+				}
+				if !actualCred.Verify("p@ssw0rd") {
+					t.Errorf("credential does not match with the password")
+				}
+				if !actualCred.UpdatedAt.Equal(cred.UpdatedAt) {
+					t.Errorf("updated at: (actual, expected) = (%s, %s)", actualCred.UpdatedAt, cred.UpdatedAt)
+				}
+			})
+		}
+	})
+
+	t.Run("Register rejects a user with used email", func(t *testing.T) {
+		ctx := context.Background()
+		pool := poolBroaker.GetPool(ctx, t)
+		testee := kpguser.New(pool)
+
+		cred := try.To(domain.NewPasswordCredential("p@ssw0rd")).OrFatal(t)
+		try.To(testee.Register(
+			ctx, domain.User{Email: "user@example.com", Name: "user", Role: domain.RoleViewer}, cred,
+		)).OrFatal(t)
+
+		_, err := testee.Register(
+			ctx, domain.User{Email: "user@example.com", Name: "user2", Role: domain.RoleAdmin}, cred,
+		)
+		if !errors.Is(err, domain.ErrUserExists) {
+			t.Errorf("unexpected error: %+v", err)
+		}
+	})
+
+	t.Run("Get returns ErrMissing for unknown user", func(t *testing.T) {
+		ctx := context.Background()
+		pool := poolBroaker.GetPool(ctx, t)
+		testee := kpguser.New(pool)
+
+		if _, _, err := testee.Get(ctx, "no-such-user"); !errors.Is(err, kerr.ErrMissing) {
+			t.Errorf("unexpected error: %+v", err)
+		}
+	})
+}
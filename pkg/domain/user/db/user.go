@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+
+	types "github.com/opst/knitfab/pkg/domain"
+)
+
+type UserInterface interface {
+	// Get retrieves a user and its password credential by user id.
+	//
+	// # Args
+	//
+	// - context.Context
+	//
+	// - string: user id
+	//
+	// # Returns
+	//
+	// - types.User: found user
+	//
+	// - types.PasswordCredential: password credential of the user
+	//
+	// - error: ErrMissing if the user or its credential is not found.
+	Get(ctx context.Context, userId string) (types.User, types.PasswordCredential, error)
+
+	// Lookup retrieves a user and its password credential by email address.
+	//
+	// # Args
+	//
+	// - context.Context
+	//
+	// - string: email address
+	//
+	// # Returns
+	//
+	// - types.User: found user
+	//
+	// - types.PasswordCredential: password credential of the user
+	//
+	// - error: ErrMissing if the user or its credential is not found.
+	Lookup(ctx context.Context, email string) (types.User, types.PasswordCredential, error)
+
+	// Register creates a new user with its role and password credential.
+	//
+	// # Args
+	//
+	// - context.Context
+	//
+	// - types.User: user to be registered. Id is ignored.
+	//
+	// - types.PasswordCredential: password credential of the user
+	//
+	// # Returns
+	//
+	// - types.User: registered user, with its Id.
+	//
+	// - error: ErrUserExists if a user with the same email exists.
+	Register(ctx context.Context, user types.User, cred types.PasswordCredential) (types.User, error)
+}
@@ -0,0 +1,62 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/opst/knitfab/pkg/domain"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestAsRole(t *testing.T) {
+	for _, s := range []string{"viewer", "plan-author", "admin"} {
+		t.Run("it accepts "+s, func(t *testing.T) {
+			r, err := domain.AsRole(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.String() != s {
+				t.Errorf("unexpected role: %s", r)
+			}
+		})
+	}
+
+	t.Run("it rejects unknown role", func(t *testing.T) {
+		if _, err := domain.AsRole("root"); !errors.Is(err, domain.ErrUnknownRole) {
+			t.Errorf("unexpected error: %+v", err)
+		}
+	})
+}
+
+func TestPasswordCredential(t *testing.T) {
+	cred := try.To(domain.NewPasswordCredential("p@ssw0rd")).OrFatal(t)
+
+	t.Run("it has columns in length for auth_password table", func(t *testing.T) {
+		if l := len(cred.Salt); l != 32 {
+			t.Errorf("salt length: %d", l)
+		}
+		if l := len(cred.Hash); l != 512 {
+			t.Errorf("hash length: %d", l)
+		}
+		if l := len(cred.Key); l != 64 {
+			t.Errorf("key length: %d", l)
+		}
+	})
+
+	t.Run("it verifies the password", func(t *testing.T) {
+		if !cred.Verify("p@ssw0rd") {
+			t.Errorf("correct password is rejected")
+		}
+		if cred.Verify("password") {
+			t.Errorf("wrong password is accepted")
+		}
+	})
+
+	t.Run("it rejects any password when revoked", func(t *testing.T) {
+		revoked := cred
+		revoked.Revoked = true
+		if revoked.Verify("p@ssw0rd") {
+			t.Errorf("revoked credential accepts password")
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// WebhookDeliveryStatus is a status of a webhook delivery in the outbox.
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending is a delivery waiting to be (re)delivered.
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+
+	// WebhookDeliveryFailed is a delivery given up after all attempts.
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is a webhook call queued in the outbox.
+//
+// Deliveries are removed from the outbox when they are delivered successfully.
+type WebhookDelivery struct {
+	Id int64
+
+	// Hook is the name of the hook configuration which the delivery belongs to.
+	Hook string
+
+	// URL is the destination of the delivery.
+	URL string
+
+	// Payload is the body of the request.
+	Payload []byte
+
+	Status WebhookDeliveryStatus
+
+	// Attempts is the number of attempts made so far.
+	Attempts int
+
+	// NextAttemptAt is the time when the delivery is attempted next.
+	NextAttemptAt time.Time
+
+	// LastError is the error of the last attempt.
+	LastError string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,73 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	types "github.com/opst/knitfab/pkg/domain"
+	kdbmock "github.com/opst/knitfab/pkg/domain/internal/db/mock"
+	kdb "github.com/opst/knitfab/pkg/domain/webhook/db"
+)
+
+type WebhookInterface struct {
+	Impl struct {
+		Enqueue func(ctx context.Context, hook string, urls []string, payload []byte) error
+		Deliver func(
+			ctx context.Context,
+			deliver func(types.WebhookDelivery) error,
+			retry func(types.WebhookDelivery) (time.Duration, bool),
+		) (*types.WebhookDelivery, error)
+		Find func(ctx context.Context, status []types.WebhookDeliveryStatus) ([]types.WebhookDelivery, error)
+	}
+	Calls struct {
+		Enqueue kdbmock.CallLog[struct {
+			Hook    string
+			URLs    []string
+			Payload []byte
+		}]
+		Deliver kdbmock.CallLog[struct{}]
+		Find    kdbmock.CallLog[[]types.WebhookDeliveryStatus]
+	}
+}
+
+var _ kdb.WebhookInterface = &WebhookInterface{}
+
+func NewWebhookInterface() *WebhookInterface {
+	return &WebhookInterface{}
+}
+
+func (m *WebhookInterface) Enqueue(ctx context.Context, hook string, urls []string, payload []byte) error {
+	m.Calls.Enqueue = append(m.Calls.Enqueue, struct {
+		Hook    string
+		URLs    []string
+		Payload []byte
+	}{Hook: hook, URLs: urls, Payload: payload})
+	if m.Impl.Enqueue != nil {
+		return m.Impl.Enqueue(ctx, hook, urls, payload)
+	}
+
+	panic(errors.New("should not be called"))
+}
+
+func (m *WebhookInterface) Deliver(
+	ctx context.Context,
+	deliver func(types.WebhookDelivery) error,
+	retry func(types.WebhookDelivery) (time.Duration, bool),
+) (*types.WebhookDelivery, error) {
+	m.Calls.Deliver = append(m.Calls.Deliver, struct{}{})
+	if m.Impl.Deliver != nil {
+		return m.Impl.Deliver(ctx, deliver, retry)
+	}
+
+	panic(errors.New("should not be called"))
+}
+
+func (m *WebhookInterface) Find(ctx context.Context, status []types.WebhookDeliveryStatus) ([]types.WebhookDelivery, error) {
+	m.Calls.Find = append(m.Calls.Find, status)
+	if m.Impl.Find != nil {
+		return m.Impl.Find(ctx, status)
+	}
+
+	panic(errors.New("should not be called"))
+}
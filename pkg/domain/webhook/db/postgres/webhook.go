@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	kpool "github.com/opst/knitfab/pkg/conn/db/postgres/pool"
+	types "github.com/opst/knitfab/pkg/domain"
+	kdbwebhook "github.com/opst/knitfab/pkg/domain/webhook/db"
+	xe "github.com/opst/knitfab/pkg/errors"
+	"github.com/opst/knitfab/pkg/utils/slices"
+)
+
+type webhookPG struct {
+	pool kpool.Pool
+}
+
+func New(pool kpool.Pool) kdbwebhook.WebhookInterface {
+	return &webhookPG{pool: pool}
+}
+
+func (w *webhookPG) Enqueue(ctx context.Context, hook string, urls []string, payload []byte) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	tx, err := kpool.BeginIn(ctx, w.pool)
+	if err != nil {
+		return xe.Wrap(err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(
+		ctx,
+		`
+		insert into "webhook_outbox" ("hook", "url", "payload")
+		select $1, "url", $3 from unnest($2::varchar[]) as "url"
+		`,
+		hook, urls, payload,
+	); err != nil {
+		return xe.Wrap(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return xe.Wrap(err)
+	}
+	return nil
+}
+
+const webhookColumns = `
+	"id", "hook", "url", "payload", "status", "attempts",
+	"next_attempt_at", "last_error", "created_at", "updated_at"
+`
+
+func scanWebhookDelivery(row pgx.Row) (types.WebhookDelivery, error) {
+	d := types.WebhookDelivery{}
+	var status string
+	if err := row.Scan(
+		&d.Id, &d.Hook, &d.URL, &d.Payload, &status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastError, &d.CreatedAt, &d.UpdatedAt,
+	); err != nil {
+		return types.WebhookDelivery{}, err
+	}
+	d.Status = types.WebhookDeliveryStatus(status)
+	return d, nil
+}
+
+func (w *webhookPG) Deliver(
+	ctx context.Context,
+	deliver func(types.WebhookDelivery) error,
+	retry func(types.WebhookDelivery) (time.Duration, bool),
+) (*types.WebhookDelivery, error) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return nil, xe.Wrap(err)
+	}
+	defer tx.Rollback(ctx)
+
+	d, err := scanWebhookDelivery(tx.QueryRow(
+		ctx,
+		`
+		select `+webhookColumns+`
+		from "webhook_outbox"
+		where "status" = 'pending' and "next_attempt_at" <= now()
+		order by "next_attempt_at", "id"
+		limit 1
+		for update skip locked
+		`,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil // nothing to do
+		}
+		return nil, xe.Wrap(err)
+	}
+
+	derr := deliver(d)
+	d.Attempts += 1
+	if derr == nil {
+		if _, err := tx.Exec(ctx, `delete from "webhook_outbox" where "id" = $1`, d.Id); err != nil {
+			return nil, xe.Wrap(err)
+		}
+		d.LastError = ""
+	} else {
+		d.LastError = derr.Error()
+		wait, ok := retry(d)
+		if !ok {
+			d.Status = types.WebhookDeliveryFailed
+		}
+		if err := tx.QueryRow(
+			ctx,
+			`
+			update "webhook_outbox"
+			set
+				"status" = $2,
+				"attempts" = $3,
+				"last_error" = $4,
+				"next_attempt_at" = now() + $5::bigint * interval '1 millisecond',
+				"updated_at" = now()
+			where "id" = $1
+			returning "next_attempt_at", "updated_at"
+			`,
+			d.Id, string(d.Status), d.Attempts, d.LastError, wait.Milliseconds(),
+		).Scan(&d.NextAttemptAt, &d.UpdatedAt); err != nil {
+			return nil, xe.Wrap(err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, xe.Wrap(err)
+	}
+	return &d, nil
+}
+
+func (w *webhookPG) Find(ctx context.Context, status []types.WebhookDeliveryStatus) ([]types.WebhookDelivery, error) {
+	conn, err := w.pool.Acquire(ctx)
+	if err != nil {
+		return nil, xe.Wrap(err)
+	}
+	defer conn.Release()
+
+	st := slices.Map(status, func(s types.WebhookDeliveryStatus) string { return string(s) })
+	if st == nil {
+		st = []string{}
+	}
+
+	rows, err := conn.Query(
+		ctx,
+		`
+		select `+webhookColumns+`
+		from "webhook_outbox"
+		where cardinality($1::varchar[]) = 0 or "status"::varchar = any($1)
+		order by "id"
+		`,
+		st,
+	)
+	if err != nil {
+		return nil, xe.Wrap(err)
+	}
+	defer rows.Close()
+
+	deliveries := []types.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, xe.Wrap(err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, xe.Wrap(err)
+	}
+
+	return deliveries, nil
+}
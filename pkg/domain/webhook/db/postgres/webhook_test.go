@@ -0,0 +1,126 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/conn/db/postgres/pool/testenv"
+	"github.com/opst/knitfab/pkg/domain"
+	kpgwebhook "github.com/opst/knitfab/pkg/domain/webhook/db/postgres"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/slices"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestWebhookOutbox(t *testing.T) {
+	poolBroaker := testenv.NewPoolBroaker(context.Background(), t)
+
+	ctx := context.Background()
+	pool := poolBroaker.GetPool(ctx, t)
+	testee := kpgwebhook.New(pool)
+
+	if err := testee.Enqueue(
+		ctx, "lifecycle-hooks",
+		[]string{"http://example.com/1", "http://example.com/2"},
+		[]byte(`{"runId": "run-1"}`),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	urlsOf := func(ds []domain.WebhookDelivery) []string {
+		return slices.Map(ds, func(d domain.WebhookDelivery) string { return d.URL })
+	}
+
+	{
+		pending := try.To(testee.Find(ctx, []domain.WebhookDeliveryStatus{domain.WebhookDeliveryPending})).OrFatal(t)
+		if want := []string{"http://example.com/1", "http://example.com/2"}; !cmp.SliceEq(urlsOf(pending), want) {
+			t.Fatalf("pending deliveries: %v", urlsOf(pending))
+		}
+		for _, d := range pending {
+			if d.Hook != "lifecycle-hooks" || string(d.Payload) != `{"runId": "run-1"}` || d.Attempts != 0 {
+				t.Errorf("unexpected delivery: %+v", d)
+			}
+		}
+	}
+
+	t.Run("successful delivery is removed from the outbox", func(t *testing.T) {
+		delivered := try.To(testee.Deliver(
+			ctx,
+			func(d domain.WebhookDelivery) error { return nil },
+			func(d domain.WebhookDelivery) (time.Duration, bool) {
+				t.Error("retry should not be called")
+				return 0, false
+			},
+		)).OrFatal(t)
+		if delivered == nil || delivered.URL != "http://example.com/1" || delivered.Attempts != 1 {
+			t.Fatalf("unexpected delivery: %+v", delivered)
+		}
+
+		all := try.To(testee.Find(ctx, nil)).OrFatal(t)
+		if want := []string{"http://example.com/2"}; !cmp.SliceEq(urlsOf(all), want) {
+			t.Errorf("deliveries: %v", urlsOf(all))
+		}
+	})
+
+	t.Run("failed delivery is retried later", func(t *testing.T) {
+		delivered := try.To(testee.Deliver(
+			ctx,
+			func(d domain.WebhookDelivery) error { return errors.New("fake error") },
+			func(d domain.WebhookDelivery) (time.Duration, bool) { return time.Hour, true },
+		)).OrFatal(t)
+		if delivered == nil ||
+			delivered.URL != "http://example.com/2" ||
+			delivered.Attempts != 1 ||
+			delivered.Status != domain.WebhookDeliveryPending ||
+			delivered.LastError != "fake error" ||
+			!delivered.NextAttemptAt.After(time.Now().Add(30*time.Minute)) {
+			t.Fatalf("unexpected delivery: %+v", delivered)
+		}
+
+		// not yet
+		none := try.To(testee.Deliver(
+			ctx,
+			func(d domain.WebhookDelivery) error {
+				t.Error("deliver should not be called")
+				return nil
+			},
+			func(d domain.WebhookDelivery) (time.Duration, bool) { return 0, false },
+		)).OrFatal(t)
+		if none != nil {
+			t.Errorf("unexpected delivery: %+v", none)
+		}
+	})
+
+	t.Run("delivery is marked as failed when it is not retried", func(t *testing.T) {
+		conn := try.To(pool.Acquire(ctx)).OrFatal(t)
+		if _, err := conn.Exec(ctx, `update "webhook_outbox" set "next_attempt_at" = now()`); err != nil {
+			t.Fatal(err)
+		}
+		conn.Release()
+
+		delivered := try.To(testee.Deliver(
+			ctx,
+			func(d domain.WebhookDelivery) error { return errors.New("fake error 2") },
+			func(d domain.WebhookDelivery) (time.Duration, bool) {
+				if d.Attempts != 2 {
+					t.Errorf("attempts: %d", d.Attempts)
+				}
+				return 0, false
+			},
+		)).OrFatal(t)
+		if delivered == nil || delivered.Status != domain.WebhookDeliveryFailed {
+			t.Fatalf("unexpected delivery: %+v", delivered)
+		}
+
+		failed := try.To(testee.Find(ctx, []domain.WebhookDeliveryStatus{domain.WebhookDeliveryFailed})).OrFatal(t)
+		if len(failed) != 1 || failed[0].LastError != "fake error 2" || failed[0].Attempts != 2 {
+			t.Errorf("failed deliveries: %+v", failed)
+		}
+		pending := try.To(testee.Find(ctx, []domain.WebhookDeliveryStatus{domain.WebhookDeliveryPending})).OrFatal(t)
+		if len(pending) != 0 {
+			t.Errorf("pending deliveries: %+v", pending)
+		}
+	})
+}
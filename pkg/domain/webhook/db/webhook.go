@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	types "github.com/opst/knitfab/pkg/domain"
+)
+
+// WebhookInterface is the outbox of webhook deliveries.
+type WebhookInterface interface {
+	// Enqueue adds deliveries of the payload to each URL into the outbox.
+	//
+	// When the context is bound to a transaction (e.g. the one passed to PickAndSetStatus of Runs),
+	// deliveries are added in the transaction.
+	// So, they are queued if and only if the transaction is committed.
+	//
+	// # Args
+	//
+	// - context.Context
+	//
+	// - hook string: name of the hook configuration which deliveries belong to.
+	//
+	// - urls []string: destinations.
+	//
+	// - payload []byte: body of requests.
+	//
+	// # Returns
+	//
+	// - error
+	Enqueue(ctx context.Context, hook string, urls []string, payload []byte) error
+
+	// Deliver picks a pending delivery whose next attempt time has come, and delivers it.
+	//
+	// The picked delivery is locked while delivering,
+	// so the same delivery is not delivered concurrently.
+	//
+	// # Args
+	//
+	// - context.Context
+	//
+	// - deliver: function to deliver. When it returns nil, the delivery is removed from the outbox.
+	// Otherwise, its attempts is incremented and the error is recorded.
+	//
+	// - retry: function to decide whether to retry the failed delivery, called with the delivery after the attempt.
+	// When it returns (wait, true), the delivery is retried after wait.
+	// Otherwise, the delivery is marked as failed.
+	//
+	// # Returns
+	//
+	// - *types.WebhookDelivery: the delivery picked, after the attempt. nil if there are no deliveries to be delivered.
+	//
+	// - error: error on accessing the outbox. Errors from deliver are not returned but recorded.
+	Deliver(
+		ctx context.Context,
+		deliver func(types.WebhookDelivery) error,
+		retry func(types.WebhookDelivery) (time.Duration, bool),
+	) (*types.WebhookDelivery, error)
+
+	// Find deliveries in the outbox.
+	//
+	// # Args
+	//
+	// - context.Context
+	//
+	// - status: statuses of deliveries to be found. If empty, all deliveries are found.
+	//
+	// # Returns
+	//
+	// - []types.WebhookDelivery: found deliveries, ordered by id (older first).
+	//
+	// - error
+	Find(ctx context.Context, status []types.WebhookDeliveryStatus) ([]types.WebhookDelivery, error)
+}
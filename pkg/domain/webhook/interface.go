@@ -0,0 +1,19 @@
+package webhook
+
+import "github.com/opst/knitfab/pkg/domain/webhook/db"
+
+type Interface interface {
+	Database() db.WebhookInterface
+}
+
+type impl struct {
+	db db.WebhookInterface
+}
+
+func New(db db.WebhookInterface) Interface {
+	return &impl{db: db}
+}
+
+func (i *impl) Database() db.WebhookInterface {
+	return i.db
+}
@@ -0,0 +1,68 @@
+package hook
+
+import (
+	"context"
+
+	apiruns "github.com/opst/knitfab-api-types/runs"
+	apitags "github.com/opst/knitfab-api-types/tags"
+	cfg_hook "github.com/opst/knitfab/pkg/configs/hook"
+)
+
+// Build builds a lifecycle hook of Runs from the configuration.
+//
+// The hook calls webhooks in "lifecycle-hooks", and then,
+// emits events to "lifecycle-events" if configured.
+//
+// tags is used to look up Tags of Data in events.
+//
+// outbox is used to queue payloads for "after" webhooks. If nil, they are sent directly.
+//
+// "before" webhooks are not retried in place, since they are called in the transaction picking the Run.
+// Loops call them again when they pick the Run next time.
+func Build[R any](
+	cfg cfg_hook.Config,
+	merge func(a, b R) R,
+	tags func(knitIds []string) (map[string][]apitags.Tag, error),
+	outbox func(ctx context.Context, hook string, urls []string, payload []byte) error,
+) Hook[apiruns.Detail, R] {
+	web := BuildWeb[apiruns.Detail](cfg.Lifecycle, cfg_hook.LifecycleHooks, merge, outbox)
+	web.Retry = cfg_hook.RetryPolicy{}
+	if len(cfg.LifecycleEvents.URLs) == 0 {
+		return web
+	}
+
+	return Multi[apiruns.Detail, R]{
+		Hooks: []Hook[apiruns.Detail, R]{
+			web,
+			FromConfig[R](cfg.LifecycleEvents, tags),
+		},
+		Merge: merge,
+	}
+}
+
+// BuildWeb builds a webhook from the configuration.
+//
+// name is the name of the configuration, recorded with payloads queued in outbox.
+//
+// outbox is used to queue payloads for "after" webhooks. If nil, they are sent directly.
+func BuildWeb[T any, R any](
+	cfg cfg_hook.WebHook,
+	name string,
+	merge func(a, b R) R,
+	outbox func(ctx context.Context, hook string, urls []string, payload []byte) error,
+) Web[T, R] {
+	web := Web[T, R]{
+		BeforeURL: cfg.Before,
+		AfterURL:  cfg.After,
+		Merge:     merge,
+		Secret:    cfg.Secret,
+		Timeout:   cfg.Timeout,
+		Retry:     cfg.Retry,
+	}
+	if outbox != nil {
+		web.Outbox = func(ctx context.Context, urls []string, payload []byte) error {
+			return outbox(ctx, name, urls, payload)
+		}
+	}
+	return web
+}
@@ -0,0 +1,315 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	apiruns "github.com/opst/knitfab-api-types/runs"
+	apitags "github.com/opst/knitfab-api-types/tags"
+	cfg_hook "github.com/opst/knitfab/pkg/configs/hook"
+	"github.com/opst/knitfab/pkg/domain"
+)
+
+const (
+	OpenLineageProducer = "https://github.com/opst/knitfab"
+
+	openLineageRunEventSchema = "https://openlineage.io/spec/2-0-2/OpenLineage.json#/$defs/RunEvent"
+	openLineageTagsFacet      = "https://openlineage.io/spec/facets/1-0-0/TagsDatasetFacet.json#/$defs/TagsDatasetFacet"
+
+	// CloudEventsSource is the "source" attribute of CloudEvents emitted by Events.
+	CloudEventsSource = "/knitfab/runs"
+)
+
+// OpenLineageRunEvent is a RunEvent of OpenLineage.
+//
+// See: https://openlineage.io/docs/spec/object-model
+type OpenLineageRunEvent struct {
+	EventType string               `json:"eventType"`
+	EventTime string               `json:"eventTime"`
+	Run       OpenLineageRun       `json:"run"`
+	Job       OpenLineageJob       `json:"job"`
+	Inputs    []OpenLineageDataset `json:"inputs"`
+	Outputs   []OpenLineageDataset `json:"outputs"`
+	Producer  string               `json:"producer"`
+	SchemaURL string               `json:"schemaURL"`
+}
+
+type OpenLineageRun struct {
+	RunId string `json:"runId"`
+}
+
+type OpenLineageJob struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type OpenLineageDataset struct {
+	Namespace string                    `json:"namespace"`
+	Name      string                    `json:"name"`
+	Facets    *OpenLineageDatasetFacets `json:"facets,omitempty"`
+}
+
+type OpenLineageDatasetFacets struct {
+	Tags *OpenLineageTagsFacet `json:"tags,omitempty"`
+}
+
+// OpenLineageTagsFacet is the TagsDatasetFacet of OpenLineage.
+type OpenLineageTagsFacet struct {
+	Producer  string           `json:"_producer"`
+	SchemaURL string           `json:"_schemaURL"`
+	Tags      []OpenLineageTag `json:"tags"`
+}
+
+type OpenLineageTag struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// CloudEvent is a CloudEvents v1.0 event in the structured content mode.
+type CloudEvent struct {
+	SpecVersion     string              `json:"specversion"`
+	Id              string              `json:"id"`
+	Source          string              `json:"source"`
+	Type            string              `json:"type"`
+	Subject         string              `json:"subject,omitempty"`
+	Time            string              `json:"time,omitempty"`
+	DataContentType string              `json:"datacontenttype,omitempty"`
+	DataSchema      string              `json:"dataschema,omitempty"`
+	Data            OpenLineageRunEvent `json:"data"`
+}
+
+// Events is a hook which emits lifecycle events of Runs to URLs
+// in a standard format, OpenLineage RunEvent or CloudEvents.
+//
+// Events are emitted only after status changes of Runs, and only for statuses below:
+//
+// - "starting": START
+//
+// - "running": RUNNING
+//
+// - "done": COMPLETE
+//
+// - "failed": FAIL
+//
+// - "invalidated": ABORT
+//
+// Before does nothing.
+type Events[R any] struct {
+	// Format is the format of events.
+	Format cfg_hook.EventFormat
+
+	// Namespace is the namespace of jobs and datasets.
+	Namespace string
+
+	// URLs are destinations of events.
+	URLs []*url.URL
+
+	// Tags looks up Tags of Data by Knit Ids.
+	//
+	// If Tags is nil, datasets in events have no tags.
+	Tags func(knitIds []string) (map[string][]apitags.Tag, error)
+}
+
+// FromConfig builds Events from the configuration.
+func FromConfig[R any](
+	cfg cfg_hook.EventHook,
+	tags func(knitIds []string) (map[string][]apitags.Tag, error),
+) Events[R] {
+	return Events[R]{
+		Format:    cfg.Format,
+		Namespace: cfg.Namespace,
+		URLs:      cfg.URLs,
+		Tags:      tags,
+	}
+}
+
+// eventType returns the type of OpenLineage RunEvent for the status of a Run.
+//
+// If the status has no corresponding event, it returns false.
+func eventType(status string) (string, bool) {
+	switch domain.KnitRunStatus(status) {
+	case domain.Starting:
+		return "START", true
+	case domain.Running:
+		return "RUNNING", true
+	case domain.Done:
+		return "COMPLETE", true
+	case domain.Failed:
+		return "FAIL", true
+	case domain.Invalidated:
+		return "ABORT", true
+	default:
+		return "", false
+	}
+}
+
+// RunEvent converts the Run to an OpenLineage RunEvent.
+//
+// Jobs are named with Plan Ids, and datasets are named with Knit Ids.
+//
+// If the status of the Run has no corresponding event, it returns false.
+func (e Events[R]) RunEvent(value apiruns.Detail) (OpenLineageRunEvent, bool, error) {
+	evtype, ok := eventType(value.Status)
+	if !ok {
+		return OpenLineageRunEvent{}, false, nil
+	}
+
+	knitIds := []string{}
+	for _, in := range value.Inputs {
+		knitIds = append(knitIds, in.KnitId)
+	}
+	for _, out := range value.Outputs {
+		knitIds = append(knitIds, out.KnitId)
+	}
+	if l := value.Log; l != nil {
+		knitIds = append(knitIds, l.KnitId)
+	}
+
+	tags := map[string][]apitags.Tag{}
+	if e.Tags != nil && 0 < len(knitIds) {
+		t, err := e.Tags(knitIds)
+		if err != nil {
+			return OpenLineageRunEvent{}, false, err
+		}
+		tags = t
+	}
+
+	dataset := func(knitId string) OpenLineageDataset {
+		ds := OpenLineageDataset{Namespace: e.Namespace, Name: knitId}
+		ts, ok := tags[knitId]
+		if !ok {
+			return ds
+		}
+		facet := &OpenLineageTagsFacet{
+			Producer:  OpenLineageProducer,
+			SchemaURL: openLineageTagsFacet,
+			Tags:      make([]OpenLineageTag, 0, len(ts)),
+		}
+		for _, t := range ts {
+			facet.Tags = append(facet.Tags, OpenLineageTag{
+				Key: t.Key, Value: t.Value, Source: "knitfab",
+			})
+		}
+		ds.Facets = &OpenLineageDatasetFacets{Tags: facet}
+		return ds
+	}
+
+	ev := OpenLineageRunEvent{
+		EventType: evtype,
+		EventTime: value.UpdatedAt.String(),
+		Run:       OpenLineageRun{RunId: value.RunId},
+		Job:       OpenLineageJob{Namespace: e.Namespace, Name: value.Plan.PlanId},
+		Inputs:    []OpenLineageDataset{},
+		Outputs:   []OpenLineageDataset{},
+		Producer:  OpenLineageProducer,
+		SchemaURL: openLineageRunEventSchema,
+	}
+	for _, in := range value.Inputs {
+		ev.Inputs = append(ev.Inputs, dataset(in.KnitId))
+	}
+	for _, out := range value.Outputs {
+		ev.Outputs = append(ev.Outputs, dataset(out.KnitId))
+	}
+	if l := value.Log; l != nil {
+		ev.Outputs = append(ev.Outputs, dataset(l.KnitId))
+	}
+	return ev, true, nil
+}
+
+// CloudEvent wraps an OpenLineage RunEvent with CloudEvents envelope.
+//
+// The type of the CloudEvent is "io.knitfab.run.<event type in lower case>",
+// e.g. "io.knitfab.run.start".
+func (e Events[R]) CloudEvent(value apiruns.Detail, ev OpenLineageRunEvent) CloudEvent {
+	id := fmt.Sprintf("%s/%s", value.RunId, value.Status)
+	if 0 < value.Attempt {
+		id = fmt.Sprintf("%s/%d", id, value.Attempt)
+	}
+	return CloudEvent{
+		SpecVersion:     "1.0",
+		Id:              id,
+		Source:          CloudEventsSource,
+		Type:            "io.knitfab.run." + strings.ToLower(ev.EventType),
+		Subject:         value.RunId,
+		Time:            ev.EventTime,
+		DataContentType: "application/json",
+		DataSchema:      openLineageRunEventSchema,
+		Data:            ev,
+	}
+}
+
+func (e Events[R]) payload(value apiruns.Detail) (string, []byte, bool, error) {
+	ev, ok, err := e.RunEvent(value)
+	if err != nil || !ok {
+		return "", nil, false, err
+	}
+
+	var body any = ev
+	contentType := "application/json"
+	if e.Format == cfg_hook.CloudEvents {
+		body = e.CloudEvent(value, ev)
+		contentType = "application/cloudevents+json"
+	}
+
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return "", nil, false, err
+	}
+	return contentType, buf, true, nil
+}
+
+func (e Events[R]) Before(context.Context, apiruns.Detail) (R, error) {
+	return *new(R), nil
+}
+
+// After sends an event to each URL.
+//
+// Even if some of URLs fail, the event is sent to the rest of URLs.
+func (e Events[R]) After(ctx context.Context, value apiruns.Detail) error {
+	if len(e.URLs) == 0 {
+		return nil
+	}
+
+	contentType, buf, ok, err := e.payload(value)
+	if err != nil {
+		return errors.Join(err, ErrHookFailed)
+	}
+	if !ok {
+		return nil
+	}
+
+	errs := []error{}
+	for _, u := range e.URLs {
+		if err := sendEvent(ctx, u.String(), contentType, buf); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sendEvent(ctx context.Context, url string, contentType string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payload))
+	if err != nil {
+		return errors.Join(err, ErrHookFailed)
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Join(err, ErrHookFailed)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if 200 <= resp.StatusCode && resp.StatusCode < 300 {
+		return nil
+	}
+	return fmt.Errorf("%w (%s %d)", ErrHookFailed, url, resp.StatusCode)
+}
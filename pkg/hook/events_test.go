@@ -0,0 +1,223 @@
+package hook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/opst/knitfab-api-types/misc/rfctime"
+	apiplans "github.com/opst/knitfab-api-types/plans"
+	apiruns "github.com/opst/knitfab-api-types/runs"
+	apitags "github.com/opst/knitfab-api-types/tags"
+	cfg_hook "github.com/opst/knitfab/pkg/configs/hook"
+	"github.com/opst/knitfab/pkg/hook"
+	"github.com/opst/knitfab/pkg/utils/cmp"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestEvents_After(t *testing.T) {
+	updatedAt := try.To(rfctime.ParseRFC3339DateTime("2024-01-02T03:04:05.678+09:00")).OrFatal(t)
+	run := func(status string) apiruns.Detail {
+		return apiruns.Detail{
+			Summary: apiruns.Summary{
+				RunId:     "run-1",
+				Status:    status,
+				UpdatedAt: updatedAt,
+				Plan:      apiplans.Summary{PlanId: "plan-1"},
+			},
+			Inputs: []apiruns.Assignment{
+				{Mountpoint: apiplans.Mountpoint{Path: "/in/1"}, KnitId: "data-in"},
+			},
+			Outputs: []apiruns.Assignment{
+				{Mountpoint: apiplans.Mountpoint{Path: "/out/1"}, KnitId: "data-out"},
+			},
+			Log: &apiruns.LogSummary{KnitId: "data-log"},
+		}
+	}
+	tags := func(knitIds []string) (map[string][]apitags.Tag, error) {
+		if !cmp.SliceContentEq(knitIds, []string{"data-in", "data-out", "data-log"}) {
+			t.Errorf("unexpected knitIds: %v", knitIds)
+		}
+		return map[string][]apitags.Tag{
+			"data-in": {{Key: "type", Value: "csv"}},
+		}, nil
+	}
+
+	type request struct {
+		contentType string
+		body        []byte
+	}
+	serve := func(t *testing.T, status int) (*url.URL, *[]request) {
+		reqs := []request{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				t.Errorf("unexpected method: %s", r.Method)
+			}
+			body, _ := io.ReadAll(r.Body)
+			reqs = append(reqs, request{contentType: r.Header.Get("Content-Type"), body: body})
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(server.Close)
+		return try.To(url.Parse(server.URL)).OrFatal(t), &reqs
+	}
+
+	wantEvent := func(eventType string) hook.OpenLineageRunEvent {
+		return hook.OpenLineageRunEvent{
+			EventType: eventType,
+			EventTime: updatedAt.String(),
+			Run:       hook.OpenLineageRun{RunId: "run-1"},
+			Job:       hook.OpenLineageJob{Namespace: "example", Name: "plan-1"},
+			Inputs: []hook.OpenLineageDataset{
+				{
+					Namespace: "example", Name: "data-in",
+					Facets: &hook.OpenLineageDatasetFacets{
+						Tags: &hook.OpenLineageTagsFacet{
+							Producer:  hook.OpenLineageProducer,
+							SchemaURL: "https://openlineage.io/spec/facets/1-0-0/TagsDatasetFacet.json#/$defs/TagsDatasetFacet",
+							Tags:      []hook.OpenLineageTag{{Key: "type", Value: "csv", Source: "knitfab"}},
+						},
+					},
+				},
+			},
+			Outputs: []hook.OpenLineageDataset{
+				{Namespace: "example", Name: "data-out"},
+				{Namespace: "example", Name: "data-log"},
+			},
+			Producer:  hook.OpenLineageProducer,
+			SchemaURL: "https://openlineage.io/spec/2-0-2/OpenLineage.json#/$defs/RunEvent",
+		}
+	}
+	eventEq := func(a, b hook.OpenLineageRunEvent) bool {
+		ja := try.To(json.Marshal(a)).OrFatal(t)
+		jb := try.To(json.Marshal(b)).OrFatal(t)
+		return string(ja) == string(jb)
+	}
+
+	for name, testcase := range map[string]struct {
+		status    string
+		eventType string
+	}{
+		"starting -> START":      {status: "starting", eventType: "START"},
+		"running -> RUNNING":     {status: "running", eventType: "RUNNING"},
+		"done -> COMPLETE":       {status: "done", eventType: "COMPLETE"},
+		"failed -> FAIL":         {status: "failed", eventType: "FAIL"},
+		"invalidated -> ABORT":   {status: "invalidated", eventType: "ABORT"},
+		"ready -> (no event)":    {status: "ready"},
+		"aborting -> (no event)": {status: "aborting"},
+	} {
+		t.Run("openlineage: "+name, func(t *testing.T) {
+			u, reqs := serve(t, http.StatusOK)
+			testee := hook.Events[struct{}]{
+				Format: cfg_hook.OpenLineage, Namespace: "example",
+				URLs: []*url.URL{u}, Tags: tags,
+			}
+			if err := testee.After(context.Background(), run(testcase.status)); err != nil {
+				t.Fatal(err)
+			}
+
+			if testcase.eventType == "" {
+				if len(*reqs) != 0 {
+					t.Errorf("unexpected requests: %d", len(*reqs))
+				}
+				return
+			}
+			if len(*reqs) != 1 {
+				t.Fatalf("unexpected requests: %d", len(*reqs))
+			}
+			req := (*reqs)[0]
+			if req.contentType != "application/json" {
+				t.Errorf("unexpected content type: %s", req.contentType)
+			}
+			var got hook.OpenLineageRunEvent
+			if err := json.Unmarshal(req.body, &got); err != nil {
+				t.Fatal(err)
+			}
+			if want := wantEvent(testcase.eventType); !eventEq(got, want) {
+				t.Errorf("event:\n===actual===\n%+v\n===expected===\n%+v", got, want)
+			}
+		})
+	}
+
+	t.Run("cloudevents", func(t *testing.T) {
+		u, reqs := serve(t, http.StatusOK)
+		testee := hook.Events[struct{}]{
+			Format: cfg_hook.CloudEvents, Namespace: "example",
+			URLs: []*url.URL{u}, Tags: tags,
+		}
+		if err := testee.After(context.Background(), run("done")); err != nil {
+			t.Fatal(err)
+		}
+		if len(*reqs) != 1 {
+			t.Fatalf("unexpected requests: %d", len(*reqs))
+		}
+		req := (*reqs)[0]
+		if req.contentType != "application/cloudevents+json" {
+			t.Errorf("unexpected content type: %s", req.contentType)
+		}
+
+		var got hook.CloudEvent
+		if err := json.Unmarshal(req.body, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.SpecVersion != "1.0" ||
+			got.Id != "run-1/done" ||
+			got.Source != hook.CloudEventsSource ||
+			got.Type != "io.knitfab.run.complete" ||
+			got.Subject != "run-1" ||
+			got.Time != updatedAt.String() ||
+			got.DataContentType != "application/json" {
+			t.Errorf("unexpected envelope: %+v", got)
+		}
+		if want := wantEvent("COMPLETE"); !eventEq(got.Data, want) {
+			t.Errorf("data:\n===actual===\n%+v\n===expected===\n%+v", got.Data, want)
+		}
+	})
+
+	t.Run("it sends events to all URLs even if some of them fail", func(t *testing.T) {
+		u1, reqs1 := serve(t, http.StatusInternalServerError)
+		u2, reqs2 := serve(t, http.StatusOK)
+		testee := hook.Events[struct{}]{
+			Format: cfg_hook.OpenLineage, Namespace: "example",
+			URLs: []*url.URL{u1, u2}, Tags: tags,
+		}
+		err := testee.After(context.Background(), run("done"))
+		if !errors.Is(err, hook.ErrHookFailed) {
+			t.Errorf("expected ErrHookFailed, but got %v", err)
+		}
+		if len(*reqs1) != 1 || len(*reqs2) != 1 {
+			t.Errorf("unexpected requests: %d, %d", len(*reqs1), len(*reqs2))
+		}
+	})
+
+	t.Run("it fails when tags cannot be looked up", func(t *testing.T) {
+		u, reqs := serve(t, http.StatusOK)
+		expectedErr := errors.New("fake error")
+		testee := hook.Events[struct{}]{
+			Format: cfg_hook.OpenLineage, Namespace: "example",
+			URLs: []*url.URL{u},
+			Tags: func([]string) (map[string][]apitags.Tag, error) { return nil, expectedErr },
+		}
+		err := testee.After(context.Background(), run("done"))
+		if !errors.Is(err, expectedErr) || !errors.Is(err, hook.ErrHookFailed) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(*reqs) != 0 {
+			t.Errorf("unexpected requests: %d", len(*reqs))
+		}
+	})
+}
+
+func TestEvents_Before(t *testing.T) {
+	testee := hook.Events[struct{}]{
+		Format: cfg_hook.OpenLineage,
+		URLs:   []*url.URL{try.To(url.Parse("http://somewhere.invalid")).OrFatal(t)},
+	}
+	if _, err := testee.Before(context.Background(), apiruns.Detail{Summary: apiruns.Summary{Status: "starting"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
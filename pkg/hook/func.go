@@ -1,6 +1,9 @@
 package hook
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 // Func is a hook that calls functions before and after processing the value T.
 type Func[T any, R any] struct {
@@ -13,9 +16,14 @@ type Func[T any, R any] struct {
 	//
 	// If AfterFn is nil, it is not called.
 	AfterFn func(T) error
+
+	// EnqueueFn is a function to call when the value T is queued for After.
+	//
+	// If EnqueueFn is nil, it is not called.
+	EnqueueFn func(T) error
 }
 
-func (f Func[T, R]) Before(value T) (R, error) {
+func (f Func[T, R]) Before(_ context.Context, value T) (R, error) {
 	if f.BeforeFn == nil {
 		return *new(R), nil
 	}
@@ -26,7 +34,7 @@ func (f Func[T, R]) Before(value T) (R, error) {
 	return ret, nil
 }
 
-func (f Func[T, R]) After(value T) error {
+func (f Func[T, R]) After(_ context.Context, value T) error {
 	if f.AfterFn == nil {
 		return nil
 	}
@@ -36,3 +44,14 @@ func (f Func[T, R]) After(value T) error {
 	}
 	return nil
 }
+
+func (f Func[T, R]) Enqueue(_ context.Context, value T) error {
+	if f.EnqueueFn == nil {
+		return nil
+	}
+	err := f.EnqueueFn(value)
+	if err != nil {
+		return errors.Join(err, ErrHookFailed)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package hook
+
+import (
+	"context"
+	"errors"
+)
+
+// Hook is an interface for before/after hooks.
+type Hook[T any, R any] interface {
+	// Before is called before the value T is processed.
+	Before(context.Context, T) (R, error)
+
+	// After is called after the value T is processed.
+	After(context.Context, T) error
+}
+
+// Queue is implemented by hooks which deliver values for After through an outbox.
+type Queue[T any] interface {
+	// Enqueue queues the value for After into the outbox.
+	//
+	// When a database transaction is bound to the context,
+	// the value is queued in the transaction,
+	// so it is queued if and only if the change notified with the value is committed.
+	Enqueue(context.Context, T) error
+}
+
+// Enqueue queues the value into the outbox of the hook, if the hook is a Queue.
+//
+// Otherwise, it does nothing.
+// Either way, call After of the hook after the change is committed.
+func Enqueue[T any, R any](ctx context.Context, h Hook[T, R], value T) error {
+	q, ok := h.(Queue[T])
+	if !ok {
+		return nil
+	}
+	return q.Enqueue(ctx, value)
+}
+
+var ErrHookFailed = errors.New("hook failed")
+
+// ErrHookRejected is an error that the hook refuses the value.
+//
+// Errors wrapping this also wrap ErrHookFailed.
+var ErrHookRejected = errors.New("hook rejected")
@@ -0,0 +1,58 @@
+package hook
+
+import (
+	"context"
+	"errors"
+)
+
+// Multi is a hook which calls hooks in order.
+type Multi[T any, R any] struct {
+	Hooks []Hook[T, R]
+
+	// Merge merges responses from Before of each hook.
+	Merge func(a, b R) R
+}
+
+// Before calls Before of each hook in order, and returns merged responses.
+//
+// If one of hooks fails, it stops and returns the error.
+func (m Multi[T, R]) Before(ctx context.Context, value T) (R, error) {
+	ret := *new(R)
+	for i, h := range m.Hooks {
+		r, err := h.Before(ctx, value)
+		if err != nil {
+			return *new(R), err
+		}
+		if i == 0 {
+			ret = r
+		} else {
+			ret = m.Merge(ret, r)
+		}
+	}
+	return ret, nil
+}
+
+// After calls After of each hook in order.
+//
+// Even if some of hooks fail, it calls all hooks and returns joined errors.
+func (m Multi[T, R]) After(ctx context.Context, value T) error {
+	errs := []error{}
+	for _, h := range m.Hooks {
+		if err := h.After(ctx, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Enqueue queues the value into outboxes of hooks which are Queue.
+//
+// If one of them fails, it stops and returns the error.
+func (m Multi[T, R]) Enqueue(ctx context.Context, value T) error {
+	for _, h := range m.Hooks {
+		if err := Enqueue(ctx, h, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
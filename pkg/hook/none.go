@@ -0,0 +1,14 @@
+package hook
+
+import "context"
+
+// None is a hook that does nothing.
+type None[T any] struct{}
+
+func (None[T]) Before(_ context.Context, value T) (struct{}, error) {
+	return struct{}{}, nil
+}
+
+func (None[T]) After(_ context.Context, value T) error {
+	return nil
+}
@@ -0,0 +1,50 @@
+package hook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// HeaderTimestamp is the header of the time when the request is sent, in Unix seconds.
+	HeaderTimestamp = "X-Knitfab-Timestamp"
+
+	// HeaderSignature is the header of the signature of the request.
+	//
+	// The value is "sha256=" followed by hex-encoded HMAC-SHA256 of
+	// "<value of X-Knitfab-Timestamp>.<request body>" with the secret.
+	HeaderSignature = "X-Knitfab-Signature"
+
+	// HeaderDelivery is the header of the id of the delivery in the webhook outbox.
+	//
+	// Receivers can use it to detect duplicated deliveries.
+	HeaderDelivery = "X-Knitfab-Delivery"
+)
+
+// Sign returns the signature of the payload sent at the timestamp.
+//
+// The signature is "sha256=" followed by hex-encoded HMAC-SHA256 of
+// "<timestamp in Unix seconds>.<payload>" with the secret.
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify verifies the signature of the request, which is signed by Sign.
+//
+// It is intended to be used by receivers written in Go, and for testing.
+func Verify(secret string, header http.Header, payload []byte) bool {
+	ts, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return false
+	}
+	expected := Sign(secret, time.Unix(ts, 0), payload)
+	return hmac.Equal([]byte(expected), []byte(header.Get(HeaderSignature)))
+}
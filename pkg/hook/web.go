@@ -0,0 +1,257 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	cfg_hook "github.com/opst/knitfab/pkg/configs/hook"
+	"github.com/opst/knitfab/pkg/utils/slices"
+)
+
+// Web is a webhook for before/after hooks.
+type Web[T any, R any] struct {
+	// BeforeURL is a list of URLs to call before processing the value T.
+	//
+	// The value T is sent as a JSON payload for each URL.
+	//
+	// If and only if all of the URLs return a 2xx status code, the hook proceeds.
+	// Otherwise, the hook fails.
+	BeforeURL []*url.URL
+
+	// AfterURL is a list of URLs to call after processing the value T.
+	//
+	// The value T is sent as a JSON payload for each URL.
+	//
+	// If and only if all of the URLs return a 2xx status code, the hook proceeds.
+	// Otherwise, the hook fails.
+	AfterURL []*url.URL
+
+	Merge func(a, b R) R
+
+	// Secret is a key to sign payloads with HMAC-SHA256.
+	//
+	// If empty, payloads are not signed. See Sign for the signature.
+	Secret string
+
+	// Timeout is the timeout for each request.
+	//
+	// If zero, requests do not time out.
+	Timeout time.Duration
+
+	// Retry is the policy to retry failed requests.
+	//
+	// Requests are retried when they fail without response, or with the status 429 or 5xx.
+	// Waiting for the next attempt is cancelled when the context is done.
+	// Zero value means no retries.
+	Retry cfg_hook.RetryPolicy
+
+	// Outbox queues payloads for AfterURL instead of sending them.
+	//
+	// Queued payloads are delivered by the webhook delivery loop,
+	// so they are not lost even if loops are restarted.
+	//
+	// When it is set, After does nothing:
+	// values should be queued with Enqueue, in the transaction of the change they notify.
+	//
+	// If nil, payloads are sent directly by After.
+	Outbox func(ctx context.Context, urls []string, payload []byte) error
+}
+
+// Sender sends payloads to webhooks.
+type Sender struct {
+	// Secret is a key to sign payloads. If empty, payloads are not signed.
+	Secret string
+
+	// Timeout is the timeout for each request. If zero, requests do not time out.
+	Timeout time.Duration
+}
+
+// Post sends the payload as JSON to the url.
+//
+// If header is not nil, it is added to the request.
+//
+// The caller should close the body of the response.
+func (s Sender) Post(ctx context.Context, url string, payload []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Secret != "" {
+		now := time.Now()
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set(HeaderSignature, Sign(s.Secret, now, payload))
+	}
+
+	client := &http.Client{Timeout: s.Timeout}
+	return client.Do(req)
+}
+
+// Deliver sends the payload queued in the webhook outbox.
+//
+// It returns nil if and only if the url responds with a 2xx status code.
+func (s Sender) Deliver(ctx context.Context, url string, payload []byte, deliveryId int64) error {
+	header := http.Header{}
+	header.Set(HeaderDelivery, strconv.FormatInt(deliveryId, 10))
+
+	resp, err := s.Post(ctx, url, payload, header)
+	if err != nil {
+		return errors.Join(err, ErrHookFailed)
+	}
+	defer resp.Body.Close()
+
+	if 200 <= resp.StatusCode && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%w (%s %d): %s", ErrHookFailed, url, resp.StatusCode, string(body))
+}
+
+// retryable tells whether the request failed with the status should be retried.
+//
+// Other 4xx statuses are treated as the refusal of the receiver, so they are not retried.
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || 500 <= statusCode
+}
+
+func (w Web[T, R]) sendRequest(ctx context.Context, url string, payload []byte) (R, error) {
+	for attempts := 1; ; attempts++ {
+		r, retry, err := w.sendRequestOnce(ctx, url, payload)
+		if err == nil || !retry {
+			return r, err
+		}
+		wait, ok := w.Retry.Wait(attempts)
+		if !ok {
+			return r, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return r, errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func (w Web[T, R]) sendRequestOnce(ctx context.Context, url string, payload []byte) (R, bool, error) {
+	resp, err := Sender{Secret: w.Secret, Timeout: w.Timeout}.Post(ctx, url, payload, nil)
+	if err != nil {
+		return *new(R), true, errors.Join(err, ErrHookFailed)
+	}
+	defer resp.Body.Close()
+
+	if 200 <= resp.StatusCode && resp.StatusCode < 300 {
+
+		if resp.Header.Get("Content-Type") == "application/json" {
+			r := new(R)
+			if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+				return *r, false, errors.Join(err, ErrHookFailed)
+			}
+			return *r, false, nil
+		}
+
+		return *new(R), false, nil
+	}
+
+	retry := retryable(resp.StatusCode)
+	ctype := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(ctype, "text/") && !(strings.HasPrefix(ctype, "application/") && strings.Contains(ctype, "json")) {
+		return *new(R), retry, refusal(retry, fmt.Errorf(
+			"%w (%s %d, Content-Type: %s)",
+			ErrHookFailed, url, resp.StatusCode, ctype,
+		))
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	return *new(R), retry, refusal(retry, fmt.Errorf(
+		"%w (%s %d, Content-Type: %s): %s",
+		ErrHookFailed, url, resp.StatusCode, ctype, string(body),
+	))
+}
+
+// refusal marks err as ErrHookRejected if the request is not retryable,
+// since the receiver refuses the value then.
+func refusal(retry bool, err error) error {
+	if retry {
+		return err
+	}
+	return errors.Join(err, ErrHookRejected)
+}
+
+func (w Web[T, R]) hook(ctx context.Context, value T, urls []*url.URL) (R, error) {
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return *new(R), err
+	}
+
+	if len(urls) == 0 {
+		return *new(R), nil
+	}
+
+	first, rest := urls[0], urls[1:]
+
+	resp, err := w.sendRequest(ctx, first.String(), buf)
+	if err != nil {
+		return *new(R), err
+	}
+
+	for _, url := range rest {
+		r, err := w.sendRequest(ctx, url.String(), buf)
+		if err != nil {
+			return *new(R), err
+		}
+		resp = w.Merge(resp, r)
+	}
+
+	return resp, nil
+}
+
+func (w Web[T, R]) Before(ctx context.Context, value T) (R, error) {
+	return w.hook(ctx, value, w.BeforeURL)
+}
+
+// After sends the value to AfterURL.
+//
+// When Outbox is set, it does nothing. See Enqueue.
+func (w Web[T, R]) After(ctx context.Context, value T) error {
+	if w.Outbox != nil {
+		return nil
+	}
+	_, err := w.hook(ctx, value, w.AfterURL)
+	return err
+}
+
+// Enqueue queues the value for AfterURL into Outbox.
+//
+// When Outbox is not set, it does nothing. See After.
+func (w Web[T, R]) Enqueue(ctx context.Context, value T) error {
+	if w.Outbox == nil || len(w.AfterURL) == 0 {
+		return nil
+	}
+	buf, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	urls := slices.Map(w.AfterURL, func(u *url.URL) string { return u.String() })
+	if err := w.Outbox(ctx, urls, buf); err != nil {
+		return errors.Join(err, ErrHookFailed)
+	}
+	return nil
+}
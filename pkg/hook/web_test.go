@@ -2,14 +2,17 @@ package hook_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
-	"github.com/opst/knitfab/cmd/loops/hook"
+	cfg_hook "github.com/opst/knitfab/pkg/configs/hook"
+	"github.com/opst/knitfab/pkg/hook"
 	"github.com/opst/knitfab/pkg/utils/cmp"
 	"github.com/opst/knitfab/pkg/utils/try"
 )
@@ -100,7 +103,7 @@ func TestWebHook_Before(t *testing.T) {
 					return ret
 				},
 			}
-			ret, err := testee.Before(when.value)
+			ret, err := testee.Before(context.Background(), when.value)
 			if !errors.Is(err, then.err) {
 				t.Errorf("Want: %v, Got: %v", then.err, err)
 			}
@@ -187,7 +190,7 @@ func TestHook_Before_Sends_InvalidUrl(t *testing.T) {
 		Merge: func(a, b struct{}) struct{} { return struct{}{} },
 	}
 
-	_, err := testee.Before("hello")
+	_, err := testee.Before(context.Background(), "hello")
 	if err == nil {
 		t.Fatal("Expected an error")
 	}
@@ -279,7 +282,7 @@ func TestWebHook_After(t *testing.T) {
 					return ret
 				},
 			}
-			err := testee.After(when.value)
+			err := testee.After(context.Background(), when.value)
 			if !errors.Is(err, then.err) {
 				t.Errorf("Expected: %v, Got: %v", then.err, err)
 			}
@@ -344,8 +347,237 @@ func TestHook_After_Sends_InvalidUrl(t *testing.T) {
 		Merge: func(a, b struct{}) struct{} { return struct{}{} },
 	}
 
-	err := testee.After("hello")
+	err := testee.After(context.Background(), "hello")
 	if err == nil {
 		t.Fatal("Expected an error")
 	}
 }
+
+func TestWebHook_Signature(t *testing.T) {
+	secret := "s3cr3t"
+	verified := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(r.Body)
+		verified = hook.Verify(secret, r.Header, buf.Bytes())
+		if hook.Verify("wrong secret", r.Header, buf.Bytes()) {
+			t.Error("verified with wrong secret")
+		}
+		if hook.Verify(secret, r.Header, append(buf.Bytes(), ' ')) {
+			t.Error("verified with modified payload")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	testee := hook.Web[string, struct{}]{
+		BeforeURL: []*url.URL{try.To(url.Parse(server.URL)).OrFatal(t)},
+		Merge:     func(a, b struct{}) struct{} { return struct{}{} },
+		Secret:    secret,
+	}
+	if _, err := testee.Before(context.Background(), "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if !verified {
+		t.Error("signature is not verified")
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac 'secret'
+	got := hook.Sign("secret", time.Unix(1700000000, 0), []byte(`{"a":1}`))
+	want := "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"
+	if got != want {
+		t.Errorf("unexpected signature: %s", got)
+	}
+	if got == hook.Sign("secret", time.Unix(1700000001, 0), []byte(`{"a":1}`)) {
+		t.Error("signature does not depend on timestamp")
+	}
+}
+
+func TestWebHook_Retry(t *testing.T) {
+	for name, testcase := range map[string]struct {
+		statuses []int
+		invoked  int
+		err      error
+		rejected bool
+	}{
+		"it retries 5xx until success": {
+			statuses: []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK},
+			invoked:  3,
+		},
+		"it retries 429": {
+			statuses: []int{http.StatusTooManyRequests, http.StatusOK},
+			invoked:  2,
+		},
+		"it gives up after max attempts": {
+			statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			invoked:  3,
+			err:      hook.ErrHookFailed,
+		},
+		"it does not retry other 4xx": {
+			statuses: []int{http.StatusForbidden, http.StatusOK},
+			invoked:  1,
+			err:      hook.ErrHookFailed,
+			rejected: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			invoked := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(testcase.statuses[invoked])
+				invoked += 1
+			}))
+			defer server.Close()
+
+			testee := hook.Web[string, struct{}]{
+				BeforeURL: []*url.URL{try.To(url.Parse(server.URL)).OrFatal(t)},
+				Merge:     func(a, b struct{}) struct{} { return struct{}{} },
+				Retry:     cfg_hook.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+			}
+			_, err := testee.Before(context.Background(), "hello")
+			if !errors.Is(err, testcase.err) {
+				t.Errorf("Expected: %v, Got: %v", testcase.err, err)
+			}
+			if errors.Is(err, hook.ErrHookRejected) != testcase.rejected {
+				t.Errorf("rejected: %v, want %v (err = %v)", !testcase.rejected, testcase.rejected, err)
+			}
+			if invoked != testcase.invoked {
+				t.Errorf("invoked: %d, want %d", invoked, testcase.invoked)
+			}
+		})
+	}
+
+	t.Run("it stops waiting for the next attempt when the context is done", func(t *testing.T) {
+		invoked := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			invoked += 1
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		testee := hook.Web[string, struct{}]{
+			BeforeURL: []*url.URL{try.To(url.Parse(server.URL)).OrFatal(t)},
+			Merge:     func(a, b struct{}) struct{} { return struct{}{} },
+			Retry:     cfg_hook.RetryPolicy{MaxAttempts: 3, Backoff: time.Hour},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := testee.Before(ctx, "hello")
+		if !errors.Is(err, hook.ErrHookFailed) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if invoked != 1 {
+			t.Errorf("invoked: %d, want 1", invoked)
+		}
+	})
+
+	t.Run("it times out", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		testee := hook.Web[string, struct{}]{
+			BeforeURL: []*url.URL{try.To(url.Parse(server.URL)).OrFatal(t)},
+			Merge:     func(a, b struct{}) struct{} { return struct{}{} },
+			Timeout:   10 * time.Millisecond,
+		}
+		if _, err := testee.Before(context.Background(), "hello"); !errors.Is(err, hook.ErrHookFailed) {
+			t.Errorf("Expected: %v, Got: %v", hook.ErrHookFailed, err)
+		}
+	})
+}
+
+func TestWebHook_Enqueue_Outbox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("webhook should not be called directly")
+	}))
+	defer server.Close()
+
+	type queued struct {
+		urls    []string
+		payload string
+	}
+	got := []queued{}
+	testee := hook.Web[map[string]string, struct{}]{
+		AfterURL: []*url.URL{
+			try.To(url.Parse(server.URL + "/1")).OrFatal(t),
+			try.To(url.Parse(server.URL + "/2")).OrFatal(t),
+		},
+		Merge: func(a, b struct{}) struct{} { return struct{}{} },
+		Outbox: func(ctx context.Context, urls []string, payload []byte) error {
+			got = append(got, queued{urls: urls, payload: string(payload)})
+			return nil
+		},
+	}
+	if err := testee.After(context.Background(), map[string]string{"content": "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("queued by After: %+v", got)
+	}
+	if err := hook.Enqueue[map[string]string, struct{}](
+		context.Background(), testee, map[string]string{"content": "hello"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("unexpected queued: %+v", got)
+	}
+	if !cmp.SliceEq(got[0].urls, []string{server.URL + "/1", server.URL + "/2"}) {
+		t.Errorf("urls: %v", got[0].urls)
+	}
+	if got[0].payload != `{"content":"hello"}` {
+		t.Errorf("payload: %s", got[0].payload)
+	}
+
+	t.Run("it fails when outbox fails", func(t *testing.T) {
+		expectedErr := errors.New("fake error")
+		testee.Outbox = func(ctx context.Context, urls []string, payload []byte) error { return expectedErr }
+		err := testee.Enqueue(context.Background(), map[string]string{"content": "hello"})
+		if !errors.Is(err, expectedErr) || !errors.Is(err, hook.ErrHookFailed) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSender_Deliver(t *testing.T) {
+	for name, testcase := range map[string]struct {
+		status int
+		err    error
+	}{
+		"success": {status: http.StatusNoContent},
+		"failure": {status: http.StatusBadGateway, err: hook.ErrHookFailed},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var header http.Header
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header
+				buf := new(bytes.Buffer)
+				buf.ReadFrom(r.Body)
+				body = buf.Bytes()
+				w.WriteHeader(testcase.status)
+			}))
+			defer server.Close()
+
+			testee := hook.Sender{Secret: "s3cr3t", Timeout: time.Second}
+			err := testee.Deliver(context.Background(), server.URL, []byte(`{"a":1}`), 42)
+			if !errors.Is(err, testcase.err) {
+				t.Errorf("Expected: %v, Got: %v", testcase.err, err)
+			}
+			if string(body) != `{"a":1}` {
+				t.Errorf("body: %s", body)
+			}
+			if got := header.Get(hook.HeaderDelivery); got != "42" {
+				t.Errorf("delivery header: %s", got)
+			}
+			if !hook.Verify("s3cr3t", header, body) {
+				t.Error("signature is not verified")
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package archive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opst/knitfab/pkg/utils/archive/internal"
+)
+
+// prefix of checksums, telling the hash algorithm.
+const ChecksumPrefix = "sha256:"
+
+// Checksum builds a checksum of files, as a Merkle tree.
+//
+// Each regular file is hashed by its content, and each symlink by its target.
+// Each directory is hashed by kinds, names and hashes of its entries in lexical order.
+// Directories without any files are ignored, same as tarballs made by GoTar.
+// Permissions and timestamps are not a part of the checksum.
+//
+// Files can be added in any order.
+type Checksum struct {
+	root *checksumNode
+}
+
+type checksumNode struct {
+	kind     byte
+	sum      []byte
+	children map[string]*checksumNode
+}
+
+const (
+	kindFile    byte = 'f'
+	kindSymlink byte = 'l'
+	kindDir     byte = 'd'
+)
+
+func NewChecksum() *Checksum {
+	return &Checksum{root: &checksumNode{kind: kindDir, children: map[string]*checksumNode{}}}
+}
+
+// AddFile adds a regular file at the path (slash separated, relative to the root) with its content.
+func (c *Checksum) AddFile(p string, content io.Reader) error {
+	h := sha256.New()
+	if _, err := io.Copy(h, content); err != nil {
+		return err
+	}
+	c.put(p, &checksumNode{kind: kindFile, sum: h.Sum(nil)})
+	return nil
+}
+
+// AddSymlink adds a symlink at the path (slash separated, relative to the root) pointing the target.
+func (c *Checksum) AddSymlink(p string, target string) {
+	sum := sha256.Sum256([]byte(target))
+	c.put(p, &checksumNode{kind: kindSymlink, sum: sum[:]})
+}
+
+func (c *Checksum) put(p string, leaf *checksumNode) {
+	names := strings.Split(strings.Trim(path.Clean("/"+p), "/"), "/")
+	node := c.root
+	for _, name := range names[:len(names)-1] {
+		child, ok := node.children[name]
+		if !ok || child.kind != kindDir {
+			child = &checksumNode{kind: kindDir, children: map[string]*checksumNode{}}
+			node.children[name] = child
+		}
+		node = child
+	}
+	node.children[names[len(names)-1]] = leaf
+}
+
+// Sum returns the checksum, formatted as "sha256:<hex>".
+func (c *Checksum) Sum() string {
+	return ChecksumPrefix + hex.EncodeToString(c.root.digest())
+}
+
+func (n *checksumNode) digest() []byte {
+	if n.kind != kindDir {
+		return n.sum
+	}
+
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		child := n.children[name]
+		h.Write([]byte{child.kind})
+		binary.Write(h, binary.BigEndian, uint64(len(name)))
+		h.Write([]byte(name))
+		h.Write(child.digest())
+	}
+	return h.Sum(nil)
+}
+
+// ChecksumOf returns the checksum of files under root.
+//
+// Symlinks are not followed. Files other than regular files and symlinks are ignored.
+//
+// See Checksum for the detail.
+func ChecksumOf(ctx context.Context, root string) (string, error) {
+	absroot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	c := NewChecksum()
+	err = internal.LWalk(
+		absroot,
+		func(fullpath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if fullpath == absroot {
+				return nil
+			}
+
+			relpath, err := filepath.Rel(absroot, fullpath)
+			if err != nil {
+				return err
+			}
+			relpath = filepath.ToSlash(relpath)
+
+			switch {
+			case info.Mode().IsRegular():
+				f, err := ctxOpen(ctx, fullpath)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				return c.AddFile(relpath, f)
+			case info.Mode()&os.ModeSymlink != 0:
+				target, err := os.Readlink(fullpath)
+				if err != nil {
+					return err
+				}
+				c.AddSymlink(relpath, target)
+			}
+			return nil
+		},
+		nil,
+	)
+	if err != nil {
+		return "", err
+	}
+	return c.Sum(), nil
+}
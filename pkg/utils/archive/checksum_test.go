@@ -0,0 +1,124 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opst/knitfab/pkg/utils/archive"
+	"github.com/opst/knitfab/pkg/utils/try"
+)
+
+func TestChecksum(t *testing.T) {
+	prepare := func(t *testing.T, files map[string]string) string {
+		t.Helper()
+		root := t.TempDir()
+		for name, content := range files {
+			p := filepath.Join(root, aspath(name))
+			if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return root
+	}
+
+	files := map[string]string{
+		"a.txt":         "alpha",
+		"dir/b.txt":     "bravo",
+		"dir/sub/c.txt": "charlie",
+	}
+
+	t.Run("checksum of files is stable, and starts with the prefix", func(t *testing.T) {
+		ctx := context.Background()
+		sum1 := try.To(archive.ChecksumOf(ctx, prepare(t, files))).OrFatal(t)
+		sum2 := try.To(archive.ChecksumOf(ctx, prepare(t, files))).OrFatal(t)
+		if sum1 != sum2 {
+			t.Errorf("checksums are not stable: %s != %s", sum1, sum2)
+		}
+		if !strings.HasPrefix(sum1, archive.ChecksumPrefix) {
+			t.Errorf("unexpected format: %s", sum1)
+		}
+	})
+
+	t.Run("checksum changes when content, name or layout changes", func(t *testing.T) {
+		ctx := context.Background()
+		base := try.To(archive.ChecksumOf(ctx, prepare(t, files))).OrFatal(t)
+
+		for name, changed := range map[string]map[string]string{
+			"content": {"a.txt": "alpha", "dir/b.txt": "BRAVO", "dir/sub/c.txt": "charlie"},
+			"name":    {"a.txt": "alpha", "dir/b2.txt": "bravo", "dir/sub/c.txt": "charlie"},
+			"layout":  {"a.txt": "alpha", "dir/b.txt": "bravo", "dir/c.txt": "charlie"},
+			"removed": {"a.txt": "alpha", "dir/b.txt": "bravo"},
+		} {
+			sum := try.To(archive.ChecksumOf(ctx, prepare(t, changed))).OrFatal(t)
+			if sum == base {
+				t.Errorf("%s: checksum is not changed", name)
+			}
+		}
+	})
+
+	t.Run("checksum ignores permissions and empty directories", func(t *testing.T) {
+		ctx := context.Background()
+		base := try.To(archive.ChecksumOf(ctx, prepare(t, files))).OrFatal(t)
+
+		root := prepare(t, files)
+		if err := os.Chmod(filepath.Join(root, "a.txt"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(root, "empty", "dir"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		sum := try.To(archive.ChecksumOf(ctx, root)).OrFatal(t)
+		if sum != base {
+			t.Errorf("checksum is changed: %s != %s", sum, base)
+		}
+	})
+
+	t.Run("checksum of a tarball made by GoTar equals to the one of files", func(t *testing.T) {
+		ctx := context.Background()
+		root := prepare(t, files)
+		if err := os.Symlink("a.txt", filepath.Join(root, "link")); err != nil {
+			t.Fatal(err)
+		}
+		want := try.To(archive.ChecksumOf(ctx, root)).OrFatal(t)
+
+		buf := new(bytes.Buffer)
+		prog := archive.GoTar(ctx, root, buf)
+		<-prog.Done()
+		if err := prog.Error(); err != nil {
+			t.Fatal(err)
+		}
+
+		c := archive.NewChecksum()
+		r := tar.NewReader(buf)
+		for {
+			hdr, err := r.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			switch hdr.Typeflag {
+			case tar.TypeReg:
+				if err := c.AddFile(hdr.Name, r); err != nil {
+					t.Fatal(err)
+				}
+			case tar.TypeSymlink:
+				c.AddSymlink(hdr.Name, hdr.Linkname)
+			}
+		}
+
+		if got := c.Sum(); got != want {
+			t.Errorf("checksum: (actual, expected) = (%s, %s)", got, want)
+		}
+	})
+}
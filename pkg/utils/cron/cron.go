@@ -0,0 +1,250 @@
+// Package cron parses schedules in the standard 5-field cron format.
+//
+//	┌───────────── minute (0-59)
+//	│ ┌─────────── hour (0-23)
+//	│ │ ┌───────── day of month (1-31)
+//	│ │ │ ┌─────── month (1-12 or JAN-DEC)
+//	│ │ │ │ ┌───── day of week (0-7 or SUN-SAT; both 0 and 7 are Sunday)
+//	│ │ │ │ │
+//	* * * * *
+//
+// Each field accepts "*", a number, a range "a-b", a step "*/n" or "a-b/n", and lists of them separated by ",".
+//
+// Descriptors "@yearly" ("@annually"), "@monthly", "@weekly", "@daily" ("@midnight") and "@hourly" are also accepted.
+//
+// As with the traditional cron, when both of day of month and day of week are restricted (not "*"),
+// a time matches if either of them matches.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid cron schedule")
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	fieldMinute = field{name: "minute", min: 0, max: 59}
+	fieldHour   = field{name: "hour", min: 0, max: 23}
+	fieldDom    = field{name: "day of month", min: 1, max: 31}
+	fieldMonth  = field{
+		name: "month", min: 1, max: 12,
+		names: map[string]int{
+			"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+			"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+		},
+	}
+	fieldDow = field{
+		name: "day of week", min: 0, max: 7,
+		names: map[string]int{
+			"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+		},
+	}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron schedule.
+type Schedule struct {
+	expr string
+
+	// bitsets. n-th bit is set if value n matches.
+	minute, hour, dom, month, dow uint64
+
+	// true if the field is "*" (or "*/1")
+	domStar, dowStar bool
+}
+
+// Parse parses cron expression.
+//
+// # Returns
+//
+// - Schedule
+//
+// - error: ErrInvalidSchedule if expr is not valid.
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if strings.HasPrefix(expr, "@") {
+		s, ok := descriptors[strings.ToLower(expr)]
+		if !ok {
+			return Schedule{}, fmt.Errorf("%w: unknown descriptor: %s", ErrInvalidSchedule, expr)
+		}
+		spec = s
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf(
+			"%w: expected 5 fields, but %d: %s", ErrInvalidSchedule, len(fields), expr,
+		)
+	}
+
+	s := Schedule{expr: expr}
+	var err error
+	if s.minute, _, err = parseField(fields[0], fieldMinute); err != nil {
+		return Schedule{}, err
+	}
+	if s.hour, _, err = parseField(fields[1], fieldHour); err != nil {
+		return Schedule{}, err
+	}
+	if s.dom, s.domStar, err = parseField(fields[2], fieldDom); err != nil {
+		return Schedule{}, err
+	}
+	if s.month, _, err = parseField(fields[3], fieldMonth); err != nil {
+		return Schedule{}, err
+	}
+	if s.dow, s.dowStar, err = parseField(fields[4], fieldDow); err != nil {
+		return Schedule{}, err
+	}
+	if s.dow&(1<<7) != 0 { // 7 is also Sunday
+		s.dow |= 1
+	}
+
+	return s, nil
+}
+
+func parseField(expr string, f field) (uint64, bool, error) {
+	bad := func(reason string) error {
+		return fmt.Errorf("%w: %s: %s: %s", ErrInvalidSchedule, f.name, reason, expr)
+	}
+
+	var bits uint64
+	star := false
+	for _, term := range strings.Split(expr, ",") {
+		rng, stepExpr, hasStep := strings.Cut(term, "/")
+		step := 1
+		if hasStep {
+			s, err := strconv.Atoi(stepExpr)
+			if err != nil || s <= 0 {
+				return 0, false, bad("bad step")
+			}
+			step = s
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = f.min, f.max
+			if step == 1 {
+				star = true
+			}
+		case strings.Contains(rng, "-"):
+			l, h, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(l); err != nil {
+				return 0, false, bad(err.Error())
+			}
+			if hi, err = f.value(h); err != nil {
+				return 0, false, bad(err.Error())
+			}
+			if hi < lo {
+				return 0, false, bad("range is reversed")
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, false, bad(err.Error())
+			}
+			lo, hi = v, v
+			if hasStep {
+				hi = f.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, star, nil
+}
+
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("not a number: %s", s)
+	}
+	if v < f.min || f.max < v {
+		return 0, fmt.Errorf("out of range [%d, %d]: %d", f.min, f.max, v)
+	}
+	return v, nil
+}
+
+// String returns the expression which the Schedule is parsed from.
+func (s Schedule) String() string {
+	return s.expr
+}
+
+// IsZero returns true if the Schedule is not parsed from any expression.
+func (s Schedule) IsZero() bool {
+	return s.expr == ""
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+func (s Schedule) matchDay(t time.Time) bool {
+	dom := has(s.dom, t.Day())
+	dow := has(s.dow, int(t.Weekday()))
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next returns the earliest time matching the Schedule, strictly after t.
+//
+// The returned time is in the location of t.
+// If no time matches in 5 years after t, it returns zero time.
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.IsZero() {
+		return time.Time{}
+	}
+
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
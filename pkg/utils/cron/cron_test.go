@@ -0,0 +1,81 @@
+package cron_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opst/knitfab/pkg/utils/cron"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every-minute",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := cron.Parse(expr); !errors.Is(err, cron.ErrInvalidSchedule) {
+				t.Errorf("expected ErrInvalidSchedule, but got %v", err)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	for _, testcase := range []struct {
+		expr string
+		from string
+		want string
+	}{
+		{expr: "* * * * *", from: "2024-01-01T00:00:30Z", want: "2024-01-01T00:01:00Z"},
+		{expr: "*/15 * * * *", from: "2024-01-01T00:15:00Z", want: "2024-01-01T00:30:00Z"},
+		{expr: "0 3 * * *", from: "2024-01-01T03:00:00Z", want: "2024-01-02T03:00:00Z"},
+		{expr: "@daily", from: "2024-01-31T12:00:00Z", want: "2024-02-01T00:00:00Z"},
+		{expr: "@hourly", from: "2024-01-01T23:59:00Z", want: "2024-01-02T00:00:00Z"},
+		{expr: "30 9 * * MON-FRI", from: "2024-01-05T10:00:00Z", want: "2024-01-08T09:30:00Z"}, // Fri -> Mon
+		{expr: "0 0 * * 7", from: "2024-01-01T00:00:00Z", want: "2024-01-07T00:00:00Z"},        // Sunday
+		{expr: "0 0 29 2 *", from: "2024-03-01T00:00:00Z", want: "2028-02-29T00:00:00Z"},
+		{expr: "0 0 1,15 * *", from: "2024-01-02T00:00:00Z", want: "2024-01-15T00:00:00Z"},
+		{expr: "0 0 13 * FRI", from: "2024-01-01T00:00:00Z", want: "2024-01-05T00:00:00Z"}, // either matches
+		{expr: "0 12 * JUN *", from: "2024-01-01T00:00:00Z", want: "2024-06-01T12:00:00Z"},
+		{expr: "10-20/5 * * * *", from: "2024-01-01T00:16:00Z", want: "2024-01-01T00:20:00Z"},
+	} {
+		t.Run(testcase.expr+" from "+testcase.from, func(t *testing.T) {
+			s, err := cron.Parse(testcase.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(at(testcase.from)); !got.Equal(at(testcase.want)) {
+				t.Errorf("Next: got %s, want %s", got, testcase.want)
+			}
+			if s.String() != testcase.expr {
+				t.Errorf("String: got %s", s.String())
+			}
+		})
+	}
+
+	t.Run("zero schedule never matches", func(t *testing.T) {
+		if got := (cron.Schedule{}).Next(time.Now()); !got.IsZero() {
+			t.Errorf("Next: got %s", got)
+		}
+	})
+}
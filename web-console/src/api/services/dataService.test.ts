@@ -2,7 +2,7 @@ import { DataService } from "./dataService";
 import { ApiClient } from "../apiClient";
 import { Duration } from "./types/time";
 import { DateTime } from "luxon";
-import { RawDataDetail } from "./types/types";
+import { RawDataDetail, RawLineage } from "./types/types";
 
 const mockApiClient: Partial<ApiClient> = {
     get: jest.fn(),
@@ -86,4 +86,35 @@ describe("DataService", () => {
             expect(calledUrl?.searchParams.get("tag")).toContain("knit#id:data-123");
         });
     });
+
+    describe("fetchLineage", () => {
+        it("should call correct URL with directions and depth", async () => {
+            (mockApiClient.get as jest.Mock).mockResolvedValue({
+                root: "data-123", data: [], runs: [],
+            } satisfies RawLineage);
+
+            const lineage = await testee.fetchLineage("data-123", { upstream: true, depth: 2 });
+            expect(lineage).toEqual({ root: "data-123", data: [], runs: [] });
+
+            const calledUrl = URL.parse(
+                (mockApiClient.get as jest.Mock).mock.calls[0][0] as string,
+                "http://localhost"
+            );
+            expect(calledUrl).not.toBeNull();
+            expect(calledUrl?.pathname).toBe(`/lineage/data-123`);
+            expect(calledUrl?.searchParams.get("upstream")).toBe("true");
+            expect(calledUrl?.searchParams.has("downstream")).toBe(false);
+            expect(calledUrl?.searchParams.get("depth")).toBe("2");
+        });
+
+        it("should trace without limit by default", async () => {
+            (mockApiClient.get as jest.Mock).mockResolvedValue({
+                root: "data-123", data: [], runs: [],
+            } satisfies RawLineage);
+
+            await testee.fetchLineage("data-123");
+            const calledUrl = (mockApiClient.get as jest.Mock).mock.calls[0][0] as string;
+            expect(calledUrl).toBe("/lineage/data-123?depth=all");
+        });
+    });
 });
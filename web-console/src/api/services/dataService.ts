@@ -1,8 +1,8 @@
 import luxon from 'luxon';
-import { DataDetail, Tag } from '../../types/types';
+import { DataDetail, Lineage, Tag } from '../../types/types';
 import { ApiClient } from '../apiClient';
 import { Duration, durationToString } from './types/time';
-import { RawDataDetail, toDataDetail, toTagString } from './types/types';
+import { RawDataDetail, RawLineage, toDataDetail, toLineage, toTagString } from './types/types';
 
 export class DataService {
     private apiClient: ApiClient;
@@ -75,4 +75,38 @@ export class DataService {
                 return toDataDetail(ds[0]);
             });
     }
+
+    /**
+     * Fetches the Data Lineage traced from a Data item
+     *
+     * @param id - The ID of the Data item where tracing starts
+     * @param params - An object containing optional parameters for tracing.
+     *
+     * The `upstream` and `downstream` fields specify directions to be traced.
+     * If neither is true, both directions are traced.
+     *
+     * The `depth` field is the max number of Runs to be traced along a path.
+     * If it is not provided, it traces without limit.
+     *
+     * @returns a Promise that resolves to a Lineage object, which has Data and Runs in the traced subgraph.
+     * If no Data item with the specified ID exists, the Promise will be rejected.
+     */
+    public async fetchLineage(id: string, params: {
+        upstream?: boolean;
+        downstream?: boolean;
+        depth?: number;
+    } = {}): Promise<Lineage> {
+        const queryParams = new URLSearchParams();
+        if (params.upstream) {
+            queryParams.append('upstream', 'true');
+        }
+        if (params.downstream) {
+            queryParams.append('downstream', 'true');
+        }
+        queryParams.append('depth', params.depth === undefined ? 'all' : `${params.depth}`);
+
+        return this.apiClient
+            .get<RawLineage>(`/lineage/${encodeURIComponent(id)}?${queryParams.toString()}`)
+            .then(toLineage);
+    }
 }
@@ -1,4 +1,4 @@
-import { Assignment, DataDetail, PlanDetail, PlanSummary, RunDetail, RunSummary, Tag } from "../../../types/types";
+import { Assignment, DataDetail, Lineage, PlanDetail, PlanSummary, RunDetail, RunSummary, Tag } from "../../../types/types";
 import { DateTime } from "luxon";
 
 export type RawDataSummary = {
@@ -10,6 +10,8 @@ export type RawDataDetail = RawDataSummary & {
     upstream: RawCreatedFrom
     downstreams: RawAssignedTo[]
     nomination: RawNominatedBy[]
+    size?: { bytes: number, files: number }
+    checksum?: string
 };
 
 export type TagString = `${string}:${string}`;
@@ -44,7 +46,9 @@ export function toDataDetail(data: RawDataDetail): DataDetail {
             plan: toPlanSummary(n.plan),
             tags: n.tags.map(parseTag),
             path: n.path,
-        }))
+        })),
+        size: data.size && { bytes: data.size.bytes, files: data.size.files },
+        checksum: data.checksum,
     }
 }
 
@@ -186,6 +190,20 @@ export function toRunDetail(run: RawRunDetail): RunDetail {
     }
 }
 
+export type RawLineage = {
+    root: string
+    data: RawDataDetail[]
+    runs: RawRunDetail[]
+}
+
+export function toLineage(lineage: RawLineage): Lineage {
+    return {
+        root: lineage.root,
+        data: lineage.data.map(toDataDetail),
+        runs: lineage.runs.map(toRunDetail),
+    }
+}
+
 export type RawCreatedFrom = {
     run: RawRunSummary
     mountpoint?: RawMountpoint
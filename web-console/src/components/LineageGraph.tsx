@@ -125,76 +125,75 @@ const LineageGraphInner = ({ dataService, runService, rootDataId, rootRunId }: {
         const fetchedRun: { run: RunDetail }[] = [];
         const fetchedLinks: Link[] = [];
 
-        // Fetch data and run recursively.
-        // - fetchData: fetch Data and its upstream/downstream Runs
-        // - fetchRun: fetch Run and its input/output Data
+        // Collect Data and Runs from Lineages fetched from the API.
+        // - addData: add Data
+        // - addRun: add Run and links between the Run and its input/output Data
         //
         // Found Data and Runs are stored in fetchedData and fetchedRun, respectively.
         // Found Links are stored in fetchedLinks.
-        const fetchData = async (knitId: string) => {
-            if (fetchedData.find((n) => n.data.knitId === knitId)) { return; }
-            const data = await dataService.fetchById(knitId);
+        const addData = (data: DataDetail) => {
+            if (fetchedData.find((n) => n.data.knitId === data.knitId)) { return; }
             fetchedData.push({ data });
-            if (data.upstream) {
-                await fetchRun(data.upstream.run.runId);
-            }
-            for (const downstream of data.downstreams) {
-                await fetchRun(downstream.run.runId);
+        };
+
+        const addLink = (newLink: Link) => {
+            if (!fetchedLinks.find((e) => sameLink(e, newLink))) {
+                fetchedLinks.push(newLink);
             }
         };
 
-        const fetchRun = async (runId: string) => {
-            if (fetchedRun.find((n) => n.run.runId === runId)) { return; }
-            const run = await runService.fetchById(runId);
+        const addRun = (run: RunDetail) => {
+            if (fetchedRun.find((n) => n.run.runId === run.runId)) { return; }
             fetchedRun.push({ run });
 
-            // on Data is fetched, add link between Data and Run
             for (const input of run.inputs) {
-                await fetchData(input.knitId);
-                const newLink = {
+                addLink({
                     type: "input" as const,
                     source: input.knitId,
                     target: run.runId,
                     label: input.path,
-                };
-                if (!fetchedLinks.find((e) => sameLink(e, newLink))) {
-                    fetchedLinks.push(newLink);
-                }
+                });
             }
             for (const output of run.outputs) {
-                await fetchData(output.knitId);
-                const newLink = {
+                addLink({
                     type: "output" as const,
                     source: run.runId,
                     target: output.knitId,
                     label: output.path,
-                };
-                if (!fetchedLinks.find((e) => sameLink(e, newLink))) {
-                    fetchedLinks.push(newLink);
-                }
+                });
             }
             if (run.log) {
-                await fetchData(run.log.knitId);
-                const newLink = {
+                addLink({
                     type: "output" as const,
                     source: run.runId,
                     target: run.log.knitId,
                     label: "(log)",
-                };
-                if (!fetchedLinks.find((e) => sameLink(e, newLink))) {
-                    fetchedLinks.push(newLink);
-                }
+                });
             }
         };
 
+        const fetchLineage = async (knitId: string, params: { upstream?: boolean, downstream?: boolean } = {}) => {
+            const lineage = await dataService.fetchLineage(knitId, params);
+            lineage.data.forEach(addData);
+            lineage.runs.forEach(addRun);
+        };
+
         // fetch graph from root, and build graph
         const fetchGraph = async () => {
             try {
                 if (rootDataId) {
-                    await fetchData(rootDataId);
+                    await fetchLineage(rootDataId);
                 }
                 if (rootRunId) {
-                    await fetchRun(rootRunId);
+                    // trace upstream from inputs, and downstream from outputs.
+                    const run = await runService.fetchById(rootRunId);
+                    addRun(run);
+                    for (const input of run.inputs) {
+                        await fetchLineage(input.knitId, { upstream: true });
+                    }
+                    for (const output of [...run.outputs, ...(run.log ? [run.log] : [])]) {
+                        await fetchLineage(output.knitId, { downstream: true });
+                    }
                 }
 
                 const _edges = fetchedLinks.map((link) => {
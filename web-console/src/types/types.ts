@@ -7,6 +7,13 @@ export type DataDetail = DataSummary & {
     upstream: CreatedFrom;
     downstreams: AssignedTo[];
     nomination: NominatedBy[];
+    size?: DataSize;
+    checksum?: string;
+};
+
+export type DataSize = {
+    bytes: number;
+    files: number;
 };
 
 export type PlanSummary = {
@@ -42,6 +49,12 @@ export type RunDetail = RunSummary & {
     log?: LogSummary
 };
 
+export type Lineage = {
+    root: string
+    data: DataDetail[]
+    runs: RunDetail[]
+};
+
 
 export type Tag = {
     key: string